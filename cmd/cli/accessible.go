@@ -0,0 +1,19 @@
+package main
+
+import (
+	"flag"
+
+	"github.com/pavliha/aircast/aircast-cli/internal/term"
+)
+
+// accessibleFlag defines --accessible on a command's flags
+func accessibleFlag(fs *flag.FlagSet) *bool {
+	return fs.Bool("accessible", false, "Screen-reader friendly output: plain numbered prompts and labeled status lines, no full-screen interfaces, colors or symbols (env AIRCAST_ACCESSIBLE)")
+}
+
+// applyAccessible switches to accessible output when requested
+func applyAccessible(on bool) {
+	if on {
+		term.SetAccessible()
+	}
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/pavliha/aircast/aircast-cli/internal/cli"
+	"github.com/pavliha/aircast/aircast-cli/internal/term"
+	"github.com/pavliha/aircast/aircast-cli/internal/ui"
+	log "github.com/sirupsen/logrus"
+)
+
+// alarmHookTimeout bounds how long an alarm hook may run
+const alarmHookTimeout = 30 * time.Second
+
+var (
+	alarmRaisedStyle  = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("15")).Background(lipgloss.Color("160"))
+	alarmClearedStyle = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("10"))
+)
+
+// parseAlarms combines alarm rules from flags and config.json
+func parseAlarms(rules ...[]string) ([]cli.AlarmRule, error) {
+	var alarms []cli.AlarmRule
+	seen := make(map[string]bool)
+	for _, set := range rules {
+		for _, s := range set {
+			rule, err := cli.ParseAlarmRule(s)
+			if err != nil {
+				return nil, err
+			}
+			if !seen[rule.String()] {
+				seen[rule.String()] = true
+				alarms = append(alarms, rule)
+			}
+		}
+	}
+	return alarms, nil
+}
+
+// newAlarmHandler returns the bridge's alarm callback: it highlights the
+// alarm in the console, shows a desktop notification where possible and
+// runs the hook command, if any
+func newAlarmHandler(deviceID, hook string, logger *log.Entry) func(cli.AlarmEvent) {
+	return func(event cli.AlarmEvent) {
+		value := strconv.FormatFloat(event.Value, 'f', -1, 64)
+		if event.Raised {
+			fmt.Fprintf(ui.Output, "\n%s %s (now %s)\n\n", alarmRaisedStyle.Render(" ALARM "), event.Rule, value)
+			notifyDesktop("Aircast alarm", fmt.Sprintf("%s (now %s)", event.Rule, value), logger)
+		} else {
+			fmt.Fprintf(ui.Output, "\n%s %s (now %s)\n\n", alarmClearedStyle.Render(term.Symbol("✓ ", "")+"Alarm cleared:"), event.Rule, value)
+		}
+
+		if hook != "" {
+			go runAlarmHook(hook, deviceID, event, value, logger)
+		}
+	}
+}
+
+// runAlarmHook runs the hook command with the alarm described in its environment
+func runAlarmHook(hook, deviceID string, event cli.AlarmEvent, value string, logger *log.Entry) {
+	ctx, cancel := context.WithTimeout(context.Background(), alarmHookTimeout)
+	defer cancel()
+
+	state := "cleared"
+	if event.Raised {
+		state = "raised"
+	}
+
+	cmd := shellCommand(ctx, hook)
+	cmd.Env = append(os.Environ(),
+		"AIRCAST_ALARM="+event.Rule.String(),
+		"AIRCAST_ALARM_METRIC="+event.Rule.Metric,
+		"AIRCAST_ALARM_STATE="+state,
+		"AIRCAST_ALARM_VALUE="+value,
+		"AIRCAST_ALARM_TIME="+event.Time.UTC().Format(time.RFC3339),
+		"AIRCAST_DEVICE_ID="+deviceID,
+	)
+
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		logger.WithError(err).WithFields(log.Fields{
+			"hook":   hook,
+			"output": string(out),
+		}).Warn("Alarm hook failed")
+		return
+	}
+	logger.WithFields(log.Fields{"hook": hook, "alarm": event.Rule.String(), "state": state}).Debug("Alarm hook ran")
+}
+
+// shellCommand runs a command line through the platform shell
+func shellCommand(ctx context.Context, command string) *exec.Cmd {
+	if runtime.GOOS == "windows" {
+		return exec.CommandContext(ctx, "cmd", "/C", command)
+	}
+	return exec.CommandContext(ctx, "sh", "-c", command)
+}
+
+// notifyDesktop shows a desktop notification when a desktop session is
+// available; relays running headless simply skip it
+func notifyDesktop(title, message string, logger *log.Entry) {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("osascript", "-e", fmt.Sprintf("display notification %q with title %q", message, title))
+	case "linux":
+		if os.Getenv("DISPLAY") == "" && os.Getenv("WAYLAND_DISPLAY") == "" {
+			return
+		}
+		cmd = exec.Command("notify-send", "--urgency=critical", title, message)
+	default:
+		return
+	}
+	if err := cmd.Start(); err != nil {
+		logger.WithError(err).Debug("Desktop notification unavailable")
+		return
+	}
+	go func() { _ = cmd.Wait() }()
+}
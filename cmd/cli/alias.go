@@ -0,0 +1,166 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/pavliha/aircast/aircast-cli/internal/auth"
+	"github.com/pavliha/aircast/aircast-cli/internal/term"
+)
+
+// aliasCommands are the subcommands of "alias"
+var aliasCommands = map[string]command{
+	"list":   {"List device aliases", runAliasList},
+	"remove": {"Delete a device alias", runAliasRemove},
+	"set":    {"Create or update an alias for a device ID", runAliasSet},
+}
+
+// runAlias dispatches "alias" subcommands
+func runAlias(args []string) error {
+	if len(args) == 0 || strings.HasPrefix(args[0], "-") {
+		return runAliasList(args)
+	}
+
+	cmd, ok := aliasCommands[args[0]]
+	if !ok {
+		printSubcommands("alias", aliasCommands)
+		return fmt.Errorf("unknown alias command %q", args[0])
+	}
+	return cmd.run(args[1:])
+}
+
+// runAliasSet creates or updates an alias, checking the device exists when
+// the API is reachable
+func runAliasSet(args []string) error {
+	fs := flag.NewFlagSet("alias set", flag.ExitOnError)
+	apiURL := fs.String("api", defaultAPIURL(), "API base URL used to verify the device")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: aircast-cli alias set [flags] <alias> <device-id>\n\n")
+		fs.PrintDefaults()
+	}
+
+	positional := parseArgs(fs, args)
+	if len(positional) != 2 {
+		fs.Usage()
+		os.Exit(2)
+	}
+	name, deviceID := positional[0], positional[1]
+
+	configStore, err := auth.NewConfigStore()
+	if err != nil {
+		return err
+	}
+
+	// Allow aliasing an alias by resolving it first
+	if deviceID, err = configStore.ResolveDevice(deviceID); err != nil {
+		return err
+	}
+
+	deviceName := ""
+	if client, err := newAPIClient(*apiURL); err == nil {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		if devices, err := client.GetDevices(ctx); err == nil {
+			found := false
+			for _, d := range devices {
+				if d.ID == deviceID {
+					found, deviceName = true, d.Name
+					break
+				}
+			}
+			if !found {
+				return fmt.Errorf("device %s not found in your account", deviceID)
+			}
+		}
+	}
+
+	if err := configStore.SetAlias(name, deviceID); err != nil {
+		return err
+	}
+
+	if deviceName != "" {
+		fmt.Printf("%s%s %s %s (%s)\n", term.Symbol("✓ ", ""), name, term.Symbol("→", "->"), deviceName, deviceID)
+	} else {
+		fmt.Printf("%s%s %s %s (not verified, API unavailable)\n", term.Symbol("✓ ", ""), name, term.Symbol("→", "->"), deviceID)
+	}
+	return nil
+}
+
+// runAliasRemove deletes an alias
+func runAliasRemove(args []string) error {
+	fs := flag.NewFlagSet("alias remove", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: aircast-cli alias remove <alias>\n")
+	}
+
+	positional := parseArgs(fs, args)
+	if len(positional) != 1 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	configStore, err := auth.NewConfigStore()
+	if err != nil {
+		return err
+	}
+
+	removed, err := configStore.RemoveAlias(positional[0])
+	if err != nil {
+		return err
+	}
+	if !removed {
+		return fmt.Errorf("no alias named %q", positional[0])
+	}
+
+	fmt.Printf("%sRemoved alias %s\n", term.Symbol("✓ ", ""), positional[0])
+	return nil
+}
+
+// runAliasList prints all aliases
+func runAliasList(args []string) error {
+	fs := flag.NewFlagSet("alias list", flag.ExitOnError)
+	_ = fs.Parse(args)
+
+	configStore, err := auth.NewConfigStore()
+	if err != nil {
+		return err
+	}
+	config, err := configStore.LoadConfig()
+	if err != nil {
+		return err
+	}
+
+	if len(config.Aliases) == 0 {
+		fmt.Println("No aliases defined. Create one with: aircast-cli alias set <alias> <device-id>")
+		return nil
+	}
+
+	names := make([]string, 0, len(config.Aliases))
+	for name := range config.Aliases {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ALIAS\tDEVICE ID")
+	for _, name := range names {
+		fmt.Fprintf(w, "%s\t%s\n", name, config.Aliases[name])
+	}
+	return w.Flush()
+}
+
+// resolveDeviceArg turns a device ID or alias given on the command line into a device ID
+func resolveDeviceArg(nameOrID string) (string, error) {
+	configStore, err := auth.NewConfigStore()
+	if err != nil {
+		return nameOrID, err
+	}
+	return configStore.ResolveDevice(nameOrID)
+}
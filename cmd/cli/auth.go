@@ -0,0 +1,308 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/pavliha/aircast/aircast-cli/internal/api"
+	"github.com/pavliha/aircast/aircast-cli/internal/auth"
+	"github.com/pavliha/aircast/aircast-cli/internal/ui"
+	log "github.com/sirupsen/logrus"
+)
+
+// defaultTokenLifetime is assumed when the token response carries no expiry
+const defaultTokenLifetime = 24 * time.Hour
+
+// maxClockSkew is the local clock error above which the user is warned
+const maxClockSkew = 2 * time.Minute
+
+// syncClock measures the local clock's offset from the API server and applies
+// it to token expiry checks. Failures are ignored; the local clock is used.
+func syncClock(ctx context.Context, apiURL string, tokenStore *auth.TokenStore, logger *log.Entry) {
+	serverTime, err := api.ServerTime(ctx, apiURL)
+	if err != nil {
+		logger.WithError(err).Debug("Could not determine server time, trusting local clock")
+		return
+	}
+
+	skew := time.Until(serverTime)
+	tokenStore.SetClockSkew(skew)
+	logger.WithField("skew", skew.Round(time.Millisecond)).Debug("Measured clock skew against API")
+
+	if skew > maxClockSkew || skew < -maxClockSkew {
+		direction := "behind"
+		if skew < 0 {
+			direction = "ahead of"
+			skew = -skew
+		}
+		logger.WithField("skew", skew.Round(time.Second)).Warn("Local clock is off")
+		fmt.Printf("⚠ Your system clock is %s %s the Aircast server.\n", skew.Round(time.Second), direction)
+		fmt.Println("  Token expiry is being corrected, but please fix the system time (e.g. enable NTP).")
+		fmt.Println()
+	}
+}
+
+// oauthClient returns the OAuth2 client to log in to apiURL as: the
+// oauth_client of the environment with that API URL, else the oauth_client
+// from config.json, with AIRCAST_CLIENT_ID, AIRCAST_CLIENT_SECRET,
+// AIRCAST_PKCE and AIRCAST_AUTH_ISSUER taking precedence, or the built-in
+// Aircast client. A client secret given as a reference, e.g.
+// "file:/etc/aircast/client-secret", is read from there.
+func oauthClient(apiURL string) (auth.Client, error) {
+	var client auth.Client
+	if configStore, err := auth.NewConfigStore(); err == nil {
+		if config, err := configStore.LoadConfig(); err == nil {
+			if env, ok := environmentFor(config, apiURL); ok && env.OAuthClient != nil {
+				client = *env.OAuthClient
+			} else if config.OAuthClient != nil {
+				client = *config.OAuthClient
+			}
+		}
+	}
+
+	if v := os.Getenv("AIRCAST_CLIENT_ID"); v != "" {
+		client.ID = v
+	}
+	if v := os.Getenv("AIRCAST_CLIENT_SECRET"); v != "" {
+		client.Secret = v
+	}
+	if v := os.Getenv("AIRCAST_PKCE"); v != "" {
+		client.PKCE = v
+	}
+	if v := os.Getenv("AIRCAST_AUTH_ISSUER"); v != "" {
+		client.Issuer = v
+	}
+
+	if err := client.Validate(); err != nil {
+		return auth.Client{}, fmt.Errorf("invalid OAuth client configuration: %w", err)
+	}
+	if err := resolveSecret(&client.Secret, "OAuth client secret", true); err != nil {
+		return auth.Client{}, err
+	}
+	return client, nil
+}
+
+// authenticate runs the device code flow, or the credential helper if one is
+// configured, and stores the resulting token
+func authenticate(ctx context.Context, apiURL, scope string, tokenStore *auth.TokenStore, logger *log.Entry) (string, error) {
+	if helper := credentialHelper(); helper != "" {
+		return authenticateHelper(ctx, helper, apiURL, scope, tokenStore, logger)
+	}
+
+	client, err := oauthClient(apiURL)
+	if err != nil {
+		return "", err
+	}
+
+	logger = logger.WithField("component", "auth")
+	authenticator := auth.NewDeviceCodeAuth(apiURL, logger)
+	authenticator.Scope = scope
+	authenticator.Client = client
+
+	token, err := ui.Authenticate(ctx, authenticator)
+	if err != nil {
+		return "", err
+	}
+
+	storeToken(token, apiURL, tokenStore, logger)
+	return token.AccessToken, nil
+}
+
+// authenticateBrowser logs in through the browser with PKCE, falling back to
+// the device code flow when no browser is available. A configured credential
+// helper takes precedence.
+func authenticateBrowser(ctx context.Context, apiURL, scope string, tokenStore *auth.TokenStore, logger *log.Entry) (string, error) {
+	if helper := credentialHelper(); helper != "" {
+		return authenticateHelper(ctx, helper, apiURL, scope, tokenStore, logger)
+	}
+
+	client, err := oauthClient(apiURL)
+	if err != nil {
+		return "", err
+	}
+
+	logger = logger.WithField("component", "auth")
+	authenticator := auth.NewBrowserAuth(apiURL, logger)
+	authenticator.Scope = scope
+	authenticator.Client = client
+
+	token, err := authenticator.Authenticate(ctx)
+	if errors.Is(err, auth.ErrNoBrowser) {
+		logger.Debug("No browser available, using device code flow")
+		fmt.Println("No browser available here - use the code below on another device.")
+		return authenticate(ctx, apiURL, scope, tokenStore, logger)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	storeToken(token, apiURL, tokenStore, logger)
+	return token.AccessToken, nil
+}
+
+// storeToken saves a newly issued token for future runs
+func storeToken(token *auth.TokenResponse, apiURL string, tokenStore *auth.TokenStore, logger *log.Entry) {
+	lifetime := defaultTokenLifetime
+	if token.ExpiresIn > 0 {
+		lifetime = time.Duration(token.ExpiresIn) * time.Second
+	}
+
+	// Save token for future use
+	newToken := &auth.StoredToken{
+		AccessToken:  token.AccessToken,
+		RefreshToken: token.RefreshToken,
+		TokenType:    "Bearer",
+		ExpiresAt:    tokenStore.Now().Add(lifetime),
+		Scope:        token.Scope,
+		APIURL:       apiURL,
+		Environment:  auth.TokenEnvironment(token.AccessToken, apiURL),
+	}
+
+	if err := tokenStore.SaveToken(newToken); err != nil {
+		logger.WithError(err).Warn("Failed to save token (will need to re-authenticate next time)")
+	} else {
+		fmt.Printf("✓ Token saved to: %s\n", tokenStore.GetTokenPath())
+		fmt.Println()
+	}
+}
+
+// warnEnvironmentSwitch tells the user that the stored login belongs to a
+// different environment than the one selected with --api
+func warnEnvironmentSwitch(token *auth.StoredToken, apiURL string, logger *log.Entry) {
+	if token == nil || token.APIURL == "" || token.APIURL == apiURL {
+		return
+	}
+
+	from, to := token.Env(), auth.DetectEnvironment(apiURL)
+	logger.WithFields(log.Fields{
+		"token_env": from,
+		"api_env":   to,
+	}).Warn("Stored token belongs to a different API")
+	fmt.Printf("⚠ You are logged in to %s (%s), but --api points to %s (%s).\n", from, token.APIURL, to, apiURL)
+	fmt.Printf("  Logging in to %s; devices from %s will not be available.\n", to, from)
+	fmt.Println()
+}
+
+// checkDeviceEnvironment verifies that an explicitly requested device exists
+// in the environment the token belongs to, so a mismatch is reported clearly
+// instead of surfacing as a WebSocket 404. It is skipped if the API is unreachable.
+func checkDeviceEnvironment(ctx context.Context, client *api.Client, cache *auth.DeviceCache, apiURL, deviceID string, logger *log.Entry) error {
+	devices, err := client.GetDevices(ctx)
+	if err != nil {
+		logger.WithError(err).Debug("Could not verify device environment")
+		return nil
+	}
+	for _, d := range devices {
+		if d.ID == deviceID {
+			return nil
+		}
+	}
+
+	env := auth.DetectEnvironment(apiURL)
+	if cached, cachedURL, _ := cache.Find(deviceID); cached != nil && cachedURL != apiURL {
+		return fmt.Errorf("device %s (%s) belongs to the %s environment (%s), but you are connected to %s (%s); rerun with --api %s",
+			deviceID, cached.Name, auth.DetectEnvironment(cachedURL), cachedURL, env, apiURL, cachedURL)
+	}
+	return fmt.Errorf("device %s was not found in your %s account (%s); check the ID or the --api environment", deviceID, env, apiURL)
+}
+
+// logout revokes the stored token on the server, then deletes it locally
+func logout(tokenStore *auth.TokenStore, logger *log.Entry) {
+	token, err := tokenStore.LoadToken()
+	if err != nil {
+		logger.WithError(err).Warn("Failed to load stored token")
+	}
+
+	if token != nil && token.APIURL != "" && credentialHelper() != "" {
+		fmt.Println("The login came from the credential helper; sign out of your SSO to end the session there too.")
+	} else if token != nil && token.APIURL != "" {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		// Revoke the refresh token first so no new access tokens can be minted
+		client, revokeErr := oauthClient(token.APIURL)
+		if revokeErr == nil && token.RefreshToken != "" {
+			revokeErr = auth.RevokeToken(ctx, token.APIURL, client, token.RefreshToken, auth.TokenTypeRefresh)
+		}
+		if revokeErr == nil {
+			revokeErr = auth.RevokeToken(ctx, token.APIURL, client, token.AccessToken, auth.TokenTypeAccess)
+		}
+
+		if revokeErr != nil {
+			logger.WithError(revokeErr).Warn("Token revocation failed")
+			fmt.Printf("⚠ Could not revoke the session on %s: %v\n", token.APIURL, revokeErr)
+			fmt.Printf("  The token was removed locally but stays valid on the server until %s.\n",
+				token.ExpiresAt.Local().Format("2006-01-02 15:04"))
+			fmt.Println("  Revoke it from the Aircast dashboard if this machine may be compromised.")
+		} else {
+			fmt.Println("✓ Session revoked on the server")
+		}
+	}
+
+	if err := tokenStore.DeleteToken(); err != nil {
+		logger.WithError(err).Fatal("Failed to delete token")
+	}
+	fmt.Println("✓ Logged out successfully")
+	fmt.Printf("Token removed from: %s\n", tokenStore.GetTokenPath())
+}
+
+// newAPIClient creates an API client using the stored token for apiURL
+func newAPIClient(apiURL string) (*api.Client, error) {
+	tokenStore, err := auth.NewTokenStore()
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := tokenStore.LoadToken()
+	if err != nil {
+		return nil, err
+	}
+
+	// A local clock running ahead makes a valid login look expired; ask the
+	// server's clock before sending the user to log in again
+	if token != nil && token.APIURL == apiURL && !tokenStore.IsTokenValid(token) {
+		syncClock(context.Background(), apiURL, tokenStore, log.WithField("app", "aircast-cli"))
+	}
+
+	if token == nil || !tokenStore.IsTokenValid(token) || token.APIURL != apiURL {
+		return nil, fmt.Errorf("not logged in to %s, run 'aircast-cli login' first", apiURL)
+	}
+
+	return indexDevices(api.NewClient(apiURL, token.AccessToken), apiURL), nil
+}
+
+// runLogin authenticates and stores a token without starting the bridge
+func runLogin(args []string) error {
+	fs := flag.NewFlagSet("login", flag.ExitOnError)
+	apiURL := fs.String("api", defaultAPIURL(), "API base URL")
+	scope := fs.String("scope", "", "Request a restricted token, e.g. telemetry-only (default: full access)")
+	browser := fs.Bool("browser", false, "Log in through the browser on this machine instead of entering a code")
+	accessible := accessibleFlag(fs)
+	_ = fs.Parse(args)
+	applyAccessible(*accessible)
+
+	tokenStore, err := auth.NewTokenStore()
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	login := authenticate
+	if *browser {
+		login = authenticateBrowser
+	}
+
+	if _, err := login(ctx, *apiURL, *scope, tokenStore, log.WithField("app", "aircast-cli")); err != nil {
+		return fmt.Errorf("authentication failed: %w", err)
+	}
+
+	return nil
+}
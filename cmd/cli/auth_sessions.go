@@ -0,0 +1,200 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"text/tabwriter"
+
+	"github.com/pavliha/aircast/aircast-cli/internal/api"
+	"github.com/pavliha/aircast/aircast-cli/internal/auth"
+	"github.com/pavliha/aircast/aircast-cli/internal/term"
+)
+
+// authCommands are the subcommands of "auth"
+var authCommands = map[string]command{
+	"sessions": {"List the account's active logins with their device, IP and last use", runAuthSessions},
+	"revoke":   {"Sign out a login session, e.g. of a lost or stolen laptop", runAuthRevoke},
+}
+
+// runAuth dispatches "auth" subcommands
+func runAuth(args []string) error {
+	return runGroup("auth", authCommands, args)
+}
+
+// listSessions fetches the account's sessions, explaining an API without them
+func listSessions(ctx context.Context, client *api.Client) ([]api.Session, error) {
+	sessions, err := client.ListSessions(ctx)
+	if errors.Is(err, api.ErrSessionsUnsupported) {
+		return nil, fmt.Errorf("%w; manage sessions in the dashboard instead", err)
+	}
+	return sessions, err
+}
+
+// runAuthSessions lists the account's active login sessions
+func runAuthSessions(args []string) error {
+	fs := flag.NewFlagSet("auth sessions", flag.ExitOnError)
+	apiURL := fs.String("api", defaultAPIURL(), "API base URL")
+	jsonOut := fs.Bool("json", false, "Print the sessions as JSON for scripts")
+	_ = fs.Parse(args)
+
+	client, err := newAPIClient(*apiURL)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	sessions, err := listSessions(ctx, client)
+	if err != nil {
+		return err
+	}
+
+	if *jsonOut {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(sessions)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tDEVICE\tIP\tCREATED\tLAST USED\t")
+	for _, s := range sessions {
+		ip := s.IP
+		if ip == "" {
+			ip = "-"
+		}
+		lastUsed := "-"
+		if !s.LastUsedAt.IsZero() {
+			lastUsed = s.LastUsedAt.Local().Format("2006-01-02 15:04")
+		}
+		current := ""
+		if s.Current {
+			current = "(this machine)"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", s.ID, s.Device, ip, s.CreatedAt.Local().Format("2006-01-02 15:04"), lastUsed, current)
+	}
+	return w.Flush()
+}
+
+// runAuthRevoke signs out a session given by ID or a unique ID prefix, or
+// every other session with --others
+func runAuthRevoke(args []string) error {
+	fs := flag.NewFlagSet("auth revoke", flag.ExitOnError)
+	apiURL := fs.String("api", defaultAPIURL(), "API base URL")
+	others := fs.Bool("others", false, "Revoke every session except this machine's")
+	g := guardFlags(fs)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: aircast-cli auth revoke [flags] <session-id>\n")
+		fmt.Fprintf(fs.Output(), "       aircast-cli auth revoke --others [flags]\n\n")
+		fmt.Fprintf(fs.Output(), "Session IDs are shown by 'aircast-cli auth sessions'; a unique prefix is enough.\n\n")
+		fs.PrintDefaults()
+	}
+	positional := parseArgs(fs, args)
+	if (*others && len(positional) != 0) || (!*others && len(positional) != 1) {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	client, err := newAPIClient(*apiURL)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	sessions, err := listSessions(ctx, client)
+	if err != nil {
+		return err
+	}
+
+	var targets []api.Session
+	if *others {
+		for _, s := range sessions {
+			if !s.Current {
+				targets = append(targets, s)
+			}
+		}
+		if len(targets) == 0 {
+			fmt.Println("No other sessions")
+			return nil
+		}
+	} else {
+		session, err := findSession(sessions, positional[0])
+		if err != nil {
+			return err
+		}
+		targets = []api.Session{session}
+	}
+
+	if !*g.yes {
+		for _, s := range targets {
+			fmt.Printf("  %s  %s, last used %s\n", s.ID, s.Device, statusAgo(s.LastUsedAt))
+		}
+	}
+	var confirmed bool
+	switch {
+	case len(targets) > 1:
+		// Typing the count guards against signing out more than intended
+		confirmed = g.confirmTyped(fmt.Sprintf("Sign out these %d sessions?", len(targets)), strconv.Itoa(len(targets)))
+	case targets[0].Current:
+		confirmed = g.confirm("This is this machine's session; you will be logged out here. Sign it out?")
+	default:
+		confirmed = g.confirm("Sign out this session?")
+	}
+	if !confirmed {
+		fmt.Println("Aborted")
+		return nil
+	}
+
+	g.attach(client)
+	for _, s := range targets {
+		err := client.RevokeSession(ctx, s.ID)
+		if g.skipped(err) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		fmt.Printf("%sSigned out %s (%s)\n", term.Symbol("✓ ", ""), s.ID, s.Device)
+
+		// The stored token is now useless; don't leave it behind
+		if s.Current {
+			if tokenStore, err := auth.NewTokenStore(); err == nil {
+				_ = tokenStore.DeleteToken()
+			}
+			fmt.Println("Logged out on this machine; run 'aircast-cli login' to log in again")
+		}
+	}
+	g.report()
+	return nil
+}
+
+// findSession returns the session whose ID is id or starts with it
+func findSession(sessions []api.Session, id string) (api.Session, error) {
+	var matches []api.Session
+	for _, s := range sessions {
+		if s.ID == id {
+			return s, nil
+		}
+		if strings.HasPrefix(s.ID, id) {
+			matches = append(matches, s)
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		return api.Session{}, fmt.Errorf("no active session %s; list them with 'aircast-cli auth sessions'", id)
+	case 1:
+		return matches[0], nil
+	}
+	return api.Session{}, fmt.Errorf("%q matches %d sessions; give more of the ID", id, len(matches))
+}
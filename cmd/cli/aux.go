@@ -0,0 +1,33 @@
+package main
+
+import (
+	"context"
+
+	"github.com/pavliha/aircast/aircast-cli/internal/cli"
+	log "github.com/sirupsen/logrus"
+)
+
+// startAuxBridge bridges a device's companion data channel to a local TCP
+// address. Companion data is optional, so failures are logged and the
+// MAVLink bridge keeps running without it.
+func startAuxBridge(ctx context.Context, apiURL, deviceID, accessToken, addr string, logger *log.Entry) *cli.Bridge {
+	auxLogger := logger.WithField("component", "aux")
+
+	b, err := cli.New(&cli.Config{
+		WebSocketURL: buildAuxWebSocketURL(apiURL, deviceID),
+		AuthToken:    accessToken,
+		TCPAddress:   addr,
+		Logger:       auxLogger,
+		Aux:          true,
+	})
+	if err != nil {
+		auxLogger.WithError(err).Error("Companion data channel disabled")
+		return nil
+	}
+
+	if err := b.Start(ctx); err != nil {
+		auxLogger.WithError(err).Error("Companion data channel disabled")
+		return nil
+	}
+	return b
+}
@@ -0,0 +1,30 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/pavliha/aircast/aircast-cli/internal/term"
+)
+
+// bannerLabelWidth aligns the values of banner fields
+const bannerLabelWidth = 12
+
+// bannerField prints a labelled value of the bridge banner, moving the
+// value to its own line when the terminal is too narrow for both
+func bannerField(icon, label, value string) {
+	prefix := "  " + term.Symbol(icon, "") + label + ":"
+	prefix += strings.Repeat(" ", max(bannerLabelWidth-len(label)-1, 1))
+
+	if lipgloss.Width(prefix)+lipgloss.Width(value) > term.Width() {
+		fmt.Printf("%s\n     %s\n", strings.TrimRight(prefix, " "), value)
+		return
+	}
+	fmt.Printf("%s%s\n", prefix, value)
+}
+
+// bannerNote prints a line of the bridge banner with a leading icon
+func bannerNote(icon, text string) {
+	fmt.Printf("  %s%s\n", term.Symbol(icon, ""), text)
+}
@@ -0,0 +1,209 @@
+package main
+
+import (
+	"context"
+	"encoding/binary"
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"runtime"
+	"sort"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/pavliha/aircast/aircast-cli/internal/cli"
+	"github.com/pavliha/aircast/aircast-cli/internal/mavlink"
+	log "github.com/sirupsen/logrus"
+)
+
+// benchMsgID is the message used for benchmark traffic. SYSTEM_TIME carries a
+// 64-bit timestamp field, which we fill with the send time to measure latency.
+const benchMsgID = 2
+
+// runBench measures bridge throughput and latency over loopback against a
+// local mock WebSocket server
+func runBench(args []string) error {
+	fs := flag.NewFlagSet("bench", flag.ExitOnError)
+	count := fs.Int("messages", 100000, "Number of MAVLink messages to send")
+	batch := fs.Int("batch", 1, "MAVLink frames per WebSocket message")
+	rate := fs.Int("rate", 0, "Messages per second to send (0 = as fast as possible)")
+	coalesce := fs.Duration("coalesce", 0, "Bridge TCP write coalescing interval")
+	dropCorrupt := fs.Bool("drop-corrupted", false, "Enable CRC filtering in the bridge")
+	timeout := fs.Duration("timeout", 60*time.Second, "Abort if not all messages arrive within this time")
+	_ = fs.Parse(args)
+
+	if *count <= 0 || *batch <= 0 {
+		return fmt.Errorf("messages and batch must be positive")
+	}
+
+	// Mock WebSocket server that streams benchmark frames once the GCS is connected
+	start := make(chan struct{})
+	server, wsURL, err := startBenchServer(start, *count, *batch, *rate)
+	if err != nil {
+		return err
+	}
+	defer server.Close()
+
+	logger := log.New()
+	logger.SetLevel(log.WarnLevel)
+
+	b, err := cli.New(&cli.Config{
+		WebSocketURL:     wsURL,
+		TCPAddress:       "127.0.0.1:0",
+		Logger:           logger.WithField("component", "bench"),
+		DropCorrupted:    *dropCorrupt,
+		CoalesceInterval: *coalesce,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create bridge: %w", err)
+	}
+	if err := b.Start(context.Background()); err != nil {
+		return fmt.Errorf("failed to start bridge: %w", err)
+	}
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), cli.DefaultShutdownTimeout)
+		defer cancel()
+		_ = b.Shutdown(ctx)
+	}()
+
+	conn, err := net.Dial("tcp", b.TCPAddr().String())
+	if err != nil {
+		return fmt.Errorf("failed to connect to bridge: %w", err)
+	}
+	defer conn.Close()
+
+	// Give the bridge a moment to register the client before traffic starts
+	time.Sleep(100 * time.Millisecond)
+
+	fmt.Printf("Benchmarking %d messages (batch %d)...\n", *count, *batch)
+
+	var before, after runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&before)
+
+	began := time.Now()
+	close(start)
+
+	latencies, err := receiveBench(conn, *count, *timeout)
+	elapsed := time.Since(began)
+	runtime.ReadMemStats(&after)
+	if err != nil {
+		return err
+	}
+
+	sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+	percentile := func(p float64) time.Duration {
+		return latencies[int(float64(len(latencies)-1)*p)]
+	}
+
+	mallocs := after.Mallocs - before.Mallocs
+	allocBytes := after.TotalAlloc - before.TotalAlloc
+
+	fmt.Println()
+	fmt.Printf("  Messages:     %d in %s\n", len(latencies), elapsed.Round(time.Millisecond))
+	fmt.Printf("  Throughput:   %.0f msgs/sec\n", float64(len(latencies))/elapsed.Seconds())
+	fmt.Printf("  Latency:      p50 %s, p99 %s, max %s\n",
+		percentile(0.50).Round(time.Microsecond),
+		percentile(0.99).Round(time.Microsecond),
+		latencies[len(latencies)-1].Round(time.Microsecond))
+	fmt.Printf("  Allocations:  %d (%.1f per message), %d bytes (%.0f B per message)\n",
+		mallocs, float64(mallocs)/float64(len(latencies)), allocBytes, float64(allocBytes)/float64(len(latencies)))
+	fmt.Printf("  GC cycles:    %d\n", after.NumGC-before.NumGC)
+	fmt.Println()
+	fmt.Println("  Allocation figures include the in-process mock server.")
+
+	return nil
+}
+
+// startBenchServer starts a local WebSocket server that sends count benchmark
+// frames after start is closed. It returns the server and its WebSocket URL.
+func startBenchServer(start <-chan struct{}, count, batch, rate int) (*http.Server, string, error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to start mock server: %w", err)
+	}
+
+	upgrader := websocket.Upgrader{}
+	server := &http.Server{
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			conn, err := upgrader.Upgrade(w, r, nil)
+			if err != nil {
+				return
+			}
+			defer conn.Close()
+
+			// Drain anything the bridge sends upstream
+			go func() {
+				for {
+					if _, _, err := conn.NextReader(); err != nil {
+						return
+					}
+				}
+			}()
+
+			<-start
+
+			var interval time.Duration
+			if rate > 0 {
+				interval = time.Second * time.Duration(batch) / time.Duration(rate)
+			}
+
+			payload := make([]byte, 12)
+			msg := make([]byte, 0, batch*32)
+			for sent := 0; sent < count; {
+				msg = msg[:0]
+				for i := 0; i < batch && sent < count; i++ {
+					binary.LittleEndian.PutUint64(payload, uint64(time.Now().UnixNano()))
+					frame, _ := mavlink.EncodeV2(uint8(sent), 1, 1, benchMsgID, payload)
+					msg = append(msg, frame...)
+					sent++
+				}
+				if err := conn.WriteMessage(websocket.BinaryMessage, msg); err != nil {
+					return
+				}
+				if interval > 0 {
+					time.Sleep(interval)
+				}
+			}
+
+			// Keep the connection open until the bridge hangs up
+			<-r.Context().Done()
+		}),
+	}
+
+	go func() { _ = server.Serve(listener) }()
+
+	return server, fmt.Sprintf("ws://%s/bench", listener.Addr()), nil
+}
+
+// receiveBench reads count benchmark frames from the bridge and returns their latencies
+func receiveBench(conn net.Conn, count int, timeout time.Duration) ([]time.Duration, error) {
+	_ = conn.SetReadDeadline(time.Now().Add(timeout))
+
+	parser := mavlink.NewParser()
+	latencies := make([]time.Duration, 0, count)
+	buf := make([]byte, 64*1024)
+
+	for len(latencies) < count {
+		n, err := conn.Read(buf)
+		if err != nil {
+			if err == io.EOF || len(latencies) > 0 {
+				return nil, fmt.Errorf("received %d of %d messages: %w", len(latencies), count, err)
+			}
+			return nil, fmt.Errorf("failed to read from bridge: %w", err)
+		}
+
+		now := time.Now().UnixNano()
+		for _, frame := range parser.Feed(buf[:n]) {
+			if frame.MsgID != benchMsgID || len(frame.Payload) < 8 {
+				continue
+			}
+			sent := int64(binary.LittleEndian.Uint64(frame.Payload))
+			latencies = append(latencies, time.Duration(now-sent))
+		}
+	}
+
+	return latencies, nil
+}
@@ -0,0 +1,240 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"github.com/pavliha/aircast/aircast-cli/internal/cli"
+	"github.com/pavliha/aircast/aircast-cli/internal/control"
+	"github.com/pavliha/aircast/aircast-cli/internal/recording"
+	"github.com/pavliha/aircast/aircast-cli/internal/store"
+	"github.com/pavliha/aircast/aircast-cli/internal/term"
+	log "github.com/sirupsen/logrus"
+)
+
+// command is a subcommand of the CLI
+type command struct {
+	summary string
+	run     func(args []string) error
+}
+
+// commands lists the available subcommands; running without one starts the bridge
+var commands = map[string]command{
+	"alias":             {"Name devices for use with --device (set, remove, list)", runAlias},
+	"auth":              {"Manage the account's login sessions (sessions, revoke)", runAuth},
+	"bench":             {"Measure bridge throughput and latency over loopback", runBench},
+	"clients":           {"List clients connected to a running bridge", runClients},
+	"completion":        {"Print a shell completion script (bash, zsh, fish)", runCompletion},
+	"config":            {"Copy profiles, aliases, alarms and hooks to other machines (export, import)", runConfig},
+	"connect":           {"Connect to a device and run the bridge (default)", runConnect},
+	"devices":           {"List and manage devices (list, remove)", runDevices},
+	"env":               {"Switch between API environments and their logins (use, list, set, remove)", runEnv},
+	"export":            {"Convert tlogs and recordings for analysis (csv)", runExport},
+	"export-connection": {"Write a QGroundControl or Mission Planner link config for the bridge", runExportConnection},
+	"fleet":             {"Report the status of every device for ops checks and monitoring (status)", runFleet},
+	"flights":           {"Show the flight time logbook (list, open)", runFlights},
+	"history":           {"Show past sessions with data used and loss, per session or month", runHistory},
+	"kick":              {"Disconnect a client from a running bridge", runKick},
+	"login":             {"Authenticate and store a token (optionally with a restricted scope)", runLogin},
+	"mission":           {"Review mission changes before a flight (diff)", runMission},
+	"outputs":           {"Add or remove secondary outputs of a running bridge (list, add, remove)", runOutputs},
+	"param":             {"Review parameter changes before a flight (diff)", runParam},
+	"recording":         {"Inspect and split multi-device recordings (info, split)", runRecording},
+	"setup-windows":     {"Register firewall rules, PATH and startup entry on Windows", runSetupWindows},
+	"share":             {"Create a temporary read-only link to a device's live telemetry", runShare},
+	"speedtest":         {"Measure latency and throughput over the path to a device", runSpeedtest},
+	"status":            {"Show the connection state, ports, clients and last data of a running bridge", runStatus},
+	"stop":              {"Stop the bridge started with --daemon", runStop},
+	"support-bundle":    {"Collect sanitized logs and diagnostics into a zip for support", runSupportBundle},
+	"training":          {"Simulate a lost link on a running bridge to drill operators (status, start, stop, drill)", runTraining},
+	"verify-gcs":        {"Check a ground station exchanges MAVLink both ways with a simulated vehicle", runVerifyGCS},
+}
+
+// usage prints help for the bridge flags and the available subcommands
+func usage() {
+	out := flag.CommandLine.Output()
+	fmt.Fprintf(out, "Usage: aircast-cli [flags]\n")
+	fmt.Fprintf(out, "       aircast-cli <command> [flags]\n\n")
+	fmt.Fprintf(out, "Commands:\n")
+	printCommandList(commands)
+
+	fmt.Fprintf(out, "\nGlobal flags (any command):\n")
+	printGlobalFlags()
+
+	fmt.Fprintf(out, "\nFlags:\n")
+	flag.PrintDefaults()
+}
+
+// printSubcommands prints usage for a command group such as "devices"
+func printSubcommands(group string, subcommands map[string]command) {
+	fmt.Fprintf(os.Stderr, "Usage: aircast-cli %s <command> [flags]\n\n", group)
+	fmt.Fprintf(os.Stderr, "Commands:\n")
+	printCommandList(subcommands)
+	fmt.Fprintln(os.Stderr)
+}
+
+// printCommandList prints commands and their summaries in name order
+func printCommandList(cmds map[string]command) {
+	out := flag.CommandLine.Output()
+
+	names := make([]string, 0, len(cmds))
+	for name := range cmds {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(out, "  %-18s %s\n", name, cmds[name].summary)
+	}
+}
+
+// parseArgs parses flags that may appear before or after positional
+// arguments and returns the positional arguments
+func parseArgs(fs *flag.FlagSet, args []string) []string {
+	var positional []string
+	for {
+		_ = fs.Parse(args)
+		args = fs.Args()
+		if len(args) == 0 {
+			return positional
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+}
+
+// defaultControlSocket returns the default control socket path, or "" if it can't be determined
+func defaultControlSocket() string {
+	dir, err := store.ConfigDirPath()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "control.sock")
+}
+
+// parseControlAccess builds the control socket access from its flags,
+// reading tokens given as secret references
+func parseControlAccess(mode, group, token, readToken string) (control.Access, error) {
+	access := control.Access{Mode: 0600, Group: group}
+	if group != "" {
+		access.Mode = 0660
+	}
+	if mode != "" {
+		m, err := strconv.ParseUint(mode, 8, 32)
+		if err != nil || m > 0777 || m&0600 != 0600 {
+			return access, fmt.Errorf("invalid --control-socket-mode %q: expected octal permissions the owner can read and write, e.g. 0660", mode)
+		}
+		access.Mode = os.FileMode(m)
+	}
+
+	if err := resolveSecret(&token, "Control token", true); err != nil {
+		return access, err
+	}
+	if err := resolveSecret(&readToken, "Control read token", true); err != nil {
+		return access, err
+	}
+	if readToken != "" && token == "" {
+		return access, fmt.Errorf("--control-read-token needs --control-token as well, or the read token's holders could control the bridge")
+	}
+	if token != "" && token == readToken {
+		return access, fmt.Errorf("--control-read-token must differ from --control-token")
+	}
+	access.Token, access.ReadToken = token, readToken
+	return access, nil
+}
+
+// newControlServer creates a control server exposing the bridge's management commands
+func newControlServer(path string, access control.Access, b *cli.Bridge, channel recording.ChannelInfo, folder *store.FlightFolder, logger *log.Entry) *control.Server {
+	logger = logger.WithField("component", "control")
+	if access.Open() {
+		logger.Warnf("Control socket is open to other users (mode %04o) without --control-token; they can kick clients and change outputs", access.Mode)
+	}
+	server := control.NewServer(path, access, logger)
+
+	server.Handle("clients", control.PermRead, func(req control.Request) (interface{}, error) {
+		return b.Clients(), nil
+	})
+
+	server.Handle("kick", control.PermControl, func(req control.Request) (interface{}, error) {
+		id := req.Args["client"]
+		if id == "" {
+			return nil, fmt.Errorf("missing client")
+		}
+		return nil, b.KickClient(id)
+	})
+
+	server.Handle("device", control.PermRead, func(req control.Request) (interface{}, error) {
+		return channel, nil
+	})
+
+	server.Handle("status", control.PermRead, func(req control.Request) (interface{}, error) {
+		return bridgeStatus{PID: os.Getpid(), Device: channel, BridgeStatus: b.Status()}, nil
+	})
+
+	handleOutputs(server, b, channel, folder)
+	handleTraining(server, b)
+
+	return server
+}
+
+// runClients prints the clients connected to a running bridge
+func runClients(args []string) error {
+	fs := flag.NewFlagSet("clients", flag.ExitOnError)
+	socket := fs.String("control-socket", defaultControlSocket(), "Control socket path of the running bridge")
+	_ = fs.Parse(args)
+
+	var clients []cli.ClientStats
+	if err := control.Call(*socket, "clients", nil, &clients); err != nil {
+		return err
+	}
+
+	if len(clients) == 0 {
+		fmt.Println("No clients connected")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "CLIENT\tCONNECTED\tLAST ACTIVITY\tBYTES IN\tBYTES OUT\tWRITES\tAVG WRITE\tDUPLICATES")
+	for _, c := range clients {
+		fmt.Fprintf(w, "%s\t%s\t%s ago\t%d\t%d\t%d\t%.0f B\t%d\n",
+			c.ID,
+			time.Since(c.ConnectedAt).Round(time.Second),
+			time.Since(c.LastActivity).Round(time.Second),
+			c.BytesIn,
+			c.BytesOut,
+			c.Writes,
+			c.AvgWriteSize(),
+			c.Duplicates,
+		)
+	}
+	return w.Flush()
+}
+
+// runKick disconnects a client from a running bridge
+func runKick(args []string) error {
+	fs := flag.NewFlagSet("kick", flag.ExitOnError)
+	socket := fs.String("control-socket", defaultControlSocket(), "Control socket path of the running bridge")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: aircast-cli kick [flags] <client>\n\n")
+		fmt.Fprintf(fs.Output(), "Client is an ID from 'aircast-cli clients' (e.g. tcp:127.0.0.1:50412)\n\n")
+		fs.PrintDefaults()
+	}
+	_ = fs.Parse(args)
+
+	if fs.NArg() != 1 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	if err := control.Call(*socket, "kick", map[string]string{"client": fs.Arg(0)}, nil); err != nil {
+		return err
+	}
+
+	fmt.Printf("%sKicked %s\n", term.Symbol("✓ ", ""), fs.Arg(0))
+	return nil
+}
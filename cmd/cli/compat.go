@@ -0,0 +1,64 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/pavliha/aircast/aircast-cli/internal/api"
+	"github.com/pavliha/aircast/aircast-cli/internal/term"
+	log "github.com/sirupsen/logrus"
+)
+
+// checkCompatibility warns when the API no longer supports this CLI version
+// or plans to remove endpoints it uses, so protocol changes surface at
+// startup instead of as puzzling failures later. The check never blocks the
+// bridge: an unreachable API or one without the endpoint is ignored.
+func checkCompatibility(ctx context.Context, apiURL string, logger *log.Entry) {
+	compat, err := api.CheckCompatibility(ctx, apiURL, version)
+	if errors.Is(err, api.ErrCompatUnsupported) {
+		logger.Debug("API has no compatibility endpoint, skipping check")
+		return
+	}
+	if err != nil {
+		logger.WithError(err).Debug("Compatibility check failed")
+		return
+	}
+
+	logger.WithFields(log.Fields{
+		"api_version": compat.APIVersion,
+		"min_cli":     compat.MinCLIVersion,
+		"latest_cli":  compat.LatestCLIVersion,
+	}).Debug("API compatibility")
+
+	warning := term.Symbol("⚠ ", "Warning: ")
+	printed := false
+
+	if compat.TooOld(version) {
+		logger.WithFields(log.Fields{"version": version, "min_cli": compat.MinCLIVersion}).Warn("CLI is older than the API supports")
+		fmt.Printf("%saircast-cli %s is older than the server supports (minimum %s).\n", warning, version, compat.MinCLIVersion)
+		fmt.Println("  Connections may fail or behave unexpectedly. Please update aircast-cli.")
+		printed = true
+	} else if compat.UpdateAvailable(version) {
+		logger.WithField("latest_cli", compat.LatestCLIVersion).Info("A newer CLI release is available")
+	}
+
+	deprecated := compat.DeprecatedInUse()
+	for _, d := range deprecated {
+		fields := log.Fields{"endpoint": d.Path}
+		line := fmt.Sprintf("%sThe server is retiring %s, which this version of aircast-cli uses", warning, d.Path)
+		if d.Sunset != "" {
+			fields["sunset"] = d.Sunset
+			line += " (after " + d.Sunset + ")"
+		}
+		logger.WithFields(fields).Warn("CLI uses a deprecated API endpoint")
+		fmt.Println(line + ".")
+	}
+	if len(deprecated) > 0 && !printed {
+		fmt.Println("  Update aircast-cli to keep it working.")
+	}
+	if printed || len(deprecated) > 0 {
+		fmt.Println("  Pass --skip-compat-check to hide these warnings.")
+		fmt.Println()
+	}
+}
@@ -0,0 +1,165 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/pavliha/aircast/aircast-cli/internal/auth"
+)
+
+// completeCommand is the hidden command the shell completion scripts call
+const completeCommand = "__complete"
+
+// completionScripts are the shell integration scripts, keyed by shell
+var completionScripts = map[string]string{
+	"bash": `_aircast_cli() {
+    local cur prev
+    cur="${COMP_WORDS[COMP_CWORD]}"
+    prev="${COMP_WORDS[COMP_CWORD-1]}"
+    if [[ "$prev" == "--device" || "$prev" == "-device" ]]; then
+        COMPREPLY=($(compgen -W "$(aircast-cli __complete devices 2>/dev/null)" -- "$cur"))
+    elif [[ "$prev" == "--device-name" || "$prev" == "-device-name" ]]; then
+        local IFS=$'\n'
+        COMPREPLY=($(compgen -W "$(aircast-cli __complete device-names 2>/dev/null | cut -f1)" -- "$cur"))
+        COMPREPLY=("${COMPREPLY[@]// /\\ }")
+    elif [[ $COMP_CWORD -eq 1 ]]; then
+        COMPREPLY=($(compgen -W "$(aircast-cli __complete commands 2>/dev/null)" -- "$cur"))
+    elif [[ "${COMP_WORDS[1]}" == "alias" && $COMP_CWORD -eq 2 ]]; then
+        COMPREPLY=($(compgen -W "list remove set" -- "$cur"))
+    elif [[ "${COMP_WORDS[1]} ${COMP_WORDS[2]}" == "alias remove" ]]; then
+        COMPREPLY=($(compgen -W "$(aircast-cli __complete aliases 2>/dev/null)" -- "$cur"))
+    elif [[ "${COMP_WORDS[1]} ${COMP_WORDS[2]}" == "devices remove" ]]; then
+        COMPREPLY=($(compgen -W "$(aircast-cli __complete devices 2>/dev/null)" -- "$cur"))
+    elif [[ "${COMP_WORDS[1]}" == "env" && $COMP_CWORD -eq 2 ]]; then
+        COMPREPLY=($(compgen -W "list remove set use" -- "$cur"))
+    elif [[ "${COMP_WORDS[1]}" == "env" && $COMP_CWORD -eq 3 && "${COMP_WORDS[2]}" != "list" ]]; then
+        COMPREPLY=($(compgen -W "$(aircast-cli __complete environments 2>/dev/null)" -- "$cur"))
+    fi
+}
+complete -o default -F _aircast_cli aircast-cli
+`,
+	"zsh": `#compdef aircast-cli
+_aircast_cli() {
+    if [[ "${words[CURRENT-1]}" == (--device|-device) ]]; then
+        compadd -- ${(f)"$(aircast-cli __complete devices 2>/dev/null)"}
+    elif [[ "${words[CURRENT-1]}" == (--device-name|-device-name) ]]; then
+        local -a names
+        names=(${(f)"$(aircast-cli __complete device-names 2>/dev/null | sed 's/:/\\:/g' | tr '\t' ':')"})
+        _describe 'device name' names
+    elif (( CURRENT == 2 )); then
+        compadd -- ${(f)"$(aircast-cli __complete commands 2>/dev/null)"}
+    elif [[ "${words[2]}" == alias && CURRENT -eq 3 ]]; then
+        compadd -- list remove set
+    elif [[ "${words[2]} ${words[3]}" == "alias remove" ]]; then
+        compadd -- ${(f)"$(aircast-cli __complete aliases 2>/dev/null)"}
+    elif [[ "${words[2]} ${words[3]}" == "devices remove" ]]; then
+        compadd -- ${(f)"$(aircast-cli __complete devices 2>/dev/null)"}
+    elif [[ "${words[2]}" == env && CURRENT -eq 3 ]]; then
+        compadd -- list remove set use
+    elif [[ "${words[2]}" == env && CURRENT -eq 4 && "${words[3]}" != list ]]; then
+        compadd -- ${(f)"$(aircast-cli __complete environments 2>/dev/null)"}
+    else
+        _files
+    fi
+}
+compdef _aircast_cli aircast-cli
+`,
+	"fish": `complete -c aircast-cli -f -n '__fish_is_first_arg' -a '(aircast-cli __complete commands 2>/dev/null)'
+complete -c aircast-cli -f -l device -r -a '(aircast-cli __complete devices 2>/dev/null)'
+complete -c aircast-cli -f -l device-name -r -a '(aircast-cli __complete device-names 2>/dev/null)'
+complete -c aircast-cli -f -n '__fish_seen_subcommand_from alias; and not __fish_seen_subcommand_from list remove set' -a 'list remove set'
+complete -c aircast-cli -f -n '__fish_seen_subcommand_from alias; and __fish_seen_subcommand_from remove' -a '(aircast-cli __complete aliases 2>/dev/null)'
+complete -c aircast-cli -f -n '__fish_seen_subcommand_from devices; and __fish_seen_subcommand_from remove' -a '(aircast-cli __complete devices 2>/dev/null)'
+complete -c aircast-cli -f -n '__fish_seen_subcommand_from env; and not __fish_seen_subcommand_from list remove set use' -a 'list remove set use'
+complete -c aircast-cli -f -n '__fish_seen_subcommand_from env; and __fish_seen_subcommand_from remove set use' -a '(aircast-cli __complete environments 2>/dev/null)'
+`,
+}
+
+// runCompletion prints the completion script for a shell
+func runCompletion(args []string) error {
+	if len(args) != 1 || completionScripts[args[0]] == "" {
+		fmt.Fprintf(os.Stderr, "Usage: aircast-cli completion <bash|zsh|fish>\n\n")
+		fmt.Fprintf(os.Stderr, "  bash: source <(aircast-cli completion bash)\n")
+		fmt.Fprintf(os.Stderr, "  zsh:  source <(aircast-cli completion zsh)\n")
+		fmt.Fprintf(os.Stderr, "  fish: aircast-cli completion fish | source\n")
+		os.Exit(2)
+	}
+
+	fmt.Print(completionScripts[args[0]])
+	return nil
+}
+
+// runComplete prints completion candidates, one per line. It never waits
+// for the network so completion stays instant; a stale device index is
+// refreshed by a background "refresh" for next time.
+func runComplete(args []string) {
+	if len(args) != 1 {
+		return
+	}
+	if args[0] == "refresh" {
+		refreshDeviceIndex()
+		return
+	}
+
+	var candidates []string
+	switch args[0] {
+	case "commands":
+		for name := range commands {
+			candidates = append(candidates, name)
+		}
+
+	case "aliases", "devices":
+		configStore, err := auth.NewConfigStore()
+		if err != nil {
+			return
+		}
+		if config, err := configStore.LoadConfig(); err == nil {
+			for name := range config.Aliases {
+				candidates = append(candidates, name)
+			}
+		}
+		if args[0] == "devices" {
+			candidates = append(candidates, cachedDeviceIDs()...)
+			refreshDeviceIndexInBackground()
+		}
+
+	case "environments":
+		configStore, err := auth.NewConfigStore()
+		if err != nil {
+			return
+		}
+		if config, err := configStore.LoadConfig(); err == nil {
+			for name := range config.AllEnvironments() {
+				candidates = append(candidates, name)
+			}
+		}
+
+	case "device-names":
+		candidates = deviceNameCandidates()
+		refreshDeviceIndexInBackground()
+	}
+
+	sort.Strings(candidates)
+	for _, c := range candidates {
+		fmt.Println(c)
+	}
+}
+
+// cachedDeviceIDs returns the IDs in the cached device list
+func cachedDeviceIDs() []string {
+	cache, err := auth.NewDeviceCache()
+	if err != nil {
+		return nil
+	}
+	cached, err := cache.LoadAny()
+	if err != nil || cached == nil {
+		return nil
+	}
+
+	ids := make([]string, 0, len(cached.Devices))
+	for _, d := range cached.Devices {
+		ids = append(ids, d.ID)
+	}
+	return ids
+}
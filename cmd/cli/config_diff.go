@@ -0,0 +1,202 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/pavliha/aircast/aircast-cli/internal/term"
+	"github.com/pavliha/aircast/aircast-cli/internal/vehicleconfig"
+)
+
+// paramCommands are the subcommands of "param"
+var paramCommands = map[string]command{
+	"diff": {"Compare two parameter files (Mission Planner or QGroundControl)", runParamDiff},
+}
+
+// missionCommands are the subcommands of "mission"
+var missionCommands = map[string]command{
+	"diff": {"Compare two mission files (waypoint or QGroundControl .plan)", runMissionDiff},
+}
+
+// Styles of the diff lines; the +, - and ~ markers carry the meaning without color
+var (
+	diffAddedStyle   = lipgloss.NewStyle().Foreground(lipgloss.Color("10"))
+	diffRemovedStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("9"))
+	diffChangedStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("11"))
+)
+
+// runParam dispatches "param" subcommands
+func runParam(args []string) error {
+	return runGroup("param", paramCommands, args)
+}
+
+// runMission dispatches "mission" subcommands
+func runMission(args []string) error {
+	return runGroup("mission", missionCommands, args)
+}
+
+// runGroup dispatches the subcommands of a command group
+func runGroup(group string, subcommands map[string]command, args []string) error {
+	if len(args) == 0 {
+		printSubcommands(group, subcommands)
+		os.Exit(2)
+	}
+
+	cmd, ok := subcommands[args[0]]
+	if !ok {
+		printSubcommands(group, subcommands)
+		return fmt.Errorf("unknown %s command %q", group, args[0])
+	}
+	return cmd.run(args[1:])
+}
+
+// diffOptions are the flags shared by "param diff" and "mission diff"
+type diffOptions struct {
+	json     *bool
+	exitCode *bool
+}
+
+// parseDiffArgs parses a diff command's flags and its two files
+func parseDiffArgs(name, files string, args []string) (diffOptions, string, string) {
+	fs := flag.NewFlagSet(name, flag.ExitOnError)
+	opts := diffOptions{
+		json:     fs.Bool("json", false, "Print the changes as an RFC 6902 JSON Patch"),
+		exitCode: fs.Bool("exit-code", false, "Exit with status 1 if the files differ, for pre-flight scripts"),
+	}
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: aircast-cli %s [flags] <old> <new>\n\n", name)
+		fmt.Fprintf(fs.Output(), "Shows what changes from the old to the new %s: + added, - removed, ~ changed.\n\n", files)
+		fs.PrintDefaults()
+	}
+	positional := parseArgs(fs, args)
+	if len(positional) != 2 {
+		fs.Usage()
+		os.Exit(2)
+	}
+	return opts, positional[0], positional[1]
+}
+
+// runParamDiff compares two parameter files
+func runParamDiff(args []string) error {
+	opts, oldPath, newPath := parseDiffArgs("param diff", "parameter file", args)
+
+	oldParams, err := vehicleconfig.LoadParams(oldPath)
+	if err != nil {
+		return err
+	}
+	newParams, err := vehicleconfig.LoadParams(newPath)
+	if err != nil {
+		return err
+	}
+
+	changes := vehicleconfig.DiffParams(oldParams, newParams)
+	return printDiff(changes, opts, func(c vehicleconfig.Change) string {
+		switch c.Kind {
+		case vehicleconfig.Added:
+			return fmt.Sprintf("%s = %s", c.Name, formatParam(c.New.(float64)))
+		case vehicleconfig.Removed:
+			return fmt.Sprintf("%s (was %s)", c.Name, formatParam(c.Old.(float64)))
+		}
+		return fmt.Sprintf("%s: %s %s %s", c.Name, formatParam(c.Old.(float64)), term.Symbol("→", "->"), formatParam(c.New.(float64)))
+	})
+}
+
+// runMissionDiff compares two mission files
+func runMissionDiff(args []string) error {
+	opts, oldPath, newPath := parseDiffArgs("mission diff", "mission", args)
+
+	oldMission, err := vehicleconfig.LoadMission(oldPath)
+	if err != nil {
+		return err
+	}
+	newMission, err := vehicleconfig.LoadMission(newPath)
+	if err != nil {
+		return err
+	}
+
+	changes := vehicleconfig.DiffMission(oldMission, newMission)
+	return printDiff(changes, opts, func(c vehicleconfig.Change) string {
+		switch c.Kind {
+		case vehicleconfig.Added:
+			return describeMissionItem(c.Name, c.New.(vehicleconfig.MissionItem))
+		case vehicleconfig.Removed:
+			return describeMissionItem(c.Name, c.Old.(vehicleconfig.MissionItem))
+		}
+
+		fields := make([]string, 0, len(c.Fields))
+		for _, f := range c.Fields {
+			from, to := formatParam(f.Old), formatParam(f.New)
+			if f.Name == "command" {
+				from, to = vehicleconfig.CommandName(uint16(f.Old)), vehicleconfig.CommandName(uint16(f.New))
+			}
+			fields = append(fields, fmt.Sprintf("%s %s %s %s", f.Name, from, term.Symbol("→", "->"), to))
+		}
+		item := c.New.(vehicleconfig.MissionItem)
+		return fmt.Sprintf("#%s %s: %s", c.Name, vehicleconfig.CommandName(item.Command), strings.Join(fields, ", "))
+	})
+}
+
+// printDiff prints changes as colored lines, or as a JSON Patch with --json
+func printDiff(changes []vehicleconfig.Change, opts diffOptions, describe func(vehicleconfig.Change) string) error {
+	if *opts.json {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(vehicleconfig.Patch(changes)); err != nil {
+			return err
+		}
+	} else {
+		printDiffLines(changes, describe)
+	}
+
+	if *opts.exitCode && len(changes) > 0 {
+		os.Exit(1)
+	}
+	return nil
+}
+
+// printDiffLines prints a line per change and a count of each kind
+func printDiffLines(changes []vehicleconfig.Change, describe func(vehicleconfig.Change) string) {
+	if len(changes) == 0 {
+		fmt.Println("No differences")
+		return
+	}
+
+	counts := map[vehicleconfig.ChangeKind]int{}
+	for _, c := range changes {
+		counts[c.Kind]++
+		switch c.Kind {
+		case vehicleconfig.Added:
+			fmt.Println(diffAddedStyle.Render("+ " + describe(c)))
+		case vehicleconfig.Removed:
+			fmt.Println(diffRemovedStyle.Render("- " + describe(c)))
+		default:
+			fmt.Println(diffChangedStyle.Render("~ " + describe(c)))
+		}
+	}
+	fmt.Printf("\n%d changed, %d added, %d removed\n",
+		counts[vehicleconfig.Changed], counts[vehicleconfig.Added], counts[vehicleconfig.Removed])
+}
+
+// describeMissionItem summarizes an added or removed mission item
+func describeMissionItem(index string, item vehicleconfig.MissionItem) string {
+	desc := fmt.Sprintf("#%s %s", index, vehicleconfig.CommandName(item.Command))
+	lat, lon, alt := item.Params[4], item.Params[5], item.Params[6]
+	if !math.IsNaN(lat) && !math.IsNaN(lon) && (lat != 0 || lon != 0) {
+		desc += fmt.Sprintf(" at %.7f, %.7f, %s m", lat, lon, formatParam(alt))
+	}
+	return desc
+}
+
+// formatParam formats a value at the autopilot's single precision, "unset" for NaN
+func formatParam(v float64) string {
+	if math.IsNaN(v) {
+		return "unset"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 32)
+}
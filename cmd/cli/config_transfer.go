@@ -0,0 +1,350 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pavliha/aircast/aircast-cli/internal/auth"
+	"github.com/pavliha/aircast/aircast-cli/internal/term"
+	"gopkg.in/yaml.v3"
+)
+
+// configCommands are the subcommands of "config"
+var configCommands = map[string]command{
+	"export": {"Write the configuration (profiles, aliases, alarms, sinks) without secrets to a file", runConfigExport},
+	"import": {"Apply a configuration exported on another machine", runConfigImport},
+}
+
+// runConfig dispatches "config" subcommands
+func runConfig(args []string) error {
+	return runGroup("config", configCommands, args)
+}
+
+// configFormat picks the export format: the --format flag, or the output
+// file's extension, YAML otherwise
+func configFormat(format, path string) (string, error) {
+	if format == "" {
+		if strings.EqualFold(filepath.Ext(path), ".json") {
+			return "json", nil
+		}
+		return "yaml", nil
+	}
+	if format != "yaml" && format != "json" {
+		return "", fmt.Errorf("unknown format %q (expected yaml or json)", format)
+	}
+	return format, nil
+}
+
+// marshalConfig encodes a config as JSON or YAML. YAML goes through JSON so
+// both use the config file's field names.
+func marshalConfig(config *auth.Config, format string) ([]byte, error) {
+	data, err := json.MarshalIndent(config, "", "  ")
+	if err != nil || format == "json" {
+		return append(data, '\n'), err
+	}
+
+	var generic any
+	if err := json.Unmarshal(data, &generic); err != nil {
+		return nil, err
+	}
+	dropNulls(generic)
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "# aircast-cli configuration exported %s; secrets are not included\n", time.Now().Format("2006-01-02"))
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(generic); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), enc.Close()
+}
+
+// dropNulls removes null fields from decoded JSON, which would otherwise
+// show up as "field: null" lines in YAML
+func dropNulls(v any) {
+	switch v := v.(type) {
+	case map[string]any:
+		for k, item := range v {
+			if item == nil {
+				delete(v, k)
+				continue
+			}
+			dropNulls(item)
+		}
+	case []any:
+		for _, item := range v {
+			dropNulls(item)
+		}
+	}
+}
+
+// unmarshalConfig decodes an exported config. YAML is a superset of JSON,
+// so both are read the same way; unknown fields are rejected to catch typos.
+func unmarshalConfig(data []byte) (*auth.Config, error) {
+	var generic any
+	if err := yaml.Unmarshal(data, &generic); err != nil {
+		return nil, err
+	}
+	if generic == nil {
+		return &auth.Config{}, nil
+	}
+	if _, ok := generic.(map[string]any); !ok {
+		return nil, fmt.Errorf("expected a mapping of configuration sections")
+	}
+
+	data, err := json.Marshal(generic)
+	if err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	var config auth.Config
+	if err := dec.Decode(&config); err != nil {
+		return nil, err
+	}
+	return &config, nil
+}
+
+// runConfigExport writes the portable part of the configuration, for
+// setting up other ground station laptops the same way
+func runConfigExport(args []string) error {
+	fs := flag.NewFlagSet("config export", flag.ExitOnError)
+	output := fs.String("output", "-", "File to write, - for standard output")
+	format := fs.String("format", "", "Output format: yaml or json (default: from the --output extension, otherwise yaml)")
+	_ = fs.Parse(args)
+
+	outFormat, err := configFormat(*format, *output)
+	if err != nil {
+		return err
+	}
+
+	configStore, err := auth.NewConfigStore()
+	if err != nil {
+		return err
+	}
+	config, err := configStore.LoadConfig()
+	if err != nil {
+		return err
+	}
+
+	portable, removed := config.Portable()
+	data, err := marshalConfig(portable, outFormat)
+	if err != nil {
+		return fmt.Errorf("failed to encode configuration: %w", err)
+	}
+
+	if *output == "-" {
+		_, err = os.Stdout.Write(data)
+	} else {
+		// Database hosts and webhook targets are still worth keeping private
+		err = os.WriteFile(*output, data, 0600)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write configuration: %w", err)
+	}
+
+	if *output != "-" {
+		fmt.Fprintf(os.Stderr, "%sConfiguration exported to %s\n", term.Symbol("✓ ", ""), *output)
+	}
+	if len(removed) > 0 {
+		fmt.Fprintf(os.Stderr, "Secrets left out, set them on each machine: %s\n", strings.Join(removed, ", "))
+	}
+	if commands := hookCommands(config); len(commands) > 0 {
+		fmt.Fprintf(os.Stderr, "Commands left out, set them on each machine: %s\n", strings.Join(commands, ", "))
+	}
+	return nil
+}
+
+// hookCommands lists the settings of config that name commands to run,
+// which are never exported or imported
+func hookCommands(config *auth.Config) []string {
+	var commands []string
+	if config.AlarmHook != "" {
+		commands = append(commands, "alarm_hook")
+	}
+	if config.CredentialHelper != "" {
+		commands = append(commands, "credential_helper")
+	}
+	return commands
+}
+
+// runConfigImport applies an exported configuration, merging it with the
+// local one unless --replace is given
+func runConfigImport(args []string) error {
+	fs := flag.NewFlagSet("config import", flag.ExitOnError)
+	replace := fs.Bool("replace", false, "Replace profiles, aliases, alarms, remap rules, sinks and hooks instead of merging")
+	g := guardFlags(fs)
+	fs.Lookup("dry-run").Usage = "Show what would change without changing it"
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: aircast-cli config import [flags] <file|->\n\n")
+		fs.PrintDefaults()
+	}
+
+	positional := parseArgs(fs, args)
+	if len(positional) != 1 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	var data []byte
+	var err error
+	if positional[0] == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(positional[0])
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read configuration: %w", err)
+	}
+	imported, err := unmarshalConfig(data)
+	if err != nil {
+		return fmt.Errorf("invalid configuration in %s: %w", positional[0], err)
+	}
+	for name := range imported.Aliases {
+		if err := auth.ValidateAlias(name); err != nil {
+			return err
+		}
+	}
+
+	configStore, err := auth.NewConfigStore()
+	if err != nil {
+		return err
+	}
+	current, err := configStore.LoadConfig()
+	if err != nil {
+		return err
+	}
+
+	if commands := hookCommands(imported); len(commands) > 0 {
+		fmt.Printf("Ignoring %s: commands are never imported, set them in %s on this machine\n",
+			strings.Join(commands, ", "), configStore.GetConfigPath())
+	}
+
+	// Preview on a copy; the config file is only written once confirmed
+	preview, err := cloneConfig(current)
+	if err != nil {
+		return err
+	}
+	for _, target := range preview.Import(imported, *replace) {
+		fmt.Printf("Skipping the webhook to %s: the export left out its URL; add it in %s on this machine\n",
+			target, configStore.GetConfigPath())
+	}
+	changes := configChanges(current, preview)
+	if len(changes) == 0 {
+		fmt.Println("Nothing to import: the configuration already matches")
+		return nil
+	}
+
+	fmt.Println("Changes to", configStore.GetConfigPath()+":")
+	for _, change := range changes {
+		fmt.Printf("  %s\n", change)
+	}
+	if *g.dryRun {
+		fmt.Println("Dry run: nothing was changed")
+		return nil
+	}
+	if !g.confirm("Apply these changes?") {
+		fmt.Println("Aborted")
+		return nil
+	}
+
+	if err := configStore.ImportConfig(imported, *replace); err != nil {
+		return err
+	}
+	fmt.Printf("%sConfiguration imported\n", term.Symbol("✓ ", ""))
+	return nil
+}
+
+// cloneConfig deep-copies a config
+func cloneConfig(config *auth.Config) (*auth.Config, error) {
+	data, err := json.Marshal(config)
+	if err != nil {
+		return nil, err
+	}
+	var clone auth.Config
+	return &clone, json.Unmarshal(data, &clone)
+}
+
+// configChanges describes the differences between two configs per section,
+// e.g. "bandwidth profiles: + survey, ~ low-bandwidth"
+func configChanges(before, after *auth.Config) []string {
+	var changes []string
+	add := func(section string, parts []string) {
+		if len(parts) > 0 {
+			changes = append(changes, section+": "+strings.Join(parts, ", "))
+		}
+	}
+
+	add("bandwidth profiles", mapChanges(before.BandwidthProfiles, after.BandwidthProfiles))
+	add("aliases", mapChanges(before.Aliases, after.Aliases))
+	add("environments", mapChanges(before.Environments, after.Environments))
+	add("alarms", listChanges(before.Alarms, after.Alarms))
+	add("remap rules", listChanges(before.Remap, after.Remap))
+	add("log redaction", listChanges(before.LogRedact, after.LogRedact))
+	if !jsonEqual(before.TelemetrySinks, after.TelemetrySinks) {
+		changes = append(changes, fmt.Sprintf("telemetry sinks: %d, was %d", len(after.TelemetrySinks), len(before.TelemetrySinks)))
+	}
+	if !jsonEqual(before.Webhooks, after.Webhooks) {
+		changes = append(changes, fmt.Sprintf("webhooks: %d, was %d", len(after.Webhooks), len(before.Webhooks)))
+	}
+	if !jsonEqual(before.OAuthClient, after.OAuthClient) {
+		changes = append(changes, "OAuth client: updated")
+	}
+	return changes
+}
+
+// mapChanges lists added (+), changed (~) and removed (-) keys
+func mapChanges[V any](before, after map[string]V) []string {
+	var parts []string
+	keys := slices.Collect(maps.Keys(after))
+	sort.Strings(keys)
+	for _, k := range keys {
+		old, ok := before[k]
+		switch {
+		case !ok:
+			parts = append(parts, "+ "+k)
+		case !jsonEqual(old, after[k]):
+			parts = append(parts, "~ "+k)
+		}
+	}
+	keys = slices.Collect(maps.Keys(before))
+	sort.Strings(keys)
+	for _, k := range keys {
+		if _, ok := after[k]; !ok {
+			parts = append(parts, "- "+k)
+		}
+	}
+	return parts
+}
+
+// listChanges lists added (+) and removed (-) entries
+func listChanges(before, after []string) []string {
+	var parts []string
+	for _, item := range after {
+		if !slices.Contains(before, item) {
+			parts = append(parts, "+ "+item)
+		}
+	}
+	for _, item := range before {
+		if !slices.Contains(after, item) {
+			parts = append(parts, "- "+item)
+		}
+	}
+	return parts
+}
+
+// jsonEqual compares two values by their JSON encoding
+func jsonEqual(a, b any) bool {
+	dataA, errA := json.Marshal(a)
+	dataB, errB := json.Marshal(b)
+	return errA == nil && errB == nil && bytes.Equal(dataA, dataB)
+}
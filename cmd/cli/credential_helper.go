@@ -0,0 +1,91 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/pavliha/aircast/aircast-cli/internal/auth"
+	log "github.com/sirupsen/logrus"
+)
+
+// helperLoginTimeout bounds a login through the credential helper, which
+// may wait for the user to finish signing in to the SSO broker in a browser
+const helperLoginTimeout = 5 * time.Minute
+
+// credentialHelper returns the command that supplies tokens instead of the
+// OAuth flows: AIRCAST_CREDENTIAL_HELPER, or credential_helper from
+// config.json. Empty means the built-in OAuth login is used.
+func credentialHelper() string {
+	if v := os.Getenv("AIRCAST_CREDENTIAL_HELPER"); v != "" {
+		return v
+	}
+	if configStore, err := auth.NewConfigStore(); err == nil {
+		if config, err := configStore.LoadConfig(); err == nil {
+			return config.CredentialHelper
+		}
+	}
+	return ""
+}
+
+// runCredentialHelper runs the credential helper through the shell and
+// returns the token it prints. For a login it shares the terminal, so it can
+// prompt or show a sign-in link; for a refresh its messages are only logged,
+// and ctx bounds how long it may take.
+func runCredentialHelper(ctx context.Context, helper, apiURL, scope, reason string, tokenStore *auth.TokenStore, logger *log.Entry) (*auth.TokenResponse, error) {
+	if reason == auth.HelperLogin {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, helperLoginTimeout)
+		defer cancel()
+	}
+
+	cmd := shellCommand(ctx, helper)
+	cmd.Env = append(os.Environ(),
+		"AIRCAST_API_URL="+apiURL,
+		"AIRCAST_ENVIRONMENT="+auth.DetectEnvironment(apiURL),
+		"AIRCAST_SCOPE="+scope,
+		"AIRCAST_CREDENTIAL_REASON="+reason,
+	)
+	var stderr bytes.Buffer
+	if reason == auth.HelperLogin {
+		cmd.Stdin = os.Stdin
+		cmd.Stderr = os.Stderr
+	} else {
+		cmd.Stderr = &stderr
+	}
+
+	logger.WithFields(log.Fields{"helper": helper, "reason": reason}).Debug("Running credential helper")
+	out, err := cmd.Output()
+	if msg := strings.TrimSpace(stderr.String()); msg != "" {
+		logger.WithField("output", msg).Debug("Credential helper messages")
+	}
+	if err != nil {
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return nil, fmt.Errorf("credential helper %q did not finish in time", helper)
+		}
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("credential helper %q failed: %w: %s", helper, err, msg)
+		}
+		return nil, fmt.Errorf("credential helper %q failed: %w", helper, err)
+	}
+
+	return auth.ParseHelperCredential(out, tokenStore.Now())
+}
+
+// authenticateHelper logs in with the credential helper and stores the token
+func authenticateHelper(ctx context.Context, helper, apiURL, scope string, tokenStore *auth.TokenStore, logger *log.Entry) (string, error) {
+	logger = logger.WithField("component", "auth")
+	fmt.Println("Logging in with the credential helper...")
+
+	token, err := runCredentialHelper(ctx, helper, apiURL, scope, auth.HelperLogin, tokenStore, logger)
+	if err != nil {
+		return "", err
+	}
+
+	storeToken(token, apiURL, tokenStore, logger)
+	return token.AccessToken, nil
+}
@@ -0,0 +1,163 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pavliha/aircast/aircast-cli/internal/store"
+	"github.com/pavliha/aircast/aircast-cli/internal/term"
+)
+
+// daemonChildEnv marks the background process started by --daemon, so it
+// runs the bridge instead of detaching again
+const daemonChildEnv = "AIRCAST_DAEMON_CHILD"
+
+// Files in the config directory used by --daemon unless overridden
+const (
+	daemonPIDName = "aircast.pid"
+	daemonLogName = "aircast.log"
+)
+
+// daemonStartTimeout bounds how long --daemon waits for the background
+// bridge to accept connections before returning anyway
+const daemonStartTimeout = 2 * time.Minute
+
+// errNotRunning is returned when the PID file names no running bridge
+var errNotRunning = errors.New("no bridge is running in the background")
+
+// daemonChild reports whether this process is the background bridge
+func daemonChild() bool {
+	return os.Getenv(daemonChildEnv) != ""
+}
+
+// defaultPIDFile returns the default PID file path, or "" if it can't be determined
+func defaultPIDFile() string {
+	return configFile(daemonPIDName)
+}
+
+// defaultDaemonLog returns the default log file of a background bridge
+func defaultDaemonLog() string {
+	return configFile(daemonLogName)
+}
+
+// configFile returns the path of name in the config directory, or "" if
+// the directory can't be determined
+func configFile(name string) string {
+	dir, err := store.ConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, name)
+}
+
+// writePIDFile records this process's PID
+func writePIDFile(path string) error {
+	return os.WriteFile(path, []byte(strconv.Itoa(os.Getpid())+"\n"), 0644)
+}
+
+// removePIDFile removes the PID file if it still names this process, so a
+// newer bridge's file is left alone
+func removePIDFile(path string) {
+	if pid, err := readPIDFile(path); err == nil && pid == os.Getpid() {
+		_ = os.Remove(path)
+	}
+}
+
+// readPIDFile returns the PID recorded in path
+func readPIDFile(path string) (int, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+	pid, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil || pid <= 0 {
+		return 0, fmt.Errorf("invalid PID file %s", path)
+	}
+	return pid, nil
+}
+
+// runningPID returns the PID of the background bridge. A PID file left
+// behind by a bridge that has exited is removed, also when its PID now
+// belongs to another process.
+func runningPID(path string) (int, error) {
+	pid, err := readPIDFile(path)
+	if os.IsNotExist(err) {
+		return 0, errNotRunning
+	}
+	if err != nil {
+		return 0, err
+	}
+	if !daemonProcess(pid) {
+		_ = os.Remove(path)
+		return 0, errNotRunning
+	}
+	return pid, nil
+}
+
+// waitForReady waits for the READY line the background bridge prints once
+// it accepts connections. ready is false if the bridge exited first.
+func waitForReady(scanner *bufio.Scanner, timeout time.Duration) (line string, ready bool, timedOut bool) {
+	lines := make(chan string, 1)
+	go func() {
+		defer close(lines)
+		if scanner.Scan() {
+			lines <- scanner.Text()
+		}
+	}()
+
+	select {
+	case l, ok := <-lines:
+		return l, ok && strings.HasPrefix(l, "READY"), false
+	case <-time.After(timeout):
+		return "", false, true
+	}
+}
+
+// tailFile returns up to n trailing lines of a file
+func tailFile(path string, n int) []string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	lines := strings.Split(strings.TrimRight(string(data), "\n"), "\n")
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	return lines
+}
+
+// runStop stops the bridge started with --daemon
+func runStop(args []string) error {
+	fs := flag.NewFlagSet("stop", flag.ExitOnError)
+	pidFile := fs.String("pid-file", getEnv("AIRCAST_PID_FILE", defaultPIDFile()), "PID file of the background bridge")
+	timeout := fs.Duration("timeout", 15*time.Second, "How long to wait for the bridge to exit")
+	_ = fs.Parse(args)
+
+	pid, err := runningPID(*pidFile)
+	if err != nil {
+		return err
+	}
+
+	if err := terminateProcess(pid); err != nil {
+		return fmt.Errorf("failed to stop bridge (PID %d): %w", pid, err)
+	}
+
+	deadline := time.Now().Add(*timeout)
+	for processAlive(pid) {
+		if time.Now().After(deadline) {
+			return fmt.Errorf("bridge (PID %d) is still shutting down after %s", pid, *timeout)
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	_ = os.Remove(*pidFile)
+
+	fmt.Printf("%sStopped bridge (PID %d)\n", term.Symbol("✓ ", ""), pid)
+	return nil
+}
@@ -0,0 +1,126 @@
+//go:build !windows
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"github.com/pavliha/aircast/aircast-cli/internal/term"
+)
+
+// startDaemon re-runs the bridge as a background process in its own
+// session, with its log in logFile, and returns once it accepts
+// connections. The child runs in quiet mode, so it needs a stored login and
+// a device just like --quiet; if it exits early, the end of its log is shown.
+func startDaemon(args []string, logFile, pidFile string, explicitLevel bool) error {
+	if pid, err := runningPID(pidFile); err == nil {
+		return fmt.Errorf("a bridge is already running in the background (PID %d), stop it with 'aircast-cli stop'", pid)
+	}
+
+	exe, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("failed to locate executable: %w", err)
+	}
+
+	if logFile == "" {
+		logFile = defaultDaemonLog()
+	}
+	logOut, err := os.OpenFile(logFile, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open log file: %w", err)
+	}
+	defer logOut.Close()
+
+	// Quiet mode logs only errors unless a level is given
+	childArgs := append(args[:len(args):len(args)], "--quiet")
+	if !explicitLevel {
+		childArgs = append(childArgs, "--log-level=info")
+	}
+
+	cmd := exec.Command(exe, childArgs...)
+	cmd.Env = append(os.Environ(), daemonChildEnv+"=1", "AIRCAST_PID_FILE="+pidFile)
+	cmd.Stderr = logOut
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setsid: true}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to start background bridge: %w", err)
+	}
+
+	line, ready, timedOut := waitForReady(bufio.NewScanner(stdout), daemonStartTimeout)
+	pid := cmd.Process.Pid
+
+	switch {
+	case ready:
+		_ = cmd.Process.Release()
+		fmt.Printf("%sBridge running in the background (PID %d)\n", term.Symbol("✓ ", ""), pid)
+		fmt.Printf("  %s\n", line)
+	case timedOut:
+		_ = cmd.Process.Release()
+		fmt.Printf("%sBridge started in the background (PID %d) but isn't accepting connections yet\n", term.Symbol("⚠ ", "Warning: "), pid)
+	default:
+		_ = cmd.Wait()
+		_ = os.Remove(pidFile)
+		fmt.Fprintf(os.Stderr, "Background bridge exited during startup. End of %s:\n", logFile)
+		for _, l := range tailFile(logFile, 5) {
+			fmt.Fprintf(os.Stderr, "  %s\n", l)
+		}
+		return fmt.Errorf("bridge failed to start")
+	}
+
+	fmt.Printf("  Log: %s\n", logFile)
+	fmt.Printf("  PID file: %s\n", pidFile)
+	fmt.Println("  Stop it with 'aircast-cli stop'")
+	return nil
+}
+
+// processAlive reports whether a process with this PID exists
+func processAlive(pid int) bool {
+	err := syscall.Kill(pid, 0)
+	return err == nil || err == syscall.EPERM
+}
+
+// daemonProcess reports whether pid is a running background bridge rather
+// than an unrelated process that reused the PID of one that died. On Linux
+// the process must have been started by --daemon; where its environment
+// can't be read, e.g. on macOS, it must at least run this executable.
+func daemonProcess(pid int) bool {
+	if !processAlive(pid) {
+		return false
+	}
+	if environ, err := os.ReadFile(fmt.Sprintf("/proc/%d/environ", pid)); err == nil {
+		return bytes.Contains(append([]byte{0}, environ...), []byte("\x00"+daemonChildEnv+"=1\x00"))
+	}
+
+	exe, err := os.Executable()
+	if err != nil {
+		return false
+	}
+	out, err := exec.Command("ps", "-p", strconv.Itoa(pid), "-o", "comm=").Output()
+	if err != nil {
+		return false
+	}
+	return filepath.Base(strings.TrimSpace(string(out))) == filepath.Base(exe)
+}
+
+// terminateProcess asks a process to shut down gracefully
+func terminateProcess(pid int) error {
+	return syscall.Kill(pid, syscall.SIGTERM)
+}
+
+// detachProcess makes cmd run in its own session, so it outlives the shell
+// or terminal that started it
+func detachProcess(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setsid: true}
+}
@@ -0,0 +1,38 @@
+//go:build windows
+
+package main
+
+import (
+	"fmt"
+	"os/exec"
+	"syscall"
+)
+
+// startDaemon is only supported on Unix; on Windows, setup-windows --startup
+// starts the bridge at logon instead
+func startDaemon(args []string, logFile, pidFile string, explicitLevel bool) error {
+	return fmt.Errorf("--daemon is only available on Linux and macOS (use 'aircast-cli setup-windows --startup' on Windows)")
+}
+
+// processAlive reports whether a process with this PID exists. Background
+// bridges don't exist on Windows, so PID files are always stale.
+func processAlive(pid int) bool {
+	return false
+}
+
+// daemonProcess reports whether pid is a background bridge, which never
+// exist on Windows
+func daemonProcess(pid int) bool {
+	return false
+}
+
+// terminateProcess is only supported on Unix
+func terminateProcess(pid int) error {
+	return fmt.Errorf("not supported on Windows")
+}
+
+// detachProcess makes cmd run in its own process group without a console
+// window, so it outlives the terminal that started it
+func detachProcess(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{CreationFlags: syscall.CREATE_NEW_PROCESS_GROUP, HideWindow: true}
+}
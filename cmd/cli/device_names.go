@@ -0,0 +1,178 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pavliha/aircast/aircast-cli/internal/api"
+	"github.com/pavliha/aircast/aircast-cli/internal/auth"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// deviceIndexStale is the age from which names resolved from the cache
+	// are shown with how old they are
+	deviceIndexStale = 24 * time.Hour
+
+	// deviceIndexRefresh is the age from which completion refreshes the
+	// cached names in the background for next time
+	deviceIndexRefresh = time.Hour
+
+	// deviceIndexRefreshTimeout bounds a background refresh
+	deviceIndexRefreshTimeout = 15 * time.Second
+)
+
+// indexDevices makes a client record the device names in every device list
+// it fetches from apiURL in the device cache
+func indexDevices(client *api.Client, apiURL string) *api.Client {
+	client.OnDevices = func(devices []api.Device) {
+		cache, err := auth.NewDeviceCache()
+		if err == nil {
+			err = cache.UpdateNames(apiURL, devices)
+		}
+		if err != nil {
+			log.WithError(err).Debug("Failed to update device names")
+		}
+	}
+	return client
+}
+
+// resolveDeviceName returns the ID of the device named name in the apiURL
+// environment from the device cache, without calling the API. Names match
+// case-insensitively. It also returns how long ago the API last listed the
+// device.
+func resolveDeviceName(name, apiURL string) (string, time.Duration, error) {
+	cache, err := auth.NewDeviceCache()
+	if err != nil {
+		return "", 0, err
+	}
+	devices, err := cache.Names()
+	if err != nil {
+		return "", 0, err
+	}
+
+	var matches, elsewhere []auth.IndexedDevice
+	for _, d := range devices {
+		if !strings.EqualFold(d.Name, name) {
+			continue
+		}
+		if d.APIURL == apiURL {
+			matches = append(matches, d)
+		} else {
+			elsewhere = append(elsewhere, d)
+		}
+	}
+
+	switch {
+	case len(matches) == 1:
+		return matches[0].ID, time.Since(matches[0].SeenAt), nil
+	case len(matches) > 1:
+		ids := make([]string, len(matches))
+		for i, d := range matches {
+			ids[i] = d.ID
+		}
+		return "", 0, fmt.Errorf("%d devices are named %q (%s); pass --device with the ID instead", len(matches), name, strings.Join(ids, ", "))
+	case len(elsewhere) > 0:
+		d := elsewhere[0]
+		return "", 0, fmt.Errorf("device %q belongs to the %s environment (%s); rerun with --api %s", name, auth.DetectEnvironment(d.APIURL), d.APIURL, d.APIURL)
+	}
+	return "", 0, fmt.Errorf("no device named %q is known for %s; run 'aircast-cli devices' to refresh the device names", name, apiURL)
+}
+
+// deviceNameCandidates returns the indexed device names for completion,
+// each followed by a tab and a description: the device ID, and its age if
+// the name hasn't been confirmed by the API for a while
+func deviceNameCandidates() []string {
+	cache, err := auth.NewDeviceCache()
+	if err != nil {
+		return nil
+	}
+	devices, err := cache.Names()
+	if err != nil {
+		return nil
+	}
+
+	var candidates []string
+	for _, d := range devices {
+		desc := d.ID
+		if age := time.Since(d.SeenAt); age >= deviceIndexStale {
+			desc += fmt.Sprintf(" (%s, seen %s ago)", auth.DetectEnvironment(d.APIURL), formatAge(age))
+		} else {
+			desc += fmt.Sprintf(" (%s)", auth.DetectEnvironment(d.APIURL))
+		}
+		candidates = append(candidates, d.Name+"\t"+desc)
+	}
+	sort.Strings(candidates)
+	return candidates
+}
+
+// formatAge formats a long duration in its largest unit, e.g. "3d" or "5h"
+func formatAge(d time.Duration) string {
+	switch {
+	case d >= 24*time.Hour:
+		return fmt.Sprintf("%dd", int(d/(24*time.Hour)))
+	case d >= time.Hour:
+		return fmt.Sprintf("%dh", int(d/time.Hour))
+	}
+	return fmt.Sprintf("%dm", int(d/time.Minute))
+}
+
+// refreshDeviceIndexInBackground starts a detached "__complete refresh" if
+// the cached device names are older than deviceIndexRefresh, so the next
+// completion has current names while this one returns at once with what is
+// cached
+func refreshDeviceIndexInBackground() {
+	cache, err := auth.NewDeviceCache()
+	if err != nil {
+		return
+	}
+	devices, err := cache.Names()
+	if err != nil {
+		return
+	}
+	var newest time.Time
+	for _, d := range devices {
+		if d.SeenAt.After(newest) {
+			newest = d.SeenAt
+		}
+	}
+	if time.Since(newest) < deviceIndexRefresh {
+		return
+	}
+	if start, err := cache.StartRefresh(deviceIndexRefresh); err != nil || !start {
+		return
+	}
+
+	exe, err := os.Executable()
+	if err != nil {
+		return
+	}
+	cmd := exec.Command(exe, completeCommand, "refresh")
+	detachProcess(cmd)
+	if err := cmd.Start(); err == nil {
+		_ = cmd.Process.Release()
+	}
+}
+
+// refreshDeviceIndex fetches the device list with the stored login to
+// update the cached device names. It fails quietly offline or when logged
+// out, since nobody is waiting for it.
+func refreshDeviceIndex() {
+	tokenStore, err := auth.NewTokenStore()
+	if err != nil {
+		return
+	}
+	token, err := tokenStore.LoadToken()
+	if err != nil || token == nil || token.APIURL == "" || !tokenStore.IsTokenValid(token) {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), deviceIndexRefreshTimeout)
+	defer cancel()
+	_, _ = indexDevices(api.NewClient(token.APIURL, token.AccessToken), token.APIURL).GetDevices(ctx)
+}
@@ -0,0 +1,147 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"github.com/pavliha/aircast/aircast-cli/internal/api"
+	"github.com/pavliha/aircast/aircast-cli/internal/term"
+)
+
+// deviceTagCommands are the subcommands of "devices tag"
+var deviceTagCommands = map[string]command{
+	"add":    {"Add tags to a device", runDeviceTagAdd},
+	"remove": {"Remove tags from a device", runDeviceTagRemove},
+	"list":   {"Show a device's tags", runDeviceTagList},
+}
+
+// runDevicesTag dispatches "devices tag" subcommands
+func runDevicesTag(args []string) error {
+	return runGroup("devices tag", deviceTagCommands, args)
+}
+
+// tagFlag adds the --tag filter to a command's flags
+func tagFlag(fs *flag.FlagSet) *string {
+	return fs.String("tag", getEnv("AIRCAST_TAG", ""), "Only include devices with these tags, comma-separated; all must match (env AIRCAST_TAG)")
+}
+
+// filterDevices applies a --tag filter, reporting when it leaves no devices
+func filterDevices(devices []api.Device, tagList string) ([]api.Device, error) {
+	tags := splitList(tagList)
+	filtered := api.FilterByTags(devices, tags)
+	if len(tags) > 0 && len(devices) > 0 && len(filtered) == 0 {
+		return nil, fmt.Errorf("no devices tagged %s", strings.Join(tags, ", "))
+	}
+	return filtered, nil
+}
+
+// formatTags formats a device's tags for a table cell
+func formatTags(tags []string) string {
+	if len(tags) == 0 {
+		return "-"
+	}
+	return strings.Join(tags, ",")
+}
+
+// parseTagArgs parses a "devices tag" command's device and tags. Commands
+// taking tags change the device and get the flags of g.
+func parseTagArgs(name string, args []string, needTags bool, guardFlags func(*flag.FlagSet) *guard) (apiURL, deviceID string, tags []string, g *guard) {
+	fs := flag.NewFlagSet("devices tag "+name, flag.ExitOnError)
+	api := fs.String("api", defaultAPIURL(), "API base URL")
+	if guardFlags != nil {
+		g = guardFlags(fs)
+	}
+	fs.Usage = func() {
+		if needTags {
+			fmt.Fprintf(fs.Output(), "Usage: aircast-cli devices tag %s [flags] <device-id|alias> <tag>...\n\n", name)
+		} else {
+			fmt.Fprintf(fs.Output(), "Usage: aircast-cli devices tag %s [flags] <device-id|alias>\n\n", name)
+		}
+		fs.PrintDefaults()
+	}
+	positional := parseArgs(fs, args)
+	if len(positional) == 0 || (needTags && len(positional) < 2) || (!needTags && len(positional) != 1) {
+		fs.Usage()
+		os.Exit(2)
+	}
+	return *api, positional[0], positional[1:], g
+}
+
+// runDeviceTagAdd adds tags to a device
+func runDeviceTagAdd(args []string) error {
+	apiURL, device, tags, g := parseTagArgs("add", args, true, dryRunFlag)
+	return updateDeviceTags(apiURL, device, g, func(ctx context.Context, client *api.Client, deviceID string) ([]string, error) {
+		return client.AddDeviceTags(ctx, deviceID, tags)
+	})
+}
+
+// runDeviceTagRemove removes tags from a device after confirmation
+func runDeviceTagRemove(args []string) error {
+	apiURL, device, tags, g := parseTagArgs("remove", args, true, guardFlags)
+	if !g.confirm(fmt.Sprintf("Remove %s from %s?", strings.Join(tags, ", "), device)) {
+		fmt.Println("Aborted")
+		return nil
+	}
+	return updateDeviceTags(apiURL, device, g, func(ctx context.Context, client *api.Client, deviceID string) ([]string, error) {
+		var remaining []string
+		for _, tag := range tags {
+			var err error
+			if remaining, err = client.RemoveDeviceTag(ctx, deviceID, tag); err != nil && !g.skipped(err) {
+				return nil, err
+			}
+		}
+		return remaining, nil
+	})
+}
+
+// runDeviceTagList prints a device's tags
+func runDeviceTagList(args []string) error {
+	apiURL, device, _, _ := parseTagArgs("list", args, false, nil)
+	return updateDeviceTags(apiURL, device, nil, func(ctx context.Context, client *api.Client, deviceID string) ([]string, error) {
+		return client.GetDeviceTags(ctx, deviceID)
+	})
+}
+
+// updateDeviceTags runs a tag request for a device given by ID or alias and
+// prints the device's tags afterwards. g is nil for requests that only read.
+func updateDeviceTags(apiURL, device string, g *guard, request func(context.Context, *api.Client, string) ([]string, error)) error {
+	deviceID, err := resolveDeviceArg(device)
+	if err != nil {
+		return err
+	}
+
+	client, err := newAPIClient(apiURL)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	if g != nil {
+		g.attach(client)
+	}
+	tags, err := request(ctx, client, deviceID)
+	if errors.Is(err, api.ErrTagsUnsupported) {
+		return fmt.Errorf("%w; tag devices in the dashboard instead", err)
+	}
+	if err != nil && !g.skipped(err) {
+		return err
+	}
+	if g.report() {
+		return nil
+	}
+
+	if len(tags) == 0 {
+		fmt.Printf("%s has no tags\n", deviceID)
+		return nil
+	}
+	fmt.Printf("%s%s: %s\n", term.Symbol("🏷  ", ""), deviceID, strings.Join(tags, ", "))
+	return nil
+}
@@ -0,0 +1,282 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"text/tabwriter"
+	"time"
+
+	"github.com/pavliha/aircast/aircast-cli/internal/api"
+	"github.com/pavliha/aircast/aircast-cli/internal/auth"
+	"github.com/pavliha/aircast/aircast-cli/internal/term"
+	"github.com/pavliha/aircast/aircast-cli/internal/ui"
+	log "github.com/sirupsen/logrus"
+)
+
+// deviceCommands are the subcommands of "devices"
+var deviceCommands = map[string]command{
+	"list":   {"List devices in your account", runDevicesList},
+	"remove": {"Unregister a device from your account", runDevicesRemove},
+	"tag":    {"Group devices with tags (add, remove, list)", runDevicesTag},
+}
+
+// deviceRefreshInterval is how often the interactive device screen updates
+const deviceRefreshInterval = 5 * time.Second
+
+// runDevices dispatches "devices" subcommands. Without one it opens the
+// interactive device screen in a terminal and prints the list otherwise.
+func runDevices(args []string) error {
+	if len(args) == 0 || strings.HasPrefix(args[0], "-") {
+		if ui.Interactive() {
+			return runDevicesBrowse(args)
+		}
+		return runDevicesList(args)
+	}
+
+	cmd, ok := deviceCommands[args[0]]
+	if !ok {
+		printSubcommands("devices", deviceCommands)
+		return fmt.Errorf("unknown devices command %q", args[0])
+	}
+	return cmd.run(args[1:])
+}
+
+// cachedDeviceName returns a device's name from the device list cache, or ""
+func cachedDeviceName(cache *auth.DeviceCache, deviceID string) string {
+	device, _, err := cache.Find(deviceID)
+	if err != nil || device == nil {
+		return ""
+	}
+	return device.Name
+}
+
+// runDevicesBrowse shows live device status and starts the bridge for the
+// device the user picks
+func runDevicesBrowse(args []string) error {
+	fs := flag.NewFlagSet("devices", flag.ExitOnError)
+	apiURL := fs.String("api", defaultAPIURL(), "API base URL")
+	tag := tagFlag(fs)
+	accessible := accessibleFlag(fs)
+	_ = fs.Parse(args)
+	if *accessible {
+		applyAccessible(true)
+		return runDevicesList(args)
+	}
+
+	client, err := newAPIClient(*apiURL)
+	if err != nil {
+		return err
+	}
+	cache, err := auth.NewDeviceCache()
+	if err != nil {
+		return err
+	}
+
+	aliases := map[string]string{}
+	if configStore, err := auth.NewConfigStore(); err == nil {
+		if config, err := configStore.LoadConfig(); err == nil {
+			aliases = config.DeviceAliases()
+		}
+	}
+
+	// Revalidate with the cached ETag so refreshes only download the list
+	// when it changed; online status is fetched every time
+	account := auth.AccountKey(client.Token())
+	var etag string
+	var previous []api.Device
+	if cached, err := cache.Load(*apiURL, account); err == nil && cached != nil {
+		etag, previous = cached.ETag, cached.Devices
+	}
+	load := func(ctx context.Context) ([]api.Device, error) {
+		list, err := client.GetDevicesCached(ctx, etag, previous)
+		if err != nil {
+			return nil, err
+		}
+		etag, previous = list.ETag, list.Devices
+		_ = cache.Save(*apiURL, account, list)
+		return api.FilterByTags(list.Devices, splitList(*tag)), nil
+	}
+
+	device, err := ui.BrowseDevices(load, aliases, deviceRefreshInterval)
+	if err != nil || device == nil {
+		return err
+	}
+
+	fmt.Printf("Connecting to %s...\n\n", device.Name)
+	runBridge([]string{"--api", *apiURL, "--device", device.ID})
+	return nil
+}
+
+// runDevicesList prints the devices in the account
+func runDevicesList(args []string) error {
+	fs := flag.NewFlagSet("devices list", flag.ExitOnError)
+	apiURL := fs.String("api", defaultAPIURL(), "API base URL")
+	tag := tagFlag(fs)
+	accessible := accessibleFlag(fs)
+	_ = fs.Parse(args)
+	applyAccessible(*accessible)
+
+	client, err := newAPIClient(*apiURL)
+	if err != nil {
+		return err
+	}
+	cache, err := auth.NewDeviceCache()
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	devices, _, err := fetchDevices(ctx, client, cache, *apiURL, client.Token(), log.WithField("app", "aircast-cli"))
+	if err != nil {
+		return err
+	}
+	if devices, err = filterDevices(devices, *tag); err != nil {
+		return err
+	}
+
+	if len(devices) == 0 {
+		fmt.Println("No devices found in your account")
+		return nil
+	}
+
+	aliases := map[string]string{}
+	if configStore, err := auth.NewConfigStore(); err == nil {
+		if config, err := configStore.LoadConfig(); err == nil {
+			aliases = config.DeviceAliases()
+		}
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tNAME\tALIAS\tTAGS\tSTATUS\tLAST SEEN")
+	for _, d := range devices {
+		status := "offline"
+		if d.IsOnline {
+			status = "online"
+		}
+		lastSeen := "-"
+		if t, err := time.Parse(time.RFC3339, d.LastSeenAt); err == nil {
+			lastSeen = t.Local().Format("2006-01-02 15:04")
+		}
+		alias := aliases[d.ID]
+		if alias == "" {
+			alias = "-"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", d.ID, d.Name, alias, formatTags(d.Tags), status, lastSeen)
+	}
+	return w.Flush()
+}
+
+// maxStaleDeviceList is the oldest cached device list served while the API
+// is returning server errors
+const maxStaleDeviceList = 24 * time.Hour
+
+// fetchDevices fetches the device list, revalidating the cached copy with its
+// ETag. If the API is failing with a server error, a recent cached list is
+// served instead with a warning; stale reports whether that happened.
+func fetchDevices(ctx context.Context, client *api.Client, cache *auth.DeviceCache, apiURL, accessToken string, logger *log.Entry) (devices []api.Device, stale bool, err error) {
+	account := auth.AccountKey(accessToken)
+	cached, cacheErr := cache.Load(apiURL, account)
+	if cacheErr != nil {
+		logger.WithError(cacheErr).Debug("Failed to load device cache")
+	}
+
+	var etag string
+	var previous []api.Device
+	if cached != nil {
+		etag, previous = cached.ETag, cached.Devices
+	}
+
+	list, err := client.GetDevicesCached(ctx, etag, previous)
+	if err != nil {
+		if api.IsServerError(err) && cached != nil && cached.Age() < maxStaleDeviceList {
+			logger.WithError(err).Warn("API failing, using cached device list")
+			fmt.Printf("%sAircast API is having problems - showing the device list from %s ago.\n", term.Symbol("⚠ ", "Warning: "), cached.Age().Round(time.Second))
+			fmt.Println("  Online status may be stale.")
+			fmt.Println()
+			return cached.Devices, true, nil
+		}
+		return nil, false, err
+	}
+
+	if list.NotModified {
+		logger.Debug("Device list unchanged since last fetch")
+	}
+	if err := cache.Save(apiURL, account, list); err != nil {
+		logger.WithError(err).Warn("Failed to cache device list")
+	}
+
+	return list.Devices, false, nil
+}
+
+// runDevicesRemove unregisters a device after confirmation
+func runDevicesRemove(args []string) error {
+	fs := flag.NewFlagSet("devices remove", flag.ExitOnError)
+	apiURL := fs.String("api", defaultAPIURL(), "API base URL")
+	g := guardFlags(fs)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: aircast-cli devices remove [flags] <device-id|alias>\n\n")
+		fs.PrintDefaults()
+	}
+
+	positional := parseArgs(fs, args)
+	if len(positional) != 1 {
+		fs.Usage()
+		os.Exit(2)
+	}
+	deviceID, err := resolveDeviceArg(positional[0])
+	if err != nil {
+		return err
+	}
+
+	client, err := newAPIClient(*apiURL)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	// Look the device up first so the prompt can show which airframe is affected
+	devices, err := client.GetDevices(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to fetch devices: %w", err)
+	}
+
+	var device *api.Device
+	for i := range devices {
+		if devices[i].ID == deviceID {
+			device = &devices[i]
+			break
+		}
+	}
+	if device == nil {
+		return fmt.Errorf("device %s not found in your account", deviceID)
+	}
+
+	if device.IsOnline && !*g.yes {
+		fmt.Printf("%s%s is currently online.\n", term.Symbol("⚠ ", "Warning: "), device.Name)
+	}
+	// Typing the name makes sure it's the intended airframe, not just any Enter
+	if !g.confirmTyped(fmt.Sprintf("Remove %s (%s) from your account? This cannot be undone.", device.Name, device.ID), device.Name, device.ID) {
+		fmt.Println("Aborted")
+		return nil
+	}
+
+	g.attach(client)
+	if err := client.DeleteDevice(ctx, device.ID); err != nil && !g.skipped(err) {
+		return err
+	}
+	if g.report() {
+		return nil
+	}
+
+	fmt.Printf("%sRemoved %s (%s)\n", term.Symbol("✓ ", ""), device.Name, device.ID)
+	return nil
+}
@@ -0,0 +1,317 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/pavliha/aircast/aircast-cli/internal/auth"
+	"github.com/pavliha/aircast/aircast-cli/internal/term"
+)
+
+// envCommands are the subcommands of "env"
+var envCommands = map[string]command{
+	"list":   {"List API environments and their logins", runEnvList},
+	"remove": {"Delete an environment", runEnvRemove},
+	"set":    {"Create or update an environment (API URL, OAuth client, default device)", runEnvSet},
+	"use":    {"Switch to an environment by name or API URL", runEnvUse},
+}
+
+// runEnv dispatches "env" subcommands
+func runEnv(args []string) error {
+	if len(args) == 0 || strings.HasPrefix(args[0], "-") {
+		return runEnvList(args)
+	}
+
+	cmd, ok := envCommands[args[0]]
+	if !ok {
+		printSubcommands("env", envCommands)
+		return fmt.Errorf("unknown env command %q", args[0])
+	}
+	return cmd.run(args[1:])
+}
+
+// defaultAPIURL is the default of --api: AIRCAST_API_URL, else the API URL
+// of the environment chosen with 'env use', else production
+func defaultAPIURL() string {
+	if v := os.Getenv("AIRCAST_API_URL"); v != "" {
+		return v
+	}
+	if configStore, err := auth.NewConfigStore(); err == nil {
+		if config, err := configStore.LoadConfig(); err == nil {
+			return config.APIURL()
+		}
+	}
+	return auth.DefaultAPIURL
+}
+
+// environmentFor returns the environment with apiURL as its API URL
+func environmentFor(config *auth.Config, apiURL string) (auth.Environment, bool) {
+	_, env, ok := config.LookupEnvironment(apiURL)
+	return env, ok
+}
+
+// runEnvUse makes an environment the default for --api and brings back its
+// login, keeping the current one for switching back
+func runEnvUse(args []string) error {
+	fs := flag.NewFlagSet("env use", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: aircast-cli env use <name|api-url>\n\n")
+		fmt.Fprintf(fs.Output(), "Built-in environments: prod, staging, dev, local. An API URL that isn't\n")
+		fmt.Fprintf(fs.Output(), "an environment yet is added under its host name.\n")
+	}
+
+	positional := parseArgs(fs, args)
+	if len(positional) != 1 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	configStore, err := auth.NewConfigStore()
+	if err != nil {
+		return err
+	}
+	config, err := configStore.LoadConfig()
+	if err != nil {
+		return err
+	}
+
+	name, env, ok := config.LookupEnvironment(positional[0])
+	if !ok {
+		if !strings.Contains(positional[0], "://") {
+			return fmt.Errorf("unknown environment %q; add it with 'aircast-cli env set %s <api-url>'", positional[0], positional[0])
+		}
+		if name, err = environmentName(positional[0]); err != nil {
+			return err
+		}
+		env = auth.Environment{APIURL: strings.TrimRight(positional[0], "/")}
+		if err := configStore.SetEnvironment(name, env); err != nil {
+			return err
+		}
+		fmt.Printf("%sAdded environment %s\n", term.Symbol("✓ ", ""), name)
+	}
+
+	if err := configStore.UseEnvironment(name); err != nil {
+		return err
+	}
+	tokenStore, err := auth.NewTokenStore()
+	if err != nil {
+		return err
+	}
+	token, err := tokenStore.SwitchToken(env.APIURL)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%sUsing %s (%s)\n", term.Symbol("✓ ", ""), name, env.APIURL)
+	switch {
+	case token == nil:
+		fmt.Println("  Not logged in; run 'aircast-cli login'")
+	case !tokenStore.IsTokenValid(token):
+		fmt.Println("  Login expired; run 'aircast-cli login'")
+	}
+	if v := os.Getenv("AIRCAST_API_URL"); v != "" && v != env.APIURL {
+		fmt.Printf("%sAIRCAST_API_URL is set to %s and still takes precedence\n", term.Symbol("⚠ ", "Warning: "), v)
+	}
+	return nil
+}
+
+// environmentName names an environment added by API URL after its host,
+// e.g. "api.example.com" or "localhost-8080"
+func environmentName(apiURL string) (string, error) {
+	if err := auth.ValidateAPIURL(apiURL); err != nil {
+		return "", err
+	}
+	u, _ := url.Parse(apiURL)
+	name := strings.ReplaceAll(u.Host, ":", "-")
+	if auth.ValidateEnvironmentName(name) != nil {
+		name = "env-" + name
+	}
+	if err := auth.ValidateEnvironmentName(name); err != nil {
+		return "", fmt.Errorf("can't name an environment after %s; add it with 'aircast-cli env set <name> %s'", u.Host, apiURL)
+	}
+	return name, nil
+}
+
+// runEnvSet creates or updates an environment. Flags that aren't given keep
+// their current values.
+func runEnvSet(args []string) error {
+	fs := flag.NewFlagSet("env set", flag.ExitOnError)
+	device := fs.String("device", "", "Device (ID or alias) to connect to in this environment when no --device is given; empty to use the last device")
+	clientID := fs.String("client-id", "", "OAuth client ID to log in to this environment with")
+	issuer := fs.String("issuer", "", "Authorization server to discover this environment's login endpoints from")
+	discover := fs.Bool("discover", false, "Discover the login endpoints from the environment's API URL")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: aircast-cli env set [flags] <name> [api-url]\n\n")
+		fmt.Fprintf(fs.Output(), "The API URL is required for a new environment.\n\n")
+		fs.PrintDefaults()
+	}
+
+	positional := parseArgs(fs, args)
+	if len(positional) < 1 || len(positional) > 2 {
+		fs.Usage()
+		os.Exit(2)
+	}
+	name := positional[0]
+
+	configStore, err := auth.NewConfigStore()
+	if err != nil {
+		return err
+	}
+	config, err := configStore.LoadConfig()
+	if err != nil {
+		return err
+	}
+
+	env, exists := config.AllEnvironments()[name]
+	if len(positional) == 2 {
+		env.APIURL = strings.TrimRight(positional[1], "/")
+	} else if !exists {
+		return fmt.Errorf("environment %q doesn't exist yet; give its API URL", name)
+	}
+
+	set := map[string]bool{}
+	fs.Visit(func(f *flag.Flag) { set[f.Name] = true })
+	if set["device"] {
+		env.Device = *device
+	}
+	if set["client-id"] || set["issuer"] || set["discover"] {
+		client := auth.Client{}
+		if env.OAuthClient != nil {
+			client = *env.OAuthClient
+		}
+		if set["client-id"] {
+			client.ID = *clientID
+		}
+		if set["issuer"] {
+			client.Issuer = *issuer
+		}
+		if set["discover"] {
+			client.Discover = *discover
+		}
+		if err := client.Validate(); err != nil {
+			return fmt.Errorf("invalid OAuth client: %w", err)
+		}
+		env.OAuthClient = &client
+	}
+
+	if err := configStore.SetEnvironment(name, env); err != nil {
+		return err
+	}
+	fmt.Printf("%s%s → %s\n", term.Symbol("✓ ", ""), name, env.APIURL)
+	return nil
+}
+
+// runEnvRemove deletes a configured environment; a built-in one reverts to
+// its defaults
+func runEnvRemove(args []string) error {
+	fs := flag.NewFlagSet("env remove", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: aircast-cli env remove <name>\n")
+	}
+
+	positional := parseArgs(fs, args)
+	if len(positional) != 1 {
+		fs.Usage()
+		os.Exit(2)
+	}
+	name := positional[0]
+
+	configStore, err := auth.NewConfigStore()
+	if err != nil {
+		return err
+	}
+	removed, err := configStore.RemoveEnvironment(name)
+	if err != nil {
+		return err
+	}
+	if !removed {
+		if _, builtin := auth.BuiltinEnvironments[name]; builtin {
+			return fmt.Errorf("%s is built in and can't be removed", name)
+		}
+		return fmt.Errorf("no environment named %q", name)
+	}
+
+	if _, builtin := auth.BuiltinEnvironments[name]; builtin {
+		fmt.Printf("%s%s reverted to %s\n", term.Symbol("✓ ", ""), name, auth.BuiltinEnvironments[name].APIURL)
+	} else {
+		fmt.Printf("%sRemoved environment %s\n", term.Symbol("✓ ", ""), name)
+	}
+	return nil
+}
+
+// runEnvList prints the environments, marking the active one, with each
+// one's login and default device
+func runEnvList(args []string) error {
+	fs := flag.NewFlagSet("env list", flag.ExitOnError)
+	_ = fs.Parse(args)
+
+	configStore, err := auth.NewConfigStore()
+	if err != nil {
+		return err
+	}
+	config, err := configStore.LoadConfig()
+	if err != nil {
+		return err
+	}
+	tokenStore, err := auth.NewTokenStore()
+	if err != nil {
+		return err
+	}
+	current, err := tokenStore.LoadToken()
+	if err != nil {
+		return err
+	}
+
+	all := config.AllEnvironments()
+	names := make([]string, 0, len(all))
+	for name := range all {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	active := config.Environment
+	if active == "" {
+		active = auth.EnvProduction
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "\tNAME\tAPI URL\tLOGIN\tDEVICE")
+	for _, name := range names {
+		env := all[name]
+		marker := ""
+		if name == active {
+			marker = "*"
+		}
+
+		token := current
+		if token == nil || token.APIURL != env.APIURL {
+			token, _ = tokenStore.KeptToken(env.APIURL)
+		}
+		login := "-"
+		if token != nil {
+			login = "expired"
+			if tokenStore.IsTokenValid(token) {
+				login = "valid"
+			}
+		}
+
+		device := env.Device
+		if device == "" {
+			device = "-"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", marker, name, env.APIURL, login, device)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	if v := os.Getenv("AIRCAST_API_URL"); v != "" {
+		fmt.Printf("\nAIRCAST_API_URL is set: commands use %s\n", v)
+	}
+	return nil
+}
@@ -0,0 +1,219 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/pavliha/aircast/aircast-cli/internal/cli"
+	"github.com/pavliha/aircast/aircast-cli/internal/store"
+	"github.com/pavliha/aircast/aircast-cli/internal/webhook"
+	log "github.com/sirupsen/logrus"
+)
+
+// eventLogName is the session's event log in its flight folder
+const eventLogName = "events.jsonl"
+
+// Event types in the event log besides those shared with webhooks
+const (
+	eventAuth            = "auth.ready"
+	eventDeviceSelected  = "device.selected"
+	eventLinkConnected   = "link.connected"
+	eventLinkFailed      = "link.failed"
+	eventLinkStalled     = "link.stalled"
+	eventClientConnected = "client.connected"
+	eventClientLeft      = "client.disconnected"
+	eventVehicleArmed    = "vehicle.armed"
+	eventVehicleDisarmed = "vehicle.disarmed"
+	eventSessionExpired  = "session.expired"
+)
+
+// sessionEvent is one line of the event log
+type sessionEvent struct {
+	Time time.Time `json:"time"`
+	Type string    `json:"type"`
+	Data any       `json:"data,omitempty"`
+}
+
+// authData describes an auth.ready event: how the session got its token
+type authData struct {
+	Method string `json:"method"` // "stored" or "login"
+	Reason string `json:"reason,omitempty"`
+	Env    string `json:"env"`
+}
+
+// deviceSelectedData describes a device.selected event
+type deviceSelectedData struct {
+	DeviceID string `json:"device_id"`
+	Name     string `json:"name,omitempty"`
+	How      string `json:"how"` // "flag", "alias", "name", "env", "last" or "picker"
+}
+
+// clientData describes client.connected and client.disconnected events
+type clientData struct {
+	ID string `json:"id"`
+}
+
+// eventLog writes the session's lifecycle events as JSON lines, so tools can
+// rebuild the timeline without parsing the human-readable log. Events before
+// the session has a flight folder (authentication, device selection) are
+// held until attach. A nil eventLog discards everything.
+type eventLog struct {
+	logger *log.Entry
+
+	mu      sync.Mutex
+	pending []sessionEvent
+	file    *os.File
+	enc     *json.Encoder
+	closed  bool
+}
+
+// newEventLog starts collecting events, or returns nil if disabled
+func newEventLog(enabled bool, logger *log.Entry) *eventLog {
+	if !enabled {
+		return nil
+	}
+	return &eventLog{logger: logger}
+}
+
+// emit records an event
+func (l *eventLog) emit(eventType string, data any) {
+	if l == nil {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	event := sessionEvent{Time: time.Now().UTC(), Type: eventType, Data: data}
+	switch {
+	case l.closed:
+	case l.enc == nil:
+		l.pending = append(l.pending, event)
+	default:
+		l.write(event)
+	}
+}
+
+// attach opens events.jsonl in the session's flight folder and writes the
+// events held so far. Without a folder the events are dropped.
+func (l *eventLog) attach(folder *store.FlightFolder) {
+	if l == nil {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	pending := l.pending
+	l.pending = nil
+	if folder == nil {
+		l.closed = true
+		return
+	}
+
+	path, err := folder.Add(eventLogName, "events")
+	if err == nil {
+		l.file, err = os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	}
+	if err != nil {
+		l.logger.WithError(err).Warn("Event log unavailable")
+		l.closed = true
+		return
+	}
+
+	l.enc = json.NewEncoder(l.file)
+	for _, event := range pending {
+		l.write(event)
+	}
+}
+
+// write appends one event. Caller must hold mu.
+func (l *eventLog) write(event sessionEvent) {
+	if err := l.enc.Encode(event); err != nil {
+		l.logger.WithError(err).Warn("Failed to write event log, no more events are recorded")
+		l.closed = true
+	}
+}
+
+// close stops recording and closes the file
+func (l *eventLog) close() {
+	if l == nil {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.closed = true
+	if l.file != nil {
+		_ = l.file.Close()
+	}
+}
+
+// linkHandler wraps the bridge's link callback to also log drops and
+// restores
+func (l *eventLog) linkHandler(next func(bool, error)) func(bool, error) {
+	if l == nil {
+		return next
+	}
+	var mu sync.Mutex
+	var lostAt time.Time
+	return func(up bool, err error) {
+		if next != nil {
+			next(up, err)
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		if !up {
+			lostAt = time.Now()
+			l.emit(webhook.LinkLost, linkData{Error: err.Error()})
+			return
+		}
+		l.emit(webhook.LinkRestored, linkData{Downtime: time.Since(lostAt).Seconds()})
+	}
+}
+
+// alarmHandler wraps the bridge's alarm callback to also log alarms
+func (l *eventLog) alarmHandler(next func(cli.AlarmEvent)) func(cli.AlarmEvent) {
+	if l == nil {
+		return next
+	}
+	return func(event cli.AlarmEvent) {
+		next(event)
+		name := webhook.AlarmCleared
+		if event.Raised {
+			name = webhook.AlarmRaised
+		}
+		l.emit(name, alarmData{Rule: event.Rule.String(), Metric: event.Rule.Metric, Value: event.Value})
+	}
+}
+
+// armedHandler wraps the bridge's arming callback to also log arming
+func (l *eventLog) armedHandler(next func(bool, time.Time)) func(bool, time.Time) {
+	if l == nil {
+		return next
+	}
+	return func(armed bool, at time.Time) {
+		if next != nil {
+			next(armed, at)
+		}
+		if armed {
+			l.emit(eventVehicleArmed, nil)
+			return
+		}
+		l.emit(eventVehicleDisarmed, nil)
+	}
+}
+
+// clientHandler logs ground stations joining and leaving
+func (l *eventLog) clientHandler() func(string, bool) {
+	if l == nil {
+		return nil
+	}
+	return func(id string, connected bool) {
+		if connected {
+			l.emit(eventClientConnected, clientData{ID: id})
+			return
+		}
+		l.emit(eventClientLeft, clientData{ID: id})
+	}
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/pavliha/aircast/aircast-cli/internal/api"
+	"github.com/pavliha/aircast/aircast-cli/internal/auth"
+	"github.com/pavliha/aircast/aircast-cli/internal/term"
+	"github.com/pavliha/aircast/aircast-cli/internal/ui"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	eventsMinBackoff = 2 * time.Second
+	eventsMaxBackoff = time.Minute
+)
+
+// watchEvents subscribes to the account's device lifecycle events and shows
+// them as they happen, reconnecting until ctx is done. It gives up quietly
+// if the API has no event feed, and waits for a new login if the session is
+// rejected.
+func watchEvents(ctx context.Context, client *api.Client, cache *auth.DeviceCache, deviceID string, logger *log.Entry) {
+	logger = logger.WithField("component", "events")
+	backoff := eventsMinBackoff
+	lastID := ""
+
+	for ctx.Err() == nil {
+		started := time.Now()
+		token := client.Token()
+		var err error
+		lastID, err = client.SubscribeEvents(ctx, lastID, func(event api.Event) {
+			showEvent(event, cache, deviceID, logger)
+		})
+		if ctx.Err() != nil {
+			return
+		}
+
+		switch {
+		case errors.Is(err, api.ErrEventsUnsupported):
+			logger.Debug("No device event feed available")
+			return
+		case api.IsAuthError(err):
+			logger.WithError(err).Warn("Device event feed rejected the session, waiting for a new login")
+			changed := client.TokenChanged()
+			if client.Token() == token {
+				select {
+				case <-ctx.Done():
+					return
+				case <-changed:
+				}
+			}
+			backoff = eventsMinBackoff
+			continue
+		case err != nil:
+			logger.WithError(err).Debug("Device event feed disconnected")
+		}
+
+		// A stream that stayed up for a while was healthy; reconnect promptly
+		if time.Since(started) > eventsMaxBackoff {
+			backoff = eventsMinBackoff
+		}
+		wait := backoff
+		if retryAfter, limited := api.IsRateLimited(err); limited {
+			logger.WithError(err).Info("Device event feed rate limited")
+			wait = max(wait, retryAfter)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(wait):
+		}
+		backoff = min(backoff*2, eventsMaxBackoff)
+	}
+}
+
+// showEvent prints and logs a device lifecycle event
+func showEvent(event api.Event, cache *auth.DeviceCache, deviceID string, logger *log.Entry) {
+	name := event.DeviceName
+	if name == "" {
+		name = cachedDeviceName(cache, event.DeviceID)
+	}
+	if name == "" {
+		name = event.DeviceID
+	}
+	if event.DeviceID == deviceID {
+		name += " (bridged device)"
+	}
+
+	var line string
+	switch event.Type {
+	case api.EventDeviceOnline:
+		line = fmt.Sprintf("%s%s is online", term.Symbol("🟢 ", ""), name)
+	case api.EventDeviceOffline:
+		line = fmt.Sprintf("%s%s went offline", term.Symbol("⚫ ", ""), name)
+	case api.EventAgentUpdated:
+		line = fmt.Sprintf("%s%s agent updated to %s", term.Symbol("⬆️  ", ""), name, event.AgentVersion)
+	case api.EventOwnershipChange:
+		if event.Role == "" {
+			line = fmt.Sprintf("%sYour access to %s was removed", term.Symbol("⚠ ", "Warning: "), name)
+		} else {
+			line = fmt.Sprintf("%sYour role on %s is now %s", term.Symbol("🔑 ", ""), name, event.Role)
+		}
+	default:
+		logger.WithField("type", event.Type).Debug("Ignoring unknown device event")
+		return
+	}
+
+	fmt.Fprintf(ui.Output, "\n%s\n\n", line)
+	logger.WithFields(log.Fields{
+		"type":      event.Type,
+		"device_id": event.DeviceID,
+	}).Info("Device event")
+}
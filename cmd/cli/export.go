@@ -0,0 +1,248 @@
+package main
+
+import (
+	"encoding/csv"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pavliha/aircast/aircast-cli/internal/mavlink"
+	"github.com/pavliha/aircast/aircast-cli/internal/recording"
+	"github.com/pavliha/aircast/aircast-cli/internal/term"
+)
+
+// exportCommands are the subcommands of "export"
+var exportCommands = map[string]command{
+	"csv": {"Convert a tlog or recording into one CSV file per message", runExportCSV},
+}
+
+// runExport dispatches "export" subcommands
+func runExport(args []string) error {
+	if len(args) == 0 {
+		printSubcommands("export", exportCommands)
+		os.Exit(2)
+	}
+
+	cmd, ok := exportCommands[args[0]]
+	if !ok {
+		printSubcommands("export", exportCommands)
+		return fmt.Errorf("unknown export command %q", args[0])
+	}
+	return cmd.run(args[1:])
+}
+
+// readFrames calls fn for every frame of a recording or a tlog. Frames of a
+// recording carry their channel; a tlog has only channel 0.
+func readFrames(path string, fn func(channel uint32, t time.Time, raw []byte) error) error {
+	err := readRecording(path, func(rec recording.Record) error {
+		if rec.Kind == recording.KindChannel {
+			return nil
+		}
+		return fn(rec.Channel, rec.Time, rec.Data)
+	})
+	if !errors.Is(err, recording.ErrNotRecording) {
+		return err
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = file.Close() }()
+
+	r := recording.NewTlogReader(file)
+	for {
+		t, raw, err := r.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		if err := fn(0, t, raw); err != nil {
+			return err
+		}
+	}
+}
+
+// csvKey identifies the CSV file a frame goes to
+type csvKey struct {
+	channel uint32
+	msgID   uint32
+}
+
+// csvFile is one message's CSV output
+type csvFile struct {
+	path   string
+	file   *os.File
+	w      *csv.Writer
+	fields []mavlink.Field
+	rows   int
+}
+
+// parseMessageList resolves a comma-separated list of message names
+func parseMessageList(list string) (map[uint32]bool, error) {
+	if list == "" {
+		return nil, nil
+	}
+	ids := make(map[uint32]bool)
+	for _, name := range strings.Split(list, ",") {
+		if strings.TrimSpace(name) == "" {
+			continue
+		}
+		id, ok := mavlink.MessageID(name)
+		if !ok {
+			return nil, fmt.Errorf("unknown message %q", strings.TrimSpace(name))
+		}
+		ids[id] = true
+	}
+	return ids, nil
+}
+
+// runExportCSV writes each message of a log to its own CSV file, with a
+// column per payload field
+func runExportCSV(args []string) error {
+	fs := flag.NewFlagSet("export csv", flag.ExitOnError)
+	messages := fs.String("messages", "", "Comma-separated messages to export, e.g. GLOBAL_POSITION_INT,SYS_STATUS (default all)")
+	outDir := fs.String("out", ".", "Directory for the CSV files")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: aircast-cli export csv [flags] <tlog or recording>\n\n")
+		fmt.Fprintf(fs.Output(), "Writes <MESSAGE>.csv per message, in a folder per device for multi-device recordings.\n\n")
+		fs.PrintDefaults()
+	}
+	positional := parseArgs(fs, args)
+	if len(positional) != 1 {
+		fs.Usage()
+		os.Exit(2)
+	}
+	path := positional[0]
+
+	wanted, err := parseMessageList(*messages)
+	if err != nil {
+		return err
+	}
+
+	// Multi-device recordings get a folder per device
+	channels, err := summarizeRecording(path)
+	if err != nil && !errors.Is(err, recording.ErrNotRecording) {
+		return err
+	}
+	var dirs map[uint32]string
+	if len(channels) > 1 {
+		dirs = channelFileNames(channels)
+	}
+
+	files := make(map[csvKey]*csvFile)
+	defer func() {
+		for _, f := range files {
+			_ = f.file.Close()
+		}
+	}()
+
+	parser := mavlink.NewParser()
+	var invalid int
+	err = readFrames(path, func(channel uint32, t time.Time, raw []byte) error {
+		for _, frame := range parser.Feed(raw) {
+			if frame.Validate() != nil {
+				invalid++
+				continue
+			}
+			if wanted != nil && !wanted[frame.MsgID] {
+				continue
+			}
+
+			key := csvKey{channel, frame.MsgID}
+			out, ok := files[key]
+			if !ok {
+				var err error
+				if out, err = createCSV(filepath.Join(*outDir, dirs[channel]), frame.MsgID); err != nil {
+					return err
+				}
+				files[key] = out
+			}
+
+			row := []string{
+				t.UTC().Format("2006-01-02T15:04:05.000000Z"),
+				strconv.FormatFloat(float64(t.UnixMicro())/1e6, 'f', 6, 64),
+				strconv.Itoa(int(frame.SysID)),
+				strconv.Itoa(int(frame.CompID)),
+			}
+			for _, field := range out.fields {
+				row = append(row, field.Values(frame.Payload)...)
+			}
+			if err := out.w.Write(row); err != nil {
+				return err
+			}
+			out.rows++
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	written := make([]*csvFile, 0, len(files))
+	seen := make(map[uint32]bool)
+	for key, f := range files {
+		f.w.Flush()
+		if err := f.w.Error(); err != nil {
+			return err
+		}
+		written = append(written, f)
+		seen[key.msgID] = true
+	}
+	sort.Slice(written, func(i, j int) bool { return written[i].path < written[j].path })
+	for _, f := range written {
+		fmt.Printf("%s%s (%d rows)\n", term.Symbol("✓ ", ""), f.path, f.rows)
+	}
+
+	var missing []string
+	for id := range wanted {
+		if !seen[id] {
+			missing = append(missing, mavlink.MessageName(id))
+		}
+	}
+	sort.Strings(missing)
+	for _, name := range missing {
+		fmt.Printf("No %s messages in %s\n", name, path)
+	}
+	if len(files) == 0 && len(wanted) == 0 {
+		fmt.Println("No frames to export")
+	}
+	if invalid > 0 {
+		fmt.Printf("Skipped %d frames with bad checksums or unknown messages\n", invalid)
+	}
+	return nil
+}
+
+// createCSV creates the CSV file for a message and writes its header
+func createCSV(dir string, msgID uint32) (*csvFile, error) {
+	fields, _ := mavlink.MessageFields(msgID)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+
+	path := filepath.Join(dir, mavlink.MessageName(msgID)+".csv")
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+
+	out := &csvFile{path: path, file: file, w: csv.NewWriter(file), fields: fields}
+	header := []string{"timestamp", "unix_time", "sys_id", "comp_id"}
+	for _, field := range fields {
+		header = append(header, field.Columns()...)
+	}
+	if err := out.w.Write(header); err != nil {
+		_ = file.Close()
+		return nil, err
+	}
+	return out, nil
+}
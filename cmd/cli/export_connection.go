@@ -0,0 +1,84 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/pavliha/aircast/aircast-cli/internal/gcsconfig"
+	"github.com/pavliha/aircast/aircast-cli/internal/term"
+)
+
+// runExportConnection writes a ground control station link configuration
+// pointing at the bridge, optionally installing it into the GCS settings
+func runExportConnection(args []string) error {
+	fs := flag.NewFlagSet("export-connection", flag.ExitOnError)
+	format := fs.String("format", "qgc", "Ground station to configure: qgc or mission-planner")
+	tcpListen := fs.String("tcp", getEnv("AIRCAST_TCP_LISTEN", "127.0.0.1:5169"), "TCP listen address of the bridge")
+	udpListen := fs.String("udp", "", "Use the bridge's UDP listen address instead of TCP (QGroundControl only)")
+	host := fs.String("host", "", "Address ground stations reach the bridge at (default from the listen address, 127.0.0.1 for wildcards)")
+	name := fs.String("name", "Aircast", "Link name shown in the ground station")
+	output := fs.String("o", "", "Write the configuration to a file instead of stdout")
+	install := fs.Bool("install", false, "Add the link to the ground station's settings file (close the ground station first)")
+	configFile := fs.String("config-file", "", "Settings file to install into (default the ground station's standard location)")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: aircast-cli export-connection [flags]\n\n")
+		fmt.Fprintf(fs.Output(), "Writes a QGroundControl comm link or Mission Planner preset for the bridge.\n\n")
+		fs.PrintDefaults()
+	}
+	_ = fs.Parse(args)
+
+	protocol, listen := "tcp", *tcpListen
+	if *udpListen != "" {
+		protocol, listen = "udp", *udpListen
+	}
+	link, err := gcsconfig.NewLink(*name, protocol, listen, *host)
+	if err != nil {
+		return err
+	}
+
+	var (
+		data        []byte
+		installFunc func(string, gcsconfig.Link) error
+		defaultPath func() (string, error)
+		gcsName     string
+	)
+	switch *format {
+	case "qgc", "qgroundcontrol":
+		data = gcsconfig.QGC(link)
+		installFunc, defaultPath, gcsName = gcsconfig.InstallQGC, gcsconfig.QGCSettingsPath, "QGroundControl"
+	case "mission-planner", "mp":
+		if data, err = gcsconfig.MissionPlanner(link); err != nil {
+			return err
+		}
+		installFunc, defaultPath, gcsName = gcsconfig.InstallMissionPlanner, gcsconfig.MissionPlannerSettingsPath, "Mission Planner"
+	default:
+		return fmt.Errorf("unknown format %q (expected qgc or mission-planner)", *format)
+	}
+
+	if !*install {
+		if *output == "" {
+			_, err = os.Stdout.Write(data)
+			return err
+		}
+		if err := os.WriteFile(*output, data, 0644); err != nil {
+			return fmt.Errorf("failed to write %s: %w", *output, err)
+		}
+		fmt.Printf("%sWrote %s link configuration to %s\n", term.Symbol("✓ ", ""), gcsName, *output)
+		return nil
+	}
+
+	path := *configFile
+	if path == "" {
+		if path, err = defaultPath(); err != nil {
+			return err
+		}
+	}
+	if err := installFunc(path, link); err != nil {
+		return err
+	}
+
+	fmt.Printf("%sAdded %q (%s://%s:%d) to %s\n", term.Symbol("✓ ", ""), link.Name, link.Protocol, link.Host, link.Port, path)
+	fmt.Printf("  If %s was running, close it and run this again: it rewrites its settings on exit.\n", gcsName)
+	return nil
+}
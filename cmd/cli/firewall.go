@@ -0,0 +1,238 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"os/exec"
+	"runtime"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pavliha/aircast/aircast-cli/internal/network"
+	"github.com/pavliha/aircast/aircast-cli/internal/term"
+)
+
+// isLoopbackListen reports whether a listen address only accepts local connections
+func isLoopbackListen(addr string) bool {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return false
+	}
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// commandOutput runs a command and returns its trimmed output, or "" if it fails
+func commandOutput(name string, args ...string) string {
+	out, err := exec.Command(name, args...).Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(out))
+}
+
+// checkListenFirewall inspects the local firewall for a non-loopback listener
+// and returns guidance if it is likely to block ground stations on other hosts
+func checkListenFirewall(protocol, addr string) []string {
+	if addr == "" || isLoopbackListen(addr) {
+		return nil
+	}
+	_, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil
+	}
+	proto := strings.ToLower(protocol)
+
+	switch runtime.GOOS {
+	case "linux":
+		if out, err := exec.Command("ufw", "status").CombinedOutput(); !errors.Is(err, exec.ErrNotFound) {
+			status := strings.TrimSpace(string(out))
+			switch {
+			case strings.Contains(status, "need to be root"):
+				return []string{
+					fmt.Sprintf("ufw is installed, but its rules can only be read as root, so %s port %s couldn't be checked.", protocol, port),
+					"Check them with: sudo ufw status",
+				}
+			case strings.HasPrefix(status, "Status: active"):
+				if !ufwAllows(status, port, proto) {
+					return []string{
+						fmt.Sprintf("ufw is active and has no rule for %s port %s.", protocol, port),
+						fmt.Sprintf("Allow it with: sudo ufw allow %s/%s", port, proto),
+					}
+				}
+				return nil
+			}
+		}
+		if commandOutput("firewall-cmd", "--state") == "running" {
+			if commandOutput("firewall-cmd", "--query-port="+port+"/"+proto) != "yes" {
+				return []string{
+					fmt.Sprintf("firewalld is running and %s port %s is not open.", protocol, port),
+					fmt.Sprintf("Open it with: sudo firewall-cmd --add-port=%s/%s --permanent && sudo firewall-cmd --reload", port, proto),
+				}
+			}
+		}
+
+	case "darwin":
+		state := commandOutput("/usr/libexec/ApplicationFirewall/socketfilterfw", "--getglobalstate")
+		if strings.Contains(state, "enabled") {
+			exe, _ := os.Executable()
+			apps := commandOutput("/usr/libexec/ApplicationFirewall/socketfilterfw", "--listapps")
+			if exe != "" && !strings.Contains(apps, exe) {
+				return []string{
+					"The macOS application firewall is on and aircast-cli is not in its allow list.",
+					"Allow it when macOS prompts, or run:",
+					fmt.Sprintf("  sudo /usr/libexec/ApplicationFirewall/socketfilterfw --add %q --unblockapp %q", exe, exe),
+				}
+			}
+		}
+
+	case "windows":
+		rule := fmt.Sprintf("name=%s (%s %s)", "Aircast CLI", protocol, port)
+		if commandOutput("netsh", "advfirewall", "firewall", "show", "rule", rule) == "" {
+			return []string{
+				fmt.Sprintf("No Windows Firewall rule allows %s port %s; Defender will block or prompt.", protocol, port),
+				fmt.Sprintf("Register one with: aircast-cli setup-windows --%s %s", proto, addr),
+			}
+		}
+	}
+
+	return nil
+}
+
+// ufwAllows reports whether "ufw status" output has a rule allowing
+// incoming connections to port over proto ("tcp" or "udp")
+func ufwAllows(status, port, proto string) bool {
+	rules := false
+	for _, line := range strings.Split(status, "\n") {
+		if !rules {
+			rules = strings.HasPrefix(line, "--")
+			continue
+		}
+
+		// To, then the action, such as "5760/tcp ALLOW Anywhere" or
+		// "10.0.0.2 5000:6000/udp LIMIT IN 10.0.0.0/8"
+		fields := strings.Fields(line)
+		action := slices.IndexFunc(fields, func(f string) bool {
+			return f == "ALLOW" || f == "LIMIT" || f == "DENY" || f == "REJECT"
+		})
+		if action < 0 || fields[action] == "DENY" || fields[action] == "REJECT" {
+			continue
+		}
+		if action+1 < len(fields) && fields[action+1] == "OUT" {
+			continue
+		}
+
+		ports := "" // A To without ports allows them all
+		for _, f := range fields[:action] {
+			if f == "Anywhere" || strings.HasPrefix(f, "(") || net.ParseIP(f) != nil {
+				continue
+			}
+			if _, _, err := net.ParseCIDR(f); err == nil {
+				continue
+			}
+			ports = f
+		}
+		if ports == "" || ufwPortMatches(ports, port, proto) {
+			return true
+		}
+	}
+	return false
+}
+
+// ufwPortMatches reports whether a ufw port spec such as "5760",
+// "5760/tcp", "5000:6000/udp" or "22,80,443/tcp" covers port over proto.
+// Application profile names don't match.
+func ufwPortMatches(spec, port, proto string) bool {
+	ports, specProto, _ := strings.Cut(spec, "/")
+	if specProto != "" && specProto != proto {
+		return false
+	}
+	want, err := strconv.Atoi(port)
+	if err != nil {
+		return false
+	}
+	for _, p := range strings.Split(ports, ",") {
+		lo, hi, isRange := strings.Cut(p, ":")
+		if !isRange {
+			hi = lo
+		}
+		low, err1 := strconv.Atoi(lo)
+		high, err2 := strconv.Atoi(hi)
+		if err1 == nil && err2 == nil && low <= want && want <= high {
+			return true
+		}
+	}
+	return false
+}
+
+// printFirewallWarnings runs the listener pre-checks and prints any guidance
+func printFirewallWarnings(tcpListen, udpListen string) {
+	var lines []string
+	lines = append(lines, checkListenFirewall("TCP", tcpListen)...)
+	lines = append(lines, checkListenFirewall("UDP", udpListen)...)
+	if len(lines) == 0 {
+		return
+	}
+
+	fmt.Printf("%sGround stations on other machines may not be able to connect:\n", term.Symbol("⚠ ", "Warning: "))
+	for _, line := range lines {
+		fmt.Printf("  %s\n", line)
+	}
+	fmt.Println()
+}
+
+// diagnoseEgress explains a failed WebSocket connection by probing whether
+// outbound connections to the API host are possible at all
+func diagnoseEgress(wsURL string, dialErr error) []string {
+	u, err := url.Parse(wsURL)
+	if err != nil {
+		return nil
+	}
+	port := u.Port()
+	if port == "" {
+		port = "443"
+		if u.Scheme == "ws" {
+			port = "80"
+		}
+	}
+	target := net.JoinHostPort(u.Hostname(), port)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	conn, err := network.DialContext(ctx, "tcp", target)
+	if err != nil {
+		var dnsErr *net.DNSError
+		if errors.As(err, &dnsErr) {
+			return []string{
+				fmt.Sprintf("Cannot resolve %s. Check your DNS settings or network connection.", u.Hostname()),
+			}
+		}
+		return []string{
+			fmt.Sprintf("Outbound connections to %s are blocked (%v).", target, err),
+			"A firewall or captive portal is likely blocking egress. Allow outbound HTTPS",
+			"for aircast-cli, or sign in to the network's captive portal first.",
+		}
+	}
+	_ = conn.Close()
+
+	// TCP works, so something on the path rejected the WebSocket upgrade
+	lines := []string{
+		fmt.Sprintf("%s is reachable, but the WebSocket connection failed: %v", target, dialErr),
+	}
+	if os.Getenv("HTTPS_PROXY") != "" || os.Getenv("https_proxy") != "" {
+		lines = append(lines, "A proxy is configured; make sure it allows WebSocket upgrades (CONNECT to port 443).")
+	} else {
+		lines = append(lines, "A corporate proxy or filtering firewall may be blocking WebSocket upgrades.",
+			"Set HTTPS_PROXY if your network requires a proxy.")
+	}
+	return lines
+}
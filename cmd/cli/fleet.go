@@ -0,0 +1,225 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"text/tabwriter"
+	"time"
+
+	"github.com/pavliha/aircast/aircast-cli/internal/api"
+	"github.com/pavliha/aircast/aircast-cli/internal/auth"
+	log "github.com/sirupsen/logrus"
+)
+
+// fleetCommands are the subcommands of "fleet"
+var fleetCommands = map[string]command{
+	"status": {"Summarize every device: online, last seen, agent version and link quality", runFleetStatus},
+}
+
+// fleetConcurrency bounds the per-device API requests in flight
+const fleetConcurrency = 8
+
+// runFleet dispatches "fleet" subcommands, showing the status without one
+func runFleet(args []string) error {
+	if len(args) == 0 || strings.HasPrefix(args[0], "-") {
+		return runFleetStatus(args)
+	}
+
+	cmd, ok := fleetCommands[args[0]]
+	if !ok {
+		printSubcommands("fleet", fleetCommands)
+		return fmt.Errorf("unknown fleet command %q", args[0])
+	}
+	return cmd.run(args[1:])
+}
+
+// fleetDevice is one device's line in the fleet report. Fields the API
+// couldn't report are left empty.
+type fleetDevice struct {
+	ID       string     `json:"id"`
+	Name     string     `json:"name"`
+	Alias    string     `json:"alias,omitempty"`
+	Online   bool       `json:"online"`
+	Tags     []string   `json:"tags,omitempty"`
+	LastSeen *time.Time `json:"last_seen,omitempty"`
+
+	AgentVersion string `json:"agent_version,omitempty"`
+	ProxyRunning *bool  `json:"proxy_running,omitempty"` // The agent is attached to the autopilot
+
+	Link *api.LinkMetrics `json:"link,omitempty"` // Latest metrics from a bridge with --share-metrics
+}
+
+// fleetReport collects the status of every device in the account with all
+// of tags (every device if tags is empty)
+func fleetReport(ctx context.Context, client *api.Client, aliases map[string]string, tags string) ([]fleetDevice, error) {
+	devices, err := client.GetDevices(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch devices: %w", err)
+	}
+	if devices, err = filterDevices(devices, tags); err != nil {
+		return nil, err
+	}
+
+	report := make([]fleetDevice, len(devices))
+	sem := make(chan struct{}, fleetConcurrency)
+	var wg sync.WaitGroup
+	for i, d := range devices {
+		report[i] = fleetDevice{ID: d.ID, Name: d.Name, Alias: aliases[d.ID], Online: d.IsOnline, Tags: d.Tags}
+		if t, err := time.Parse(time.RFC3339, d.LastSeenAt); err == nil {
+			report[i].LastSeen = &t
+		}
+
+		wg.Add(1)
+		go func(entry *fleetDevice) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			logger := log.WithField("device_id", entry.ID)
+			if agent, err := client.GetAgentStatus(ctx, entry.ID); err == nil {
+				entry.AgentVersion = agent.Version
+				entry.ProxyRunning = &agent.ProxyRunning
+			} else if !errors.Is(err, api.ErrAgentUnsupported) {
+				logger.WithError(err).Debug("Failed to get agent status")
+			}
+			if link, err := client.GetLinkMetrics(ctx, entry.ID); err == nil {
+				entry.Link = link
+			} else if !errors.Is(err, api.ErrNoLinkMetrics) {
+				logger.WithError(err).Debug("Failed to get link metrics")
+			}
+		}(&report[i])
+	}
+	wg.Wait()
+
+	return report, ctx.Err()
+}
+
+// runFleetStatus prints the status of every device, for a morning check or
+// for monitoring systems
+func runFleetStatus(args []string) error {
+	fs := flag.NewFlagSet("fleet status", flag.ExitOnError)
+	apiURL := fs.String("api", defaultAPIURL(), "API base URL")
+	output := fs.String("output", "table", "Output format: table, json or csv")
+	tag := tagFlag(fs)
+	_ = fs.Parse(args)
+
+	if *output != "table" && *output != "json" && *output != "csv" {
+		return fmt.Errorf("unknown output %q (expected table, json or csv)", *output)
+	}
+
+	client, err := newAPIClient(*apiURL)
+	if err != nil {
+		return err
+	}
+
+	aliases := map[string]string{}
+	if configStore, err := auth.NewConfigStore(); err == nil {
+		if config, err := configStore.LoadConfig(); err == nil {
+			aliases = config.DeviceAliases()
+		}
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	report, err := fleetReport(ctx, client, aliases, *tag)
+	if err != nil {
+		return err
+	}
+
+	switch *output {
+	case "json":
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(report)
+	case "csv":
+		return printFleetCSV(report)
+	}
+
+	if len(report) == 0 {
+		fmt.Println("No devices found in your account")
+		return nil
+	}
+	return printFleetTable(report)
+}
+
+// printFleetTable prints the fleet report for people
+func printFleetTable(report []fleetDevice) error {
+	online := 0
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tNAME\tSTATUS\tLAST SEEN\tAGENT\tAUTOPILOT\tRTT\tLOSS\tRECONNECTS")
+	for _, d := range report {
+		status := "offline"
+		if d.Online {
+			status = "online"
+			online++
+		}
+		name := d.Name
+		if d.Alias != "" {
+			name = fmt.Sprintf("%s (%s)", d.Name, d.Alias)
+		}
+		lastSeen := "-"
+		if d.LastSeen != nil {
+			lastSeen = d.LastSeen.Local().Format("2006-01-02 15:04")
+		}
+		agent := d.AgentVersion
+		if agent == "" {
+			agent = "-"
+		}
+		autopilot := "-"
+		if d.ProxyRunning != nil {
+			autopilot = "not attached"
+			if *d.ProxyRunning {
+				autopilot = "attached"
+			}
+		}
+		rtt, loss, reconnects := "-", "-", "-"
+		if d.Link != nil {
+			rtt = fmt.Sprintf("%.0f ms", d.Link.RTTMs)
+			loss = fmt.Sprintf("%.1f%%", d.Link.LossPct)
+			reconnects = strconv.FormatUint(d.Link.Reconnects, 10)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n", d.ID, name, status, lastSeen, agent, autopilot, rtt, loss, reconnects)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	fmt.Printf("\n%d of %d devices online\n", online, len(report))
+	return nil
+}
+
+// printFleetCSV prints the fleet report as CSV with a header row. Unknown
+// values are empty cells.
+func printFleetCSV(report []fleetDevice) error {
+	w := csv.NewWriter(os.Stdout)
+	_ = w.Write([]string{"id", "name", "alias", "online", "last_seen", "agent_version", "proxy_running", "rtt_ms", "loss_pct", "reconnects", "metrics_reported_at", "tags"})
+	for _, d := range report {
+		row := []string{d.ID, d.Name, d.Alias, strconv.FormatBool(d.Online), "", d.AgentVersion, "", "", "", "", "", strings.Join(d.Tags, ",")}
+		if d.LastSeen != nil {
+			row[4] = d.LastSeen.UTC().Format(time.RFC3339)
+		}
+		if d.ProxyRunning != nil {
+			row[6] = strconv.FormatBool(*d.ProxyRunning)
+		}
+		if d.Link != nil {
+			row[7] = strconv.FormatFloat(d.Link.RTTMs, 'f', 1, 64)
+			row[8] = strconv.FormatFloat(d.Link.LossPct, 'f', 2, 64)
+			row[9] = strconv.FormatUint(d.Link.Reconnects, 10)
+			row[10] = d.Link.ReportedAt.UTC().Format(time.RFC3339)
+		}
+		_ = w.Write(row)
+	}
+	w.Flush()
+	return w.Error()
+}
@@ -0,0 +1,321 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/pavliha/aircast/aircast-cli/internal/auth"
+	"github.com/pavliha/aircast/aircast-cli/internal/store"
+	"github.com/pavliha/aircast/aircast-cli/internal/term"
+	log "github.com/sirupsen/logrus"
+)
+
+// flightCommands are the subcommands of "flights"
+var flightCommands = map[string]command{
+	"list": {"List logged flights with durations and totals", runFlightsList},
+	"open": {"Reveal a flight's folder of recordings and other artifacts", runFlightsOpen},
+}
+
+// runFlights dispatches "flights" subcommands
+func runFlights(args []string) error {
+	if len(args) == 0 || strings.HasPrefix(args[0], "-") {
+		return runFlightsList(args)
+	}
+
+	cmd, ok := flightCommands[args[0]]
+	if !ok {
+		printSubcommands("flights", flightCommands)
+		return fmt.Errorf("unknown flights command %q", args[0])
+	}
+	return cmd.run(args[1:])
+}
+
+// flightTracker turns arming transitions into flight log entries
+type flightTracker struct {
+	store    *store.FlightLog
+	folder   *store.FlightFolder // Session artifacts, nil if unavailable
+	deviceID string
+	name     string
+	logger   *log.Entry
+
+	mu      sync.Mutex
+	armedAt time.Time // Zero while disarmed
+}
+
+// newFlightTracker creates a tracker for the bridged device, or returns nil
+// if the flight log can't be opened
+func newFlightTracker(deviceID, name string, folder *store.FlightFolder, logger *log.Entry) *flightTracker {
+	store, err := store.NewFlightLog()
+	if err != nil {
+		logger.WithError(err).Warn("Flight log unavailable")
+		return nil
+	}
+	return &flightTracker{store: store, folder: folder, deviceID: deviceID, name: name, logger: logger}
+}
+
+// newFlightFolder prepares the folder for this session's artifacts, or
+// returns nil if the config directory is unavailable
+func newFlightFolder(deviceID, name string, logger *log.Entry) *store.FlightFolder {
+	folder, err := store.NewFlightFolder(deviceID, name, time.Now())
+	if err != nil {
+		logger.WithError(err).Warn("Flight folder unavailable, artifacts are written to the current directory")
+		return nil
+	}
+	return folder
+}
+
+// isPath reports whether an artifact name includes a directory, as opposed
+// to a bare file name
+func isPath(name string) bool {
+	return filepath.IsAbs(name) || strings.ContainsAny(name, `/\`)
+}
+
+// artifactPath returns where to write an artifact. Bare file names go into
+// the session's flight folder so they don't get lost in the working
+// directory; paths, e.g. a recording shared by several bridges, are kept.
+func artifactPath(folder *store.FlightFolder, name, kind string) (string, error) {
+	if folder == nil || isPath(name) {
+		return name, nil
+	}
+	return folder.Add(name, kind)
+}
+
+// Armed records an arming state change reported by the bridge
+func (ft *flightTracker) Armed(armed bool, at time.Time) {
+	ft.mu.Lock()
+	defer ft.mu.Unlock()
+
+	if armed {
+		ft.armedAt = at
+		fmt.Printf("\n%sArmed at %s\n\n", term.Symbol("🛫 ", ""), at.Local().Format("15:04:05"))
+		ft.logger.Info("Vehicle armed")
+		return
+	}
+	if ft.armedAt.IsZero() {
+		return
+	}
+
+	flight := ft.finish(at, false)
+	fmt.Printf("\n%sDisarmed after %s\n\n", term.Symbol("🛬 ", ""), formatFlightDuration(flight.Duration()))
+}
+
+// Close logs a flight still in progress when the bridge stops
+func (ft *flightTracker) Close() {
+	ft.mu.Lock()
+	defer ft.mu.Unlock()
+
+	if !ft.armedAt.IsZero() {
+		ft.finish(time.Now(), true)
+	}
+}
+
+// finish writes the current flight to the log
+func (ft *flightTracker) finish(at time.Time, incomplete bool) store.Flight {
+	flight := store.Flight{
+		DeviceID:   ft.deviceID,
+		DeviceName: ft.name,
+		ArmedAt:    ft.armedAt.UTC(),
+		DisarmedAt: at.UTC(),
+		Incomplete: incomplete,
+	}
+	ft.armedAt = time.Time{}
+
+	if ft.folder != nil {
+		if ft.folder.Created() {
+			flight.Folder = ft.folder.Path()
+		}
+		if err := ft.folder.AddFlight(flight); err != nil {
+			ft.logger.WithError(err).Warn("Failed to update flight folder index")
+		}
+	}
+
+	fields := log.Fields{"duration": flight.Duration().Round(time.Second), "incomplete": incomplete}
+	if err := ft.store.Append(flight); err != nil {
+		ft.logger.WithError(err).WithFields(fields).Warn("Failed to log flight")
+	} else {
+		ft.logger.WithFields(fields).Info("Flight logged")
+	}
+	return flight
+}
+
+// formatFlightDuration formats a flight duration as h:mm:ss or m:ss
+func formatFlightDuration(d time.Duration) string {
+	d = d.Round(time.Second)
+	h, m, s := int(d.Hours()), int(d.Minutes())%60, int(d.Seconds())%60
+	if h > 0 {
+		return fmt.Sprintf("%d:%02d:%02d", h, m, s)
+	}
+	return fmt.Sprintf("%d:%02d", m, s)
+}
+
+// runFlightsList prints the flight log
+func runFlightsList(args []string) error {
+	fs := flag.NewFlagSet("flights list", flag.ExitOnError)
+	device := fs.String("device", "", "Only show flights of this device ID or alias")
+	limit := fs.Int("limit", 20, "Show at most this many of the most recent flights (0 = all)")
+	_ = fs.Parse(args)
+
+	flights, err := loggedFlights(*device)
+	if err != nil {
+		return err
+	}
+	if len(flights) == 0 {
+		fmt.Println("No flights logged yet")
+		return nil
+	}
+
+	var total time.Duration
+	for _, f := range flights {
+		total += f.Duration()
+	}
+
+	first := 0
+	if *limit > 0 && len(flights) > *limit {
+		first = len(flights) - *limit
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "#\tDATE\tDEVICE\tARMED\tDISARMED\tDURATION\tFOLDER")
+	for i, f := range flights[first:] {
+		name := f.DeviceName
+		if name == "" {
+			name = f.DeviceID
+		}
+		duration := formatFlightDuration(f.Duration())
+		if f.Incomplete {
+			duration += " (bridge stopped while armed)"
+		}
+		folder := ""
+		if f.Folder != "" {
+			folder = "yes"
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			first+i+1,
+			f.ArmedAt.Local().Format("2006-01-02"),
+			name,
+			f.ArmedAt.Local().Format("15:04:05"),
+			f.DisarmedAt.Local().Format("15:04:05"),
+			duration,
+			folder,
+		)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	noun := "flights"
+	if len(flights) == 1 {
+		noun = "flight"
+	}
+	fmt.Printf("\n%d %s, %s total\n", len(flights), noun, formatFlightDuration(total))
+	return nil
+}
+
+// resolveDeviceFilter resolves a --device filter, which may be an alias
+func resolveDeviceFilter(device string) (string, error) {
+	if device == "" {
+		return "", nil
+	}
+	configStore, err := auth.NewConfigStore()
+	if err != nil {
+		return "", err
+	}
+	return configStore.ResolveDevice(device)
+}
+
+// loggedFlights returns the flight log, optionally for one device ID or alias
+func loggedFlights(device string) ([]store.Flight, error) {
+	deviceID, err := resolveDeviceFilter(device)
+	if err != nil {
+		return nil, err
+	}
+
+	store, err := store.NewFlightLog()
+	if err != nil {
+		return nil, err
+	}
+	return store.List(deviceID)
+}
+
+// runFlightsOpen reveals the folder of a flight from 'flights list', or of
+// the latest session that kept artifacts
+func runFlightsOpen(args []string) error {
+	fs := flag.NewFlagSet("flights open", flag.ExitOnError)
+	device := fs.String("device", "", "Number only this device's flights, as 'flights list --device' does")
+	printOnly := fs.Bool("print", false, "Print the folder instead of opening it")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: aircast-cli flights open [flags] [n]\n\n")
+		fmt.Fprintf(fs.Output(), "n is a flight number from 'aircast-cli flights list'; without it the latest folder opens\n\n")
+		fs.PrintDefaults()
+	}
+	positional := parseArgs(fs, args)
+	if len(positional) > 1 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	var folder string
+	if len(positional) == 0 {
+		deviceID, err := resolveDeviceFilter(*device)
+		if err != nil {
+			return err
+		}
+		indexes, err := store.ListFlightFolders(deviceID)
+		if err != nil {
+			return err
+		}
+		if len(indexes) == 0 {
+			return fmt.Errorf("no flight folders yet; sessions get one when they record, e.g. with --record flight.rec")
+		}
+		folder = indexes[len(indexes)-1].Path
+	} else {
+		flights, err := loggedFlights(*device)
+		if err != nil {
+			return err
+		}
+		n, err := strconv.Atoi(positional[0])
+		if err != nil || n < 1 || n > len(flights) {
+			return fmt.Errorf("no flight %q; see 'aircast-cli flights list'", positional[0])
+		}
+		if folder = flights[n-1].Folder; folder == "" {
+			return fmt.Errorf("flight %d has no folder; only sessions that record keep one", n)
+		}
+	}
+
+	if _, err := os.Stat(folder); err != nil {
+		return fmt.Errorf("flight folder %s is gone", folder)
+	}
+	if *printOnly || !revealFolder(folder) {
+		fmt.Println(folder)
+		return nil
+	}
+	fmt.Printf("Opened %s\n", folder)
+	return nil
+}
+
+// revealFolder opens a folder in the desktop's file manager. It returns
+// false where there is none, e.g. over SSH.
+func revealFolder(path string) bool {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("open", path)
+	case "windows":
+		cmd = exec.Command("explorer", path)
+	default:
+		if os.Getenv("DISPLAY") == "" && os.Getenv("WAYLAND_DISPLAY") == "" {
+			return false
+		}
+		cmd = exec.Command("xdg-open", path)
+	}
+	return cmd.Start() == nil
+}
@@ -0,0 +1,141 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pavliha/aircast/aircast-cli/internal/auth"
+	"github.com/pavliha/aircast/aircast-cli/internal/store"
+)
+
+// globalFlag is a flag accepted before or after any command. It's stored in
+// its environment variable, so a --daemon bridge inherits it.
+type globalFlag struct {
+	env     string
+	boolean bool
+	usage   string
+}
+
+// globalFlags are the flags that apply to every command
+var globalFlags = map[string]globalFlag{
+	"config-dir":   {"AIRCAST_CONFIG_DIR", false, "Keep login, config, logs and caches in this directory instead of ~/.aircast"},
+	"portable":     {"AIRCAST_PORTABLE", true, "Keep login, config, logs and caches in " + portableDirName + " next to the executable, e.g. on a USB drive"},
+	"system-token": {"AIRCAST_SYSTEM_TOKEN", true, "Use the login shared by all users of this machine (" + auth.SystemTokenDir() + ")"},
+}
+
+// printGlobalFlags prints the global flags in name order for usage
+func printGlobalFlags() {
+	out := flag.CommandLine.Output()
+
+	names := make([]string, 0, len(globalFlags))
+	for name := range globalFlags {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		f := globalFlags[name]
+		arg := " string"
+		if f.boolean {
+			arg = ""
+		}
+		fmt.Fprintf(out, "  -%s%s\n    \t%s (also %s)\n", name, arg, f.usage, f.env)
+	}
+}
+
+// extractGlobalFlags removes global flags from the command line, wherever
+// they appear, and stores them in their environment variables
+func extractGlobalFlags(args []string) ([]string, error) {
+	var rest []string
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" {
+			rest = append(rest, args[i:]...)
+			break
+		}
+		name, value, hasValue := strings.Cut(strings.TrimLeft(arg, "-"), "=")
+		flag, ok := globalFlags[name]
+		if !ok || !strings.HasPrefix(arg, "-") {
+			rest = append(rest, arg)
+			continue
+		}
+
+		switch {
+		case flag.boolean && !hasValue:
+			value = "1"
+		case flag.boolean:
+			on, err := strconv.ParseBool(value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid value %q for --%s", value, name)
+			}
+			value = ""
+			if on {
+				value = "1"
+			}
+		case !hasValue:
+			if i+1 >= len(args) {
+				return nil, fmt.Errorf("flag needs an argument: --%s", name)
+			}
+			i++
+			value = args[i]
+		}
+		if err := os.Setenv(flag.env, value); err != nil {
+			return nil, err
+		}
+	}
+	return rest, nil
+}
+
+// portableDirName is the directory next to the executable that holds the
+// state of a portable install
+const portableDirName = "aircast-data"
+
+// portableDir returns the state directory of a portable install
+func portableDir() (string, error) {
+	exe, err := os.Executable()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate executable: %w", err)
+	}
+	if resolved, err := filepath.EvalSymlinks(exe); err == nil {
+		exe = resolved
+	}
+	return filepath.Join(filepath.Dir(exe), portableDirName), nil
+}
+
+// applyConfigDir relocates the config directory and login as set by
+// AIRCAST_CONFIG_DIR, AIRCAST_PORTABLE and AIRCAST_SYSTEM_TOKEN. A binary
+// with a portable directory next to it stays portable without the flag.
+func applyConfigDir() error {
+	dir := os.Getenv("AIRCAST_CONFIG_DIR")
+	if dir == "" {
+		portable, err := portableDir()
+		if os.Getenv("AIRCAST_PORTABLE") != "" {
+			if err != nil {
+				return err
+			}
+			if err := os.MkdirAll(portable, 0700); err != nil {
+				return fmt.Errorf("portable mode needs a writable directory next to the executable: %w", err)
+			}
+			dir = portable
+		} else if info, statErr := os.Stat(portable); err == nil && statErr == nil && info.IsDir() {
+			dir = portable
+		}
+	}
+
+	if dir != "" {
+		if err := store.SetConfigDir(dir); err != nil {
+			return err
+		}
+		// Background bridges may run from another working directory
+		abs, _ := store.ConfigDirPath()
+		_ = os.Setenv("AIRCAST_CONFIG_DIR", abs)
+	}
+	if os.Getenv("AIRCAST_SYSTEM_TOKEN") != "" {
+		return auth.UseSystemTokenStore()
+	}
+	return nil
+}
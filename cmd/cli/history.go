@@ -0,0 +1,185 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"text/tabwriter"
+	"time"
+
+	"github.com/pavliha/aircast/aircast-cli/internal/cli"
+	"github.com/pavliha/aircast/aircast-cli/internal/store"
+)
+
+// runHistory lists past bridge sessions from the statistics database, or
+// totals per month to compare link quality over time
+func runHistory(args []string) error {
+	fs := flag.NewFlagSet("history", flag.ExitOnError)
+	device := fs.String("device", "", "Only sessions of this device ID or alias")
+	site := fs.String("site", "", "Only sessions at this site (see the bridge's --site)")
+	months := fs.Int("months", 0, fmt.Sprintf("Only sessions of the last N months (default all, up to %d)", store.StatsMonths))
+	monthly := fs.Bool("monthly", false, "Show totals per month, site and device instead of sessions")
+	limit := fs.Int("limit", 50, "Show at most this many of the latest sessions (0 for all)")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: aircast-cli history [flags]\n\n")
+		fmt.Fprintf(fs.Output(), "Shows past bridge sessions with their duration, data used and estimated loss.\n\n")
+		fs.PrintDefaults()
+	}
+	if len(parseArgs(fs, args)) != 0 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	if *months < 0 || *months > store.StatsMonths {
+		return fmt.Errorf("--months must be between 1 and %d: older sessions aren't kept", store.StatsMonths)
+	}
+
+	deviceID := ""
+	if *device != "" {
+		var err error
+		if deviceID, err = resolveDeviceArg(*device); err != nil {
+			return err
+		}
+	}
+
+	db, err := store.NewStatsDB()
+	if err != nil {
+		return err
+	}
+	all, err := db.All()
+	if err != nil {
+		return err
+	}
+
+	// Sessions past the retention stay in the file until the next is added
+	since := store.MonthsStart(time.Now(), store.StatsMonths)
+	if *months > 0 {
+		since = store.MonthsStart(time.Now(), *months)
+	}
+	var records []store.SessionRecord
+	for _, rec := range all {
+		if (deviceID != "" && rec.DeviceID != deviceID) || (*site != "" && rec.Site != *site) || rec.StartedAt.Before(since) {
+			continue
+		}
+		records = append(records, rec)
+	}
+	if len(records) == 0 && len(all) > 0 {
+		fmt.Println("No matching sessions.")
+		return nil
+	}
+	if len(records) == 0 {
+		fmt.Println("No sessions recorded yet. Sessions are added when the bridge stops.")
+		return nil
+	}
+
+	if *monthly {
+		return printMonthlyHistory(records)
+	}
+	if *limit > 0 && len(records) > *limit {
+		records = records[len(records)-*limit:]
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "STARTED\tDEVICE\tSITE\tDURATION\tDATA\tLOSS\tRECONNECTS")
+	for i := len(records) - 1; i >= 0; i-- {
+		rec := records[i]
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%.1f%%\t%d\n",
+			rec.StartedAt.Local().Format("2006-01-02 15:04"),
+			historyDevice(rec.DeviceID, rec.DeviceName),
+			historySite(rec.Site),
+			rec.Duration().Round(time.Second),
+			cli.FormatBytes(rec.BytesUp+rec.BytesDown),
+			rec.LossRate()*100,
+			rec.Reconnects)
+	}
+	return w.Flush()
+}
+
+// monthTotals adds up the sessions of one month, site and device
+type monthTotals struct {
+	month      string
+	site       string
+	device     string
+	sessions   int
+	duration   time.Duration
+	bytes      uint64
+	frames     uint64
+	lost       uint64
+	reconnects uint64
+}
+
+// printMonthlyHistory prints totals per month, site and device, newest month
+// first, with loss weighted by frames so long sessions count more
+func printMonthlyHistory(records []store.SessionRecord) error {
+	groups := make(map[[3]string]*monthTotals)
+	for _, rec := range records {
+		month := rec.StartedAt.Local().Format("2006-01")
+		device := historyDevice(rec.DeviceID, rec.DeviceName)
+		key := [3]string{month, rec.Site, rec.DeviceID}
+		t, ok := groups[key]
+		if !ok {
+			t = &monthTotals{month: month, site: rec.Site, device: device}
+			groups[key] = t
+		}
+		t.device = device // The latest name
+		t.sessions++
+		t.duration += rec.Duration()
+		t.bytes += rec.BytesUp + rec.BytesDown
+		t.frames += rec.Frames
+		t.lost += rec.Lost
+		t.reconnects += rec.Reconnects
+	}
+
+	totals := make([]*monthTotals, 0, len(groups))
+	for _, t := range groups {
+		totals = append(totals, t)
+	}
+	sort.Slice(totals, func(i, j int) bool {
+		a, b := totals[i], totals[j]
+		if a.month != b.month {
+			return a.month > b.month
+		}
+		if a.site != b.site {
+			return a.site < b.site
+		}
+		return a.device < b.device
+	})
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "MONTH\tSITE\tDEVICE\tSESSIONS\tCONNECTED\tDATA\tLOSS\tRECONNECTS/H")
+	for _, t := range totals {
+		loss := 0.0
+		if t.frames+t.lost > 0 {
+			loss = float64(t.lost) / float64(t.frames+t.lost) * 100
+		}
+		perHour := 0.0
+		if t.duration > 0 {
+			perHour = float64(t.reconnects) / t.duration.Hours()
+		}
+		connected := t.duration.Round(time.Second)
+		if t.duration >= time.Hour {
+			connected = t.duration.Round(time.Minute)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%s\t%s\t%.1f%%\t%.1f\n",
+			t.month, historySite(t.site), t.device, t.sessions,
+			connected, cli.FormatBytes(t.bytes), loss, perHour)
+	}
+	return w.Flush()
+}
+
+// historyDevice names a device by its name when known
+func historyDevice(id, name string) string {
+	if name != "" {
+		return name
+	}
+	return id
+}
+
+// historySite shows sessions without a site as "-"
+func historySite(site string) string {
+	if site == "" {
+		return "-"
+	}
+	return site
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/pavliha/aircast/aircast-cli/internal/mavlink"
+	log "github.com/sirupsen/logrus"
+)
+
+// defaultIdentifyText is the --identify banner unless --identify-text says otherwise
+const defaultIdentifyText = "via Aircast: {device} / {env}"
+
+// identifyBanner expands an --identify-text template: {device} is the
+// device's alias, or its name, or its ID, {name} and {id} are always the
+// name and ID, and {env} is the API environment, e.g. "prod"
+func identifyBanner(template, deviceID, name, alias, env string, logger *log.Entry) string {
+	device := deviceID
+	switch {
+	case alias != "":
+		device = alias
+	case name != "":
+		device = name
+	}
+	if name == "" {
+		name = deviceID
+	}
+
+	banner := strings.NewReplacer(
+		"{device}", device,
+		"{name}", name,
+		"{id}", deviceID,
+		"{env}", env,
+	).Replace(template)
+
+	if len(banner) > mavlink.MaxStatusTextLen {
+		logger.WithField("banner", banner).Warnf("Client banner is longer than %d characters and will be cut off", mavlink.MaxStatusTextLen)
+	}
+	return banner
+}
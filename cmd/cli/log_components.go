@@ -0,0 +1,82 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// logComponents maps the names accepted by --log-components to the
+// "component" values their log entries carry. Entries without a component
+// come from the bridge itself.
+var logComponents = map[string][]string{
+	"bridge":  {"", "bridge"},
+	"auth":    {"auth", "browser_auth", "device_code_auth", "token"},
+	"aux":     {"aux"},
+	"control": {"control"},
+	"events":  {"events"},
+	"http":    {"http"},
+	"map":     {"map"},
+	"metrics": {"metrics"},
+}
+
+// componentLevels is the log level of each component selected with
+// --log-components, keyed by the entries' "component" value. A nil
+// componentLevels logs every component.
+type componentLevels map[string]log.Level
+
+// parseLogComponents parses a list such as "bridge,auth=debug". Components
+// without a level use defaultLevel.
+func parseLogComponents(spec string, defaultLevel log.Level) (componentLevels, error) {
+	if strings.TrimSpace(spec) == "" {
+		return nil, nil
+	}
+
+	levels := componentLevels{}
+	for _, item := range strings.Split(spec, ",") {
+		name, levelName, hasLevel := strings.Cut(strings.TrimSpace(item), "=")
+		values, ok := logComponents[name]
+		if !ok {
+			names := make([]string, 0, len(logComponents))
+			for n := range logComponents {
+				names = append(names, n)
+			}
+			sort.Strings(names)
+			return nil, fmt.Errorf("unknown log component %q (available: %s)", name, strings.Join(names, ", "))
+		}
+
+		level := defaultLevel
+		if hasLevel {
+			var err error
+			if level, err = log.ParseLevel(levelName); err != nil {
+				return nil, fmt.Errorf("invalid level for log component %s: %w", name, err)
+			}
+		}
+		for _, value := range values {
+			levels[value] = level
+		}
+	}
+	return levels, nil
+}
+
+// max returns the most verbose level of any component, or level if that is
+// more verbose
+func (c componentLevels) max(level log.Level) log.Level {
+	for _, l := range c {
+		level = max(level, l)
+	}
+	return level
+}
+
+// allows reports whether an entry should be logged. Errors are always
+// logged, whatever their component.
+func (c componentLevels) allows(entry *log.Entry) bool {
+	if c == nil || entry.Level <= log.ErrorLevel {
+		return true
+	}
+	component, _ := entry.Data["component"].(string)
+	level, ok := c[component]
+	return ok && entry.Level <= level
+}
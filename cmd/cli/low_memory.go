@@ -0,0 +1,30 @@
+package main
+
+import (
+	"os"
+	"runtime/debug"
+
+	"github.com/pavliha/aircast/aircast-cli/internal/ui"
+)
+
+// GC settings in low-memory mode, unless GOGC or GOMEMLIMIT are set. The
+// soft limit makes the collector work harder as the heap nears it instead
+// of letting it double between collections.
+const (
+	lowMemoryGCPercent = 50
+	lowMemoryLimit     = 24 << 20 // 24 MiB
+)
+
+// applyLowMemory trades some CPU for a smaller footprint on devices such as
+// a Raspberry Pi Zero, where the bridge shares little RAM with a video
+// pipeline: no full-screen interfaces and a tighter garbage collector. The
+// bridge itself shrinks its buffers via cli.Config.LowMemory.
+func applyLowMemory() {
+	ui.DisableScreens()
+	if os.Getenv("GOGC") == "" {
+		debug.SetGCPercent(lowMemoryGCPercent)
+	}
+	if os.Getenv("GOMEMLIMIT") == "" {
+		debug.SetMemoryLimit(lowMemoryLimit)
+	}
+}
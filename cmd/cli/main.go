@@ -4,8 +4,12 @@ import (
 	"context"
 	"flag"
 	"fmt"
+	"io"
+	"net"
 	"os"
 	"os/signal"
+	"strings"
+	"sync/atomic"
 	"syscall"
 	"time"
 
@@ -13,7 +17,15 @@ import (
 	"github.com/pavliha/aircast/aircast-cli/internal/api"
 	"github.com/pavliha/aircast/aircast-cli/internal/auth"
 	"github.com/pavliha/aircast/aircast-cli/internal/cli"
+	"github.com/pavliha/aircast/aircast-cli/internal/control"
+	"github.com/pavliha/aircast/aircast-cli/internal/mapview"
+	"github.com/pavliha/aircast/aircast-cli/internal/network"
+	"github.com/pavliha/aircast/aircast-cli/internal/recording"
+	"github.com/pavliha/aircast/aircast-cli/internal/systemd"
+	"github.com/pavliha/aircast/aircast-cli/internal/term"
+	"github.com/pavliha/aircast/aircast-cli/internal/tsdb"
 	"github.com/pavliha/aircast/aircast-cli/internal/ui"
+	"github.com/pavliha/aircast/aircast-cli/internal/webhook"
 	log "github.com/sirupsen/logrus"
 )
 
@@ -27,19 +39,158 @@ func main() {
 	// Load .env file if it exists (silent fail if not present)
 	_ = godotenv.Load()
 
+	flag.Usage = usage
+
+	// Credentials are removed from all log output, before any sink sees it
+	log.AddHook(logRedactor)
+
+	// Global flags may appear anywhere on the command line
+	args, err := extractGlobalFlags(os.Args[1:])
+	if err == nil {
+		err = applyConfigDir()
+	}
+	if err == nil {
+		err = loadRedactPatterns()
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	// Dual-stack dialing, host overrides, custom DNS and interface binding
+	// for all HTTP requests
+	network.Configure()
+	if err := applyNetworkOptions(envNetworkOptions()); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	retries, timeout, err := envAPIPolicy()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	applyAPIPolicy(retries, timeout)
+	transport, err := envTransportOptions()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	network.ConfigureTransport(transport)
+	if os.Getenv("AIRCAST_TRACE_HTTP") != "" {
+		network.EnableHTTPTrace(log.WithField("app", "aircast-cli"))
+	}
+	applyAccessible(os.Getenv("AIRCAST_ACCESSIBLE") != "")
+
+	// Dispatch subcommands; anything else runs the bridge
+	if len(args) > 0 && args[0] == completeCommand {
+		runComplete(args[1:])
+		return
+	}
+	if len(args) > 0 {
+		if cmd, ok := commands[args[0]]; ok {
+			if err := cmd.run(args[1:]); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+			return
+		}
+	}
+
+	runBridge(args)
+}
+
+// runConnect is the "connect" command, equivalent to running without a command
+func runConnect(args []string) error {
+	runBridge(args)
+	return nil
+}
+
+// runBridge authenticates, selects a device and runs the MAVLink bridge
+func runBridge(args []string) {
 	// Command line flags - simplified!
 	var (
 		deviceID    = flag.String("device", "", "Device ID to connect to (optional - will prompt to select)")
-		apiURL      = flag.String("api", getEnv("AIRCAST_API_URL", "https://api.aircast.one"), "API base URL")
+		deviceNamed = flag.String("device-name", getEnv("AIRCAST_DEVICE_NAME", ""), "Device name to connect to, looked up in the local index of device names so it works offline")
+		deviceTag   = flag.String("tag", getEnv("AIRCAST_TAG", ""), "Only offer devices with these tags in the picker, comma-separated; all must match")
+		apiURL      = flag.String("api", defaultAPIURL(), "API base URL")
 		tcpListen   = flag.String("tcp", getEnv("AIRCAST_TCP_LISTEN", "127.0.0.1:5169"), "TCP listen address for MAVLink clients")
 		udpListen   = flag.String("udp", getEnv("AIRCAST_UDP_LISTEN", ""), "UDP listen address for MAVLink clients (optional)")
 		doLogin     = flag.Bool("login", false, "Force re-authentication (clear stored token)")
 		doLogout    = flag.Bool("logout", false, "Clear stored authentication token")
-		logLevel    = flag.String("log-level", getEnv("LOG_LEVEL", "info"), "Log level (trace, debug, info, warn, error)")
+		logLevel    = flag.String("log-level", getEnv("LOG_LEVEL", "info"), "Log level (trace, debug, info, warn, error); on a terminal only warnings and errors are shown unless set")
+		logComps    = flag.String("log-components", getEnv("AIRCAST_LOG_COMPONENTS", ""), "Only log these components, optionally at their own level, e.g. bridge,auth=debug (errors are always logged)")
+		logFile     = flag.String("log-file", getEnv("AIRCAST_LOG_FILE", ""), "Write the diagnostic log to this file instead of the terminal (errors are still shown)")
 		showVersion = flag.Bool("version", false, "Show version information")
+		dropCorrupt = flag.Bool("drop-corrupted", false, "Drop MAVLink frames that fail CRC validation instead of forwarding them")
+		statsEvery  = flag.Duration("stats-interval", 0, "Log traffic statistics at this interval (e.g. 30s, 0 to disable)")
+		maxClients  = flag.Int("max-clients", 0, "Maximum concurrent TCP clients (0 = unlimited)")
+		maxPerIP    = flag.Int("max-clients-per-ip", 0, "Maximum concurrent TCP clients per source IP (0 = unlimited)")
+		tcpNagle    = flag.Bool("tcp-nagle", false, "Enable Nagle's algorithm on TCP client sockets (fewer packets, more latency)")
+		coalesce    = flag.Duration("coalesce", 0, "Batch downlink writes to TCP clients over this interval (e.g. 5ms, 0 to disable)")
+		dedupWindow = flag.Duration("dedup-window", 0, "Drop uplink frames identical to one another client sent within this window (e.g. 200ms, 0 to disable)")
+		uplinkBatch = flag.Duration("uplink-batch", 0, "Send small uplink frames together in one WebSocket message over this interval, e.g. 15ms for high-rate RC overrides (0 = off, every frame is sent at once)")
+		controlSock = flag.String("control-socket", defaultControlSocket(), "Control socket path for clients/kick commands (empty to disable)")
+		useCached   = flag.Bool("cached", false, "Use the cached device list if the API is unreachable")
+		shareMetric = flag.Bool("share-metrics", getEnv("AIRCAST_SHARE_METRICS", "") != "", "Report anonymized link quality (latency, loss, reconnects) to the Aircast fleet dashboard")
+		shareDiag   = flag.Bool("share-diagnostics", false, "Upload a connection report to Aircast support if no data was received")
+		bwProfile   = flag.String("profile-bandwidth", getEnv("AIRCAST_PROFILE_BANDWIDTH", cli.ProfileFull), "Downlink bandwidth profile: full, low-bandwidth, cellular-minimal or one defined in config.json")
+		sourceRates = flag.Bool("source-rates", getEnv("AIRCAST_SOURCE_RATES", "") != "", "Ask the autopilot to send telemetry at the bandwidth profile's rates (SET_MESSAGE_INTERVAL) instead of dropping the excess at the bridge")
+		adaptRate   = flag.Float64("adaptive-rate", 0, "Adapt downlink message rates to link latency and loss (AIMD), between 1 Hz and this maximum in Hz (0 = off)")
+		dataBudget  = flag.String("data-budget", getEnv("AIRCAST_DATA_BUDGET", ""), "Data usage limit, e.g. 500MB/day or 1GB/session; telemetry is reduced once exceeded")
+		readyFile   = flag.String("ready-file", getEnv("AIRCAST_READY_FILE", ""), "File to create once data is flowing from the device, removed on exit")
+		auxListen   = flag.String("aux", getEnv("AIRCAST_AUX", ""), "Also bridge the device's companion data channel (newline-delimited JSON) to this TCP address, e.g. 127.0.0.1:5800")
+		liveEvents  = flag.Bool("events", true, "Show device online/offline, agent update and ownership events from the API while running")
+		mapListen   = flag.String("map-listen", getEnv("AIRCAST_MAP_LISTEN", ""), "Serve a live map of the vehicle for observers on this address, e.g. :8090")
+		recordFile  = flag.String("record", getEnv("AIRCAST_RECORD", ""), "Record traffic to this file; a bare name goes into the session's flight folder. Bridges for several devices share one file for synchronized replay when given the same path, e.g. ./mission.acrec")
+		skipCompat  = flag.Bool("skip-compat-check", getEnv("AIRCAST_SKIP_COMPAT_CHECK", "") != "", "Don't check whether the API still supports this CLI version")
+		lowMemory   = flag.Bool("low-memory", getEnv("AIRCAST_LOW_MEMORY", "") != "", "Reduce memory use for small devices such as a Raspberry Pi Zero: smaller buffers, no full-screen interfaces, tighter GC")
+		daemon      = flag.Bool("daemon", false, "Run the bridge in the background (Linux and macOS); stop it with 'aircast-cli stop'")
+		pidFile     = flag.String("pid-file", getEnv("AIRCAST_PID_FILE", defaultPIDFile()), "PID file written by the --daemon bridge")
+		quiet       = flag.Bool("quiet", false, "Suppress banners and progress; print only a READY line on stdout and errors on stderr")
 	)
 
-	flag.Parse()
+	var resolves stringList
+	flag.Var(&resolves, "resolve", "Static host override host:port:address, e.g. api.aircast.one:443:10.0.0.5 (repeatable)")
+	var alarmFlags stringList
+	flag.Var(&alarmFlags, "alarm", "Telemetry alarm such as battery<20, hdop>2.5 or rssi<40 (repeatable; also \"alarms\" in config.json)")
+	var remapFlags stringList
+	flag.Var(&remapFlags, "remap", "Rewrite source IDs, e.g. gcs:*/*=255/190 or device:1/1=2/1 (repeatable; also \"remap\" in config.json)")
+	alarmHook := flag.String("alarm-hook", getEnv("AIRCAST_ALARM_HOOK", ""), "Command run when an alarm is raised or cleared, with details in AIRCAST_ALARM_* variables")
+	dnsServer := flag.String("dns", "", "DNS server for API lookups, e.g. 10.0.0.1 or 10.0.0.1:5353")
+	bindIface := flag.String("bind-interface", getEnv("AIRCAST_BIND_INTERFACE", ""), "Connect to the API through this interface or local address, e.g. wwan0 or 10.64.0.2, instead of the default route")
+	apiRetries := flag.Int("api-retries", -1, "Retries for failed idempotent API calls with exponential backoff (default 3, env AIRCAST_API_RETRIES)")
+	traceHTTP := flag.Bool("trace-http", false, "Log metadata of every API request and response, with credentials redacted (env AIRCAST_TRACE_HTTP)")
+	apiTimeout := flag.Duration("api-timeout", 0, "Timeout per API call attempt (default 10s, env AIRCAST_API_TIMEOUT)")
+	apiHTTP2 := flag.Bool("api-http2", true, "Use HTTP/2 for API requests when the server offers it (env AIRCAST_API_HTTP2)")
+	apiKeepAlive := flag.Duration("api-keepalive", 0, "TCP keep-alive interval of API connections (default 30s, negative disables, env AIRCAST_API_KEEPALIVE)")
+	apiIdleTimeout := flag.Duration("api-idle-timeout", 0, "How long idle API connections are kept for reuse (default 90s, negative closes them after each request, env AIRCAST_API_IDLE_TIMEOUT)")
+	heartbeat := flag.Duration("heartbeat", 0, "Send a ground station HEARTBEAT to the device at this interval (e.g. 1s) for device proxies that shut down without GCS traffic (0 = off)")
+	heartbeatSys := flag.Int("heartbeat-sysid", 255, "System ID of the --heartbeat messages")
+	heartbeatComp := flag.Int("heartbeat-compid", 240, "Component ID of the --heartbeat messages; the default, MAV_COMP_ID_UDP_BRIDGE, keeps them apart from ground stations' own")
+	heartbeatAlways := flag.Bool("heartbeat-always", false, "Send --heartbeat messages even while a client sends its own heartbeats")
+	eventLogOn := flag.Bool("event-log", true, "Write session lifecycle events (auth, device selection, link, clients, alarms) as JSON lines to events.jsonl in the session's flight folder")
+	expires := flag.Duration("expires", 0, "End the session automatically after this long (e.g. 2h), for handing a temporary link to a guest pilot; ground station commands are blocked in the last minute (0 = no limit)")
+	identify := flag.Bool("identify", getEnv("AIRCAST_IDENTIFY", "") != "", "Greet each new ground station client with a STATUSTEXT naming the device and environment, to tell open links apart")
+	identifyText := flag.String("identify-text", getEnv("AIRCAST_IDENTIFY_TEXT", defaultIdentifyText), "Text of the --identify message; {device}, {name}, {id} and {env} are replaced")
+	radioStatus := flag.Duration("radio-status", 0, "Send ground stations a RADIO_STATUS at this interval (e.g. 1s) with the cloud link's loss and latency as signal levels, for their link quality widgets (0 = off)")
+	bondIfaces := flag.String("bond", getEnv("AIRCAST_BOND", ""), "Connect to the device over two local interfaces, primary first, e.g. eth0,wwan0")
+	routeList := flag.String("routes", getEnv("AIRCAST_ROUTES", ""), "Interfaces to choose between by connection quality, e.g. starlink0,wwan0; the fastest is used and re-checked every 30s")
+	endpointList := flag.String("api-endpoints", getEnv("AIRCAST_API_ENDPOINTS", ""), "Further API base URLs serving the same devices, e.g. https://eu.api.aircast.one; the fastest of these and --api is used, like with --routes")
+	bondMode := flag.String("bond-mode", getEnv("AIRCAST_BOND_MODE", cli.BondDuplicate), "How --bond uses the second interface: duplicate (both links carry telemetry) or failover (only while the primary is down)")
+	site := flag.String("site", getEnv("AIRCAST_SITE", ""), "Name of the flying site, recorded in the session history to compare link quality per site")
+	shutdownAfter := flag.String("shutdown-timeout", getEnv("AIRCAST_SHUTDOWN_TIMEOUT", cli.DefaultShutdownTimeout.String()), "How long to wait for the bridge to stop before giving up on stuck connections; press Ctrl+C again to exit at once")
+	stallAfter := flag.String("stall-timeout", getEnv("AIRCAST_STALL_TIMEOUT", cli.DefaultStallTimeout.String()), "Reconnect when the device stops sending for this long while ground stations are sending requests, e.g. over a half-open path (0 = never)")
+	controlMode := flag.String("control-socket-mode", getEnv("AIRCAST_CONTROL_SOCKET_MODE", ""), "Permissions of the control socket, e.g. 0660 to let its group reach it (default 0600, or 0660 with --control-socket-group)")
+	controlGroup := flag.String("control-socket-group", getEnv("AIRCAST_CONTROL_SOCKET_GROUP", ""), "Give the control socket to this group, e.g. for a monitoring agent")
+	controlToken := flag.String("control-token", getEnv(control.TokenEnv, ""), "Token control commands (kick, outputs add/remove, training) must present; env:, file:, fd: and prompt read it from elsewhere")
+	controlReadToken := flag.String("control-read-token", getEnv("AIRCAST_CONTROL_READ_TOKEN", ""), "Token that allows only queries (status, clients, outputs) over the control socket; env:, file:, fd: and prompt read it from elsewhere")
+	accessible := accessibleFlag(flag.CommandLine)
+
+	_ = flag.CommandLine.Parse(args)
+	applyAccessible(*accessible)
+	if *lowMemory {
+		applyLowMemory()
+	}
 
 	// Show version
 	if *showVersion {
@@ -47,18 +198,77 @@ func main() {
 		os.Exit(0)
 	}
 
+	// Detach into the background; the background process runs the bridge
+	if *daemon && !daemonChild() {
+		explicit := flagSet("log-level") || os.Getenv("LOG_LEVEL") != ""
+		if err := startDaemon(args, *logFile, *pidFile, explicit); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+	if daemonChild() {
+		// stderr already goes to the log file
+		*logFile = ""
+		if err := writePIDFile(*pidFile); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: failed to write PID file: %v\n", err)
+			os.Exit(1)
+		}
+		defer removePIDFile(*pidFile)
+		// Fatal errors exit without running deferred calls
+		log.RegisterExitHandler(func() { removePIDFile(*pidFile) })
+	}
+
+	// In quiet mode only errors are logged unless a level is given explicitly
+	var readyOut io.Writer = os.Stdout
+	if *quiet {
+		readyOut = enterQuietMode()
+		if !flagSet("log-level") && os.Getenv("LOG_LEVEL") == "" {
+			*logLevel = "error"
+		}
+	}
+
 	// Configure logging
 	level, err := log.ParseLevel(*logLevel)
 	if err != nil {
 		log.WithError(err).Fatal("Invalid log level")
 	}
-	log.SetLevel(level)
-	log.SetFormatter(&log.TextFormatter{
-		FullTimestamp: true,
-	})
+	components, err := parseLogComponents(*logComps, level)
+	if err != nil {
+		log.WithError(err).Fatal("Invalid --log-components")
+	}
+	explicitLevel := flagSet("log-level") || os.Getenv("LOG_LEVEL") != "" || *quiet || components != nil
+	if err := setupLogging(level, explicitLevel, *logFile, components); err != nil {
+		log.WithError(err).Fatal("Invalid --log-file")
+	}
 
 	logger := log.WithField("app", "aircast-cli")
 
+	if err := applyNetworkOptions(resolves, *dnsServer, *bindIface); err != nil {
+		logger.WithError(err).Fatal("Invalid network option")
+	}
+	applyAPIPolicy(*apiRetries, *apiTimeout)
+	// Flags override the environment, which main already validated
+	transport, _ := envTransportOptions()
+	if flagSet("api-http2") {
+		transport.DisableHTTP2 = !*apiHTTP2
+	}
+	if *apiKeepAlive != 0 {
+		transport.KeepAlive = *apiKeepAlive
+	}
+	if *apiIdleTimeout != 0 {
+		transport.IdleTimeout = *apiIdleTimeout
+	}
+	network.ConfigureTransport(transport)
+	if *traceHTTP {
+		network.EnableHTTPTrace(logger)
+	}
+
+	// Keep a copy of this session's log for support bundles
+	if err := attachSessionLog(components); err != nil {
+		logger.WithError(err).Debug("Session log disabled")
+	}
+
 	// Initialize token store
 	tokenStore, err := auth.NewTokenStore()
 	if err != nil {
@@ -71,26 +281,43 @@ func main() {
 		logger.WithError(err).Fatal("Failed to initialize config store")
 	}
 
+	// Initialize device cache
+	deviceCache, err := auth.NewDeviceCache()
+	if err != nil {
+		logger.WithError(err).Fatal("Failed to initialize device cache")
+	}
+
 	// Handle logout
 	if *doLogout {
-		if err := tokenStore.DeleteToken(); err != nil {
-			logger.WithError(err).Fatal("Failed to delete token")
-		}
-		fmt.Println("✓ Logged out successfully")
-		fmt.Printf("Token removed from: %s\n", tokenStore.GetTokenPath())
+		logout(tokenStore, logger)
+		removePIDFile(*pidFile)
 		os.Exit(0)
 	}
 
 	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
 	defer cancel()
 
+	// Lifecycle events for post-flight tooling, written once the session
+	// has a flight folder
+	events := newEventLog(*eventLogOn, logger)
+	events.emit(webhook.SessionStarted, sessionStartedData{Version: version, TCP: *tcpListen, UDP: *udpListen})
+	env := auth.DetectEnvironment(*apiURL)
+
 	// Get or authenticate token
 	var accessToken string
 
 	// Force login if requested
+	loginReason := "required"
 	if *doLogin {
 		logger.Info("Forcing re-authentication")
 		_ = tokenStore.DeleteToken()
+		loginReason = "forced"
+	}
+
+	// Judge token expiry by the server's clock, not a possibly wrong local one
+	syncClock(ctx, *apiURL, tokenStore, logger)
+	if !*skipCompat {
+		checkCompatibility(ctx, *apiURL, logger)
 	}
 
 	// Try to load existing token
@@ -99,43 +326,83 @@ func main() {
 		logger.WithError(err).Warn("Failed to load stored token")
 	}
 
+	warnEnvironmentSwitch(storedToken, *apiURL, logger)
+
 	// Check if we have a valid token
 	if storedToken != nil && tokenStore.IsTokenValid(storedToken) && storedToken.APIURL == *apiURL {
 		logger.Debug("Using stored authentication token")
 		accessToken = storedToken.AccessToken
+		events.emit(eventAuth, authData{Method: "stored", Env: env})
 	} else {
 		// Need to authenticate
 		if storedToken != nil {
 			logger.Debug("Stored token is invalid or expired, re-authenticating")
 		}
 
+		if *quiet && credentialHelper() == "" {
+			logger.Fatal("Authentication required - run 'aircast-cli login' first (login is interactive and unavailable with --quiet)")
+		}
+
 		fmt.Println("Authentication required...")
 		fmt.Println()
 
-		authenticator := auth.NewDeviceCodeAuth(*apiURL, logger)
-		accessToken, err = authenticator.Authenticate(ctx)
+		accessToken, err = authenticate(ctx, *apiURL, "", tokenStore, logger)
 		if err != nil {
 			logger.WithError(err).Fatal("Authentication failed")
 		}
+		events.emit(eventAuth, authData{Method: "login", Reason: loginReason, Env: env})
+	}
 
-		// Save token for future use
-		newToken := &auth.StoredToken{
-			AccessToken: accessToken,
-			TokenType:   "Bearer",
-			ExpiresAt:   time.Now().Add(24 * time.Hour), // Tokens expire in 24 hours
-			APIURL:      *apiURL,
+	// Aliases and user-defined bandwidth profiles live in the config file
+	userConfig, err := configStore.LoadConfig()
+	if err != nil {
+		logger.WithError(err).Warn("Failed to load config, aliases and custom bandwidth profiles unavailable")
+		userConfig = &auth.Config{}
+	}
+	if err := resolveConfigSecrets(userConfig); err != nil {
+		logger.WithError(err).Fatal("Failed to read a secret from config.json")
+	}
+
+	// Get device ID (from flag or alias, saved config, or interactive selection)
+	selectedDeviceID := *deviceID
+	selectedHow := "flag"
+	if id, ok := userConfig.Aliases[selectedDeviceID]; ok {
+		logger.WithFields(log.Fields{"alias": selectedDeviceID, "device_id": id}).Debug("Resolved device alias")
+		selectedDeviceID = id
+		selectedHow = "alias"
+	}
+	if *deviceNamed != "" {
+		if selectedDeviceID != "" {
+			logger.Fatal("Pass either --device or --device-name, not both")
+		}
+		id, age, err := resolveDeviceName(*deviceNamed, *apiURL)
+		if err != nil {
+			logger.WithError(err).Fatal("Unknown device name")
 		}
+		if age >= deviceIndexStale {
+			fmt.Printf("%s%q is %s according to the device list from %s ago\n\n", term.Symbol("⚠ ", "Warning: "), *deviceNamed, id, formatAge(age))
+		}
+		logger.WithFields(log.Fields{"name": *deviceNamed, "device_id": id}).Debug("Resolved device name")
+		selectedDeviceID = id
+		selectedHow = "name"
+	}
 
-		if err := tokenStore.SaveToken(newToken); err != nil {
-			logger.WithError(err).Warn("Failed to save token (will need to re-authenticate next time)")
-		} else {
-			fmt.Printf("✓ Token saved to: %s\n", tokenStore.GetTokenPath())
-			fmt.Println()
+	// The environment's default device goes before the last used one
+	if selectedDeviceID == "" {
+		if env, ok := environmentFor(userConfig, *apiURL); ok && env.Device != "" {
+			selectedDeviceID, selectedHow = env.Device, "env"
+			if id, ok := userConfig.Aliases[env.Device]; ok {
+				selectedDeviceID = id
+			}
+			logger.WithField("device_id", selectedDeviceID).Debug("Using the environment's default device")
 		}
 	}
 
-	// Get device ID (from flag, saved config, or interactive selection)
-	selectedDeviceID := *deviceID
+	if selectedDeviceID != "" {
+		if err := checkDeviceEnvironment(ctx, indexDevices(api.NewClient(*apiURL, accessToken), *apiURL), deviceCache, *apiURL, selectedDeviceID, logger); err != nil {
+			logger.WithError(err).Fatal("Device not available")
+		}
+	}
 
 	if selectedDeviceID == "" {
 		// Try to use last saved device
@@ -144,12 +411,15 @@ func main() {
 			logger.WithError(err).Warn("Failed to load last device from config")
 		}
 
-		// Fetch devices from API
-		apiClient := api.NewClient(*apiURL, accessToken)
-		devices, err := apiClient.GetDevices(ctx)
+		// Fetch devices from API, revalidating the cached list
+		apiClient := indexDevices(api.NewClient(*apiURL, accessToken), *apiURL)
+		devices, usingCache, err := fetchDevices(ctx, apiClient, deviceCache, *apiURL, accessToken, logger)
 		if err != nil {
 			// If authentication failed, delete token and re-authenticate
 			if api.IsAuthError(err) {
+				if *quiet && credentialHelper() == "" {
+					logger.Fatal("Session expired - run 'aircast-cli login' first (login is interactive and unavailable with --quiet)")
+				}
 				logger.Warn("Token is invalid or expired, re-authenticating...")
 				_ = tokenStore.DeleteToken()
 
@@ -157,49 +427,47 @@ func main() {
 				fmt.Println("Your session has expired. Re-authenticating...")
 				fmt.Println()
 
-				authenticator := auth.NewDeviceCodeAuth(*apiURL, logger)
-				accessToken, err = authenticator.Authenticate(ctx)
+				accessToken, err = authenticate(ctx, *apiURL, "", tokenStore, logger)
 				if err != nil {
 					logger.WithError(err).Fatal("Authentication failed")
 				}
-
-				// Save new token
-				newToken := &auth.StoredToken{
-					AccessToken: accessToken,
-					TokenType:   "Bearer",
-					ExpiresAt:   time.Now().Add(24 * time.Hour),
-					APIURL:      *apiURL,
-				}
-
-				if err := tokenStore.SaveToken(newToken); err != nil {
-					logger.WithError(err).Warn("Failed to save token")
-				} else {
-					fmt.Printf("✓ Token saved to: %s\n", tokenStore.GetTokenPath())
-					fmt.Println()
-				}
+				events.emit(eventAuth, authData{Method: "login", Reason: "expired", Env: env})
 
 				// Retry fetching devices with new token
-				apiClient = api.NewClient(*apiURL, accessToken)
-				devices, err = apiClient.GetDevices(ctx)
+				apiClient = indexDevices(api.NewClient(*apiURL, accessToken), *apiURL)
+				devices, usingCache, err = fetchDevices(ctx, apiClient, deviceCache, *apiURL, accessToken, logger)
 				if err != nil {
 					logger.WithError(err).Fatal("Failed to fetch devices")
 				}
+			} else if *useCached {
+				devices = loadCachedDevices(deviceCache, *apiURL, auth.AccountKey(accessToken), err, logger)
+				usingCache = true
+			} else if _, limited := api.IsRateLimited(err); limited {
+				logger.WithError(err).Fatal("Failed to fetch devices - pass --device to connect without the device list")
 			} else {
-				logger.WithError(err).Fatal("Failed to fetch devices")
+				logger.WithError(err).Error("Failed to fetch devices")
+				logger.Fatal("API unreachable - run with --cached to use the last known device list, or pass --device")
 			}
 		}
 
+		// Auto-selection and the picker only consider devices with --tag
+		if devices, err = filterDevices(devices, *deviceTag); err != nil {
+			logger.WithError(err).Fatal("No device to connect to")
+		}
+
 		// Try to auto-select last device if available and valid
 		if lastDeviceID != "" {
 			// Check if the last device is still in the list and online
 			for _, device := range devices {
 				if device.ID == lastDeviceID {
-					if device.IsOnline {
+					// Cached online status is stale, so let the WebSocket decide
+					if device.IsOnline || usingCache {
 						selectedDeviceID = lastDeviceID
-						fmt.Printf("✓ Auto-connecting to last device: %s\n\n", device.Name)
+						selectedHow = "last"
+						fmt.Printf("%sAuto-connecting to last device: %s\n\n", term.Symbol("✓ ", ""), device.Name)
 						logger.WithField("device_id", lastDeviceID).Debug("Auto-selected last device")
 					} else {
-						fmt.Printf("⚠ Last device (%s) is offline, please select a device\n\n", device.Name)
+						fmt.Printf("%sLast device (%s) is offline, please select a device\n\n", term.Symbol("⚠ ", "Warning: "), device.Name)
 						logger.WithField("device_id", lastDeviceID).Warn("Last device is offline")
 					}
 					break
@@ -208,13 +476,24 @@ func main() {
 		}
 
 		// If no auto-selection, let user pick a device
+		if selectedDeviceID == "" && *quiet {
+			logger.Fatal("No device selected - pass --device with --quiet")
+		}
 		if selectedDeviceID == "" {
-			selectedDevice, err := ui.PickDevice(devices)
+			selectedDevice, err := ui.PickDevice(devices, userConfig.DeviceAliases(), lastKnownTelemetry(logger), func(ctx context.Context, device api.Device) (string, error) {
+				telemetry, err := cli.ProbeTelemetry(ctx, buildWebSocketURL(*apiURL, device.ID), accessToken)
+				if err != nil {
+					return "", err
+				}
+				rememberTelemetry(device.ID, telemetry, time.Now(), logger)
+				return telemetry.String(), nil
+			})
 			if err != nil {
 				logger.WithError(err).Fatal("Failed to select device")
 			}
 
 			selectedDeviceID = selectedDevice.ID
+			selectedHow = "picker"
 		}
 
 		// Save the selected device for next time
@@ -225,44 +504,435 @@ func main() {
 
 	// Build WebSocket URL
 	wsURL := buildWebSocketURL(*apiURL, selectedDeviceID)
+	var endpoints []string
+	for _, endpoint := range splitList(*endpointList) {
+		endpoints = append(endpoints, buildWebSocketURL(strings.TrimSuffix(endpoint, "/"), selectedDeviceID))
+	}
+
+	shutdownTimeout, err := time.ParseDuration(*shutdownAfter)
+	if err != nil || shutdownTimeout <= 0 {
+		logger.Fatalf("Invalid --shutdown-timeout %q", *shutdownAfter)
+	}
+	stallTimeout, err := time.ParseDuration(*stallAfter)
+	if err != nil || stallTimeout < 0 {
+		logger.Fatalf("Invalid --stall-timeout %q", *stallAfter)
+	}
+	var controlAccess control.Access
+	if *controlSock != "" {
+		if controlAccess, err = parseControlAccess(*controlMode, *controlGroup, *controlToken, *controlReadToken); err != nil {
+			logger.WithError(err).Fatal("Invalid control socket access")
+		}
+	}
+
+	// Set up data budget accounting
+	var budget cli.DataBudget
+	var dataUsed uint64
+	var recordUsage func(n uint64)
+	if *dataBudget != "" {
+		budget, err = cli.ParseDataBudget(*dataBudget)
+		if err != nil {
+			logger.WithError(err).Fatal("Invalid --data-budget")
+		}
+
+		usageStore, err := auth.NewUsageStore()
+		if err != nil {
+			logger.WithError(err).Fatal("Failed to initialize usage store")
+		}
+		if dataUsed, err = usageStore.Today(); err != nil {
+			logger.WithError(err).Warn("Failed to load today's data usage")
+		}
+		recordUsage = func(n uint64) {
+			if err := usageStore.Add(n); err != nil {
+				logger.WithError(err).Warn("Failed to record data usage")
+			}
+		}
+	}
+
+	// Remove a ready file left behind by an earlier run so it only ever
+	// signals this bridge's readiness
+	if *readyFile != "" {
+		if err := os.Remove(*readyFile); err != nil && !os.IsNotExist(err) {
+			logger.WithError(err).Fatal("Failed to remove stale ready file")
+		}
+	}
+
+	// Telemetry alarms from flags and config.json
+	alarms, err := parseAlarms(alarmFlags, userConfig.Alarms)
+	if err != nil {
+		logger.WithError(err).Fatal("Invalid alarm")
+	}
+	if *alarmHook == "" {
+		*alarmHook = userConfig.AlarmHook
+	}
+
+	// Source ID remapping from flags and config.json
+	remaps, err := parseRemaps(remapFlags, userConfig.Remap)
+	if err != nil {
+		logger.WithError(err).Fatal("Invalid remap")
+	}
+
+	if *uplinkBatch < 0 || *uplinkBatch > 100*time.Millisecond {
+		logger.Fatal("Invalid --uplink-batch: must be between 0 and 100ms")
+	}
+
+	// Upstream heartbeats on behalf of ground stations
+	if *heartbeatSys < 1 || *heartbeatSys > 255 || *heartbeatComp < 1 || *heartbeatComp > 255 {
+		logger.Fatal("Invalid --heartbeat-sysid or --heartbeat-compid: must be between 1 and 255")
+	}
+	if *expires != 0 && *expires < 2*cli.ExpiryReadOnly {
+		logger.Fatalf("Invalid --expires: must be at least %s", 2*cli.ExpiryReadOnly)
+	}
+
+	heartbeatConfig := cli.HeartbeatConfig{
+		Interval: *heartbeat,
+		SysID:    uint8(*heartbeatSys),
+		CompID:   uint8(*heartbeatComp),
+		Always:   *heartbeatAlways,
+	}
+
+	// Second link over another interface
+	var bond cli.BondConfig
+	if *bondIfaces != "" {
+		if bond, err = cli.ParseBond(*bondIfaces, *bondMode); err != nil {
+			logger.WithError(err).Fatal("Invalid --bond")
+		}
+	}
+
+	deviceName := cachedDeviceName(deviceCache, selectedDeviceID)
+	events.emit(eventDeviceSelected, deviceSelectedData{DeviceID: selectedDeviceID, Name: deviceName, How: selectedHow})
+
+	var clientBanner string
+	if *identify {
+		clientBanner = identifyBanner(*identifyText, selectedDeviceID, deviceName,
+			userConfig.DeviceAliases()[selectedDeviceID], env, logger)
+	}
+
+	// Recordings and other artifacts of this session
+	folder := newFlightFolder(selectedDeviceID, deviceName, logger)
+	events.attach(folder)
+
+	// Record into a shared multi-device container
+	var recorder *recording.Writer
+	recordPath := *recordFile
+	if recordPath != "" {
+		if recordPath, err = artifactPath(folder, recordPath, "recording"); err != nil {
+			logger.WithError(err).Fatal("Failed to open recording")
+		}
+		if _, err := os.Stat(*recordFile); err == nil && recordPath != *recordFile {
+			// Bare names used to be shared in the working directory
+			fmt.Printf("%s%s exists here but the recording goes to the flight folder; use --record ./%s to share it with other bridges\n",
+				term.Symbol("⚠️  ", "Warning: "), *recordFile, *recordFile)
+		}
+		recorder = openRecording(recordPath, selectedDeviceID, deviceName, logger)
+	}
+
+	// Log armed periods in the flight ledger
+	flights := newFlightTracker(selectedDeviceID, deviceName, folder, logger)
+	var onArmed func(bool, time.Time)
+	if flights != nil {
+		onArmed = flights.Armed
+	}
+
+	// Session lifecycle notifications for ops integrations, from config.json
+	onAlarm := newAlarmHandler(selectedDeviceID, *alarmHook, logger)
+	var onLink func(bool, error)
+	var notifier *webhook.Notifier
+	if len(userConfig.Webhooks) > 0 {
+		notifier, err = webhook.New(userConfig.Webhooks, webhook.Device{ID: selectedDeviceID, Name: deviceName}, logger)
+		if err != nil {
+			logger.WithError(err).Fatal("Invalid webhook")
+		}
+		onAlarm = webhookAlarmHandler(notifier, onAlarm)
+		onLink = webhookLinkHandler(notifier)
+	}
+	onAlarm = events.alarmHandler(onAlarm)
+	onArmed = events.armedHandler(onArmed)
+	onLink = events.linkHandler(onLink)
+
+	// Walk through an outage of the device's proxy on a screen instead of notices
+	var trouble *troubleshooter
+	if ui.Interactive() && !*quiet {
+		name := deviceName
+		if name == "" {
+			name = selectedDeviceID
+		}
+		trouble = &troubleshooter{
+			ctx:      ctx,
+			stop:     cancel,
+			client:   api.NewClient(*apiURL, accessToken),
+			deviceID: selectedDeviceID,
+			device:   name,
+			logger:   logger,
+		}
+	}
+
+	// A guest session tears itself down
+	var expiresAt time.Time
+	var expired atomic.Bool
+	if *expires > 0 {
+		expiresAt = time.Now().Add(*expires)
+	}
 
 	// Create bridge configuration
 	config := &cli.Config{
 		WebSocketURL: wsURL,
+		Output:       ui.Output,
 		AuthToken:    accessToken,
 		TCPAddress:   *tcpListen,
 		UDPAddress:   *udpListen,
 		Logger:       logger,
+
+		DropCorrupted: *dropCorrupt,
+		StatsInterval: *statsEvery,
+
+		MaxClients:      *maxClients,
+		MaxClientsPerIP: *maxPerIP,
+
+		TCPNagle:         *tcpNagle,
+		CoalesceInterval: *coalesce,
+		DedupWindow:      *dedupWindow,
+		BatchInterval:    *uplinkBatch,
+
+		BandwidthProfile: *bwProfile,
+		CustomProfiles:   userConfig.BandwidthProfiles,
+		SourceRates:      *sourceRates,
+		AdaptiveMaxRate:  *adaptRate,
+		Recorder:         recorder,
+
+		Alarms:   alarms,
+		OnAlarm:  onAlarm,
+		OnArmed:  onArmed,
+		OnLink:   onLink,
+		OnClient: events.clientHandler(),
+
+		StallTimeout: stallTimeout,
+		OnStall: func(silence time.Duration) {
+			events.emit(eventLinkStalled, linkData{Silence: silence.Seconds()})
+		},
+
+		ExpiresAt: expiresAt,
+		OnExpire: func() {
+			expired.Store(true)
+			events.emit(eventSessionExpired, nil)
+			cancel()
+		},
+
+		Remap:     remaps,
+		Heartbeat: heartbeatConfig,
+
+		ClientBanner:        clientBanner,
+		RadioStatusInterval: *radioStatus,
+
+		LowMemory: *lowMemory,
+		Bond:      bond,
+		Routes:    splitList(*routeList),
+		Endpoints: endpoints,
+
+		DataBudget:      budget,
+		DataUsed:        dataUsed,
+		RecordDataUsage: recordUsage,
+
+		// Tell systemd (Type=notify) and file watchers the bridge is usable
+		OnReady: func() {
+			logger.Info("Data flowing, bridge ready")
+			if err := systemd.Notify(systemd.Ready); err != nil {
+				logger.WithError(err).Warn("Failed to notify systemd")
+			}
+			if *readyFile != "" {
+				if err := touchReadyFile(*readyFile); err != nil {
+					logger.WithError(err).Warn("Failed to create ready file")
+				}
+			}
+		},
 	}
 
 	// Create and start bridge
+	if trouble != nil {
+		config.OnCircuit = trouble.onCircuit
+	}
 	b, err := cli.New(config)
 	if err != nil {
 		logger.WithError(err).Fatal("Failed to create bridge")
 	}
+	if trouble != nil {
+		trouble.setBridge(b)
+	}
+
+	// Decoded telemetry for the team's own analytics, from config.json
+	var sinks []string
+	for _, sinkConfig := range userConfig.TelemetrySinks {
+		sink, err := tsdb.New(sinkConfig, tsdb.Device{ID: selectedDeviceID, Name: deviceName}, logger)
+		if err != nil {
+			logger.WithError(err).Fatal("Invalid telemetry sink")
+		}
+		b.AddOutput(sinkConfig.Type, tsdb.Target(sinkConfig), sink)
+		sinks = append(sinks, sinkConfig.Type+" "+tsdb.Target(sinkConfig))
+	}
 
-	if err := b.Start(); err != nil {
+	printFirewallWarnings(*tcpListen, *udpListen)
+
+	if err := b.Start(ctx); err != nil {
+		if d := b.Diagnostics(); !d.Connected && d.HandshakeAttempts > 0 {
+			fmt.Println()
+			if d.LastHandshakeCode != 0 {
+				fmt.Printf("  %s\n", d.Verdict())
+			} else {
+				for _, line := range diagnoseEgress(wsURL, err) {
+					fmt.Printf("  %s\n", line)
+				}
+			}
+			fmt.Println()
+		}
+		events.emit(eventLinkFailed, linkData{Error: err.Error()})
 		logger.WithError(err).Fatal("Failed to start bridge")
 	}
+	events.emit(eventLinkConnected, nil)
+
+	// Start control socket so other invocations can query and manage the bridge
+	var controlServer *control.Server
+	if *controlSock != "" {
+		channel := recording.ChannelInfo{DeviceID: selectedDeviceID, Name: deviceName}
+		controlServer = newControlServer(*controlSock, controlAccess, b, channel, folder, logger)
+		if err := controlServer.Start(); err != nil {
+			logger.WithError(err).Warn("Control socket disabled")
+			controlServer = nil
+		}
+	}
+
+	// Live map for observers without a ground station
+	var mapServer *mapview.Server
+	if *mapListen != "" {
+		mapServer = mapview.NewServer(*mapListen, b.Telemetry, logger.WithField("component", "map"))
+		if err := mapServer.Start(); err != nil {
+			logger.WithError(err).Warn("Map view disabled")
+			mapServer = nil
+		}
+	}
+
+	// Companion computer data alongside MAVLink
+	var auxBridge *cli.Bridge
+	if *auxListen != "" {
+		auxBridge = startAuxBridge(ctx, *apiURL, selectedDeviceID, accessToken, *auxListen, logger)
+	}
+
+	// Refresh the login before reconnects and API calls start being rejected
+	eventsClient := api.NewClient(*apiURL, accessToken)
+	metricsClient := api.NewClient(*apiURL, accessToken)
+	clients := []*api.Client{eventsClient, metricsClient}
+	if trouble != nil {
+		clients = append(clients, trouble.client)
+	}
+	go watchTokenExpiry(ctx, *apiURL, accessToken, tokenStore, logger, clients, b, auxBridge)
+
+	// Live device lifecycle notifications
+	if *liveEvents {
+		go watchEvents(ctx, eventsClient, deviceCache, selectedDeviceID, logger)
+	}
+
+	// Opt-in link health reporting for the fleet dashboard
+	var metrics *metricsPusher
+	if *shareMetric {
+		metrics = startMetricsPusher(metricsClient, b, selectedDeviceID, logger)
+	}
+
+	notifier.Notify(webhook.SessionStarted, sessionStartedData{Version: version, TCP: *tcpListen, UDP: *udpListen})
 
-	fmt.Println("╔═══════════════════════════════════════════════════════════════╗")
-	fmt.Println("║          🚀 MAVLink Bridge Running                           ║")
-	fmt.Println("╚═══════════════════════════════════════════════════════════════╝")
+	fmt.Println(term.Banner(term.Symbol("🚀 ", "") + "MAVLink Bridge Running"))
 	fmt.Println()
-	fmt.Printf("  📡 Device:     %s\n", selectedDeviceID)
-	fmt.Printf("  🔌 TCP Port:   %s\n", *tcpListen)
+	bannerField("📡 ", "Device", selectedDeviceID)
+	if env := auth.DetectEnvironment(*apiURL); env != auth.EnvProduction {
+		bannerField("🌐 ", "Env", fmt.Sprintf("%s (%s)", env, *apiURL))
+	}
+	if dir := os.Getenv("AIRCAST_CONFIG_DIR"); dir != "" {
+		bannerField("📁 ", "Config", dir)
+	}
+	if *bwProfile != cli.ProfileFull {
+		bannerField("📉 ", "Profile", *bwProfile)
+	}
+	if *adaptRate > 0 {
+		bannerField("📈 ", "Adaptive", fmt.Sprintf("1-%.0f Hz, following link latency and loss", *adaptRate))
+	}
+	if len(remaps) > 0 {
+		rules := make([]string, len(remaps))
+		for i, rule := range remaps {
+			rules[i] = rule.String()
+		}
+		bannerField("🔀 ", "Remap", strings.Join(rules, ", "))
+	}
+	if *bindIface != "" {
+		bannerField("🛰️  ", "Interface", *bindIface)
+	}
+	if routes := b.Routes(); len(routes) > 0 {
+		names := make([]string, len(routes))
+		for i, r := range routes {
+			names[i] = r.Name
+			if r.Active {
+				names[i] += " (active)"
+			}
+		}
+		bannerField("🧭 ", "Routes", strings.Join(names, ", "))
+	}
+	if mtu := b.PathMTU(); mtu.Reduced() {
+		bannerField("📏 ", "Path MTU", fmt.Sprintf("%d via %s", mtu.MTU, mtu.Interface))
+	}
+	if bond.Enabled() {
+		bannerField("🔗 ", "Bond", fmt.Sprintf("%s + %s (%s)", bond.Primary, bond.Backup, bond.Mode))
+	}
+	if *heartbeat > 0 {
+		bannerField("💓 ", "Heartbeat", fmt.Sprintf("every %s as %d/%d", *heartbeat, *heartbeatSys, *heartbeatComp))
+	}
+	if len(alarms) > 0 {
+		names := make([]string, len(alarms))
+		for i, alarm := range alarms {
+			names[i] = alarm.String()
+		}
+		bannerField("🚨 ", "Alarms", strings.Join(names, ", "))
+	}
+	if !expiresAt.IsZero() {
+		bannerField("⏳ ", "Expires", fmt.Sprintf("%s (in %s), commands blocked in the last minute", expiresAt.Local().Format("15:04"), *expires))
+	}
+	if recorder != nil {
+		bannerField("⏺️  ", "Recording", recordPath)
+	}
+	if len(sinks) > 0 {
+		bannerField("🗄️  ", "Telemetry", strings.Join(sinks, ", "))
+	}
+	if notifier != nil {
+		targets := make([]string, len(userConfig.Webhooks))
+		for i, hook := range userConfig.Webhooks {
+			targets[i] = webhook.Target(hook)
+		}
+		bannerField("🔔 ", "Webhooks", strings.Join(targets, ", "))
+	}
+	if metrics != nil {
+		bannerField("📊 ", "Metrics", "sharing link quality with the fleet dashboard")
+	}
+	if budget.Enabled() {
+		used := "this session"
+		if budget.Daily {
+			used = cli.FormatBytes(dataUsed) + " used today"
+		}
+		bannerField("📶 ", "Budget", fmt.Sprintf("%s (%s)", budget, used))
+	}
+	bannerField("🔌 ", "TCP Port", *tcpListen)
 	if *udpListen != "" {
-		fmt.Printf("  🔌 UDP Port:   %s\n", *udpListen)
+		bannerField("🔌 ", "UDP Port", *udpListen)
+	}
+	if mapServer != nil {
+		bannerField("🗺️  ", "Map", mapURL(mapServer.Addr()))
+	}
+	if auxBridge != nil {
+		bannerField("🧩 ", "Aux Port", fmt.Sprintf("%s (companion data)", *auxListen))
 	}
 	fmt.Println()
-	fmt.Println("  🛩️  Connect your ground control station to:")
+	bannerNote("🛩️  ", "Connect your ground control station to:")
 	fmt.Printf("     tcp://%s\n", *tcpListen)
 	if *udpListen != "" {
 		fmt.Printf("     udp://%s\n", *udpListen)
 	}
 	fmt.Println()
-	fmt.Println("  💡 Waiting for device MAVLink proxy to start...")
-	fmt.Println("  ⏹️  Press Ctrl+C to stop")
+	bannerNote("💡 ", "Waiting for device MAVLink proxy to start...")
+	bannerNote("⏹️  ", "Press Ctrl+C to stop")
 	fmt.Println()
 
 	logger.WithFields(log.Fields{
@@ -271,21 +941,155 @@ func main() {
 		"udp":       *udpListen,
 	}).Info("Bridge started")
 
+	if *quiet {
+		printReady(readyOut, *tcpListen, *udpListen)
+	}
+
 	// Wait for interrupt signal
 	<-ctx.Done()
 
 	fmt.Println()
+	if expired.Load() {
+		fmt.Printf("%sGuest session expired after %s\n", term.Symbol("⏳ ", ""), *expires)
+	}
 	logger.Info("Shutting down...")
-	if err := b.Stop(); err != nil {
+	cleanedUp := forceExitOnSignal(shutdownTimeout, *pidFile, logger)
+	_ = systemd.Notify(systemd.Stopping)
+	if *readyFile != "" {
+		_ = os.Remove(*readyFile)
+	}
+	if controlServer != nil {
+		_ = controlServer.Stop()
+	}
+	shutdownCtx, cancelShutdown := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancelShutdown()
+	if err := b.Shutdown(shutdownCtx); err != nil {
 		logger.WithError(err).Error("Error during shutdown")
 	}
-	fmt.Println("✓ Bridge stopped")
+	telemetry, telemetryAt := b.Telemetry()
+	rememberTelemetry(selectedDeviceID, &telemetry, telemetryAt, logger)
+	if auxBridge != nil {
+		_ = auxBridge.Shutdown(shutdownCtx)
+	}
+	if mapServer != nil {
+		_ = mapServer.Stop()
+	}
+	if metrics != nil {
+		metrics.Stop()
+	}
+	if flights != nil {
+		flights.Close()
+	}
+	if recorder != nil {
+		if err := recorder.Close(); err != nil {
+			logger.WithError(err).Error("Failed to finish recording")
+		}
+	}
+	if folder != nil {
+		if err := folder.Close(); err != nil {
+			logger.WithError(err).Warn("Failed to update flight folder index")
+		}
+		if folder.Created() {
+			fmt.Printf("%sSession files saved to %s\n", term.Symbol("📁 ", ""), folder.Path())
+		}
+	}
+	cleanedUp()
+	fmt.Println(term.Symbol("✓ ", "") + "Bridge stopped")
+
+	stats := b.Stats()
+	diag := b.Diagnostics()
+	if notifier != nil {
+		notifySessionStopped(notifier, stats, b.LinkStats(), diag)
+		notifier.Close()
+	}
+	events.emit(webhook.SessionStopped, newSessionStoppedData(stats, b.LinkStats(), diag))
+	events.close()
+	printStats(stats)
+	if bond.Enabled() {
+		printBondStats(b.BondStats())
+	}
+	recordSession(*apiURL, selectedDeviceID, deviceName, *site, stats, b.LinkStats(), diag, logger)
+
+	if !diag.ReceivedData() {
+		report := newConnectionReport(*apiURL, selectedDeviceID, diag)
+		printPostMortem(report)
+		if *shareDiag {
+			shareDiagnostics(*apiURL, accessToken, report)
+		} else {
+			fmt.Println("  Run with --share-diagnostics to send this report to Aircast support.")
+		}
+	}
+}
+
+// printStats prints the session traffic summary
+func printStats(s cli.StatsSnapshot) {
+	fmt.Println()
+	fmt.Printf("  Session: %s\n", time.Since(s.Since).Round(time.Second))
+	for _, d := range []struct {
+		name  string
+		stats cli.DirectionStats
+	}{
+		{"Uplink", s.Uplink},
+		{"Downlink", s.Downlink},
+	} {
+		fmt.Printf("  %-9s %d frames, %d bytes, %d corrupted (%.2f%%), %d dropped, %d filtered",
+			d.name+":", d.stats.Frames, d.stats.Bytes, d.stats.Corrupted, d.stats.CorruptionRate()*100, d.stats.Dropped, d.stats.Filtered)
+		if d.stats.Duplicates > 0 {
+			fmt.Printf(", %d duplicates", d.stats.Duplicates)
+		}
+		fmt.Println()
+	}
+	fmt.Printf("  %-9s %d frames (%.2f%%)\n", "Lost:", s.Downlink.Lost, s.Downlink.LossRate()*100)
+	for _, src := range s.Sources {
+		fmt.Printf("    sys %3d comp %3d: %d received, %d lost (%.2f%%)\n",
+			src.SysID, src.CompID, src.Received, src.Lost, src.LossRate()*100)
+	}
+}
+
+// printBondStats prints what the backup link of a --bond session carried
+func printBondStats(s cli.BondStats) {
+	fmt.Printf("  %-9s %d downlink messages first, %d duplicates dropped, %d uplink messages, %d connects\n",
+		"Backup:", s.Messages, s.Duplicates, s.Uplink, s.Connects)
+}
+
+// loadCachedDevices returns the cached device list after the API failed with
+// apiErr, warning about its age. It exits if no usable cache exists.
+func loadCachedDevices(cache *auth.DeviceCache, apiURL, account string, apiErr error, logger *log.Entry) []api.Device {
+	cached, err := cache.Load(apiURL, account)
+	if err != nil {
+		logger.WithError(err).Warn("Failed to load device cache")
+	}
+	if cached == nil || len(cached.Devices) == 0 {
+		logger.WithError(apiErr).Fatal("API unreachable and no cached device list available")
+	}
+
+	logger.WithError(apiErr).Warn("API unreachable, using cached device list")
+	fmt.Println(term.Symbol("⚠ ", "Warning: ") + "Aircast API is unreachable - using the device list cached")
+	fmt.Printf("  %s ago (%s). Online status shown may be stale;\n",
+		cached.Age().Round(time.Minute), cached.FetchedAt.Local().Format("2006-01-02 15:04"))
+	fmt.Println("  the connection will be attempted anyway.")
+	fmt.Println()
+
+	return cached.Devices
 }
 
 // buildWebSocketURL constructs the WebSocket URL from API URL and device ID
 func buildWebSocketURL(apiURL, deviceID string) string {
-	wsURL := fmt.Sprintf("%s/v1/mavlink/web/%s/ws", apiURL, deviceID)
+	return toWebSocketURL(fmt.Sprintf("%s/v1/mavlink/web/%s/ws", apiURL, deviceID))
+}
+
+// buildEchoWebSocketURL constructs the echo WebSocket URL used by speedtest
+func buildEchoWebSocketURL(apiURL, deviceID string) string {
+	return toWebSocketURL(fmt.Sprintf("%s/v1/mavlink/web/%s/echo", apiURL, deviceID))
+}
+
+// buildAuxWebSocketURL constructs the companion data WebSocket URL for a device
+func buildAuxWebSocketURL(apiURL, deviceID string) string {
+	return toWebSocketURL(fmt.Sprintf("%s/v1/companion/web/%s/ws", apiURL, deviceID))
+}
 
+// toWebSocketURL switches an HTTP(S) URL to the matching WebSocket scheme
+func toWebSocketURL(wsURL string) string {
 	// Replace http with ws, https with wss
 	if len(wsURL) >= 7 && wsURL[:7] == "http://" {
 		return "ws://" + wsURL[7:]
@@ -296,6 +1100,29 @@ func buildWebSocketURL(apiURL, deviceID string) string {
 	return wsURL
 }
 
+// mapURL returns the URL to open the map view served on addr
+func mapURL(addr net.Addr) string {
+	host, port, err := net.SplitHostPort(addr.String())
+	if err != nil {
+		return "http://" + addr.String()
+	}
+	if ip := net.ParseIP(host); ip == nil || ip.IsUnspecified() {
+		host = "localhost"
+	}
+	return "http://" + net.JoinHostPort(host, port)
+}
+
+// flagSet reports whether a bridge flag was given on the command line
+func flagSet(name string) bool {
+	set := false
+	flag.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			set = true
+		}
+	})
+	return set
+}
+
 // getEnv gets an environment variable with a fallback default value
 func getEnv(key, defaultValue string) string {
 	if value := os.Getenv(key); value != "" {
@@ -0,0 +1,139 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"runtime"
+	"sync"
+	"time"
+
+	"github.com/pavliha/aircast/aircast-cli/internal/api"
+	"github.com/pavliha/aircast/aircast-cli/internal/cli"
+	log "github.com/sirupsen/logrus"
+)
+
+// metricsInterval is how often link metrics are pushed with --share-metrics
+const metricsInterval = time.Minute
+
+// linkMetrics is the report sent with --share-metrics. It deliberately
+// carries no host names, IP addresses, user names or ground station details.
+type linkMetrics struct {
+	SessionID  string    `json:"session_id"` // Random per run, links reports of one session
+	DeviceID   string    `json:"device_id"`
+	Version    string    `json:"cli_version"`
+	OS         string    `json:"os"`
+	ReportedAt time.Time `json:"reported_at"`
+	Uptime     float64   `json:"uptime_seconds"`
+
+	RTTMs      float64 `json:"rtt_ms"`
+	RTTMinMs   float64 `json:"rtt_min_ms"`
+	RTTMaxMs   float64 `json:"rtt_max_ms"`
+	Reconnects uint64  `json:"reconnects"`
+
+	DownlinkFrames uint64  `json:"downlink_frames"`
+	DownlinkLost   uint64  `json:"downlink_lost"`
+	LossPct        float64 `json:"loss_pct"`
+	CorruptPct     float64 `json:"corrupt_pct"`
+	CircuitOpens   int     `json:"circuit_opens"`
+	Clients        int     `json:"clients"`
+}
+
+// metricsPusher periodically reports link metrics to the Aircast API
+type metricsPusher struct {
+	client    *api.Client
+	bridge    *cli.Bridge
+	deviceID  string
+	sessionID string
+	logger    *log.Entry
+
+	wg     sync.WaitGroup
+	cancel context.CancelFunc
+}
+
+// startMetricsPusher begins pushing metrics every metricsInterval
+func startMetricsPusher(client *api.Client, b *cli.Bridge, deviceID string, logger *log.Entry) *metricsPusher {
+	id := make([]byte, 8)
+	_, _ = rand.Read(id)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	p := &metricsPusher{
+		client:    client,
+		bridge:    b,
+		deviceID:  deviceID,
+		sessionID: hex.EncodeToString(id),
+		logger:    logger.WithField("component", "metrics"),
+		cancel:    cancel,
+	}
+
+	p.wg.Add(1)
+	go p.run(ctx)
+	return p
+}
+
+func (p *metricsPusher) run(ctx context.Context) {
+	defer p.wg.Done()
+
+	ticker := time.NewTicker(metricsInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			p.push()
+		}
+	}
+}
+
+// Stop stops the periodic pushes and sends a final report
+func (p *metricsPusher) Stop() {
+	p.cancel()
+	p.wg.Wait()
+	p.push()
+}
+
+// push sends one report; failures are logged and otherwise ignored
+func (p *metricsPusher) push() {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := p.client.PushLinkMetrics(ctx, p.snapshot()); err != nil {
+		p.logger.WithError(err).Debug("Failed to push link metrics")
+		return
+	}
+	p.logger.Debug("Pushed link metrics")
+}
+
+// snapshot builds a report from the bridge's current statistics
+func (p *metricsPusher) snapshot() linkMetrics {
+	stats := p.bridge.Stats()
+	link := p.bridge.LinkStats()
+	diag := p.bridge.Diagnostics()
+
+	ms := func(d time.Duration) float64 {
+		return float64(d) / float64(time.Millisecond)
+	}
+
+	return linkMetrics{
+		SessionID:  p.sessionID,
+		DeviceID:   p.deviceID,
+		Version:    version,
+		OS:         runtime.GOOS + "/" + runtime.GOARCH,
+		ReportedAt: time.Now().UTC(),
+		Uptime:     time.Since(stats.Since).Seconds(),
+
+		RTTMs:      ms(link.RTT),
+		RTTMinMs:   ms(link.RTTMin),
+		RTTMaxMs:   ms(link.RTTMax),
+		Reconnects: link.Reconnects,
+
+		DownlinkFrames: stats.Downlink.Frames,
+		DownlinkLost:   stats.Downlink.Lost,
+		LossPct:        stats.Downlink.LossRate() * 100,
+		CorruptPct:     stats.Downlink.CorruptionRate() * 100,
+		CircuitOpens:   diag.CircuitOpens,
+		Clients:        len(p.bridge.Clients()),
+	}
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pavliha/aircast/aircast-cli/internal/api"
+	"github.com/pavliha/aircast/aircast-cli/internal/network"
+)
+
+// stringList is a repeatable string flag
+type stringList []string
+
+func (l *stringList) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *stringList) Set(value string) error {
+	*l = append(*l, value)
+	return nil
+}
+
+// splitList splits a comma-separated flag value, dropping empty entries
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// applyNetworkOptions installs host overrides, a custom DNS server and an
+// interface binding for all HTTP and WebSocket connections
+func applyNetworkOptions(resolves []string, dnsServer, bindInterface string) error {
+	for _, spec := range resolves {
+		if err := network.AddResolve(spec); err != nil {
+			return err
+		}
+	}
+	if dnsServer != "" {
+		if err := network.SetDNSServer(dnsServer); err != nil {
+			return err
+		}
+	}
+	if bindInterface != "" {
+		if err := network.BindInterface(bindInterface); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// envNetworkOptions reads AIRCAST_RESOLVE (comma-separated), AIRCAST_DNS and
+// AIRCAST_BIND_INTERFACE, which apply to every command
+func envNetworkOptions() ([]string, string, string) {
+	var resolves []string
+	for _, spec := range strings.Split(os.Getenv("AIRCAST_RESOLVE"), ",") {
+		if spec = strings.TrimSpace(spec); spec != "" {
+			resolves = append(resolves, spec)
+		}
+	}
+	return resolves, os.Getenv("AIRCAST_DNS"), os.Getenv("AIRCAST_BIND_INTERFACE")
+}
+
+// applyAPIPolicy sets how often failed API calls are retried and how long each
+// attempt may take. Negative retries and zero timeouts keep the defaults.
+func applyAPIPolicy(retries int, timeout time.Duration) {
+	if retries >= 0 {
+		api.DefaultRetryPolicy.MaxAttempts = retries + 1
+	}
+	if timeout > 0 {
+		api.DefaultRetryPolicy.CallTimeout = timeout
+	}
+}
+
+// envAPIPolicy reads AIRCAST_API_RETRIES and AIRCAST_API_TIMEOUT, which apply
+// to every command
+func envAPIPolicy() (int, time.Duration, error) {
+	retries, timeout := -1, time.Duration(0)
+	if v := os.Getenv("AIRCAST_API_RETRIES"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			return 0, 0, fmt.Errorf("invalid AIRCAST_API_RETRIES %q", v)
+		}
+		retries = n
+	}
+	if v := os.Getenv("AIRCAST_API_TIMEOUT"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d <= 0 {
+			return 0, 0, fmt.Errorf("invalid AIRCAST_API_TIMEOUT %q", v)
+		}
+		timeout = d
+	}
+	return retries, timeout, nil
+}
+
+// envTransportOptions reads AIRCAST_API_HTTP2, AIRCAST_API_KEEPALIVE and
+// AIRCAST_API_IDLE_TIMEOUT, which apply to every command
+func envTransportOptions() (network.TransportOptions, error) {
+	var opts network.TransportOptions
+	if v := os.Getenv("AIRCAST_API_HTTP2"); v != "" {
+		on, err := strconv.ParseBool(v)
+		if err != nil {
+			return opts, fmt.Errorf("invalid AIRCAST_API_HTTP2 %q", v)
+		}
+		opts.DisableHTTP2 = !on
+	}
+	for _, env := range []struct {
+		name string
+		dst  *time.Duration
+	}{
+		{"AIRCAST_API_KEEPALIVE", &opts.KeepAlive},
+		{"AIRCAST_API_IDLE_TIMEOUT", &opts.IdleTimeout},
+	} {
+		if v := os.Getenv(env.name); v != "" {
+			d, err := time.ParseDuration(v)
+			if err != nil {
+				return opts, fmt.Errorf("invalid %s %q", env.name, v)
+			}
+			*env.dst = d
+		}
+	}
+	return opts, nil
+}
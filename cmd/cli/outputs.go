@@ -0,0 +1,168 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/pavliha/aircast/aircast-cli/internal/cli"
+	"github.com/pavliha/aircast/aircast-cli/internal/control"
+	"github.com/pavliha/aircast/aircast-cli/internal/recording"
+	"github.com/pavliha/aircast/aircast-cli/internal/store"
+	"github.com/pavliha/aircast/aircast-cli/internal/term"
+)
+
+// outputCommands are the subcommands of "outputs"
+var outputCommands = map[string]command{
+	"list":   {"List secondary outputs of a running bridge", runOutputsList},
+	"add":    {"Add an output to a running bridge (udp <host:port>, record <file>)", runOutputsAdd},
+	"remove": {"Stop and remove an output from a running bridge", runOutputsRemove},
+}
+
+// runOutputs dispatches "outputs" subcommands, listing outputs without one
+func runOutputs(args []string) error {
+	if len(args) == 0 || strings.HasPrefix(args[0], "-") {
+		return runOutputsList(args)
+	}
+
+	cmd, ok := outputCommands[args[0]]
+	if !ok {
+		printSubcommands("outputs", outputCommands)
+		return fmt.Errorf("unknown outputs command %q", args[0])
+	}
+	return cmd.run(args[1:])
+}
+
+// openOutput creates a sink for a secondary output. Recordings go on the
+// bridged device's channel, like --record.
+func openOutput(kind, target string, channel recording.ChannelInfo) (cli.OutputSink, error) {
+	switch kind {
+	case "udp":
+		return cli.NewUDPOutput(target)
+	case "record":
+		return recording.Create(target, channel)
+	default:
+		return nil, fmt.Errorf("unknown output kind %q (expected udp or record)", kind)
+	}
+}
+
+// handleOutputs registers the control commands that manage secondary outputs.
+// Recordings given as a bare file name go into the session's flight folder.
+func handleOutputs(server *control.Server, b *cli.Bridge, channel recording.ChannelInfo, folder *store.FlightFolder) {
+	server.Handle("outputs", control.PermRead, func(req control.Request) (interface{}, error) {
+		return b.Outputs(), nil
+	})
+
+	server.Handle("output-add", control.PermControl, func(req control.Request) (interface{}, error) {
+		kind, target := req.Args["kind"], req.Args["target"]
+		if target == "" {
+			return nil, fmt.Errorf("missing target")
+		}
+		if kind == "record" {
+			var err error
+			if target, err = artifactPath(folder, target, "recording"); err != nil {
+				return nil, err
+			}
+		}
+		sink, err := openOutput(kind, target, channel)
+		if err != nil {
+			return nil, err
+		}
+		return b.AddOutput(kind, target, sink), nil
+	})
+
+	server.Handle("output-remove", control.PermControl, func(req control.Request) (interface{}, error) {
+		id := req.Args["output"]
+		if id == "" {
+			return nil, fmt.Errorf("missing output")
+		}
+		return nil, b.RemoveOutput(id)
+	})
+}
+
+// runOutputsList prints the secondary outputs of a running bridge
+func runOutputsList(args []string) error {
+	fs := flag.NewFlagSet("outputs list", flag.ExitOnError)
+	socket := fs.String("control-socket", defaultControlSocket(), "Control socket path of the running bridge")
+	_ = fs.Parse(args)
+
+	var outputs []cli.OutputInfo
+	if err := control.Call(*socket, "outputs", nil, &outputs); err != nil {
+		return err
+	}
+
+	if len(outputs) == 0 {
+		fmt.Println("No outputs")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "OUTPUT\tTARGET\tADDED\tFRAMES")
+	for _, o := range outputs {
+		fmt.Fprintf(w, "%s\t%s\t%s ago\t%d\n", o.ID, o.Target, time.Since(o.AddedAt).Round(time.Second), o.Frames)
+	}
+	return w.Flush()
+}
+
+// runOutputsAdd adds a secondary output to a running bridge
+func runOutputsAdd(args []string) error {
+	fs := flag.NewFlagSet("outputs add", flag.ExitOnError)
+	socket := fs.String("control-socket", defaultControlSocket(), "Control socket path of the running bridge")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: aircast-cli outputs add [flags] <kind> <target>\n\n")
+		fmt.Fprintf(fs.Output(), "  udp <host:port>   Send the device's traffic to a UDP address, e.g. udp 10.0.0.7:14550\n")
+		fmt.Fprintf(fs.Output(), "  record <file>     Start recording both directions to a file, like --record\n")
+		fmt.Fprintf(fs.Output(), "                    (a bare file name goes into the session's flight folder)\n\n")
+		fs.PrintDefaults()
+	}
+	positional := parseArgs(fs, args)
+	if len(positional) != 2 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	kind, target := positional[0], positional[1]
+	if kind == "record" && isPath(target) {
+		// The bridge may run in another directory
+		abs, err := filepath.Abs(target)
+		if err != nil {
+			return err
+		}
+		target = abs
+	}
+
+	var info cli.OutputInfo
+	if err := control.Call(*socket, "output-add", map[string]string{"kind": kind, "target": target}, &info); err != nil {
+		return err
+	}
+
+	fmt.Printf("%sAdded %s (%s)\n", term.Symbol("✓ ", ""), info.ID, info.Target)
+	return nil
+}
+
+// runOutputsRemove removes a secondary output from a running bridge
+func runOutputsRemove(args []string) error {
+	fs := flag.NewFlagSet("outputs remove", flag.ExitOnError)
+	socket := fs.String("control-socket", defaultControlSocket(), "Control socket path of the running bridge")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: aircast-cli outputs remove [flags] <output>\n\n")
+		fmt.Fprintf(fs.Output(), "Output is an ID from 'aircast-cli outputs' (e.g. udp-1)\n\n")
+		fs.PrintDefaults()
+	}
+	positional := parseArgs(fs, args)
+	if len(positional) != 1 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	if err := control.Call(*socket, "output-remove", map[string]string{"output": positional[0]}, nil); err != nil {
+		return err
+	}
+
+	fmt.Printf("%sRemoved %s\n", term.Symbol("✓ ", ""), positional[0])
+	return nil
+}
@@ -0,0 +1,153 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"runtime"
+	"sort"
+	"time"
+
+	"github.com/pavliha/aircast/aircast-cli/internal/api"
+	"github.com/pavliha/aircast/aircast-cli/internal/auth"
+	"github.com/pavliha/aircast/aircast-cli/internal/cli"
+	"github.com/pavliha/aircast/aircast-cli/internal/store"
+	"github.com/pavliha/aircast/aircast-cli/internal/term"
+	log "github.com/sirupsen/logrus"
+)
+
+// connectionReport is the post-mortem of a session that never received data
+type connectionReport struct {
+	Version     string          `json:"version"`
+	OS          string          `json:"os"`
+	APIURL      string          `json:"api_url"`
+	DeviceID    string          `json:"device_id"`
+	Duration    string          `json:"duration"`
+	Verdict     string          `json:"verdict"`
+	Diagnostics cli.Diagnostics `json:"diagnostics"`
+}
+
+// newConnectionReport builds a post-mortem from the bridge connection history
+func newConnectionReport(apiURL, deviceID string, d cli.Diagnostics) connectionReport {
+	return connectionReport{
+		Version:     fmt.Sprintf("%s (commit: %s)", version, commit),
+		OS:          runtime.GOOS + "/" + runtime.GOARCH,
+		APIURL:      apiURL,
+		DeviceID:    deviceID,
+		Duration:    time.Since(d.StartedAt).Round(time.Second).String(),
+		Verdict:     d.Verdict(),
+		Diagnostics: d,
+	}
+}
+
+// printPostMortem explains why no data flowed during the session
+func printPostMortem(r connectionReport) {
+	d := r.Diagnostics
+
+	fmt.Println()
+	fmt.Println("  No MAVLink data was received this session. Connection summary:")
+	fmt.Println()
+	if d.LastHandshakeCode == http.StatusUnauthorized || d.LastHandshakeCode == http.StatusForbidden {
+		fmt.Printf("    Auth:        %stoken rejected by %s\n", term.Symbol("✗ ", ""), r.APIURL)
+	} else {
+		fmt.Printf("    Auth:        %stoken obtained for %s\n", term.Symbol("✓ ", ""), r.APIURL)
+	}
+	switch {
+	case d.Connected:
+		fmt.Printf("    Handshake:   %sconnected (%d attempts, %d failed)\n", term.Symbol("✓ ", ""), d.HandshakeAttempts, d.HandshakeFailures)
+	case d.LastHandshakeCode != 0:
+		fmt.Printf("    Handshake:   %sHTTP %d (%d attempts)\n", term.Symbol("✗ ", ""), d.LastHandshakeCode, d.HandshakeAttempts)
+	default:
+		fmt.Printf("    Handshake:   %s%s (%d attempts)\n", term.Symbol("✗ ", ""), d.LastHandshakeErr, d.HandshakeAttempts)
+	}
+
+	if len(d.CloseCodes) > 0 {
+		codes := make([]int, 0, len(d.CloseCodes))
+		for code := range d.CloseCodes {
+			codes = append(codes, code)
+		}
+		sort.Ints(codes)
+		fmt.Print("    Close codes: ")
+		for i, code := range codes {
+			if i > 0 {
+				fmt.Print(", ")
+			}
+			fmt.Printf("%d %s%d", code, term.Symbol("×", "x"), d.CloseCodes[code])
+		}
+		fmt.Println()
+	}
+	fmt.Printf("    Breaker:     opened %d time(s)\n", d.CircuitOpens)
+	fmt.Println()
+	fmt.Printf("  %s%s\n", term.Symbol("→ ", ""), r.Verdict)
+	fmt.Println()
+}
+
+// shareDiagnostics uploads the post-mortem and prints the support reference
+func shareDiagnostics(apiURL, token string, r connectionReport) {
+	// The session context is already cancelled by Ctrl+C
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+
+	id, err := api.NewClient(apiURL, token).UploadDiagnostics(ctx, r)
+	if err != nil {
+		fmt.Printf("  %sFailed to upload diagnostics: %v\n", term.Symbol("✗ ", ""), err)
+		return
+	}
+	fmt.Printf("  %sDiagnostics uploaded. Quote reference %s when contacting support.\n", term.Symbol("✓ ", ""), id)
+}
+
+// sessionSummary is the record of a bridge session kept for support bundles
+type sessionSummary struct {
+	StartedAt   time.Time         `json:"started_at"`
+	Duration    string            `json:"duration"`
+	Version     string            `json:"version"`
+	APIURL      string            `json:"api_url"`
+	DeviceID    string            `json:"device_id"`
+	Verdict     string            `json:"verdict"`
+	Stats       cli.StatsSnapshot `json:"stats"`
+	Diagnostics cli.Diagnostics   `json:"diagnostics"`
+}
+
+// recordSession appends the session summary to the local session history and
+// its statistics to the statistics database behind 'aircast-cli history'
+func recordSession(apiURL, deviceID, deviceName, site string, s cli.StatsSnapshot, l cli.LinkStats, d cli.Diagnostics, logger *log.Entry) {
+	db, err := store.NewStatsDB()
+	if err == nil {
+		err = db.Add(store.SessionRecord{
+			StartedAt:  d.StartedAt,
+			EndedAt:    time.Now(),
+			DeviceID:   deviceID,
+			DeviceName: deviceName,
+			Site:       site,
+			BytesUp:    s.Uplink.Bytes,
+			BytesDown:  s.Downlink.Bytes,
+			Frames:     s.Downlink.Frames,
+			Lost:       s.Downlink.Lost,
+			Reconnects: l.Reconnects,
+			RTT:        l.RTT,
+		})
+	}
+	if err != nil {
+		logger.WithError(err).Warn("Failed to record session statistics")
+	}
+
+	history, err := auth.NewSessionHistory()
+	if err != nil {
+		logger.WithError(err).Warn("Failed to open session history")
+		return
+	}
+
+	err = history.Append(sessionSummary{
+		StartedAt:   d.StartedAt,
+		Duration:    time.Since(d.StartedAt).Round(time.Second).String(),
+		Version:     version,
+		APIURL:      apiURL,
+		DeviceID:    deviceID,
+		Verdict:     d.Verdict(),
+		Stats:       s,
+		Diagnostics: d,
+	})
+	if err != nil {
+		logger.WithError(err).Warn("Failed to record session summary")
+	}
+}
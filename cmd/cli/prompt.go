@@ -0,0 +1,114 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/pavliha/aircast/aircast-cli/internal/api"
+)
+
+// confirm asks a yes/no question on the terminal, defaulting to no
+func confirm(question string) bool {
+	fmt.Printf("%s [y/N] ", question)
+
+	answer, ok := readAnswer()
+	answer = strings.ToLower(answer)
+	return ok && (answer == "y" || answer == "yes")
+}
+
+// confirmTyped asks the user to type expected (or one of alternatives,
+// e.g. a device's ID for its name) to go ahead, so a fat-fingered Enter
+// can't confirm
+func confirmTyped(question, expected string, alternatives ...string) bool {
+	fmt.Printf("%s\nType %q to confirm: ", question, expected)
+
+	answer, ok := readAnswer()
+	if !ok {
+		return false
+	}
+	for _, want := range append([]string{expected}, alternatives...) {
+		if want != "" && answer == want {
+			return true
+		}
+	}
+	return false
+}
+
+// readAnswer reads a line from the terminal, false if there is none
+func readAnswer() (string, bool) {
+	answer, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil {
+		fmt.Println()
+		return "", false
+	}
+	return strings.TrimSpace(answer), true
+}
+
+// guard is the --yes and --dry-run handling of a command that changes or
+// deletes things in the account
+type guard struct {
+	yes     *bool
+	dryRun  *bool
+	planned []api.PlannedRequest
+}
+
+// guardFlags adds --yes and --dry-run to a command's flags
+func guardFlags(fs *flag.FlagSet) *guard {
+	return &guard{
+		yes:    fs.Bool("yes", false, "Skip the confirmation prompt"),
+		dryRun: fs.Bool("dry-run", false, "Show the API requests that would be made without making them"),
+	}
+}
+
+// dryRunFlag adds only --dry-run, for changes that don't need confirming
+func dryRunFlag(fs *flag.FlagSet) *guard {
+	return &guard{
+		yes:    new(bool),
+		dryRun: fs.Bool("dry-run", false, "Show the API requests that would be made without making them"),
+	}
+}
+
+// attach makes the client record changes instead of sending them under --dry-run
+func (g *guard) attach(client *api.Client) {
+	if *g.dryRun {
+		client.DryRun = func(req api.PlannedRequest) {
+			g.planned = append(g.planned, req)
+		}
+	}
+}
+
+// confirm asks a yes/no question unless --yes or --dry-run was given
+func (g *guard) confirm(question string) bool {
+	return *g.yes || *g.dryRun || confirm(question)
+}
+
+// confirmTyped asks the user to type expected unless --yes or --dry-run was given
+func (g *guard) confirmTyped(question, expected string, alternatives ...string) bool {
+	return *g.yes || *g.dryRun || confirmTyped(question, expected, alternatives...)
+}
+
+// skipped reports whether err is a change --dry-run recorded instead of making
+func (g *guard) skipped(err error) bool {
+	return errors.Is(err, api.ErrDryRun)
+}
+
+// report prints the requests a dry run recorded and reports whether it was
+// one. A nil guard is never a dry run.
+func (g *guard) report() bool {
+	if g == nil || !*g.dryRun {
+		return false
+	}
+	if len(g.planned) == 0 {
+		fmt.Println("Dry run: nothing would change")
+		return true
+	}
+	fmt.Println("Dry run: nothing was changed. These requests would be made:")
+	for _, req := range g.planned {
+		fmt.Printf("  %s\n", req)
+	}
+	return true
+}
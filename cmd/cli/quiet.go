@@ -0,0 +1,41 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"time"
+)
+
+// enterQuietMode silences banners and progress output by pointing os.Stdout
+// at the null device. It returns the real stdout for the READY line.
+func enterQuietMode() *os.File {
+	stdout := os.Stdout
+	if devNull, err := os.OpenFile(os.DevNull, os.O_WRONLY, 0); err == nil {
+		os.Stdout = devNull
+	}
+	return stdout
+}
+
+// printReady prints the machine-parsable line wrappers wait for once the
+// bridge accepts connections, e.g. "READY tcp=127.0.0.1:5169 udp=0.0.0.0:14550"
+func printReady(w io.Writer, tcpListen, udpListen string) {
+	line := "READY tcp=" + tcpListen
+	if udpListen != "" {
+		line += " udp=" + udpListen
+	}
+	fmt.Fprintln(w, line)
+}
+
+// touchReadyFile creates path or updates its modification time
+func touchReadyFile(path string) error {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	now := time.Now()
+	return os.Chtimes(path, now, now)
+}
@@ -0,0 +1,260 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/pavliha/aircast/aircast-cli/internal/recording"
+	"github.com/pavliha/aircast/aircast-cli/internal/term"
+	log "github.com/sirupsen/logrus"
+)
+
+// recordingCommands are the subcommands of "recording"
+var recordingCommands = map[string]command{
+	"info":  {"Show the devices, frame counts and time span of a recording", runRecordingInfo},
+	"split": {"Export each device of a recording to its own tlog", runRecordingSplit},
+}
+
+// runRecording dispatches "recording" subcommands
+func runRecording(args []string) error {
+	if len(args) == 0 {
+		printSubcommands("recording", recordingCommands)
+		os.Exit(2)
+	}
+
+	cmd, ok := recordingCommands[args[0]]
+	if !ok {
+		printSubcommands("recording", recordingCommands)
+		return fmt.Errorf("unknown recording command %q", args[0])
+	}
+	return cmd.run(args[1:])
+}
+
+// openRecording starts recording the bridged device's traffic
+func openRecording(path, deviceID, deviceName string, logger *log.Entry) *recording.Writer {
+	w, err := recording.Create(path, recording.ChannelInfo{DeviceID: deviceID, Name: deviceName})
+	if err != nil {
+		logger.WithError(err).Fatal("Failed to open recording")
+	}
+	logger.WithFields(log.Fields{
+		"file":    path,
+		"channel": w.Channel(),
+	}).Info("Recording traffic")
+	return w
+}
+
+// channelSummary accumulates what a recording holds for one channel
+type channelSummary struct {
+	id       uint32
+	info     recording.ChannelInfo
+	uplink   int
+	downlink int
+	first    time.Time
+	last     time.Time
+}
+
+// label names a channel for display and file names
+func (c *channelSummary) label() string {
+	if c.info.Name != "" {
+		return c.info.Name
+	}
+	if c.info.DeviceID != "" {
+		return c.info.DeviceID
+	}
+	return fmt.Sprintf("channel-%d", c.id)
+}
+
+// readRecording opens a recording and calls fn for every record
+func readRecording(path string, fn func(rec recording.Record) error) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = file.Close() }()
+
+	r, err := recording.NewReader(bufio.NewReader(file))
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	for {
+		rec, err := r.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if err := fn(rec); err != nil {
+			return err
+		}
+	}
+}
+
+// summarizeRecording reads a recording's channels in the order they appear
+func summarizeRecording(path string) ([]*channelSummary, error) {
+	channels := make(map[uint32]*channelSummary)
+	var order []*channelSummary
+
+	err := readRecording(path, func(rec recording.Record) error {
+		c, ok := channels[rec.Channel]
+		if !ok {
+			c = &channelSummary{id: rec.Channel, first: rec.Time}
+			channels[rec.Channel] = c
+			order = append(order, c)
+		}
+		switch rec.Kind {
+		case recording.KindChannel:
+			if info, err := rec.ChannelInfo(); err == nil {
+				c.info = info
+			}
+		case recording.KindUplink:
+			c.uplink++
+		case recording.KindDownlink:
+			c.downlink++
+		}
+		if rec.Time.Before(c.first) {
+			c.first = rec.Time
+		}
+		if rec.Time.After(c.last) {
+			c.last = rec.Time
+		}
+		return nil
+	})
+	return order, err
+}
+
+// runRecordingInfo prints a summary of each channel in a recording
+func runRecordingInfo(args []string) error {
+	fs := flag.NewFlagSet("recording info", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: aircast-cli recording info <file>\n")
+	}
+	positional := parseArgs(fs, args)
+	if len(positional) != 1 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	channels, err := summarizeRecording(positional[0])
+	if err != nil {
+		return err
+	}
+	if len(channels) == 0 {
+		fmt.Println("Recording is empty")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "CHANNEL\tDEVICE\tNAME\tDOWNLINK\tUPLINK\tSTART\tDURATION")
+	for _, c := range channels {
+		fmt.Fprintf(w, "%d\t%s\t%s\t%d\t%d\t%s\t%s\n",
+			c.id, c.info.DeviceID, c.info.Name, c.downlink, c.uplink,
+			c.first.Local().Format("2006-01-02 15:04:05"),
+			c.last.Sub(c.first).Round(time.Second))
+	}
+	return w.Flush()
+}
+
+// unsafeFileChars are replaced when deriving tlog names from device names
+var unsafeFileChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// channelFileNames names files after the channels' devices, disambiguating
+// duplicate names
+func channelFileNames(channels []*channelSummary) map[uint32]string {
+	names := make(map[uint32]string, len(channels))
+	used := make(map[string]bool)
+	for _, c := range channels {
+		name := strings.Trim(unsafeFileChars.ReplaceAllString(c.label(), "_"), "_")
+		if used[name] {
+			name = fmt.Sprintf("%s-%d", name, c.id)
+		}
+		used[name] = true
+		names[c.id] = name
+	}
+	return names
+}
+
+// runRecordingSplit writes one tlog per device, keeping the shared
+// timestamps so the logs replay in sync
+func runRecordingSplit(args []string) error {
+	fs := flag.NewFlagSet("recording split", flag.ExitOnError)
+	outDir := fs.String("out", ".", "Directory for the tlog files")
+	downlinkOnly := fs.Bool("downlink-only", false, "Omit frames sent by ground stations")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: aircast-cli recording split [flags] <file>\n\n")
+		fs.PrintDefaults()
+	}
+	positional := parseArgs(fs, args)
+	if len(positional) != 1 {
+		fs.Usage()
+		os.Exit(2)
+	}
+	path := positional[0]
+
+	channels, err := summarizeRecording(path)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(*outDir, 0755); err != nil {
+		return err
+	}
+
+	names := channelFileNames(channels)
+
+	files := make(map[uint32]*bufio.Writer)
+	var closers []*os.File
+	defer func() {
+		for _, f := range closers {
+			_ = f.Close()
+		}
+	}()
+
+	err = readRecording(path, func(rec recording.Record) error {
+		if rec.Kind == recording.KindChannel || (*downlinkOnly && rec.Kind == recording.KindUplink) {
+			return nil
+		}
+		out, ok := files[rec.Channel]
+		if !ok {
+			f, err := os.Create(filepath.Join(*outDir, names[rec.Channel]+".tlog"))
+			if err != nil {
+				return err
+			}
+			closers = append(closers, f)
+			out = bufio.NewWriter(f)
+			files[rec.Channel] = out
+		}
+		return recording.WriteTlog(out, rec.Time, rec.Data)
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, out := range files {
+		if err := out.Flush(); err != nil {
+			return err
+		}
+	}
+
+	ids := make([]uint32, 0, len(files))
+	for id := range files {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return names[ids[i]] < names[ids[j]] })
+	for _, id := range ids {
+		fmt.Printf("%s%s\n", term.Symbol("✓ ", ""), filepath.Join(*outDir, names[id]+".tlog"))
+	}
+	if len(ids) == 0 {
+		fmt.Println("No frames to export")
+	}
+	return nil
+}
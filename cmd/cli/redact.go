@@ -0,0 +1,145 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/pavliha/aircast/aircast-cli/internal/auth"
+	"github.com/pavliha/aircast/aircast-cli/internal/store"
+	log "github.com/sirupsen/logrus"
+)
+
+// redactedValue replaces secrets in logs and support bundles
+const redactedValue = "[REDACTED]"
+
+// secretPatterns match credentials that must never leave the machine. A
+// pattern with a group keeps the group, e.g. the name of a redacted field.
+var secretPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`eyJ[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+\.[A-Za-z0-9_-]*`), // JWTs
+	regexp.MustCompile(`(?i)(bearer\s+)[A-Za-z0-9._~+/=-]+`),
+	regexp.MustCompile(`(?i)((?:access_token|refresh_token|id_token|token|secret|password|client_secret|code_verifier|device_code|user_code)["']?(?:\s*=\s*["']?|:\s*["']|:))[^"'\s&,;}]+`),
+	regexp.MustCompile(`([?&](?:code|state)=)[^&\s"]+`), // OAuth callback parameters
+	regexp.MustCompile(`(?i)((?:^|[\s;"])(?:set-)?cookie:\s*)[^;"\n]+`),
+	regexp.MustCompile(`(?i)((?:^|[\s;"])(?:[A-Za-z0-9_.-]*session[A-Za-z0-9_.-]*|sid|[A-Za-z0-9_-]+\.sid)=)[^;&\s"]+`), // Session cookies
+	regexp.MustCompile(`(://[^:/@\s]+:)[^@\s]+@`),                                                                       // URL credentials
+}
+
+// sensitiveFields are log fields whose values are always redacted
+var sensitiveFields = map[string]bool{
+	"token":         true,
+	"access_token":  true,
+	"refresh_token": true,
+	"device_code":   true,
+	"user_code":     true,
+	"cookie":        true,
+	"authorization": true,
+	"password":      true,
+	"secret":        true,
+}
+
+// redactSecrets replaces tokens and credentials in s with a placeholder
+func redactSecrets(s string) string {
+	return redactWith(secretPatterns, s)
+}
+
+// redactWith replaces the matches of patterns in s with a placeholder
+func redactWith(patterns []*regexp.Regexp, s string) string {
+	for _, re := range patterns {
+		if re.NumSubexp() > 0 {
+			s = re.ReplaceAllString(s, "${1}"+redactedValue)
+		} else {
+			s = re.ReplaceAllString(s, redactedValue)
+		}
+	}
+	return s
+}
+
+// redactHook removes credentials from every log entry before the log sinks
+// see it, so debug logs and support bundles can be shared. It must be the
+// first hook added: logrus fires hooks in the order they were added.
+type redactHook struct {
+	mu       sync.RWMutex
+	patterns []*regexp.Regexp
+}
+
+// logRedactor is the redaction hook of all log output
+var logRedactor = &redactHook{patterns: secretPatterns}
+
+// addPatterns adds redaction patterns, e.g. from "log_redact" in config.json
+func (h *redactHook) addPatterns(patterns []string) error {
+	compiled := make([]*regexp.Regexp, 0, len(patterns))
+	for _, p := range patterns {
+		re, err := regexp.Compile(p)
+		if err != nil {
+			return fmt.Errorf("invalid pattern %q: %w", p, err)
+		}
+		compiled = append(compiled, re)
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.patterns = append(h.patterns[:len(h.patterns):len(h.patterns)], compiled...)
+	return nil
+}
+
+// loadRedactPatterns adds the "log_redact" patterns of config.json to the
+// redaction hook, so every command's logs use them. A config file that
+// can't be read is left to the command to report.
+func loadRedactPatterns() error {
+	// Don't create the config directory just for this
+	if dir, err := store.ConfigDirPath(); err != nil {
+		return nil
+	} else if _, err := os.Stat(dir); err != nil {
+		return nil
+	}
+	configStore, err := auth.NewConfigStore()
+	if err != nil {
+		return nil
+	}
+	config, err := configStore.LoadConfig()
+	if err != nil {
+		return nil
+	}
+	if err := logRedactor.addPatterns(config.LogRedact); err != nil {
+		return fmt.Errorf("invalid log_redact in %s: %w", configStore.GetConfigPath(), err)
+	}
+	return nil
+}
+
+// Levels implements log.Hook
+func (h *redactHook) Levels() []log.Level {
+	return log.AllLevels
+}
+
+// Fire implements log.Hook. The entry is the logger's own copy, so its
+// message and fields can be replaced.
+func (h *redactHook) Fire(entry *log.Entry) error {
+	h.mu.RLock()
+	patterns := h.patterns
+	h.mu.RUnlock()
+
+	entry.Message = redactWith(patterns, entry.Message)
+	for name, value := range entry.Data {
+		if sensitiveFields[strings.ToLower(name)] {
+			entry.Data[name] = redactedValue
+			continue
+		}
+		switch v := value.(type) {
+		case string:
+			entry.Data[name] = redactWith(patterns, v)
+		case error:
+			if s := redactWith(patterns, v.Error()); s != v.Error() {
+				entry.Data[name] = errors.New(s)
+			}
+		case fmt.Stringer:
+			if s := redactWith(patterns, v.String()); s != v.String() {
+				entry.Data[name] = s
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,23 @@
+package main
+
+import "github.com/pavliha/aircast/aircast-cli/internal/cli"
+
+// parseRemaps combines source ID remap rules from flags and config.json,
+// keeping flag rules first so they take precedence
+func parseRemaps(rules ...[]string) ([]cli.RemapRule, error) {
+	var remaps []cli.RemapRule
+	seen := make(map[string]bool)
+	for _, set := range rules {
+		for _, s := range set {
+			rule, err := cli.ParseRemapRule(s)
+			if err != nil {
+				return nil, err
+			}
+			if !seen[rule.String()] {
+				seen[rule.String()] = true
+				remaps = append(remaps, rule)
+			}
+		}
+	}
+	return remaps, nil
+}
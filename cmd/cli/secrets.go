@@ -0,0 +1,53 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/pavliha/aircast/aircast-cli/internal/auth"
+	"github.com/pavliha/aircast/aircast-cli/internal/secret"
+	"github.com/pavliha/aircast/aircast-cli/internal/tsdb"
+)
+
+// resolveSecret replaces a secret reference (env:, file:, fd: or prompt)
+// with the secret it refers to and, unless it is a URL whose credentials
+// are redacted anyway, removes the secret from all log output
+func resolveSecret(value *string, label string, redact bool) error {
+	if !secret.IsReference(*value) {
+		return nil
+	}
+	v, err := secret.Resolve(*value, label)
+	if err != nil {
+		return err
+	}
+	*value = v
+	if redact {
+		return logRedactor.addPatterns([]string{regexp.QuoteMeta(v)})
+	}
+	return nil
+}
+
+// resolveConfigSecrets resolves the secret references of the webhooks and
+// telemetry sinks in config.json
+func resolveConfigSecrets(config *auth.Config) error {
+	for i := range config.Webhooks {
+		hook := &config.Webhooks[i]
+		if err := resolveSecret(&hook.Secret, "Signing secret of webhook "+hook.URL, true); err != nil {
+			return err
+		}
+		// Chat service webhook URLs (Slack, Discord, Teams) are themselves secrets
+		if err := resolveSecret(&hook.URL, fmt.Sprintf("URL of webhook %d", i+1), true); err != nil {
+			return err
+		}
+	}
+	for i := range config.TelemetrySinks {
+		sink := &config.TelemetrySinks[i]
+		if err := resolveSecret(&sink.URL, fmt.Sprintf("URL of telemetry sink %d (%s)", i+1, sink.Type), false); err != nil {
+			return err
+		}
+		if err := resolveSecret(&sink.Token, "Token of telemetry sink "+tsdb.Target(*sink), true); err != nil {
+			return err
+		}
+	}
+	return nil
+}
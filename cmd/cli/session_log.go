@@ -0,0 +1,103 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/mattn/go-isatty"
+	"github.com/pavliha/aircast/aircast-cli/internal/store"
+	"github.com/pavliha/aircast/aircast-cli/internal/term"
+	"github.com/pavliha/aircast/aircast-cli/internal/ui"
+	log "github.com/sirupsen/logrus"
+)
+
+// sessionLogName is the file in the config directory holding the last
+// bridge session's log, collected by support-bundle
+const sessionLogName = "last-session.log"
+
+// logSinkHook writes log entries up to a level to one destination
+type logSinkHook struct {
+	out        io.Writer
+	formatter  log.Formatter
+	level      log.Level
+	components componentLevels // Per-component levels from --log-components
+}
+
+// Levels implements log.Hook
+func (h *logSinkHook) Levels() []log.Level {
+	return log.AllLevels[:h.level+1]
+}
+
+// Fire implements log.Hook
+func (h *logSinkHook) Fire(entry *log.Entry) error {
+	if !h.components.allows(entry) {
+		return nil
+	}
+	line, err := h.formatter.Format(entry)
+	if err != nil {
+		return err
+	}
+	_, err = h.out.Write(line)
+	return err
+}
+
+// setupLogging keeps diagnostics apart from the operator console. Log
+// entries go to sinks rather than the logger's own output: the log file if
+// one is given (with only errors also shown on the terminal), otherwise
+// stderr. When stderr is the operator's terminal, it only shows warnings
+// and errors unless the level was chosen explicitly; the full log is still
+// kept for support bundles. With per-component levels, the logger runs at
+// the most verbose of them and the sinks filter by component.
+func setupLogging(level log.Level, explicit bool, logFile string, components componentLevels) error {
+	level = components.max(level)
+	log.SetLevel(level)
+	log.SetOutput(io.Discard)
+
+	consoleLevel := level
+	if logFile != "" {
+		file, err := os.OpenFile(logFile, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+		if err != nil {
+			return fmt.Errorf("failed to open log file: %w", err)
+		}
+		log.AddHook(&logSinkHook{
+			out:        file,
+			formatter:  &log.TextFormatter{DisableColors: true, FullTimestamp: true},
+			level:      level,
+			components: components,
+		})
+		consoleLevel = min(level, log.ErrorLevel)
+	} else if !explicit && isatty.IsTerminal(os.Stderr.Fd()) {
+		consoleLevel = min(level, log.WarnLevel)
+	}
+
+	log.AddHook(&logSinkHook{
+		out:        ui.LogOutput,
+		formatter:  &log.TextFormatter{FullTimestamp: true, ForceColors: isatty.IsTerminal(os.Stderr.Fd()) && !term.Accessible(), DisableColors: term.Accessible()},
+		level:      consoleLevel,
+		components: components,
+	})
+	return nil
+}
+
+// attachSessionLog starts copying this session's log to the config directory
+func attachSessionLog(components componentLevels) error {
+	configDir, err := store.ConfigDir()
+	if err != nil {
+		return err
+	}
+
+	file, err := os.OpenFile(filepath.Join(configDir, sessionLogName), os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+
+	log.AddHook(&logSinkHook{
+		out:        file,
+		formatter:  &log.TextFormatter{DisableColors: true, FullTimestamp: true},
+		level:      log.GetLevel(),
+		components: components,
+	})
+	return nil
+}
@@ -0,0 +1,10 @@
+//go:build !windows
+
+package main
+
+import "fmt"
+
+// runSetupWindows is only meaningful on Windows
+func runSetupWindows(args []string) error {
+	return fmt.Errorf("setup-windows is only available on Windows")
+}
@@ -0,0 +1,255 @@
+//go:build windows
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// firewallRuleName prefixes the firewall rules created by setup-windows
+const firewallRuleName = "Aircast CLI"
+
+// startupValueName is the value under the HKCU Run key that starts the bridge at logon
+const startupValueName = "AircastCLI"
+
+// runSetupWindows registers firewall rules, PATH and an optional startup
+// entry so first connections aren't blocked by Windows Defender prompts
+func runSetupWindows(args []string) error {
+	fs := flag.NewFlagSet("setup-windows", flag.ExitOnError)
+	tcpListen := fs.String("tcp", getEnv("AIRCAST_TCP_LISTEN", "127.0.0.1:5169"), "TCP listen address the bridge will use")
+	udpListen := fs.String("udp", getEnv("AIRCAST_UDP_LISTEN", ""), "UDP listen address the bridge will use (optional)")
+	startup := fs.Bool("startup", false, "Start the bridge automatically at logon")
+	addPath := fs.Bool("path", true, "Add the aircast-cli directory to your user PATH")
+	remove := fs.Bool("remove", false, "Remove firewall rules, startup entry and PATH entry")
+	noElevate := fs.Bool("no-elevate", false, "Don't prompt for administrator rights (firewall rules are skipped)")
+	pause := fs.Bool("pause", false, "Wait for Enter before exiting (used by the elevated window)")
+	firewallOnly := fs.Bool("firewall-only", false, "Only add or remove the firewall rules (used by the elevated window)")
+	_ = fs.Parse(args)
+
+	if *pause {
+		defer func() {
+			fmt.Print("\nPress Enter to close this window...")
+			_, _ = fmt.Scanln()
+		}()
+	}
+
+	exe, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("failed to locate executable: %w", err)
+	}
+
+	// The elevated window only handles the firewall rules
+	if *firewallOnly {
+		if *remove {
+			removeFirewallRules(exe)
+			return nil
+		}
+		return addFirewallRules(exe, *tcpListen, *udpListen)
+	}
+
+	if *remove {
+		if err := firewallStep(exe, args, *noElevate, func() error {
+			removeFirewallRules(exe)
+			return nil
+		}); err != nil {
+			return err
+		}
+		return removeUserSetup(exe)
+	}
+
+	if err := firewallStep(exe, args, *noElevate, func() error {
+		return addFirewallRules(exe, *tcpListen, *udpListen)
+	}); err != nil {
+		return err
+	}
+
+	if *addPath {
+		if err := addToUserPath(filepath.Dir(exe)); err != nil {
+			return err
+		}
+	}
+
+	if *startup {
+		command := fmt.Sprintf(`"%s" connect --tcp %s`, exe, *tcpListen)
+		if *udpListen != "" {
+			command += " --udp " + *udpListen
+		}
+		if err := runCommand("reg", "add", `HKCU\Software\Microsoft\Windows\CurrentVersion\Run`,
+			"/v", startupValueName, "/t", "REG_SZ", "/d", command, "/f"); err != nil {
+			return fmt.Errorf("failed to add startup entry: %w", err)
+		}
+		fmt.Println("✓ Bridge will start at logon")
+	}
+
+	fmt.Println()
+	fmt.Println("Setup complete. Open a new terminal to use 'aircast-cli' from anywhere.")
+	return nil
+}
+
+// firewallRule is an inbound allow rule for one listener
+type firewallRule struct {
+	name     string
+	protocol string
+	port     string
+}
+
+// firewallRules returns rules for the listeners; loopback-only listeners
+// never trigger Defender prompts, but are registered so later changes work
+func firewallRules(tcpListen, udpListen string) []firewallRule {
+	var rules []firewallRule
+	for _, l := range []struct{ protocol, addr string }{{"TCP", tcpListen}, {"UDP", udpListen}} {
+		if l.addr == "" {
+			continue
+		}
+		_, port, err := net.SplitHostPort(l.addr)
+		if err != nil {
+			continue
+		}
+		rules = append(rules, firewallRule{
+			name:     fmt.Sprintf("%s (%s %s)", firewallRuleName, l.protocol, port),
+			protocol: l.protocol,
+			port:     port,
+		})
+	}
+	return rules
+}
+
+// addFirewallRule replaces any existing rule of the same name
+func addFirewallRule(exe string, rule firewallRule) error {
+	_ = runCommand("netsh", "advfirewall", "firewall", "delete", "rule", "name="+rule.name)
+	err := runCommand("netsh", "advfirewall", "firewall", "add", "rule",
+		"name="+rule.name, "dir=in", "action=allow", "enable=yes",
+		"program="+exe, "protocol="+rule.protocol, "localport="+rule.port,
+		"profile=private,domain")
+	if err != nil {
+		return fmt.Errorf("failed to add firewall rule %q: %w", rule.name, err)
+	}
+	return nil
+}
+
+// firewallStep runs the firewall part of setup: directly with administrator
+// rights, otherwise in an elevated setup-windows that does nothing else, so
+// the PATH and startup entries still land in this user's profile rather
+// than the administrator's
+func firewallStep(exe string, args []string, noElevate bool, run func() error) error {
+	if isAdmin() {
+		return run()
+	}
+	if noElevate {
+		fmt.Println("⚠ Skipping firewall rules (not running as administrator)")
+		return nil
+	}
+
+	fmt.Println("Administrator rights are needed for the firewall rules.")
+	fmt.Println("Approve the Windows prompt to continue in an elevated window...")
+	return runElevated(exe, append([]string{"setup-windows", "--firewall-only", "--pause"}, args...))
+}
+
+// addFirewallRules adds the rules for the bridge's listeners
+func addFirewallRules(exe, tcpListen, udpListen string) error {
+	for _, rule := range firewallRules(tcpListen, udpListen) {
+		if err := addFirewallRule(exe, rule); err != nil {
+			return err
+		}
+		fmt.Printf("✓ Firewall rule added: %s\n", rule.name)
+	}
+	return nil
+}
+
+// removeFirewallRules removes every rule for the executable
+func removeFirewallRules(exe string) {
+	// netsh matches rule names exactly, so remove by program instead
+	_ = runCommand("netsh", "advfirewall", "firewall", "delete", "rule", "name=all", "program="+exe)
+	fmt.Println("✓ Firewall rules removed")
+}
+
+// removeUserSetup undoes the startup and PATH entries setup-windows
+// registers in the user's profile
+func removeUserSetup(exe string) error {
+	_ = runCommand("reg", "delete", `HKCU\Software\Microsoft\Windows\CurrentVersion\Run`, "/v", startupValueName, "/f")
+	fmt.Println("✓ Startup entry removed")
+	return removeFromUserPath(filepath.Dir(exe))
+}
+
+// userPathScript reads and rewrites the user PATH without setx, which
+// truncates values over 1024 characters
+const userPathScript = `$dir = $env:AIRCAST_DIR
+$parts = @([Environment]::GetEnvironmentVariable('Path', 'User') -split ';' | Where-Object { $_ -and $_ -ne $dir })
+if ($env:AIRCAST_ADD -eq '1') { $parts += $dir }
+[Environment]::SetEnvironmentVariable('Path', ($parts -join ';'), 'User')`
+
+// addToUserPath appends dir to the user PATH if missing
+func addToUserPath(dir string) error {
+	if pathContains(os.Getenv("PATH"), dir) {
+		fmt.Println("✓ Already on PATH")
+		return nil
+	}
+	if err := runPowerShell(userPathScript, "AIRCAST_DIR="+dir, "AIRCAST_ADD=1"); err != nil {
+		return fmt.Errorf("failed to update PATH: %w", err)
+	}
+	fmt.Printf("✓ Added %s to your PATH\n", dir)
+	return nil
+}
+
+// removeFromUserPath removes dir from the user PATH
+func removeFromUserPath(dir string) error {
+	if err := runPowerShell(userPathScript, "AIRCAST_DIR="+dir, "AIRCAST_ADD=0"); err != nil {
+		return fmt.Errorf("failed to update PATH: %w", err)
+	}
+	fmt.Println("✓ PATH entry removed")
+	return nil
+}
+
+// pathContains reports whether a PATH-style list contains dir
+func pathContains(pathList, dir string) bool {
+	for _, p := range filepath.SplitList(pathList) {
+		if strings.EqualFold(filepath.Clean(p), filepath.Clean(dir)) {
+			return true
+		}
+	}
+	return false
+}
+
+// isAdmin reports whether the process has administrator rights; "net session"
+// fails with access denied otherwise
+func isAdmin() bool {
+	return exec.Command("net", "session").Run() == nil
+}
+
+// runElevated re-launches the executable through the UAC prompt and waits for it
+func runElevated(exe string, args []string) error {
+	quoted := make([]string, len(args))
+	for i, a := range args {
+		quoted[i] = "'" + strings.ReplaceAll(a, "'", "''") + "'"
+	}
+	script := fmt.Sprintf("Start-Process -FilePath '%s' -ArgumentList %s -Verb RunAs -Wait",
+		strings.ReplaceAll(exe, "'", "''"), strings.Join(quoted, ","))
+	if err := runPowerShell(script); err != nil {
+		return fmt.Errorf("elevation was cancelled or failed: %w", err)
+	}
+	return nil
+}
+
+// runPowerShell runs a script with extra environment variables
+func runPowerShell(script string, env ...string) error {
+	cmd := exec.Command("powershell", "-NoProfile", "-NonInteractive", "-Command", script)
+	cmd.Env = append(os.Environ(), env...)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("%w: %s", err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// runCommand runs a command and includes its output in any error
+func runCommand(name string, args ...string) error {
+	if out, err := exec.Command(name, args...).CombinedOutput(); err != nil {
+		return fmt.Errorf("%w: %s", err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
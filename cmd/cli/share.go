@@ -0,0 +1,87 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/pavliha/aircast/aircast-cli/internal/control"
+	"github.com/pavliha/aircast/aircast-cli/internal/qr"
+	"github.com/pavliha/aircast/aircast-cli/internal/recording"
+	"github.com/pavliha/aircast/aircast-cli/internal/term"
+)
+
+// maxShareExpiry is the longest a share link may stay valid
+const maxShareExpiry = 24 * time.Hour
+
+// runShare mints a temporary read-only link to a device's live telemetry,
+// by default for the device the running bridge is connected to
+func runShare(args []string) error {
+	fs := flag.NewFlagSet("share", flag.ExitOnError)
+	apiURL := fs.String("api", defaultAPIURL(), "API base URL")
+	expires := fs.Duration("expires", time.Hour, "How long the link stays valid (at most 24h)")
+	socket := fs.String("control-socket", defaultControlSocket(), "Control socket path of the running bridge")
+	showQR := fs.Bool("qr", true, "Print a QR code of the link")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: aircast-cli share [flags] [device-id|alias]\n\n")
+		fmt.Fprintf(fs.Output(), "Creates a read-only link to a device's live telemetry in the web dashboard.\n")
+		fmt.Fprintf(fs.Output(), "Without a device, shares the device of the running bridge.\n\n")
+		fs.PrintDefaults()
+	}
+
+	positional := parseArgs(fs, args)
+	if len(positional) > 1 {
+		fs.Usage()
+		os.Exit(2)
+	}
+	if *expires <= 0 || *expires > maxShareExpiry {
+		return fmt.Errorf("--expires must be between 1s and %s", maxShareExpiry)
+	}
+
+	var deviceID, deviceName string
+	if len(positional) == 1 {
+		var err error
+		if deviceID, err = resolveDeviceArg(positional[0]); err != nil {
+			return err
+		}
+	} else {
+		var channel recording.ChannelInfo
+		if err := control.Call(*socket, "device", nil, &channel); err != nil {
+			return fmt.Errorf("name a device to share, or start the bridge first: %w", err)
+		}
+		deviceID, deviceName = channel.DeviceID, channel.Name
+	}
+
+	client, err := newAPIClient(*apiURL)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	share, err := client.CreateShare(ctx, deviceID, *expires)
+	if err != nil {
+		return err
+	}
+
+	if deviceName == "" {
+		deviceName = deviceID
+	}
+	fmt.Printf("%sRead-only link to %s, valid until %s:\n\n", term.Symbol("🔗 ", ""), deviceName, share.ExpiresAt.Local().Format("2006-01-02 15:04"))
+	fmt.Printf("  %s\n\n", share.URL)
+
+	// Screen readers and ASCII terminals can't use a QR code
+	if *showQR && term.UTF8() && !term.Accessible() {
+		if code, err := qr.Encode(share.URL); err == nil {
+			fmt.Print(code.String())
+			fmt.Println()
+		}
+	}
+	fmt.Println("Anyone with the link can watch telemetry until it expires; it can't send commands.")
+	return nil
+}
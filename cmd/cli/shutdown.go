@@ -0,0 +1,38 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// forceExitOnSignal makes sure shutdown can't hang: a second Ctrl+C (or
+// SIGTERM) exits at once, and if cleanup still runs well past the bridge's
+// shutdown timeout the process exits on its own. pidFile is removed either
+// way, if it names this process. The returned func disarms the timeout once
+// connections and files are closed, leaving only the session report, which
+// may wait on the API.
+func forceExitOnSignal(timeout time.Duration, pidFile string, logger *log.Entry) (cleanedUp func()) {
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, os.Interrupt, syscall.SIGTERM)
+
+	go func() {
+		<-sigs
+		fmt.Fprintln(os.Stderr, "\nForced exit; some connections and files may not have been closed cleanly")
+		removePIDFile(pidFile)
+		os.Exit(130)
+	}()
+
+	// The bridge gives up on stuck goroutines after timeout; allow as long
+	// again for recordings, notifications and the session history
+	watchdog := time.AfterFunc(2*timeout, func() {
+		logger.Errorf("Shutdown did not finish within %s, exiting", 2*timeout)
+		removePIDFile(pidFile)
+		os.Exit(1)
+	})
+	return func() { watchdog.Stop() }
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/pavliha/aircast/aircast-cli/internal/cli"
+	"github.com/pavliha/aircast/aircast-cli/internal/term"
+)
+
+// Thresholds for the speed test verdict
+const (
+	speedFullTelemetry    = 8 * 1024 // Bytes per second for full-rate telemetry
+	speedMinimalTelemetry = 2 * 1024 // Bytes per second for the cellular-minimal profile
+	speedSluggishRTT      = 500 * time.Millisecond
+	speedBufferbloatRTT   = time.Second
+	speedLossWarning      = 2.0 // Percent
+)
+
+// runSpeedtest measures latency and throughput over the WebSocket path to a
+// device, to set expectations before a flight
+func runSpeedtest(args []string) error {
+	fs := flag.NewFlagSet("speedtest", flag.ExitOnError)
+	apiURL := fs.String("api", defaultAPIURL(), "API base URL")
+	pings := fs.Int("pings", 20, "Number of latency probes")
+	interval := fs.Duration("interval", 100*time.Millisecond, "Pause between latency probes")
+	duration := fs.Duration("duration", 10*time.Second, "Length of the throughput test")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: aircast-cli speedtest [flags] <device-id|alias>\n\n")
+		fmt.Fprintf(fs.Output(), "Measures latency and throughput over the path to a device, through the relay\n")
+		fmt.Fprintf(fs.Output(), "and the device's uplink, rather than your own internet connection.\n\n")
+		fs.PrintDefaults()
+	}
+
+	positional := parseArgs(fs, args)
+	if len(positional) != 1 {
+		fs.Usage()
+		os.Exit(2)
+	}
+	if *pings < 1 {
+		return fmt.Errorf("--pings must be at least 1")
+	}
+	if *duration <= 0 {
+		return fmt.Errorf("--duration must be positive")
+	}
+
+	deviceID, err := resolveDeviceArg(positional[0])
+	if err != nil {
+		return err
+	}
+	client, err := newAPIClient(*apiURL)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	fmt.Println(term.Banner("Speed Test"))
+	fmt.Println()
+	progress := func(phase string) {
+		switch phase {
+		case "latency":
+			fmt.Printf("Measuring latency with %d probes...\n", *pings)
+		case "throughput":
+			fmt.Printf("Measuring throughput for %s...\n", *duration)
+		}
+	}
+
+	opts := cli.SpeedTestOptions{Pings: *pings, Interval: *interval, Duration: *duration}
+	result, err := cli.SpeedTest(ctx, buildEchoWebSocketURL(*apiURL, deviceID), client.Token(), opts, progress)
+	if errors.Is(err, cli.ErrEchoUnsupported) {
+		return fmt.Errorf("%w; update aircast-agent on the device", err)
+	}
+	if err != nil {
+		return err
+	}
+
+	fmt.Println()
+	fmt.Printf("  Latency:     %s min, %s median, %s p95\n", roundRTT(result.MinRTT), roundRTT(result.MedianRTT), roundRTT(result.P95RTT))
+	fmt.Printf("  Jitter:      %s\n", roundRTT(result.Jitter))
+	fmt.Printf("  Loss:        %.1f%% (%d of %d probes)\n", result.LossPercent(), result.Lost, result.Pings)
+	fmt.Printf("  Throughput:  %s/s (%.0f kbit/s)\n", cli.FormatBytes(uint64(result.Throughput)), result.Throughput*8/1000)
+	fmt.Printf("  Under load:  %s median latency\n", roundRTT(result.LoadedRTT))
+	fmt.Println()
+
+	for _, line := range speedVerdict(result) {
+		fmt.Println(line)
+	}
+	return nil
+}
+
+// speedVerdict explains what a speed test result means for a flight
+func speedVerdict(r *cli.SpeedTestResult) []string {
+	warn := term.Symbol("⚠️  ", "Warning: ")
+	var lines []string
+	switch {
+	case r.Throughput < speedMinimalTelemetry:
+		lines = append(lines, warn+"Too slow for regular telemetry; expect gaps even with --profile "+cli.ProfileCellularMinimal+".")
+	case r.Throughput < speedFullTelemetry:
+		lines = append(lines, warn+"Too slow for full-rate telemetry; use --profile "+cli.ProfileLowBandwidth+" or "+cli.ProfileCellularMinimal+".")
+	}
+	if r.P95RTT > speedSluggishRTT {
+		lines = append(lines, warn+"High latency; commands and joystick input will feel sluggish.")
+	}
+	if r.LoadedRTT > speedBufferbloatRTT && r.LoadedRTT > 2*r.MedianRTT {
+		lines = append(lines, warn+"Latency rises sharply under load; avoid log downloads and video during the flight.")
+	}
+	if r.LossPercent() > speedLossWarning {
+		lines = append(lines, warn+"Probes were lost; the link may drop out.")
+	}
+	if len(lines) == 0 {
+		lines = append(lines, term.Symbol("✓ ", "OK: ")+"The link is good for full-rate telemetry.")
+	}
+	return lines
+}
+
+// roundRTT rounds a round trip for display
+func roundRTT(d time.Duration) time.Duration {
+	if d < 10*time.Millisecond {
+		return d.Round(100 * time.Microsecond)
+	}
+	return d.Round(time.Millisecond)
+}
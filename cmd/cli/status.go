@@ -0,0 +1,138 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/pavliha/aircast/aircast-cli/internal/cli"
+	"github.com/pavliha/aircast/aircast-cli/internal/control"
+	"github.com/pavliha/aircast/aircast-cli/internal/recording"
+	"github.com/pavliha/aircast/aircast-cli/internal/term"
+)
+
+// bridgeStatus is the reply to the "status" control command
+type bridgeStatus struct {
+	PID    int                   `json:"pid"`
+	Device recording.ChannelInfo `json:"device"`
+	cli.BridgeStatus
+}
+
+// statusReport is what "status --json" prints
+type statusReport struct {
+	Running bool          `json:"running"`
+	PID     int           `json:"pid,omitempty"`
+	Bridge  *bridgeStatus `json:"bridge,omitempty"` // Missing if the control socket didn't answer
+	Error   string        `json:"error,omitempty"`
+}
+
+// runStatus reports whether a bridge is running, in the foreground or with
+// --daemon, and its health as reported over the control socket. It exits
+// with status 3 if no bridge is running.
+func runStatus(args []string) error {
+	fs := flag.NewFlagSet("status", flag.ExitOnError)
+	pidFile := fs.String("pid-file", getEnv("AIRCAST_PID_FILE", defaultPIDFile()), "PID file of the background bridge")
+	socket := fs.String("control-socket", defaultControlSocket(), "Control socket path of the running bridge")
+	jsonOut := fs.Bool("json", false, "Print the status as JSON for scripts")
+	_ = fs.Parse(args)
+
+	var report statusReport
+	var status bridgeStatus
+	if err := control.Call(*socket, "status", nil, &status); err == nil {
+		report = statusReport{Running: true, PID: status.PID, Bridge: &status}
+	} else if errors.Is(err, control.ErrDenied) {
+		return err
+	} else {
+		pid, pidErr := runningPID(*pidFile)
+		if pidErr != nil && !errors.Is(pidErr, errNotRunning) {
+			return pidErr
+		}
+		if pidErr == nil {
+			report = statusReport{Running: true, PID: pid, Error: "control socket not answering: " + err.Error()}
+		}
+	}
+
+	if *jsonOut {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(report); err != nil {
+			return err
+		}
+	} else {
+		printStatus(report)
+	}
+	if !report.Running {
+		os.Exit(3)
+	}
+	return nil
+}
+
+// printStatus prints a status report for people
+func printStatus(report statusReport) {
+	if !report.Running {
+		fmt.Println("Not running")
+		return
+	}
+	if report.Bridge == nil {
+		fmt.Printf("%sRunning (PID %d)\n", term.Symbol("✓ ", ""), report.PID)
+		fmt.Printf("%s%s\n", term.Symbol("⚠ ", "Warning: "), report.Error)
+		return
+	}
+
+	s := report.Bridge
+	fmt.Printf("%sRunning (PID %d), up %s\n", term.Symbol("✓ ", ""), s.PID, time.Since(s.StartedAt).Round(time.Second))
+
+	device := s.Device.DeviceID
+	if s.Device.Name != "" {
+		device = fmt.Sprintf("%s (%s)", s.Device.Name, s.Device.DeviceID)
+	}
+	fmt.Printf("  Device:      %s\n", device)
+
+	connection := string(s.Connection)
+	if s.Link.RTT > 0 {
+		connection += fmt.Sprintf(", RTT %d ms", s.Link.RTT.Milliseconds())
+	}
+	if s.Link.Reconnects > 0 {
+		connection += fmt.Sprintf(", %d reconnects", s.Link.Reconnects)
+	}
+	if s.Link.Stalls > 0 {
+		connection += fmt.Sprintf(", %d stalls", s.Link.Stalls)
+	}
+	if s.Training != cli.TrainingOff {
+		connection += fmt.Sprintf(", simulated %s outage", s.Training)
+	}
+	fmt.Printf("  Connection:  %s\n", connection)
+
+	if s.TCPAddress != "" {
+		fmt.Printf("  TCP:         %s\n", s.TCPAddress)
+	}
+	if s.UDPAddress != "" {
+		fmt.Printf("  UDP:         %s\n", s.UDPAddress)
+	}
+	fmt.Printf("  Clients:     %d connected\n", s.Clients)
+	fmt.Printf("  Last data:   from device %s, from ground stations %s\n", statusAgo(orZero(s.LastDownlinkAt)), statusAgo(orZero(s.LastUplinkAt)))
+	fmt.Printf("  Telemetry:   %s\n", statusAgo(orZero(s.LastTelemetryAt)))
+	fmt.Printf("  Loss:        %.2f%% of downlink frames (%d lost)\n", s.DownlinkLossRate*100, s.DownlinkLost)
+	if s.ExpiresAt != nil {
+		fmt.Printf("  Expires:     %s (in %s)\n", s.ExpiresAt.Local().Format("15:04"), time.Until(*s.ExpiresAt).Round(time.Second))
+	}
+}
+
+// statusAgo formats how long ago something happened, or "never"
+func statusAgo(t time.Time) string {
+	if t.IsZero() {
+		return "never"
+	}
+	return time.Since(t).Round(time.Second).String() + " ago"
+}
+
+// orZero returns the time t points to, or the zero time for nil
+func orZero(t *time.Time) time.Time {
+	if t == nil {
+		return time.Time{}
+	}
+	return *t
+}
@@ -0,0 +1,183 @@
+package main
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/pavliha/aircast/aircast-cli/internal/api"
+	"github.com/pavliha/aircast/aircast-cli/internal/auth"
+	"github.com/pavliha/aircast/aircast-cli/internal/store"
+	"github.com/pavliha/aircast/aircast-cli/internal/term"
+)
+
+// runSupportBundle collects sanitized diagnostics into a zip for support tickets
+func runSupportBundle(args []string) error {
+	fs := flag.NewFlagSet("support-bundle", flag.ExitOnError)
+	apiURL := fs.String("api", defaultAPIURL(), "API base URL to run network checks against")
+	output := fs.String("o", "", "Output file (default aircast-support-<time>.zip)")
+	_ = fs.Parse(args)
+
+	if *output == "" {
+		*output = fmt.Sprintf("aircast-support-%s.zip", time.Now().Format("20060102-150405"))
+	}
+
+	configDir, err := store.ConfigDir()
+	if err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(*output, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to create bundle: %w", err)
+	}
+	defer f.Close()
+
+	zw := zip.NewWriter(f)
+	add := func(name, content string) error {
+		w, err := zw.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate, Modified: time.Now()})
+		if err != nil {
+			return err
+		}
+		_, err = w.Write([]byte(redactSecrets(content)))
+		return err
+	}
+
+	fmt.Println("Collecting support bundle...")
+
+	files := []struct {
+		name    string
+		content string
+	}{
+		{"version.txt", versionInfo()},
+		{"environment.txt", environmentInfo()},
+		{"network.txt", networkChecks(*apiURL)},
+		{"token.json", redactedToken(configDir)},
+		{"config.json", readConfigFile(configDir, "config.json")},
+		{"devices.json", readConfigFile(configDir, "devices.json")},
+		{"sessions.jsonl", readConfigFile(configDir, "sessions.jsonl")},
+		{sessionLogName, readConfigFile(configDir, sessionLogName)},
+	}
+	for _, file := range files {
+		if err := add(file.name, file.content); err != nil {
+			return fmt.Errorf("failed to write %s: %w", file.name, err)
+		}
+	}
+
+	if err := zw.Close(); err != nil {
+		return fmt.Errorf("failed to write bundle: %w", err)
+	}
+
+	fmt.Printf("%sSupport bundle written to %s\n", term.Symbol("✓ ", ""), *output)
+	fmt.Println("  Tokens and credentials have been redacted. Attach this file to your support ticket.")
+	return nil
+}
+
+// versionInfo describes the CLI build and platform
+func versionInfo() string {
+	return fmt.Sprintf("aircast-cli %s\ncommit: %s\nbuilt: %s\ngo: %s\nplatform: %s/%s\n",
+		version, commit, date, runtime.Version(), runtime.GOOS, runtime.GOARCH)
+}
+
+// environmentInfo lists the environment variables that affect the CLI
+func environmentInfo() string {
+	var b strings.Builder
+	for _, name := range []string{"AIRCAST_API_URL", "AIRCAST_TCP_LISTEN", "AIRCAST_UDP_LISTEN", "LOG_LEVEL",
+		"HTTP_PROXY", "HTTPS_PROXY", "NO_PROXY", "http_proxy", "https_proxy", "no_proxy"} {
+		if value, ok := os.LookupEnv(name); ok {
+			fmt.Fprintf(&b, "%s=%s\n", name, value)
+		}
+	}
+	if b.Len() == 0 {
+		return "(no relevant environment variables set)\n"
+	}
+	return b.String()
+}
+
+// readConfigFile returns a file from the config directory, or a note if unavailable
+func readConfigFile(configDir, name string) string {
+	data, err := os.ReadFile(filepath.Join(configDir, name))
+	if err != nil {
+		return fmt.Sprintf("(unavailable: %v)\n", err)
+	}
+	return string(data)
+}
+
+// redactedToken returns the stored token metadata with the secrets removed
+func redactedToken(configDir string) string {
+	data, err := os.ReadFile(filepath.Join(configDir, "token.json"))
+	if err != nil {
+		return fmt.Sprintf("(unavailable: %v)\n", err)
+	}
+
+	var token auth.StoredToken
+	if err := json.Unmarshal(data, &token); err != nil {
+		return fmt.Sprintf("(unparseable: %v)\n", err)
+	}
+	if token.AccessToken != "" {
+		token.AccessToken = "[REDACTED]"
+	}
+	if token.RefreshToken != "" {
+		token.RefreshToken = "[REDACTED]"
+	}
+
+	out, _ := json.MarshalIndent(token, "", "  ")
+	return string(out) + "\n"
+}
+
+// networkChecks tests DNS, TCP and HTTPS reachability of the API
+func networkChecks(apiURL string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "API: %s\n", apiURL)
+
+	u, err := url.Parse(apiURL)
+	if err != nil {
+		fmt.Fprintf(&b, "✗ invalid API URL: %v\n", err)
+		return b.String()
+	}
+
+	port := u.Port()
+	if port == "" {
+		port = "443"
+		if u.Scheme == "http" {
+			port = "80"
+		}
+	}
+
+	start := time.Now()
+	addrs, err := net.LookupHost(u.Hostname())
+	if err != nil {
+		fmt.Fprintf(&b, "✗ DNS lookup: %v\n", err)
+	} else {
+		fmt.Fprintf(&b, "✓ DNS lookup: %s (%s)\n", strings.Join(addrs, ", "), time.Since(start).Round(time.Millisecond))
+	}
+
+	start = time.Now()
+	conn, err := net.DialTimeout("tcp", net.JoinHostPort(u.Hostname(), port), 5*time.Second)
+	if err != nil {
+		fmt.Fprintf(&b, "✗ TCP connect: %v\n", err)
+	} else {
+		fmt.Fprintf(&b, "✓ TCP connect: %s (%s)\n", conn.RemoteAddr(), time.Since(start).Round(time.Millisecond))
+		_ = conn.Close()
+	}
+
+	start = time.Now()
+	serverTime, err := api.ServerTime(context.Background(), apiURL)
+	if err != nil {
+		fmt.Fprintf(&b, "✗ HTTP request: %v\n", err)
+	} else {
+		fmt.Fprintf(&b, "✓ HTTP request: %s\n", time.Since(start).Round(time.Millisecond))
+		fmt.Fprintf(&b, "  clock skew: %s\n", time.Until(serverTime).Round(time.Millisecond))
+	}
+
+	return b.String()
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"time"
+
+	"github.com/pavliha/aircast/aircast-cli/internal/mavlink"
+	"github.com/pavliha/aircast/aircast-cli/internal/store"
+	log "github.com/sirupsen/logrus"
+)
+
+// lastKnownTelemetry returns the telemetry last seen on each device, or
+// nothing if the cache can't be read
+func lastKnownTelemetry(logger *log.Entry) map[string]store.TelemetrySnapshot {
+	cache, err := store.NewTelemetryCache()
+	if err == nil {
+		var snapshots map[string]store.TelemetrySnapshot
+		if snapshots, err = cache.Load(); err == nil {
+			return snapshots
+		}
+	}
+	logger.WithError(err).Debug("Failed to load last known telemetry")
+	return nil
+}
+
+// rememberTelemetry stores a device's latest telemetry for the picker to
+// show while it is offline. Telemetry without a heartbeat isn't worth keeping.
+func rememberTelemetry(deviceID string, t *mavlink.Telemetry, at time.Time, logger *log.Entry) {
+	if !t.HaveHeartbeat || at.IsZero() {
+		return
+	}
+
+	snapshot := store.TelemetrySnapshot{
+		Battery:    -1,
+		Firmware:   t.Firmware(),
+		FlightMode: t.FlightMode(),
+		SeenAt:     at,
+	}
+	if t.HaveBattery {
+		snapshot.Battery = t.Battery
+	}
+
+	cache, err := store.NewTelemetryCache()
+	if err == nil {
+		err = cache.Save(deviceID, snapshot)
+	}
+	if err != nil {
+		logger.WithError(err).Warn("Failed to remember device telemetry")
+	}
+}
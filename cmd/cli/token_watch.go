@@ -0,0 +1,177 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/pavliha/aircast/aircast-cli/internal/api"
+	"github.com/pavliha/aircast/aircast-cli/internal/auth"
+	"github.com/pavliha/aircast/aircast-cli/internal/cli"
+	"github.com/pavliha/aircast/aircast-cli/internal/term"
+	"github.com/pavliha/aircast/aircast-cli/internal/ui"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	tokenCheckInterval = 30 * time.Second // How often the stored login is checked
+	tokenRefreshAhead  = 35 * time.Minute // Refresh this long before expiry, ahead of the first warning
+	tokenRefreshRetry  = time.Minute      // Wait after a failed refresh
+)
+
+// tokenWarnings are the remaining lifetimes at which the user is warned
+// that the login is about to expire, most urgent last
+var tokenWarnings = []time.Duration{30 * time.Minute, 5 * time.Minute, 0}
+
+// watchTokenExpiry keeps the login of the bridges and of the API clients
+// beside them fresh while they run. The WebSocket
+// only presents the token when it (re)connects, so an expired token goes
+// unnoticed until a reconnect is rejected mid-flight. Before that happens the
+// token is refreshed in the background; if it can't be, escalating warnings
+// tell the user to log in again. A login made in another terminal is picked
+// up from the token store.
+func watchTokenExpiry(ctx context.Context, apiURL, accessToken string, tokenStore *auth.TokenStore, logger *log.Entry, clients []*api.Client, bridges ...*cli.Bridge) {
+	logger = logger.WithField("component", "token")
+
+	current := accessToken
+	warned := 0
+	var retryAt time.Time
+	var rejected string // Refresh token the server refused
+	helper := credentialHelper()
+
+	check := func() {
+		token, err := tokenStore.LoadToken()
+		if err != nil || token == nil || token.APIURL != apiURL {
+			return
+		}
+
+		// Logged in again elsewhere: use the new token on the next reconnect
+		if token.AccessToken != current {
+			current = token.AccessToken
+			warned = 0
+			setToken(current, clients, bridges)
+			logger.Info("Using updated login for reconnects")
+		}
+
+		remaining := token.ExpiresAt.Sub(tokenStore.Now())
+
+		refreshable := helper != "" || token.RefreshToken != "" && token.RefreshToken != rejected
+		if remaining <= tokenRefreshAhead && refreshable && time.Now().After(retryAt) {
+			refreshed, err := refreshStoredToken(ctx, token, tokenStore)
+			if err == nil {
+				current = refreshed.AccessToken
+				warned = 0
+				setToken(current, clients, bridges)
+				logger.WithField("expires_at", refreshed.ExpiresAt.Format(time.RFC3339)).Info("Login refreshed")
+				return
+			}
+
+			var tokenErr *auth.TokenErrorResponse
+			if errors.As(err, &tokenErr) {
+				// Rejected refresh tokens won't start working again
+				rejected = token.RefreshToken
+				logger.WithError(err).Warn("Login can't be refreshed")
+			} else {
+				retryAt = time.Now().Add(tokenRefreshRetry)
+				logger.WithError(err).Debug("Login refresh failed, will retry")
+			}
+		}
+
+		for warned < len(tokenWarnings) && remaining <= tokenWarnings[warned] {
+			warned++
+			if warned < len(tokenWarnings) && remaining <= tokenWarnings[warned] {
+				continue // Skip to the most urgent warning that applies
+			}
+			printTokenWarning(remaining)
+			logger.WithField("remaining", remaining.Round(time.Second)).Warn("Login expires soon")
+		}
+	}
+
+	ticker := time.NewTicker(tokenCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		check()
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// refreshStoredToken exchanges the stored refresh token for a new login, or
+// asks the credential helper for a new token, and saves it. The token file
+// stays locked meanwhile: if another aircast-cli refreshed the login first,
+// its token is used rather than spending the refresh token a second time.
+func refreshStoredToken(ctx context.Context, token *auth.StoredToken, tokenStore *auth.TokenStore) (*auth.StoredToken, error) {
+	ctx, cancel := context.WithTimeout(ctx, 15*time.Second)
+	defer cancel()
+
+	helper := credentialHelper()
+	client, err := oauthClient(token.APIURL)
+	if err != nil {
+		return nil, err
+	}
+
+	return tokenStore.UpdateToken(func(stored *auth.StoredToken) (*auth.StoredToken, error) {
+		if stored != nil && stored.APIURL == token.APIURL && (stored.RefreshToken != token.RefreshToken || stored.AccessToken != token.AccessToken) {
+			return stored, nil // Refreshed or logged in again elsewhere
+		}
+
+		var resp *auth.TokenResponse
+		var err error
+		if helper != "" {
+			resp, err = runCredentialHelper(ctx, helper, token.APIURL, token.Scope, auth.HelperRefresh, tokenStore, log.WithFields(log.Fields{"app": "aircast-cli", "component": "token"}))
+		} else {
+			resp, err = auth.RefreshAccessToken(ctx, token.APIURL, client, token.RefreshToken)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		lifetime := defaultTokenLifetime
+		if resp.ExpiresIn > 0 {
+			lifetime = time.Duration(resp.ExpiresIn) * time.Second
+		}
+
+		refreshed := *token
+		refreshed.AccessToken = resp.AccessToken
+		refreshed.RefreshToken = resp.RefreshToken
+		refreshed.ExpiresAt = tokenStore.Now().Add(lifetime)
+		if resp.Scope != "" {
+			refreshed.Scope = resp.Scope
+		}
+		return &refreshed, nil
+	})
+}
+
+// setToken hands a new token to every API client and running bridge
+func setToken(token string, clients []*api.Client, bridges []*cli.Bridge) {
+	for _, c := range clients {
+		c.SetToken(token)
+	}
+	for _, b := range bridges {
+		if b != nil {
+			b.SetAuthToken(token)
+		}
+	}
+}
+
+// printTokenWarning tells the user the login is running out
+func printTokenWarning(remaining time.Duration) {
+	warning := term.Symbol("⚠ ", "Warning: ")
+	if remaining <= 0 {
+		fmt.Fprintf(ui.Output, "\n%sYour login has expired. The bridge keeps running, but can't reconnect if the link drops.\n", warning)
+	} else {
+		minutes := int(remaining.Round(time.Minute).Minutes())
+		when := fmt.Sprintf("%d minutes", minutes)
+		if minutes <= 1 {
+			when = "a minute"
+		}
+		fmt.Fprintf(ui.Output, "\n%sYour login expires in %s. After that the bridge can't reconnect if the link drops.\n", warning, when)
+	}
+	fmt.Fprintln(ui.Output, "  Run 'aircast-cli login' in another terminal; the bridge picks up the new login.")
+	fmt.Fprintln(ui.Output)
+}
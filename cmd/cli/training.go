@@ -0,0 +1,234 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/pavliha/aircast/aircast-cli/internal/cli"
+	"github.com/pavliha/aircast/aircast-cli/internal/control"
+	"github.com/pavliha/aircast/aircast-cli/internal/term"
+)
+
+// trainingCommands are the subcommands of "training"
+var trainingCommands = map[string]command{
+	"status": {"Show whether a simulated outage is running", runTrainingStatus},
+	"start":  {"Start a simulated outage (downlink or both) that ends by itself", runTrainingStart},
+	"stop":   {"End the simulated outage and resume forwarding", runTrainingStop},
+	"drill":  {"Cut and restore the link on a schedule or on Enter until interrupted", runTrainingDrill},
+}
+
+// runTraining dispatches "training" subcommands, showing the status without one
+func runTraining(args []string) error {
+	if len(args) == 0 || strings.HasPrefix(args[0], "-") {
+		return runTrainingStatus(args)
+	}
+
+	cmd, ok := trainingCommands[args[0]]
+	if !ok {
+		printSubcommands("training", trainingCommands)
+		return fmt.Errorf("unknown training command %q", args[0])
+	}
+	return cmd.run(args[1:])
+}
+
+// handleTraining registers the control commands that drive simulated outages
+func handleTraining(server *control.Server, b *cli.Bridge) {
+	server.Handle("training", control.PermRead, func(req control.Request) (interface{}, error) {
+		return b.Training(), nil
+	})
+
+	server.Handle("training-set", control.PermControl, func(req control.Request) (interface{}, error) {
+		outage, err := cli.ParseTrainingOutage(req.Args["outage"])
+		if err != nil {
+			return nil, err
+		}
+		var d time.Duration
+		if outage != cli.TrainingOff {
+			if d, err = time.ParseDuration(req.Args["duration"]); err != nil || d <= 0 {
+				return nil, fmt.Errorf("invalid duration %q", req.Args["duration"])
+			}
+		}
+		return b.SimulateOutage(outage, d), nil
+	})
+}
+
+// setTraining starts or ends a simulated outage on a running bridge
+func setTraining(socket string, outage cli.TrainingOutage, d time.Duration) (cli.TrainingState, error) {
+	var state cli.TrainingState
+	err := control.Call(socket, "training-set", map[string]string{"outage": outage.String(), "duration": d.String()}, &state)
+	return state, err
+}
+
+// printTraining prints a simulated outage state
+func printTraining(state cli.TrainingState) {
+	if state.Outage == cli.TrainingOff {
+		fmt.Println("No simulated outage, forwarding normally")
+		return
+	}
+	fmt.Printf("Simulated outage: %s, link restored in %s\n", state.Outage, time.Until(state.Until).Round(time.Second))
+}
+
+// runTrainingStatus prints the simulated outage of a running bridge
+func runTrainingStatus(args []string) error {
+	fs := flag.NewFlagSet("training status", flag.ExitOnError)
+	socket := fs.String("control-socket", defaultControlSocket(), "Control socket path of the running bridge")
+	_ = fs.Parse(args)
+
+	var state cli.TrainingState
+	if err := control.Call(*socket, "training", nil, &state); err != nil {
+		return err
+	}
+	printTraining(state)
+	return nil
+}
+
+// runTrainingStart starts a simulated outage on a running bridge
+func runTrainingStart(args []string) error {
+	fs := flag.NewFlagSet("training start", flag.ExitOnError)
+	socket := fs.String("control-socket", defaultControlSocket(), "Control socket path of the running bridge")
+	duration := fs.Duration("duration", time.Minute, "Restore the link after this long")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: aircast-cli training start [flags] [downlink|both]\n\n")
+		fmt.Fprintf(fs.Output(), "  downlink   Withhold telemetry from ground stations; their commands still reach the vehicle (default)\n")
+		fmt.Fprintf(fs.Output(), "  both       Drop traffic in both directions, except emergency commands\n\n")
+		fs.PrintDefaults()
+	}
+	positional := parseArgs(fs, args)
+	if len(positional) > 1 || *duration <= 0 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	outage := cli.TrainingDownlink
+	if len(positional) == 1 {
+		var err error
+		if outage, err = cli.ParseTrainingOutage(positional[0]); err != nil {
+			return err
+		}
+		if outage == cli.TrainingOff {
+			return fmt.Errorf("use 'aircast-cli training stop' to end an outage")
+		}
+	}
+
+	state, err := setTraining(*socket, outage, *duration)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("%sSimulated %s outage until %s\n", term.Symbol("✓ ", ""), state.Outage, state.Until.Local().Format("15:04:05"))
+	return nil
+}
+
+// runTrainingStop ends the simulated outage of a running bridge
+func runTrainingStop(args []string) error {
+	fs := flag.NewFlagSet("training stop", flag.ExitOnError)
+	socket := fs.String("control-socket", defaultControlSocket(), "Control socket path of the running bridge")
+	_ = fs.Parse(args)
+
+	if _, err := setTraining(*socket, cli.TrainingOff, 0); err != nil {
+		return err
+	}
+	fmt.Printf("%sLink restored\n", term.Symbol("✓ ", ""))
+	return nil
+}
+
+// runTrainingDrill repeatedly cuts and restores the link of a running bridge.
+// With --every the outages follow a schedule; otherwise Enter toggles them.
+// The link is restored when the drill ends, however it ends.
+func runTrainingDrill(args []string) error {
+	fs := flag.NewFlagSet("training drill", flag.ExitOnError)
+	socket := fs.String("control-socket", defaultControlSocket(), "Control socket path of the running bridge")
+	outageName := fs.String("outage", "downlink", "What to cut: downlink or both")
+	every := fs.Duration("every", 0, "Start an outage at this interval instead of on Enter")
+	duration := fs.Duration("duration", time.Minute, "How long each outage lasts (with Enter, the longest it can last)")
+	_ = fs.Parse(args)
+
+	outage, err := cli.ParseTrainingOutage(*outageName)
+	if err != nil {
+		return err
+	}
+	if outage == cli.TrainingOff || *duration <= 0 || (*every > 0 && *every <= *duration) {
+		return fmt.Errorf("need --outage downlink or both, and --every longer than --duration")
+	}
+
+	// Check the bridge is there before the operator starts waiting
+	var state cli.TrainingState
+	if err := control.Call(*socket, "training", nil, &state); err != nil {
+		return err
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+	defer func() {
+		if _, err := setTraining(*socket, cli.TrainingOff, 0); err == nil {
+			fmt.Printf("%sDrill over, link restored\n", term.Symbol("✓ ", ""))
+		}
+	}()
+
+	start := func() error {
+		state, err := setTraining(*socket, outage, *duration)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("%s  %sLink lost (%s) until %s\n", time.Now().Format("15:04:05"), term.Symbol("✗ ", ""), state.Outage, state.Until.Local().Format("15:04:05"))
+		return nil
+	}
+
+	if *every > 0 {
+		fmt.Printf("Drill: %s outage of %s every %s. Press Ctrl+C to end.\n", outage, *duration, *every)
+		ticker := time.NewTicker(*every)
+		defer ticker.Stop()
+		for {
+			if err := start(); err != nil {
+				return err
+			}
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-ticker.C:
+			}
+		}
+	}
+
+	fmt.Printf("Drill: press Enter to cut the link (%s), Enter again to restore it. Press Ctrl+C to end.\n", outage)
+	lines := make(chan struct{})
+	go func() {
+		scanner := bufio.NewScanner(os.Stdin)
+		for scanner.Scan() {
+			lines <- struct{}{}
+		}
+		close(lines)
+	}()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case _, ok := <-lines:
+			if !ok {
+				return nil
+			}
+		}
+
+		var current cli.TrainingState
+		if err := control.Call(*socket, "training", nil, &current); err != nil {
+			return err
+		}
+		if current.Outage != cli.TrainingOff {
+			if _, err := setTraining(*socket, cli.TrainingOff, 0); err != nil {
+				return err
+			}
+			fmt.Printf("%s  %sLink restored\n", time.Now().Format("15:04:05"), term.Symbol("✓ ", ""))
+			continue
+		}
+		if err := start(); err != nil {
+			return err
+		}
+	}
+}
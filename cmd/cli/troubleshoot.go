@@ -0,0 +1,121 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/pavliha/aircast/aircast-cli/internal/api"
+	"github.com/pavliha/aircast/aircast-cli/internal/cli"
+	"github.com/pavliha/aircast/aircast-cli/internal/term"
+	"github.com/pavliha/aircast/aircast-cli/internal/ui"
+	log "github.com/sirupsen/logrus"
+)
+
+// troubleshooter opens the troubleshooting screen when the circuit breaker
+// opens because the device's MAVLink proxy isn't answering
+type troubleshooter struct {
+	ctx      context.Context
+	stop     context.CancelFunc // Stops the bridge
+	client   *api.Client
+	deviceID string
+	device   string
+	logger   *log.Entry
+
+	mu        sync.Mutex
+	bridge    *cli.Bridge
+	connected chan struct{} // Open while the screen is showing
+	hidden    bool          // The user hid the screen for this outage
+	retryAt   time.Time     // When the bridge reconnects next
+}
+
+// setBridge sets the bridge to retry from the screen, once it is created
+func (t *troubleshooter) setBridge(b *cli.Bridge) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.bridge = b
+}
+
+// onCircuit is the bridge's OnCircuit hook; it must not block
+func (t *troubleshooter) onCircuit(open bool, retryAt time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if !open {
+		t.hidden = false
+		if t.connected != nil {
+			// The screen prints the notice once it has closed
+			close(t.connected)
+			t.connected = nil
+			return
+		}
+		printConnected()
+		return
+	}
+	t.retryAt = retryAt
+	if t.connected != nil || t.hidden || t.bridge == nil {
+		return
+	}
+
+	t.connected = make(chan struct{})
+	go t.show(t.connected, t.bridge)
+}
+
+// nextRetry returns when the bridge reconnects next
+func (t *troubleshooter) nextRetry() time.Time {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.retryAt
+}
+
+// retryNow reconnects without waiting out the circuit breaker
+func (t *troubleshooter) retryNow(b *cli.Bridge) {
+	t.mu.Lock()
+	t.retryAt = time.Now()
+	t.mu.Unlock()
+	b.RetryNow()
+}
+
+// show runs the troubleshooting screen until data flows or the user leaves it
+func (t *troubleshooter) show(connected chan struct{}, b *cli.Bridge) {
+	result, err := ui.Troubleshoot(t.ctx, ui.Troubleshooter{
+		Device:    t.device,
+		NextRetry: t.nextRetry,
+		Status: func(ctx context.Context) (*api.AgentStatus, error) {
+			return t.client.GetAgentStatus(ctx, t.deviceID)
+		},
+		Restart: func(ctx context.Context) error {
+			return t.client.RestartAgent(ctx, t.deviceID)
+		},
+		Logs: func(ctx context.Context, lines int) ([]string, error) {
+			return t.client.GetAgentLogs(ctx, t.deviceID, lines)
+		},
+		RetryNow: func() { t.retryNow(b) },
+	}, connected)
+	if err != nil {
+		t.logger.WithError(err).Warn("Troubleshooting screen failed")
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.connected == connected {
+		// Closed by the user rather than by the connection coming back
+		t.connected = nil
+		t.hidden = true
+	}
+
+	switch result {
+	case ui.TroubleshootConnected:
+		printConnected()
+	case ui.TroubleshootQuit:
+		t.stop()
+	case ui.TroubleshootDismissed:
+		fmt.Fprintf(ui.Output, "\n%sDevice MAVLink proxy is not running. Still retrying in the background...\n\n", term.Symbol("⏸️  ", ""))
+	}
+}
+
+// printConnected announces that data flows again after an outage
+func printConnected() {
+	fmt.Fprintf(ui.Output, "\n%sConnected! MAVLink data is flowing.\n\n", term.Symbol("✅ ", ""))
+}
@@ -0,0 +1,235 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/gorilla/websocket"
+	"github.com/pavliha/aircast/aircast-cli/internal/cli"
+	"github.com/pavliha/aircast/aircast-cli/internal/mavlink"
+	"github.com/pavliha/aircast/aircast-cli/internal/term"
+	log "github.com/sirupsen/logrus"
+)
+
+// HEARTBEAT fields for the simulated vehicle
+const (
+	verifySysID         = 1
+	verifyCompID        = 1
+	mavTypeQuadrotor    = 2 // MAV_TYPE_QUADROTOR
+	mavAutopilotGeneric = 0 // MAV_AUTOPILOT_GENERIC
+	mavStateStandby     = 3 // MAV_STATE_STANDBY
+)
+
+var (
+	verifyPassStyle = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("10"))
+	verifyFailStyle = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("9"))
+)
+
+// gcsFrame is a frame a ground station sent to the simulated vehicle
+type gcsFrame struct {
+	sysID, compID uint8
+	msgID         uint32
+}
+
+// verifyCheck is one step of the ground station check
+type verifyCheck struct {
+	name   string
+	hint   string // Printed when the check fails
+	detail string
+	passed bool
+}
+
+// runVerifyGCS runs the bridge against a simulated vehicle and checks that
+// a ground station exchanges MAVLink with it in both directions
+func runVerifyGCS(args []string) error {
+	fs := flag.NewFlagSet("verify-gcs", flag.ExitOnError)
+	tcpListen := fs.String("tcp", getEnv("AIRCAST_TCP_LISTEN", "127.0.0.1:5169"), "TCP listen address for the ground station")
+	udpListen := fs.String("udp", getEnv("AIRCAST_UDP_LISTEN", ""), "UDP listen address for the ground station (optional)")
+	timeout := fs.Duration("timeout", 2*time.Minute, "Give up if the checks haven't passed within this time")
+	_ = fs.Parse(args)
+
+	frames := make(chan gcsFrame, 64)
+	server, wsURL, err := startVehicleServer(frames)
+	if err != nil {
+		return err
+	}
+	defer server.Close()
+
+	logger := log.New()
+	logger.SetLevel(log.WarnLevel)
+
+	b, err := cli.New(&cli.Config{
+		WebSocketURL: wsURL,
+		TCPAddress:   *tcpListen,
+		UDPAddress:   *udpListen,
+		Logger:       logger.WithField("component", "verify-gcs"),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create bridge: %w", err)
+	}
+	if err := b.Start(context.Background()); err != nil {
+		return fmt.Errorf("failed to start bridge: %w", err)
+	}
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), cli.DefaultShutdownTimeout)
+		defer cancel()
+		_ = b.Shutdown(ctx)
+	}()
+
+	fmt.Println(term.Banner("Ground Station Check"))
+	fmt.Println()
+	fmt.Printf("Simulating a vehicle (system %d) on:\n", verifySysID)
+	fmt.Printf("  tcp://%s\n", b.TCPAddr())
+	if *udpListen != "" {
+		fmt.Printf("  udp://%s\n", *udpListen)
+	}
+	fmt.Println()
+	fmt.Println("Connect your ground station to one of these now...")
+	fmt.Println()
+
+	checks := []*verifyCheck{
+		{
+			name: "Ground station connected",
+			hint: "Check the link's address and port, and that no firewall blocks it.",
+		},
+		{
+			name: "Heartbeats received from the ground station",
+			hint: "Make sure the ground station sends heartbeats on this link (QGroundControl: Application Settings > MAVLink > Emit heartbeat).",
+		},
+		{
+			name: "Vehicle heartbeats delivered to the ground station",
+			hint: "For UDP, the ground station must send first so the bridge learns its address.",
+		},
+		{
+			name: "Ground station is talking to the vehicle",
+			hint: "The ground station hasn't requested anything from the vehicle, so it is likely ignoring its heartbeats. Check its MAVLink version and system ID filters.",
+		},
+	}
+	connected, gcsHeartbeat, delivered, answered := checks[0], checks[1], checks[2], checks[3]
+	pass := func(c *verifyCheck, detail string) {
+		if c.passed {
+			return
+		}
+		c.passed, c.detail = true, detail
+		line := term.Symbol("✓ ", "OK: ") + c.name
+		if detail != "" {
+			line += " (" + detail + ")"
+		}
+		fmt.Println(verifyPassStyle.Render(line))
+	}
+
+	deadline := time.After(*timeout)
+	ticker := time.NewTicker(200 * time.Millisecond)
+	defer ticker.Stop()
+
+	for !(connected.passed && gcsHeartbeat.passed && delivered.passed && answered.passed) {
+		select {
+		case f := <-frames:
+			if f.msgID == mavlink.MsgIDHeartbeat {
+				pass(gcsHeartbeat, fmt.Sprintf("system %d, component %d", f.sysID, f.compID))
+			} else {
+				pass(answered, mavlink.MessageName(f.msgID))
+			}
+		case <-ticker.C:
+			for _, c := range b.Clients() {
+				pass(connected, c.ID)
+				if c.BytesOut > 0 {
+					pass(delivered, "")
+				}
+			}
+		case <-deadline:
+			return verifyVerdict(checks)
+		}
+	}
+
+	return verifyVerdict(checks)
+}
+
+// verifyVerdict prints the outcome of the checks, with a hint for each that
+// failed, and returns an error unless they all passed
+func verifyVerdict(checks []*verifyCheck) error {
+	fmt.Println()
+	failed := 0
+	for _, c := range checks {
+		if c.passed {
+			continue
+		}
+		failed++
+		fmt.Println(verifyFailStyle.Render(term.Symbol("✗ ", "FAILED: ") + c.name))
+		fmt.Printf("  %s\n", c.hint)
+	}
+	if failed > 0 {
+		fmt.Println()
+		fmt.Println(verifyFailStyle.Render(fmt.Sprintf("FAIL: %d of %d checks failed", failed, len(checks))))
+		return fmt.Errorf("ground station check failed")
+	}
+
+	fmt.Println(verifyPassStyle.Render("PASS: MAVLink flows both ways between the ground station and the bridge"))
+	return nil
+}
+
+// startVehicleServer starts a local WebSocket server that plays a vehicle:
+// it sends a HEARTBEAT every second and reports the frames it receives on
+// frames. It returns the server and its WebSocket URL.
+func startVehicleServer(frames chan<- gcsFrame) (*http.Server, string, error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to start simulated vehicle: %w", err)
+	}
+
+	upgrader := websocket.Upgrader{}
+	server := &http.Server{
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			conn, err := upgrader.Upgrade(w, r, nil)
+			if err != nil {
+				return
+			}
+			defer conn.Close()
+
+			go func() {
+				parser := mavlink.NewParser()
+				for {
+					_, data, err := conn.ReadMessage()
+					if err != nil {
+						return
+					}
+					for _, f := range parser.Feed(data) {
+						select {
+						case frames <- gcsFrame{sysID: f.SysID, compID: f.CompID, msgID: f.MsgID}:
+						default:
+						}
+					}
+				}
+			}()
+
+			payload := make([]byte, 9) // custom_mode (uint32) and base_mode stay 0
+			payload[4] = mavTypeQuadrotor
+			payload[5] = mavAutopilotGeneric
+			payload[7] = mavStateStandby
+			payload[8] = 3 // MAVLink version
+
+			ticker := time.NewTicker(time.Second)
+			defer ticker.Stop()
+			for seq := uint8(0); ; seq++ {
+				frame, _ := mavlink.EncodeV2(seq, verifySysID, verifyCompID, mavlink.MsgIDHeartbeat, payload)
+				if err := conn.WriteMessage(websocket.BinaryMessage, frame); err != nil {
+					return
+				}
+				select {
+				case <-ticker.C:
+				case <-r.Context().Done():
+					return
+				}
+			}
+		}),
+	}
+
+	go func() { _ = server.Serve(listener) }()
+
+	return server, fmt.Sprintf("ws://%s/vehicle", listener.Addr()), nil
+}
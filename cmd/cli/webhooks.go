@@ -0,0 +1,90 @@
+package main
+
+import (
+	"sync"
+	"time"
+
+	"github.com/pavliha/aircast/aircast-cli/internal/cli"
+	"github.com/pavliha/aircast/aircast-cli/internal/webhook"
+)
+
+// sessionStartedData describes a session.started event
+type sessionStartedData struct {
+	Version string `json:"cli_version"`
+	TCP     string `json:"tcp"`
+	UDP     string `json:"udp,omitempty"`
+}
+
+// sessionStoppedData describes a session.stopped event
+type sessionStoppedData struct {
+	Duration       float64 `json:"duration_seconds"`
+	ReceivedData   bool    `json:"received_data"`
+	Verdict        string  `json:"verdict"`
+	UplinkBytes    uint64  `json:"uplink_bytes"`
+	DownlinkBytes  uint64  `json:"downlink_bytes"`
+	DownlinkFrames uint64  `json:"downlink_frames"`
+	DownlinkLost   uint64  `json:"downlink_lost"`
+	Reconnects     uint64  `json:"reconnects"`
+}
+
+// linkData describes link.lost and link.restored events
+type linkData struct {
+	Error    string  `json:"error,omitempty"`
+	Downtime float64 `json:"downtime_seconds,omitempty"` // How long the link was down, on link.restored
+	Silence  float64 `json:"silence_seconds,omitempty"`  // How long no data arrived, on link.stalled
+}
+
+// alarmData describes alarm.raised and alarm.cleared events
+type alarmData struct {
+	Rule   string  `json:"rule"`
+	Metric string  `json:"metric"`
+	Value  float64 `json:"value"`
+}
+
+// webhookLinkHandler returns the bridge's link callback, reporting drops
+// and how long each lasted
+func webhookLinkHandler(n *webhook.Notifier) func(bool, error) {
+	var mu sync.Mutex
+	var lostAt time.Time
+	return func(up bool, err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		if !up {
+			lostAt = time.Now()
+			n.Notify(webhook.LinkLost, linkData{Error: err.Error()})
+			return
+		}
+		n.Notify(webhook.LinkRestored, linkData{Downtime: time.Since(lostAt).Seconds()})
+	}
+}
+
+// webhookAlarmHandler wraps the bridge's alarm callback to also notify webhooks
+func webhookAlarmHandler(n *webhook.Notifier, next func(cli.AlarmEvent)) func(cli.AlarmEvent) {
+	return func(event cli.AlarmEvent) {
+		next(event)
+		name := webhook.AlarmCleared
+		if event.Raised {
+			name = webhook.AlarmRaised
+		}
+		n.Notify(name, alarmData{Rule: event.Rule.String(), Metric: event.Rule.Metric, Value: event.Value})
+	}
+}
+
+// notifySessionStopped reports the session's totals to webhooks
+func notifySessionStopped(n *webhook.Notifier, s cli.StatsSnapshot, link cli.LinkStats, d cli.Diagnostics) {
+	n.Notify(webhook.SessionStopped, newSessionStoppedData(s, link, d))
+}
+
+// newSessionStoppedData summarizes a finished session
+func newSessionStoppedData(s cli.StatsSnapshot, link cli.LinkStats, d cli.Diagnostics) sessionStoppedData {
+	return sessionStoppedData{
+		Duration:       time.Since(d.StartedAt).Seconds(),
+		ReceivedData:   d.ReceivedData(),
+		Verdict:        d.Verdict(),
+		UplinkBytes:    s.Uplink.Bytes,
+		DownlinkBytes:  s.Downlink.Bytes,
+		DownlinkFrames: s.Downlink.Frames,
+		DownlinkLost:   s.Downlink.Lost,
+		Reconnects:     link.Reconnects,
+	}
+}
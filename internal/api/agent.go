@@ -0,0 +1,90 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// ErrAgentUnsupported is returned when the API or the device's agent can't
+// report on or control the agent remotely
+var ErrAgentUnsupported = errors.New("remote agent management is not available for this device")
+
+// AgentStatus is what the API knows about the aircast-agent on a device
+type AgentStatus struct {
+	Online       bool      `json:"online"` // The agent is connected to the API
+	Version      string    `json:"version,omitempty"`
+	ProxyRunning bool      `json:"proxy_running"` // The MAVLink proxy is attached to the autopilot
+	ProxyError   string    `json:"proxy_error,omitempty"`
+	StartedAt    time.Time `json:"started_at,omitempty"`
+	CanRestart   bool      `json:"can_restart"`
+}
+
+// agentPath returns the path of a device's agent endpoint
+func agentPath(deviceID, suffix string) string {
+	return "/v1/user/devices/" + url.PathEscape(deviceID) + "/agent" + suffix
+}
+
+// GetAgentStatus fetches the status of the agent on a device
+func (c *Client) GetAgentStatus(ctx context.Context, deviceID string) (*AgentStatus, error) {
+	resp, err := c.do(ctx, "GET", agentPath(deviceID, ""), nil, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get agent status: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusNotImplemented {
+		return nil, ErrAgentUnsupported
+	}
+	if err := checkResponse(resp); err != nil {
+		return nil, err
+	}
+
+	var status AgentStatus
+	if err := json.NewDecoder(resp.Body).Decode(&status); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	return &status, nil
+}
+
+// RestartAgent asks the agent on a device to restart its MAVLink proxy
+func (c *Client) RestartAgent(ctx context.Context, deviceID string) error {
+	resp, err := c.do(ctx, "POST", agentPath(deviceID, "/restart"), nil, nil)
+	if err != nil {
+		return fmt.Errorf("failed to restart agent: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusNotImplemented {
+		return ErrAgentUnsupported
+	}
+	return checkResponse(resp)
+}
+
+// GetAgentLogs fetches up to lines of the most recent agent log lines
+func (c *Client) GetAgentLogs(ctx context.Context, deviceID string, lines int) ([]string, error) {
+	resp, err := c.do(ctx, "GET", agentPath(deviceID, fmt.Sprintf("/logs?lines=%d", lines)), nil, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get agent logs: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusNotImplemented {
+		return nil, ErrAgentUnsupported
+	}
+	if err := checkResponse(resp); err != nil {
+		return nil, err
+	}
+
+	var result struct {
+		Lines []string `json:"lines"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	return result.Lines, nil
+}
@@ -6,40 +6,43 @@ import (
 	"fmt"
 	"io"
 	"net/http"
-	"time"
-)
-
-// AuthError represents an authentication error (401)
-type AuthError struct {
-	StatusCode int
-	Message    string
-}
-
-func (e *AuthError) Error() string {
-	return fmt.Sprintf("authentication failed (status %d): %s", e.StatusCode, e.Message)
-}
+	"net/url"
+	"sync"
 
-// IsAuthError checks if an error is an AuthError
-func IsAuthError(err error) bool {
-	_, ok := err.(*AuthError)
-	return ok
-}
+	log "github.com/sirupsen/logrus"
+)
 
 // Client handles API communication
 type Client struct {
 	baseURL    string
 	httpClient *http.Client
-	token      string
+
+	mu       sync.Mutex
+	token    string
+	tokenSet chan struct{} // Closed by the next SetToken
+
+	// Retry controls per-call timeouts and retries, DefaultRetryPolicy by default
+	Retry RetryPolicy
+
+	// DryRun, if set, receives requests that would change the account
+	// instead of them being sent, and those calls fail with ErrDryRun.
+	// Reads are still sent, so a plan can be built from current data.
+	DryRun func(PlannedRequest)
+
+	// OnDevices, if set, receives every device list fetched, so callers can
+	// keep a local index of device names up to date
+	OnDevices func([]Device)
 }
 
 // Device represents a device from the API
 type Device struct {
-	ID           string `json:"id"`
-	Name         string `json:"name"`
-	LastSeenAt   string `json:"last_seen_at"`
-	RegisteredAt string `json:"registered_at"`
-	Role         string `json:"role"`
-	IsOnline     bool   `json:"-"` // Populated from status endpoint
+	ID           string   `json:"id"`
+	Name         string   `json:"name"`
+	LastSeenAt   string   `json:"last_seen_at"`
+	RegisteredAt string   `json:"registered_at"`
+	Role         string   `json:"role"`
+	Tags         []string `json:"tags,omitempty"` // Groups set in the dashboard or with "devices tag"
+	IsOnline     bool     `json:"-"`              // Populated from status endpoint
 }
 
 // DeviceStatus represents device online status
@@ -60,103 +63,225 @@ type DeviceStatusResponse struct {
 
 // NewClient creates a new API client
 func NewClient(baseURL, token string) *Client {
+	// Timeouts are applied per attempt by the retry policy
 	return &Client{
-		baseURL: baseURL,
-		httpClient: &http.Client{
-			Timeout: 10 * time.Second,
-		},
-		token: token,
+		baseURL:    baseURL,
+		httpClient: &http.Client{},
+		token:      token,
+		Retry:      DefaultRetryPolicy,
 	}
 }
 
-// GetDevices fetches the list of devices with their online status
-func (c *Client) GetDevices(ctx context.Context) ([]Device, error) {
-	// Fetch devices list
-	devicesURL := fmt.Sprintf("%s/v1/user/devices", c.baseURL)
-	req, err := http.NewRequestWithContext(ctx, "GET", devicesURL, nil)
+// Token returns the access token the client authenticates with
+func (c *Client) Token() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.token
+}
+
+// SetToken switches the client to a new access token, such as a refreshed
+// login, for the requests it sends from now on
+func (c *Client) SetToken(token string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.token = token
+	if c.tokenSet != nil {
+		close(c.tokenSet)
+		c.tokenSet = nil
+	}
+}
+
+// TokenChanged returns a channel that is closed the next time SetToken is
+// called, so a caller whose login was rejected can wait for a new one
+func (c *Client) TokenChanged() <-chan struct{} {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.tokenSet == nil {
+		c.tokenSet = make(chan struct{})
+	}
+	return c.tokenSet
+}
+
+// newRequest creates an authenticated API request for a path such as "/v1/user/devices"
+func (c *Client) newRequest(ctx context.Context, method, path string, body io.Reader) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, body)
 	if err != nil {
 		return nil, err
 	}
 
 	// Add authentication - try both cookie and header
+	token := c.Token()
 	req.AddCookie(&http.Cookie{
 		Name:  "session",
-		Value: c.token,
+		Value: token,
 	})
-	req.Header.Set("Authorization", "Bearer "+c.token)
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	return req, nil
+}
 
-	resp, err := c.httpClient.Do(req)
+// DeleteDevice unregisters a device from the account
+func (c *Client) DeleteDevice(ctx context.Context, deviceID string) error {
+	resp, err := c.do(ctx, "DELETE", "/v1/user/devices/"+url.PathEscape(deviceID), nil, nil)
 	if err != nil {
-		return nil, fmt.Errorf("failed to fetch devices: %w", err)
+		return fmt.Errorf("failed to delete device: %w", err)
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		if resp.StatusCode == http.StatusUnauthorized {
-			return nil, &AuthError{
-				StatusCode: resp.StatusCode,
-				Message:    string(body),
-			}
-		}
-		return nil, fmt.Errorf("API error (status %d): %s", resp.StatusCode, string(body))
+	if resp.StatusCode == http.StatusNotFound {
+		return fmt.Errorf("device %s not found", deviceID)
+	}
+
+	return checkResponse(resp)
+}
+
+// DeviceList is a device list and the ETag to revalidate it with
+type DeviceList struct {
+	Devices     []Device
+	ETag        string
+	NotModified bool // The previously fetched list was still current
+}
+
+// GetDevices fetches the list of devices with their online status
+func (c *Client) GetDevices(ctx context.Context) ([]Device, error) {
+	list, err := c.GetDevicesCached(ctx, "", nil)
+	if err != nil {
+		return nil, err
 	}
+	return list.Devices, nil
+}
 
-	var devices []Device
-	if err := json.NewDecoder(resp.Body).Decode(&devices); err != nil {
-		return nil, fmt.Errorf("failed to parse devices response: %w", err)
+// GetDevicesCached fetches the device list, revalidating a previously
+// fetched list with its ETag so an unchanged list isn't downloaded again.
+// Online status is always refreshed.
+func (c *Client) GetDevicesCached(ctx context.Context, etag string, cached []Device) (*DeviceList, error) {
+	list, err := c.getDevices(ctx, etag, cached)
+	if err == nil && c.OnDevices != nil {
+		c.OnDevices(list.Devices)
 	}
+	return list, err
+}
 
-	// Fetch status for all devices
-	statusURL := fmt.Sprintf("%s/v1/user/devices/status", c.baseURL)
-	statusReq, err := http.NewRequestWithContext(ctx, "GET", statusURL, nil)
+// getDevices fetches the device list and the online status of each device
+func (c *Client) getDevices(ctx context.Context, etag string, cached []Device) (*DeviceList, error) {
+	var header http.Header
+	if etag != "" && cached != nil {
+		header = http.Header{"If-None-Match": {etag}}
+	}
+
+	resp, err := c.do(ctx, "GET", "/v1/user/devices", nil, header)
 	if err != nil {
-		fmt.Printf("Debug: Failed to create status request: %v\n", err)
-		return devices, nil // Return devices without status if status fetch fails
+		return nil, fmt.Errorf("failed to fetch devices: %w", err)
 	}
+	defer resp.Body.Close()
 
-	statusReq.AddCookie(&http.Cookie{
-		Name:  "session",
-		Value: c.token,
-	})
-	statusReq.Header.Set("Authorization", "Bearer "+c.token)
+	list := &DeviceList{ETag: resp.Header.Get("ETag")}
+	if resp.StatusCode == http.StatusNotModified {
+		log.WithField("etag", etag).Trace("Device list not modified")
+		list.NotModified = true
+		list.ETag = etag
+		list.Devices = append([]Device(nil), cached...)
+		for i := range list.Devices {
+			list.Devices[i].IsOnline = false
+		}
+	} else {
+		if err := checkResponse(resp); err != nil {
+			return nil, err
+		}
+		if err := json.NewDecoder(resp.Body).Decode(&list.Devices); err != nil {
+			return nil, fmt.Errorf("failed to parse devices response: %w", err)
+		}
+	}
+	devices := list.Devices
 
-	statusResp, err := c.httpClient.Do(statusReq)
+	// Fetch status for all devices
+	statusResp, err := c.do(ctx, "GET", "/v1/user/devices/status", nil, nil)
 	if err != nil {
-		fmt.Printf("Debug: Failed to fetch status: %v\n", err)
-		return devices, nil // Return devices without status
+		log.WithError(err).Trace("Failed to fetch device status")
+		return list, nil // Return devices without status
 	}
 	defer statusResp.Body.Close()
 
-	fmt.Printf("Debug: Status response code: %d\n", statusResp.StatusCode)
-
-	if statusResp.StatusCode == http.StatusOK {
-		body, _ := io.ReadAll(statusResp.Body)
-		fmt.Printf("Debug: Status response body: %s\n", string(body))
-
-		var statusResponse DeviceStatusResponse
-		if err := json.Unmarshal(body, &statusResponse); err == nil {
-			fmt.Printf("Debug: Parsed %d statuses (total: %d, online: %d)\n",
-				len(statusResponse.Devices), statusResponse.Summary.Total, statusResponse.Summary.Online)
-
-			// Create a map for quick lookup
-			statusMap := make(map[string]bool)
-			for _, s := range statusResponse.Devices {
-				fmt.Printf("Debug: Device %s is online: %v\n", s.DeviceID, s.IsOnline)
-				statusMap[s.DeviceID] = s.IsOnline
-			}
-
-			// Update devices with status
-			for i := range devices {
-				if online, ok := statusMap[devices[i].ID]; ok {
-					fmt.Printf("Debug: Setting device %s online status to: %v\n", devices[i].ID, online)
-					devices[i].IsOnline = online
-				}
-			}
-		} else {
-			fmt.Printf("Debug: Failed to parse status: %v\n", err)
+	if statusResp.StatusCode != http.StatusOK {
+		log.WithField("status", statusResp.StatusCode).Trace("Device status unavailable")
+		return list, nil
+	}
+
+	var statusResponse DeviceStatusResponse
+	if err := json.NewDecoder(statusResp.Body).Decode(&statusResponse); err != nil {
+		log.WithError(err).Trace("Failed to parse device status")
+		return list, nil
+	}
+	log.WithFields(log.Fields{
+		"statuses": len(statusResponse.Devices),
+		"total":    statusResponse.Summary.Total,
+		"online":   statusResponse.Summary.Online,
+	}).Trace("Fetched device status")
+
+	// Create a map for quick lookup
+	statusMap := make(map[string]bool)
+	for _, s := range statusResponse.Devices {
+		statusMap[s.DeviceID] = s.IsOnline
+	}
+
+	// Update devices with status
+	for i := range devices {
+		if online, ok := statusMap[devices[i].ID]; ok {
+			devices[i].IsOnline = online
 		}
+		log.WithFields(log.Fields{
+			"device_id": devices[i].ID,
+			"online":    devices[i].IsOnline,
+		}).Trace("Device status")
+	}
+
+	return list, nil
+}
+
+// UploadDiagnostics sends a connection diagnostics report to Aircast support
+// and returns the reference ID to quote in a support ticket
+func (c *Client) UploadDiagnostics(ctx context.Context, report interface{}) (string, error) {
+	body, err := json.Marshal(report)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode diagnostics: %w", err)
+	}
+
+	resp, err := c.do(ctx, "POST", "/v1/support/diagnostics", body, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to upload diagnostics: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if err := checkResponse(resp); err != nil {
+		return "", err
+	}
+
+	var result struct {
+		ID string `json:"id"`
 	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return result.ID, nil
+}
+
+// PushLinkMetrics reports ground-side link quality metrics for the fleet dashboard
+func (c *Client) PushLinkMetrics(ctx context.Context, metrics interface{}) error {
+	body, err := json.Marshal(metrics)
+	if err != nil {
+		return fmt.Errorf("failed to encode metrics: %w", err)
+	}
+
+	resp, err := c.do(ctx, "POST", "/v1/telemetry/link-metrics", body, nil)
+	if err != nil {
+		return fmt.Errorf("failed to push metrics: %w", err)
+	}
+	defer resp.Body.Close()
 
-	return devices, nil
+	return checkResponse(resp)
 }
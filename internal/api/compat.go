@@ -0,0 +1,155 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrCompatUnsupported is returned when the API has no compatibility endpoint
+var ErrCompatUnsupported = errors.New("API does not offer a compatibility check")
+
+// UsedEndpoints lists the API endpoints the CLI calls, in the notation the
+// compatibility endpoint uses for deprecations ({id} for path parameters)
+var UsedEndpoints = []string{
+	"/v1/user/devices",
+	"/v1/user/devices/{id}",
+	"/v1/user/devices/{id}/shares",
+	"/v1/user/devices/{id}/agent",
+	"/v1/user/devices/{id}/agent/restart",
+	"/v1/user/devices/{id}/agent/logs",
+	"/v1/user/devices/status",
+	"/v1/user/events",
+	"/v1/oauth2/cli/code",
+	"/v1/oauth2/cli/token",
+	"/v1/oauth2/cli/revoke",
+	"/v1/oauth2/cli/authorize",
+	"/v1/oauth2/user/sessions/me",
+	"/v1/mavlink/web/{id}/ws",
+	"/v1/mavlink/web/{id}/echo",
+	"/v1/companion/web/{id}/ws",
+	"/v1/support/diagnostics",
+	"/v1/telemetry/link-metrics",
+}
+
+// DeprecatedEndpoint is an API endpoint scheduled for removal
+type DeprecatedEndpoint struct {
+	Path        string `json:"path"`
+	Sunset      string `json:"sunset,omitempty"`      // Date after which it may be removed
+	Replacement string `json:"replacement,omitempty"` // Endpoint to use instead
+}
+
+// Compatibility describes which CLI versions the API supports
+type Compatibility struct {
+	APIVersion       string               `json:"api_version"`
+	MinCLIVersion    string               `json:"min_cli_version,omitempty"`
+	LatestCLIVersion string               `json:"latest_cli_version,omitempty"`
+	Deprecated       []DeprecatedEndpoint `json:"deprecated,omitempty"`
+}
+
+// CheckCompatibility asks the API which CLI versions it supports. It does
+// not need a login, so it can run before authentication.
+func CheckCompatibility(ctx context.Context, baseURL, cliVersion string) (*Compatibility, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, "GET", baseURL+"/v1/cli/compat?version="+url.QueryEscape(cliVersion), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reach API: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusNotImplemented {
+		return nil, ErrCompatUnsupported
+	}
+	if err := checkResponse(resp); err != nil {
+		return nil, err
+	}
+
+	var compat Compatibility
+	if err := json.NewDecoder(resp.Body).Decode(&compat); err != nil {
+		return nil, fmt.Errorf("failed to parse compatibility response: %w", err)
+	}
+	return &compat, nil
+}
+
+// TooOld reports whether cliVersion is below the minimum the API supports.
+// Development builds are never too old.
+func (c *Compatibility) TooOld(cliVersion string) bool {
+	return c.MinCLIVersion != "" && olderVersion(cliVersion, c.MinCLIVersion)
+}
+
+// UpdateAvailable reports whether a newer CLI release than cliVersion exists
+func (c *Compatibility) UpdateAvailable(cliVersion string) bool {
+	return c.LatestCLIVersion != "" && olderVersion(cliVersion, c.LatestCLIVersion)
+}
+
+// DeprecatedInUse returns the deprecated endpoints the CLI calls
+func (c *Compatibility) DeprecatedInUse() []DeprecatedEndpoint {
+	var inUse []DeprecatedEndpoint
+	for _, d := range c.Deprecated {
+		for _, path := range UsedEndpoints {
+			if d.Path == path {
+				inUse = append(inUse, d)
+				break
+			}
+		}
+	}
+	return inUse
+}
+
+// olderVersion reports whether version a is older than b. Both are dotted
+// numeric versions with an optional "v" prefix and pre-release suffix;
+// anything else, such as "dev", is never older.
+func olderVersion(a, b string) bool {
+	pa, ok := parseVersion(a)
+	if !ok {
+		return false
+	}
+	pb, ok := parseVersion(b)
+	if !ok {
+		return false
+	}
+	for i := 0; i < max(len(pa), len(pb)); i++ {
+		var x, y int
+		if i < len(pa) {
+			x = pa[i]
+		}
+		if i < len(pb) {
+			y = pb[i]
+		}
+		if x != y {
+			return x < y
+		}
+	}
+	return false
+}
+
+// parseVersion splits a version such as "v1.4.2-rc1" into its numbers
+func parseVersion(v string) ([]int, bool) {
+	v = strings.TrimPrefix(v, "v")
+	if i := strings.IndexAny(v, "-+"); i >= 0 {
+		v = v[:i]
+	}
+	var parts []int
+	for _, s := range strings.Split(v, ".") {
+		n, err := strconv.Atoi(s)
+		if err != nil {
+			return nil, false
+		}
+		parts = append(parts, n)
+	}
+	return parts, true
+}
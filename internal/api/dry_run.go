@@ -0,0 +1,32 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+)
+
+// ErrDryRun is returned by calls that would change the account while the
+// client's DryRun is set; the request was recorded instead of sent
+var ErrDryRun = errors.New("dry run: request not sent")
+
+// PlannedRequest is a request a dry run would have sent
+type PlannedRequest struct {
+	Method string
+	Path   string
+	Body   []byte // JSON body, nil if none
+}
+
+// String formats the request as e.g. `DELETE /v1/user/devices/abc`
+func (r PlannedRequest) String() string {
+	s := r.Method + " " + r.Path
+	if len(r.Body) > 0 {
+		s += " " + string(r.Body)
+	}
+	return s
+}
+
+// readOnly reports whether a request method never changes anything, so a
+// dry run still sends it to plan with current data
+func readOnly(method string) bool {
+	return method == http.MethodGet || method == http.MethodHead
+}
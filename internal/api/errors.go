@@ -0,0 +1,165 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxErrorBody bounds how much of an unparseable error body is kept, so a
+// proxy's HTML error page doesn't end up in the user's terminal
+const maxErrorBody = 200
+
+// AuthError represents an authentication error (401)
+type AuthError struct {
+	StatusCode int
+	Code       string // Machine-readable code from the API's error envelope, if any
+	Message    string
+}
+
+func (e *AuthError) Error() string {
+	return fmt.Sprintf("authentication failed (status %d): %s", e.StatusCode, e.Message)
+}
+
+// IsAuthError checks if an error is an AuthError
+func IsAuthError(err error) bool {
+	var authErr *AuthError
+	return errors.As(err, &authErr)
+}
+
+// StatusError is an unsuccessful API response other than 401 and 429
+type StatusError struct {
+	StatusCode int
+	Code       string // Machine-readable code from the API's error envelope, if any
+	Message    string
+	RetryAfter time.Duration // How long the API asked to wait before retrying, 0 if it didn't
+}
+
+func (e *StatusError) Error() string {
+	if e.Code != "" {
+		return fmt.Sprintf("API error (status %d, %s): %s", e.StatusCode, e.Code, e.Message)
+	}
+	return fmt.Sprintf("API error (status %d): %s", e.StatusCode, e.Message)
+}
+
+// IsServerError reports whether err is a 5xx response, i.e. the API is up
+// but failing, as opposed to a client or network error
+func IsServerError(err error) bool {
+	var statusErr *StatusError
+	return errors.As(err, &statusErr) && statusErr.StatusCode >= 500
+}
+
+// RateLimitError is a 429 response: the account or client made too many
+// requests and should wait RetryAfter before trying again
+type RateLimitError struct {
+	Code       string
+	Message    string
+	RetryAfter time.Duration // 0 if the API didn't say
+}
+
+func (e *RateLimitError) Error() string {
+	msg := "Aircast API rate limit reached"
+	if e.RetryAfter > 0 {
+		msg += fmt.Sprintf(", try again in %s", e.RetryAfter.Round(time.Second))
+	}
+	if e.Message != "" {
+		msg += ": " + e.Message
+	}
+	return msg
+}
+
+// IsRateLimited reports whether err is a 429 response and how long the API
+// asked to wait
+func IsRateLimited(err error) (time.Duration, bool) {
+	var rateErr *RateLimitError
+	if !errors.As(err, &rateErr) {
+		return 0, false
+	}
+	return rateErr.RetryAfter, true
+}
+
+// errorEnvelope is the API's JSON error body. Older endpoints nest it under
+// "error".
+type errorEnvelope struct {
+	Code       string  `json:"code"`
+	Message    string  `json:"message"`
+	RetryAfter float64 `json:"retry_after"` // Seconds
+}
+
+// parseErrorBody extracts the error envelope from a response body, falling
+// back to the body's text when it isn't one
+func parseErrorBody(body []byte) errorEnvelope {
+	var flat errorEnvelope
+	var nested struct {
+		Error *errorEnvelope `json:"error"`
+	}
+	if json.Unmarshal(body, &nested) == nil && nested.Error != nil && nested.Error.Message != "" {
+		return *nested.Error
+	}
+	if json.Unmarshal(body, &flat) == nil && flat.Message != "" {
+		return flat
+	}
+
+	text := strings.TrimSpace(string(body))
+	if len(text) > maxErrorBody {
+		text = text[:maxErrorBody] + "..."
+	}
+	return errorEnvelope{Message: text}
+}
+
+// parseRetryAfter reads a Retry-After header given in seconds or as an HTTP
+// date, returning 0 if it is missing or invalid
+func parseRetryAfter(header string, now time.Time) time.Duration {
+	if header == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(header); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if at, err := http.ParseTime(header); err == nil && at.After(now) {
+		return at.Sub(now)
+	}
+	return 0
+}
+
+// checkResponse converts an unsuccessful response into an error
+func checkResponse(resp *http.Response) error {
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+
+	body, _ := io.ReadAll(resp.Body)
+	envelope := parseErrorBody(body)
+
+	// The header wins over the body; proxies set it too
+	retryAfter := parseRetryAfter(resp.Header.Get("Retry-After"), time.Now())
+	if retryAfter == 0 && envelope.RetryAfter > 0 {
+		retryAfter = time.Duration(envelope.RetryAfter * float64(time.Second))
+	}
+
+	switch resp.StatusCode {
+	case http.StatusUnauthorized:
+		return &AuthError{
+			StatusCode: resp.StatusCode,
+			Code:       envelope.Code,
+			Message:    envelope.Message,
+		}
+	case http.StatusTooManyRequests:
+		return &RateLimitError{
+			Code:       envelope.Code,
+			Message:    envelope.Message,
+			RetryAfter: retryAfter,
+		}
+	}
+	return &StatusError{
+		StatusCode: resp.StatusCode,
+		Code:       envelope.Code,
+		Message:    envelope.Message,
+		RetryAfter: retryAfter,
+	}
+}
@@ -0,0 +1,114 @@
+package api
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Device lifecycle event types
+const (
+	EventDeviceOnline    = "device.online"
+	EventDeviceOffline   = "device.offline"
+	EventAgentUpdated    = "device.agent_updated"
+	EventOwnershipChange = "device.ownership_changed"
+)
+
+// ErrEventsUnsupported is returned when the API has no event feed
+var ErrEventsUnsupported = errors.New("API does not offer an event feed")
+
+// Event is a device lifecycle event from the account's event feed
+type Event struct {
+	ID           string    `json:"id,omitempty"`
+	Type         string    `json:"type"`
+	DeviceID     string    `json:"device_id"`
+	DeviceName   string    `json:"device_name,omitempty"`
+	AgentVersion string    `json:"agent_version,omitempty"` // device.agent_updated
+	Role         string    `json:"role,omitempty"`          // device.ownership_changed: the account's new role, empty if access was removed
+	OccurredAt   time.Time `json:"occurred_at"`
+}
+
+// SubscribeEvents streams the account's device events (Server-Sent Events)
+// to fn until ctx is done or the stream ends. lastEventID resumes after an
+// earlier stream; the ID of the last event received is returned so the
+// caller can resume after reconnecting.
+func (c *Client) SubscribeEvents(ctx context.Context, lastEventID string, fn func(Event)) (string, error) {
+	req, err := c.newRequest(ctx, http.MethodGet, "/v1/user/events", nil)
+	if err != nil {
+		return lastEventID, err
+	}
+	req.Header.Set("Accept", "text/event-stream")
+	if lastEventID != "" {
+		req.Header.Set("Last-Event-ID", lastEventID)
+	}
+
+	// The stream is long-lived, so it bypasses the retry policy's per-call timeout
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return lastEventID, fmt.Errorf("failed to reach API: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusNotImplemented {
+		return lastEventID, ErrEventsUnsupported
+	}
+	if err := checkResponse(resp); err != nil {
+		return lastEventID, err
+	}
+	if ct := resp.Header.Get("Content-Type"); !strings.HasPrefix(ct, "text/event-stream") {
+		return lastEventID, ErrEventsUnsupported
+	}
+
+	var eventType, id string
+	var data strings.Builder
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		// A blank line dispatches the event assembled so far
+		if line == "" {
+			if data.Len() > 0 {
+				var event Event
+				if err := json.Unmarshal([]byte(data.String()), &event); err == nil {
+					if eventType != "" && eventType != "message" {
+						event.Type = eventType
+					}
+					if id != "" {
+						event.ID = id
+						lastEventID = id
+					}
+					fn(event)
+				}
+			}
+			eventType, id = "", ""
+			data.Reset()
+			continue
+		}
+
+		field, value, _ := strings.Cut(line, ":")
+		value = strings.TrimPrefix(value, " ")
+		switch field {
+		case "event":
+			eventType = value
+		case "id":
+			id = value
+		case "data":
+			if data.Len() > 0 {
+				data.WriteByte('\n')
+			}
+			data.WriteString(value)
+		}
+		// Comments (":keepalive") and unknown fields are ignored
+	}
+
+	if err := scanner.Err(); err != nil && ctx.Err() == nil {
+		return lastEventID, fmt.Errorf("event stream interrupted: %w", err)
+	}
+	return lastEventID, nil
+}
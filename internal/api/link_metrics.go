@@ -0,0 +1,46 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// ErrNoLinkMetrics is returned when no bridge has reported link metrics for
+// a device, or the API doesn't keep them
+var ErrNoLinkMetrics = errors.New("no link metrics reported for this device")
+
+// LinkMetrics is the latest link quality reported for a device by bridges
+// running with --share-metrics
+type LinkMetrics struct {
+	ReportedAt time.Time `json:"reported_at"`
+	RTTMs      float64   `json:"rtt_ms"`
+	LossPct    float64   `json:"loss_pct"`
+	Reconnects uint64    `json:"reconnects"`
+}
+
+// GetLinkMetrics fetches the latest link metrics reported for a device
+func (c *Client) GetLinkMetrics(ctx context.Context, deviceID string) (*LinkMetrics, error) {
+	resp, err := c.do(ctx, "GET", "/v1/user/devices/"+url.PathEscape(deviceID)+"/link-metrics", nil, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get link metrics: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusNotImplemented {
+		return nil, ErrNoLinkMetrics
+	}
+	if err := checkResponse(resp); err != nil {
+		return nil, err
+	}
+
+	var metrics LinkMetrics
+	if err := json.NewDecoder(resp.Body).Decode(&metrics); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	return &metrics, nil
+}
@@ -0,0 +1,188 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// RetryPolicy controls timeouts and retries of API calls. Only idempotent
+// requests (GET, HEAD, PUT, DELETE) are retried.
+type RetryPolicy struct {
+	MaxAttempts int           // Total attempts including the first
+	BaseDelay   time.Duration // Backoff before the first retry, doubled after each
+	MaxDelay    time.Duration // Upper bound for the backoff
+	CallTimeout time.Duration // Timeout of each attempt
+
+	// MaxRetryAfter is the longest Retry-After on a 429 or 503 that is
+	// waited out; a longer one fails the call at once
+	MaxRetryAfter time.Duration
+}
+
+// DefaultRetryPolicy is used by new clients
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts: 4,
+	BaseDelay:   500 * time.Millisecond,
+	MaxDelay:    5 * time.Second,
+	CallTimeout: 10 * time.Second,
+
+	MaxRetryAfter: 30 * time.Second,
+}
+
+// retryableStatus are responses that indicate a transient server or proxy problem
+var retryableStatus = map[int]bool{
+	http.StatusTooManyRequests:    true,
+	http.StatusBadGateway:         true,
+	http.StatusServiceUnavailable: true,
+	http.StatusGatewayTimeout:     true,
+}
+
+// idempotentMethods may be retried without side effects
+var idempotentMethods = map[string]bool{
+	http.MethodGet:    true,
+	http.MethodHead:   true,
+	http.MethodPut:    true,
+	http.MethodDelete: true,
+}
+
+// backoff returns the delay before retry n (1-based), with jitter
+func (p RetryPolicy) backoff(n int) time.Duration {
+	delay := p.BaseDelay << (n - 1)
+	if delay > p.MaxDelay || delay <= 0 {
+		delay = p.MaxDelay
+	}
+	// Full jitter on the upper half spreads out clients retrying together
+	return delay/2 + time.Duration(rand.Int63n(int64(delay/2)+1))
+}
+
+// cancelOnClose releases an attempt's timeout once the body has been read
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (c cancelOnClose) Close() error {
+	err := c.ReadCloser.Close()
+	c.cancel()
+	return err
+}
+
+// do sends an API request, applying the per-attempt timeout and retrying
+// idempotent requests on network errors and transient status codes. The
+// caller's context deadline bounds all attempts.
+func (c *Client) do(ctx context.Context, method, path string, body []byte, header http.Header) (*http.Response, error) {
+	if c.DryRun != nil && !readOnly(method) {
+		c.DryRun(PlannedRequest{Method: method, Path: path, Body: body})
+		return nil, ErrDryRun
+	}
+
+	policy := c.Retry
+	attempts := policy.MaxAttempts
+	if attempts < 1 || !idempotentMethods[method] {
+		attempts = 1
+	}
+
+	for attempt := 1; ; attempt++ {
+		resp, err := c.attempt(ctx, method, path, body, header)
+
+		retryable := err != nil || retryableStatus[resp.StatusCode]
+		if !retryable || attempt >= attempts || ctx.Err() != nil {
+			return resp, err
+		}
+
+		delay := policy.backoff(attempt)
+		if err == nil {
+			// The server knows better than the backoff when it will take requests again
+			if retryAfter := responseRetryAfter(resp); retryAfter > 0 {
+				if retryAfter > policy.MaxRetryAfter {
+					return resp, err
+				}
+				delay = max(delay, retryAfter)
+			}
+		}
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < delay {
+			return resp, err
+		}
+
+		fields := log.Fields{"method": method, "path": path, "attempt": attempt, "retry_in": delay.Round(time.Millisecond)}
+		if err != nil {
+			log.WithFields(fields).WithError(err).Debug("API request failed, retrying")
+		} else {
+			fields["status"] = resp.StatusCode
+			if resp.StatusCode == http.StatusTooManyRequests {
+				log.WithFields(fields).Warn("Aircast API rate limit reached, waiting before retrying")
+			} else {
+				log.WithFields(fields).Debug("API request failed, retrying")
+			}
+			_, _ = io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(delay):
+		}
+	}
+}
+
+// responseRetryAfter returns how long a response asks to wait before
+// retrying: its Retry-After header or, without one, the retry_after of its
+// error envelope. The body stays readable for the caller.
+func responseRetryAfter(resp *http.Response) time.Duration {
+	if retryAfter := parseRetryAfter(resp.Header.Get("Retry-After"), time.Now()); retryAfter > 0 {
+		return retryAfter
+	}
+	body, err := io.ReadAll(resp.Body)
+	resp.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(body), resp.Body), resp.Body}
+	if err != nil {
+		return 0
+	}
+	if envelope := parseErrorBody(body); envelope.RetryAfter > 0 {
+		return time.Duration(envelope.RetryAfter * float64(time.Second))
+	}
+	return 0
+}
+
+// attempt sends a single request with the per-attempt timeout
+func (c *Client) attempt(ctx context.Context, method, path string, body []byte, header http.Header) (*http.Response, error) {
+	attemptCtx, cancel := ctx, context.CancelFunc(func() {})
+	if c.Retry.CallTimeout > 0 {
+		attemptCtx, cancel = context.WithTimeout(ctx, c.Retry.CallTimeout)
+	}
+
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+	req, err := c.newRequest(attemptCtx, method, path, reader)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	for name, values := range header {
+		req.Header[name] = values
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		cancel()
+		if errors.Is(err, context.DeadlineExceeded) && ctx.Err() == nil {
+			return nil, fmt.Errorf("%s %s timed out after %s", method, path, c.Retry.CallTimeout)
+		}
+		return nil, err
+	}
+
+	resp.Body = cancelOnClose{ReadCloser: resp.Body, cancel: cancel}
+	return resp, nil
+}
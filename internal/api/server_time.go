@@ -0,0 +1,40 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// ServerTime fetches the API server's current time from the Date header of a
+// lightweight HEAD request, adjusted for half the round trip
+func ServerTime(ctx context.Context, baseURL string) (time.Time, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, "HEAD", baseURL, nil)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	sent := time.Now()
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to reach API: %w", err)
+	}
+	defer resp.Body.Close()
+	rtt := time.Since(sent)
+
+	date := resp.Header.Get("Date")
+	if date == "" {
+		return time.Time{}, fmt.Errorf("API response has no Date header")
+	}
+
+	serverTime, err := http.ParseTime(date)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid Date header %q: %w", date, err)
+	}
+
+	return serverTime.Add(rtt / 2), nil
+}
@@ -0,0 +1,68 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// ErrSessionsUnsupported is returned when the API can't list or revoke
+// login sessions
+var ErrSessionsUnsupported = errors.New("session management is not supported by this API server")
+
+// Session is an active login of the account, e.g. the CLI on a field laptop
+type Session struct {
+	ID         string    `json:"id"`
+	Device     string    `json:"device"` // Client and host the login was made from, as reported by the API
+	IP         string    `json:"ip,omitempty"`
+	Scope      string    `json:"scope,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastUsedAt time.Time `json:"last_used_at,omitempty"`
+	Current    bool      `json:"current"` // The session of the token making the request
+}
+
+// ListSessions fetches the account's active login sessions
+func (c *Client) ListSessions(ctx context.Context) ([]Session, error) {
+	resp, err := c.do(ctx, "GET", "/v1/user/sessions", nil, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list sessions: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusNotImplemented {
+		return nil, ErrSessionsUnsupported
+	}
+	if err := checkResponse(resp); err != nil {
+		return nil, err
+	}
+
+	var result struct {
+		Sessions []Session `json:"sessions"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	return result.Sessions, nil
+}
+
+// RevokeSession signs a session out: its access and refresh tokens stop
+// working at once
+func (c *Client) RevokeSession(ctx context.Context, sessionID string) error {
+	resp, err := c.do(ctx, "DELETE", "/v1/user/sessions/"+url.PathEscape(sessionID), nil, nil)
+	if err != nil {
+		return fmt.Errorf("failed to revoke session: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusNotFound:
+		return fmt.Errorf("session %s not found", sessionID)
+	case http.StatusNotImplemented:
+		return ErrSessionsUnsupported
+	}
+	return checkResponse(resp)
+}
@@ -0,0 +1,53 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// Share is a read-only link to a device's live telemetry in the web
+// dashboard, for someone without access to the account
+type Share struct {
+	ID        string    `json:"id"`
+	URL       string    `json:"url"`
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// CreateShare mints a read-only share link to a device's telemetry that
+// expires after ttl
+func (c *Client) CreateShare(ctx context.Context, deviceID string, ttl time.Duration) (*Share, error) {
+	body, err := json.Marshal(map[string]interface{}{
+		"expires_in": int(ttl.Seconds()),
+		"access":     "read",
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.do(ctx, "POST", "/v1/user/devices/"+url.PathEscape(deviceID)+"/shares", body, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create share: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("device %s not found", deviceID)
+	}
+	if err := checkResponse(resp); err != nil {
+		return nil, err
+	}
+
+	var share Share
+	if err := json.NewDecoder(resp.Body).Decode(&share); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	if share.URL == "" {
+		return nil, fmt.Errorf("API returned a share without a URL")
+	}
+	return &share, nil
+}
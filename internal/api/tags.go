@@ -0,0 +1,109 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// ErrTagsUnsupported is returned when the API doesn't support device tags
+var ErrTagsUnsupported = errors.New("device tags are not supported by this API server")
+
+// HasTag reports whether the device has a tag, ignoring case as the
+// dashboard does
+func (d Device) HasTag(tag string) bool {
+	for _, t := range d.Tags {
+		if strings.EqualFold(t, tag) {
+			return true
+		}
+	}
+	return false
+}
+
+// FilterByTags returns the devices that have every one of tags, or all
+// devices if tags is empty
+func FilterByTags(devices []Device, tags []string) []Device {
+	if len(tags) == 0 {
+		return devices
+	}
+
+	var filtered []Device
+	for _, d := range devices {
+		match := true
+		for _, tag := range tags {
+			if !d.HasTag(tag) {
+				match = false
+				break
+			}
+		}
+		if match {
+			filtered = append(filtered, d)
+		}
+	}
+	return filtered
+}
+
+// tagsPath returns the path of a device's tags endpoint
+func tagsPath(deviceID string) string {
+	return "/v1/user/devices/" + url.PathEscape(deviceID) + "/tags"
+}
+
+// GetDeviceTags fetches a device's tags
+func (c *Client) GetDeviceTags(ctx context.Context, deviceID string) ([]string, error) {
+	resp, err := c.do(ctx, "GET", tagsPath(deviceID), nil, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get device tags: %w", err)
+	}
+	defer resp.Body.Close()
+
+	return decodeTags(resp)
+}
+
+// AddDeviceTags adds tags to a device and returns all of its tags
+func (c *Client) AddDeviceTags(ctx context.Context, deviceID string, tags []string) ([]string, error) {
+	body, err := json.Marshal(map[string][]string{"tags": tags})
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.do(ctx, "POST", tagsPath(deviceID), body, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to add device tags: %w", err)
+	}
+	defer resp.Body.Close()
+
+	return decodeTags(resp)
+}
+
+// RemoveDeviceTag removes a tag from a device and returns its remaining tags
+func (c *Client) RemoveDeviceTag(ctx context.Context, deviceID, tag string) ([]string, error) {
+	resp, err := c.do(ctx, "DELETE", tagsPath(deviceID)+"/"+url.PathEscape(tag), nil, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to remove device tag: %w", err)
+	}
+	defer resp.Body.Close()
+
+	return decodeTags(resp)
+}
+
+// decodeTags reads the tags endpoints' {"tags": [...]} response
+func decodeTags(resp *http.Response) ([]string, error) {
+	if resp.StatusCode == http.StatusNotImplemented {
+		return nil, ErrTagsUnsupported
+	}
+	if err := checkResponse(resp); err != nil {
+		return nil, err
+	}
+
+	var result struct {
+		Tags []string `json:"tags"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	return result.Tags, nil
+}
@@ -0,0 +1,282 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"runtime"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// browserLoginTimeout bounds how long the user has to finish logging in
+const browserLoginTimeout = 5 * time.Minute
+
+// ErrNoBrowser is returned when no browser can be opened, so callers can
+// fall back to the device code flow
+var ErrNoBrowser = errors.New("no browser available")
+
+// BrowserAuth implements the OAuth2 authorization code flow with PKCE
+// (RFC 7636) and a loopback redirect (RFC 8252). An existing browser session
+// authorizes the CLI in a couple of seconds without polling.
+type BrowserAuth struct {
+	apiURL string
+	logger *log.Entry
+
+	// Scope requests a restricted token; empty requests the default scopes
+	Scope string
+
+	// Client is the OAuth2 client to authenticate as; the zero value is the Aircast CLI client
+	Client Client
+}
+
+// callbackResult is what the browser redirect delivered
+type callbackResult struct {
+	code string
+	err  error
+}
+
+// NewBrowserAuth creates a new browser authenticator
+func NewBrowserAuth(apiURL string, logger *log.Entry) *BrowserAuth {
+	if logger == nil {
+		logger = log.WithField("component", "browser_auth")
+	}
+
+	return &BrowserAuth{
+		apiURL: apiURL,
+		logger: logger,
+	}
+}
+
+// Authenticate opens the authorization page in the browser, waits for the
+// redirect to a localhost callback and exchanges the code for a token
+func (b *BrowserAuth) Authenticate(ctx context.Context) (*TokenResponse, error) {
+	if !browserAvailable() {
+		return nil, ErrNoBrowser
+	}
+
+	endpoints, err := b.Client.Endpoints(ctx, b.apiURL)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := require("authorization", endpoints.Authorization); err != nil {
+		return nil, err
+	}
+
+	verifier, err := randomString(32)
+	if err != nil {
+		return nil, err
+	}
+	state, err := randomString(16)
+	if err != nil {
+		return nil, err
+	}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, fmt.Errorf("failed to start callback server: %w", err)
+	}
+	redirectURI := fmt.Sprintf("http://%s/callback", listener.Addr())
+
+	results := make(chan callbackResult, 1)
+	server := &http.Server{
+		Handler:           b.callbackHandler(state, results),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	go func() { _ = server.Serve(listener) }()
+	defer server.Close()
+
+	params := url.Values{
+		"response_type":         {"code"},
+		"client_id":             {b.Client.ClientID()},
+		"redirect_uri":          {redirectURI},
+		"code_challenge":        {codeChallenge(PKCES256, verifier)},
+		"code_challenge_method": {PKCES256},
+		"state":                 {state},
+	}
+	if b.Scope != "" {
+		params.Set("scope", b.Scope)
+	}
+	authURL := endpoints.Authorization + "?" + params.Encode()
+
+	if err := openBrowser(authURL); err != nil {
+		b.logger.WithError(err).Debug("Failed to open browser")
+		return nil, ErrNoBrowser
+	}
+
+	fmt.Println("Opened your browser to log in to Aircast.")
+	fmt.Println("If it didn't open, visit:")
+	fmt.Println()
+	fmt.Printf("  %s\n", authURL)
+	fmt.Println()
+
+	ctx, cancel := context.WithTimeout(ctx, browserLoginTimeout)
+	defer cancel()
+
+	var result callbackResult
+	select {
+	case <-ctx.Done():
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return nil, fmt.Errorf("timed out waiting for browser login")
+		}
+		return nil, ctx.Err()
+	case result = <-results:
+	}
+	if result.err != nil {
+		return nil, result.err
+	}
+
+	token, err := b.exchangeCode(ctx, endpoints.Token, result.code, redirectURI, verifier)
+	if err != nil {
+		return nil, fmt.Errorf("failed to exchange authorization code: %w", err)
+	}
+
+	fmt.Println("✓ Authentication successful!")
+	if token.Scope != "" {
+		fmt.Printf("  Granted scopes: %s\n", token.Scope)
+	}
+	fmt.Println()
+
+	return token, nil
+}
+
+// callbackHandler receives the authorization redirect and reports the first
+// valid result
+func (b *BrowserAuth) callbackHandler(state string, results chan<- callbackResult) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/callback", func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+
+		// Ignore requests that aren't the redirect for this login attempt
+		if q.Get("state") != state {
+			http.Error(w, "Invalid state", http.StatusBadRequest)
+			return
+		}
+
+		var result callbackResult
+		switch {
+		case q.Get("error") == "access_denied":
+			result.err = fmt.Errorf("user denied authorization")
+		case q.Get("error") != "":
+			result.err = fmt.Errorf("authorization error: %s", firstNonEmpty(q.Get("error_description"), q.Get("error")))
+		case q.Get("code") == "":
+			result.err = fmt.Errorf("authorization response contained no code")
+		default:
+			result.code = q.Get("code")
+		}
+
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		if result.err != nil {
+			fmt.Fprintf(w, "<html><body><h2>Aircast login failed</h2><p>%s</p></body></html>", html.EscapeString(result.err.Error()))
+		} else {
+			fmt.Fprint(w, "<html><body><h2>Aircast CLI is logged in</h2><p>You can close this window.</p></body></html>")
+		}
+
+		select {
+		case results <- result:
+		default:
+		}
+	})
+	return mux
+}
+
+// exchangeCode trades the authorization code and PKCE verifier for a token
+func (b *BrowserAuth) exchangeCode(ctx context.Context, tokenURL, code, redirectURI, verifier string) (*TokenResponse, error) {
+	params := map[string]string{
+		"grant_type":    "authorization_code",
+		"code":          code,
+		"redirect_uri":  redirectURI,
+		"code_verifier": verifier,
+	}
+
+	req, err := b.Client.newRequest(ctx, tokenURL, params)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	var tokenResp TokenResponse
+	if err := json.Unmarshal(body, &tokenResp); err != nil {
+		return nil, fmt.Errorf("failed to parse response (status %d): %w", resp.StatusCode, err)
+	}
+	if tokenResp.Error != "" {
+		return nil, &TokenErrorResponse{
+			ErrorCode:        tokenResp.Error,
+			ErrorDescription: tokenResp.ErrorDesc,
+		}
+	}
+	if tokenResp.AccessToken == "" {
+		return nil, fmt.Errorf("token response (status %d) contained no access token", resp.StatusCode)
+	}
+
+	return &tokenResp, nil
+}
+
+// browserAvailable reports whether a browser can plausibly be opened, e.g.
+// not over SSH on a headless Linux box
+func browserAvailable() bool {
+	if os.Getenv("BROWSER") != "" {
+		return true
+	}
+	switch runtime.GOOS {
+	case "darwin", "windows":
+		return true
+	default:
+		return os.Getenv("DISPLAY") != "" || os.Getenv("WAYLAND_DISPLAY") != ""
+	}
+}
+
+// openBrowser opens url in $BROWSER or the system's default browser
+func openBrowser(url string) error {
+	if browser := os.Getenv("BROWSER"); browser != "" {
+		return exec.Command(browser, url).Start()
+	}
+	switch runtime.GOOS {
+	case "darwin":
+		return exec.Command("open", url).Start()
+	case "windows":
+		return exec.Command("rundll32", "url.dll,FileProtocolHandler", url).Start()
+	default:
+		return exec.Command("xdg-open", url).Start()
+	}
+}
+
+// randomString returns n random bytes, base64url-encoded
+func randomString(n int) (string, error) {
+	buf := make([]byte, n)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate random value: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+// firstNonEmpty returns the first non-empty string
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
@@ -0,0 +1,123 @@
+package auth
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// DefaultClientID is the public OAuth2 client the Aircast API knows the CLI by
+const DefaultClientID = "aircast-cli"
+
+// PKCE code challenge methods (RFC 7636)
+const (
+	PKCES256  = "S256"
+	PKCEPlain = "plain"
+)
+
+// Client identifies the CLI to the authorization server and says where that
+// server's endpoints are. The zero value is the public client the Aircast API
+// expects; deployments that front Aircast auth with a standard identity
+// provider (Auth0, Keycloak) set their own.
+type Client struct {
+	ID     string `json:"client_id,omitempty"`     // Empty means DefaultClientID
+	Secret string `json:"client_secret,omitempty"` // Sent as client_secret for confidential clients
+
+	// PKCE adds a code challenge to device code requests, for providers
+	// that require one on every flow: "S256", "plain" or empty for none.
+	// The browser login always uses S256.
+	PKCE string `json:"pkce,omitempty"`
+
+	// FormEncoded sends requests as application/x-www-form-urlencoded, as
+	// RFC 6749 specifies, instead of the JSON the Aircast API accepts
+	FormEncoded bool `json:"form_encoded,omitempty"`
+
+	// Overrides replaces individual endpoints, e.g. for self-hosted
+	// deployments with different auth routing
+	Overrides Endpoints `json:"endpoints"`
+
+	// Issuer is an authorization server whose endpoints are discovered from
+	// its metadata. Discover does the same with the API URL as issuer.
+	Issuer   string `json:"issuer,omitempty"`
+	Discover bool   `json:"discover,omitempty"`
+}
+
+// ClientID returns the client ID to send
+func (c Client) ClientID() string {
+	if c.ID == "" {
+		return DefaultClientID
+	}
+	return c.ID
+}
+
+// Validate checks the PKCE method
+func (c Client) Validate() error {
+	switch c.PKCE {
+	case "", PKCES256, PKCEPlain:
+		return nil
+	default:
+		return fmt.Errorf("unsupported PKCE method %q (use S256 or plain)", c.PKCE)
+	}
+}
+
+// newPKCE returns a code verifier and its challenge for the configured
+// method, or empty strings if PKCE is off
+func (c Client) newPKCE() (verifier, challenge string, err error) {
+	if c.PKCE == "" {
+		return "", "", nil
+	}
+	if verifier, err = randomString(32); err != nil {
+		return "", "", err
+	}
+	return verifier, codeChallenge(c.PKCE, verifier), nil
+}
+
+// codeChallenge derives the PKCE challenge for a verifier
+func codeChallenge(method, verifier string) string {
+	if method == PKCEPlain {
+		return verifier
+	}
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// newRequest builds a POST to an authorization server endpoint, adding the
+// client's credentials to params
+func (c Client) newRequest(ctx context.Context, endpoint string, params map[string]string) (*http.Request, error) {
+	body := make(map[string]string, len(params)+2)
+	for k, v := range params {
+		body[k] = v
+	}
+	body["client_id"] = c.ClientID()
+	if c.Secret != "" {
+		body["client_secret"] = c.Secret
+	}
+
+	if c.FormEncoded {
+		values := url.Values{}
+		for k, v := range body {
+			values.Set(k, v)
+		}
+		req, err := http.NewRequestWithContext(ctx, "POST", endpoint, strings.NewReader(values.Encode()))
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.Header.Set("Accept", "application/json")
+		return req, nil
+	}
+
+	reqJSON, _ := json.Marshal(body)
+	req, err := http.NewRequestWithContext(ctx, "POST", endpoint, bytes.NewReader(reqJSON))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	return req, nil
+}
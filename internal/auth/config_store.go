@@ -5,6 +5,12 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/pavliha/aircast/aircast-cli/internal/settings"
+	"github.com/pavliha/aircast/aircast-cli/internal/store"
 )
 
 // ConfigStore handles persistent storage of user preferences
@@ -15,21 +21,78 @@ type ConfigStore struct {
 // Config represents user configuration/preferences
 type Config struct {
 	LastDeviceID string `json:"last_device_id,omitempty"`
+
+	// BandwidthProfiles are user-defined profiles for --profile-bandwidth
+	BandwidthProfiles map[string]settings.BandwidthProfile `json:"bandwidth_profiles,omitempty"`
+
+	// Aliases map short names to device IDs, accepted wherever a device ID is
+	Aliases map[string]string `json:"aliases,omitempty"`
+
+	// Alarms are telemetry alarm rules such as "battery<20", added to any --alarm flags
+	Alarms []string `json:"alarms,omitempty"`
+	// AlarmHook is a command run when an alarm is raised or cleared
+	AlarmHook string `json:"alarm_hook,omitempty"`
+
+	// Remap are source ID rewrite rules such as "gcs:*/*=255/190", added to any --remap flags
+	Remap []string `json:"remap,omitempty"`
+
+	// TelemetrySinks write selected decoded telemetry fields to InfluxDB or
+	// Postgres/TimescaleDB while bridging
+	TelemetrySinks []settings.TelemetrySink `json:"telemetry_sinks,omitempty"`
+
+	// Webhooks receive signed JSON on session start and stop, link loss and
+	// restore, and alarms
+	Webhooks []settings.Webhook `json:"webhooks,omitempty"`
+
+	// LogRedact are extra regular expressions whose matches are removed from
+	// log output; a group in the pattern is kept, e.g. the name of a field
+	LogRedact []string `json:"log_redact,omitempty"`
+
+	// OAuthClient replaces the built-in OAuth2 client, e.g. for an identity
+	// provider in front of a self-hosted API
+	OAuthClient *Client `json:"oauth_client,omitempty"`
+
+	// CredentialHelper is a command that prints a token as JSON, used to
+	// log in and refresh instead of the OAuth flows, e.g. for an SSO broker
+	CredentialHelper string `json:"credential_helper,omitempty"`
+
+	// Environments are named API environments added with 'aircast-cli env
+	// set', besides the built-in ones
+	Environments map[string]Environment `json:"environments,omitempty"`
+	// Environment is the one chosen with 'aircast-cli env use', whose API
+	// URL --api defaults to
+	Environment string `json:"environment,omitempty"`
 }
 
-// NewConfigStore creates a new config store
-func NewConfigStore() (*ConfigStore, error) {
-	// Use ~/.aircast for config directory
-	homeDir, err := os.UserHomeDir()
-	if err != nil {
-		return nil, fmt.Errorf("failed to get home directory: %w", err)
+// aliasPattern restricts alias names so they can't be mistaken for flags or IDs
+var aliasPattern = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_-]{0,31}$`)
+
+// DeviceAliases returns the aliases of each device ID, comma-separated
+func (c *Config) DeviceAliases() map[string]string {
+	names := make([]string, 0, len(c.Aliases))
+	for name := range c.Aliases {
+		names = append(names, name)
 	}
+	sort.Strings(names)
 
-	configDir := filepath.Join(homeDir, ".aircast")
+	byDevice := make(map[string][]string)
+	for _, name := range names {
+		id := c.Aliases[name]
+		byDevice[id] = append(byDevice[id], name)
+	}
+
+	result := make(map[string]string, len(byDevice))
+	for id, aliases := range byDevice {
+		result[id] = strings.Join(aliases, ", ")
+	}
+	return result
+}
 
-	// Create directory if it doesn't exist
-	if err := os.MkdirAll(configDir, 0700); err != nil {
-		return nil, fmt.Errorf("failed to create config directory: %w", err)
+// NewConfigStore creates a new config store
+func NewConfigStore() (*ConfigStore, error) {
+	configDir, err := store.ConfigDir()
+	if err != nil {
+		return nil, err
 	}
 
 	return &ConfigStore{
@@ -44,21 +107,50 @@ func (cs *ConfigStore) GetConfigPath() string {
 
 // SaveConfig saves configuration to disk
 func (cs *ConfigStore) SaveConfig(config *Config) error {
-	configPath := cs.GetConfigPath()
+	unlock, err := store.LockFile(cs.GetConfigPath(), 0600)
+	if err != nil {
+		return err
+	}
+	defer unlock()
 
+	return cs.saveConfig(config)
+}
+
+// saveConfig writes the config file; the caller holds its lock
+func (cs *ConfigStore) saveConfig(config *Config) error {
 	data, err := json.MarshalIndent(config, "", "  ")
 	if err != nil {
 		return fmt.Errorf("failed to marshal config: %w", err)
 	}
 
 	// Write with restrictive permissions (only user can read/write)
-	if err := os.WriteFile(configPath, data, 0600); err != nil {
+	if err := store.WriteFileAtomic(cs.GetConfigPath(), data, 0600); err != nil {
 		return fmt.Errorf("failed to write config file: %w", err)
 	}
 
 	return nil
 }
 
+// update loads the config, lets change modify it and saves it if change
+// reports a modification, holding the config file's lock throughout so
+// changes from another aircast-cli aren't lost
+func (cs *ConfigStore) update(change func(config *Config) bool) error {
+	unlock, err := store.LockFile(cs.GetConfigPath(), 0600)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	config, err := cs.LoadConfig()
+	if err != nil {
+		return err
+	}
+	if !change(config) {
+		return nil
+	}
+	return cs.saveConfig(config)
+}
+
 // LoadConfig loads configuration from disk
 func (cs *ConfigStore) LoadConfig() (*Config, error) {
 	configPath := cs.GetConfigPath()
@@ -81,14 +173,59 @@ func (cs *ConfigStore) LoadConfig() (*Config, error) {
 
 // SaveLastDevice saves the last used device ID
 func (cs *ConfigStore) SaveLastDevice(deviceID string) error {
-	config, err := cs.LoadConfig()
-	if err != nil {
+	return cs.update(func(config *Config) bool {
+		config.LastDeviceID = deviceID
+		return true
+	})
+}
+
+// SetAlias points an alias at a device ID, replacing any existing target
+func (cs *ConfigStore) SetAlias(name, deviceID string) error {
+	if err := ValidateAlias(name); err != nil {
 		return err
 	}
 
-	config.LastDeviceID = deviceID
+	return cs.update(func(config *Config) bool {
+		if config.Aliases == nil {
+			config.Aliases = make(map[string]string)
+		}
+		config.Aliases[name] = deviceID
+		return true
+	})
+}
+
+// ValidateAlias checks that an alias name is usable
+func ValidateAlias(name string) error {
+	if !aliasPattern.MatchString(name) {
+		return fmt.Errorf("invalid alias %q: use up to 32 letters, digits, '-' or '_', starting with a letter", name)
+	}
+	return nil
+}
+
+// RemoveAlias deletes an alias, reporting whether it existed
+func (cs *ConfigStore) RemoveAlias(name string) (bool, error) {
+	var existed bool
+	err := cs.update(func(config *Config) bool {
+		if _, existed = config.Aliases[name]; existed {
+			delete(config.Aliases, name)
+		}
+		return existed
+	})
+	return existed, err
+}
 
-	return cs.SaveConfig(config)
+// ResolveDevice returns the device ID an alias points to, or the argument
+// unchanged if it is not an alias
+func (cs *ConfigStore) ResolveDevice(nameOrID string) (string, error) {
+	config, err := cs.LoadConfig()
+	if err != nil {
+		return nameOrID, err
+	}
+
+	if id, ok := config.Aliases[nameOrID]; ok {
+		return id, nil
+	}
+	return nameOrID, nil
 }
 
 // GetLastDevice returns the last used device ID
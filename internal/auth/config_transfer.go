@@ -0,0 +1,270 @@
+package auth
+
+import (
+	"fmt"
+	"maps"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/pavliha/aircast/aircast-cli/internal/secret"
+	"github.com/pavliha/aircast/aircast-cli/internal/settings"
+)
+
+// Portable returns a copy of the config to replicate onto other machines:
+// the last used device and the active environment are left out, as are secrets (webhook URLs
+// and signing secrets, database tokens, passwords and query credentials,
+// the OAuth client secret) unless they are references such as "env:NAME".
+// Webhook URLs keep only their scheme and host, so imports can match them
+// to the local webhooks. It also returns where each removed secret was,
+// e.g. "webhooks[0].secret". The alarm hook and the
+// credential helper are never exported: they are commands this machine
+// runs, so importing them would run whatever the file says.
+func (c *Config) Portable() (*Config, []string) {
+	var removed []string
+	out := &Config{
+		BandwidthProfiles: c.BandwidthProfiles,
+		Aliases:           c.Aliases,
+		Alarms:            c.Alarms,
+		Remap:             c.Remap,
+		LogRedact:         c.LogRedact,
+	}
+
+	for i, sink := range c.TelemetrySinks {
+		if sink.Token != "" && !secret.IsReference(sink.Token) {
+			sink.Token = ""
+			removed = append(removed, fmt.Sprintf("telemetry_sinks[%d].token", i))
+		}
+		if u, ok := withoutCredentials(sink.URL); ok && !secret.IsReference(sink.URL) {
+			sink.URL = u
+			removed = append(removed, fmt.Sprintf("telemetry_sinks[%d].url credentials", i))
+		}
+		out.TelemetrySinks = append(out.TelemetrySinks, sink)
+	}
+	for i, hook := range c.Webhooks {
+		if hook.Secret != "" && !secret.IsReference(hook.Secret) {
+			hook.Secret = ""
+			removed = append(removed, fmt.Sprintf("webhooks[%d].secret", i))
+		}
+		if !secret.IsReference(hook.URL) {
+			hook.URL = redactWebhookURL(hook.URL)
+			removed = append(removed, fmt.Sprintf("webhooks[%d].url", i))
+		}
+		out.Webhooks = append(out.Webhooks, hook)
+	}
+	if c.OAuthClient != nil {
+		client := *c.OAuthClient
+		if client.Secret != "" && !secret.IsReference(client.Secret) {
+			client.Secret = ""
+			removed = append(removed, "oauth_client.client_secret")
+		}
+		out.OAuthClient = &client
+	}
+	for _, name := range slices.Sorted(maps.Keys(c.Environments)) {
+		env := c.Environments[name]
+		if env.OAuthClient != nil {
+			client := *env.OAuthClient
+			if client.Secret != "" && !secret.IsReference(client.Secret) {
+				client.Secret = ""
+				removed = append(removed, fmt.Sprintf("environments.%s.oauth_client.client_secret", name))
+			}
+			env.OAuthClient = &client
+		}
+		if out.Environments == nil {
+			out.Environments = make(map[string]Environment)
+		}
+		out.Environments[name] = env
+	}
+
+	return out, removed
+}
+
+// credentialParams are query parameters that carry credentials in sink URLs
+var credentialParams = []string{"password", "token", "access_token", "api_key", "apikey", "key", "secret", "sig", "signature"}
+
+// withoutCredentials removes the password and credential query parameters
+// from a connection URL, reporting whether there were any
+func withoutCredentials(raw string) (string, bool) {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return raw, false
+	}
+	removed := false
+	if u.User != nil {
+		if _, ok := u.User.Password(); ok {
+			u.User = url.User(u.User.Username())
+			removed = true
+		}
+	}
+	query := u.Query()
+	for name := range query {
+		if slices.Contains(credentialParams, strings.ToLower(name)) {
+			query.Del(name)
+			removed = true
+		}
+	}
+	if !removed {
+		return raw, false
+	}
+	u.RawQuery = query.Encode()
+	return u.String(), true
+}
+
+// redactedPath replaces the path of exported webhook URLs
+const redactedPath = "/[REDACTED]"
+
+// redactWebhookURL keeps only the scheme and host of a webhook URL: for
+// chat services the path or query is the credential
+func redactWebhookURL(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" {
+		return redactedPath
+	}
+	return u.Scheme + "://" + u.Host + redactedPath
+}
+
+// sameWebhook reports whether an imported webhook describes a local one,
+// matching redacted URLs by scheme and host
+func sameWebhook(local, in settings.Webhook) bool {
+	if strings.HasSuffix(in.URL, redactedPath) {
+		return redactWebhookURL(local.URL) == in.URL
+	}
+	return local.URL == in.URL
+}
+
+// Import applies an exported config. With replace, every section the
+// export covers is replaced; otherwise profiles, aliases and environments
+// are merged (imported entries win) and lists gain the entries they don't
+// have yet. Secrets already on this machine are kept for sinks, webhooks
+// and OAuth clients the import describes, since exports never carry them.
+// Webhooks whose redacted URL matches no local one are skipped; Import
+// returns their scheme and host. Commands (the alarm hook and the
+// credential helper) are never imported; the local ones are kept.
+func (c *Config) Import(in *Config, replace bool) []string {
+	var skipped []string
+	localSinks, localHooks, localClient := slices.Clone(c.TelemetrySinks), slices.Clone(c.Webhooks), c.OAuthClient
+	localEnvs := maps.Clone(c.Environments)
+
+	if replace {
+		c.BandwidthProfiles = in.BandwidthProfiles
+		c.Aliases = in.Aliases
+		c.Alarms = in.Alarms
+		c.Remap = in.Remap
+		c.LogRedact = in.LogRedact
+		c.TelemetrySinks = nil
+		c.Webhooks = nil
+		c.OAuthClient = nil
+		c.Environments = nil
+	} else {
+		c.BandwidthProfiles = mergeMap(c.BandwidthProfiles, in.BandwidthProfiles)
+		c.Aliases = mergeMap(c.Aliases, in.Aliases)
+		c.Alarms = appendMissing(c.Alarms, in.Alarms)
+		c.Remap = appendMissing(c.Remap, in.Remap)
+		c.LogRedact = appendMissing(c.LogRedact, in.LogRedact)
+	}
+
+	for _, sink := range in.TelemetrySinks {
+		i := slices.IndexFunc(c.TelemetrySinks, func(s settings.TelemetrySink) bool { return sameSink(s, sink) })
+		if old := slices.IndexFunc(localSinks, func(s settings.TelemetrySink) bool { return sameSink(s, sink) }); old >= 0 {
+			keepSinkSecrets(&sink, localSinks[old])
+		}
+		if i >= 0 {
+			c.TelemetrySinks[i] = sink
+		} else {
+			c.TelemetrySinks = append(c.TelemetrySinks, sink)
+		}
+	}
+	for _, hook := range in.Webhooks {
+		old := slices.IndexFunc(localHooks, func(h settings.Webhook) bool { return sameWebhook(h, hook) })
+		if old >= 0 {
+			hook.URL = localHooks[old].URL
+			if hook.Secret == "" {
+				hook.Secret = localHooks[old].Secret
+			}
+		} else if strings.HasSuffix(hook.URL, redactedPath) {
+			skipped = append(skipped, strings.TrimSuffix(hook.URL, redactedPath))
+			continue
+		}
+		i := slices.IndexFunc(c.Webhooks, func(h settings.Webhook) bool { return h.URL == hook.URL })
+		if i >= 0 {
+			c.Webhooks[i] = hook
+		} else {
+			c.Webhooks = append(c.Webhooks, hook)
+		}
+	}
+	if in.OAuthClient != nil {
+		client := *in.OAuthClient
+		if localClient != nil && localClient.ID == client.ID && client.Secret == "" {
+			client.Secret = localClient.Secret
+		}
+		c.OAuthClient = &client
+	}
+	for name, env := range in.Environments {
+		if local := localEnvs[name].OAuthClient; env.OAuthClient != nil && local != nil && local.ID == env.OAuthClient.ID && env.OAuthClient.Secret == "" {
+			client := *env.OAuthClient
+			client.Secret = local.Secret
+			env.OAuthClient = &client
+		}
+		if c.Environments == nil {
+			c.Environments = make(map[string]Environment)
+		}
+		c.Environments[name] = env
+	}
+	if _, ok := c.AllEnvironments()[c.Environment]; !ok {
+		c.Environment = "" // The active environment was replaced
+	}
+	return skipped
+}
+
+// sameSink reports whether two sinks write to the same place, ignoring credentials
+func sameSink(a, b settings.TelemetrySink) bool {
+	urlA, _ := withoutCredentials(a.URL)
+	urlB, _ := withoutCredentials(b.URL)
+	return a.Type == b.Type && urlA == urlB && a.Database == b.Database && a.Bucket == b.Bucket && a.Table == b.Table
+}
+
+// keepSinkSecrets fills in the token and URL credentials an imported sink
+// lacks from the local one
+func keepSinkSecrets(sink *settings.TelemetrySink, local settings.TelemetrySink) {
+	if sink.Token == "" {
+		sink.Token = local.Token
+	}
+	if _, ok := withoutCredentials(sink.URL); !ok {
+		if _, hadCredentials := withoutCredentials(local.URL); hadCredentials {
+			sink.URL = local.URL
+		}
+	}
+}
+
+// mergeMap adds the entries of in to m, replacing those with the same key
+func mergeMap[V any](m, in map[string]V) map[string]V {
+	if len(in) == 0 {
+		return m
+	}
+	if m == nil {
+		m = make(map[string]V, len(in))
+	}
+	for k, v := range in {
+		m[k] = v
+	}
+	return m
+}
+
+// appendMissing adds the items of in that list doesn't have yet
+func appendMissing(list, in []string) []string {
+	for _, item := range in {
+		if !slices.Contains(list, item) {
+			list = append(list, item)
+		}
+	}
+	return list
+}
+
+// ImportConfig applies an exported config to the config file, keeping the
+// last used device
+func (cs *ConfigStore) ImportConfig(in *Config, replace bool) error {
+	return cs.update(func(config *Config) bool {
+		config.Import(in, replace)
+		return true
+	})
+}
@@ -0,0 +1,53 @@
+package auth
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Reasons a credential helper is run, passed to it as AIRCAST_CREDENTIAL_REASON
+const (
+	HelperLogin   = "login"   // Someone is logging in; the helper may interact with them
+	HelperRefresh = "refresh" // A running bridge needs a fresh token; no one may be watching
+)
+
+// HelperCredential is the JSON a credential helper prints on stdout. Either
+// expires_in (seconds) or expires_at (RFC 3339) may give the token's expiry.
+type HelperCredential struct {
+	AccessToken string    `json:"access_token"`
+	TokenType   string    `json:"token_type,omitempty"` // Bearer if empty
+	ExpiresIn   int       `json:"expires_in,omitempty"`
+	ExpiresAt   time.Time `json:"expires_at,omitempty"`
+	Scope       string    `json:"scope,omitempty"`
+}
+
+// ParseHelperCredential reads a credential helper's output as a token
+// response. now is the time to measure expires_at against.
+func ParseHelperCredential(output []byte, now time.Time) (*TokenResponse, error) {
+	var cred HelperCredential
+	if err := json.Unmarshal(output, &cred); err != nil {
+		return nil, fmt.Errorf("credential helper printed invalid JSON: %w", err)
+	}
+	if cred.AccessToken == "" {
+		return nil, fmt.Errorf("credential helper printed no access_token")
+	}
+	if cred.TokenType != "" && !strings.EqualFold(cred.TokenType, "bearer") {
+		return nil, fmt.Errorf("credential helper returned a %q token, only Bearer tokens are supported", cred.TokenType)
+	}
+
+	token := &TokenResponse{
+		AccessToken: cred.AccessToken,
+		TokenType:   "Bearer",
+		ExpiresIn:   cred.ExpiresIn,
+		Scope:       cred.Scope,
+	}
+	if token.ExpiresIn == 0 && !cred.ExpiresAt.IsZero() {
+		token.ExpiresIn = int(cred.ExpiresAt.Sub(now) / time.Second)
+		if token.ExpiresIn <= 0 {
+			return nil, fmt.Errorf("credential helper returned a token that expired at %s", cred.ExpiresAt.Format(time.RFC3339))
+		}
+	}
+	return token, nil
+}
@@ -0,0 +1,220 @@
+package auth
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/pavliha/aircast/aircast-cli/internal/api"
+	"github.com/pavliha/aircast/aircast-cli/internal/store"
+)
+
+// DeviceCache persists the last fetched device list so the CLI can still
+// connect when the REST API is unreachable. It also keeps the names of the
+// devices in every list the CLI fetches, across environments, so shell
+// completion and --device-name can resolve names instantly and offline.
+type DeviceCache struct {
+	configDir string
+}
+
+// CachedDevices is a device list snapshot
+type CachedDevices struct {
+	APIURL    string          `json:"api_url"`
+	Account   string          `json:"account,omitempty"` // AccountKey of the token that fetched the list
+	ETag      string          `json:"etag,omitempty"`    // Validator for conditional requests
+	FetchedAt time.Time       `json:"fetched_at"`
+	Devices   []api.Device    `json:"devices"`
+	Online    map[string]bool `json:"online"` // Online status at fetch time, by device ID
+}
+
+// IndexedDevice is a device whose name the cache has seen
+type IndexedDevice struct {
+	ID     string    `json:"id"`
+	Name   string    `json:"name"`
+	APIURL string    `json:"api_url"`
+	SeenAt time.Time `json:"seen_at"` // When a fetched device list last included it
+}
+
+// deviceCacheFile is the on-disk cache. Unlike the snapshot, which only
+// holds the last list fetched, Names keeps each environment's devices when
+// another environment's list is fetched.
+type deviceCacheFile struct {
+	CachedDevices
+	Names            []IndexedDevice `json:"names,omitempty"`
+	RefreshStartedAt time.Time       `json:"names_refresh_started_at"` // Last background refresh of the names started
+}
+
+// NewDeviceCache creates a new device cache
+func NewDeviceCache() (*DeviceCache, error) {
+	configDir, err := store.ConfigDir()
+	if err != nil {
+		return nil, err
+	}
+
+	return &DeviceCache{
+		configDir: configDir,
+	}, nil
+}
+
+// GetCachePath returns the path to the device cache file
+func (dc *DeviceCache) GetCachePath() string {
+	return filepath.Join(dc.configDir, "devices.json")
+}
+
+// Save stores the device list fetched from apiURL by account
+func (dc *DeviceCache) Save(apiURL, account string, list *api.DeviceList) error {
+	return dc.update(func(file *deviceCacheFile) {
+		file.CachedDevices = CachedDevices{
+			APIURL:    apiURL,
+			Account:   account,
+			ETag:      list.ETag,
+			FetchedAt: time.Now(),
+			Devices:   list.Devices,
+			Online:    make(map[string]bool, len(list.Devices)),
+		}
+		for _, d := range list.Devices {
+			file.Online[d.ID] = d.IsOnline
+		}
+	})
+}
+
+// LoadAny returns the cached device list whichever API it came from, or nil
+// if there is none
+func (dc *DeviceCache) LoadAny() (*CachedDevices, error) {
+	file, err := dc.read()
+	if err != nil {
+		return nil, err
+	}
+	if file.FetchedAt.IsZero() {
+		return nil, nil // Only names so far, not an error
+	}
+	return &file.CachedDevices, nil
+}
+
+// Load returns the device list cached for apiURL and account, or nil if
+// there is none
+func (dc *DeviceCache) Load(apiURL, account string) (*CachedDevices, error) {
+	cached, err := dc.LoadAny()
+	if err != nil || cached == nil {
+		return nil, err
+	}
+
+	// A list from another environment or account is useless here
+	if cached.APIURL != apiURL || cached.Account != account {
+		return nil, nil
+	}
+
+	for i := range cached.Devices {
+		cached.Devices[i].IsOnline = cached.Online[cached.Devices[i].ID]
+	}
+
+	return cached, nil
+}
+
+// Find returns the cached device with the given ID regardless of which
+// environment the list came from, along with that list's API URL
+func (dc *DeviceCache) Find(deviceID string) (*api.Device, string, error) {
+	cached, err := dc.LoadAny()
+	if err != nil || cached == nil {
+		return nil, "", err
+	}
+
+	for i := range cached.Devices {
+		if cached.Devices[i].ID == deviceID {
+			return &cached.Devices[i], cached.APIURL, nil
+		}
+	}
+	return nil, "", nil
+}
+
+// Age returns how long ago the list was fetched
+func (c *CachedDevices) Age() time.Duration {
+	return time.Since(c.FetchedAt)
+}
+
+// Names returns every device whose name the cache has seen
+func (dc *DeviceCache) Names() ([]IndexedDevice, error) {
+	file, err := dc.read()
+	if err != nil {
+		return nil, err
+	}
+	return file.Names, nil
+}
+
+// UpdateNames replaces the device names kept for apiURL with a freshly
+// fetched list
+func (dc *DeviceCache) UpdateNames(apiURL string, devices []api.Device) error {
+	return dc.update(func(file *deviceCacheFile) {
+		kept := file.Names[:0]
+		for _, d := range file.Names {
+			if d.APIURL != apiURL {
+				kept = append(kept, d)
+			}
+		}
+
+		now := time.Now()
+		for _, d := range devices {
+			kept = append(kept, IndexedDevice{ID: d.ID, Name: d.Name, APIURL: apiURL, SeenAt: now})
+		}
+		file.Names = kept
+	})
+}
+
+// StartRefresh records that a background refresh of the names is starting,
+// unless one started less than interval ago, and reports whether to go
+// ahead. This keeps repeated completions from each starting their own.
+func (dc *DeviceCache) StartRefresh(interval time.Duration) (bool, error) {
+	var start bool
+	err := dc.update(func(file *deviceCacheFile) {
+		if time.Since(file.RefreshStartedAt) < interval {
+			return
+		}
+		file.RefreshStartedAt = time.Now()
+		start = true
+	})
+	return start, err
+}
+
+// update applies a change to the cache file while holding its lock
+func (dc *DeviceCache) update(change func(file *deviceCacheFile)) error {
+	unlock, err := store.LockFile(dc.GetCachePath(), 0600)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	file, err := dc.read()
+	if err != nil {
+		// A corrupt cache is refetched; start over
+		file = &deviceCacheFile{}
+	}
+	change(file)
+
+	data, err := json.MarshalIndent(file, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal device cache: %w", err)
+	}
+	if err := store.WriteFileAtomic(dc.GetCachePath(), data, 0600); err != nil {
+		return fmt.Errorf("failed to write device cache: %w", err)
+	}
+	return nil
+}
+
+// read loads the cache file, empty if there is none
+func (dc *DeviceCache) read() (*deviceCacheFile, error) {
+	data, err := os.ReadFile(dc.GetCachePath())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return &deviceCacheFile{}, nil
+		}
+		return nil, fmt.Errorf("failed to read device cache: %w", err)
+	}
+
+	var file deviceCacheFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse device cache: %w", err)
+	}
+	return &file, nil
+}
@@ -1,7 +1,6 @@
 package auth
 
 import (
-	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
@@ -9,6 +8,7 @@ import (
 	"net/http"
 	"time"
 
+	"github.com/pavliha/aircast/aircast-cli/internal/term"
 	log "github.com/sirupsen/logrus"
 )
 
@@ -16,6 +16,14 @@ import (
 type DeviceCodeAuth struct {
 	apiURL string
 	logger *log.Entry
+
+	// Scope requests a restricted token (e.g. "telemetry-only"); empty requests the default scopes
+	Scope string
+
+	// Client is the OAuth2 client to authenticate as; the zero value is the Aircast CLI client
+	Client Client
+
+	resolved *Endpoints // Client endpoints, resolved on first use
 }
 
 // DeviceCodeResponse represents the initial device code response
@@ -26,6 +34,8 @@ type DeviceCodeResponse struct {
 	VerificationURIComplete string `json:"verification_uri_complete"`
 	ExpiresIn               int    `json:"expires_in"`
 	Interval                int    `json:"interval"`
+
+	codeVerifier string // PKCE verifier sent with the token request
 }
 
 // TokenResponse represents the token response
@@ -58,44 +68,91 @@ func NewDeviceCodeAuth(apiURL string, logger *log.Entry) *DeviceCodeAuth {
 }
 
 // Authenticate performs OAuth2 Device Code Flow
-func (d *DeviceCodeAuth) Authenticate(ctx context.Context) (string, error) {
+func (d *DeviceCodeAuth) Authenticate(ctx context.Context) (*TokenResponse, error) {
 	// Step 1: Request device code
-	deviceResp, err := d.requestDeviceCode(ctx)
+	deviceResp, err := d.RequestCode(ctx)
 	if err != nil {
-		return "", fmt.Errorf("failed to request device code: %w", err)
+		return nil, err
 	}
 
 	// Step 2: Display instructions to user
 	d.displayInstructions(deviceResp)
 
 	// Step 3: Poll for token
-	token, err := d.pollForToken(ctx, deviceResp)
+	token, err := d.PollToken(ctx, deviceResp)
 	if err != nil {
-		return "", fmt.Errorf("failed to get token: %w", err)
+		return nil, err
 	}
 
 	fmt.Println("\n✓ Authentication successful!")
+	if token.Scope != "" {
+		fmt.Printf("  Granted scopes: %s\n", token.Scope)
+	}
 	fmt.Println()
 
 	return token, nil
 }
 
+// RequestCode requests a device code for the user to authorize
+func (d *DeviceCodeAuth) RequestCode(ctx context.Context) (*DeviceCodeResponse, error) {
+	deviceResp, err := d.requestDeviceCode(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to request device code: %w", err)
+	}
+	return deviceResp, nil
+}
+
+// PollToken waits until the user authorizes the device code and returns the token
+func (d *DeviceCodeAuth) PollToken(ctx context.Context, deviceResp *DeviceCodeResponse) (*TokenResponse, error) {
+	token, err := d.pollForToken(ctx, deviceResp)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get token: %w", err)
+	}
+	return token, nil
+}
+
+// endpoints resolves the client's endpoints once per authenticator
+func (d *DeviceCodeAuth) endpoints(ctx context.Context) (Endpoints, error) {
+	if d.resolved == nil {
+		endpoints, err := d.Client.Endpoints(ctx, d.apiURL)
+		if err != nil {
+			return Endpoints{}, err
+		}
+		d.resolved = &endpoints
+	}
+	return *d.resolved, nil
+}
+
 // requestDeviceCode requests a device code from the API
 func (d *DeviceCodeAuth) requestDeviceCode(ctx context.Context) (*DeviceCodeResponse, error) {
-	url := fmt.Sprintf("%s/v1/oauth2/cli/code", d.apiURL)
+	endpoints, err := d.endpoints(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	verifier, challenge, err := d.Client.newPKCE()
+	if err != nil {
+		return nil, err
+	}
 
-	// Request body with client_id
-	reqBody := map[string]string{
-		"client_id": "aircast-cli",
+	params := map[string]string{}
+	if d.Scope != "" {
+		params["scope"] = d.Scope
+	}
+	if challenge != "" {
+		params["code_challenge"] = challenge
+		params["code_challenge_method"] = d.Client.PKCE
 	}
-	reqJSON, _ := json.Marshal(reqBody)
 
-	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(reqJSON))
+	endpoint, err := require("device_authorization", endpoints.DeviceAuthorization)
 	if err != nil {
 		return nil, err
 	}
 
-	req.Header.Set("Content-Type", "application/json")
+	req, err := d.Client.newRequest(ctx, endpoint, params)
+	if err != nil {
+		return nil, err
+	}
 
 	resp, err := http.DefaultClient.Do(req)
 	if err != nil {
@@ -112,20 +169,35 @@ func (d *DeviceCodeAuth) requestDeviceCode(ctx context.Context) (*DeviceCodeResp
 	if err := json.NewDecoder(resp.Body).Decode(&deviceResp); err != nil {
 		return nil, err
 	}
+	deviceResp.codeVerifier = verifier
+
+	// RFC 8628 makes these optional; the Aircast API always sends them
+	if deviceResp.VerificationURIComplete == "" {
+		deviceResp.VerificationURIComplete = deviceResp.VerificationURI
+	}
+	if deviceResp.Interval <= 0 {
+		deviceResp.Interval = 5
+	}
 
 	return &deviceResp, nil
 }
 
 // displayInstructions shows authentication instructions to the user
 func (d *DeviceCodeAuth) displayInstructions(resp *DeviceCodeResponse) {
-	fmt.Println("\n╔═══════════════════════════════════════════════════════════════╗")
-	fmt.Println("║                   Aircast Authentication                      ║")
-	fmt.Println("╚═══════════════════════════════════════════════════════════════╝")
+	fmt.Println()
+	fmt.Println(term.Banner("Aircast Authentication"))
 	fmt.Println()
 	fmt.Println("To authenticate aircast-cli, visit this URL:")
 	fmt.Println()
 	fmt.Printf("  %s\n", resp.VerificationURIComplete)
 	fmt.Println()
+	if resp.VerificationURIComplete == resp.VerificationURI && resp.UserCode != "" {
+		fmt.Printf("and enter the code: %s\n", resp.UserCode)
+		fmt.Println()
+	}
+	if d.Scope != "" {
+		fmt.Printf("Requested scope: %s\n", d.Scope)
+	}
 	fmt.Printf("Code expires in %d minutes.\n", resp.ExpiresIn/60)
 	fmt.Println()
 	fmt.Println("Waiting for authorization...")
@@ -133,8 +205,12 @@ func (d *DeviceCodeAuth) displayInstructions(resp *DeviceCodeResponse) {
 }
 
 // pollForToken polls the API for token
-func (d *DeviceCodeAuth) pollForToken(ctx context.Context, deviceResp *DeviceCodeResponse) (string, error) {
-	url := fmt.Sprintf("%s/v1/oauth2/cli/token", d.apiURL)
+func (d *DeviceCodeAuth) pollForToken(ctx context.Context, deviceResp *DeviceCodeResponse) (*TokenResponse, error) {
+	endpoints, err := d.endpoints(ctx)
+	if err != nil {
+		return nil, err
+	}
+	url := endpoints.Token
 	interval := time.Duration(deviceResp.Interval) * time.Second
 	expires := time.Now().Add(time.Duration(deviceResp.ExpiresIn) * time.Second)
 
@@ -144,10 +220,10 @@ func (d *DeviceCodeAuth) pollForToken(ctx context.Context, deviceResp *DeviceCod
 	for {
 		select {
 		case <-ctx.Done():
-			return "", ctx.Err()
+			return nil, ctx.Err()
 		case <-ticker.C:
 			if time.Now().After(expires) {
-				return "", fmt.Errorf("device code expired")
+				return nil, fmt.Errorf("device code expired")
 			}
 
 			token, err := d.attemptTokenRequest(ctx, url, deviceResp)
@@ -166,11 +242,13 @@ func (d *DeviceCodeAuth) pollForToken(ctx context.Context, deviceResp *DeviceCod
 						d.logger.Debug("Slowing down polling")
 						continue
 					case "expired_token":
-						return "", fmt.Errorf("device code expired")
+						return nil, fmt.Errorf("device code expired")
 					case "access_denied":
-						return "", fmt.Errorf("user denied authorization")
+						return nil, fmt.Errorf("user denied authorization")
+					case "invalid_scope":
+						return nil, fmt.Errorf("requested scope %q is not available: %s", d.Scope, tokenErr.ErrorDescription)
 					default:
-						return "", fmt.Errorf("authorization error: %s", tokenErr.ErrorDescription)
+						return nil, fmt.Errorf("authorization error: %s", tokenErr.ErrorDescription)
 					}
 				}
 				// Other errors
@@ -185,52 +263,51 @@ func (d *DeviceCodeAuth) pollForToken(ctx context.Context, deviceResp *DeviceCod
 }
 
 // attemptTokenRequest attempts to get the token
-func (d *DeviceCodeAuth) attemptTokenRequest(ctx context.Context, url string, deviceResp *DeviceCodeResponse) (string, error) {
-	reqBody := map[string]string{
+func (d *DeviceCodeAuth) attemptTokenRequest(ctx context.Context, url string, deviceResp *DeviceCodeResponse) (*TokenResponse, error) {
+	params := map[string]string{
 		"grant_type":  "urn:ietf:params:oauth:grant-type:device_code",
 		"device_code": deviceResp.DeviceCode,
-		"client_id":   "aircast-cli",
 	}
-	reqJSON, _ := json.Marshal(reqBody)
+	if deviceResp.codeVerifier != "" {
+		params["code_verifier"] = deviceResp.codeVerifier
+	}
 
-	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(reqJSON))
+	req, err := d.Client.newRequest(ctx, url, params)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
-	req.Header.Set("Content-Type", "application/json")
-
 	resp, err := http.DefaultClient.Do(req)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 	defer resp.Body.Close()
 
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
 	// Parse response (success or error in same structure)
 	var tokenResp TokenResponse
 	if err := json.Unmarshal(body, &tokenResp); err != nil {
-		return "", fmt.Errorf("failed to parse response: %w", err)
+		return nil, fmt.Errorf("failed to parse response: %w", err)
 	}
 
 	// Check if response contains an error
 	if tokenResp.Error != "" {
-		return "", &TokenErrorResponse{
+		return nil, &TokenErrorResponse{
 			ErrorCode:        tokenResp.Error,
 			ErrorDescription: tokenResp.ErrorDesc,
 		}
 	}
 
-	// Success - return access token
+	// Success - return the token
 	if tokenResp.AccessToken == "" {
-		return "", fmt.Errorf("no access token in response")
+		return nil, fmt.Errorf("no access token in response")
 	}
 
-	return tokenResp.AccessToken, nil
+	return &tokenResp, nil
 }
 
 // Error implements error interface for TokenErrorResponse
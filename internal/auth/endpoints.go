@@ -0,0 +1,167 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Endpoints are an authorization server's OAuth2 endpoints, named as in
+// RFC 8414 metadata. Each is an absolute URL or a path relative to the API URL.
+type Endpoints struct {
+	DeviceAuthorization string `json:"device_authorization_endpoint,omitempty"`
+	Token               string `json:"token_endpoint,omitempty"`
+	Authorization       string `json:"authorization_endpoint,omitempty"`
+	Revocation          string `json:"revocation_endpoint,omitempty"`
+}
+
+// DefaultEndpoints are the Aircast API's routes
+var DefaultEndpoints = Endpoints{
+	DeviceAuthorization: "/v1/oauth2/cli/code",
+	Token:               "/v1/oauth2/cli/token",
+	Authorization:       "/v1/oauth2/cli/authorize",
+	Revocation:          "/v1/oauth2/cli/revoke",
+}
+
+// discoveryPaths are the well-known metadata documents tried in order
+var discoveryPaths = []string{"oauth-authorization-server", "openid-configuration"}
+
+// merge returns e with its unset endpoints taken from fallback
+func (e Endpoints) merge(fallback Endpoints) Endpoints {
+	if e.DeviceAuthorization == "" {
+		e.DeviceAuthorization = fallback.DeviceAuthorization
+	}
+	if e.Token == "" {
+		e.Token = fallback.Token
+	}
+	if e.Authorization == "" {
+		e.Authorization = fallback.Authorization
+	}
+	if e.Revocation == "" {
+		e.Revocation = fallback.Revocation
+	}
+	return e
+}
+
+// resolve makes relative endpoints absolute against apiURL
+func (e Endpoints) resolve(apiURL string) Endpoints {
+	abs := func(endpoint string) string {
+		if endpoint == "" || strings.Contains(endpoint, "://") {
+			return endpoint
+		}
+		return strings.TrimRight(apiURL, "/") + "/" + strings.TrimLeft(endpoint, "/")
+	}
+	return Endpoints{
+		DeviceAuthorization: abs(e.DeviceAuthorization),
+		Token:               abs(e.Token),
+		Authorization:       abs(e.Authorization),
+		Revocation:          abs(e.Revocation),
+	}
+}
+
+// Endpoints returns the client's authorization server endpoints as absolute
+// URLs: those configured explicitly, then those the issuer advertises if
+// discovery is enabled, then the Aircast API's own routes. The Aircast routes
+// are never used for an external issuer; endpoints it doesn't advertise stay
+// unset and fail when used.
+func (c Client) Endpoints(ctx context.Context, apiURL string) (Endpoints, error) {
+	endpoints := c.Overrides
+
+	if issuer := c.issuer(apiURL); issuer != "" {
+		discovered, err := Discover(ctx, issuer)
+		if err != nil {
+			return Endpoints{}, err
+		}
+		endpoints = endpoints.merge(discovered)
+	}
+
+	if c.Issuer == "" {
+		endpoints = endpoints.merge(DefaultEndpoints)
+	}
+	return endpoints.resolve(apiURL), nil
+}
+
+// require returns endpoint, or an error naming the missing endpoint
+func require(name, endpoint string) (string, error) {
+	if endpoint == "" {
+		return "", fmt.Errorf("the authorization server doesn't advertise a %s endpoint; set it under oauth_client.endpoints", name)
+	}
+	return endpoint, nil
+}
+
+// issuer returns the server to discover endpoints from, or "" if discovery is off
+func (c Client) issuer(apiURL string) string {
+	if c.Issuer != "" {
+		return c.Issuer
+	}
+	if c.Discover {
+		return apiURL
+	}
+	return ""
+}
+
+// Discover fetches an authorization server's metadata (RFC 8414), falling
+// back to OpenID Connect discovery for providers that only publish that
+func Discover(ctx context.Context, issuer string) (Endpoints, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	var lastErr error
+	for _, name := range discoveryPaths {
+		for _, metadataURL := range wellKnownURLs(issuer, name) {
+			endpoints, err := fetchMetadata(ctx, metadataURL)
+			if err == nil {
+				return endpoints, nil
+			}
+			lastErr = err
+		}
+	}
+	return Endpoints{}, fmt.Errorf("failed to discover authorization server endpoints at %s: %w", issuer, lastErr)
+}
+
+// wellKnownURLs returns where a metadata document may be published. RFC 8414
+// inserts the well-known segment before an issuer's path, while many
+// providers (e.g. Keycloak realms) append it.
+func wellKnownURLs(issuer, name string) []string {
+	issuer = strings.TrimRight(issuer, "/")
+	u, err := url.Parse(issuer)
+	if err != nil || u.Path == "" {
+		return []string{issuer + "/.well-known/" + name}
+	}
+
+	path := u.Path
+	u.Path = "/.well-known/" + name + path
+	return []string{u.String(), issuer + "/.well-known/" + name}
+}
+
+// fetchMetadata downloads and decodes one metadata document
+func fetchMetadata(ctx context.Context, metadataURL string) (Endpoints, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", metadataURL, nil)
+	if err != nil {
+		return Endpoints{}, err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return Endpoints{}, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return Endpoints{}, fmt.Errorf("%s returned status %d", metadataURL, resp.StatusCode)
+	}
+
+	var endpoints Endpoints
+	if err := json.NewDecoder(resp.Body).Decode(&endpoints); err != nil {
+		return Endpoints{}, fmt.Errorf("invalid metadata at %s: %w", metadataURL, err)
+	}
+	if endpoints.Token == "" {
+		return Endpoints{}, fmt.Errorf("metadata at %s has no token_endpoint", metadataURL)
+	}
+	return endpoints, nil
+}
@@ -0,0 +1,101 @@
+package auth
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"net"
+	"net/url"
+	"strings"
+)
+
+// Aircast environments
+const (
+	EnvProduction = "prod"
+	EnvStaging    = "staging"
+	EnvDev        = "dev"
+	EnvLocal      = "local"
+	EnvCustom     = "custom"
+)
+
+// DetectEnvironment classifies an API or issuer URL by its host name
+func DetectEnvironment(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Hostname() == "" {
+		return EnvCustom
+	}
+	host := strings.ToLower(u.Hostname())
+
+	if host == "localhost" {
+		return EnvLocal
+	}
+	if ip := net.ParseIP(host); ip != nil {
+		if ip.IsLoopback() {
+			return EnvLocal
+		}
+		return EnvCustom
+	}
+	if host != "aircast.one" && !strings.HasSuffix(host, ".aircast.one") {
+		return EnvCustom
+	}
+
+	for _, label := range strings.Split(strings.TrimSuffix(host, ".aircast.one"), ".") {
+		switch label {
+		case "dev":
+			return EnvDev
+		case "staging", "stage":
+			return EnvStaging
+		}
+	}
+	return EnvProduction
+}
+
+// tokenClaims are the JWT claims the CLI reads from access tokens
+type tokenClaims struct {
+	Issuer  string `json:"iss"`
+	Subject string `json:"sub"`
+}
+
+// parseClaims decodes the claims of a JWT without verifying it, returning
+// zero claims for opaque tokens
+func parseClaims(accessToken string) tokenClaims {
+	var claims tokenClaims
+	parts := strings.Split(accessToken, ".")
+	if len(parts) == 3 {
+		if payload, err := base64.RawURLEncoding.DecodeString(parts[1]); err == nil {
+			_ = json.Unmarshal(payload, &claims)
+		}
+	}
+	return claims
+}
+
+// TokenEnvironment determines which environment issued an access token,
+// from the JWT issuer claim if present, otherwise from the API URL
+func TokenEnvironment(accessToken, apiURL string) string {
+	if issuer := parseClaims(accessToken).Issuer; issuer != "" {
+		if env := DetectEnvironment(issuer); env != EnvCustom {
+			return env
+		}
+	}
+	return DetectEnvironment(apiURL)
+}
+
+// AccountKey identifies the account a token belongs to for keying caches:
+// the JWT subject, or a hash of an opaque token
+func AccountKey(accessToken string) string {
+	if subject := parseClaims(accessToken).Subject; subject != "" {
+		return subject
+	}
+	sum := sha256.Sum256([]byte(accessToken))
+	return hex.EncodeToString(sum[:8])
+}
+
+// Env returns the token's environment, detecting it for tokens saved before
+// the environment was stored
+func (t *StoredToken) Env() string {
+	if t.Environment != "" {
+		return t.Environment
+	}
+	return TokenEnvironment(t.AccessToken, t.APIURL)
+}
@@ -0,0 +1,162 @@
+package auth
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Environment is a named API environment for 'aircast-cli env'
+type Environment struct {
+	APIURL string `json:"api_url"`
+
+	// OAuthClient replaces oauth_client when logging in to this
+	// environment, e.g. for a staging identity provider
+	OAuthClient *Client `json:"oauth_client,omitempty"`
+
+	// Device is connected to when no --device is given, instead of the last
+	// used device; an alias or device ID
+	Device string `json:"device,omitempty"`
+}
+
+// BuiltinEnvironments are known without being added
+var BuiltinEnvironments = map[string]Environment{
+	EnvProduction: {APIURL: "https://api.aircast.one"},
+	EnvStaging:    {APIURL: "https://api.staging.aircast.one"},
+	EnvDev:        {APIURL: "https://api.dev.aircast.one"},
+	EnvLocal:      {APIURL: "http://localhost:3333"},
+}
+
+// DefaultAPIURL is the production API, used when no environment is active
+const DefaultAPIURL = "https://api.aircast.one"
+
+// envNamePattern restricts environment names like aliases
+var envNamePattern = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_.-]{0,31}$`)
+
+// ValidateEnvironmentName checks that an environment name is usable
+func ValidateEnvironmentName(name string) error {
+	if !envNamePattern.MatchString(name) {
+		return fmt.Errorf("invalid environment name %q: use up to 32 letters, digits, '.', '-' or '_', starting with a letter", name)
+	}
+	return nil
+}
+
+// ValidateAPIURL checks that an environment's API URL is an absolute HTTP(S) URL
+func ValidateAPIURL(apiURL string) error {
+	u, err := url.Parse(apiURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("invalid API URL %q: expected e.g. https://api.example.com", apiURL)
+	}
+	return nil
+}
+
+// AllEnvironments returns the built-in environments overlaid with the
+// configured ones
+func (c *Config) AllEnvironments() map[string]Environment {
+	all := make(map[string]Environment, len(BuiltinEnvironments)+len(c.Environments))
+	for name, env := range BuiltinEnvironments {
+		all[name] = env
+	}
+	for name, env := range c.Environments {
+		all[name] = env
+	}
+	return all
+}
+
+// LookupEnvironment finds an environment by name, or by API URL when given
+// a URL, returning its name
+func (c *Config) LookupEnvironment(nameOrURL string) (string, Environment, bool) {
+	all := c.AllEnvironments()
+	if env, ok := all[nameOrURL]; ok {
+		return nameOrURL, env, true
+	}
+	if !strings.Contains(nameOrURL, "://") {
+		return "", Environment{}, false
+	}
+
+	// Configured environments first, so one that shadows a built-in wins
+	want := strings.TrimRight(nameOrURL, "/")
+	names := make([]string, 0, len(all))
+	for name := range all {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		_, ci := c.Environments[names[i]]
+		_, cj := c.Environments[names[j]]
+		if ci != cj {
+			return ci
+		}
+		return names[i] < names[j]
+	})
+	for _, name := range names {
+		if strings.TrimRight(all[name].APIURL, "/") == want {
+			return name, all[name], true
+		}
+	}
+	return "", Environment{}, false
+}
+
+// APIURL returns the active environment's API URL, or DefaultAPIURL
+func (c *Config) APIURL() string {
+	if c.Environment != "" {
+		if env, ok := c.AllEnvironments()[c.Environment]; ok {
+			return env.APIURL
+		}
+	}
+	return DefaultAPIURL
+}
+
+// SetEnvironment adds or replaces a named environment
+func (cs *ConfigStore) SetEnvironment(name string, env Environment) error {
+	if err := ValidateEnvironmentName(name); err != nil {
+		return err
+	}
+	if err := ValidateAPIURL(env.APIURL); err != nil {
+		return err
+	}
+
+	return cs.update(func(config *Config) bool {
+		if config.Environments == nil {
+			config.Environments = make(map[string]Environment)
+		}
+		config.Environments[name] = env
+		return true
+	})
+}
+
+// RemoveEnvironment deletes a configured environment, reporting whether it
+// existed. Removing the active one makes production active again.
+func (cs *ConfigStore) RemoveEnvironment(name string) (bool, error) {
+	var existed bool
+	err := cs.update(func(config *Config) bool {
+		if _, existed = config.Environments[name]; !existed {
+			return false
+		}
+		delete(config.Environments, name)
+		if config.Environment == name {
+			if _, builtin := BuiltinEnvironments[name]; !builtin {
+				config.Environment = ""
+			}
+		}
+		return true
+	})
+	return existed, err
+}
+
+// UseEnvironment makes a known environment the active one
+func (cs *ConfigStore) UseEnvironment(name string) error {
+	var found bool
+	err := cs.update(func(config *Config) bool {
+		if _, found = config.AllEnvironments()[name]; !found {
+			return false
+		}
+		config.Environment = name
+		return true
+	})
+	if err == nil && !found {
+		err = fmt.Errorf("unknown environment %q", name)
+	}
+	return err
+}
@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/pavliha/aircast/aircast-cli/internal/term"
 	log "github.com/sirupsen/logrus"
 )
 
@@ -60,9 +61,8 @@ func (a *OAuth2Authenticator) Authenticate(ctx context.Context) (string, error)
 	authURL := fmt.Sprintf("%s/v1/oauth2/user/google?token=%s", a.config.APIURL, authToken)
 
 	// Display instructions to user
-	fmt.Println("\n╔═══════════════════════════════════════════════════════════════╗")
-	fmt.Println("║                   Aircast Authentication                      ║")
-	fmt.Println("╚═══════════════════════════════════════════════════════════════╝")
+	fmt.Println()
+	fmt.Println(term.Banner("Aircast Authentication"))
 	fmt.Println()
 	fmt.Println("To authenticate, please visit this URL in your browser:")
 	fmt.Println()
@@ -0,0 +1,60 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// RefreshAccessToken exchanges a refresh token for a new access token
+// (RFC 6749 section 6). Errors reported by the server are returned as
+// *TokenErrorResponse, e.g. "invalid_grant" once the refresh token is revoked
+// or expired. The refresh must use the client the token was issued to.
+func RefreshAccessToken(ctx context.Context, apiURL string, client Client, refreshToken string) (*TokenResponse, error) {
+	endpoints, err := client.Endpoints(ctx, apiURL)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := client.newRequest(ctx, endpoints.Token, map[string]string{
+		"grant_type":    "refresh_token",
+		"refresh_token": refreshToken,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	var tokenResp TokenResponse
+	if err := json.Unmarshal(body, &tokenResp); err != nil {
+		return nil, fmt.Errorf("token refresh failed (status %d): %s", resp.StatusCode, string(body))
+	}
+	if tokenResp.Error != "" {
+		return nil, &TokenErrorResponse{
+			ErrorCode:        tokenResp.Error,
+			ErrorDescription: tokenResp.ErrorDesc,
+		}
+	}
+	if resp.StatusCode != http.StatusOK || tokenResp.AccessToken == "" {
+		return nil, fmt.Errorf("token refresh failed (status %d)", resp.StatusCode)
+	}
+
+	// Servers that don't rotate refresh tokens omit it from the response
+	if tokenResp.RefreshToken == "" {
+		tokenResp.RefreshToken = refreshToken
+	}
+
+	return &tokenResp, nil
+}
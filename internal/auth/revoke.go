@@ -0,0 +1,49 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// Token type hints for revocation (RFC 7009)
+const (
+	TokenTypeAccess  = "access_token"
+	TokenTypeRefresh = "refresh_token"
+)
+
+// RevokeToken invalidates a token on the server (RFC 7009)
+func RevokeToken(ctx context.Context, apiURL string, client Client, token, tokenTypeHint string) error {
+	endpoints, err := client.Endpoints(ctx, apiURL)
+	if err != nil {
+		return err
+	}
+
+	endpoint, err := require("revocation", endpoints.Revocation)
+	if err != nil {
+		return err
+	}
+
+	req, err := client.newRequest(ctx, endpoint, map[string]string{
+		"token":           token,
+		"token_type_hint": tokenTypeHint,
+	})
+	if err != nil {
+		return err
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	// Per RFC 7009 the server answers 200 for unknown or already revoked tokens too
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNoContent {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("revocation failed (status %d): %s", resp.StatusCode, string(body))
+	}
+
+	return nil
+}
@@ -0,0 +1,94 @@
+package auth
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/pavliha/aircast/aircast-cli/internal/store"
+)
+
+// maxSessionHistory is the number of session summaries kept on disk
+const maxSessionHistory = 20
+
+// SessionHistory keeps summaries of recent bridge sessions for support bundles
+type SessionHistory struct {
+	configDir string
+}
+
+// NewSessionHistory creates a new session history store
+func NewSessionHistory() (*SessionHistory, error) {
+	configDir, err := store.ConfigDir()
+	if err != nil {
+		return nil, err
+	}
+
+	return &SessionHistory{
+		configDir: configDir,
+	}, nil
+}
+
+// GetHistoryPath returns the path to the session history file
+func (sh *SessionHistory) GetHistoryPath() string {
+	return filepath.Join(sh.configDir, "sessions.jsonl")
+}
+
+// Append records a session summary, dropping the oldest beyond the limit
+func (sh *SessionHistory) Append(summary interface{}) error {
+	line, err := json.Marshal(summary)
+	if err != nil {
+		return fmt.Errorf("failed to marshal session summary: %w", err)
+	}
+
+	sessions, err := sh.Recent(maxSessionHistory - 1)
+	if err != nil {
+		return err
+	}
+	sessions = append(sessions, line)
+
+	var buf bytes.Buffer
+	for _, s := range sessions {
+		buf.Write(s)
+		buf.WriteByte('\n')
+	}
+
+	if err := os.WriteFile(sh.GetHistoryPath(), buf.Bytes(), 0600); err != nil {
+		return fmt.Errorf("failed to write session history: %w", err)
+	}
+
+	return nil
+}
+
+// Recent returns up to n of the most recent session summaries, oldest first
+func (sh *SessionHistory) Recent(n int) ([]json.RawMessage, error) {
+	f, err := os.Open(sh.GetHistoryPath())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read session history: %w", err)
+	}
+	defer f.Close()
+
+	var sessions []json.RawMessage
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 || !json.Valid(line) {
+			continue
+		}
+		sessions = append(sessions, json.RawMessage(append([]byte(nil), line...)))
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read session history: %w", err)
+	}
+
+	if len(sessions) > n {
+		sessions = sessions[len(sessions)-n:]
+	}
+	return sessions, nil
+}
@@ -0,0 +1,44 @@
+package auth
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+)
+
+// sharedTokenDir holds the login instead of the config directory when set
+// with UseSystemTokenStore
+var sharedTokenDir string
+
+// SystemTokenDir returns the system-wide login location shared by the users
+// of a ground station
+func SystemTokenDir() string {
+	if runtime.GOOS == "windows" {
+		return filepath.Join(os.Getenv("ProgramData"), "Aircast")
+	}
+	return "/var/lib/aircast"
+}
+
+// UseSystemTokenStore keeps the login in SystemTokenDir, so every user of a
+// shared ground station uses one login instead of each logging in. An
+// administrator creates the directory for a group of pilots; the rest of the
+// state stays per user.
+func UseSystemTokenStore() error {
+	dir := SystemTokenDir()
+	info, err := os.Stat(dir)
+	if os.IsNotExist(err) {
+		return fmt.Errorf("system token directory %s does not exist; an administrator needs to create it (see Sharing a ground station in the README)", dir)
+	}
+	if err != nil {
+		return fmt.Errorf("system token directory: %w", err)
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("system token location %s is not a directory", dir)
+	}
+	if runtime.GOOS != "windows" && info.Mode().Perm()&0007 != 0 {
+		return fmt.Errorf("system token directory %s is accessible to all users (mode %04o); restrict it with 'chmod o-rwx %s'", dir, info.Mode().Perm(), dir)
+	}
+	sharedTokenDir = dir
+	return nil
+}
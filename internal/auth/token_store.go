@@ -1,16 +1,28 @@
 package auth
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
+
+	"github.com/pavliha/aircast/aircast-cli/internal/store"
 )
 
 // TokenStore handles persistent storage of authentication tokens
 type TokenStore struct {
 	configDir string
+
+	// shared is set for the system-wide store, whose login the group of
+	// pilots reads and refreshes
+	shared bool
+
+	// clockSkew is how far the server clock is ahead of the local clock
+	clockSkew time.Duration
 }
 
 // StoredToken represents a persisted authentication token
@@ -21,21 +33,18 @@ type StoredToken struct {
 	ExpiresAt    time.Time `json:"expires_at"`
 	Scope        string    `json:"scope,omitempty"`
 	APIURL       string    `json:"api_url"`
+	Environment  string    `json:"environment,omitempty"` // prod, staging, dev, local or custom
 }
 
 // NewTokenStore creates a new token store
 func NewTokenStore() (*TokenStore, error) {
-	// Use ~/.aircast for config directory
-	homeDir, err := os.UserHomeDir()
-	if err != nil {
-		return nil, fmt.Errorf("failed to get home directory: %w", err)
+	if sharedTokenDir != "" {
+		return &TokenStore{configDir: sharedTokenDir, shared: true}, nil
 	}
 
-	configDir := filepath.Join(homeDir, ".aircast")
-
-	// Create directory if it doesn't exist
-	if err := os.MkdirAll(configDir, 0700); err != nil {
-		return nil, fmt.Errorf("failed to create config directory: %w", err)
+	configDir, err := store.ConfigDir()
+	if err != nil {
+		return nil, err
 	}
 
 	return &TokenStore{
@@ -48,23 +57,154 @@ func (ts *TokenStore) GetTokenPath() string {
 	return filepath.Join(ts.configDir, "token.json")
 }
 
-// SaveToken saves a token to disk
+// SaveToken saves a token to disk. A login to another API than the stored
+// one's is kept aside, for SwitchToken to bring back.
 func (ts *TokenStore) SaveToken(token *StoredToken) error {
-	tokenPath := ts.GetTokenPath()
+	unlock, err := store.LockFile(ts.GetTokenPath(), ts.perm())
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	stored, err := ts.LoadToken()
+	if err != nil {
+		return err
+	}
+	if stored != nil && stored.APIURL != "" && stored.APIURL != token.APIURL {
+		if err := ts.putAside(stored); err != nil {
+			return err
+		}
+	}
+	return ts.saveToken(token)
+}
+
+// SwitchToken makes the login to apiURL the stored token: the current login
+// to another API is kept aside and the one kept for apiURL, if any, comes
+// back. It returns the login now stored, nil if apiURL has none.
+func (ts *TokenStore) SwitchToken(apiURL string) (*StoredToken, error) {
+	unlock, err := store.LockFile(ts.GetTokenPath(), ts.perm())
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	stored, err := ts.LoadToken()
+	if err != nil {
+		return nil, err
+	}
+	if stored != nil && stored.APIURL == apiURL {
+		return stored, nil
+	}
+	if stored != nil && stored.APIURL != "" {
+		if err := ts.putAside(stored); err != nil {
+			return nil, err
+		}
+	}
+
+	token, err := ts.KeptToken(apiURL)
+	if err != nil {
+		return nil, err
+	}
+	if token == nil {
+		return nil, ts.DeleteToken()
+	}
+	if err := ts.saveToken(token); err != nil {
+		return nil, err
+	}
+	_ = os.Remove(ts.asidePath(apiURL))
+	return token, nil
+}
+
+// KeptToken returns the login kept aside for apiURL, nil if there is none
+func (ts *TokenStore) KeptToken(apiURL string) (*StoredToken, error) {
+	data, err := os.ReadFile(ts.asidePath(apiURL))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read kept token: %w", err)
+	}
+	var token StoredToken
+	if err := json.Unmarshal(data, &token); err != nil {
+		return nil, fmt.Errorf("failed to parse kept token: %w", err)
+	}
+	return &token, nil
+}
 
+// putAside keeps a login to another API; the caller holds the token lock
+func (ts *TokenStore) putAside(token *StoredToken) error {
+	dirPerm := os.FileMode(0700)
+	if ts.shared {
+		dirPerm = 0770
+	}
+	if err := os.MkdirAll(filepath.Dir(ts.asidePath(token.APIURL)), dirPerm); err != nil {
+		return fmt.Errorf("failed to create token directory: %w", err)
+	}
 	data, err := json.MarshalIndent(token, "", "  ")
 	if err != nil {
 		return fmt.Errorf("failed to marshal token: %w", err)
 	}
+	if err := store.WriteFileAtomic(ts.asidePath(token.APIURL), data, ts.perm()); err != nil {
+		return fmt.Errorf("failed to keep token for %s: %w", token.APIURL, err)
+	}
+	return nil
+}
 
-	// Write with restrictive permissions (only user can read/write)
-	if err := os.WriteFile(tokenPath, data, 0600); err != nil {
-		return fmt.Errorf("failed to write token file: %w", err)
+// asidePath is where the login to apiURL is kept while another is stored
+func (ts *TokenStore) asidePath(apiURL string) string {
+	sum := sha256.Sum256([]byte(strings.TrimRight(apiURL, "/")))
+	return filepath.Join(ts.configDir, "tokens", hex.EncodeToString(sum[:8])+".json")
+}
+
+// UpdateToken replaces the stored token with the one update returns, holding
+// the token file's lock so another aircast-cli can't refresh or replace it in
+// between. update gets the stored token, nil if there is none; returning it
+// unchanged leaves the file alone.
+func (ts *TokenStore) UpdateToken(update func(stored *StoredToken) (*StoredToken, error)) (*StoredToken, error) {
+	unlock, err := store.LockFile(ts.GetTokenPath(), ts.perm())
+	if err != nil {
+		return nil, err
 	}
+	defer unlock()
 
+	stored, err := ts.LoadToken()
+	if err != nil {
+		return nil, err
+	}
+	token, err := update(stored)
+	if err != nil || token == stored {
+		return token, err
+	}
+	if err := ts.saveToken(token); err != nil {
+		return nil, err
+	}
+	return token, nil
+}
+
+// saveToken writes the token file; the caller holds its lock
+func (ts *TokenStore) saveToken(token *StoredToken) error {
+	data, err := json.MarshalIndent(token, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal token: %w", err)
+	}
+
+	// Replace rather than overwrite, so other processes never read a partial token
+	if err := store.WriteFileAtomic(ts.GetTokenPath(), data, ts.perm()); err != nil {
+		return fmt.Errorf("failed to write token file: %w", err)
+	}
 	return nil
 }
 
+// perm returns the token file's permissions: only the user can read and write
+// it, except the system-wide file, which the directory's group shares so
+// every pilot can refresh it
+func (ts *TokenStore) perm() os.FileMode {
+	if ts.shared {
+		return 0660
+	}
+	return 0600
+}
+
 // LoadToken loads a token from disk
 func (ts *TokenStore) LoadToken() (*StoredToken, error) {
 	tokenPath := ts.GetTokenPath()
@@ -96,6 +236,17 @@ func (ts *TokenStore) DeleteToken() error {
 	return nil
 }
 
+// SetClockSkew sets the measured offset of the server clock from the local
+// clock, so expiry is judged in server time rather than by a wrong local clock
+func (ts *TokenStore) SetClockSkew(skew time.Duration) {
+	ts.clockSkew = skew
+}
+
+// Now returns the current time as seen by the server
+func (ts *TokenStore) Now() time.Time {
+	return time.Now().Add(ts.clockSkew)
+}
+
 // IsTokenValid checks if a token is still valid
 func (ts *TokenStore) IsTokenValid(token *StoredToken) bool {
 	if token == nil {
@@ -103,5 +254,5 @@ func (ts *TokenStore) IsTokenValid(token *StoredToken) bool {
 	}
 
 	// Check if token has expired (with 5 minute buffer)
-	return time.Now().Before(token.ExpiresAt.Add(-5 * time.Minute))
+	return ts.Now().Before(token.ExpiresAt.Add(-5 * time.Minute))
 }
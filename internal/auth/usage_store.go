@@ -0,0 +1,87 @@
+package auth
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/pavliha/aircast/aircast-cli/internal/store"
+)
+
+// UsageStore tracks bridge data usage per calendar day across sessions
+type UsageStore struct {
+	configDir string
+}
+
+// DailyUsage is the data transferred on one day
+type DailyUsage struct {
+	Date  string `json:"date"` // Local date, YYYY-MM-DD
+	Bytes uint64 `json:"bytes"`
+}
+
+// NewUsageStore creates a new usage store
+func NewUsageStore() (*UsageStore, error) {
+	configDir, err := store.ConfigDir()
+	if err != nil {
+		return nil, err
+	}
+
+	return &UsageStore{
+		configDir: configDir,
+	}, nil
+}
+
+// GetUsagePath returns the path to the usage file
+func (us *UsageStore) GetUsagePath() string {
+	return filepath.Join(us.configDir, "usage.json")
+}
+
+// today returns the local date key
+func today() string {
+	return time.Now().Format("2006-01-02")
+}
+
+// Today returns the bytes recorded for the current day
+func (us *UsageStore) Today() (uint64, error) {
+	data, err := os.ReadFile(us.GetUsagePath())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return 0, nil
+		}
+		return 0, fmt.Errorf("failed to read usage file: %w", err)
+	}
+
+	var usage DailyUsage
+	if err := json.Unmarshal(data, &usage); err != nil {
+		return 0, fmt.Errorf("failed to parse usage file: %w", err)
+	}
+
+	if usage.Date != today() {
+		return 0, nil
+	}
+	return usage.Bytes, nil
+}
+
+// Add records bytes transferred today
+func (us *UsageStore) Add(n uint64) error {
+	used, err := us.Today()
+	if err != nil {
+		// A corrupt file shouldn't block accounting; start the day over
+		used = 0
+	}
+
+	data, err := json.MarshalIndent(DailyUsage{Date: today(), Bytes: used + n}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal usage: %w", err)
+	}
+
+	// Rewritten every few seconds while the bridge runs; a crash mid-write
+	// must not lose the day's count
+	if err := store.WriteFileAtomic(us.GetUsagePath(), data, 0600); err != nil {
+		return fmt.Errorf("failed to write usage file: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,139 @@
+package cli
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// AIMD tuning for adaptive rate limiting
+const (
+	adaptInterval     = 2 * time.Second // How often the link is evaluated
+	adaptPingInterval = 2 * time.Second // RTT sampling interval while adapting
+	adaptMinRate      = 1.0             // Hz, never limit below this
+	adaptIncrease     = 1.0             // Hz added per healthy interval
+	adaptDecrease     = 0.5             // Rate multiplier on congestion
+	adaptLossLimit    = 0.05            // Interval loss rate treated as congestion
+	adaptRTTFactor    = 2.0             // Smoothed RTT above this multiple of the baseline is congestion...
+	adaptRTTMargin    = 150 * time.Millisecond
+	// ...provided it is also at least this much above the baseline, so fast links don't flap
+)
+
+// adaptiveFilter caps every message stream of a profile at rate Hz
+func adaptiveFilter(name string, p BandwidthProfile, rate float64) (*rateFilter, error) {
+	capped := p
+	if capped.MaxRate <= 0 || capped.MaxRate > rate {
+		capped.MaxRate = rate
+	}
+	capped.Rates = make(map[string]float64, len(p.Rates))
+	for msg, hz := range p.Rates {
+		capped.Rates[msg] = math.Min(hz, rate)
+	}
+	return newRateFilter(fmt.Sprintf("%s (adaptive %.0f Hz)", name, rate), capped)
+}
+
+// adaptRate adjusts the downlink rate limit to the measured link capacity:
+// it halves the rate when latency climbs or frames are lost and adds 1 Hz
+// back per healthy interval, up to the configured maximum (AIMD)
+func (b *Bridge) adaptRate() {
+
+	ticker := time.NewTicker(adaptInterval)
+	defer ticker.Stop()
+
+	maxRate := b.config.AdaptiveMaxRate
+	rate := maxRate
+	lowered := false // Stream rates lowered at the source
+	last := b.stats.Snapshot()
+	lastBytes := last.Downlink.Bytes
+
+	for {
+		select {
+		case <-b.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		// The data budget's reduced profile takes precedence
+		if b.budgetExceeded.Load() {
+			continue
+		}
+
+		now := b.stats.Snapshot()
+		link := b.link.Snapshot()
+
+		frames := now.Downlink.Frames - last.Downlink.Frames
+		lost := now.Downlink.Lost - last.Downlink.Lost
+		throughput := float64(now.Downlink.Bytes-lastBytes) / adaptInterval.Seconds()
+		last, lastBytes = now, now.Downlink.Bytes
+
+		var lossRate float64
+		if frames+lost > 0 {
+			lossRate = float64(lost) / float64(frames+lost)
+		}
+		rttHigh := link.RTTSamples > 1 &&
+			float64(link.RTT) > adaptRTTFactor*float64(link.RTTMin) &&
+			link.RTT-link.RTTMin > adaptRTTMargin
+		congested := lossRate > adaptLossLimit || rttHigh
+
+		previous := rate
+		if congested {
+			rate = math.Max(adaptMinRate, math.Floor(rate*adaptDecrease))
+		} else {
+			rate = math.Min(maxRate, rate+adaptIncrease)
+		}
+		if rate == previous {
+			continue
+		}
+
+		fields := log.Fields{
+			"rate_hz":     rate,
+			"rtt":         link.RTT.Round(time.Millisecond),
+			"rtt_base":    link.RTTMin.Round(time.Millisecond),
+			"loss_pct":    fmt.Sprintf("%.1f", lossRate*100),
+			"throughput":  FormatBytes(uint64(throughput)) + "/s",
+			"max_rate_hz": maxRate,
+		}
+		if rate >= maxRate {
+			b.filter.Store(b.profileFilter)
+			b.logger.WithFields(fields).Info("Link recovered, full telemetry rates restored")
+			if lowered && !b.restoreStreamRates() {
+				b.logger.Info("No ground station stream rates to restore; reconnect the ground station to restore the vehicle's stream rates")
+			}
+			lowered = false
+			continue
+		}
+
+		filter, err := adaptiveFilter(b.profileName(), b.profile, rate)
+		if err != nil {
+			b.logger.WithError(err).Error("Failed to build adaptive filter")
+			continue
+		}
+		b.filter.Store(filter)
+		if !congested {
+			b.logger.WithFields(fields).Info("Link healthy, increasing telemetry rate")
+			continue
+		}
+		b.logger.WithFields(fields).Warn("Link congested, reducing telemetry rate")
+
+		// Throttle at the source too, so less data crosses the congested
+		// link. Rates are only ever lowered there; the ground station's
+		// own rates are restored once the link has recovered.
+		streamRate := rate
+		if b.profile.MaxRate > 0 && b.profile.MaxRate < streamRate {
+			streamRate = b.profile.MaxRate
+		}
+		if b.lowerStreamRate(uint16(streamRate)) {
+			lowered = true
+		}
+	}
+}
+
+// profileName returns the configured bandwidth profile name
+func (b *Bridge) profileName() string {
+	if b.config.BandwidthProfile == "" {
+		return ProfileFull
+	}
+	return b.config.BandwidthProfile
+}
@@ -0,0 +1,172 @@
+package cli
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pavliha/aircast/aircast-cli/internal/mavlink"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	alarmInterval   = 1 * time.Second // How often alarms are evaluated
+	alarmClearDelay = 5 * time.Second // How long a condition must stay false before its alarm clears
+)
+
+// alarmMetrics extract the values alarms can watch; ok is false while the
+// vehicle hasn't reported the value
+var alarmMetrics = map[string]func(t *mavlink.Telemetry) (value float64, ok bool){
+	"battery": func(t *mavlink.Telemetry) (float64, bool) {
+		return float64(t.Battery), t.HaveBattery && t.Battery >= 0
+	},
+	"hdop": func(t *mavlink.Telemetry) (float64, bool) {
+		return t.HDOP, t.HaveGPS && t.HDOP >= 0
+	},
+	"sats": func(t *mavlink.Telemetry) (float64, bool) {
+		return float64(t.Satellites), t.HaveGPS
+	},
+	"rssi": func(t *mavlink.Telemetry) (float64, bool) {
+		return float64(t.RSSI), t.HaveRadio && t.RSSI != 255
+	},
+	"remrssi": func(t *mavlink.Telemetry) (float64, bool) {
+		return float64(t.RemoteRSSI), t.HaveRadio && t.RemoteRSSI != 255
+	},
+	"alt": func(t *mavlink.Telemetry) (float64, bool) {
+		return t.AltRelative, t.HavePosition
+	},
+	"speed": func(t *mavlink.Telemetry) (float64, bool) {
+		return t.GroundSpeed, t.HavePosition
+	},
+}
+
+// AlarmMetrics lists the values alarms can watch
+func AlarmMetrics() []string {
+	names := make([]string, 0, len(alarmMetrics))
+	for name := range alarmMetrics {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// AlarmRule raises an alarm when a telemetry value crosses a threshold
+type AlarmRule struct {
+	Metric    string
+	Op        string // "<", "<=", ">" or ">="
+	Threshold float64
+}
+
+// alarmPattern matches rules such as "battery<20" or "hdop > 2.5"
+var alarmPattern = regexp.MustCompile(`^\s*([a-z]+)\s*(<=|>=|<|>)\s*(-?[0-9.]+)\s*$`)
+
+// ParseAlarmRule parses a rule such as "battery<20"
+func ParseAlarmRule(s string) (AlarmRule, error) {
+	m := alarmPattern.FindStringSubmatch(strings.ToLower(s))
+	if m == nil {
+		return AlarmRule{}, fmt.Errorf("invalid alarm %q (expected e.g. battery<20)", s)
+	}
+	if _, ok := alarmMetrics[m[1]]; !ok {
+		return AlarmRule{}, fmt.Errorf("unknown alarm value %q (available: %s)", m[1], strings.Join(AlarmMetrics(), ", "))
+	}
+	threshold, err := strconv.ParseFloat(m[3], 64)
+	if err != nil {
+		return AlarmRule{}, fmt.Errorf("invalid alarm threshold %q", m[3])
+	}
+	return AlarmRule{Metric: m[1], Op: m[2], Threshold: threshold}, nil
+}
+
+// String returns the rule in the syntax ParseAlarmRule accepts
+func (r AlarmRule) String() string {
+	return fmt.Sprintf("%s%s%s", r.Metric, r.Op, strconv.FormatFloat(r.Threshold, 'f', -1, 64))
+}
+
+// triggered reports whether a value violates the rule
+func (r AlarmRule) triggered(value float64) bool {
+	switch r.Op {
+	case "<":
+		return value < r.Threshold
+	case "<=":
+		return value <= r.Threshold
+	case ">":
+		return value > r.Threshold
+	default:
+		return value >= r.Threshold
+	}
+}
+
+// AlarmEvent reports an alarm being raised or cleared
+type AlarmEvent struct {
+	Rule   AlarmRule
+	Value  float64
+	Raised bool // False when the alarm cleared
+	Time   time.Time
+}
+
+// alarmState tracks one rule between evaluations
+type alarmState struct {
+	active  bool
+	okSince time.Time // When the condition last became false while active
+}
+
+// watchAlarms evaluates the configured alarms against the decoded telemetry
+// and reports every change through Config.OnAlarm
+func (b *Bridge) watchAlarms() {
+
+	ticker := time.NewTicker(alarmInterval)
+	defer ticker.Stop()
+
+	states := make([]alarmState, len(b.config.Alarms))
+	for {
+		select {
+		case <-b.ctx.Done():
+			return
+		case now := <-ticker.C:
+			t, _ := b.Telemetry()
+			for i, rule := range b.config.Alarms {
+				value, ok := alarmMetrics[rule.Metric](&t)
+				if !ok {
+					continue
+				}
+				b.evaluateAlarm(&states[i], rule, value, now)
+			}
+		}
+	}
+}
+
+// evaluateAlarm raises an alarm as soon as its rule is violated and clears
+// it once the value has been back in range for alarmClearDelay, so values
+// hovering at the threshold don't flap
+func (b *Bridge) evaluateAlarm(state *alarmState, rule AlarmRule, value float64, now time.Time) {
+	switch {
+	case rule.triggered(value):
+		state.okSince = time.Time{}
+		if state.active {
+			return
+		}
+		state.active = true
+	case !state.active:
+		return
+	case state.okSince.IsZero():
+		state.okSince = now
+		return
+	case now.Sub(state.okSince) < alarmClearDelay:
+		return
+	default:
+		state.active = false
+	}
+
+	event := AlarmEvent{Rule: rule, Value: value, Raised: state.active, Time: now}
+	fields := log.Fields{"alarm": rule.String(), "value": value}
+	if event.Raised {
+		b.logger.WithFields(fields).Warn("Alarm raised")
+	} else {
+		b.logger.WithFields(fields).Info("Alarm cleared")
+	}
+	if b.config.OnAlarm != nil {
+		b.config.OnAlarm(event)
+	}
+}
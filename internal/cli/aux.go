@@ -0,0 +1,32 @@
+package cli
+
+import "bytes"
+
+// maxAuxLine bounds a partial uplink line buffered for an auxiliary channel
+const maxAuxLine = 64 * 1024
+
+// inspectAux frames companion data on an auxiliary channel as newline-delimited
+// records. Downlink messages are terminated with a newline if they lack one;
+// uplink data is cut at the last newline and the remainder kept until the
+// client completes the line. The returned slice is only valid until the next
+// call for the same stream.
+func (b *Bridge) inspectAux(stream *frameStream, dir Direction, data []byte) []byte {
+	if dir == Downlink {
+		if len(data) == 0 || data[len(data)-1] == '\n' {
+			return data
+		}
+		stream.out = append(append(stream.out[:0], data...), '\n')
+		return stream.out
+	}
+
+	stream.out = append(stream.out[:0], stream.partial...)
+	stream.out = append(stream.out, data...)
+
+	end := bytes.LastIndexByte(stream.out, '\n') + 1
+	stream.partial = append(stream.partial[:0], stream.out[end:]...)
+	if len(stream.partial) > maxAuxLine {
+		b.logger.WithField("bytes", len(stream.partial)).Warn("Discarding overlong auxiliary line")
+		stream.partial = stream.partial[:0]
+	}
+	return stream.out[:end]
+}
@@ -0,0 +1,312 @@
+package cli
+
+import (
+	"fmt"
+	"hash/maphash"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/pavliha/aircast/aircast-cli/internal/network"
+	log "github.com/sirupsen/logrus"
+)
+
+// Bond modes
+const (
+	// BondDuplicate keeps both links connected; each downlink message is
+	// forwarded from whichever link delivers it first
+	BondDuplicate = "duplicate"
+	// BondFailover connects the backup link only while the primary is down
+	BondFailover = "failover"
+)
+
+const (
+	bondDedupWindow = 5 * time.Second  // Longest delay between the two copies of a message
+	bondMinBackoff  = 2 * time.Second  // First wait after the backup link fails to connect
+	bondMaxBackoff  = 30 * time.Second // Longest wait between backup connection attempts
+)
+
+// BondConfig carries the device link over two local interfaces, e.g.
+// Ethernet and an LTE dongle, for sites with a flaky primary uplink
+type BondConfig struct {
+	Primary string // Interface of the main link, e.g. "eth0"
+	Backup  string // Interface of the second link, e.g. "wwan0"
+	Mode    string // BondDuplicate or BondFailover
+}
+
+// Enabled reports whether bonding is configured
+func (c BondConfig) Enabled() bool {
+	return c.Backup != ""
+}
+
+// ParseBond parses a "primary,backup" interface pair and a bond mode
+func ParseBond(interfaces, mode string) (BondConfig, error) {
+	primary, backup, ok := strings.Cut(interfaces, ",")
+	primary, backup = strings.TrimSpace(primary), strings.TrimSpace(backup)
+	if !ok || primary == "" || backup == "" || strings.Contains(backup, ",") {
+		return BondConfig{}, fmt.Errorf("invalid bond %q: want two interfaces, e.g. eth0,wwan0", interfaces)
+	}
+	if primary == backup {
+		return BondConfig{}, fmt.Errorf("invalid bond %q: interfaces must differ", interfaces)
+	}
+	if mode != BondDuplicate && mode != BondFailover {
+		return BondConfig{}, fmt.Errorf("unknown bond mode %q (expected %s or %s)", mode, BondDuplicate, BondFailover)
+	}
+	return BondConfig{Primary: primary, Backup: backup, Mode: mode}, nil
+}
+
+// BondStats summarizes the backup link
+type BondStats struct {
+	Interface  string `json:"interface"`
+	Mode       string `json:"mode"`
+	Connected  bool   `json:"connected"`
+	Connects   uint64 `json:"connects"`
+	Messages   uint64 `json:"messages"`   // Downlink messages the backup delivered first
+	Duplicates uint64 `json:"duplicates"` // Copies dropped because the other link was faster
+	Uplink     uint64 `json:"uplink"`     // Uplink messages sent over the backup
+}
+
+// bondLink is the second WebSocket to the device, over the backup interface
+type bondLink struct {
+	config BondConfig
+	dial   network.DialFunc
+	logger *log.Entry
+
+	// conn is the backup connection, nil while disconnected; writeMu
+	// serializes writes to it
+	connMu  sync.Mutex
+	conn    *websocket.Conn
+	writeMu sync.Mutex
+
+	// recvMu serializes downlink forwarding from the two links, which share
+	// the frame parser, and guards seen
+	recvMu    sync.Mutex
+	seed      maphash.Seed
+	seen      map[uint64]bondCopy
+	lastPrune time.Time
+
+	// wake is signalled when the primary link drops
+	wake chan struct{}
+
+	connects   atomic.Uint64
+	messages   atomic.Uint64
+	duplicates atomic.Uint64
+	uplink     atomic.Uint64
+}
+
+// bondCopy records which link first delivered a message, and when
+type bondCopy struct {
+	backup bool
+	at     time.Time
+}
+
+// newBondLink prepares the backup link; Bridge.Start connects it
+func newBondLink(config BondConfig, dial network.DialFunc, logger *log.Entry) *bondLink {
+	return &bondLink{
+		config: config,
+		dial:   dial,
+		logger: logger.WithFields(log.Fields{"component": "bond", "interface": config.Backup}),
+		seed:   maphash.MakeSeed(),
+		seen:   make(map[uint64]bondCopy),
+		wake:   make(chan struct{}, 1),
+	}
+}
+
+// BondStats returns statistics of the backup link, zero without bonding
+func (b *Bridge) BondStats() BondStats {
+	if b.bond == nil {
+		return BondStats{}
+	}
+	l := b.bond
+	l.connMu.Lock()
+	connected := l.conn != nil
+	l.connMu.Unlock()
+	return BondStats{
+		Interface:  l.config.Backup,
+		Mode:       l.config.Mode,
+		Connected:  connected,
+		Connects:   l.connects.Load(),
+		Messages:   l.messages.Load(),
+		Duplicates: l.duplicates.Load(),
+		Uplink:     l.uplink.Load(),
+	}
+}
+
+// receiveDownlink forwards a message from either link. With bonding, copies
+// of a message arriving over both links are forwarded once.
+func (b *Bridge) receiveDownlink(msgType int, data []byte, backup bool) {
+	if b.bond == nil {
+		b.forwardDownlink(msgType, data)
+		return
+	}
+
+	l := b.bond
+	l.recvMu.Lock()
+	defer l.recvMu.Unlock()
+	if l.duplicate(data, backup, time.Now()) {
+		l.duplicates.Add(1)
+		return
+	}
+	if backup {
+		l.messages.Add(1)
+	}
+	b.forwardDownlink(msgType, data)
+}
+
+// duplicate reports whether the other link delivered the same message within
+// the window; caller must hold recvMu. MAVLink sequence numbers make repeats
+// of a message on one link differ, so identical bytes mean the same message.
+func (l *bondLink) duplicate(data []byte, backup bool, now time.Time) bool {
+	if now.Sub(l.lastPrune) >= bondDedupWindow {
+		for k, c := range l.seen {
+			if now.Sub(c.at) >= bondDedupWindow {
+				delete(l.seen, k)
+			}
+		}
+		l.lastPrune = now
+	}
+
+	key := maphash.Bytes(l.seed, data)
+	if c, ok := l.seen[key]; ok && c.backup != backup && now.Sub(c.at) < bondDedupWindow {
+		// Each message arrives at most twice
+		delete(l.seen, key)
+		return true
+	}
+	l.seen[key] = bondCopy{backup: backup, at: now}
+	return false
+}
+
+// write sends an uplink message over the backup link
+func (l *bondLink) write(msgType int, data []byte) error {
+	l.connMu.Lock()
+	conn := l.conn
+	l.connMu.Unlock()
+	if conn == nil {
+		return fmt.Errorf("backup link not connected")
+	}
+
+	l.writeMu.Lock()
+	defer l.writeMu.Unlock()
+	if err := conn.WriteMessage(msgType, data); err != nil {
+		return err
+	}
+	l.uplink.Add(1)
+	return nil
+}
+
+// primaryLost wakes a failover backup link
+func (l *bondLink) primaryLost() {
+	select {
+	case l.wake <- struct{}{}:
+	default:
+	}
+}
+
+// primaryRestored disconnects a failover backup link
+func (l *bondLink) primaryRestored() {
+	if l.config.Mode != BondFailover {
+		return
+	}
+	l.connMu.Lock()
+	defer l.connMu.Unlock()
+	if l.conn != nil {
+		_ = l.conn.Close()
+	}
+}
+
+// close disconnects the backup link for shutdown
+func (l *bondLink) close() {
+	l.connMu.Lock()
+	defer l.connMu.Unlock()
+	if l.conn != nil {
+		_ = l.conn.Close()
+	}
+}
+
+// runBond keeps the backup link connected: always in duplicate mode, and
+// while the primary is down in failover mode
+func (b *Bridge) runBond() {
+
+	l := b.bond
+	backoff := bondMinBackoff
+	failing := false
+	for {
+		if l.config.Mode == BondFailover && !b.linkDown.Load() {
+			select {
+			case <-b.ctx.Done():
+				return
+			case <-l.wake:
+			}
+			continue
+		}
+
+		conn, err := b.dialWebSocketVia(b.ctx, l.dial)
+		if err != nil {
+			if !failing {
+				l.logger.WithError(err).Warn("Backup link failed to connect, retrying")
+				failing = true
+			}
+			select {
+			case <-b.ctx.Done():
+				return
+			case <-time.After(backoff):
+			}
+			backoff = min(backoff*2, bondMaxBackoff)
+			continue
+		}
+		failing = false
+		backoff = bondMinBackoff
+
+		l.connMu.Lock()
+		if b.ctx.Err() != nil {
+			l.connMu.Unlock()
+			_ = conn.Close()
+			return
+		}
+		l.conn = conn
+		l.connMu.Unlock()
+		l.connects.Add(1)
+		l.logger.Info("Backup link connected")
+		if l.config.Mode == BondFailover && !b.linkDown.Load() {
+			// The primary came back while this link was connecting
+			l.primaryRestored()
+		}
+
+		err = b.readBond(conn)
+
+		l.connMu.Lock()
+		l.conn = nil
+		l.connMu.Unlock()
+		_ = conn.Close()
+
+		if b.ctx.Err() != nil {
+			return
+		}
+		if l.config.Mode == BondFailover && !b.linkDown.Load() {
+			l.logger.Info("Primary link restored, backup link disconnected")
+			continue
+		}
+		l.logger.WithError(err).Warn("Backup link lost")
+	}
+}
+
+// readBond forwards the backup link's downlink until it fails
+func (b *Bridge) readBond(conn *websocket.Conn) error {
+	for {
+		bufp, msgType, err := readMessage(conn)
+		if err != nil {
+			return err
+		}
+		// The primary link handles moves; this one follows on its next dial
+		if msgType == websocket.TextMessage && !b.config.Aux {
+			if _, ok := messageMove(*bufp); ok {
+				putBuffer(bufp)
+				continue
+			}
+		}
+		b.receiveDownlink(msgType, *bufp, true)
+		putBuffer(bufp)
+	}
+}
@@ -0,0 +1,350 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/pavliha/aircast/aircast-cli/internal/network"
+	"github.com/pavliha/aircast/aircast-cli/internal/term"
+)
+
+// FailureClass is why the connection to the device failed. Each class has
+// its own circuit breaker policy, so that e.g. an expired token isn't met
+// with waits meant for a device that isn't ready.
+type FailureClass int
+
+const (
+	// FailureNoData is a connection that ended without delivering data,
+	// usually because the device's MAVLink proxy isn't running
+	FailureNoData FailureClass = iota
+	// FailureAuth is a handshake the server rejected (HTTP 401 or 403)
+	// because the token expired or was revoked
+	FailureAuth
+	// FailureNoRoute is an API that can't be reached, or a connection that
+	// broke without a close frame
+	FailureNoRoute
+	// FailureClosed is a working connection the server closed cleanly, e.g.
+	// while restarting for maintenance
+	FailureClosed
+
+	failureClasses = iota
+)
+
+// String returns the failure class name
+func (c FailureClass) String() string {
+	switch c {
+	case FailureAuth:
+		return "auth"
+	case FailureNoRoute:
+		return "no_route"
+	case FailureClosed:
+		return "closed"
+	default:
+		return "no_data"
+	}
+}
+
+// breakerPolicy is how the circuit breaker reacts to one class of failure
+type breakerPolicy struct {
+	threshold int           // Consecutive failures that open the circuit
+	wait      time.Duration // How long the circuit stays open
+}
+
+// breakerPolicies are the policies of each failure class
+var breakerPolicies = [failureClasses]breakerPolicy{
+	FailureNoData:  {threshold: 3, wait: 30 * time.Second},
+	FailureAuth:    {threshold: 1, wait: 5 * time.Minute}, // Ends early when SetAuthToken brings a new login
+	FailureNoRoute: {threshold: 3, wait: 15 * time.Second},
+	FailureClosed:  {threshold: 5, wait: 5 * time.Second},
+}
+
+const (
+	// reconnectPause is the pause after a failed reconnect while the circuit is closed
+	reconnectPause = 2 * time.Second
+	// probeTimeout bounds a half-open probe
+	probeTimeout = 5 * time.Second
+	// probeDataWait is how long a probe of a device without data waits for some
+	probeDataWait = 3 * time.Second
+)
+
+// HandshakeError is a WebSocket handshake the server answered with an HTTP
+// error status
+type HandshakeError struct {
+	StatusCode int
+	Err        error
+}
+
+func (e *HandshakeError) Error() string {
+	return fmt.Sprintf("HTTP %d: %v", e.StatusCode, e.Err)
+}
+
+func (e *HandshakeError) Unwrap() error {
+	return e.Err
+}
+
+// classifyFailure works out why a reconnect or an established connection
+// failed; gotData tells whether the connection delivered any data
+func classifyFailure(err error, gotData bool) FailureClass {
+	// A stalled connection delivered data before its path went half-open
+	if errors.Is(err, ErrStalled) {
+		return FailureNoRoute
+	}
+
+	var hs *HandshakeError
+	if errors.As(err, &hs) {
+		if hs.StatusCode == http.StatusUnauthorized || hs.StatusCode == http.StatusForbidden {
+			return FailureAuth
+		}
+		return FailureNoRoute
+	}
+
+	var ce *websocket.CloseError
+	if errors.As(err, &ce) {
+		if gotData && (ce.Code == websocket.CloseNormalClosure || ce.Code == websocket.CloseGoingAway) {
+			return FailureClosed
+		}
+		return FailureNoData
+	}
+	return FailureNoRoute
+}
+
+// recordFailure records a connection failure and opens the circuit when the
+// failures of its class reach the class's threshold
+func (b *Bridge) recordFailure(class FailureClass, err error) {
+	b.circuitMu.Lock()
+	defer b.circuitMu.Unlock()
+
+	b.failures[class]++
+	b.lastFailureTime = time.Now()
+
+	policy := breakerPolicies[class]
+	if b.failures[class] < policy.threshold || b.circuitState == "open" {
+		return
+	}
+
+	reopened := b.circuitState == "half-open" && b.circuitClass == class
+	b.circuitState = "open"
+	b.circuitClass = class
+	b.circuitOpenUntil = time.Now().Add(policy.wait)
+	b.diag.CircuitOpened(class, b.failures[class])
+	b.logger.WithField("class", class.String()).WithField("failures", b.failures[class]).Warn("Circuit breaker opened")
+	select {
+	case <-b.retryNow: // A request from before the circuit opened
+	default:
+	}
+
+	if class == FailureNoData && b.config.OnCircuit != nil {
+		b.config.OnCircuit(true, b.circuitOpenUntil)
+		return
+	}
+	if reopened {
+		fmt.Fprintf(b.config.Output, "%sStill not connected. Retrying in %v...\n\n", term.Symbol("⏸️  ", ""), policy.wait)
+		return
+	}
+
+	warn := term.Symbol("⚠️  ", "Warning: ")
+	switch class {
+	case FailureAuth:
+		var hs *HandshakeError
+		errors.As(err, &hs)
+		fmt.Fprintf(b.config.Output, "\n%sThe server rejected the login (HTTP %d).\n", warn, hs.StatusCode)
+		fmt.Fprintf(b.config.Output, "   Run 'aircast-cli login' in another terminal; the bridge reconnects with the new login.\n\n")
+	case FailureNoRoute:
+		fmt.Fprintf(b.config.Output, "\n%sCan't reach Aircast: %v\n", warn, err)
+		fmt.Fprintf(b.config.Output, "   Check this computer's internet connection. Retrying in %v...\n\n", policy.wait)
+	case FailureClosed:
+		fmt.Fprintf(b.config.Output, "\n%sThe server keeps closing the connection.\n", warn)
+		fmt.Fprintf(b.config.Output, "   Retrying in %v...\n\n", policy.wait)
+	default:
+		fmt.Fprintf(b.config.Output, "\n%sDevice MAVLink proxy is not running.\n", warn)
+		fmt.Fprintf(b.config.Output, "   Please start the aircast-agent on your device.\n")
+		fmt.Fprintf(b.config.Output, "   Retrying in %v...\n\n", policy.wait)
+	}
+}
+
+// waitCircuit pauses while the circuit is open. When the wait is over, the
+// circuit is half-open: a probe checks that the cause of the failures is gone
+// before the bridge reconnects and announces it; if not, the circuit quietly
+// opens again. It reports whether it waited, and returns early when the
+// bridge stops.
+func (b *Bridge) waitCircuit() bool {
+	b.circuitMu.Lock()
+	open, class, until := b.circuitState == "open", b.circuitClass, b.circuitOpenUntil
+	b.circuitMu.Unlock()
+	if !open {
+		return false
+	}
+
+	quiet := b.config.OnCircuit != nil && class == FailureNoData
+	if waitTime := time.Until(until); waitTime > 0 && !quiet && class == FailureNoData {
+		fmt.Fprintf(b.config.Output, "\n%sDevice not ready. Waiting %v before retry...\n\n", term.Symbol("⏸️  ", ""), waitTime.Round(time.Second))
+	}
+
+	for {
+		// Sleep with context cancellation support
+		select {
+		case <-b.ctx.Done():
+			return true
+		case <-time.After(time.Until(until)):
+		case <-b.retryNow:
+		}
+
+		b.circuitMu.Lock()
+		b.circuitState = "half-open"
+		b.circuitMu.Unlock()
+		err := b.probeCircuit(class)
+		if err == nil {
+			break
+		}
+		if b.ctx.Err() != nil {
+			return true
+		}
+		until = b.reopenCircuit(err)
+	}
+
+	if !quiet {
+		fmt.Fprintln(b.config.Output, term.Symbol("🔄 ", "")+"Retrying connection...")
+	}
+	return true
+}
+
+// reopenCircuit opens a half-open circuit again after a failed probe,
+// without announcing it again, and returns when it closes
+func (b *Bridge) reopenCircuit(err error) time.Time {
+	b.circuitMu.Lock()
+	defer b.circuitMu.Unlock()
+
+	policy := breakerPolicies[b.circuitClass]
+	b.circuitState = "open"
+	b.circuitOpenUntil = time.Now().Add(policy.wait)
+	b.logger.WithError(err).WithField("class", b.circuitClass.String()).Info("Probe failed, circuit breaker stays open")
+	if b.circuitClass == FailureNoData && b.config.OnCircuit != nil {
+		b.config.OnCircuit(true, b.circuitOpenUntil)
+	}
+	return b.circuitOpenUntil
+}
+
+// circuitIsOpen reports whether the circuit breaker holds off reconnects
+func (b *Bridge) circuitIsOpen() bool {
+	b.circuitMu.Lock()
+	defer b.circuitMu.Unlock()
+	return b.circuitState == "open"
+}
+
+// probeCircuit checks whether the cause of the failures of class that
+// opened the circuit is gone: a HEAD request when the API couldn't be
+// reached, otherwise a short WebSocket connection that, for a device that
+// sent no data, has to deliver some or stay open for a moment
+func (b *Bridge) probeCircuit(class FailureClass) error {
+	dial := b.dial
+	if b.routes != nil {
+		dial = b.routes.activeDial()
+	}
+
+	if class == FailureNoRoute {
+		return b.probeAPI(dial)
+	}
+
+	conn, err := b.dialWebSocketVia(b.ctx, dial)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if class == FailureNoData {
+		_ = conn.SetReadDeadline(time.Now().Add(probeDataWait))
+		if _, _, err := conn.NextReader(); err != nil {
+			var ne interface{ Timeout() bool }
+			if !errors.As(err, &ne) || !ne.Timeout() {
+				return err
+			}
+			// Still open without data: the device may just be quiet
+		}
+	}
+	_ = conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""), time.Now().Add(time.Second))
+	return nil
+}
+
+// probeAPI checks that the API host answers HTTP at all
+func (b *Bridge) probeAPI(dial network.DialFunc) error {
+	u, err := url.Parse(b.currentURL())
+	if err != nil {
+		return err
+	}
+	if u.Scheme == "wss" {
+		u.Scheme = "https"
+	} else {
+		u.Scheme = "http"
+	}
+	u.Path, u.RawQuery = "/", ""
+
+	ctx, cancel := context.WithTimeout(b.ctx, probeTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, u.String(), nil)
+	if err != nil {
+		return err
+	}
+	client := &http.Client{Transport: &http.Transport{
+		Proxy:       http.ProxyFromEnvironment,
+		DialContext: dial,
+	}}
+	defer client.CloseIdleConnections()
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	_ = resp.Body.Close()
+	return nil
+}
+
+// reconnectFailed records a failed reconnect and pauses before the next one
+func (b *Bridge) reconnectFailed(err error) {
+	if b.ctx.Err() != nil {
+		return // Shut down while reconnecting
+	}
+	b.logger.WithError(err).Error("Failed to reconnect WebSocket")
+	b.recordFailure(classifyFailure(err, false), err)
+	if b.waitCircuit() {
+		return
+	}
+	select {
+	case <-b.ctx.Done():
+	case <-time.After(reconnectPause):
+	case <-b.retryNow:
+	}
+}
+
+// RetryNow ends the wait of an open circuit breaker and reconnects at once,
+// e.g. after the agent was restarted
+func (b *Bridge) RetryNow() {
+	select {
+	case b.retryNow <- struct{}{}:
+	default:
+	}
+}
+
+// resetCircuit resets the circuit breaker after data arrived
+func (b *Bridge) resetCircuit() {
+	b.connData.Store(true)
+
+	b.circuitMu.Lock()
+	defer b.circuitMu.Unlock()
+
+	if b.failures != ([failureClasses]int{}) {
+		if b.config.OnCircuit != nil && b.circuitClass == FailureNoData {
+			if b.circuitState != "closed" {
+				b.config.OnCircuit(false, time.Time{})
+			}
+		} else {
+			fmt.Fprintf(b.config.Output, "\n%sConnected! MAVLink data is flowing.\n\n", term.Symbol("✅ ", ""))
+		}
+	}
+	b.failures = [failureClasses]int{}
+	b.circuitState = "closed"
+}
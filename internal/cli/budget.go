@@ -0,0 +1,207 @@
+package cli
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pavliha/aircast/aircast-cli/internal/term"
+	log "github.com/sirupsen/logrus"
+)
+
+// budgetCheckInterval is how often data usage is checked against the budget
+const budgetCheckInterval = 5 * time.Second
+
+// budgetWarnLevels are the fractions of the budget at which the user is warned
+var budgetWarnLevels = []float64{0.5, 0.8}
+
+// budgetProfile is the bandwidth profile switched to once the budget is exceeded
+const budgetProfile = ProfileCellularMinimal
+
+// GCS identity used for messages the bridge sends to the vehicle itself
+const (
+	bridgeSysID  = 255
+	bridgeCompID = 190 // MAV_COMP_ID_MISSIONPLANNER
+)
+
+// DataBudget limits data transferred over the WebSocket
+type DataBudget struct {
+	Limit uint64 // Bytes, 0 = unlimited
+	Daily bool   // Per calendar day across sessions, otherwise per session
+}
+
+// Enabled reports whether a limit is set
+func (d DataBudget) Enabled() bool {
+	return d.Limit > 0
+}
+
+func (d DataBudget) String() string {
+	if d.Daily {
+		return FormatBytes(d.Limit) + "/day"
+	}
+	return FormatBytes(d.Limit) + "/session"
+}
+
+// byteUnits are the accepted size suffixes, longest first
+var byteUnits = []struct {
+	suffix string
+	size   uint64
+}{
+	{"GB", 1 << 30},
+	{"MB", 1 << 20},
+	{"KB", 1 << 10},
+	{"G", 1 << 30},
+	{"M", 1 << 20},
+	{"K", 1 << 10},
+	{"B", 1},
+}
+
+// ParseDataBudget parses a budget such as "500MB/day", "2GB/session" or "500MB" (per day)
+func ParseDataBudget(s string) (DataBudget, error) {
+	size, period, _ := strings.Cut(strings.TrimSpace(s), "/")
+
+	budget := DataBudget{Daily: true}
+	switch strings.ToLower(period) {
+	case "", "day":
+	case "session":
+		budget.Daily = false
+	default:
+		return DataBudget{}, fmt.Errorf("invalid budget period %q (use day or session)", period)
+	}
+
+	limit, err := ParseBytes(size)
+	if err != nil {
+		return DataBudget{}, err
+	}
+	budget.Limit = limit
+
+	return budget, nil
+}
+
+// ParseBytes parses a size such as "500MB", "1.5G" or "4096"
+func ParseBytes(s string) (uint64, error) {
+	s = strings.ToUpper(strings.TrimSpace(s))
+
+	multiplier := uint64(1)
+	for _, unit := range byteUnits {
+		if strings.HasSuffix(s, unit.suffix) {
+			s = strings.TrimSpace(strings.TrimSuffix(s, unit.suffix))
+			multiplier = unit.size
+			break
+		}
+	}
+
+	value, err := strconv.ParseFloat(s, 64)
+	if err != nil || value < 0 {
+		return 0, fmt.Errorf("invalid size %q", s)
+	}
+
+	return uint64(value * float64(multiplier)), nil
+}
+
+// FormatBytes formats a byte count with a binary unit, e.g. "1.5 MB"
+func FormatBytes(n uint64) string {
+	switch {
+	case n >= 1<<30:
+		return fmt.Sprintf("%.1f GB", float64(n)/(1<<30))
+	case n >= 1<<20:
+		return fmt.Sprintf("%.1f MB", float64(n)/(1<<20))
+	case n >= 1<<10:
+		return fmt.Sprintf("%.1f KB", float64(n)/(1<<10))
+	default:
+		return fmt.Sprintf("%d B", n)
+	}
+}
+
+// DataUsed returns the bytes counted against the data budget in the current period
+func (b *Bridge) DataUsed() uint64 {
+	return b.dataUsed.Load()
+}
+
+// enforceBudget periodically reports data usage, warns as the budget is
+// consumed and switches to reduced-rate telemetry once it is exceeded
+func (b *Bridge) enforceBudget() {
+
+	ticker := time.NewTicker(budgetCheckInterval)
+	defer ticker.Stop()
+
+	budget := b.config.DataBudget
+	period := time.Now().Format("2006-01-02")
+	reported := b.dataUsed.Load()
+	warned := 0
+	exceeded := false
+
+	check := func() {
+		used := b.dataUsed.Load()
+		if b.config.RecordDataUsage != nil && used > reported {
+			b.config.RecordDataUsage(used - reported)
+		}
+		reported = used
+
+		// A new day restores the full budget
+		if budget.Daily {
+			if day := time.Now().Format("2006-01-02"); day != period {
+				period = day
+				b.dataUsed.Store(0)
+				reported = 0
+				warned = 0
+				if exceeded {
+					exceeded = false
+					b.budgetExceeded.Store(false)
+					b.filter.Store(b.profileFilter)
+					b.logger.Info("New day, data budget reset - bandwidth profile restored")
+					fmt.Fprintf(b.config.Output, "\n%sData budget reset for the new day. Telemetry profile restored.\n", term.Symbol("✅ ", ""))
+					if b.restoreStreamRates() {
+						fmt.Fprintln(b.config.Output)
+					} else {
+						fmt.Fprint(b.config.Output, "   Reconnect your ground station to restore the vehicle's stream rates.\n\n")
+					}
+				}
+				return
+			}
+		}
+
+		fraction := float64(used) / float64(budget.Limit)
+		// Warn once per check even if several thresholds were crossed
+		level := warned
+		for level < len(budgetWarnLevels) && fraction >= budgetWarnLevels[level] {
+			level++
+		}
+		if level > warned && fraction < 1 {
+			b.logger.WithFields(log.Fields{
+				"used":   FormatBytes(used),
+				"budget": budget.String(),
+			}).Warn("Data budget threshold reached")
+			fmt.Fprintf(b.config.Output, "\n%sData budget: %.0f%% used (%s of %s)\n\n", term.Symbol("⚠️  ", "Warning: "), fraction*100, FormatBytes(used), budget)
+		}
+		warned = level
+
+		if fraction >= 1 && !exceeded {
+			exceeded = true
+			b.budgetExceeded.Store(true)
+			profile := builtinProfiles[budgetProfile]
+			reduced, _ := newRateFilter(budgetProfile, profile)
+			b.filter.Store(reduced)
+			b.lowerStreamRate(uint16(profile.MaxRate))
+
+			b.logger.WithFields(log.Fields{
+				"used":   FormatBytes(used),
+				"budget": budget.String(),
+			}).Warn("Data budget exceeded, switching to reduced-rate telemetry")
+			fmt.Fprintf(b.config.Output, "\n%sData budget exceeded (%s of %s).\n", term.Symbol("⛔ ", "Warning: "), FormatBytes(used), budget)
+			fmt.Fprintf(b.config.Output, "   Switched to the %s profile (%s);\n", budgetProfile, strings.ToLower(profile.Description))
+			fmt.Fprint(b.config.Output, "   commands, parameters and missions are unaffected.\n\n")
+		}
+	}
+
+	for {
+		select {
+		case <-b.ctx.Done():
+			check()
+			return
+		case <-ticker.C:
+			check()
+		}
+	}
+}
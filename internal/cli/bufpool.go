@@ -0,0 +1,77 @@
+package cli
+
+import (
+	"io"
+	"sync"
+
+	"github.com/gorilla/websocket"
+)
+
+// bufferSize is the initial capacity of pooled forwarding buffers
+const bufferSize = 4096
+
+// Buffer sizes in low-memory mode. MAVLink frames are at most 280 bytes, so
+// small buffers only cost extra reads, not correctness.
+const (
+	lowMemoryParserBuffer    = 2048
+	lowMemoryWebSocketBuffer = 1024
+)
+
+// maxPooledBuffer keeps unusually large buffers out of the pool so one huge
+// message doesn't pin memory for the rest of the session
+const maxPooledBuffer = 64 * 1024
+
+// bufferPool recycles forwarding buffers so the hot path doesn't allocate per message
+var bufferPool = sync.Pool{
+	New: func() interface{} {
+		buf := make([]byte, 0, bufferSize)
+		return &buf
+	},
+}
+
+// getBuffer returns an empty buffer from the pool
+func getBuffer() *[]byte {
+	bufp := bufferPool.Get().(*[]byte)
+	*bufp = (*bufp)[:0]
+	return bufp
+}
+
+// putBuffer returns a buffer to the pool; it must not be used afterwards
+func putBuffer(bufp *[]byte) {
+	if cap(*bufp) > maxPooledBuffer {
+		return
+	}
+	bufferPool.Put(bufp)
+}
+
+// readMessage reads the next WebSocket message into a pooled buffer.
+// The caller must release the buffer with putBuffer.
+func readMessage(conn *websocket.Conn) (*[]byte, int, error) {
+	msgType, reader, err := conn.NextReader()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	bufp := getBuffer()
+	buf := *bufp
+	for {
+		if len(buf) == cap(buf) {
+			buf = append(buf, 0)[:len(buf)]
+		}
+
+		n, err := reader.Read(buf[len(buf):cap(buf)])
+		buf = buf[:len(buf)+n]
+
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			*bufp = buf
+			putBuffer(bufp)
+			return nil, 0, err
+		}
+	}
+
+	*bufp = buf
+	return bufp, msgType, nil
+}
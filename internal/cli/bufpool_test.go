@@ -0,0 +1,118 @@
+package cli
+
+import (
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/websocket"
+	"github.com/pavliha/aircast/aircast-cli/internal/mavlink"
+	log "github.com/sirupsen/logrus"
+)
+
+// benchmarkMessage returns a typical downlink message: a heartbeat, a
+// position and a system status
+func benchmarkMessage(b *testing.B) []byte {
+	var msg []byte
+	for i, m := range []struct {
+		id   uint32
+		size int
+	}{{mavlink.MsgIDHeartbeat, 9}, {mavlink.MsgIDGlobalPositionInt, 28}, {mavlink.MsgIDSysStatus, 31}} {
+		frame, err := mavlink.EncodeV2(uint8(i), 1, 1, m.id, make([]byte, m.size))
+		if err != nil {
+			b.Fatal(err)
+		}
+		msg = append(msg, frame...)
+	}
+	return msg
+}
+
+// downlinkConn connects to a WebSocket server that sends msg as binary
+// messages until the connection is closed
+func downlinkConn(b *testing.B, msg []byte) *websocket.Conn {
+	var upgrader websocket.Upgrader
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		for conn.WriteMessage(websocket.BinaryMessage, msg) == nil {
+		}
+	}))
+	b.Cleanup(srv.Close)
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(srv.URL, "http"), nil)
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+// BenchmarkReadMessage compares reading WebSocket messages into pooled
+// buffers with gorilla's ReadMessage, which allocates one per message
+func BenchmarkReadMessage(b *testing.B) {
+	msg := benchmarkMessage(b)
+
+	b.Run("pooled", func(b *testing.B) {
+		conn := downlinkConn(b, msg)
+		b.ReportAllocs()
+		b.SetBytes(int64(len(msg)))
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			bufp, _, err := readMessage(conn)
+			if err != nil {
+				b.Fatal(err)
+			}
+			putBuffer(bufp)
+		}
+	})
+
+	b.Run("unpooled", func(b *testing.B) {
+		conn := downlinkConn(b, msg)
+		b.ReportAllocs()
+		b.SetBytes(int64(len(msg)))
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+// BenchmarkDownlink measures the downlink hot path: a WebSocket read,
+// forwardDownlink's frame inspection and the write to a TCP client
+func BenchmarkDownlink(b *testing.B) {
+	msg := benchmarkMessage(b)
+	conn := downlinkConn(b, msg)
+
+	logger := log.New()
+	logger.SetOutput(io.Discard)
+	bridge, err := New(&Config{Logger: log.NewEntry(logger), Output: io.Discard})
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.Cleanup(bridge.cancel)
+
+	local, remote := net.Pipe()
+	go func() { _, _ = io.Copy(io.Discard, remote) }()
+	b.Cleanup(func() { local.Close() })
+	bridge.tcpClients["bench"] = newTCPClient(local, 0, func(int) {}, func(error) {})
+
+	b.ReportAllocs()
+	b.SetBytes(int64(len(msg)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		bufp, msgType, err := readMessage(conn)
+		if err != nil {
+			b.Fatal(err)
+		}
+		bridge.forwardDownlink(msgType, *bufp)
+		putBuffer(bufp)
+	}
+}
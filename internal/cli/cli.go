@@ -6,10 +6,16 @@ import (
 	"io"
 	"net"
 	"net/http"
+	"os"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/gorilla/websocket"
+	"github.com/pavliha/aircast/aircast-cli/internal/mavlink"
+	"github.com/pavliha/aircast/aircast-cli/internal/network"
+	"github.com/pavliha/aircast/aircast-cli/internal/recording"
 	log "github.com/sirupsen/logrus"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
@@ -24,6 +30,134 @@ type Config struct {
 	TCPAddress   string
 	UDPAddress   string
 	Logger       *log.Entry
+	// Output receives notices for the operator (default os.Stdout)
+	Output io.Writer
+
+	// DropCorrupted discards frames that fail CRC validation instead of forwarding them
+	DropCorrupted bool
+	// StatsInterval enables periodic statistics logging when non-zero
+	StatsInterval time.Duration
+
+	// MaxClients limits concurrent TCP clients (0 = unlimited)
+	MaxClients int
+	// MaxClientsPerIP limits concurrent TCP clients from one source IP (0 = unlimited)
+	MaxClientsPerIP int
+
+	// TCPNagle enables Nagle's algorithm on client sockets (Go disables it by default)
+	TCPNagle bool
+	// CoalesceInterval batches downlink writes to each TCP client over this
+	// interval into a single write (0 = write every message immediately)
+	CoalesceInterval time.Duration
+
+	// BandwidthProfile names the downlink filter profile (empty = full)
+	BandwidthProfile string
+	// CustomProfiles are user-defined profiles, which take precedence over built-ins
+	CustomProfiles map[string]BandwidthProfile
+	// SourceRates sends SET_MESSAGE_INTERVAL requests so the autopilot itself
+	// sends at the profile's rates; profiles can also enable it
+	SourceRates bool
+
+	// DataBudget limits WebSocket traffic; telemetry is reduced once exceeded
+	DataBudget DataBudget
+	// DataUsed is the usage already counted against a daily budget by earlier sessions
+	DataUsed uint64
+	// RecordDataUsage is called periodically with newly transferred bytes
+	RecordDataUsage func(n uint64)
+
+	// OnReady is called once, when the first data arrives from the device
+	OnReady func()
+
+	// AdaptiveMaxRate enables AIMD rate limiting of downlink streams between
+	// 1 Hz and this rate, driven by measured latency and loss (0 = off)
+	AdaptiveMaxRate float64
+
+	// Recorder, if set, receives every valid frame in both directions
+	Recorder *recording.Writer
+
+	// Alarms are evaluated against the decoded telemetry every second;
+	// OnAlarm is called when one is raised or cleared
+	Alarms  []AlarmRule
+	OnAlarm func(AlarmEvent)
+
+	// OnArmed is called when the autopilot's HEARTBEAT arming state changes,
+	// including when the first heartbeat shows the vehicle already armed
+	OnArmed func(armed bool, at time.Time)
+
+	// ExpiresAt ends a guest session: ground stations are warned ahead of
+	// it, their commands aren't forwarded during the last ExpiryReadOnly,
+	// and OnExpire is called once it passes (zero = no limit)
+	ExpiresAt time.Time
+	OnExpire  func()
+
+	// OnLink is called when the WebSocket to the device drops (with the read
+	// error) and when a reconnect restores it (with nil). Planned moves to
+	// another server are not reported. It runs on the read loop and must not block.
+	OnLink func(up bool, err error)
+
+	// OnClient is called with a client's ID when a ground station connects
+	// (a UDP client on its first packet) and when it disconnects or is
+	// kicked. It must not block.
+	OnClient func(id string, connected bool)
+
+	// StallTimeout cycles the connection when it delivers nothing for this
+	// long while ground stations are sending (0 = never). See watchStalls.
+	StallTimeout time.Duration
+
+	// OnStall is called with how long the connection was silent when the
+	// stall watchdog cycles it, before the reconnect. It must not block.
+	OnStall func(silence time.Duration)
+
+	// OnCircuit is called when the circuit breaker opens because the device's
+	// MAVLink proxy isn't answering (with the time of the next retry) and when
+	// data flows again. It replaces the printed notices, e.g. for a
+	// troubleshooting screen, and must not block.
+	OnCircuit func(open bool, retryAt time.Time)
+
+	// Remap rewrites source system/component IDs; the first matching rule
+	// applies. Corrupted, signed and unknown frames are never rewritten.
+	Remap []RemapRule
+
+	// DedupWindow suppresses uplink frames identical to one another client
+	// sent within this window, so ground stations requesting the same
+	// streams don't double uplink usage (0 = off)
+	DedupWindow time.Duration
+
+	// Heartbeat sends GCS heartbeats upstream to keep device-side proxies alive
+	Heartbeat HeartbeatConfig
+
+	// ClientBanner is sent to each new client as a STATUSTEXT identifying
+	// the link, e.g. "via Aircast: Falcon-2 / prod" (empty = off)
+	ClientBanner string
+
+	// RadioStatusInterval sends ground stations a RADIO_STATUS describing the
+	// cloud link at this interval (0 = off)
+	RadioStatusInterval time.Duration
+
+	// Aux bridges non-MAVLink companion data (e.g. JSON sensor feeds) as
+	// newline-delimited records: text and binary WebSocket messages are
+	// forwarded as lines, and client lines are sent as text messages
+	Aux bool
+
+	// LowMemory shrinks per-connection buffers and histories for small
+	// devices such as a Raspberry Pi Zero
+	LowMemory bool
+
+	// Bond opens a second WebSocket over another local interface
+	Bond BondConfig
+
+	// Routes are interfaces the WebSocket chooses between, e.g. Starlink and
+	// LTE; each is probed periodically and the fastest is used
+	Routes []string
+
+	// Endpoints are further WebSocket URLs serving the same device, e.g. API
+	// hosts in other regions. They are probed like Routes, over each of them
+	// if given, and the fastest pair is used.
+	Endpoints []string
+
+	// BatchInterval collects small uplink writes over this interval into one
+	// WebSocket message of at most one packet, cutting per-message overhead
+	// of high-rate streams (0 = send every write at once)
+	BatchInterval time.Duration
 }
 
 // Bridge represents a MAVLink WebSocket-to-TCP/UDP bridge
@@ -31,34 +165,126 @@ type Bridge struct {
 	config *Config
 	logger *log.Entry
 
-	// WebSocket connection
-	wsConn   *websocket.Conn
-	wsMutex  sync.Mutex
-	wsCtx    context.Context
-	wsCancel context.CancelFunc
+	// WebSocket connection and the token used to (re)connect, which
+	// SetAuthToken replaces when the login is refreshed. movedURL is where
+	// the server last moved the device, nil for the configured URL. wsMutex
+	// is only held to swap or read wsConn, never while dialing or writing;
+	// wsWriteMu serializes writes, which the connection allows one at a time.
+	authToken atomic.Pointer[string]
+	movedURL  atomic.Pointer[string]
+	wsConn    *websocket.Conn
+	wsMutex   sync.Mutex
+	wsWriteMu sync.Mutex
+
+	// dial opens the primary connection; bond is the second connection over
+	// another interface, nil without Config.Bond
+	dial network.DialFunc
+	bond *bondLink
+
+	// Paths chosen between by probing, nil without Config.Routes or
+	// Config.Endpoints; switching is set while the connection is closed to
+	// change routes
+	routes    *routeSelector
+	switching atomic.Bool
+
+	// Path MTU of the WebSocket connection, nil until connected
+	pathMTU atomic.Pointer[PathMTUStats]
+
+	// Uplink writes waiting to be sent together, with BatchInterval
+	batch uplinkBatch
+
+	// The guest session is in its last minute and the uplink is read-only
+	expiring atomic.Bool
+
+	// Simulated outage for operator training, ended by trainingTimer
+	training      atomic.Int32
+	trainingUntil time.Time
+	trainingTimer *time.Timer
+	trainingMu    sync.Mutex
 
 	// TCP listener
 	tcpListener net.Listener
-	tcpClients  map[string]net.Conn
+	tcpClients  map[string]*tcpClient
 	tcpMutex    sync.RWMutex
 
 	// UDP listener
 	udpConn    *net.UDPConn
 	udpClients map[string]*net.UDPAddr
+	udpBlocked map[string]time.Time // Kicked clients ignored until the given time
 	udpMutex   sync.RWMutex
 
+	// Traffic statistics and downlink frame parser
+	stats    *Stats
+	downlink *frameStream
+
+	// Connection history for post-mortems
+	diag *diagnostics
+
+	// WebSocket round-trip times and reconnects; linkDown is set between a
+	// read error and the reconnect that follows. wsDataAt is when the primary
+	// connection last delivered a message and requestAt when a ground station
+	// last sent a request expecting an answer (Unix nanoseconds); stalled is
+	// set while the stall watchdog closes a silent connection.
+	link      linkMonitor
+	linkDown  atomic.Bool
+	wsDataAt  atomic.Int64
+	requestAt atomic.Int64
+	stalled   atomic.Bool
+
+	// When data last passed in each direction (Unix nanoseconds), by Direction
+	lastDataAt [2]atomic.Int64
+
+	// Latest vehicle state decoded from the autopilot's downlink
+	telemetry   mavlink.Telemetry
+	telemetryAt time.Time
+	telemetryMu sync.Mutex
+
+	// Fires Config.OnReady on the first data from the device
+	readyOnce sync.Once
+
+	// Data budget accounting and the active downlink filter; profileFilter
+	// is the configured profile, restored when the budget resets
+	dataUsed       atomic.Uint64
+	filter         atomic.Pointer[rateFilter]
+	profile        BandwidthProfile
+	profileFilter  *rateFilter
+	budgetExceeded atomic.Bool
+
+	// Rate requests to the autopilot, nil when disabled
+	sourceRates *sourceRates
+
+	// Rate requests from ground stations, given back after lowering them
+	gcsRates gcsRates
+
+	// Uplink de-duplication across clients, nil when disabled
+	dedup *uplinkDedup
+
+	// Secondary outputs added at runtime
+	outputs outputRegistry
+
+	// Sequence number for frames the bridge originates
+	txSeq atomic.Uint32
+	// When a client last sent a HEARTBEAT upstream (Unix nanoseconds)
+	clientHeartbeatAt atomic.Int64
+	// When the device last sent its own RADIO_STATUS (Unix nanoseconds)
+	radioStatusAt atomic.Int64
+
 	// Control
 	ctx    context.Context
 	cancel context.CancelFunc
-	wg     sync.WaitGroup
-
-	// Circuit breaker for reconnection
-	circuitState      string // "closed", "open", "half-open"
-	failureCount      int
-	lastFailureTime   time.Time
-	circuitOpenUntil  time.Time
-	failureThreshold  int
-	circuitOpenPeriod time.Duration
+	tasks  taskGroup
+	state  atomic.Int32 // State
+
+	// Circuit breaker for reconnection, see breaker.go; circuitMu guards
+	// the fields up to circuitOpenUntil
+	circuitMu        sync.Mutex
+	circuitState     string // "closed", "open", "half-open"
+	circuitClass     FailureClass
+	failures         [failureClasses]int // Consecutive failures of each class
+	lastFailureTime  time.Time
+	circuitOpenUntil time.Time
+	retryNow         chan struct{} // Cuts the open circuit's wait short
+	connData         atomic.Bool   // The current connection delivered data
 }
 
 // New creates a new MAVLink bridge
@@ -66,27 +292,117 @@ func New(config *Config) (*Bridge, error) {
 	if config.Logger == nil {
 		config.Logger = log.WithField("component", "bridge")
 	}
+	if config.Output == nil {
+		config.Output = os.Stdout
+	}
+
+	var profile BandwidthProfile
+	var profileFilter *rateFilter
+	if config.BandwidthProfile != "" {
+		var err error
+		if profile, err = LookupProfile(config.BandwidthProfile, config.CustomProfiles); err != nil {
+			return nil, err
+		}
+		if profileFilter, err = newRateFilter(config.BandwidthProfile, profile); err != nil {
+			return nil, err
+		}
+	}
+	if config.AdaptiveMaxRate != 0 && config.AdaptiveMaxRate < adaptMinRate {
+		return nil, fmt.Errorf("adaptive rate limit must be at least %.0f Hz", adaptMinRate)
+	}
 
 	ctx, cancel := context.WithCancel(context.Background())
 
-	return &Bridge{
-		config:            config,
-		logger:            config.Logger,
-		tcpClients:        make(map[string]net.Conn),
-		udpClients:        make(map[string]*net.UDPAddr),
-		ctx:               ctx,
-		cancel:            cancel,
-		circuitState:      "closed",
-		failureThreshold:  3,                // Open circuit after 3 failures
-		circuitOpenPeriod: 30 * time.Second, // Keep circuit open for 30 seconds
-	}, nil
+	b := &Bridge{
+		config:        config,
+		logger:        config.Logger,
+		tcpClients:    make(map[string]*tcpClient),
+		udpClients:    make(map[string]*net.UDPAddr),
+		udpBlocked:    make(map[string]time.Time),
+		stats:         NewStats(),
+		diag:          newDiagnostics(config.LowMemory),
+		ctx:           ctx,
+		cancel:        cancel,
+		circuitState:  "closed",
+		retryNow:      make(chan struct{}, 1),
+		profile:       profile,
+		profileFilter: profileFilter,
+		dial:          network.DialContext,
+	}
+	if config.Bond.Enabled() && (len(config.Routes) > 0 || len(config.Endpoints) > 0) {
+		cancel()
+		return nil, fmt.Errorf("bonding and route selection can't be combined")
+	}
+	if len(config.Routes) > 0 || len(config.Endpoints) > 0 {
+		routes, err := newRouteSelector(config.Routes, append([]string{config.WebSocketURL}, config.Endpoints...))
+		if err != nil {
+			cancel()
+			return nil, err
+		}
+		b.routes = routes
+	}
+	if config.Bond.Enabled() {
+		primary, err := network.InterfaceDialer(config.Bond.Primary)
+		if err != nil {
+			cancel()
+			return nil, err
+		}
+		backup, err := network.InterfaceDialer(config.Bond.Backup)
+		if err != nil {
+			cancel()
+			return nil, err
+		}
+		b.dial = primary
+		b.bond = newBondLink(config.Bond, backup, config.Logger)
+	}
+	b.downlink = b.newFrameStream()
+	b.filter.Store(profileFilter)
+	b.authToken.Store(&config.AuthToken)
+	if config.DedupWindow > 0 && !config.Aux {
+		b.dedup = newUplinkDedup(config.DedupWindow)
+	}
+	if (config.SourceRates || profile.SourceRates) && !config.Aux {
+		if profileFilter != nil {
+			b.sourceRates = newSourceRates(profileFilter)
+		} else {
+			b.logger.Warn("Source rates need a bandwidth profile that limits telemetry; not requesting any")
+		}
+	}
+
+	return b, nil
+}
+
+// Start connects to the WebSocket, opens the listeners and starts relaying.
+// ctx bounds the first connection only; the bridge then runs until Shutdown.
+// If Start fails, whatever it opened is closed and the bridge is stopped.
+// A bridge can be started once.
+func (b *Bridge) Start(ctx context.Context) error {
+	if !b.state.CompareAndSwap(int32(StateIdle), int32(StateConnecting)) {
+		return ErrStopped
+	}
+	if err := b.start(ctx); err != nil {
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), DefaultShutdownTimeout)
+		defer cancel()
+		_ = b.Shutdown(shutdownCtx)
+		return err
+	}
+	return nil
 }
 
-// Start starts the bridge
-func (b *Bridge) Start() error {
-	// Connect to WebSocket
-	if err := b.connectWebSocket(); err != nil {
-		return fmt.Errorf("failed to connect to WebSocket: %w", err)
+// start opens the connection and listeners and starts the bridge's goroutines
+func (b *Bridge) start(ctx context.Context) error {
+	// Start on the fastest route
+	if b.routes != nil {
+		b.selectRoute()
+	}
+
+	// Connect to WebSocket. A bonded bridge can start on the backup link.
+	if err := b.connectWebSocket(ctx); err != nil {
+		if b.bond == nil || ctx.Err() != nil {
+			return fmt.Errorf("failed to connect to WebSocket: %w", err)
+		}
+		b.logger.WithError(err).Warn("Primary link unavailable, continuing on the backup link")
+		b.linkDown.Store(true)
 	}
 
 	// Start TCP listener if configured
@@ -103,30 +419,160 @@ func (b *Bridge) Start() error {
 		}
 	}
 
+	if b.linkDown.Load() {
+		b.setState(StateDegraded)
+	} else {
+		b.setState(StateRunning)
+	}
+
 	// Start WebSocket reader
-	b.wg.Add(1)
-	go b.readWebSocket()
+	b.tasks.Go("websocket-reader", b.readWebSocket)
+
+	b.tasks.Go("link-ping", b.pingLink)
+
+	// Start the second link if bonding
+	if b.bond != nil {
+		b.tasks.Go("bond", b.runBond)
+	}
+
+	// Start route selection if configured
+	if b.routes != nil {
+		b.tasks.Go("routes", b.watchRoutes)
+	}
+
+	// Start adaptive rate limiting if configured
+	if b.config.AdaptiveMaxRate > 0 {
+		b.tasks.Go("adaptive-rate", b.adaptRate)
+	}
+
+	// Start telemetry alarms if configured
+	if len(b.config.Alarms) > 0 {
+		b.tasks.Go("alarms", b.watchAlarms)
+	}
+
+	// Start upstream heartbeats if configured
+	if b.config.Heartbeat.Interval > 0 && !b.config.Aux {
+		b.tasks.Go("heartbeats", b.sendHeartbeats)
+	}
+
+	// Start link status reports to ground stations if configured
+	if b.config.RadioStatusInterval > 0 && !b.config.Aux {
+		b.tasks.Go("radio-status", b.sendRadioStatus)
+	}
+
+	// Start the stall watchdog if configured
+	if b.config.StallTimeout > 0 && !b.config.Aux {
+		b.tasks.Go("stall-watchdog", b.watchStalls)
+	}
+
+	// Start periodic statistics logging if configured
+	if b.config.StatsInterval > 0 {
+		b.tasks.Go("stats-logger", b.logStats)
+	}
+
+	// Start data budget enforcement if configured
+	if b.config.DataBudget.Enabled() {
+		if b.config.DataBudget.Daily {
+			b.dataUsed.Store(b.config.DataUsed)
+		}
+		b.tasks.Go("data-budget", b.enforceBudget)
+	}
+
+	// Start the guest session clock if configured
+	if !b.config.ExpiresAt.IsZero() {
+		b.tasks.Go("expiry", b.enforceExpiry)
+	}
+
+	// Start source rate negotiation if configured
+	if b.sourceRates != nil {
+		b.tasks.Go("source-rates", b.negotiateSourceRates)
+	}
 
 	return nil
 }
 
-// Stop stops the bridge
-func (b *Bridge) Stop() error {
+// TCPAddr returns the address the TCP listener is bound to, or nil if TCP is disabled
+func (b *Bridge) TCPAddr() net.Addr {
+	b.tcpMutex.RLock()
+	defer b.tcpMutex.RUnlock()
+	if b.tcpListener == nil {
+		return nil
+	}
+	return b.tcpListener.Addr()
+}
+
+// UDPAddr returns the address the UDP listener is bound to, or nil if UDP is disabled
+func (b *Bridge) UDPAddr() net.Addr {
+	b.udpMutex.RLock()
+	defer b.udpMutex.RUnlock()
+	if b.udpConn == nil {
+		return nil
+	}
+	return b.udpConn.LocalAddr()
+}
+
+// SetAuthToken sets the token used when the bridge reconnects. The current
+// connection is unaffected, but an open circuit, e.g. one opened because the
+// server rejected the old token, retries at once with the new one.
+func (b *Bridge) SetAuthToken(token string) {
+	if old := b.authToken.Swap(&token); old == nil || *old != token {
+		b.RetryNow()
+	}
+}
+
+// Stats returns a snapshot of the bridge traffic statistics
+func (b *Bridge) Stats() StatsSnapshot {
+	return b.stats.Snapshot()
+}
+
+// Telemetry returns the latest vehicle state decoded from the downlink and
+// when it was last updated (zero if nothing has been decoded yet)
+func (b *Bridge) Telemetry() (mavlink.Telemetry, time.Time) {
+	b.telemetryMu.Lock()
+	defer b.telemetryMu.Unlock()
+	return b.telemetry, b.telemetryAt
+}
+
+// Diagnostics returns the WebSocket connection history
+func (b *Bridge) Diagnostics() Diagnostics {
+	return b.diag.Snapshot()
+}
+
+// Shutdown stops the bridge: it closes the connections and listeners, waits
+// for the bridge's goroutines until ctx is done, then closes the outputs. If
+// ctx ends first, the outputs are closed anyway and the error names the
+// components that are stuck. Calling it again does nothing.
+func (b *Bridge) Shutdown(ctx context.Context) error {
+	if State(b.state.Swap(int32(StateStopped))) == StateStopped {
+		return nil
+	}
+
+	// Hand the autopilot its own rates back, then send what's queued
+	// before the connection closes
+	b.restoreSourceRates()
+	b.flushBatch()
 	b.cancel()
+	// Don't leave a training drill's timer behind
+	b.SimulateOutage(TrainingOff, 0)
 
-	// Close WebSocket
+	// Close WebSocket. A reconnect in progress sees the bridge is stopping
+	// and closes its new connection itself.
+	b.wsMutex.Lock()
 	if b.wsConn != nil {
-		b.wsCancel()
 		_ = b.wsConn.Close()
 	}
+	b.wsMutex.Unlock()
+	if b.bond != nil {
+		b.bond.close()
+	}
 
 	// Close TCP listener and clients
 	if b.tcpListener != nil {
 		_ = b.tcpListener.Close()
 	}
 	b.tcpMutex.Lock()
-	for _, conn := range b.tcpClients {
-		_ = conn.Close()
+	for _, client := range b.tcpClients {
+		_ = client.Close()
 	}
 	b.tcpMutex.Unlock()
 
@@ -135,57 +581,124 @@ func (b *Bridge) Stop() error {
 		_ = b.udpConn.Close()
 	}
 
-	// Wait for goroutines
-	b.wg.Wait()
+	// Wait for goroutines, but not forever: one blocked on a dead socket
+	// shouldn't keep the process from exiting
+	stuck := b.tasks.Wait(ctx)
+	for _, name := range stuck {
+		b.logger.WithField("component", name).Warn("Component did not stop in time")
+	}
+
+	// No more traffic (or none that will be waited for); let outputs,
+	// including those added at runtime, flush and close
+	b.closeOutputs()
 
+	if len(stuck) > 0 {
+		return fmt.Errorf("shutdown gave up waiting for %s: %w", strings.Join(stuck, ", "), ctx.Err())
+	}
 	return nil
 }
 
-// connectWebSocket connects to the WebSocket endpoint
-func (b *Bridge) connectWebSocket() error {
-	b.logger.WithField("url", b.config.WebSocketURL).Info("Connecting to WebSocket")
+// connectWebSocket makes the first connection to the WebSocket endpoint
+func (b *Bridge) connectWebSocket(ctx context.Context) error {
+	b.logger.WithField("url", b.currentURL()).Info("Connecting to WebSocket")
+
+	conn, err := b.dialWebSocket(ctx)
+	if err != nil {
+		return fmt.Errorf("WebSocket dial failed: %w", err)
+	}
+
+	b.wsMutex.Lock()
+	b.wsConn = conn
+	b.wsMutex.Unlock()
+
+	b.logger.Info("WebSocket connected")
+	return nil
+}
+
+// dialWebSocket dials the WebSocket endpoint with the auth header and records
+// the handshake outcome
+func (b *Bridge) dialWebSocket(ctx context.Context) (*websocket.Conn, error) {
+	dial := b.dial
+	if b.routes != nil {
+		dial = b.routes.activeDial()
+	}
+	conn, err := b.dialWebSocketVia(ctx, dial)
+	if err != nil {
+		return nil, err
+	}
+	b.checkPathMTU(conn)
+	return conn, nil
+}
+
+// dialWebSocketVia dials the WebSocket endpoint with a specific dialer,
+// giving up when ctx or the bridge is done
+func (b *Bridge) dialWebSocketVia(ctx context.Context, dial network.DialFunc) (*websocket.Conn, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	stop := context.AfterFunc(b.ctx, cancel)
+	defer stop()
 
-	// Create WebSocket dialer with auth header
 	header := http.Header{}
-	if b.config.AuthToken != "" {
-		header.Add("Authorization", "Bearer "+b.config.AuthToken)
+	if token := *b.authToken.Load(); token != "" {
+		header.Add("Authorization", "Bearer "+token)
 	}
 
 	dialer := websocket.Dialer{
 		HandshakeTimeout: 10 * time.Second,
+		Proxy:            http.ProxyFromEnvironment,
+		NetDialContext:   dial,
 	}
-
-	conn, _, err := dialer.Dial(b.config.WebSocketURL, header)
-	if err != nil {
-		return fmt.Errorf("WebSocket dial failed: %w", err)
+	if b.config.LowMemory {
+		dialer.ReadBufferSize = lowMemoryWebSocketBuffer
+		dialer.WriteBufferSize = lowMemoryWebSocketBuffer
 	}
 
-	b.wsConn = conn
-	b.wsCtx, b.wsCancel = context.WithCancel(b.ctx)
+	for redirects := 0; ; redirects++ {
+		conn, resp, err := dialer.DialContext(ctx, b.currentURL(), header)
+		b.diag.Handshake(resp, err)
+		if resp != nil && resp.Body != nil {
+			_ = resp.Body.Close()
+		}
+		if conn != nil {
+			b.watchPongs(conn)
+			return conn, nil
+		}
 
-	b.logger.Info("WebSocket connected")
-	return nil
+		// The device may have moved to another node
+		if target, ok := handshakeRedirect(resp, err); ok && redirects < maxHandshakeRedirects && b.moveTo(target, "redirect") {
+			continue
+		}
+		b.revertMove()
+		if resp != nil {
+			err = &HandshakeError{StatusCode: resp.StatusCode, Err: err}
+		}
+		return nil, err
+	}
 }
 
 // startTCPListener starts the TCP listener
 func (b *Bridge) startTCPListener() error {
+	if err := network.ValidateListenAddr(b.config.TCPAddress); err != nil {
+		return err
+	}
+
 	listener, err := net.Listen("tcp", b.config.TCPAddress)
 	if err != nil {
 		return fmt.Errorf("failed to listen on TCP %s: %w", b.config.TCPAddress, err)
 	}
 
+	b.tcpMutex.Lock()
 	b.tcpListener = listener
+	b.tcpMutex.Unlock()
 	b.logger.WithField("address", b.config.TCPAddress).Info("TCP listener started")
 
-	b.wg.Add(1)
-	go b.acceptTCPConnections()
+	b.tasks.Go("tcp-listener", b.acceptTCPConnections)
 
 	return nil
 }
 
 // acceptTCPConnections accepts incoming TCP connections
 func (b *Bridge) acceptTCPConnections() {
-	defer b.wg.Done()
 
 	for {
 		conn, err := b.tcpListener.Accept()
@@ -200,20 +713,70 @@ func (b *Bridge) acceptTCPConnections() {
 		}
 
 		clientAddr := conn.RemoteAddr().String()
-		b.logger.WithField("client", clientAddr).Info("TCP client connected")
+
+		if tcpConn, ok := conn.(*net.TCPConn); ok {
+			_ = tcpConn.SetNoDelay(!b.config.TCPNagle)
+		}
 
 		b.tcpMutex.Lock()
-		b.tcpClients[clientAddr] = conn
+		if reason := b.checkClientLimits(clientAddr); reason != "" {
+			b.tcpMutex.Unlock()
+			b.logger.WithFields(log.Fields{
+				"client": clientAddr,
+				"reason": reason,
+			}).Warn("TCP client rejected")
+			_ = conn.Close()
+			continue
+		}
+		client := newTCPClient(conn, b.config.CoalesceInterval,
+			func(n int) {
+				b.stats.ClientWrite("tcp", clientAddr, n)
+			},
+			func(err error) {
+				b.logger.WithError(err).WithField("client", clientAddr).Error("Failed to write to TCP client")
+				_ = conn.Close()
+			},
+		)
+		b.tcpClients[clientAddr] = client
 		b.tcpMutex.Unlock()
 
-		b.wg.Add(1)
-		go b.handleTCPClient(conn)
+		b.logger.WithField("client", clientAddr).Info("TCP client connected")
+		b.stats.ClientConnected("tcp", clientAddr)
+		b.clientEvent(clientID("tcp", clientAddr), true)
+		b.greetClient(clientID("tcp", clientAddr), func(data []byte) error {
+			_, err := client.Write(data)
+			return err
+		})
+
+		b.tasks.Go("tcp-client", func() { b.handleTCPClient(conn) })
+	}
+}
+
+// checkClientLimits returns why a new TCP client must be rejected, or "" if it
+// may connect. Caller must hold tcpMutex.
+func (b *Bridge) checkClientLimits(clientAddr string) string {
+	if b.config.MaxClients > 0 && len(b.tcpClients) >= b.config.MaxClients {
+		return fmt.Sprintf("client limit of %d reached", b.config.MaxClients)
+	}
+
+	if b.config.MaxClientsPerIP > 0 {
+		ip, _, _ := net.SplitHostPort(clientAddr)
+		count := 0
+		for addr := range b.tcpClients {
+			if host, _, _ := net.SplitHostPort(addr); host == ip {
+				count++
+			}
+		}
+		if count >= b.config.MaxClientsPerIP {
+			return fmt.Sprintf("per-IP limit of %d reached for %s", b.config.MaxClientsPerIP, ip)
+		}
 	}
+
+	return ""
 }
 
 // handleTCPClient handles a TCP client connection
 func (b *Bridge) handleTCPClient(conn net.Conn) {
-	defer b.wg.Done()
 	clientAddr := conn.RemoteAddr().String()
 	logger := b.logger.WithField("tcp_client", clientAddr)
 
@@ -222,11 +785,17 @@ func (b *Bridge) handleTCPClient(conn net.Conn) {
 		b.tcpMutex.Lock()
 		delete(b.tcpClients, clientAddr)
 		b.tcpMutex.Unlock()
+		b.stats.ClientDisconnected("tcp", clientAddr)
 		logger.Info("TCP client disconnected")
+		b.clientEvent(clientID("tcp", clientAddr), false)
 	}()
 
 	// Read from TCP client and forward to WebSocket
-	buf := make([]byte, 4096)
+	stream := b.newFrameStream()
+	stream.client = clientID("tcp", clientAddr)
+	bufp := getBuffer()
+	defer putBuffer(bufp)
+	buf := (*bufp)[:cap(*bufp)]
 	for {
 		select {
 		case <-b.ctx.Done():
@@ -241,9 +810,15 @@ func (b *Bridge) handleTCPClient(conn net.Conn) {
 			}
 			return
 		}
+		b.stats.ClientRead("tcp", clientAddr, n)
+
+		data := b.inspectFrames(stream, Uplink, buf[:n])
+		if len(data) == 0 {
+			continue
+		}
 
 		// Forward to WebSocket
-		if err := b.writeToWebSocket(buf[:n]); err != nil {
+		if err := b.forwardUplink(stream, data); err != nil {
 			logger.WithError(err).Error("Failed to forward TCP data to WebSocket")
 			return
 		}
@@ -252,6 +827,10 @@ func (b *Bridge) handleTCPClient(conn net.Conn) {
 
 // startUDPListener starts the UDP listener
 func (b *Bridge) startUDPListener() error {
+	if err := network.ValidateListenAddr(b.config.UDPAddress); err != nil {
+		return err
+	}
+
 	addr, err := net.ResolveUDPAddr("udp", b.config.UDPAddress)
 	if err != nil {
 		return fmt.Errorf("failed to resolve UDP address %s: %w", b.config.UDPAddress, err)
@@ -262,20 +841,24 @@ func (b *Bridge) startUDPListener() error {
 		return fmt.Errorf("failed to listen on UDP %s: %w", b.config.UDPAddress, err)
 	}
 
+	b.udpMutex.Lock()
 	b.udpConn = conn
+	b.udpMutex.Unlock()
 	b.logger.WithField("address", b.config.UDPAddress).Info("UDP listener started")
 
-	b.wg.Add(1)
-	go b.readUDP()
+	b.tasks.Go("udp-listener", b.readUDP)
 
 	return nil
 }
 
 // readUDP reads from UDP and forwards to WebSocket
 func (b *Bridge) readUDP() {
-	defer b.wg.Done()
 
-	buf := make([]byte, 4096)
+	// Each UDP client is a separate frame stream
+	streams := make(map[string]*frameStream)
+	bufp := getBuffer()
+	defer putBuffer(bufp)
+	buf := (*bufp)[:cap(*bufp)]
 	for {
 		select {
 		case <-b.ctx.Done():
@@ -297,14 +880,43 @@ func (b *Bridge) readUDP() {
 		// Track UDP client
 		clientAddr := addr.String()
 		b.udpMutex.Lock()
+		if until, blocked := b.udpBlocked[clientAddr]; blocked {
+			if time.Now().Before(until) {
+				b.udpMutex.Unlock()
+				continue
+			}
+			delete(b.udpBlocked, clientAddr)
+		}
 		if _, exists := b.udpClients[clientAddr]; !exists {
 			b.udpClients[clientAddr] = addr
+			b.stats.ClientConnected("udp", clientAddr)
 			b.logger.WithField("client", clientAddr).Info("UDP client detected")
+			b.clientEvent(clientID("udp", clientAddr), true)
+			b.greetClient(clientID("udp", clientAddr), func(data []byte) error {
+				n, err := b.udpConn.WriteToUDP(data, addr)
+				if err == nil {
+					b.stats.ClientWrite("udp", clientAddr, n)
+				}
+				return err
+			})
 		}
 		b.udpMutex.Unlock()
+		b.stats.ClientRead("udp", clientAddr, n)
+
+		stream, ok := streams[clientAddr]
+		if !ok {
+			stream = b.newFrameStream()
+			stream.client = clientID("udp", clientAddr)
+			streams[clientAddr] = stream
+		}
+
+		data := b.inspectFrames(stream, Uplink, buf[:n])
+		if len(data) == 0 {
+			continue
+		}
 
 		// Forward to WebSocket
-		if err := b.writeToWebSocket(buf[:n]); err != nil {
+		if err := b.forwardUplink(stream, data); err != nil {
 			b.logger.WithError(err).Error("Failed to forward UDP data to WebSocket")
 		}
 	}
@@ -312,7 +924,6 @@ func (b *Bridge) readUDP() {
 
 // readWebSocket reads from WebSocket and forwards to TCP/UDP clients
 func (b *Bridge) readWebSocket() {
-	defer b.wg.Done()
 
 	for {
 		select {
@@ -329,216 +940,317 @@ func (b *Bridge) readWebSocket() {
 		if conn == nil {
 			// WebSocket not connected, try to reconnect
 			if err := b.reconnectWebSocket(); err != nil {
-				b.logger.WithError(err).Error("Failed to reconnect WebSocket")
-				time.Sleep(2 * time.Second)
+				b.reconnectFailed(err)
 			}
 			continue
 		}
 
-		msgType, data, err := conn.ReadMessage()
-		if err != nil {
-			select {
-			case <-b.ctx.Done():
-				return
-			default:
+		bufp, msgType, err := readMessage(conn)
+		if err == nil {
+			b.wsDataAt.Store(time.Now().UnixNano())
+		}
+		if err == nil && msgType == websocket.TextMessage && !b.config.Aux {
+			if target, ok := messageMove(*bufp); ok && b.moveTo(target, "control message") {
+				putBuffer(bufp)
+				b.redial()
+				continue
+			}
+		}
+		if err == nil {
+			b.receiveDownlink(msgType, *bufp, false)
+			putBuffer(bufp)
+			continue
+		}
+
+		select {
+		case <-b.ctx.Done():
+			return
+		default:
+			// A planned move isn't a failure: redial the new URL right away
+			if target, ok := closeMove(err); ok && b.moveTo(target, "close frame") {
+				b.diag.ReadError(err)
+				b.redial()
+				continue
+			}
+
+			// Nor is a route change
+			if b.switching.Swap(false) {
+				b.redial()
+				continue
+			}
+
+			// The stall watchdog closed a silent connection and said so
+			if b.stalled.Swap(false) {
+				err = ErrStalled
+			} else {
 				b.logger.WithError(err).Error("WebSocket read error")
-				b.recordFailure()
-
-				// Check circuit breaker state
-				if b.circuitState == "open" {
-					waitTime := time.Until(b.circuitOpenUntil)
-					if waitTime > 0 {
-						fmt.Printf("\n⏸️  Device not ready. Waiting %v before retry...\n\n", waitTime.Round(time.Second))
-
-						// Sleep with context cancellation support
-						select {
-						case <-b.ctx.Done():
-							return
-						case <-time.After(waitTime):
-							b.circuitState = "half-open"
-							fmt.Println("🔄 Retrying connection...")
-						}
-					}
+			}
+			b.diag.ReadError(err)
+			if !b.linkDown.Swap(true) {
+				b.linkState(false)
+				if b.bond != nil {
+					b.bond.primaryLost()
 				}
-
-				// Try to reconnect
-				if err := b.reconnectWebSocket(); err != nil {
-					b.logger.WithError(err).Error("Failed to reconnect WebSocket")
-					time.Sleep(2 * time.Second)
+				if b.config.OnLink != nil {
+					b.config.OnLink(false, err)
 				}
-				// Don't reset circuit breaker on successful reconnection
-				// It will reset only after receiving actual data
-				continue
 			}
-		}
+			b.recordFailure(classifyFailure(err, b.connData.Load()), err)
+			b.waitCircuit()
 
-		// Step 9: Trace CLI WebSocket read
-		tracer := otel.Tracer("aircast-cli/bridge")
-		ctx, span := tracer.Start(context.Background(), "mavlink.cli.websocket_read",
-			trace.WithAttributes(
-				attribute.String("direction", "api_to_cli"),
-				attribute.Int("mavlink.bytes", len(data)),
-				attribute.Int("ws.message_type", msgType),
-			),
-		)
+			// The route may have failed; move to a working one first
+			if b.routes != nil {
+				b.selectRoute()
+			}
+
+			// Try to reconnect
+			if err := b.reconnectWebSocket(); err != nil {
+				b.reconnectFailed(err)
+			}
+			// Don't reset circuit breaker on successful reconnection
+			// It will reset only after receiving actual data
+		}
+	}
+}
 
+// forwardDownlink forwards a WebSocket message to all TCP/UDP clients.
+// data is only valid for the duration of the call.
+func (b *Bridge) forwardDownlink(msgType int, data []byte) {
+	// Step 9: Trace CLI WebSocket read
+	tracer := otel.Tracer("aircast-cli/bridge")
+	ctx, span := tracer.Start(context.Background(), "mavlink.cli.websocket_read",
+		trace.WithAttributes(
+			attribute.String("direction", "api_to_cli"),
+			attribute.Int("mavlink.bytes", len(data)),
+			attribute.Int("ws.message_type", msgType),
+		),
+	)
+
+	// Building log fields allocates, so skip it on the hot path unless debugging
+	debug := b.logger.Logger.IsLevelEnabled(log.DebugLevel)
+	if debug {
 		b.logger.WithFields(log.Fields{
 			"msg_type": msgType,
 			"bytes":    len(data),
 			"trace_id": span.SpanContext().TraceID().String(),
 		}).Debug("CLI received message from WebSocket")
+	}
 
-		// Successful data received - reset circuit breaker
-		b.resetCircuit()
-
-		// Only process binary messages
-		if msgType != websocket.BinaryMessage {
-			b.logger.Debug("Ignoring non-binary WebSocket message")
-			span.SetStatus(codes.Error, "non-binary message")
-			span.End()
-			continue
-		}
+	// Successful data received - reset circuit breaker
+	b.diag.DataReceived()
+	b.resetCircuit()
+	if b.config.OnReady != nil {
+		b.readyOnce.Do(b.config.OnReady)
+	}
 
-		span.SetStatus(codes.Ok, "received MAVLink data from API")
+	// Only process binary messages, unless this is a companion data channel
+	if msgType != websocket.BinaryMessage && !b.config.Aux {
+		b.logger.Debug("Ignoring non-binary WebSocket message")
+		span.SetStatus(codes.Error, "non-binary message")
 		span.End()
-		_ = ctx
+		return
+	}
 
-		// Step 10: Trace CLI TCP write
-		// Forward to all TCP clients
-		b.tcpMutex.RLock()
-		for clientAddr, conn := range b.tcpClients {
-			_, tcpSpan := tracer.Start(ctx, "mavlink.cli.tcp_write",
-				trace.WithAttributes(
-					attribute.String("direction", "cli_to_mavproxy"),
-					attribute.Int("mavlink.bytes", len(data)),
-					attribute.String("client.addr", clientAddr),
-					attribute.String("transport", "tcp"),
-				),
-			)
+	span.SetStatus(codes.Ok, "received MAVLink data from API")
+	span.End()
+	_ = ctx
 
-			n, err := conn.Write(data)
-			if err != nil {
-				b.logger.WithError(err).WithField("client", clientAddr).Error("Failed to write to TCP client")
-				tcpSpan.RecordError(err)
-				tcpSpan.SetStatus(codes.Error, "tcp write failed")
-			} else {
+	data = b.inspectFrames(b.downlink, Downlink, data)
+	if len(data) == 0 {
+		return
+	}
+	if b.trainingOutage() != TrainingOff {
+		return // Withheld from the ground stations for a training drill
+	}
+
+	// Step 10: Trace CLI TCP write
+	// Forward to all TCP clients
+	b.tcpMutex.RLock()
+	for clientAddr, client := range b.tcpClients {
+		_, tcpSpan := tracer.Start(ctx, "mavlink.cli.tcp_write",
+			trace.WithAttributes(
+				attribute.String("direction", "cli_to_mavproxy"),
+				attribute.Int("mavlink.bytes", len(data)),
+				attribute.String("client.addr", clientAddr),
+				attribute.String("transport", "tcp"),
+			),
+		)
+
+		n, err := client.Write(data)
+		if err != nil {
+			b.logger.WithError(err).WithField("client", clientAddr).Error("Failed to write to TCP client")
+			tcpSpan.RecordError(err)
+			tcpSpan.SetStatus(codes.Error, "tcp write failed")
+		} else {
+			if debug {
 				b.logger.WithFields(log.Fields{
 					"client":   clientAddr,
 					"bytes":    n,
 					"trace_id": tcpSpan.SpanContext().TraceID().String(),
 				}).Debug("CLI wrote data to TCP client")
-				tcpSpan.SetAttributes(attribute.Int("bytes_written", n))
-				tcpSpan.SetStatus(codes.Ok, "data sent to MAVProxy")
 			}
-			tcpSpan.End()
-		}
-		b.tcpMutex.RUnlock()
-
-		// Forward to all UDP clients
-		if b.udpConn != nil {
-			b.udpMutex.RLock()
-			for clientAddr, addr := range b.udpClients {
-				_, udpSpan := tracer.Start(ctx, "mavlink.cli.udp_write",
-					trace.WithAttributes(
-						attribute.String("direction", "cli_to_gcs"),
-						attribute.Int("mavlink.bytes", len(data)),
-						attribute.String("client.addr", clientAddr),
-						attribute.String("transport", "udp"),
-					),
-				)
+			tcpSpan.SetAttributes(attribute.Int("bytes_written", n))
+			tcpSpan.SetStatus(codes.Ok, "data sent to MAVProxy")
+		}
+		tcpSpan.End()
+	}
+	b.tcpMutex.RUnlock()
 
-				n, err := b.udpConn.WriteToUDP(data, addr)
-				if err != nil {
-					b.logger.WithError(err).WithField("client", clientAddr).Error("Failed to write to UDP client")
-					udpSpan.RecordError(err)
-					udpSpan.SetStatus(codes.Error, "udp write failed")
-				} else {
+	// Forward to all UDP clients
+	if b.udpConn != nil {
+		b.udpMutex.RLock()
+		for clientAddr, addr := range b.udpClients {
+			_, udpSpan := tracer.Start(ctx, "mavlink.cli.udp_write",
+				trace.WithAttributes(
+					attribute.String("direction", "cli_to_gcs"),
+					attribute.Int("mavlink.bytes", len(data)),
+					attribute.String("client.addr", clientAddr),
+					attribute.String("transport", "udp"),
+				),
+			)
+
+			n, err := b.udpConn.WriteToUDP(data, addr)
+			if err != nil {
+				b.logger.WithError(err).WithField("client", clientAddr).Error("Failed to write to UDP client")
+				udpSpan.RecordError(err)
+				udpSpan.SetStatus(codes.Error, "udp write failed")
+			} else {
+				if debug {
 					b.logger.WithFields(log.Fields{
 						"client":   clientAddr,
 						"bytes":    n,
 						"trace_id": udpSpan.SpanContext().TraceID().String(),
 					}).Debug("CLI wrote data to UDP client")
-					udpSpan.SetAttributes(attribute.Int("bytes_written", n))
-					udpSpan.SetStatus(codes.Ok, "data sent to GCS")
 				}
-				udpSpan.End()
+				b.stats.ClientWrite("udp", clientAddr, n)
+				udpSpan.SetAttributes(attribute.Int("bytes_written", n))
+				udpSpan.SetStatus(codes.Ok, "data sent to GCS")
 			}
-			b.udpMutex.RUnlock()
+			udpSpan.End()
 		}
+		b.udpMutex.RUnlock()
 	}
 }
 
-// writeToWebSocket writes data to the WebSocket
+// writeToWebSocket writes data to the WebSocket, batched with other small
+// writes with BatchInterval
 func (b *Bridge) writeToWebSocket(data []byte) error {
-	b.wsMutex.Lock()
-	defer b.wsMutex.Unlock()
+	if b.config.BatchInterval > 0 && !b.config.Aux {
+		return b.batchUplink(data)
+	}
+	return b.sendUplink(data)
+}
+
+// sendUplink writes data to the WebSocket as one message
+func (b *Bridge) sendUplink(data []byte) error {
+	msgType := websocket.BinaryMessage
+	if b.config.Aux {
+		msgType = websocket.TextMessage
+	}
+	return b.writeMessage(msgType, data)
+}
 
-	if b.wsConn == nil {
-		return fmt.Errorf("WebSocket not connected")
+// wsWriteTimeout bounds one write to the primary connection
+const wsWriteTimeout = 10 * time.Second
+
+// writeMessage sends one WebSocket message. While the primary connection is
+// being replaced it fails with ErrNotConnected rather than waiting, and a
+// write that can't get through in wsWriteTimeout fails too, so a dead socket
+// doesn't hold up every writer.
+func (b *Bridge) writeMessage(msgType int, data []byte) error {
+	// Uplink goes over one link only, so the vehicle never sees a command
+	// twice: the primary while it's up, otherwise the bond's backup
+	var err error
+	if b.bond == nil || !b.linkDown.Load() {
+		err = b.writePrimary(msgType, data)
 	}
 
-	return b.wsConn.WriteMessage(websocket.BinaryMessage, data)
+	if b.bond != nil && (err != nil || b.linkDown.Load()) {
+		if backupErr := b.bond.write(msgType, data); backupErr == nil || err == nil {
+			return backupErr
+		}
+	}
+	return err
 }
 
-// reconnectWebSocket attempts to reconnect to the WebSocket
-func (b *Bridge) reconnectWebSocket() error {
+// writePrimary writes one message to the primary connection
+func (b *Bridge) writePrimary(msgType int, data []byte) error {
+	b.wsWriteMu.Lock()
+	defer b.wsWriteMu.Unlock()
+
 	b.wsMutex.Lock()
-	defer b.wsMutex.Unlock()
+	conn := b.wsConn
+	b.wsMutex.Unlock()
+	if conn == nil {
+		return ErrNotConnected
+	}
 
+	_ = conn.SetWriteDeadline(time.Now().Add(wsWriteTimeout))
+	if err := conn.WriteMessage(msgType, data); err != nil {
+		b.wsMutex.Lock()
+		replaced := b.wsConn != conn
+		b.wsMutex.Unlock()
+		if replaced {
+			// Closed under us by a reconnect or shutdown
+			return ErrNotConnected
+		}
+		return err
+	}
+	return nil
+}
+
+// reconnectWebSocket replaces the WebSocket connection. The old connection
+// is closed first, so writes fail with ErrNotConnected while the new one is
+// dialed instead of queueing behind the dial.
+func (b *Bridge) reconnectWebSocket() error {
 	b.logger.Info("Attempting to reconnect WebSocket")
 
 	// Close old connection
-	if b.wsConn != nil {
-		_ = b.wsConn.Close()
-		b.wsConn = nil
+	b.wsMutex.Lock()
+	old := b.wsConn
+	b.wsConn = nil
+	b.wsMutex.Unlock()
+	if old != nil {
+		_ = old.Close()
 	}
 
 	// Create new connection
-	header := http.Header{}
-	if b.config.AuthToken != "" {
-		header.Add("Authorization", "Bearer "+b.config.AuthToken)
-	}
-
-	dialer := websocket.Dialer{
-		HandshakeTimeout: 10 * time.Second,
-	}
-
-	conn, _, err := dialer.Dial(b.config.WebSocketURL, header)
+	conn, err := b.dialWebSocket(b.ctx)
 	if err != nil {
 		return fmt.Errorf("WebSocket reconnect failed: %w", err)
 	}
 
+	b.wsMutex.Lock()
+	if b.ctx.Err() != nil {
+		// Shut down while dialing
+		b.wsMutex.Unlock()
+		_ = conn.Close()
+		return b.ctx.Err()
+	}
 	b.wsConn = conn
+	b.wsMutex.Unlock()
+
+	b.connData.Store(false)
+	b.link.Reconnected()
 	b.logger.Info("WebSocket reconnected")
+	b.sourceRates.requestReapply()
+	if b.linkDown.Swap(false) {
+		b.linkState(true)
+		if b.bond != nil {
+			b.bond.primaryRestored()
+		}
+		if b.config.OnLink != nil {
+			b.config.OnLink(true, nil)
+		}
+	}
 
 	return nil
 }
 
-// recordFailure records a connection failure and opens circuit if threshold is reached
-func (b *Bridge) recordFailure() {
-	b.wsMutex.Lock()
-	defer b.wsMutex.Unlock()
-
-	b.failureCount++
-	b.lastFailureTime = time.Now()
-
-	if b.failureCount >= b.failureThreshold && b.circuitState == "closed" {
-		b.circuitState = "open"
-		b.circuitOpenUntil = time.Now().Add(b.circuitOpenPeriod)
-		fmt.Printf("\n⚠️  Device MAVLink proxy is not running.\n")
-		fmt.Printf("   Please start the aircast-agent on your device.\n")
-		fmt.Printf("   Retrying in %v...\n\n", b.circuitOpenPeriod)
-	}
-}
-
-// resetCircuit resets the circuit breaker after successful connection
-func (b *Bridge) resetCircuit() {
-	b.wsMutex.Lock()
-	defer b.wsMutex.Unlock()
-
-	if b.failureCount > 0 {
-		fmt.Println("\n✅ Connected! MAVLink data is flowing.\n")
+// redial reconnects after the server moved the device, pausing before the
+// next attempt if that fails
+func (b *Bridge) redial() {
+	if err := b.reconnectWebSocket(); err != nil {
+		b.reconnectFailed(err)
 	}
-	b.failureCount = 0
-	b.circuitState = "closed"
 }
@@ -0,0 +1,57 @@
+package cli
+
+import (
+	"time"
+
+	"github.com/pavliha/aircast/aircast-cli/internal/mavlink"
+	log "github.com/sirupsen/logrus"
+)
+
+// How long a new client's banner waits for the vehicle to appear on the
+// downlink, and how often it checks
+const (
+	bannerWait = 10 * time.Second
+	bannerPoll = 250 * time.Millisecond
+)
+
+// greetClient sends a new client the ClientBanner as a STATUSTEXT from the
+// vehicle, so an operator with several links open can tell them apart.
+// Ground stations only show messages from a vehicle they know, so the
+// banner waits for the autopilot's system ID and is skipped if it never
+// appears.
+func (b *Bridge) greetClient(id string, write func([]byte) error) {
+	if b.config.ClientBanner == "" || b.config.Aux {
+		return
+	}
+
+	b.tasks.Go("client-banner", func() {
+		logger := b.logger.WithField("client", id)
+		deadline := time.Now().Add(bannerWait)
+
+		sysID, ok := b.vehicleSysID()
+		for !ok {
+			if time.Now().After(deadline) {
+				logger.Debug("No vehicle on the downlink, client banner not sent")
+				return
+			}
+			select {
+			case <-b.ctx.Done():
+				return
+			case <-time.After(bannerPoll):
+			}
+			sysID, ok = b.vehicleSysID()
+		}
+
+		frame, err := mavlink.EncodeStatusText(uint8(b.txSeq.Add(1)), sysID, bridgeCompID,
+			mavlink.SeverityInfo, b.config.ClientBanner)
+		if err != nil {
+			logger.WithError(err).Error("Failed to encode client banner")
+			return
+		}
+		if err := write(frame); err != nil {
+			logger.WithError(err).Debug("Failed to send client banner")
+			return
+		}
+		logger.WithFields(log.Fields{"banner": b.config.ClientBanner}).Debug("Sent client banner")
+	})
+}
@@ -0,0 +1,160 @@
+package cli
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// kickBlockPeriod is how long a kicked UDP client is ignored. UDP has no
+// connection to close, so without it the next packet would re-register it.
+const kickBlockPeriod = time.Minute
+
+// ClientStats describes a ground control station connected to the bridge
+type ClientStats struct {
+	ID           string    `json:"id"`
+	Transport    string    `json:"transport"`
+	Address      string    `json:"address"`
+	ConnectedAt  time.Time `json:"connected_at"`
+	LastActivity time.Time `json:"last_activity"`
+	BytesIn      uint64    `json:"bytes_in"`   // Received from the client
+	BytesOut     uint64    `json:"bytes_out"`  // Sent to the client
+	Writes       uint64    `json:"writes"`     // Socket writes made to the client
+	Duplicates   uint64    `json:"duplicates"` // Frames suppressed as repeats of another client's
+}
+
+// AvgWriteSize returns the mean number of bytes per socket write
+func (c ClientStats) AvgWriteSize() float64 {
+	if c.Writes == 0 {
+		return 0
+	}
+	return float64(c.BytesOut) / float64(c.Writes)
+}
+
+// clientID builds the identifier used to address a client, e.g. "tcp:127.0.0.1:50412"
+func clientID(transport, addr string) string {
+	return transport + ":" + addr
+}
+
+// ClientConnected starts tracking a client
+func (s *Stats) ClientConnected(transport, addr string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	s.clients[clientID(transport, addr)] = &ClientStats{
+		ID:           clientID(transport, addr),
+		Transport:    transport,
+		Address:      addr,
+		ConnectedAt:  now,
+		LastActivity: now,
+	}
+}
+
+// ClientDisconnected stops tracking a client
+func (s *Stats) ClientDisconnected(transport, addr string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.clients, clientID(transport, addr))
+}
+
+// ClientRead records bytes received from a client
+func (s *Stats) ClientRead(transport, addr string, n int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if c, ok := s.clients[clientID(transport, addr)]; ok {
+		c.BytesIn += uint64(n)
+		c.LastActivity = time.Now()
+	}
+}
+
+// ClientWrite records bytes sent to a client
+func (s *Stats) ClientWrite(transport, addr string, n int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if c, ok := s.clients[clientID(transport, addr)]; ok {
+		c.BytesOut += uint64(n)
+		c.Writes++
+	}
+}
+
+// Clients returns the connected clients ordered by connection time
+func (s *Stats) Clients() []ClientStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	clients := make([]ClientStats, 0, len(s.clients))
+	for _, c := range s.clients {
+		clients = append(clients, *c)
+	}
+	sort.Slice(clients, func(i, j int) bool {
+		return clients[i].ConnectedAt.Before(clients[j].ConnectedAt)
+	})
+
+	return clients
+}
+
+// clientEvent reports a client connecting or disconnecting to Config.OnClient
+func (b *Bridge) clientEvent(id string, connected bool) {
+	if b.config.OnClient != nil {
+		b.config.OnClient(id, connected)
+	}
+}
+
+// Clients returns statistics for all connected clients
+func (b *Bridge) Clients() []ClientStats {
+	return b.stats.Clients()
+}
+
+// KickClient disconnects a client by ID ("tcp:<addr>" or "udp:<addr>").
+// A bare address is accepted when it is unambiguous.
+func (b *Bridge) KickClient(id string) error {
+	transport, addr, ok := strings.Cut(id, ":")
+	if !ok || (transport != "tcp" && transport != "udp") {
+		// Bare address - find the transport it belongs to
+		transport, addr = "", id
+		for _, c := range b.stats.Clients() {
+			if c.Address == addr {
+				if transport != "" {
+					return fmt.Errorf("address %s is ambiguous, use tcp:%s or udp:%s", addr, addr, addr)
+				}
+				transport = c.Transport
+			}
+		}
+		if transport == "" {
+			return fmt.Errorf("client %s not found", id)
+		}
+	}
+
+	switch transport {
+	case "tcp":
+		b.tcpMutex.RLock()
+		client, exists := b.tcpClients[addr]
+		b.tcpMutex.RUnlock()
+		if !exists {
+			return fmt.Errorf("client %s not found", id)
+		}
+		// Closing the connection makes handleTCPClient clean up
+		_ = client.Close()
+
+	case "udp":
+		b.udpMutex.Lock()
+		_, exists := b.udpClients[addr]
+		if exists {
+			delete(b.udpClients, addr)
+			b.udpBlocked[addr] = time.Now().Add(kickBlockPeriod)
+		}
+		b.udpMutex.Unlock()
+		if !exists {
+			return fmt.Errorf("client %s not found", id)
+		}
+		b.stats.ClientDisconnected("udp", addr)
+		b.clientEvent(clientID("udp", addr), false)
+	}
+
+	b.logger.WithField("client", clientID(transport, addr)).Warn("Client kicked")
+	return nil
+}
@@ -0,0 +1,73 @@
+package cli
+
+import (
+	"hash/maphash"
+	"sync"
+	"time"
+
+	"github.com/pavliha/aircast/aircast-cli/internal/mavlink"
+)
+
+// dedupKey identifies uplink frames with the same source, message and
+// payload; sequence numbers differ between clients and are ignored
+type dedupKey struct {
+	sysID   uint8
+	compID  uint8
+	msgID   uint32
+	payload uint64 // Hash of the payload
+}
+
+// dedupEntry remembers who last forwarded a frame and when
+type dedupEntry struct {
+	stream *frameStream
+	at     time.Time
+}
+
+// uplinkDedup suppresses frames that a client sends while an identical
+// frame from another client was forwarded within the window, e.g. when two
+// ground stations both request the same data streams
+type uplinkDedup struct {
+	mu        sync.Mutex
+	window    time.Duration
+	seed      maphash.Seed
+	seen      map[dedupKey]dedupEntry
+	lastPrune time.Time
+}
+
+// newUplinkDedup creates a de-duplicator for the given window
+func newUplinkDedup(window time.Duration) *uplinkDedup {
+	return &uplinkDedup{
+		window: window,
+		seed:   maphash.MakeSeed(),
+		seen:   make(map[dedupKey]dedupEntry),
+	}
+}
+
+// duplicate reports whether a frame from stream repeats one another client
+// sent within the window; frames that pass are remembered
+func (d *uplinkDedup) duplicate(stream *frameStream, f *mavlink.Frame, now time.Time) bool {
+	key := dedupKey{
+		sysID:   f.SysID,
+		compID:  f.CompID,
+		msgID:   f.MsgID,
+		payload: maphash.Bytes(d.seed, f.Payload),
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if now.Sub(d.lastPrune) >= d.window {
+		for k, e := range d.seen {
+			if now.Sub(e.at) >= d.window {
+				delete(d.seen, k)
+			}
+		}
+		d.lastPrune = now
+	}
+
+	if e, ok := d.seen[key]; ok && e.stream != stream && now.Sub(e.at) < d.window {
+		return true
+	}
+	d.seen[key] = dedupEntry{stream: stream, at: now}
+	return false
+}
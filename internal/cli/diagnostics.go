@@ -0,0 +1,179 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// maxDiagnosticEvents bounds the connection event history kept for
+// post-mortems; lowMemoryDiagnosticEvents applies in low-memory mode
+const (
+	maxDiagnosticEvents       = 100
+	lowMemoryDiagnosticEvents = 20
+)
+
+// ConnectionEvent is a single entry in the connection history
+type ConnectionEvent struct {
+	Time   time.Time `json:"time"`
+	Kind   string    `json:"kind"`
+	Detail string    `json:"detail,omitempty"`
+}
+
+// Diagnostics summarizes the WebSocket connection history, used to explain
+// why a session never carried any data
+type Diagnostics struct {
+	StartedAt         time.Time         `json:"started_at"`
+	HandshakeAttempts int               `json:"handshake_attempts"`
+	HandshakeFailures int               `json:"handshake_failures"`
+	LastHandshakeCode int               `json:"last_handshake_status,omitempty"` // HTTP status of the last failed handshake
+	LastHandshakeErr  string            `json:"last_handshake_error,omitempty"`
+	Connected         bool              `json:"connected"`
+	FirstDataAt       *time.Time        `json:"first_data_at,omitempty"`
+	CloseCodes        map[int]int       `json:"close_codes,omitempty"`
+	CircuitOpens      int               `json:"circuit_opens"`
+	Events            []ConnectionEvent `json:"events"`
+}
+
+// ReceivedData reports whether any WebSocket data arrived during the session
+func (d Diagnostics) ReceivedData() bool {
+	return d.FirstDataAt != nil
+}
+
+// Verdict returns a one-line explanation of the most likely failure cause
+func (d Diagnostics) Verdict() string {
+	switch {
+	case d.ReceivedData():
+		return "Data was received; the connection worked."
+	case d.HandshakeAttempts == 0:
+		return "No connection was attempted."
+	case !d.Connected && (d.LastHandshakeCode == http.StatusUnauthorized || d.LastHandshakeCode == http.StatusForbidden):
+		return fmt.Sprintf("The server rejected the token (HTTP %d). Run with --login to re-authenticate.", d.LastHandshakeCode)
+	case !d.Connected && d.LastHandshakeCode == http.StatusNotFound:
+		return "The device was not found (HTTP 404). Check the device ID and that it belongs to your account."
+	case !d.Connected && d.LastHandshakeCode != 0:
+		return fmt.Sprintf("The WebSocket handshake failed with HTTP %d.", d.LastHandshakeCode)
+	case !d.Connected:
+		return "The WebSocket endpoint could not be reached. Check your network, proxy and firewall."
+	case len(d.CloseCodes) > 0:
+		return "The server kept closing the connection. The device's MAVLink proxy (aircast-agent) is most likely not running."
+	default:
+		return "Connected, but the device sent no MAVLink data. Check that aircast-agent is running and the flight controller is attached."
+	}
+}
+
+// diagnostics records connection events as they happen
+type diagnostics struct {
+	mu       sync.Mutex
+	d        Diagnostics
+	dataSeen atomic.Bool // Lets DataReceived skip the lock on the hot path
+
+	maxEvents int // Bound on the event history
+}
+
+// newDiagnostics creates an empty connection history
+func newDiagnostics(lowMemory bool) *diagnostics {
+	maxEvents := maxDiagnosticEvents
+	if lowMemory {
+		maxEvents = lowMemoryDiagnosticEvents
+	}
+	return &diagnostics{
+		d:         Diagnostics{StartedAt: time.Now(), CloseCodes: make(map[int]int)},
+		maxEvents: maxEvents,
+	}
+}
+
+// event appends to the bounded event history. Caller must hold mu.
+func (g *diagnostics) event(kind, detail string) {
+	if len(g.d.Events) >= g.maxEvents {
+		g.d.Events = append(g.d.Events[:0], g.d.Events[1:]...)
+	}
+	g.d.Events = append(g.d.Events, ConnectionEvent{Time: time.Now(), Kind: kind, Detail: detail})
+}
+
+// Handshake records the outcome of a WebSocket dial
+func (g *diagnostics) Handshake(resp *http.Response, err error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.d.HandshakeAttempts++
+	if err == nil {
+		g.d.Connected = true
+		g.event("handshake_ok", "")
+		return
+	}
+
+	g.d.HandshakeFailures++
+	g.d.LastHandshakeErr = err.Error()
+	g.d.LastHandshakeCode = 0
+	detail := err.Error()
+	if resp != nil {
+		g.d.LastHandshakeCode = resp.StatusCode
+		detail = fmt.Sprintf("HTTP %d: %s", resp.StatusCode, err)
+	}
+	g.event("handshake_failed", detail)
+}
+
+// ReadError records why an established connection stopped delivering data
+func (g *diagnostics) ReadError(err error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	var closeErr *websocket.CloseError
+	if errors.As(err, &closeErr) {
+		g.d.CloseCodes[closeErr.Code]++
+		g.event("closed", fmt.Sprintf("code %d: %s", closeErr.Code, closeErr.Text))
+		return
+	}
+	g.event("read_error", err.Error())
+}
+
+// Moved records the server moving the device to another URL
+func (g *diagnostics) Moved(url, via string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.event("moved", fmt.Sprintf("%s (%s)", url, via))
+}
+
+// CircuitOpened records the circuit breaker tripping on a class of failure
+func (g *diagnostics) CircuitOpened(class FailureClass, failures int) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.d.CircuitOpens++
+	g.event("circuit_open", fmt.Sprintf("%s after %d failures", class, failures))
+}
+
+// DataReceived records the first WebSocket message
+func (g *diagnostics) DataReceived() {
+	if g.dataSeen.Swap(true) {
+		return
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	now := time.Now()
+	g.d.FirstDataAt = &now
+	g.event("first_data", "")
+}
+
+// Snapshot returns a copy of the connection history
+func (g *diagnostics) Snapshot() Diagnostics {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	d := g.d
+	d.CloseCodes = make(map[int]int, len(g.d.CloseCodes))
+	for code, n := range g.d.CloseCodes {
+		d.CloseCodes[code] = n
+	}
+	d.Events = append([]ConnectionEvent(nil), g.d.Events...)
+	return d
+}
@@ -0,0 +1,113 @@
+package cli
+
+import (
+	"github.com/pavliha/aircast/aircast-cli/internal/mavlink"
+	log "github.com/sirupsen/logrus"
+)
+
+// MAV_CMD values of emergency commands
+const (
+	cmdNavReturnToLaunch   = 20
+	cmdNavLand             = 21
+	cmdNavVTOLLand         = 85
+	cmdDoFlightTermination = 185
+	cmdDoParachute         = 208
+	cmdComponentArmDisarm  = 400
+
+	// forceDisarmMagic in param2 of MAV_CMD_COMPONENT_ARM_DISARM disarms even
+	// in flight, stopping the motors
+	forceDisarmMagic = 21196
+	// parachuteRelease is the PARACHUTE_ACTION that deploys the parachute
+	parachuteRelease = 2
+)
+
+// emergencyModes are the flight modes a change to is an emergency command,
+// as ground stations send return to launch and land as mode changes
+var emergencyModes = map[string]bool{
+	"RTL": true, "SMART_RTL": true, "AUTO_RTL": true, "QRTL": true,
+	"LAND": true, "QLAND": true,
+}
+
+// emergencyCommand returns the name of the emergency command a ground
+// station frame requests: return to launch, land, flight termination,
+// parachute release or a forced disarm that kills the motors. Mode changes
+// to a return or landing mode count as well, once the vehicle's heartbeat
+// tells how to read them.
+func (b *Bridge) emergencyCommand(frame *mavlink.Frame) (string, bool) {
+	if req, ok := frame.ModeRequest(); ok {
+		b.telemetryMu.Lock()
+		seen, autopilot, vehicleType := b.telemetry.HaveHeartbeat, b.telemetry.Autopilot, b.telemetry.VehicleType
+		b.telemetryMu.Unlock()
+		if !seen {
+			return "", false
+		}
+		mode := req.Mode(autopilot, vehicleType)
+		return mode, emergencyModes[mode]
+	}
+
+	cmd, ok := frame.Command()
+	if !ok {
+		return "", false
+	}
+
+	switch cmd.ID {
+	case cmdNavReturnToLaunch:
+		return "RTL", true
+	case cmdNavLand:
+		return "LAND", true
+	case cmdNavVTOLLand:
+		return "VTOL_LAND", true
+	case cmdDoFlightTermination:
+		return "FLIGHT_TERMINATION", cmd.Param1 >= 0.5
+	case cmdDoParachute:
+		return "PARACHUTE_RELEASE", cmd.Param1 == parachuteRelease
+	case cmdComponentArmDisarm:
+		return "MOTOR_KILL", cmd.Param1 == 0 && cmd.Param2 == forceDisarmMagic
+	}
+	return "", false
+}
+
+// logEmergency records an emergency command sent ahead of other traffic
+func (b *Bridge) logEmergency(stream *frameStream, name string, frame *mavlink.Frame) {
+	var target uint8
+	if req, ok := frame.ModeRequest(); ok {
+		target = req.TargetSystem
+	} else if cmd, ok := frame.Command(); ok {
+		target = cmd.TargetSystem
+	}
+	b.logger.WithFields(log.Fields{
+		"command":       name,
+		"client":        stream.client,
+		"sys_id":        frame.SysID,
+		"target_system": target,
+	}).Warn("Emergency command from ground station, sending immediately")
+}
+
+// forwardUplink sends a client's frames to the WebSocket. Frames with an
+// emergency command skip the uplink batch: they go out at once, right after
+// anything already queued so the order is kept. They also get through a
+// simulated training outage, which is never worth risking a real vehicle.
+// In the read-only last minute of a guest session, inspectFrames has
+// already taken the commands out.
+func (b *Bridge) forwardUplink(stream *frameStream, data []byte) error {
+	if !stream.urgent {
+		if b.trainingOutage() == TrainingBoth {
+			return nil // Lost for a training drill
+		}
+		return b.writeToWebSocket(data)
+	}
+	stream.urgent = false
+
+	b.batch.mu.Lock()
+	defer b.batch.mu.Unlock()
+
+	// The emergency command is sent even if the batch before it failed
+	flushErr := b.batchErrLocked()
+	if err := b.flushBatchLocked(); err != nil && flushErr == nil {
+		flushErr = err
+	}
+	if err := b.sendUplink(data); err != nil {
+		return err
+	}
+	return flushErr
+}
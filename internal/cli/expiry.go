@@ -0,0 +1,109 @@
+package cli
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/pavliha/aircast/aircast-cli/internal/mavlink"
+)
+
+// ExpiryReadOnly is how long before a guest session expires that the
+// bridge stops forwarding ground station commands, so the session can't
+// end in the middle of a manoeuvre someone else has to finish
+const ExpiryReadOnly = time.Minute
+
+// expiryBlocked are the ground station messages held back in the read-only
+// last minute: commands, mode changes, guided setpoints, mission and
+// parameter writes and manual control. Everything else still flows, above
+// all heartbeats, whose loss would trip the vehicle's GCS failsafe, and
+// emergency commands, including mode changes to RTL or LAND.
+var expiryBlocked = messageSet(
+	"COMMAND_LONG",
+	"COMMAND_INT",
+	"SET_MODE",
+	"SET_POSITION_TARGET_LOCAL_NED",
+	"SET_POSITION_TARGET_GLOBAL_INT",
+	"SET_ATTITUDE_TARGET",
+	"SET_HOME_POSITION",
+	"MISSION_COUNT",
+	"MISSION_ITEM",
+	"MISSION_ITEM_INT",
+	"MISSION_WRITE_PARTIAL_LIST",
+	"MISSION_SET_CURRENT",
+	"MISSION_CLEAR_ALL",
+	"PARAM_SET",
+	"PARAM_EXT_SET",
+	"MANUAL_CONTROL",
+	"RC_CHANNELS_OVERRIDE",
+)
+
+// expiryWarnings are the times left at which ground stations and the
+// console are warned that the session is about to end
+var expiryWarnings = []time.Duration{30 * time.Minute, 10 * time.Minute, 5 * time.Minute, ExpiryReadOnly}
+
+// ExpiresAt returns when a guest session ends, zero if it doesn't
+func (b *Bridge) ExpiresAt() time.Time {
+	return b.config.ExpiresAt
+}
+
+// enforceExpiry warns ahead of Config.ExpiresAt, turns the uplink read-only
+// for the last minute and calls Config.OnExpire when the time is up
+func (b *Bridge) enforceExpiry() {
+	expiresAt := b.config.ExpiresAt
+	logger := b.logger.WithField("expires_at", expiresAt.Format(time.RFC3339))
+	for _, left := range expiryWarnings {
+		// Warnings that already passed when the session started are skipped
+		if time.Until(expiresAt) < left {
+			continue
+		}
+		if !b.sleepUntil(expiresAt.Add(-left)) {
+			return
+		}
+
+		if left == ExpiryReadOnly {
+			b.expiring.Store(true)
+			logger.Warn("Guest session ends in 1 minute; commands from ground stations are no longer forwarded")
+			b.broadcastStatusText(mavlink.SeverityWarning, "Aircast link ends in 1 min, commands blocked")
+			continue
+		}
+		logger.Warnf("Guest session ends in %s", left)
+		b.broadcastStatusText(mavlink.SeverityWarning, fmt.Sprintf("Aircast link ends in %.0f min", left.Minutes()))
+	}
+	b.expiring.Store(true)
+
+	if !b.sleepUntil(expiresAt) {
+		return
+	}
+	logger.Warn("Guest session expired")
+	b.broadcastStatusText(mavlink.SeverityWarning, "Aircast link expired")
+	if b.config.OnExpire != nil {
+		b.config.OnExpire()
+	}
+}
+
+// sleepUntil waits until t, returning false if the bridge stops first
+func (b *Bridge) sleepUntil(t time.Time) bool {
+	timer := time.NewTimer(time.Until(t))
+	defer timer.Stop()
+	select {
+	case <-b.ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
+
+// broadcastStatusText sends every client a STATUSTEXT from the vehicle; it
+// is dropped if no vehicle has appeared on the downlink yet
+func (b *Bridge) broadcastStatusText(severity uint8, text string) {
+	sysID, ok := b.vehicleSysID()
+	if !ok || b.config.Aux {
+		return
+	}
+	frame, err := mavlink.EncodeStatusText(uint8(b.txSeq.Add(1)), sysID, bridgeCompID, severity, text)
+	if err != nil {
+		b.logger.WithError(err).Error("Failed to encode STATUSTEXT")
+		return
+	}
+	b.writeToClients(frame)
+}
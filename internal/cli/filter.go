@@ -0,0 +1,196 @@
+package cli
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pavliha/aircast/aircast-cli/internal/mavlink"
+	"github.com/pavliha/aircast/aircast-cli/internal/settings"
+)
+
+// essentialMessages are never filtered: they carry request/response protocols
+// (parameters, missions, commands, logs) that break if any message is dropped
+var essentialMessages = messageSet(
+	"HEARTBEAT",
+	"STATUSTEXT",
+	"COMMAND_ACK",
+	"COMMAND_LONG",
+	"PARAM_VALUE",
+	"PARAM_EXT_VALUE",
+	"PARAM_EXT_ACK",
+	"MISSION_COUNT",
+	"MISSION_ITEM",
+	"MISSION_ITEM_INT",
+	"MISSION_REQUEST",
+	"MISSION_REQUEST_INT",
+	"MISSION_ACK",
+	"MISSION_ITEM_REACHED",
+	"HOME_POSITION",
+	"AUTOPILOT_VERSION",
+	"TIMESYNC",
+	"FILE_TRANSFER_PROTOCOL",
+	"LOG_ENTRY",
+	"LOG_DATA",
+)
+
+// messageSet resolves dialect message names to a set of IDs
+func messageSet(names ...string) map[uint32]bool {
+	set := make(map[uint32]bool, len(names))
+	for _, name := range names {
+		id, ok := mavlink.MessageID(name)
+		if !ok {
+			panic(fmt.Sprintf("unknown MAVLink message %q", name))
+		}
+		set[id] = true
+	}
+	return set
+}
+
+// BandwidthProfile bundles downlink message filters and rate limits.
+// Essential protocol messages always pass regardless of the profile.
+type BandwidthProfile = settings.BandwidthProfile
+
+// Built-in bandwidth profile names
+const (
+	ProfileFull            = "full"
+	ProfileLowBandwidth    = "low-bandwidth"
+	ProfileCellularMinimal = "cellular-minimal"
+)
+
+// heavyMessages are high-rate sensor and diagnostic streams a GCS rarely needs in flight
+var heavyMessages = []string{
+	"RAW_IMU", "SCALED_IMU2", "SCALED_IMU3", "SCALED_PRESSURE", "SCALED_PRESSURE2",
+	"SERVO_OUTPUT_RAW", "RC_CHANNELS_RAW", "SENSOR_OFFSETS", "MEMINFO", "HWSTATUS",
+	"POWER_STATUS", "AHRS", "AHRS2", "AHRS3", "SIMSTATE", "ESC_TELEMETRY_1_TO_4", "TERRAIN_REPORT",
+}
+
+// builtinProfiles are the profiles shipped with the CLI
+var builtinProfiles = map[string]BandwidthProfile{
+	ProfileFull: {
+		Description: "Forward everything",
+	},
+	ProfileLowBandwidth: {
+		Description: "Limit telemetry to 2 Hz (attitude 4 Hz) and drop raw sensor streams",
+		MaxRate:     2,
+		Rates:       map[string]float64{"ATTITUDE": 4},
+		Block:       heavyMessages,
+	},
+	ProfileCellularMinimal: {
+		Description: "Limit telemetry to 1 Hz and drop raw sensor and RC streams",
+		MaxRate:     1,
+		Block:       append([]string{"RC_CHANNELS", "VIBRATION"}, heavyMessages...),
+	},
+}
+
+// LookupProfile finds a profile by name, preferring user-defined profiles
+func LookupProfile(name string, custom map[string]BandwidthProfile) (BandwidthProfile, error) {
+	if p, ok := custom[name]; ok {
+		return p, nil
+	}
+	if p, ok := builtinProfiles[name]; ok {
+		return p, nil
+	}
+	return BandwidthProfile{}, fmt.Errorf("unknown bandwidth profile %q (available: %s)",
+		name, strings.Join(ProfileNames(custom), ", "))
+}
+
+// ProfileNames lists built-in and user-defined profile names
+func ProfileNames(custom map[string]BandwidthProfile) []string {
+	seen := make(map[string]bool)
+	var names []string
+	for _, set := range []map[string]BandwidthProfile{builtinProfiles, custom} {
+		for name := range set {
+			if !seen[name] {
+				seen[name] = true
+				names = append(names, name)
+			}
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// rateInterval converts a rate in Hz to the minimum time between messages
+func rateInterval(hz float64) time.Duration {
+	if hz <= 0 {
+		return 0
+	}
+	return time.Duration(float64(time.Second) / hz)
+}
+
+// filterKey identifies a message stream from one component
+type filterKey struct {
+	sysID  uint8
+	compID uint8
+	msgID  uint32
+}
+
+// rateFilter applies a bandwidth profile to the downlink.
+// It is only used from the WebSocket reader goroutine.
+type rateFilter struct {
+	name      string
+	interval  time.Duration            // Default minimum time between messages of one stream
+	intervals map[uint32]time.Duration // Per-message overrides
+	blocked   map[uint32]bool
+	last      map[filterKey]time.Time
+}
+
+// newRateFilter compiles a profile into a filter, or returns nil if the
+// profile forwards everything
+func newRateFilter(name string, p BandwidthProfile) (*rateFilter, error) {
+	if p.MaxRate <= 0 && len(p.Rates) == 0 && len(p.Block) == 0 {
+		return nil, nil
+	}
+
+	f := &rateFilter{
+		name:      name,
+		interval:  rateInterval(p.MaxRate),
+		intervals: make(map[uint32]time.Duration, len(p.Rates)),
+		blocked:   make(map[uint32]bool, len(p.Block)),
+		last:      make(map[filterKey]time.Time),
+	}
+
+	for msgName, hz := range p.Rates {
+		id, ok := mavlink.MessageID(strings.ToUpper(msgName))
+		if !ok {
+			return nil, fmt.Errorf("profile %s: unknown message %q", name, msgName)
+		}
+		f.intervals[id] = rateInterval(hz)
+	}
+	for _, msgName := range p.Block {
+		id, ok := mavlink.MessageID(strings.ToUpper(msgName))
+		if !ok {
+			return nil, fmt.Errorf("profile %s: unknown message %q", name, msgName)
+		}
+		f.blocked[id] = true
+	}
+
+	return f, nil
+}
+
+// Allow reports whether a frame should be forwarded
+func (f *rateFilter) Allow(frame *mavlink.Frame, now time.Time) bool {
+	if essentialMessages[frame.MsgID] {
+		return true
+	}
+	if f.blocked[frame.MsgID] {
+		return false
+	}
+
+	interval, ok := f.intervals[frame.MsgID]
+	if !ok {
+		interval = f.interval
+	}
+	if interval <= 0 {
+		return true
+	}
+
+	key := filterKey{sysID: frame.SysID, compID: frame.CompID, msgID: frame.MsgID}
+	if last, ok := f.last[key]; ok && now.Sub(last) < interval {
+		return false
+	}
+	f.last[key] = now
+	return true
+}
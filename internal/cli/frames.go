@@ -0,0 +1,178 @@
+package cli
+
+import (
+	"errors"
+	"time"
+
+	"github.com/pavliha/aircast/aircast-cli/internal/mavlink"
+	log "github.com/sirupsen/logrus"
+)
+
+// frameStream holds the parsing state for one source of MAVLink traffic
+type frameStream struct {
+	parser  *mavlink.Parser
+	out     []byte // Reused output buffer for filtered frames
+	partial []byte // Incomplete uplink line on an auxiliary channel
+	client  string // Client ID for uplink streams
+	urgent  bool   // The last uplink data holds an emergency command
+}
+
+// newFrameStream creates the parsing state for a new traffic source
+func (b *Bridge) newFrameStream() *frameStream {
+	if b.config.LowMemory {
+		return &frameStream{parser: mavlink.NewParserSize(lowMemoryParserBuffer)}
+	}
+	return &frameStream{
+		parser: mavlink.NewParser(),
+	}
+}
+
+// inspectFrames runs data through a stream's frame parser, validates each
+// frame's CRC and records statistics. It returns the bytes to forward: the
+// original data, or only the frames that pass when DropCorrupted is enabled
+// or a downlink rate filter is active.
+// The returned slice is only valid until the next call for the same stream.
+func (b *Bridge) inspectFrames(stream *frameStream, dir Direction, data []byte) []byte {
+	b.stats.AddBytes(dir, len(data))
+	b.dataUsed.Add(uint64(len(data)))
+	b.lastDataAt[dir].Store(time.Now().UnixNano())
+
+	if b.config.Aux {
+		return b.inspectAux(stream, dir, data)
+	}
+
+	var filter *rateFilter
+	if dir == Downlink {
+		filter = b.filter.Load()
+	}
+	remap := b.remaps(dir)
+	var dedup *uplinkDedup
+	if dir == Uplink {
+		dedup = b.dedup
+	}
+	expiring := dir == Uplink && b.expiring.Load()
+	rebuild := b.config.DropCorrupted || filter != nil || remap || dedup != nil || expiring
+	now := time.Now()
+
+	frames := stream.parser.Feed(data)
+
+	out := stream.out[:0]
+	for _, frame := range frames {
+		err := frame.Validate()
+		corrupted := errors.Is(err, mavlink.ErrChecksum)
+		unknown := errors.Is(err, mavlink.ErrUnknownMessage)
+		drop := corrupted && b.config.DropCorrupted
+
+		if corrupted {
+			b.logger.WithFields(log.Fields{
+				"direction": dir.String(),
+				"msg_id":    frame.MsgID,
+				"sys_id":    frame.SysID,
+				"comp_id":   frame.CompID,
+				"dropped":   drop,
+			}).Debug("Corrupted MAVLink frame")
+		}
+
+		b.stats.AddFrame(dir, corrupted, unknown, drop)
+
+		if rec := b.config.Recorder; rec != nil && !corrupted {
+			rec.WriteFrame(frame.Raw, dir == Uplink)
+		}
+		if !corrupted {
+			b.outputs.writeFrame(frame.Raw, dir == Uplink)
+		}
+
+		// Sequence gaps on the device path indicate loss; corrupted frames
+		// can't be trusted to carry a valid sequence number
+		if dir == Downlink && !corrupted {
+			b.stats.AddSequence(frame.SysID, frame.CompID, frame.Seq)
+		}
+
+		if dir == Uplink && err == nil && frame.MsgID == mavlink.MsgIDHeartbeat {
+			b.clientHeartbeatAt.Store(now.UnixNano())
+		}
+
+		if dir == Uplink && err == nil && stallRequests[frame.MsgID] {
+			b.requestAt.Store(now.UnixNano())
+		}
+
+		if dir == Downlink && err == nil && frame.MsgID == mavlink.MsgIDRadioStatus {
+			b.radioStatusAt.Store(now.UnixNano())
+		}
+
+		if dir == Uplink && err == nil {
+			b.gcsRates.uplinkFrame(&frame)
+		}
+
+		// Measure the autopilot's streams, and notice ground stations
+		// changing them, for source rate negotiation
+		if sr := b.sourceRates; sr != nil && err == nil {
+			if dir == Downlink && frame.CompID == autopilotCompID {
+				sr.observe(&frame)
+			} else if dir == Uplink {
+				sr.uplinkFrame(&frame)
+			}
+		}
+
+		// Emergency commands are sent at once and never suppressed
+		emergency := false
+		if dir == Uplink && err == nil {
+			var name string
+			if name, emergency = b.emergencyCommand(&frame); emergency {
+				stream.urgent = true
+				b.logEmergency(stream, name, &frame)
+			}
+		}
+
+		// Keep the latest vehicle state for observers such as the map view
+		if dir == Downlink && err == nil && (frame.CompID == autopilotCompID || frame.MsgID == mavlink.MsgIDRadioStatus) {
+			b.telemetryMu.Lock()
+			wasArmed := b.telemetry.Armed
+			b.telemetry.Apply(frame)
+			armed := b.telemetry.Armed
+			b.telemetryAt = now
+			b.telemetryMu.Unlock()
+
+			if armed != wasArmed && b.config.OnArmed != nil {
+				b.config.OnArmed(armed, now)
+			}
+		}
+
+		if !rebuild || drop {
+			continue
+		}
+		if expiring && !emergency && expiryBlocked[frame.MsgID] {
+			continue // The guest session is about to end
+		}
+		if filter != nil && !corrupted && !filter.Allow(&frame, now) {
+			b.stats.AddFiltered(dir)
+			continue
+		}
+		if dedup != nil && err == nil && !emergency && dedup.duplicate(stream, &frame, now) {
+			b.stats.AddDuplicate(dir, stream.client)
+			continue
+		}
+		if remap && err == nil {
+			if sysID, compID, ok := b.remapSource(dir, &frame); ok {
+				remapped, err := frame.AppendWithSource(out, sysID, compID)
+				if err == nil {
+					out = remapped
+					continue
+				}
+				b.logger.WithError(err).WithFields(log.Fields{
+					"direction": dir.String(),
+					"sys_id":    frame.SysID,
+					"comp_id":   frame.CompID,
+				}).Debug("Frame not remapped")
+			}
+		}
+		out = append(out, frame.Raw...)
+	}
+
+	if !rebuild {
+		return data
+	}
+
+	stream.out = out
+	return out
+}
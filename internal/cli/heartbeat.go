@@ -0,0 +1,92 @@
+package cli
+
+import (
+	"time"
+
+	"github.com/pavliha/aircast/aircast-cli/internal/mavlink"
+	log "github.com/sirupsen/logrus"
+)
+
+// HEARTBEAT fields identifying the bridge as a ground station
+const (
+	mavTypeGCS          = 6 // MAV_TYPE_GCS
+	mavAutopilotInvalid = 8 // MAV_AUTOPILOT_INVALID
+	mavStateActive      = 4 // MAV_STATE_ACTIVE
+	mavlinkVersion      = 3
+
+	// heartbeatCompID is MAV_COMP_ID_UDP_BRIDGE. Ground stations send as
+	// 255/190, so the device would otherwise see two sources with one ID
+	// whose sequence numbers interleave.
+	heartbeatCompID = 240
+)
+
+// HeartbeatConfig configures GCS heartbeats sent upstream to the device
+type HeartbeatConfig struct {
+	// Interval between heartbeats (0 = off)
+	Interval time.Duration
+	// SysID and CompID identify the heartbeats (default 255 and 240)
+	SysID  uint8
+	CompID uint8
+	// Always sends heartbeats even while a client sends its own; otherwise
+	// they only fill in when no client heartbeat arrived within the interval
+	Always bool
+}
+
+// heartbeatPayload encodes a ground station HEARTBEAT
+func heartbeatPayload() []byte {
+	payload := make([]byte, 9) // custom_mode (uint32) stays 0
+	payload[4] = mavTypeGCS
+	payload[5] = mavAutopilotInvalid
+	payload[6] = 0 // base_mode
+	payload[7] = mavStateActive
+	payload[8] = mavlinkVersion
+	return payload
+}
+
+// sendHeartbeats keeps device-side proxies that tear down without ground
+// station traffic alive by sending HEARTBEAT messages on behalf of clients,
+// whether or not any are connected
+func (b *Bridge) sendHeartbeats() {
+
+	hb := b.config.Heartbeat
+	if hb.SysID == 0 {
+		hb.SysID = bridgeSysID
+	}
+	if hb.CompID == 0 {
+		hb.CompID = heartbeatCompID
+	}
+	logger := b.logger.WithFields(log.Fields{"sys_id": hb.SysID, "comp_id": hb.CompID})
+
+	ticker := time.NewTicker(hb.Interval)
+	defer ticker.Stop()
+
+	payload := heartbeatPayload()
+	sent := 0
+	for {
+		select {
+		case <-b.ctx.Done():
+			return
+		case now := <-ticker.C:
+			if !hb.Always {
+				last := b.clientHeartbeatAt.Load()
+				if last != 0 && now.Sub(time.Unix(0, last)) < hb.Interval {
+					continue
+				}
+			}
+
+			frame, err := mavlink.EncodeV2(uint8(b.txSeq.Add(1)), hb.SysID, hb.CompID, mavlink.MsgIDHeartbeat, payload)
+			if err != nil {
+				logger.WithError(err).Error("Failed to encode heartbeat")
+				return
+			}
+			if err := b.writeToWebSocket(frame); err != nil {
+				logger.WithError(err).Debug("Failed to send heartbeat")
+				continue
+			}
+			if sent == 0 {
+				logger.Info("Sending heartbeats to the device")
+			}
+			sent++
+		}
+	}
+}
@@ -0,0 +1,118 @@
+package cli
+
+import (
+	"encoding/binary"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// linkPingInterval is how often the WebSocket round-trip time is measured
+const linkPingInterval = 10 * time.Second
+
+// rttSmoothing is the weight of a new sample in the smoothed RTT (as in TCP's SRTT)
+const rttSmoothing = 0.125
+
+// LinkStats summarizes the health of the WebSocket link to the API
+type LinkStats struct {
+	Reconnects uint64        `json:"reconnects"`
+	Stalls     uint64        `json:"stalls"` // Connections cycled by the stall watchdog
+	RTT        time.Duration `json:"rtt"`    // Smoothed round-trip time, 0 until measured
+	RTTMin     time.Duration `json:"rtt_min"`
+	RTTMax     time.Duration `json:"rtt_max"`
+	RTTSamples uint64        `json:"rtt_samples"`
+}
+
+// linkMonitor measures WebSocket round-trip times and counts reconnects
+type linkMonitor struct {
+	mu    sync.Mutex
+	stats LinkStats
+}
+
+// Reconnected records a successful reconnection
+func (m *linkMonitor) Reconnected() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.stats.Reconnects++
+}
+
+// Stalled records a connection cycled because data stopped arriving
+func (m *linkMonitor) Stalled() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.stats.Stalls++
+}
+
+// AddRTT records a round-trip time sample
+func (m *linkMonitor) AddRTT(rtt time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	s := &m.stats
+	if s.RTTSamples == 0 {
+		s.RTT, s.RTTMin, s.RTTMax = rtt, rtt, rtt
+	} else {
+		s.RTT += time.Duration(rttSmoothing * float64(rtt-s.RTT))
+		s.RTTMin = min(s.RTTMin, rtt)
+		s.RTTMax = max(s.RTTMax, rtt)
+	}
+	s.RTTSamples++
+}
+
+// Snapshot returns a copy of the link statistics
+func (m *linkMonitor) Snapshot() LinkStats {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.stats
+}
+
+// LinkStats returns WebSocket link health statistics
+func (b *Bridge) LinkStats() LinkStats {
+	return b.link.Snapshot()
+}
+
+// watchPongs measures the round-trip time of pings sent by pingLink. The
+// handler runs on the reader goroutine.
+func (b *Bridge) watchPongs(conn *websocket.Conn) {
+	conn.SetPongHandler(func(payload string) error {
+		if len(payload) == 8 {
+			sent := time.Unix(0, int64(binary.BigEndian.Uint64([]byte(payload))))
+			b.link.AddRTT(time.Since(sent))
+		}
+		return nil
+	})
+}
+
+// pingLink periodically pings the API to measure the link round-trip time
+func (b *Bridge) pingLink() {
+
+	interval := linkPingInterval
+	if b.config.AdaptiveMaxRate > 0 {
+		interval = adaptPingInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	payload := make([]byte, 8)
+	for {
+		select {
+		case <-b.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		b.wsMutex.Lock()
+		conn := b.wsConn
+		b.wsMutex.Unlock()
+		if conn == nil {
+			continue
+		}
+
+		// WriteControl may be called concurrently with other writes
+		binary.BigEndian.PutUint64(payload, uint64(time.Now().UnixNano()))
+		if err := conn.WriteControl(websocket.PingMessage, payload, time.Now().Add(5*time.Second)); err != nil {
+			b.logger.WithError(err).Debug("Failed to ping WebSocket")
+		}
+	}
+}
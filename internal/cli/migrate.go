@@ -0,0 +1,139 @@
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/gorilla/websocket"
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/net/publicsuffix"
+)
+
+// closeDeviceMoved is the WebSocket close code the server sends when the
+// device has moved to another node; the close reason carries the new URL
+const closeDeviceMoved = 4301
+
+// maxHandshakeRedirects bounds the redirects followed on one dial
+const maxHandshakeRedirects = 3
+
+// moveMessage is the text control frame announcing that the device moved,
+// e.g. {"type":"device_moved","url":"wss://node3.aircast.one/v1/mavlink/web/ID/ws"}
+type moveMessage struct {
+	Type string `json:"type"`
+	URL  string `json:"url"`
+}
+
+// currentURL returns the URL the WebSocket dials: where the server last
+// moved the device, the active route's endpoint or the configured URL
+func (b *Bridge) currentURL() string {
+	if moved := b.movedURL.Load(); moved != nil {
+		return *moved
+	}
+	if b.routes != nil {
+		return b.routes.activeURL()
+	}
+	return b.config.WebSocketURL
+}
+
+// moveTo points the WebSocket at the device's new URL, announced by the
+// server through via. It reports whether the move was accepted.
+func (b *Bridge) moveTo(target, via string) bool {
+	next, err := b.resolveMove(target)
+	if err != nil {
+		b.logger.WithError(err).WithField("url", target).Warn("Ignoring device move")
+		return false
+	}
+
+	b.movedURL.Store(&next)
+	b.diag.Moved(next, via)
+	b.logger.WithFields(log.Fields{"url": next, "via": via}).Info("Device moved, redialing")
+	return true
+}
+
+// resolveMove validates a URL announced by the server. It may be relative to
+// the current URL, but must stay on the same site and must not drop TLS, so
+// a misbehaving server can't send the login token elsewhere.
+func (b *Bridge) resolveMove(target string) (string, error) {
+	base, err := url.Parse(b.currentURL())
+	if err != nil {
+		return "", err
+	}
+	next, err := base.Parse(target)
+	if err != nil {
+		return "", fmt.Errorf("invalid URL: %w", err)
+	}
+
+	switch next.Scheme {
+	case "http":
+		next.Scheme = "ws"
+	case "https":
+		next.Scheme = "wss"
+	}
+	switch {
+	case next.Scheme != "ws" && next.Scheme != "wss":
+		return "", fmt.Errorf("unsupported scheme %q", next.Scheme)
+	case base.Scheme == "wss" && next.Scheme == "ws":
+		return "", errors.New("refusing to move to an unencrypted connection")
+	case !sameSite(base.Hostname(), next.Hostname()):
+		return "", fmt.Errorf("refusing to move from %s to another site", base.Hostname())
+	}
+	return next.String(), nil
+}
+
+// revertMove returns to the configured URL after the new node failed
+func (b *Bridge) revertMove() {
+	if b.movedURL.Swap(nil) != nil {
+		b.logger.WithField("url", b.currentURL()).Warn("Device's new node unreachable, falling back to the original URL")
+	}
+}
+
+// handshakeRedirect returns the target of a redirected WebSocket handshake
+func handshakeRedirect(resp *http.Response, err error) (string, bool) {
+	if !errors.Is(err, websocket.ErrBadHandshake) || resp == nil {
+		return "", false
+	}
+	switch resp.StatusCode {
+	case http.StatusMovedPermanently, http.StatusFound, http.StatusTemporaryRedirect, http.StatusPermanentRedirect:
+		location := resp.Header.Get("Location")
+		return location, location != ""
+	}
+	return "", false
+}
+
+// closeMove returns the new URL from a close frame announcing a move
+func closeMove(err error) (string, bool) {
+	var closeErr *websocket.CloseError
+	if errors.As(err, &closeErr) && closeErr.Code == closeDeviceMoved && closeErr.Text != "" {
+		return closeErr.Text, true
+	}
+	return "", false
+}
+
+// messageMove returns the new URL from a text control frame announcing a move
+func messageMove(data []byte) (string, bool) {
+	var msg moveMessage
+	if json.Unmarshal(data, &msg) != nil || msg.Type != "device_moved" || msg.URL == "" {
+		return "", false
+	}
+	return msg.URL, true
+}
+
+// sameSite reports whether two hosts belong to the same site: identical, or
+// under the same registrable domain (api.aircast.one and node3.aircast.one,
+// but not a.github.io and b.github.io)
+func sameSite(a, b string) bool {
+	if strings.EqualFold(a, b) {
+		return true
+	}
+	if net.ParseIP(a) != nil || net.ParseIP(b) != nil {
+		return false
+	}
+	siteA, errA := publicsuffix.EffectiveTLDPlusOne(strings.ToLower(strings.TrimSuffix(a, ".")))
+	siteB, errB := publicsuffix.EffectiveTLDPlusOne(strings.ToLower(strings.TrimSuffix(b, ".")))
+	return errA == nil && errB == nil && siteA == siteB
+}
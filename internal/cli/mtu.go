@@ -0,0 +1,95 @@
+package cli
+
+import (
+	"crypto/tls"
+	"fmt"
+	"runtime"
+
+	"github.com/gorilla/websocket"
+	"github.com/pavliha/aircast/aircast-cli/internal/network"
+	"github.com/pavliha/aircast/aircast-cli/internal/term"
+	log "github.com/sirupsen/logrus"
+)
+
+// Per-packet overhead in front of a WebSocket message's payload
+const (
+	ipv4Header      = 20
+	ipv6Header      = 40
+	tcpHeader       = 32 // Including the timestamp option Linux sends by default
+	tlsRecordHeader = 29 // Record header, explicit nonce and AEAD tag
+	wsHeader        = 8  // Client frames of 126-65535 bytes, with the mask
+)
+
+// PathMTUStats describes the path MTU of the WebSocket connection
+type PathMTUStats struct {
+	network.PathMTU
+	Budget int `json:"budget"` // Largest WebSocket message that fits in one packet
+}
+
+// Reduced reports whether the path is narrower than Ethernet or runs through
+// a tunnel, whose VPN may drop full-size packets without reporting its MTU
+func (s PathMTUStats) Reduced() bool {
+	return s.MTU > 0 && (s.MTU < network.EthernetMTU || s.Tunnel)
+}
+
+// PathMTU returns the path MTU of the WebSocket connection, zero until it
+// has connected
+func (b *Bridge) PathMTU() PathMTUStats {
+	if s := b.pathMTU.Load(); s != nil {
+		return *s
+	}
+	return PathMTUStats{}
+}
+
+// checkPathMTU probes the path MTU of a new WebSocket connection and warns,
+// with what to do about it, when it changed and is reduced
+func (b *Bridge) checkPathMTU(conn *websocket.Conn) {
+	p, err := network.ProbePathMTU(conn.NetConn())
+	if err != nil {
+		// e.g. through an HTTP proxy, where the path beyond it is unknown
+		b.logger.WithError(err).Debug("Path MTU unknown")
+		return
+	}
+
+	s := PathMTUStats{PathMTU: p, Budget: p.MTU - tcpHeader - wsHeader}
+	if p.IPv6 {
+		s.Budget -= ipv6Header
+	} else {
+		s.Budget -= ipv4Header
+	}
+	if _, ok := conn.NetConn().(*tls.Conn); ok {
+		s.Budget -= tlsRecordHeader
+	}
+
+	previous := b.pathMTU.Swap(&s)
+	if s.Reduced() && (previous == nil || previous.PathMTU != s.PathMTU) {
+		b.logger.WithFields(log.Fields{
+			"mtu":       s.MTU,
+			"interface": s.Interface,
+			"tunnel":    s.Tunnel,
+			"budget":    s.Budget,
+		}).Warn("Reduced path MTU; parameter and mission transfers may stall")
+		b.printMTUAdvice(s)
+	}
+}
+
+// printMTUAdvice tells the operator how to keep a tunnel that drops
+// full-size packets from stalling large transfers, as in the README
+func (b *Bridge) printMTUAdvice(s PathMTUStats) {
+	out := b.config.Output
+	if s.Interface == "" {
+		fmt.Fprintf(out, "\n%sPath MTU is %d. If parameter or mission transfers stall,\n", term.Symbol("⚠️  ", "Warning: "), s.MTU)
+	} else {
+		fmt.Fprintf(out, "\n%sPath MTU is %d via %s. If parameter or mission transfers stall,\n", term.Symbol("⚠️  ", "Warning: "), s.MTU, s.Interface)
+	}
+	if runtime.GOOS == "linux" {
+		fmt.Fprintf(out, "   let Linux find the working size with 'sudo sysctl -w net.ipv4.tcp_mtu_probing=1'\n")
+		if s.Interface != "" {
+			fmt.Fprintf(out, "   or lower the interface's MTU, e.g. 'sudo ip link set %s mtu 1280'.\n\n", s.Interface)
+			return
+		}
+		fmt.Fprintf(out, "   or lower the tunnel's MTU, e.g. to 1280.\n\n")
+		return
+	}
+	fmt.Fprintf(out, "   lower the tunnel's MTU, e.g. to 1280.\n\n")
+}
@@ -0,0 +1,161 @@
+package cli
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// OutputSink receives a copy of every valid frame passing through the
+// bridge. WriteFrame runs on the traffic path and must not block.
+type OutputSink interface {
+	WriteFrame(raw []byte, uplink bool)
+	Close() error
+}
+
+// OutputInfo describes a secondary output added while the bridge runs
+type OutputInfo struct {
+	ID      string    `json:"id"`
+	Kind    string    `json:"kind"`   // e.g. "udp" or "record"
+	Target  string    `json:"target"` // Address or file the output writes to
+	AddedAt time.Time `json:"added_at"`
+	Frames  uint64    `json:"frames"` // Frames copied to the output so far
+}
+
+// output is a registered sink and its counters
+type output struct {
+	info   OutputInfo
+	sink   OutputSink
+	frames atomic.Uint64
+}
+
+// outputRegistry holds the secondary outputs. The traffic path reads an
+// immutable snapshot, so adding or removing outputs never stalls it.
+type outputRegistry struct {
+	mu       sync.Mutex
+	next     int
+	snapshot atomic.Pointer[[]*output]
+}
+
+// list returns the current outputs
+func (r *outputRegistry) list() []*output {
+	if outputs := r.snapshot.Load(); outputs != nil {
+		return *outputs
+	}
+	return nil
+}
+
+// writeFrame copies a frame to every output
+func (r *outputRegistry) writeFrame(raw []byte, uplink bool) {
+	for _, o := range r.list() {
+		o.sink.WriteFrame(raw, uplink)
+		o.frames.Add(1)
+	}
+}
+
+// AddOutput starts copying traffic to sink until it is removed or the
+// bridge stops, and returns its description. The bridge closes the sink.
+func (b *Bridge) AddOutput(kind, target string, sink OutputSink) OutputInfo {
+	r := &b.outputs
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.next++
+	o := &output{
+		info: OutputInfo{
+			ID:      kind + "-" + strconv.Itoa(r.next),
+			Kind:    kind,
+			Target:  target,
+			AddedAt: time.Now(),
+		},
+		sink: sink,
+	}
+	outputs := append(append([]*output(nil), r.list()...), o)
+	r.snapshot.Store(&outputs)
+
+	b.logger.WithField("output", o.info.ID).WithField("target", target).Info("Output added")
+	return o.info
+}
+
+// RemoveOutput stops and closes an output added with AddOutput
+func (b *Bridge) RemoveOutput(id string) error {
+	r := &b.outputs
+	r.mu.Lock()
+	var removed *output
+	var outputs []*output
+	for _, o := range r.list() {
+		if o.info.ID == id {
+			removed = o
+			continue
+		}
+		outputs = append(outputs, o)
+	}
+	if removed != nil {
+		r.snapshot.Store(&outputs)
+	}
+	r.mu.Unlock()
+
+	if removed == nil {
+		return fmt.Errorf("no output %q", id)
+	}
+
+	b.logger.WithField("output", id).Info("Output removed")
+	return removed.sink.Close()
+}
+
+// Outputs returns the secondary outputs in the order they were added
+func (b *Bridge) Outputs() []OutputInfo {
+	outputs := b.outputs.list()
+	infos := make([]OutputInfo, 0, len(outputs))
+	for _, o := range outputs {
+		info := o.info
+		info.Frames = o.frames.Load()
+		infos = append(infos, info)
+	}
+	return infos
+}
+
+// closeOutputs removes and closes every output when the bridge stops
+func (b *Bridge) closeOutputs() {
+	for _, info := range b.Outputs() {
+		if err := b.RemoveOutput(info.ID); err != nil {
+			b.logger.WithError(err).WithField("output", info.ID).Warn("Failed to close output")
+		}
+	}
+}
+
+// UDPOutput sends a copy of the device's traffic to a UDP address, one frame
+// per datagram, for monitoring stations. Replies are ignored.
+type UDPOutput struct {
+	conn *net.UDPConn
+}
+
+// NewUDPOutput creates an output sending downlink frames to addr
+func NewUDPOutput(addr string) (*UDPOutput, error) {
+	raddr, err := net.ResolveUDPAddr("udp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid UDP output address %q: %w", addr, err)
+	}
+	conn, err := net.DialUDP("udp", nil, raddr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open UDP output to %s: %w", addr, err)
+	}
+	return &UDPOutput{conn: conn}, nil
+}
+
+// WriteFrame sends downlink frames; uplink frames are skipped
+func (u *UDPOutput) WriteFrame(raw []byte, uplink bool) {
+	if uplink {
+		return
+	}
+	// UDP is best effort; an unreachable station shouldn't affect the bridge
+	_, _ = u.conn.Write(raw)
+}
+
+// Close closes the output's socket
+func (u *UDPOutput) Close() error {
+	return u.conn.Close()
+}
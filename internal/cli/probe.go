@@ -0,0 +1,68 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/pavliha/aircast/aircast-cli/internal/mavlink"
+	"github.com/pavliha/aircast/aircast-cli/internal/network"
+)
+
+// autopilotCompID is MAV_COMP_ID_AUTOPILOT1; other components' heartbeats
+// (cameras, gimbals) would misreport the armed state
+const autopilotCompID = 1
+
+// ProbeTelemetry opens a short-lived, receive-only WebSocket connection to a
+// device and summarizes its telemetry. It returns whatever was gathered when
+// ctx expires, or an error if nothing arrived.
+func ProbeTelemetry(ctx context.Context, wsURL, token string) (*mavlink.Telemetry, error) {
+	header := http.Header{}
+	if token != "" {
+		header.Add("Authorization", "Bearer "+token)
+	}
+
+	dialer := websocket.Dialer{
+		HandshakeTimeout: 5 * time.Second,
+		Proxy:            http.ProxyFromEnvironment,
+		NetDialContext:   network.DialContext,
+	}
+
+	conn, resp, err := dialer.DialContext(ctx, wsURL, header)
+	if resp != nil && resp.Body != nil {
+		_ = resp.Body.Close()
+	}
+	if err != nil {
+		return nil, fmt.Errorf("probe connection failed: %w", err)
+	}
+	defer conn.Close()
+
+	// Unblock the read when the probe times out
+	stop := context.AfterFunc(ctx, func() { _ = conn.Close() })
+	defer stop()
+
+	parser := mavlink.NewParser()
+	telemetry := &mavlink.Telemetry{}
+	received := false
+
+	for !telemetry.Complete() {
+		_, data, err := conn.ReadMessage()
+		if err != nil {
+			break
+		}
+		for _, frame := range parser.Feed(data) {
+			if frame.CompID != autopilotCompID || frame.Validate() != nil {
+				continue
+			}
+			telemetry.Apply(frame)
+			received = true
+		}
+	}
+
+	if !received {
+		return nil, fmt.Errorf("no telemetry received")
+	}
+	return telemetry, nil
+}
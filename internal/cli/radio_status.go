@@ -0,0 +1,141 @@
+package cli
+
+import (
+	"encoding/binary"
+	"time"
+
+	"github.com/pavliha/aircast/aircast-cli/internal/mavlink"
+)
+
+// Mapping of cloud link health onto RADIO_STATUS signal levels (0-254)
+const (
+	radioLossFloor = 0.2                    // Downlink loss at which rssi reaches 0
+	radioRTTGood   = 100 * time.Millisecond // Round-trip time still reported as full remrssi
+	radioRTTBad    = time.Second            // Round-trip time at which remrssi reaches 0
+	radioLevelMax  = 254
+	radioUnknown   = 255 // RADIO_STATUS value for "not measured"
+)
+
+// radioCompID is MAV_COMP_ID_TELEMETRY_RADIO, which ground stations expect
+// RADIO_STATUS to come from
+const radioCompID = 68
+
+// radioStatusLen is the length of a RADIO_STATUS payload: rxerrors, fixed,
+// rssi, remrssi, txbuf, noise and remnoise
+const radioStatusLen = 9
+
+// lossLevel maps a loss fraction to a signal level
+func lossLevel(loss float64) uint8 {
+	if loss >= radioLossFloor {
+		return 0
+	}
+	return uint8(radioLevelMax * (1 - loss/radioLossFloor))
+}
+
+// rttLevel maps a round-trip time to a signal level
+func rttLevel(rtt time.Duration) uint8 {
+	switch {
+	case rtt <= 0:
+		return radioUnknown
+	case rtt <= radioRTTGood:
+		return radioLevelMax
+	case rtt >= radioRTTBad:
+		return 0
+	}
+	return uint8(radioLevelMax * float64(radioRTTBad-rtt) / float64(radioRTTBad-radioRTTGood))
+}
+
+// radioStatusPayload encodes a RADIO_STATUS message. txbuf reports a free
+// buffer so autopilots that read it never throttle on the bridge's account.
+func radioStatusPayload(rssi, remrssi uint8, rxErrors uint64) []byte {
+	payload := make([]byte, radioStatusLen)
+	binary.LittleEndian.PutUint16(payload[0:], uint16(rxErrors))
+	payload[4] = rssi
+	payload[5] = remrssi
+	payload[6] = 100 // txbuf, percent free
+	return payload
+}
+
+// sendRadioStatus reports the cloud link's health to ground stations as
+// RADIO_STATUS, so their link quality widgets show it: rssi follows the
+// downlink loss over the last interval and remrssi the WebSocket round-trip
+// time. Nothing is sent while the device's own radio reports RADIO_STATUS.
+func (b *Bridge) sendRadioStatus() {
+	interval := b.config.RadioStatusInterval
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	rssi := uint8(radioUnknown)
+	last := b.stats.Snapshot().Downlink
+	for {
+		select {
+		case <-b.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		down := b.stats.Snapshot().Downlink
+		if frames, lost := down.Frames-last.Frames, down.Lost-last.Lost; frames+lost > 0 {
+			rssi = lossLevel(float64(lost) / float64(frames+lost))
+		}
+		last = down
+
+		if at := b.radioStatusAt.Load(); at != 0 && time.Since(time.Unix(0, at)) < 3*interval {
+			continue
+		}
+		if b.trainingOutage() != TrainingOff {
+			continue // Ground stations should see the drill's outage as a lost link
+		}
+		sysID, ok := b.vehicleSysID()
+		if !ok {
+			continue
+		}
+
+		level, remote := rssi, rttLevel(b.LinkStats().RTT)
+		if b.linkDown.Load() {
+			level, remote = 0, 0
+		}
+		frame, err := mavlink.EncodeV2(uint8(b.txSeq.Add(1)), sysID, radioCompID, mavlink.MsgIDRadioStatus,
+			radioStatusPayload(level, remote, down.Corrupted))
+		if err != nil {
+			b.logger.WithError(err).Error("Failed to encode RADIO_STATUS")
+			continue
+		}
+		b.writeToClients(frame)
+	}
+}
+
+// vehicleSysID returns the system ID of the first autopilot seen on the downlink
+func (b *Bridge) vehicleSysID() (uint8, bool) {
+	for _, src := range b.stats.Snapshot().Sources {
+		if src.CompID == autopilotCompID {
+			return src.SysID, true
+		}
+	}
+	return 0, false
+}
+
+// writeToClients sends data the bridge originates to every TCP and UDP client
+func (b *Bridge) writeToClients(data []byte) {
+	b.tcpMutex.RLock()
+	for clientAddr, client := range b.tcpClients {
+		if _, err := client.Write(data); err != nil {
+			b.logger.WithError(err).WithField("client", clientAddr).Debug("Failed to write to TCP client")
+		}
+	}
+	b.tcpMutex.RUnlock()
+
+	if b.udpConn == nil {
+		return
+	}
+	b.udpMutex.RLock()
+	for clientAddr, addr := range b.udpClients {
+		n, err := b.udpConn.WriteToUDP(data, addr)
+		if err != nil {
+			b.logger.WithError(err).WithField("client", clientAddr).Debug("Failed to write to UDP client")
+			continue
+		}
+		b.stats.ClientWrite("udp", clientAddr, n)
+	}
+	b.udpMutex.RUnlock()
+}
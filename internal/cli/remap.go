@@ -0,0 +1,113 @@
+package cli
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/pavliha/aircast/aircast-cli/internal/mavlink"
+)
+
+// anyID matches any system or component ID in a remap rule, and keeps the
+// original ID as a replacement
+const anyID = -1
+
+// RemapRule rewrites the source system and component IDs of frames
+// travelling in one direction
+type RemapRule struct {
+	Dir      Direction // Uplink for ground station traffic, Downlink for device traffic
+	FromSys  int       // anyID matches every system
+	FromComp int       // anyID matches every component
+	ToSys    int       // anyID keeps the original system ID
+	ToComp   int       // anyID keeps the original component ID
+}
+
+// remapPattern matches rules such as "gcs:*/*=255/190" or "device:1/1=2/*"
+var remapPattern = regexp.MustCompile(`^\s*(gcs|device)\s*:\s*(\*|\d+)\s*/\s*(\*|\d+)\s*=\s*(\*|\d+)\s*/\s*(\*|\d+)\s*$`)
+
+// ParseRemapRule parses a rule of the form "gcs:sys/comp=sys/comp" or
+// "device:sys/comp=sys/comp", where * matches any ID on the left and keeps
+// the ID on the right
+func ParseRemapRule(s string) (RemapRule, error) {
+	m := remapPattern.FindStringSubmatch(strings.ToLower(s))
+	if m == nil {
+		return RemapRule{}, fmt.Errorf("invalid remap %q (expected e.g. gcs:*/*=255/190)", s)
+	}
+
+	rule := RemapRule{Dir: Uplink}
+	if m[1] == "device" {
+		rule.Dir = Downlink
+	}
+	for i, field := range []*int{&rule.FromSys, &rule.FromComp, &rule.ToSys, &rule.ToComp} {
+		id, err := parseRemapID(m[i+2])
+		if err != nil {
+			return RemapRule{}, fmt.Errorf("invalid remap %q: %w", s, err)
+		}
+		*field = id
+	}
+	return rule, nil
+}
+
+// parseRemapID parses one ID of a remap rule
+func parseRemapID(s string) (int, error) {
+	if s == "*" {
+		return anyID, nil
+	}
+	id, err := strconv.Atoi(s)
+	if err != nil || id < 0 || id > 255 {
+		return 0, fmt.Errorf("ID %s out of range 0-255", s)
+	}
+	return id, nil
+}
+
+// String returns the rule in the syntax ParseRemapRule accepts
+func (r RemapRule) String() string {
+	side := "gcs"
+	if r.Dir == Downlink {
+		side = "device"
+	}
+	id := func(v int) string {
+		if v == anyID {
+			return "*"
+		}
+		return strconv.Itoa(v)
+	}
+	return fmt.Sprintf("%s:%s/%s=%s/%s", side, id(r.FromSys), id(r.FromComp), id(r.ToSys), id(r.ToComp))
+}
+
+// matches reports whether the rule applies to a frame
+func (r RemapRule) matches(dir Direction, f *mavlink.Frame) bool {
+	return r.Dir == dir &&
+		(r.FromSys == anyID || r.FromSys == int(f.SysID)) &&
+		(r.FromComp == anyID || r.FromComp == int(f.CompID))
+}
+
+// remapSource returns the source IDs a frame should carry according to the
+// first matching rule; ok is false if the frame keeps its IDs
+func (b *Bridge) remapSource(dir Direction, f *mavlink.Frame) (sysID, compID uint8, ok bool) {
+	for _, rule := range b.config.Remap {
+		if !rule.matches(dir, f) {
+			continue
+		}
+		sysID, compID = f.SysID, f.CompID
+		if rule.ToSys != anyID {
+			sysID = uint8(rule.ToSys)
+		}
+		if rule.ToComp != anyID {
+			compID = uint8(rule.ToComp)
+		}
+		return sysID, compID, sysID != f.SysID || compID != f.CompID
+	}
+	return 0, 0, false
+}
+
+// remaps reports whether any rule rewrites traffic in a direction
+func (b *Bridge) remaps(dir Direction) bool {
+	for _, rule := range b.config.Remap {
+		if rule.Dir == dir {
+			return true
+		}
+	}
+	return false
+}
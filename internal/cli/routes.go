@@ -0,0 +1,296 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/url"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/pavliha/aircast/aircast-cli/internal/network"
+	"github.com/pavliha/aircast/aircast-cli/internal/term"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	routeProbeInterval = 30 * time.Second
+	routeProbeTimeout  = 5 * time.Second
+	routeSmoothing     = 0.3 // Weight of a new sample in a route's smoothed connect time
+
+	// A route takes over only when it is clearly faster, so two similar
+	// routes don't cause a reconnect every probe
+	routeSwitchRatio   = 0.7
+	routeSwitchMinGain = 20 * time.Millisecond
+)
+
+// RouteStats describes one of the paths the bridge chooses between: an
+// interface, an API endpoint or both
+type RouteStats struct {
+	Name      string        `json:"name"`                // e.g. "starlink0", "eu.aircast.one" or "starlink0 to eu.aircast.one"
+	Interface string        `json:"interface,omitempty"` // Empty for the default route
+	Endpoint  string        `json:"endpoint,omitempty"`  // API host, with Config.Endpoints
+	RTT       time.Duration `json:"rtt"`                 // Smoothed TCP connect time to the API, 0 until measured
+	Up        bool          `json:"up"`                  // Whether the last probe succeeded
+	Active    bool          `json:"active"`
+}
+
+// route is an interface and WebSocket URL the bridge can connect with
+type route struct {
+	name     string
+	iface    string
+	endpoint string // API host, when routes lead to several
+	url      string
+	addr     string // host:port of url
+	dial     network.DialFunc
+	rtt      time.Duration
+	up       bool
+}
+
+// stats describes the route; the caller holds the selector's lock
+func (r *route) stats(active bool) RouteStats {
+	return RouteStats{Name: r.name, Interface: r.iface, Endpoint: r.endpoint, RTT: r.rtt, Up: r.up, Active: active}
+}
+
+// routeSelector keeps the WebSocket on the best-performing of several
+// interfaces, e.g. Starlink and LTE, and API endpoints by probing each
+// periodically
+type routeSelector struct {
+	mu     sync.Mutex
+	routes []*route
+	active int
+}
+
+// newRouteSelector prepares a route for every pair of interface and
+// WebSocket URL. Without interfaces, the default route is used.
+func newRouteSelector(interfaces, urls []string) (*routeSelector, error) {
+	if len(interfaces) == 1 && len(urls) == 1 {
+		return nil, fmt.Errorf("route selection needs at least two interfaces or API endpoints")
+	}
+	if slices.Contains(urls, "") {
+		return nil, fmt.Errorf("empty API endpoint")
+	}
+	for i, name := range interfaces {
+		if slices.Contains(interfaces[:i], name) {
+			return nil, fmt.Errorf("interface %s is listed twice in routes", name)
+		}
+	}
+	addrs := make([]string, len(urls))
+	for i, raw := range urls {
+		addr, err := websocketAddr(raw)
+		if err != nil {
+			return nil, fmt.Errorf("invalid API endpoint %q: %w", raw, err)
+		}
+		if slices.Contains(urls[:i], raw) {
+			return nil, fmt.Errorf("API endpoint %s is listed twice", raw)
+		}
+		addrs[i] = addr
+	}
+
+	s := &routeSelector{}
+	if len(interfaces) == 0 {
+		interfaces = []string{""}
+	}
+	for _, iface := range interfaces {
+		dial := network.DialFunc(network.DialContext)
+		if iface != "" {
+			var err error
+			if dial, err = network.InterfaceDialer(iface); err != nil {
+				return nil, err
+			}
+		}
+		for i, u := range urls {
+			r := &route{name: iface, iface: iface, url: u, addr: addrs[i], dial: dial}
+			if len(urls) > 1 {
+				r.endpoint, _, _ = net.SplitHostPort(addrs[i])
+				r.name = r.endpoint
+				if iface != "" {
+					r.name = iface + " to " + r.endpoint
+				}
+			}
+			s.routes = append(s.routes, r)
+		}
+	}
+	return s, nil
+}
+
+// activeDial returns the dialer of the active route
+func (s *routeSelector) activeDial() network.DialFunc {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.routes[s.active].dial
+}
+
+// activeURL returns the WebSocket URL of the active route
+func (s *routeSelector) activeURL() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.routes[s.active].url
+}
+
+// hasEndpoints reports whether the routes lead to more than one API endpoint
+func (s *routeSelector) hasEndpoints() bool {
+	return slices.ContainsFunc(s.routes, func(r *route) bool { return r.url != s.routes[0].url })
+}
+
+// snapshot returns the state of every route
+func (s *routeSelector) snapshot() []RouteStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	stats := make([]RouteStats, len(s.routes))
+	for i, r := range s.routes {
+		stats[i] = r.stats(i == s.active)
+	}
+	return stats
+}
+
+// probe measures the TCP connect time over every route in parallel, to
+// addr or, when empty, to the route's own endpoint
+func (s *routeSelector) probe(ctx context.Context, addr string) {
+	var wg sync.WaitGroup
+	for _, r := range s.routes {
+		wg.Add(1)
+		go func(r *route) {
+			defer wg.Done()
+			probeCtx, cancel := context.WithTimeout(ctx, routeProbeTimeout)
+			defer cancel()
+
+			target := addr
+			if target == "" {
+				target = r.addr
+			}
+			start := time.Now()
+			conn, err := r.dial(probeCtx, "tcp", target)
+			elapsed := time.Since(start)
+			if err == nil {
+				_ = conn.Close()
+			}
+
+			s.mu.Lock()
+			defer s.mu.Unlock()
+			r.up = err == nil
+			switch {
+			case err != nil:
+			case r.rtt == 0:
+				r.rtt = elapsed
+			default:
+				r.rtt += time.Duration(routeSmoothing * float64(elapsed-r.rtt))
+			}
+		}(r)
+	}
+	wg.Wait()
+}
+
+// choose switches to a clearly better route and reports whether it did,
+// with the state of the routes switched from and to, copied under the lock
+func (s *routeSelector) choose() (from, to RouteStats, switched bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	best := -1
+	for i, r := range s.routes {
+		if r.up && (best < 0 || r.rtt < s.routes[best].rtt) {
+			best = i
+		}
+	}
+	current := s.routes[s.active]
+	if best < 0 || best == s.active {
+		return RouteStats{}, RouteStats{}, false
+	}
+
+	candidate := s.routes[best]
+	if current.up {
+		gain := current.rtt - candidate.rtt
+		if float64(candidate.rtt) > routeSwitchRatio*float64(current.rtt) || gain < routeSwitchMinGain {
+			return RouteStats{}, RouteStats{}, false
+		}
+	}
+	s.active = best
+	return current.stats(false), candidate.stats(true), true
+}
+
+// Routes returns the paths the bridge chooses between, nil without
+// Config.Routes or Config.Endpoints
+func (b *Bridge) Routes() []RouteStats {
+	if b.routes == nil {
+		return nil
+	}
+	return b.routes.snapshot()
+}
+
+// websocketAddr returns the host:port a WebSocket URL connects to
+func websocketAddr(raw string) (string, error) {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return "", err
+	}
+	if u.Hostname() == "" {
+		return "", fmt.Errorf("no host")
+	}
+	port := u.Port()
+	if port == "" {
+		port = "80"
+		if u.Scheme == "wss" || u.Scheme == "https" {
+			port = "443"
+		}
+	}
+	return net.JoinHostPort(u.Hostname(), port), nil
+}
+
+// selectRoute probes every route and moves the WebSocket to a clearly better
+// one. It reports whether the route changed.
+func (b *Bridge) selectRoute() bool {
+	// Each route is probed against its own endpoint, unless the server
+	// moved the device: then only the interface is chosen, for the new URL
+	addr := ""
+	if b.movedURL.Load() != nil || !b.routes.hasEndpoints() {
+		var err error
+		if addr, err = websocketAddr(b.currentURL()); err != nil {
+			return false
+		}
+	}
+	b.routes.probe(b.ctx, addr)
+
+	from, to, switched := b.routes.choose()
+	if !switched {
+		return false
+	}
+
+	fields := log.Fields{"from": from.Name, "to": to.Name, "rtt": to.RTT.Round(time.Millisecond)}
+	detail := fmt.Sprintf("%s unreachable", from.Name)
+	if from.Up {
+		fields["from_rtt"] = from.RTT.Round(time.Millisecond)
+		detail = fmt.Sprintf("%v vs %v on %s", to.RTT.Round(time.Millisecond), from.RTT.Round(time.Millisecond), from.Name)
+	}
+	b.logger.WithFields(fields).Info("Switching route")
+	fmt.Fprintf(b.config.Output, "%sSwitching to %s (%s)\n", term.Symbol("🧭 ", ""), to.Name, detail)
+	return true
+}
+
+// watchRoutes re-evaluates the routes every routeProbeInterval and
+// reconnects the WebSocket over a better one
+func (b *Bridge) watchRoutes() {
+
+	ticker := time.NewTicker(routeProbeInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-b.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		if !b.selectRoute() {
+			continue
+		}
+
+		// Closing the connection makes the reader redial over the new route
+		b.wsMutex.Lock()
+		if b.wsConn != nil {
+			b.switching.Store(true)
+			_ = b.wsConn.Close()
+		}
+		b.wsMutex.Unlock()
+	}
+}
@@ -0,0 +1,75 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// DefaultShutdownTimeout is how long to give Shutdown, and how long Start
+// waits for what it opened to close when it fails
+const DefaultShutdownTimeout = 5 * time.Second
+
+// taskGroup runs the bridge's goroutines and remembers which are still
+// running, so a shutdown that times out can say what is stuck
+type taskGroup struct {
+	wg      sync.WaitGroup
+	mu      sync.Mutex
+	running map[string]int // Goroutines by component name
+}
+
+// Go runs fn in a goroutine under a component name
+func (g *taskGroup) Go(name string, fn func()) {
+	g.mu.Lock()
+	if g.running == nil {
+		g.running = make(map[string]int)
+	}
+	g.running[name]++
+	g.mu.Unlock()
+
+	g.wg.Add(1)
+	go func() {
+		defer g.wg.Done()
+		defer g.finished(name)
+		fn()
+	}()
+}
+
+// finished records that one of a component's goroutines returned
+func (g *taskGroup) finished(name string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.running[name]--; g.running[name] <= 0 {
+		delete(g.running, name)
+	}
+}
+
+// Wait waits until ctx is done for every goroutine to return. It returns the
+// components still running when it gave up, e.g. "tcp-client (2)".
+func (g *taskGroup) Wait(ctx context.Context) []string {
+	done := make(chan struct{})
+	go func() {
+		g.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	stuck := make([]string, 0, len(g.running))
+	for name, n := range g.running {
+		if n > 1 {
+			name = fmt.Sprintf("%s (%d)", name, n)
+		}
+		stuck = append(stuck, name)
+	}
+	sort.Strings(stuck)
+	return stuck
+}
@@ -0,0 +1,228 @@
+package cli
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/pavliha/aircast/aircast-cli/internal/mavlink"
+	log "github.com/sirupsen/logrus"
+)
+
+// Source rate negotiation tuning
+const (
+	sourceRateWindow   = 5 * time.Second // How long the autopilot's streams are measured
+	sourceRateSlack    = 1.5             // Streams within this multiple of the target are left alone
+	sourceRateDebounce = 2 * time.Second // Settle time after a ground station changes rates
+)
+
+// Interval values of MAV_CMD_SET_MESSAGE_INTERVAL, in microseconds
+const (
+	intervalDefault  = 0  // The autopilot's own rate
+	intervalDisabled = -1 // Stop sending the message
+)
+
+// sourceRates asks the autopilot to send the profile's streams at the
+// profile's rates, so telemetry that would be dropped at the bridge never
+// crosses the link in the first place
+type sourceRates struct {
+	filter    *rateFilter
+	measuring atomic.Bool
+
+	mu        sync.Mutex
+	counts    map[filterKey]int   // Frames seen in the current window
+	intervals map[filterKey]int32 // Intervals requested, undone on stop
+
+	reapply chan struct{}
+}
+
+// newSourceRates prepares negotiation for a compiled profile
+func newSourceRates(filter *rateFilter) *sourceRates {
+	return &sourceRates{
+		filter:    filter,
+		counts:    make(map[filterKey]int),
+		intervals: make(map[filterKey]int32),
+		reapply:   make(chan struct{}, 1),
+	}
+}
+
+// observe counts an autopilot frame while streams are being measured
+func (s *sourceRates) observe(frame *mavlink.Frame) {
+	if !s.measuring.Load() {
+		return
+	}
+	s.mu.Lock()
+	s.counts[filterKey{sysID: frame.SysID, compID: frame.CompID, msgID: frame.MsgID}]++
+	s.mu.Unlock()
+}
+
+// uplinkFrame watches ground station traffic for rate requests of their
+// own, which override ours on the autopilot
+func (s *sourceRates) uplinkFrame(frame *mavlink.Frame) {
+	if frame.MsgID == mavlink.MsgIDRequestDataStream {
+		s.requestReapply()
+		return
+	}
+	if cmd, ok := frame.Command(); ok && cmd.ID == mavlink.CmdSetMessageInterval {
+		s.requestReapply()
+	}
+}
+
+// requestReapply schedules the streams to be measured and requested again.
+// It is safe to call on a nil receiver.
+func (s *sourceRates) requestReapply() {
+	if s == nil {
+		return
+	}
+	select {
+	case s.reapply <- struct{}{}:
+	default:
+	}
+}
+
+// plan returns the intervals to request for the streams measured over window
+func (s *sourceRates) plan(counts map[filterKey]int, window time.Duration) map[filterKey]int32 {
+	planned := make(map[filterKey]int32)
+	for key, n := range counts {
+		if essentialMessages[key.msgID] {
+			continue
+		}
+		if s.filter.blocked[key.msgID] {
+			planned[key] = intervalDisabled
+			continue
+		}
+
+		interval, ok := s.filter.intervals[key.msgID]
+		if !ok {
+			interval = s.filter.interval
+		}
+		if interval <= 0 {
+			continue
+		}
+		observed := float64(n) / window.Seconds()
+		target := float64(time.Second) / float64(interval)
+		if observed > target*sourceRateSlack {
+			planned[key] = int32(interval.Microseconds())
+		}
+	}
+	return planned
+}
+
+// negotiateSourceRates measures the autopilot's streams and requests the
+// profile's rates for those that are too fast, again whenever a ground
+// station changes the rates or the connection is re-established
+func (b *Bridge) negotiateSourceRates() {
+	s := b.sourceRates
+
+	for {
+		s.mu.Lock()
+		clear(s.counts)
+		s.mu.Unlock()
+		s.measuring.Store(true)
+
+		select {
+		case <-b.ctx.Done():
+			return
+		case <-time.After(sourceRateWindow):
+		}
+
+		s.measuring.Store(false)
+		s.mu.Lock()
+		counts := make(map[filterKey]int, len(s.counts))
+		for key, n := range s.counts {
+			counts[key] = n
+		}
+		s.mu.Unlock()
+
+		// Nothing from the autopilot yet; keep measuring
+		if len(counts) == 0 {
+			continue
+		}
+		b.requestIntervals(s.plan(counts, sourceRateWindow))
+
+		select {
+		case <-b.ctx.Done():
+			return
+		case <-s.reapply:
+		}
+
+		// Let a burst of ground station requests finish first
+		select {
+		case <-b.ctx.Done():
+			return
+		case <-time.After(sourceRateDebounce):
+		}
+		select {
+		case <-s.reapply:
+		default:
+		}
+	}
+}
+
+// requestIntervals sends SET_MESSAGE_INTERVAL for each planned stream
+func (b *Bridge) requestIntervals(planned map[filterKey]int32) {
+	if len(planned) == 0 {
+		return
+	}
+
+	s := b.sourceRates
+	requested := 0
+	for key, interval := range planned {
+		if err := b.sendMessageInterval(key, interval); err != nil {
+			b.logger.WithError(err).WithField("msg_id", key.msgID).Warn("Failed to request message interval")
+			continue
+		}
+		s.mu.Lock()
+		s.intervals[key] = interval
+		s.mu.Unlock()
+		requested++
+	}
+
+	if requested > 0 {
+		b.logger.WithFields(log.Fields{
+			"profile":  s.filter.name,
+			"messages": requested,
+		}).Info("Requested lower telemetry rates from the autopilot")
+	}
+}
+
+// restoreSourceRates gives every stream the bridge changed back the
+// interval a ground station set for it, or the autopilot's default rate
+func (b *Bridge) restoreSourceRates() {
+	s := b.sourceRates
+	if s == nil {
+		return
+	}
+
+	// Don't hold the lock across WebSocket writes
+	s.mu.Lock()
+	changed := make([]filterKey, 0, len(s.intervals))
+	for key := range s.intervals {
+		changed = append(changed, key)
+	}
+	clear(s.intervals)
+	s.mu.Unlock()
+
+	for _, key := range changed {
+		interval, ok := b.gcsRates.interval(key)
+		if !ok {
+			interval = intervalDefault
+		}
+		if err := b.sendMessageInterval(key, interval); err != nil {
+			b.logger.WithError(err).WithField("msg_id", key.msgID).Debug("Failed to restore message interval")
+		}
+	}
+	if len(changed) > 0 {
+		b.logger.WithField("messages", len(changed)).Info("Restored the autopilot's telemetry rates")
+	}
+}
+
+// sendMessageInterval sends MAV_CMD_SET_MESSAGE_INTERVAL for one stream
+func (b *Bridge) sendMessageInterval(key filterKey, interval int32) error {
+	frame, err := mavlink.EncodeCommandLong(uint8(b.txSeq.Add(1)), bridgeSysID, bridgeCompID,
+		key.sysID, key.compID, mavlink.CmdSetMessageInterval, float32(key.msgID), float32(interval))
+	if err != nil {
+		return err
+	}
+	return b.writeToWebSocket(frame)
+}
@@ -0,0 +1,297 @@
+package cli
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/pavliha/aircast/aircast-cli/internal/network"
+)
+
+// ErrEchoUnsupported is returned when the device's agent has no echo endpoint
+var ErrEchoUnsupported = errors.New("device agent does not support speed tests")
+
+const (
+	speedPingSize    = 64              // Bytes per latency probe, about a MAVLink command
+	speedPingTimeout = 2 * time.Second // Wait before a latency probe counts as lost
+	speedChunkSize   = 1024            // Bytes per message in the throughput phase
+	speedWindow      = 64 * 1024       // Bytes in flight in the throughput phase
+	speedHeaderSize  = 16              // Sequence number and send time in every probe
+
+	// throughputSeq numbers the first throughput probe, apart from latency probes
+	throughputSeq uint64 = 1 << 32
+)
+
+// SpeedTestOptions configures a speed test
+type SpeedTestOptions struct {
+	Pings    int           // Latency probes, sent one at a time
+	Interval time.Duration // Pause between latency probes
+	Duration time.Duration // Length of the throughput phase
+}
+
+// SpeedTestResult is what a speed test measured over the device's path
+type SpeedTestResult struct {
+	Pings      int           `json:"pings"`
+	Lost       int           `json:"lost"`
+	MinRTT     time.Duration `json:"min_rtt"`
+	MedianRTT  time.Duration `json:"median_rtt"`
+	P95RTT     time.Duration `json:"p95_rtt"`
+	Jitter     time.Duration `json:"jitter"`     // Mean difference between consecutive round trips
+	Throughput float64       `json:"throughput"` // Bytes per second echoed back, limited by the slower direction
+	LoadedRTT  time.Duration `json:"loaded_rtt"` // Median round trip while the link was saturated
+}
+
+// LossPercent returns the share of latency probes that weren't echoed
+func (r SpeedTestResult) LossPercent() float64 {
+	if r.Pings == 0 {
+		return 0
+	}
+	return float64(r.Lost) / float64(r.Pings) * 100
+}
+
+// SpeedTest measures latency and throughput over the WebSocket path to a
+// device, through the relay, using the agent's echo endpoint. Unlike a
+// generic internet speed test, it includes the relay and the device's uplink.
+func SpeedTest(ctx context.Context, echoURL, token string, opts SpeedTestOptions, progress func(phase string)) (*SpeedTestResult, error) {
+	header := http.Header{}
+	if token != "" {
+		header.Add("Authorization", "Bearer "+token)
+	}
+	dialer := websocket.Dialer{
+		HandshakeTimeout: 10 * time.Second,
+		Proxy:            http.ProxyFromEnvironment,
+		NetDialContext:   network.DialContext,
+	}
+
+	conn, resp, err := dialer.DialContext(ctx, echoURL, header)
+	if resp != nil && resp.Body != nil {
+		_ = resp.Body.Close()
+	}
+	if err != nil {
+		if resp != nil && resp.StatusCode == http.StatusNotFound {
+			return nil, ErrEchoUnsupported
+		}
+		return nil, fmt.Errorf("speed test connection failed: %w", err)
+	}
+	defer conn.Close()
+
+	// Unblock reads when the test is cancelled
+	stop := context.AfterFunc(ctx, func() { _ = conn.Close() })
+	defer stop()
+
+	// One reader hands echoes to whichever phase is running
+	echoes := make(chan echo, 256)
+	readErr := make(chan error, 1)
+	quit := make(chan struct{})
+	defer close(quit)
+	go func() {
+		for {
+			_, data, err := conn.ReadMessage()
+			if err != nil {
+				readErr <- err
+				close(echoes)
+				return
+			}
+			if len(data) < speedHeaderSize {
+				continue
+			}
+			sent := int64(binary.BigEndian.Uint64(data[8:16]))
+			e := echo{
+				seq:  binary.BigEndian.Uint64(data[:8]),
+				size: len(data),
+				rtt:  time.Since(time.Unix(0, sent)),
+			}
+			select {
+			case echoes <- e:
+			case <-quit:
+				return
+			}
+		}
+	}()
+
+	result := &SpeedTestResult{}
+	if progress != nil {
+		progress("latency")
+	}
+	if err := measureLatency(ctx, conn, echoes, opts, result); err != nil {
+		return nil, err
+	}
+	if progress != nil {
+		progress("throughput")
+	}
+	if err := measureThroughput(ctx, conn, echoes, opts.Duration, result); err != nil {
+		return nil, err
+	}
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+
+	select {
+	case err := <-readErr:
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		return nil, fmt.Errorf("speed test connection lost: %w", err)
+	default:
+	}
+	return result, nil
+}
+
+// echo is a probe that came back
+type echo struct {
+	seq  uint64
+	size int
+	rtt  time.Duration
+}
+
+// probe builds a probe message carrying a sequence number and the send time
+func probe(seq uint64, size int) []byte {
+	msg := make([]byte, size)
+	binary.BigEndian.PutUint64(msg[:8], seq)
+	binary.BigEndian.PutUint64(msg[8:16], uint64(time.Now().UnixNano()))
+	return msg
+}
+
+// measureLatency sends probes one at a time and records their round trips
+func measureLatency(ctx context.Context, conn *websocket.Conn, echoes <-chan echo, opts SpeedTestOptions, result *SpeedTestResult) error {
+	var rtts []time.Duration
+	for seq := uint64(0); seq < uint64(opts.Pings); seq++ {
+		if err := conn.WriteMessage(websocket.BinaryMessage, probe(seq, speedPingSize)); err != nil {
+			return fmt.Errorf("speed test connection lost: %w", err)
+		}
+		result.Pings++
+
+		timeout := time.NewTimer(speedPingTimeout)
+	wait:
+		for {
+			select {
+			case <-ctx.Done():
+				timeout.Stop()
+				return ctx.Err()
+			case e, ok := <-echoes:
+				if !ok {
+					timeout.Stop()
+					return fmt.Errorf("speed test connection lost")
+				}
+				if e.seq == seq {
+					rtts = append(rtts, e.rtt)
+					break wait
+				}
+				// A late echo of an earlier probe that was counted as lost
+			case <-timeout.C:
+				result.Lost++
+				break wait
+			}
+		}
+		timeout.Stop()
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(opts.Interval):
+		}
+	}
+
+	if len(rtts) == 0 {
+		return fmt.Errorf("no probes were echoed; the device may be offline")
+	}
+	var jitter time.Duration
+	for i := 1; i < len(rtts); i++ {
+		d := rtts[i] - rtts[i-1]
+		if d < 0 {
+			d = -d
+		}
+		jitter += d
+	}
+	if len(rtts) > 1 {
+		result.Jitter = jitter / time.Duration(len(rtts)-1)
+	}
+
+	sort.Slice(rtts, func(i, j int) bool { return rtts[i] < rtts[j] })
+	result.MinRTT = rtts[0]
+	result.MedianRTT = rtts[len(rtts)/2]
+	result.P95RTT = rtts[int(float64(len(rtts)-1)*0.95)]
+	return nil
+}
+
+// measureThroughput keeps the link saturated with a window of probes for
+// duration and counts the bytes echoed back
+func measureThroughput(ctx context.Context, conn *websocket.Conn, echoes <-chan echo, duration time.Duration, result *SpeedTestResult) error {
+	ctx, cancel := context.WithTimeout(ctx, duration)
+	defer cancel()
+
+	var mu sync.Mutex
+	inFlight := 0
+	space := sync.NewCond(&mu)
+
+	// Wake the sender when the phase ends, so it doesn't wait for echoes
+	stop := context.AfterFunc(ctx, func() {
+		mu.Lock()
+		space.Broadcast()
+		mu.Unlock()
+	})
+	defer stop()
+
+	var echoed int
+	var rtts []time.Duration
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case e, ok := <-echoes:
+				if !ok {
+					return
+				}
+				if e.seq < throughputSeq {
+					continue // A late latency probe
+				}
+				mu.Lock()
+				inFlight -= e.size
+				space.Signal()
+				mu.Unlock()
+				echoed += e.size
+				rtts = append(rtts, e.rtt)
+			}
+		}
+	}()
+
+	began := time.Now()
+	var sendErr error
+	for seq := throughputSeq; ctx.Err() == nil; seq++ {
+		mu.Lock()
+		for inFlight+speedChunkSize > speedWindow && ctx.Err() == nil {
+			space.Wait()
+		}
+		inFlight += speedChunkSize
+		mu.Unlock()
+		if ctx.Err() != nil {
+			break
+		}
+		if err := conn.WriteMessage(websocket.BinaryMessage, probe(seq, speedChunkSize)); err != nil {
+			sendErr = err
+			cancel()
+			break
+		}
+	}
+	<-done
+	elapsed := time.Since(began)
+
+	if sendErr != nil {
+		return fmt.Errorf("speed test connection lost: %w", sendErr)
+	}
+	result.Throughput = float64(echoed) / elapsed.Seconds()
+	if len(rtts) > 0 {
+		sort.Slice(rtts, func(i, j int) bool { return rtts[i] < rtts[j] })
+		result.LoadedRTT = rtts[len(rtts)/2]
+	}
+	return nil
+}
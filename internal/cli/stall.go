@@ -0,0 +1,101 @@
+package cli
+
+import (
+	"errors"
+	"time"
+
+	"github.com/gorilla/websocket"
+	log "github.com/sirupsen/logrus"
+)
+
+// DefaultStallTimeout is how long the WebSocket may go without data while
+// ground stations are sending requests before the connection is cycled
+const DefaultStallTimeout = 30 * time.Second
+
+// minStallCheck is the shortest interval between stall checks
+const minStallCheck = time.Second
+
+// ErrStalled is the error a connection cycled by the stall watchdog is
+// reported with, e.g. to Config.OnLink
+var ErrStalled = errors.New("stall detected: no data from the device")
+
+// stallRequests are the ground station messages that ask the device for an
+// answer or a stream. Their going unanswered is what a stall looks like;
+// heartbeats and other one-way traffic don't count.
+var stallRequests = messageSet(
+	"COMMAND_LONG",
+	"COMMAND_INT",
+	"REQUEST_DATA_STREAM",
+	"PARAM_REQUEST_READ",
+	"PARAM_REQUEST_LIST",
+	"MISSION_REQUEST_LIST",
+	"MISSION_REQUEST_INT",
+	"LOG_REQUEST_LIST",
+	"FILE_TRANSFER_PROTOCOL",
+	"TIMESYNC",
+)
+
+// watchStalls cycles a connection that delivered data but has stopped
+// while ground stations keep sending requests, as a half-open path through
+// a NAT or proxy does: the socket stays up, so without this nothing would
+// notice until someone looked at the ground station. A connection that
+// hasn't delivered anything yet, e.g. while the device's MAVLink proxy is
+// starting, is left alone, as is silence without requests.
+func (b *Bridge) watchStalls() {
+	timeout := b.config.StallTimeout
+	ticker := time.NewTicker(max(timeout/4, minStallCheck))
+	defer ticker.Stop()
+
+	var watched *websocket.Conn // The connection being timed
+	var since time.Time
+	for {
+		select {
+		case <-b.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		b.wsMutex.Lock()
+		conn := b.wsConn
+		b.wsMutex.Unlock()
+		if conn == nil || b.linkDown.Load() {
+			watched = nil
+			continue
+		}
+
+		now := time.Now()
+		if conn != watched {
+			watched, since = conn, now
+			continue
+		}
+
+		// Armed once this connection has delivered data
+		last := time.Unix(0, b.wsDataAt.Load())
+		if !last.After(since) {
+			continue
+		}
+		silence := now.Sub(last)
+		requestAt := time.Unix(0, b.requestAt.Load())
+		if silence < timeout || !requestAt.After(last) || now.Sub(requestAt) >= timeout {
+			continue
+		}
+
+		b.logger.WithFields(log.Fields{
+			"silence":    silence.Round(time.Second).String(),
+			"request_at": requestAt.Format(time.RFC3339),
+		}).Warn("Stall detected: no data from the device while ground stations are sending requests, cycling the connection")
+		b.link.Stalled()
+		if b.config.OnStall != nil {
+			b.config.OnStall(silence)
+		}
+
+		// Closing the connection makes the reader redial
+		b.wsMutex.Lock()
+		if b.wsConn == conn {
+			b.stalled.Store(true)
+			_ = conn.Close()
+		}
+		b.wsMutex.Unlock()
+		watched = nil
+	}
+}
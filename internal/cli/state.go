@@ -0,0 +1,65 @@
+package cli
+
+import "errors"
+
+// State is where a bridge is in its lifecycle. Status reports the finer
+// ConnState of a running bridge.
+type State int32
+
+// Bridge lifecycle states
+const (
+	StateIdle       State = iota // Created, not started yet
+	StateConnecting              // Start is making the first connection
+	StateRunning                 // Connected to the device
+	StateDegraded                // Started, but the primary link is down and being re-established
+	StateStopped                 // Shut down, or Start failed; a bridge can't be restarted
+)
+
+// ErrStopped is returned by Start on a bridge that was already started or
+// shut down
+var ErrStopped = errors.New("bridge already started or stopped")
+
+// ErrNotConnected is returned for uplink writes while the WebSocket is
+// between connections
+var ErrNotConnected = errors.New("WebSocket not connected")
+
+func (s State) String() string {
+	switch s {
+	case StateIdle:
+		return "idle"
+	case StateConnecting:
+		return "connecting"
+	case StateRunning:
+		return "running"
+	case StateDegraded:
+		return "degraded"
+	case StateStopped:
+		return "stopped"
+	}
+	return "unknown"
+}
+
+// State returns where the bridge is in its lifecycle
+func (b *Bridge) State() State {
+	return State(b.state.Load())
+}
+
+// setState moves the bridge to state s unless it is stopped, which is final
+func (b *Bridge) setState(s State) {
+	for {
+		cur := b.state.Load()
+		if State(cur) == StateStopped || b.state.CompareAndSwap(cur, int32(s)) {
+			return
+		}
+	}
+}
+
+// linkState moves a started bridge between running and degraded as the
+// primary link comes and goes
+func (b *Bridge) linkState(up bool) {
+	if up {
+		b.state.CompareAndSwap(int32(StateDegraded), int32(StateRunning))
+	} else {
+		b.state.CompareAndSwap(int32(StateRunning), int32(StateDegraded))
+	}
+}
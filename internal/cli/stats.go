@@ -0,0 +1,251 @@
+package cli
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Direction identifies which way traffic flows through the bridge
+type Direction int
+
+const (
+	// Uplink is traffic from ground control stations to the device
+	Uplink Direction = iota
+	// Downlink is traffic from the device to ground control stations
+	Downlink
+)
+
+// String returns the direction name
+func (d Direction) String() string {
+	if d == Uplink {
+		return "uplink"
+	}
+	return "downlink"
+}
+
+// DirectionStats holds MAVLink frame counters for one direction
+type DirectionStats struct {
+	Bytes     uint64 `json:"bytes"`
+	Frames    uint64 `json:"frames"`
+	Corrupted uint64 `json:"corrupted"`
+	Unknown   uint64 `json:"unknown"` // Frames whose CRC can't be verified (message not in dialect)
+	Dropped   uint64 `json:"dropped"`
+	Filtered  uint64 `json:"filtered"` // Frames withheld by the bandwidth profile
+	Lost      uint64 `json:"lost"`     // Frames estimated lost from sequence gaps
+
+	Duplicates uint64 `json:"duplicates"` // Uplink frames suppressed as repeats of another client's
+}
+
+// CorruptionRate returns the fraction of frames that failed CRC validation
+func (d DirectionStats) CorruptionRate() float64 {
+	if d.Frames == 0 {
+		return 0
+	}
+	return float64(d.Corrupted) / float64(d.Frames)
+}
+
+// LossRate returns the estimated fraction of frames lost in transit
+func (d DirectionStats) LossRate() float64 {
+	if d.Frames+d.Lost == 0 {
+		return 0
+	}
+	return float64(d.Lost) / float64(d.Frames+d.Lost)
+}
+
+// SourceStats holds sequence tracking results for one MAVLink system/component
+type SourceStats struct {
+	SysID    uint8  `json:"sys_id"`
+	CompID   uint8  `json:"comp_id"`
+	Received uint64 `json:"received"`
+	Lost     uint64 `json:"lost"`
+}
+
+// LossRate returns the estimated fraction of frames lost from this source
+func (s SourceStats) LossRate() float64 {
+	if s.Received+s.Lost == 0 {
+		return 0
+	}
+	return float64(s.Lost) / float64(s.Received+s.Lost)
+}
+
+// sourceKey identifies a MAVLink system/component pair
+type sourceKey struct {
+	sysID  uint8
+	compID uint8
+}
+
+// sequenceTracker follows the sequence numbers of a single source
+type sequenceTracker struct {
+	lastSeq  uint8
+	received uint64
+	lost     uint64
+}
+
+// StatsSnapshot is a point-in-time copy of the bridge statistics
+type StatsSnapshot struct {
+	Since    time.Time      `json:"since"`
+	Uplink   DirectionStats `json:"uplink"`
+	Downlink DirectionStats `json:"downlink"`
+	Sources  []SourceStats  `json:"sources"` // Downlink sources, ordered by system/component ID
+}
+
+// Stats collects bridge traffic statistics
+type Stats struct {
+	mu       sync.Mutex
+	since    time.Time
+	uplink   DirectionStats
+	downlink DirectionStats
+	sources  map[sourceKey]*sequenceTracker
+	clients  map[string]*ClientStats
+}
+
+// NewStats creates a new statistics collector
+func NewStats() *Stats {
+	return &Stats{
+		since:   time.Now(),
+		sources: make(map[sourceKey]*sequenceTracker),
+		clients: make(map[string]*ClientStats),
+	}
+}
+
+// direction returns the counters for a direction; caller must hold mu
+func (s *Stats) direction(dir Direction) *DirectionStats {
+	if dir == Uplink {
+		return &s.uplink
+	}
+	return &s.downlink
+}
+
+// AddBytes records raw bytes received in a direction
+func (s *Stats) AddBytes(dir Direction, n int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.direction(dir).Bytes += uint64(n)
+}
+
+// AddFrame records a parsed frame and the outcome of its CRC validation
+func (s *Stats) AddFrame(dir Direction, corrupted, unknown, dropped bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	d := s.direction(dir)
+	d.Frames++
+	if corrupted {
+		d.Corrupted++
+	}
+	if unknown {
+		d.Unknown++
+	}
+	if dropped {
+		d.Dropped++
+	}
+}
+
+// AddFiltered records a frame withheld by the bandwidth profile
+func (s *Stats) AddFiltered(dir Direction) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.direction(dir).Filtered++
+}
+
+// AddDuplicate records a frame suppressed as a duplicate of another
+// client's, counted for the client that sent it
+func (s *Stats) AddDuplicate(dir Direction, client string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.direction(dir).Duplicates++
+	if c, ok := s.clients[client]; ok {
+		c.Duplicates++
+	}
+}
+
+// AddSequence records a downlink frame's sequence number and estimates loss
+// from gaps in each source's sequence
+func (s *Stats) AddSequence(sysID, compID, seq uint8) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := sourceKey{sysID: sysID, compID: compID}
+	tracker, ok := s.sources[key]
+	if !ok {
+		s.sources[key] = &sequenceTracker{lastSeq: seq, received: 1}
+		return
+	}
+
+	tracker.received++
+	if seq == tracker.lastSeq {
+		return // Duplicate, e.g. from a second radio
+	}
+
+	// Sequence numbers wrap at 256, so uint8 arithmetic gives the gap directly
+	gap := seq - tracker.lastSeq - 1
+	tracker.lastSeq = seq
+	if gap >= 128 {
+		// More likely a late frame or a restarted sender than 128+ lost
+		// frames; count nothing and follow the new sequence
+		return
+	}
+	tracker.lost += uint64(gap)
+	s.downlink.Lost += uint64(gap)
+}
+
+// Snapshot returns a copy of the current statistics
+func (s *Stats) Snapshot() StatsSnapshot {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sources := make([]SourceStats, 0, len(s.sources))
+	for key, tracker := range s.sources {
+		sources = append(sources, SourceStats{
+			SysID:    key.sysID,
+			CompID:   key.compID,
+			Received: tracker.received,
+			Lost:     tracker.lost,
+		})
+	}
+	sort.Slice(sources, func(i, j int) bool {
+		if sources[i].SysID != sources[j].SysID {
+			return sources[i].SysID < sources[j].SysID
+		}
+		return sources[i].CompID < sources[j].CompID
+	})
+
+	return StatsSnapshot{
+		Since:    s.since,
+		Uplink:   s.uplink,
+		Downlink: s.downlink,
+		Sources:  sources,
+	}
+}
+
+// logStats periodically logs traffic statistics
+func (b *Bridge) logStats() {
+
+	ticker := time.NewTicker(b.config.StatsInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-b.ctx.Done():
+			return
+		case <-ticker.C:
+			s := b.stats.Snapshot()
+			b.logger.WithFields(log.Fields{
+				"up_frames":        s.Uplink.Frames,
+				"up_corrupted":     s.Uplink.Corrupted,
+				"up_corrupt_pct":   fmt.Sprintf("%.2f", s.Uplink.CorruptionRate()*100),
+				"down_frames":      s.Downlink.Frames,
+				"down_corrupted":   s.Downlink.Corrupted,
+				"down_corrupt_pct": fmt.Sprintf("%.2f", s.Downlink.CorruptionRate()*100),
+				"down_lost":        s.Downlink.Lost,
+				"down_loss_pct":    fmt.Sprintf("%.2f", s.Downlink.LossRate()*100),
+				"up_duplicates":    s.Uplink.Duplicates,
+			}).Info("Bridge statistics")
+		}
+	}
+}
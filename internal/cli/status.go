@@ -0,0 +1,88 @@
+package cli
+
+import "time"
+
+// ConnState is the connection state of a started bridge, as reported by
+// Status. State is its lifecycle.
+type ConnState string
+
+// Connection states reported by Status
+const (
+	ConnStateConnected    ConnState = "connected"    // Data is flowing from the device
+	ConnStateNoData       ConnState = "no-data"      // Connected, but the device hasn't sent anything yet
+	ConnStateReconnecting ConnState = "reconnecting" // The connection dropped and is being re-established
+	ConnStateCircuitOpen  ConnState = "circuit-open" // Waiting out repeated failures before trying again
+)
+
+// BridgeStatus is a snapshot of a running bridge's health
+type BridgeStatus struct {
+	Connection ConnState `json:"state"`
+	StartedAt  time.Time `json:"started_at"`
+
+	TCPAddress string `json:"tcp_address,omitempty"`
+	UDPAddress string `json:"udp_address,omitempty"`
+	Clients    int    `json:"clients"`
+
+	// Pointers, since omitempty doesn't leave out a zero time.Time
+	LastDownlinkAt  *time.Time `json:"last_downlink_at,omitempty"`  // Data from the device
+	LastUplinkAt    *time.Time `json:"last_uplink_at,omitempty"`    // Data from a ground station
+	LastTelemetryAt *time.Time `json:"last_telemetry_at,omitempty"` // Autopilot telemetry decoded
+
+	ExpiresAt *time.Time `json:"expires_at,omitempty"` // When a guest session ends
+
+	// Downlink frames estimated lost from MAVLink sequence gaps
+	DownlinkLost     uint64  `json:"downlink_lost"`
+	DownlinkLossRate float64 `json:"downlink_loss_rate"` // Fraction of downlink frames lost
+
+	Link     LinkStats      `json:"link"`
+	Training TrainingOutage `json:"training,omitempty"`
+}
+
+// Status returns the bridge's connection state, listeners, clients, downlink
+// loss and when data last passed
+func (b *Bridge) Status() BridgeStatus {
+	stats := b.stats.Snapshot()
+	status := BridgeStatus{
+		Connection: ConnStateNoData,
+		StartedAt:  b.diag.Snapshot().StartedAt,
+		Clients:    len(b.Clients()),
+		Link:       b.LinkStats(),
+		Training:   b.trainingOutage(),
+
+		DownlinkLost:     stats.Downlink.Lost,
+		DownlinkLossRate: stats.Downlink.LossRate(),
+	}
+	if !b.config.ExpiresAt.IsZero() {
+		status.ExpiresAt = &b.config.ExpiresAt
+	}
+
+	switch {
+	case b.circuitIsOpen():
+		status.Connection = ConnStateCircuitOpen
+	case b.linkDown.Load():
+		status.Connection = ConnStateReconnecting
+	case b.connData.Load():
+		status.Connection = ConnStateConnected
+	}
+
+	if addr := b.TCPAddr(); addr != nil {
+		status.TCPAddress = addr.String()
+	}
+	if addr := b.UDPAddr(); addr != nil {
+		status.UDPAddress = addr.String()
+	}
+
+	if ns := b.lastDataAt[Downlink].Load(); ns != 0 {
+		at := time.Unix(0, ns)
+		status.LastDownlinkAt = &at
+	}
+	if ns := b.lastDataAt[Uplink].Load(); ns != 0 {
+		at := time.Unix(0, ns)
+		status.LastUplinkAt = &at
+	}
+	if _, at := b.Telemetry(); !at.IsZero() {
+		status.LastTelemetryAt = &at
+	}
+
+	return status
+}
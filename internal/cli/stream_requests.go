@@ -0,0 +1,166 @@
+package cli
+
+import (
+	"slices"
+	"sync"
+
+	"github.com/pavliha/aircast/aircast-cli/internal/mavlink"
+	log "github.com/sirupsen/logrus"
+)
+
+// streamKey identifies a REQUEST_DATA_STREAM stream of a target
+type streamKey struct {
+	sysID, compID, streamID uint8
+}
+
+// gcsRates remembers the stream rates ground stations asked the autopilot
+// for, so the bridge can lower them on a poor link and give them back
+// afterwards. The zero value is ready to use.
+type gcsRates struct {
+	mu        sync.Mutex
+	streams   map[streamKey]mavlink.StreamRequest // Latest request per stream
+	intervals map[filterKey]int32                 // SET_MESSAGE_INTERVAL per target and message
+}
+
+// uplinkFrame records a ground station's rate request
+func (g *gcsRates) uplinkFrame(frame *mavlink.Frame) {
+	if req, ok := frame.StreamRequest(); ok {
+		g.mu.Lock()
+		defer g.mu.Unlock()
+		if g.streams == nil {
+			g.streams = make(map[streamKey]mavlink.StreamRequest)
+		}
+		if req.StreamID == 0 {
+			// All streams: earlier requests for single ones no longer apply
+			for key := range g.streams {
+				if key.sysID == req.TargetSystem && key.compID == req.TargetComponent {
+					delete(g.streams, key)
+				}
+			}
+		}
+		g.streams[streamKey{req.TargetSystem, req.TargetComponent, req.StreamID}] = req
+		return
+	}
+
+	if cmd, ok := frame.Command(); ok && cmd.ID == mavlink.CmdSetMessageInterval {
+		g.mu.Lock()
+		defer g.mu.Unlock()
+		if g.intervals == nil {
+			g.intervals = make(map[filterKey]int32)
+		}
+		key := filterKey{sysID: cmd.TargetSystem, compID: cmd.TargetComponent, msgID: uint32(cmd.Param1)}
+		g.intervals[key] = int32(cmd.Param2)
+	}
+}
+
+// streamRequests returns the stream requests addressed to an autopilot,
+// directly or by broadcast, all-streams requests first
+func (g *gcsRates) streamRequests(sysID, compID uint8) []mavlink.StreamRequest {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	var requests []mavlink.StreamRequest
+	for key, req := range g.streams {
+		if targets(key.sysID, key.compID, sysID, compID) {
+			requests = append(requests, req)
+		}
+	}
+	slices.SortFunc(requests, func(a, b mavlink.StreamRequest) int {
+		return int(a.StreamID) - int(b.StreamID)
+	})
+	return requests
+}
+
+// interval returns the interval a ground station set for one of an
+// autopilot's messages, if any
+func (g *gcsRates) interval(key filterKey) (int32, bool) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if interval, ok := g.intervals[key]; ok {
+		return interval, true
+	}
+	for k, interval := range g.intervals {
+		if k.msgID == key.msgID && targets(k.sysID, k.compID, key.sysID, key.compID) {
+			return interval, true
+		}
+	}
+	return 0, false
+}
+
+// targets reports whether a request for targetSys/targetComp reaches a
+// component; 0 addresses every system or component
+func targets(targetSys, targetComp, sysID, compID uint8) bool {
+	return (targetSys == 0 || targetSys == sysID) && (targetComp == 0 || targetComp == compID)
+}
+
+// lowerStreamRate asks every autopilot seen on the downlink to send its
+// telemetry streams at no more than hz, so less data crosses a metered or
+// congested link. Streams a ground station asked for more slowly, or
+// stopped, stay that way; without known requests, all streams are limited
+// to hz. It reports whether any request was sent.
+func (b *Bridge) lowerStreamRate(hz uint16) bool {
+	sent := false
+	for _, src := range b.stats.Snapshot().Sources {
+		if src.CompID != autopilotCompID {
+			continue
+		}
+
+		requests := b.gcsRates.streamRequests(src.SysID, src.CompID)
+		if len(requests) == 0 {
+			requests = []mavlink.StreamRequest{{Rate: hz, Start: true}}
+		} else if !slices.ContainsFunc(requests, func(r mavlink.StreamRequest) bool { return r.Start && r.Rate > hz }) {
+			continue // Already at or below hz
+		}
+
+		// Repeat every request, capped, since an all-streams request
+		// resets the streams requested after it
+		for _, req := range requests {
+			req.TargetSystem, req.TargetComponent = src.SysID, src.CompID
+			if req.Start {
+				req.Rate = min(req.Rate, hz)
+			}
+			if b.sendStreamRequest(req) {
+				sent = true
+			}
+		}
+	}
+	return sent
+}
+
+// restoreStreamRates repeats the ground stations' own stream requests to
+// every autopilot, undoing lowerStreamRate. It reports whether any were known.
+func (b *Bridge) restoreStreamRates() bool {
+	restored := false
+	for _, src := range b.stats.Snapshot().Sources {
+		if src.CompID != autopilotCompID {
+			continue
+		}
+		for _, req := range b.gcsRates.streamRequests(src.SysID, src.CompID) {
+			req.TargetSystem, req.TargetComponent = src.SysID, src.CompID
+			b.sendStreamRequest(req)
+			restored = true
+		}
+	}
+	return restored
+}
+
+// sendStreamRequest sends one REQUEST_DATA_STREAM, logging failures
+func (b *Bridge) sendStreamRequest(req mavlink.StreamRequest) bool {
+	frame, err := mavlink.EncodeStreamRequest(uint8(b.txSeq.Add(1)), bridgeSysID, bridgeCompID, req)
+	if err != nil {
+		b.logger.WithError(err).Error("Failed to encode stream rate request")
+		return false
+	}
+	if err := b.writeToWebSocket(frame); err != nil {
+		b.logger.WithError(err).Warn("Failed to request stream rate")
+		return false
+	}
+	b.logger.WithFields(log.Fields{
+		"sys_id":    req.TargetSystem,
+		"stream_id": req.StreamID,
+		"hz":        req.Rate,
+		"start":     req.Start,
+	}).Info("Requested telemetry stream rate")
+	return true
+}
@@ -0,0 +1,107 @@
+package cli
+
+import (
+	"net"
+	"sync"
+	"time"
+)
+
+// maxCoalesceBytes flushes a coalescing buffer early once it grows this large
+const maxCoalesceBytes = 16 * 1024
+
+// tcpClient is a connected TCP ground control station. Downlink writes go
+// through it so that they can be coalesced into fewer, larger writes.
+type tcpClient struct {
+	conn     net.Conn
+	interval time.Duration
+
+	// written is called after every write to the socket with the bytes written
+	written func(n int)
+	// failed is called when a deferred (coalesced) write fails
+	failed func(err error)
+
+	mu    sync.Mutex
+	buf   []byte
+	timer *time.Timer
+}
+
+// newTCPClient wraps a connection; interval 0 writes through immediately
+func newTCPClient(conn net.Conn, interval time.Duration, written func(n int), failed func(err error)) *tcpClient {
+	return &tcpClient{
+		conn:     conn,
+		interval: interval,
+		written:  written,
+		failed:   failed,
+	}
+}
+
+// Write sends data to the client, or queues it until the coalescing timer fires
+func (c *tcpClient) Write(data []byte) (int, error) {
+	if c.interval <= 0 {
+		n, err := c.conn.Write(data)
+		if n > 0 {
+			c.written(n)
+		}
+		return n, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.buf = append(c.buf, data...)
+
+	if len(c.buf) >= maxCoalesceBytes {
+		if err := c.flushLocked(); err != nil {
+			return 0, err
+		}
+		return len(data), nil
+	}
+
+	if c.timer == nil {
+		c.timer = time.AfterFunc(c.interval, c.flush)
+	}
+
+	return len(data), nil
+}
+
+// flush writes out any queued data; runs on the coalescing timer
+func (c *tcpClient) flush() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.flushLocked(); err != nil {
+		c.failed(err)
+	}
+}
+
+// flushLocked writes the queued data in a single write; caller must hold mu
+func (c *tcpClient) flushLocked() error {
+	if c.timer != nil {
+		c.timer.Stop()
+		c.timer = nil
+	}
+
+	if len(c.buf) == 0 {
+		return nil
+	}
+
+	n, err := c.conn.Write(c.buf)
+	if n > 0 {
+		c.written(n)
+	}
+	c.buf = c.buf[:0]
+
+	return err
+}
+
+// Close stops the coalescing timer and closes the connection
+func (c *tcpClient) Close() error {
+	c.mu.Lock()
+	if c.timer != nil {
+		c.timer.Stop()
+		c.timer = nil
+	}
+	c.mu.Unlock()
+
+	return c.conn.Close()
+}
@@ -0,0 +1,104 @@
+package cli
+
+import (
+	"fmt"
+	"time"
+)
+
+// TrainingOutage is a simulated loss of the link between the ground stations
+// and the vehicle, for drilling lost-link procedures. The WebSocket to the
+// device stays up; the bridge just stops forwarding.
+type TrainingOutage int32
+
+const (
+	// TrainingOff forwards traffic normally
+	TrainingOff TrainingOutage = iota
+	// TrainingDownlink withholds telemetry from the ground stations, while
+	// their commands and heartbeats still reach the vehicle
+	TrainingDownlink
+	// TrainingBoth drops traffic in both directions, except emergency
+	// commands
+	TrainingBoth
+)
+
+// String returns the outage name
+func (o TrainingOutage) String() string {
+	switch o {
+	case TrainingDownlink:
+		return "downlink"
+	case TrainingBoth:
+		return "both"
+	default:
+		return "off"
+	}
+}
+
+// ParseTrainingOutage parses an outage name as returned by String
+func ParseTrainingOutage(s string) (TrainingOutage, error) {
+	for _, o := range []TrainingOutage{TrainingOff, TrainingDownlink, TrainingBoth} {
+		if s == o.String() {
+			return o, nil
+		}
+	}
+	return TrainingOff, fmt.Errorf("unknown outage %q (expected off, downlink or both)", s)
+}
+
+// TrainingState is the current simulated outage
+type TrainingState struct {
+	Outage TrainingOutage `json:"outage"`
+	Until  time.Time      `json:"until,omitempty"` // When the bridge restores the link by itself
+}
+
+// SimulateOutage starts a simulated outage that ends by itself after d, so a
+// drill that is abandoned never leaves the ground stations cut off, or ends
+// one with TrainingOff
+func (b *Bridge) SimulateOutage(outage TrainingOutage, d time.Duration) TrainingState {
+	b.trainingMu.Lock()
+	defer b.trainingMu.Unlock()
+
+	if b.trainingTimer != nil {
+		b.trainingTimer.Stop()
+		b.trainingTimer = nil
+	}
+
+	previous := TrainingOutage(b.training.Swap(int32(outage)))
+	if outage == TrainingOff {
+		b.trainingUntil = time.Time{}
+		if previous != TrainingOff {
+			b.logger.Warn("Training: simulated outage ended, forwarding resumed")
+		}
+		return TrainingState{}
+	}
+
+	b.trainingUntil = time.Now().Add(d)
+	b.trainingTimer = time.AfterFunc(d, func() { b.SimulateOutage(TrainingOff, 0) })
+	b.logger.WithField("outage", outage.String()).WithField("duration", d).Warn("Training: simulated outage started")
+	return TrainingState{Outage: outage, Until: b.trainingUntil}
+}
+
+// Training returns the current simulated outage
+func (b *Bridge) Training() TrainingState {
+	b.trainingMu.Lock()
+	defer b.trainingMu.Unlock()
+	return TrainingState{Outage: TrainingOutage(b.training.Load()), Until: b.trainingUntil}
+}
+
+// trainingOutage returns the simulated outage on the hot path
+func (b *Bridge) trainingOutage() TrainingOutage {
+	return TrainingOutage(b.training.Load())
+}
+
+// MarshalText encodes the outage by name
+func (o TrainingOutage) MarshalText() ([]byte, error) {
+	return []byte(o.String()), nil
+}
+
+// UnmarshalText decodes an outage name
+func (o *TrainingOutage) UnmarshalText(text []byte) error {
+	outage, err := ParseTrainingOutage(string(text))
+	if err != nil {
+		return err
+	}
+	*o = outage
+	return nil
+}
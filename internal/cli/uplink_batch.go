@@ -0,0 +1,99 @@
+package cli
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/pavliha/aircast/aircast-cli/internal/network"
+)
+
+// defaultBatchLimit bounds a batch while the path MTU is unknown: one
+// Ethernet packet over IPv4 and TLS
+const defaultBatchLimit = network.EthernetMTU - ipv4Header - tcpHeader - tlsRecordHeader - wsHeader
+
+// uplinkBatch collects small uplink writes, e.g. single MAVLink frames of a
+// high-rate stream, so they go out as one WebSocket message instead of one
+// message each
+type uplinkBatch struct {
+	mu    sync.Mutex
+	buf   []byte
+	timer *time.Timer
+	err   error // Failure of a batch sent by the timer, for the next writer
+}
+
+// batchLimit returns the largest batch to send, so a batch never needs more
+// than one packet
+func (b *Bridge) batchLimit() int {
+	if s := b.pathMTU.Load(); s != nil && s.Budget > 0 {
+		return s.Budget
+	}
+	return defaultBatchLimit
+}
+
+// batchUplink queues data for the next batch. A batch is sent when the batch
+// interval ends or when data wouldn't fit in it; data too large for a batch
+// of its own is sent at once, after the queued data.
+func (b *Bridge) batchUplink(data []byte) error {
+	b.batch.mu.Lock()
+	defer b.batch.mu.Unlock()
+
+	if err := b.batchErrLocked(); err != nil {
+		return err
+	}
+
+	limit := b.batchLimit()
+	if len(b.batch.buf) > 0 && len(b.batch.buf)+len(data) > limit {
+		if err := b.flushBatchLocked(); err != nil {
+			return err
+		}
+	}
+	if len(data) >= limit {
+		return b.sendUplink(data)
+	}
+
+	b.batch.buf = append(b.batch.buf, data...)
+	if b.batch.timer == nil {
+		b.batch.timer = time.AfterFunc(b.config.BatchInterval, b.flushBatch)
+	}
+	return nil
+}
+
+// flushBatch sends the queued batch; runs on the batch timer. A failure is
+// kept for the next write, whose caller handles it like its own failure.
+func (b *Bridge) flushBatch() {
+	b.batch.mu.Lock()
+	defer b.batch.mu.Unlock()
+
+	if err := b.flushBatchLocked(); err != nil && b.batch.err == nil {
+		b.batch.err = err
+	}
+}
+
+// batchErrLocked returns and clears the failure of a batch sent by the
+// timer; caller must hold batch.mu
+func (b *Bridge) batchErrLocked() error {
+	err := b.batch.err
+	b.batch.err = nil
+	if err != nil {
+		return fmt.Errorf("failed to send batched uplink frames: %w", err)
+	}
+	return nil
+}
+
+// flushBatchLocked sends the queued batch as one message; caller must hold
+// batch.mu
+func (b *Bridge) flushBatchLocked() error {
+	if b.batch.timer != nil {
+		b.batch.timer.Stop()
+		b.batch.timer = nil
+	}
+	if len(b.batch.buf) == 0 {
+		return nil
+	}
+
+	err := b.writeMessage(websocket.BinaryMessage, b.batch.buf)
+	b.batch.buf = b.batch.buf[:0]
+	return err
+}
@@ -0,0 +1,378 @@
+package control
+
+import (
+	"bufio"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"os/user"
+	"strconv"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/pavliha/aircast/aircast-cli/internal/secret"
+)
+
+// TokenEnv is the environment variable Call takes the token to send from.
+// It may be a secret reference such as file:/etc/aircast/control.token.
+const TokenEnv = "AIRCAST_CONTROL_TOKEN"
+
+// Request is a command sent to a running bridge over the control socket
+type Request struct {
+	Command string            `json:"command"`
+	Args    map[string]string `json:"args,omitempty"`
+	Token   string            `json:"token,omitempty"`
+}
+
+// Response is the reply to a control Request
+type Response struct {
+	OK     bool            `json:"ok"`
+	Error  string          `json:"error,omitempty"`
+	Denied bool            `json:"denied,omitempty"` // The request's token doesn't allow the command
+	Data   json.RawMessage `json:"data,omitempty"`
+}
+
+// ErrDenied is returned by Call when the bridge refuses the command for
+// lack of the right token
+var ErrDenied = errors.New("permission denied")
+
+// Handler processes a control request and returns data to encode in the response
+type Handler func(req Request) (interface{}, error)
+
+// Permission is what a command needs to be allowed
+type Permission int
+
+const (
+	PermRead    Permission = iota // Queries such as status and clients
+	PermControl                   // Operations that change the bridge, such as kick
+)
+
+// Access decides who may use the control socket. Reaching the socket is
+// governed by its file permissions; the tokens then separate queries from
+// control operations, e.g. so a monitoring agent in the socket's group can
+// read stats but not kick clients.
+type Access struct {
+	Mode  os.FileMode // Socket file permissions; 0 means 0600 (owner only)
+	Group string      // Group name or ID to give the socket to, for modes with group bits
+
+	Token     string // Needed for control operations when set
+	ReadToken string // Needed for queries when set; never allows control operations
+}
+
+// Open reports whether anyone other than the owner can reach the socket
+// and control the bridge without a token
+func (a Access) Open() bool {
+	return a.Mode&0077 != 0 && a.Token == "" && a.ReadToken == ""
+}
+
+// allows reports whether a request carrying token may run a command
+// needing perm. The read token only ever allows queries; with a read token
+// but no control token, control operations are refused altogether.
+func (a Access) allows(perm Permission, token string) bool {
+	if a.Token != "" && tokenEqual(token, a.Token) {
+		return true
+	}
+	if perm == PermControl {
+		return a.Token == "" && a.ReadToken == ""
+	}
+	return a.ReadToken == "" || tokenEqual(token, a.ReadToken)
+}
+
+// denial explains why a request carrying token may not run command
+func (a Access) denial(command string, perm Permission, token string) string {
+	switch {
+	case perm == PermControl && a.Token == "":
+		return fmt.Sprintf("%s is disabled: this bridge only allows queries", command)
+	case token == "" && perm == PermControl:
+		return fmt.Sprintf("%s needs the control token, set %s", command, TokenEnv)
+	case token == "":
+		return fmt.Sprintf("%s needs a token, set %s", command, TokenEnv)
+	case perm == PermControl && a.ReadToken != "" && tokenEqual(token, a.ReadToken):
+		return fmt.Sprintf("%s needs the control token, the token in %s only allows queries", command, TokenEnv)
+	}
+	return fmt.Sprintf("the token in %s is not valid for this bridge", TokenEnv)
+}
+
+// tokenEqual compares tokens in constant time
+func tokenEqual(got, want string) bool {
+	return subtle.ConstantTimeCompare([]byte(got), []byte(want)) == 1
+}
+
+// handler is a registered command handler with the permission it needs
+type handler struct {
+	perm Permission
+	fn   Handler
+}
+
+// Server serves control requests on a Unix domain socket.
+// Each connection carries newline-delimited JSON requests and responses.
+type Server struct {
+	path     string
+	access   Access
+	logger   *log.Entry
+	listener net.Listener
+
+	handlers map[string]handler
+	mu       sync.RWMutex
+	wg       sync.WaitGroup
+
+	conns   map[net.Conn]struct{} // Open connections, closed by Stop
+	stopped bool
+	connsMu sync.Mutex
+}
+
+// idleTimeout is how long a connection may wait for its next request, so
+// a client that connects and sends nothing can't hold up Stop
+const idleTimeout = 30 * time.Second
+
+// NewServer creates a new control server for the given socket path
+func NewServer(path string, access Access, logger *log.Entry) *Server {
+	if logger == nil {
+		logger = log.WithField("component", "control")
+	}
+	if access.Mode == 0 {
+		access.Mode = 0600
+	}
+
+	return &Server{
+		path:     path,
+		access:   access,
+		logger:   logger,
+		handlers: make(map[string]handler),
+		conns:    make(map[net.Conn]struct{}),
+	}
+}
+
+// Handle registers a handler for a command that needs perm
+func (s *Server) Handle(command string, perm Permission, fn Handler) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.handlers[command] = handler{perm: perm, fn: fn}
+}
+
+// Path returns the socket path
+func (s *Server) Path() string {
+	return s.path
+}
+
+// Start starts listening on the control socket
+func (s *Server) Start() error {
+	// A leftover socket file from a crashed instance blocks Listen; remove it
+	// unless another instance is still answering on it
+	if _, err := os.Stat(s.path); err == nil {
+		if conn, err := net.DialTimeout("unix", s.path, time.Second); err == nil {
+			_ = conn.Close()
+			return fmt.Errorf("control socket %s is in use by another instance", s.path)
+		}
+		_ = os.Remove(s.path)
+	}
+
+	listener, err := net.Listen("unix", s.path)
+	if err != nil {
+		return fmt.Errorf("failed to listen on control socket %s: %w", s.path, err)
+	}
+
+	// Only the owning user may talk to the bridge, unless access allows more
+	if err := s.setPermissions(); err != nil {
+		_ = listener.Close()
+		return err
+	}
+
+	s.listener = listener
+	s.logger.WithField("path", s.path).Debug("Control socket listening")
+
+	s.wg.Add(1)
+	go s.accept()
+
+	return nil
+}
+
+// setPermissions applies the access mode and group to the socket file
+func (s *Server) setPermissions() error {
+	if s.access.Group != "" {
+		gid, err := lookupGroup(s.access.Group)
+		if err != nil {
+			return err
+		}
+		if err := os.Chown(s.path, -1, gid); err != nil {
+			return fmt.Errorf("failed to give control socket to group %s: %w", s.access.Group, err)
+		}
+	}
+	if err := os.Chmod(s.path, s.access.Mode); err != nil {
+		return fmt.Errorf("failed to set control socket permissions: %w", err)
+	}
+	return nil
+}
+
+// lookupGroup returns the ID of a group given by name or ID
+func lookupGroup(group string) (int, error) {
+	if gid, err := strconv.Atoi(group); err == nil {
+		return gid, nil
+	}
+	g, err := user.LookupGroup(group)
+	if err != nil {
+		return 0, fmt.Errorf("unknown group %s: %w", group, err)
+	}
+	return strconv.Atoi(g.Gid)
+}
+
+// Stop closes the control socket and its open connections, and waits for
+// them to finish
+func (s *Server) Stop() error {
+	if s.listener == nil {
+		return nil
+	}
+
+	err := s.listener.Close()
+	s.connsMu.Lock()
+	s.stopped = true
+	for conn := range s.conns {
+		_ = conn.Close()
+	}
+	s.connsMu.Unlock()
+	s.wg.Wait()
+	_ = os.Remove(s.path)
+
+	return err
+}
+
+// accept accepts control connections until the listener is closed
+func (s *Server) accept() {
+	defer s.wg.Done()
+
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return
+			}
+			s.logger.WithError(err).Debug("Control accept error")
+			continue
+		}
+
+		s.connsMu.Lock()
+		if s.stopped {
+			s.connsMu.Unlock()
+			_ = conn.Close()
+			return
+		}
+		s.conns[conn] = struct{}{}
+		s.connsMu.Unlock()
+
+		s.wg.Add(1)
+		go s.serve(conn)
+	}
+}
+
+// serve handles requests on a single control connection
+func (s *Server) serve(conn net.Conn) {
+	defer s.wg.Done()
+	defer func() {
+		s.connsMu.Lock()
+		delete(s.conns, conn)
+		s.connsMu.Unlock()
+		_ = conn.Close()
+	}()
+
+	scanner := bufio.NewScanner(conn)
+	encoder := json.NewEncoder(conn)
+
+	for {
+		// The deadline covers waiting for the request and writing the reply
+		_ = conn.SetDeadline(time.Now().Add(idleTimeout))
+		if !scanner.Scan() {
+			return
+		}
+
+		var req Request
+		if err := json.Unmarshal(scanner.Bytes(), &req); err != nil {
+			_ = encoder.Encode(Response{Error: fmt.Sprintf("invalid request: %v", err)})
+			continue
+		}
+
+		if err := encoder.Encode(s.dispatch(req)); err != nil {
+			s.logger.WithError(err).Debug("Failed to write control response")
+			return
+		}
+	}
+}
+
+// dispatch runs the handler registered for a request's command
+func (s *Server) dispatch(req Request) Response {
+	s.mu.RLock()
+	h, ok := s.handlers[req.Command]
+	s.mu.RUnlock()
+
+	if !ok {
+		return Response{Error: fmt.Sprintf("unknown command %q", req.Command)}
+	}
+
+	if !s.access.allows(h.perm, req.Token) {
+		s.logger.WithFields(log.Fields{
+			"command":   req.Command,
+			"has_token": req.Token != "",
+		}).Warn("Control request denied")
+		return Response{Error: s.access.denial(req.Command, h.perm, req.Token), Denied: true}
+	}
+
+	s.logger.WithField("command", req.Command).Debug("Control request")
+
+	result, err := h.fn(req)
+	if err != nil {
+		return Response{Error: err.Error()}
+	}
+
+	data, err := json.Marshal(result)
+	if err != nil {
+		return Response{Error: fmt.Sprintf("failed to encode response: %v", err)}
+	}
+
+	return Response{OK: true, Data: data}
+}
+
+// Call sends a single request to the bridge listening on path and decodes
+// the response data into out (which may be nil). The request carries the
+// token from TokenEnv, if set.
+func Call(path, command string, args map[string]string, out interface{}) error {
+	token, err := secret.Resolve(os.Getenv(TokenEnv), "Control token ("+TokenEnv+")")
+	if err != nil {
+		return err
+	}
+
+	conn, err := net.DialTimeout("unix", path, 2*time.Second)
+	if err != nil {
+		return fmt.Errorf("no running bridge found at %s: %w", path, err)
+	}
+	defer conn.Close()
+
+	_ = conn.SetDeadline(time.Now().Add(10 * time.Second))
+
+	if err := json.NewEncoder(conn).Encode(Request{Command: command, Args: args, Token: token}); err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+
+	var resp Response
+	if err := json.NewDecoder(conn).Decode(&resp); err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if resp.Denied {
+		return fmt.Errorf("%w: %s", ErrDenied, resp.Error)
+	}
+	if !resp.OK {
+		return errors.New(resp.Error)
+	}
+
+	if out != nil && len(resp.Data) > 0 {
+		if err := json.Unmarshal(resp.Data, out); err != nil {
+			return fmt.Errorf("failed to parse response: %w", err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,81 @@
+// Package gcsconfig generates ground control station connection settings
+// pointing at the bridge, so links don't have to be set up by hand.
+package gcsconfig
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+)
+
+// Link describes the bridge endpoint a ground station should connect to
+type Link struct {
+	Name     string // Name shown in the ground station's link list
+	Protocol string // "tcp" or "udp"
+	Host     string // Address of the bridge as seen from the ground station
+	Port     int    // Port the bridge listens on
+}
+
+// NewLink builds a link from a bridge listen address. A wildcard or empty
+// listen host is replaced with host, or 127.0.0.1 if host is empty.
+func NewLink(name, protocol, listen, host string) (Link, error) {
+	listenHost, portStr, err := net.SplitHostPort(listen)
+	if err != nil {
+		return Link{}, fmt.Errorf("invalid %s address %q: %w", protocol, listen, err)
+	}
+	port, err := strconv.Atoi(portStr)
+	if err != nil || port < 1 || port > 65535 {
+		return Link{}, fmt.Errorf("invalid %s port %q", protocol, portStr)
+	}
+
+	if host == "" {
+		host = listenHost
+		if ip := net.ParseIP(host); host == "" || (ip != nil && ip.IsUnspecified()) {
+			host = "127.0.0.1"
+		}
+	}
+
+	return Link{Name: name, Protocol: protocol, Host: host, Port: port}, nil
+}
+
+// backupAndWrite saves the current contents of path to path.bak, if it
+// exists, and replaces it with data
+func backupAndWrite(path string, data []byte) error {
+	if old, err := os.ReadFile(path); err == nil {
+		if err := os.WriteFile(path+".bak", old, 0600); err != nil {
+			return fmt.Errorf("failed to back up %s: %w", path, err)
+		}
+	} else if !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", filepath.Dir(path), err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return nil
+}
+
+// appDataDir returns the per-user application settings directory: %APPDATA%
+// on Windows and ~/.config elsewhere, which is where Qt keeps settings on
+// Linux and macOS
+func appDataDir() (string, error) {
+	if runtime.GOOS == "windows" {
+		if dir := os.Getenv("APPDATA"); dir != "" {
+			return dir, nil
+		}
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	if runtime.GOOS == "windows" {
+		return filepath.Join(home, "AppData", "Roaming"), nil
+	}
+	return filepath.Join(home, ".config"), nil
+}
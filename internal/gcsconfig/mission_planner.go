@@ -0,0 +1,90 @@
+package gcsconfig
+
+import (
+	"fmt"
+	"html"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// MissionPlannerSettingsPath returns the location of Mission Planner's
+// config.xml for the current user
+func MissionPlannerSettingsPath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	return filepath.Join(home, "Documents", "Mission Planner", "config.xml"), nil
+}
+
+// missionPlannerSettings returns the config.xml keys that select link as the
+// connection Mission Planner opens by default
+func missionPlannerSettings(link Link) ([][2]string, error) {
+	if link.Protocol != "tcp" {
+		return nil, fmt.Errorf("Mission Planner presets support TCP only")
+	}
+	return [][2]string{
+		{"comport", "TCP"},
+		{"TCP_host", link.Host},
+		{"TCP_port", strconv.Itoa(link.Port)},
+	}, nil
+}
+
+// MissionPlanner returns a Mission Planner config.xml preset that connects
+// to the bridge over TCP
+func MissionPlanner(link Link) ([]byte, error) {
+	settings, err := missionPlannerSettings(link)
+	if err != nil {
+		return nil, err
+	}
+
+	var b strings.Builder
+	b.WriteString("<?xml version=\"1.0\" encoding=\"utf-8\"?>\n<Config>\n")
+	for _, kv := range settings {
+		fmt.Fprintf(&b, "  <%s>%s</%s>\n", kv[0], html.EscapeString(kv[1]), kv[0])
+	}
+	b.WriteString("</Config>\n")
+	return []byte(b.String()), nil
+}
+
+// InstallMissionPlanner sets link as the default connection in the Mission
+// Planner config.xml at path, keeping every other setting. The previous
+// file is kept as path.bak. Mission Planner must not be running, or it will
+// overwrite the change when it exits.
+func InstallMissionPlanner(path string, link Link) error {
+	settings, err := missionPlannerSettings(link)
+	if err != nil {
+		return err
+	}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		data, err = MissionPlanner(link)
+		if err != nil {
+			return err
+		}
+		return backupAndWrite(path, data)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	config := string(data)
+	if !strings.Contains(config, "</Config>") {
+		return fmt.Errorf("%s is not a Mission Planner config file", path)
+	}
+	for _, kv := range settings {
+		element := fmt.Sprintf("<%s>%s</%s>", kv[0], html.EscapeString(kv[1]), kv[0])
+		re := regexp.MustCompile(`<` + kv[0] + `>[^<]*</` + kv[0] + `>|<` + kv[0] + ` */>`)
+		if re.MatchString(config) {
+			config = re.ReplaceAllLiteralString(config, element)
+		} else {
+			end := strings.LastIndex(config, "</Config>")
+			config = config[:end] + "  " + element + "\n" + config[end:]
+		}
+	}
+	return backupAndWrite(path, []byte(config))
+}
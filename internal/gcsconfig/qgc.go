@@ -0,0 +1,176 @@
+package gcsconfig
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// QGroundControl link types, from LinkConfiguration::LinkType
+const (
+	qgcTypeUDP = 1
+	qgcTypeTCP = 2
+)
+
+// qgcLinkSection is the settings group QGroundControl stores comm links in
+const qgcLinkSection = "LinkConfigurations"
+
+// QGCSettingsPath returns the location of QGroundControl.ini for the current user
+func QGCSettingsPath() (string, error) {
+	dir, err := appDataDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "QGroundControl.org", "QGroundControl.ini"), nil
+}
+
+// qgcLinkSettings returns the QSettings keys describing link, without the
+// LinkN\ prefix
+func qgcLinkSettings(link Link) [][2]string {
+	settings := [][2]string{{"name", link.Name}}
+	if link.Protocol == "udp" {
+		// QGC binds an ephemeral local port and sends to the bridge, which
+		// replies to whichever address it last heard from
+		settings = append(settings,
+			[2]string{"type", strconv.Itoa(qgcTypeUDP)},
+			[2]string{"port", "0"},
+			[2]string{"hostCount", "1"},
+			[2]string{"host0", fmt.Sprintf("%s:%d", link.Host, link.Port)},
+		)
+	} else {
+		settings = append(settings,
+			[2]string{"type", strconv.Itoa(qgcTypeTCP)},
+			[2]string{"host", link.Host},
+			[2]string{"port", strconv.Itoa(link.Port)},
+		)
+	}
+	return append(settings,
+		[2]string{"auto", "false"},
+		[2]string{"high_latency", "false"},
+	)
+}
+
+// QGC returns a QGroundControl.ini fragment defining a comm link to the bridge
+func QGC(link Link) []byte {
+	var b strings.Builder
+	b.WriteString("[" + qgcLinkSection + "]\n")
+	b.WriteString("count=1\n")
+	for _, kv := range qgcLinkSettings(link) {
+		fmt.Fprintf(&b, "Link0\\%s=%s\n", kv[0], kv[1])
+	}
+	return []byte(b.String())
+}
+
+// InstallQGC adds link to the QGroundControl settings file at path,
+// replacing any existing link with the same name. The previous file is kept
+// as path.bak. QGroundControl must not be running, or it will overwrite the
+// change when it exits.
+func InstallQGC(path string, link Link) error {
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	return backupAndWrite(path, mergeQGC(string(data), link))
+}
+
+// mergeQGC rewrites the link section of a QGroundControl.ini with link
+// added, leaving every other section untouched
+func mergeQGC(ini string, link Link) []byte {
+	var out, section []string
+	inLinks, found := false, false
+	for _, line := range strings.Split(strings.TrimRight(ini, "\n"), "\n") {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "[") && strings.HasSuffix(trimmed, "]") {
+			inLinks = trimmed == "["+qgcLinkSection+"]"
+			if inLinks {
+				found = true
+				out = append(out, "\x00") // Placeholder for the rewritten section
+				continue
+			}
+		}
+		if inLinks {
+			section = append(section, line)
+		} else if line != "" || len(out) > 0 {
+			out = append(out, line)
+		}
+	}
+
+	links := mergeQGCLinks(section, link)
+	if !found {
+		if len(out) > 0 {
+			out = append(out, "")
+		}
+		out = append(out, "\x00")
+	}
+
+	var b strings.Builder
+	for _, line := range out {
+		if line == "\x00" {
+			b.WriteString(links)
+			continue
+		}
+		b.WriteString(line + "\n")
+	}
+	return []byte(b.String())
+}
+
+// mergeQGCLinks renumbers the existing links in a LinkConfigurations
+// section, drops any named like link, and appends link
+func mergeQGCLinks(lines []string, link Link) string {
+	links := map[int][][2]string{}
+	var other []string
+	for _, line := range lines {
+		key, value, ok := strings.Cut(line, "=")
+		if !ok || key == "count" {
+			if strings.TrimSpace(line) != "" && key != "count" {
+				other = append(other, line)
+			}
+			continue
+		}
+		prefix, field, ok := strings.Cut(key, "\\")
+		n, err := strconv.Atoi(strings.TrimPrefix(prefix, "Link"))
+		if !ok || !strings.HasPrefix(prefix, "Link") || err != nil {
+			other = append(other, line)
+			continue
+		}
+		links[n] = append(links[n], [2]string{field, value})
+	}
+
+	var indexes []int
+	for n, settings := range links {
+		if settingValue(settings, "name") != link.Name {
+			indexes = append(indexes, n)
+		}
+	}
+	sort.Ints(indexes)
+
+	var b strings.Builder
+	b.WriteString("[" + qgcLinkSection + "]\n")
+	fmt.Fprintf(&b, "count=%d\n", len(indexes)+1)
+	for i, n := range indexes {
+		for _, kv := range links[n] {
+			fmt.Fprintf(&b, "Link%d\\%s=%s\n", i, kv[0], kv[1])
+		}
+	}
+	for _, kv := range qgcLinkSettings(link) {
+		fmt.Fprintf(&b, "Link%d\\%s=%s\n", len(indexes), kv[0], kv[1])
+	}
+	for _, line := range other {
+		b.WriteString(line + "\n")
+	}
+	b.WriteString("\n")
+	return b.String()
+}
+
+// settingValue returns the value of key in settings, or ""
+func settingValue(settings [][2]string, key string) string {
+	for _, kv := range settings {
+		if kv[0] == key {
+			return kv[1]
+		}
+	}
+	return ""
+}
@@ -0,0 +1,216 @@
+// Package mapview serves a local web page showing the vehicle on a map, for
+// observers who don't have a ground control station installed.
+package mapview
+
+import (
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/pavliha/aircast/aircast-cli/internal/mavlink"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	sampleInterval = 1 * time.Second
+	maxTrackPoints = 7200 // Two hours at one point per second
+	minTrackMove   = 0.5  // Meters moved before a new track point is added
+)
+
+//go:embed index.html
+var indexHTML []byte
+
+// Source returns the latest vehicle state and when it was last updated
+type Source func() (mavlink.Telemetry, time.Time)
+
+// State is the vehicle state served to the page
+type State struct {
+	Position    bool         `json:"position"`
+	Lat         float64      `json:"lat"`
+	Lon         float64      `json:"lon"`
+	AltMSL      float64      `json:"alt_msl"`
+	AltRelative float64      `json:"alt_rel"`
+	GroundSpeed float64      `json:"ground_speed"`
+	ClimbRate   float64      `json:"climb_rate"`
+	Heading     float64      `json:"heading"`
+	Summary     string       `json:"summary"`
+	Age         float64      `json:"age"` // Seconds since the last telemetry update, -1 if never
+	Track       [][2]float64 `json:"track"`
+}
+
+// Server serves the map page and the vehicle state it polls
+type Server struct {
+	addr     string
+	source   Source
+	logger   *log.Entry
+	listener net.Listener
+	server   *http.Server
+
+	mu    sync.Mutex
+	track [][2]float64
+
+	done chan struct{}
+	wg   sync.WaitGroup
+}
+
+// NewServer creates a map server on addr fed by source
+func NewServer(addr string, source Source, logger *log.Entry) *Server {
+	if logger == nil {
+		logger = log.WithField("component", "map")
+	}
+
+	return &Server{
+		addr:   addr,
+		source: source,
+		logger: logger,
+		done:   make(chan struct{}),
+	}
+}
+
+// Start starts listening and sampling the vehicle track
+func (s *Server) Start() error {
+	listener, err := net.Listen("tcp", s.addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", s.addr, err)
+	}
+	s.listener = listener
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", s.handleIndex)
+	mux.HandleFunc("/state", s.handleState)
+	s.server = &http.Server{
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	s.wg.Add(2)
+	go s.serve()
+	go s.sample()
+
+	s.logger.WithField("address", listener.Addr().String()).Info("Map view listening")
+	return nil
+}
+
+// Addr returns the address the server is bound to
+func (s *Server) Addr() net.Addr {
+	if s.listener == nil {
+		return nil
+	}
+	return s.listener.Addr()
+}
+
+// Stop shuts the server down
+func (s *Server) Stop() error {
+	if s.server == nil {
+		return nil
+	}
+
+	close(s.done)
+	err := s.server.Close()
+	s.wg.Wait()
+	return err
+}
+
+// serve serves HTTP until the server is closed
+func (s *Server) serve() {
+	defer s.wg.Done()
+
+	if err := s.server.Serve(s.listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		s.logger.WithError(err).Error("Map view stopped")
+	}
+}
+
+// sample records the vehicle track once per interval
+func (s *Server) sample() {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(sampleInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.done:
+			return
+		case <-ticker.C:
+			t, _ := s.source()
+			s.record(t)
+		}
+	}
+}
+
+// record appends the vehicle position to the track once it has moved
+func (s *Server) record(t mavlink.Telemetry) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !t.HavePosition || (t.Lat == 0 && t.Lon == 0) {
+		return
+	}
+
+	point := [2]float64{t.Lat, t.Lon}
+	if n := len(s.track); n > 0 && distance(s.track[n-1], point) < minTrackMove {
+		return
+	}
+	if len(s.track) >= maxTrackPoints {
+		s.track = append(s.track[:0], s.track[1:]...)
+	}
+	s.track = append(s.track, point)
+}
+
+// state assembles the served vehicle state
+func (s *Server) state() State {
+	t, updated := s.source()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	state := State{
+		Position:    t.HavePosition,
+		Lat:         t.Lat,
+		Lon:         t.Lon,
+		AltMSL:      t.AltMSL,
+		AltRelative: t.AltRelative,
+		GroundSpeed: t.GroundSpeed,
+		ClimbRate:   t.ClimbRate,
+		Heading:     t.Heading,
+		Summary:     t.String(),
+		Age:         -1,
+		Track:       append([][2]float64{}, s.track...),
+	}
+	if !updated.IsZero() {
+		state.Age = time.Since(updated).Seconds()
+	}
+	return state
+}
+
+// handleIndex serves the map page
+func (s *Server) handleIndex(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/" {
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	_, _ = w.Write(indexHTML)
+}
+
+// handleState serves the vehicle state as JSON
+func (s *Server) handleState(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	_ = json.NewEncoder(w).Encode(s.state())
+}
+
+// distance returns the approximate distance in meters between two points
+func distance(a, b [2]float64) float64 {
+	const earthRadius = 6371000
+	lat := (a[0] + b[0]) / 2 * math.Pi / 180
+	dLat := (b[0] - a[0]) * math.Pi / 180
+	dLon := (b[1] - a[1]) * math.Pi / 180 * math.Cos(lat)
+	return earthRadius * math.Hypot(dLat, dLon)
+}
@@ -0,0 +1,190 @@
+package mavlink
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+)
+
+// Message IDs of command and mode requests and legacy stream rate requests
+const (
+	MsgIDSetMode           = 11
+	MsgIDRequestDataStream = 66
+	MsgIDCommandInt        = 75
+	MsgIDCommandLong       = 76
+)
+
+// CmdSetMessageInterval is MAV_CMD_SET_MESSAGE_INTERVAL: param1 is the
+// message ID, param2 the interval in microseconds (-1 disables the message,
+// 0 restores its default rate)
+const CmdSetMessageInterval = 511
+
+// CmdDoSetMode is MAV_CMD_DO_SET_MODE: param1 is the base mode, param2 the
+// custom mode (PX4: main mode) and param3 the custom sub mode (PX4)
+const CmdDoSetMode = 176
+
+// commandLongLen is the length of a COMMAND_LONG payload: seven float
+// params, the command, target system and component, and confirmation
+const commandLongLen = 33
+
+// Wire offsets shared by COMMAND_INT and COMMAND_LONG
+const (
+	commandParam1Offset = 0
+	commandParam2Offset = 4
+	commandParam3Offset = 8
+	commandIDOffset     = 28
+	commandTargetOffset = 30
+)
+
+// Wire offsets of SET_MODE
+const (
+	setModeCustomModeOffset = 0
+	setModeTargetOffset     = 4
+)
+
+// Wire layout of REQUEST_DATA_STREAM
+const (
+	streamRateOffset   = 0
+	streamTargetOffset = 2
+	streamIDOffset     = 4
+	streamStartOffset  = 5
+	requestStreamLen   = 6
+)
+
+// Command is a MAV_CMD request carried by COMMAND_LONG or COMMAND_INT
+type Command struct {
+	ID              uint16 // MAV_CMD
+	Param1          float32
+	Param2          float32
+	Param3          float32
+	TargetSystem    uint8
+	TargetComponent uint8
+}
+
+// Command decodes the command a COMMAND_LONG or COMMAND_INT frame requests;
+// ok is false for other messages
+func (f *Frame) Command() (cmd Command, ok bool) {
+	if f.MsgID != MsgIDCommandLong && f.MsgID != MsgIDCommandInt {
+		return Command{}, false
+	}
+	return Command{
+		ID:              payloadUint16(f.Payload, commandIDOffset),
+		Param1:          payloadFloat32(f.Payload, commandParam1Offset),
+		Param2:          payloadFloat32(f.Payload, commandParam2Offset),
+		Param3:          payloadFloat32(f.Payload, commandParam3Offset),
+		TargetSystem:    payloadByte(f.Payload, commandTargetOffset),
+		TargetComponent: payloadByte(f.Payload, commandTargetOffset+1),
+	}, true
+}
+
+// ModeRequest is a flight mode change requested with SET_MODE or
+// MAV_CMD_DO_SET_MODE
+type ModeRequest struct {
+	TargetSystem uint8
+
+	customMode uint32
+	subMode    uint32 // PX4 sub mode of MAV_CMD_DO_SET_MODE
+	command    bool   // Requested with MAV_CMD_DO_SET_MODE
+}
+
+// ModeRequest decodes the flight mode change a SET_MODE frame, or a
+// COMMAND_LONG or COMMAND_INT with MAV_CMD_DO_SET_MODE, requests; ok is
+// false for other frames
+func (f *Frame) ModeRequest() (req ModeRequest, ok bool) {
+	if f.MsgID == MsgIDSetMode {
+		return ModeRequest{
+			TargetSystem: payloadByte(f.Payload, setModeTargetOffset),
+			customMode:   payloadUint32(f.Payload, setModeCustomModeOffset),
+		}, true
+	}
+
+	cmd, ok := f.Command()
+	if !ok || cmd.ID != CmdDoSetMode {
+		return ModeRequest{}, false
+	}
+	return ModeRequest{
+		TargetSystem: cmd.TargetSystem,
+		customMode:   uint32(cmd.Param2),
+		subMode:      uint32(cmd.Param3),
+		command:      true,
+	}, true
+}
+
+// Mode returns the name of the requested flight mode on an autopilot and
+// vehicle type, or "" if the autopilot's modes aren't known
+func (r ModeRequest) Mode(autopilot, vehicleType uint8) string {
+	customMode := r.customMode
+	if r.command && autopilot == autopilotPX4 {
+		// PX4 takes its main and sub mode as separate params
+		customMode = (r.customMode&0xff)<<16 | (r.subMode&0xff)<<24
+	}
+	return ModeName(autopilot, vehicleType, customMode)
+}
+
+// StreamRequest is a legacy REQUEST_DATA_STREAM request to start or stop
+// a group of an autopilot's messages, or change its rate
+type StreamRequest struct {
+	TargetSystem    uint8
+	TargetComponent uint8
+	StreamID        uint8 // MAV_DATA_STREAM, 0 for all streams
+	Rate            uint16
+	Start           bool
+}
+
+// StreamRequest decodes a REQUEST_DATA_STREAM frame; ok is false for other messages
+func (f *Frame) StreamRequest() (req StreamRequest, ok bool) {
+	if f.MsgID != MsgIDRequestDataStream {
+		return StreamRequest{}, false
+	}
+	return StreamRequest{
+		TargetSystem:    payloadByte(f.Payload, streamTargetOffset),
+		TargetComponent: payloadByte(f.Payload, streamTargetOffset+1),
+		StreamID:        payloadByte(f.Payload, streamIDOffset),
+		Rate:            payloadUint16(f.Payload, streamRateOffset),
+		Start:           payloadByte(f.Payload, streamStartOffset) != 0,
+	}, true
+}
+
+// EncodeStreamRequest builds a REQUEST_DATA_STREAM frame
+func EncodeStreamRequest(seq, sysID, compID uint8, req StreamRequest) ([]byte, error) {
+	payload := make([]byte, requestStreamLen)
+	binary.LittleEndian.PutUint16(payload[streamRateOffset:], req.Rate)
+	payload[streamTargetOffset] = req.TargetSystem
+	payload[streamTargetOffset+1] = req.TargetComponent
+	payload[streamIDOffset] = req.StreamID
+	if req.Start {
+		payload[streamStartOffset] = 1
+	}
+	return EncodeV2(seq, sysID, compID, MsgIDRequestDataStream, payload)
+}
+
+// EncodeCommandLong builds a COMMAND_LONG frame requesting command of a
+// target component; params are param1 onwards, the rest are 0
+func EncodeCommandLong(seq, sysID, compID, targetSys, targetComp uint8, command uint16, params ...float32) ([]byte, error) {
+	if len(params) > 7 {
+		return nil, fmt.Errorf("mavlink: COMMAND_LONG has 7 params, got %d", len(params))
+	}
+
+	payload := make([]byte, commandLongLen)
+	for i, p := range params {
+		binary.LittleEndian.PutUint32(payload[commandParam1Offset+4*i:], math.Float32bits(p))
+	}
+	binary.LittleEndian.PutUint16(payload[commandIDOffset:], command)
+	payload[commandTargetOffset] = targetSys
+	payload[commandTargetOffset+1] = targetComp
+	return EncodeV2(seq, sysID, compID, MsgIDCommandLong, payload)
+}
+
+// payloadUint32 reads a little-endian uint32 from a possibly truncated payload
+func payloadUint32(payload []byte, offset int) uint32 {
+	var b [4]byte
+	for i := range b {
+		b[i] = payloadByte(payload, offset+i)
+	}
+	return binary.LittleEndian.Uint32(b[:])
+}
+
+// payloadFloat32 reads a little-endian float from a possibly truncated payload
+func payloadFloat32(payload []byte, offset int) float32 {
+	return math.Float32frombits(payloadUint32(payload, offset))
+}
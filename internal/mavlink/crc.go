@@ -0,0 +1,28 @@
+package mavlink
+
+// crcX25 computes the CRC-16/MCRF4XX checksum used by MAVLink
+type crcX25 uint16
+
+// newCRC returns a checksum initialised with the MAVLink seed
+func newCRC() crcX25 {
+	return 0xFFFF
+}
+
+// write accumulates bytes into the checksum
+func (c *crcX25) write(data []byte) {
+	crc := uint16(*c)
+	for _, b := range data {
+		tmp := b ^ byte(crc&0xFF)
+		tmp ^= tmp << 4
+		crc = (crc >> 8) ^ (uint16(tmp) << 8) ^ (uint16(tmp) << 3) ^ (uint16(tmp) >> 4)
+	}
+	*c = crcX25(crc)
+}
+
+// checksum calculates the checksum of a frame body followed by its CRC_EXTRA byte
+func checksum(body []byte, extra byte) uint16 {
+	crc := newCRC()
+	crc.write(body)
+	crc.write([]byte{extra})
+	return uint16(crc)
+}
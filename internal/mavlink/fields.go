@@ -0,0 +1,449 @@
+package mavlink
+
+import (
+	"encoding/binary"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// messageFields lists each message's payload fields in wire order (largest
+// type first, then extensions), as "type name" or "type name[length]".
+// Generated from the ardupilotmega.xml message definitions.
+var messageFields = map[uint32]string{
+	0:     "uint32_t custom_mode, uint8_t type, uint8_t autopilot, uint8_t base_mode, uint8_t system_status, uint8_t mavlink_version",
+	1:     "uint32_t onboard_control_sensors_present, uint32_t onboard_control_sensors_enabled, uint32_t onboard_control_sensors_health, uint16_t load, uint16_t voltage_battery, int16_t current_battery, uint16_t drop_rate_comm, uint16_t errors_comm, uint16_t errors_count1, uint16_t errors_count2, uint16_t errors_count3, uint16_t errors_count4, int8_t battery_remaining, uint32_t onboard_control_sensors_present_extended, uint32_t onboard_control_sensors_enabled_extended, uint32_t onboard_control_sensors_health_extended",
+	2:     "uint64_t time_unix_usec, uint32_t time_boot_ms",
+	4:     "uint64_t time_usec, uint32_t seq, uint8_t target_system, uint8_t target_component",
+	5:     "uint8_t target_system, uint8_t control_request, uint8_t version, char passkey[25]",
+	6:     "uint8_t gcs_system_id, uint8_t control_request, uint8_t ack",
+	7:     "char key[32]",
+	8:     "uint64_t timestamp, uint32_t tx_rate, uint32_t rx_rate, uint32_t messages_sent, uint32_t messages_received, uint32_t messages_lost, uint16_t rx_parse_err, uint16_t tx_overflows, uint16_t rx_overflows, uint8_t tx_buf, uint8_t rx_buf",
+	11:    "uint32_t custom_mode, uint8_t target_system, uint8_t base_mode",
+	20:    "int16_t param_index, uint8_t target_system, uint8_t target_component, char param_id[16]",
+	21:    "uint8_t target_system, uint8_t target_component",
+	22:    "float param_value, uint16_t param_count, uint16_t param_index, char param_id[16], uint8_t param_type",
+	23:    "float param_value, uint8_t target_system, uint8_t target_component, char param_id[16], uint8_t param_type",
+	24:    "uint64_t time_usec, int32_t lat, int32_t lon, int32_t alt, uint16_t eph, uint16_t epv, uint16_t vel, uint16_t cog, uint8_t fix_type, uint8_t satellites_visible, int32_t alt_ellipsoid, uint32_t h_acc, uint32_t v_acc, uint32_t vel_acc, uint32_t hdg_acc, uint16_t yaw",
+	25:    "uint8_t satellites_visible, uint8_t satellite_prn[20], uint8_t satellite_used[20], uint8_t satellite_elevation[20], uint8_t satellite_azimuth[20], uint8_t satellite_snr[20]",
+	26:    "uint32_t time_boot_ms, int16_t xacc, int16_t yacc, int16_t zacc, int16_t xgyro, int16_t ygyro, int16_t zgyro, int16_t xmag, int16_t ymag, int16_t zmag, int16_t temperature",
+	27:    "uint64_t time_usec, int16_t xacc, int16_t yacc, int16_t zacc, int16_t xgyro, int16_t ygyro, int16_t zgyro, int16_t xmag, int16_t ymag, int16_t zmag, uint8_t id, int16_t temperature",
+	28:    "uint64_t time_usec, int16_t press_abs, int16_t press_diff1, int16_t press_diff2, int16_t temperature",
+	29:    "uint32_t time_boot_ms, float press_abs, float press_diff, int16_t temperature, int16_t temperature_press_diff",
+	30:    "uint32_t time_boot_ms, float roll, float pitch, float yaw, float rollspeed, float pitchspeed, float yawspeed",
+	31:    "uint32_t time_boot_ms, float q1, float q2, float q3, float q4, float rollspeed, float pitchspeed, float yawspeed, float repr_offset_q[4]",
+	32:    "uint32_t time_boot_ms, float x, float y, float z, float vx, float vy, float vz",
+	33:    "uint32_t time_boot_ms, int32_t lat, int32_t lon, int32_t alt, int32_t relative_alt, int16_t vx, int16_t vy, int16_t vz, uint16_t hdg",
+	34:    "uint32_t time_boot_ms, int16_t chan1_scaled, int16_t chan2_scaled, int16_t chan3_scaled, int16_t chan4_scaled, int16_t chan5_scaled, int16_t chan6_scaled, int16_t chan7_scaled, int16_t chan8_scaled, uint8_t port, uint8_t rssi",
+	35:    "uint32_t time_boot_ms, uint16_t chan1_raw, uint16_t chan2_raw, uint16_t chan3_raw, uint16_t chan4_raw, uint16_t chan5_raw, uint16_t chan6_raw, uint16_t chan7_raw, uint16_t chan8_raw, uint8_t port, uint8_t rssi",
+	36:    "uint32_t time_usec, uint16_t servo1_raw, uint16_t servo2_raw, uint16_t servo3_raw, uint16_t servo4_raw, uint16_t servo5_raw, uint16_t servo6_raw, uint16_t servo7_raw, uint16_t servo8_raw, uint8_t port, uint16_t servo9_raw, uint16_t servo10_raw, uint16_t servo11_raw, uint16_t servo12_raw, uint16_t servo13_raw, uint16_t servo14_raw, uint16_t servo15_raw, uint16_t servo16_raw",
+	37:    "int16_t start_index, int16_t end_index, uint8_t target_system, uint8_t target_component, uint8_t mission_type",
+	38:    "int16_t start_index, int16_t end_index, uint8_t target_system, uint8_t target_component, uint8_t mission_type",
+	39:    "float param1, float param2, float param3, float param4, float x, float y, float z, uint16_t seq, uint16_t command, uint8_t target_system, uint8_t target_component, uint8_t frame, uint8_t current, uint8_t autocontinue, uint8_t mission_type",
+	40:    "uint16_t seq, uint8_t target_system, uint8_t target_component, uint8_t mission_type",
+	41:    "uint16_t seq, uint8_t target_system, uint8_t target_component",
+	42:    "uint16_t seq, uint16_t total, uint8_t mission_state, uint8_t mission_mode, uint32_t mission_id, uint32_t fence_id, uint32_t rally_points_id",
+	43:    "uint8_t target_system, uint8_t target_component, uint8_t mission_type",
+	44:    "uint16_t count, uint8_t target_system, uint8_t target_component, uint8_t mission_type, uint32_t opaque_id",
+	45:    "uint8_t target_system, uint8_t target_component, uint8_t mission_type",
+	46:    "uint16_t seq",
+	47:    "uint8_t target_system, uint8_t target_component, uint8_t type, uint8_t mission_type, uint32_t opaque_id",
+	48:    "int32_t latitude, int32_t longitude, int32_t altitude, uint8_t target_system, uint64_t time_usec",
+	49:    "int32_t latitude, int32_t longitude, int32_t altitude, uint64_t time_usec",
+	50:    "float param_value0, float scale, float param_value_min, float param_value_max, int16_t param_index, uint8_t target_system, uint8_t target_component, char param_id[16], uint8_t parameter_rc_channel_index",
+	51:    "uint16_t seq, uint8_t target_system, uint8_t target_component, uint8_t mission_type",
+	54:    "float p1x, float p1y, float p1z, float p2x, float p2y, float p2z, uint8_t target_system, uint8_t target_component, uint8_t frame",
+	55:    "float p1x, float p1y, float p1z, float p2x, float p2y, float p2z, uint8_t frame",
+	61:    "uint64_t time_usec, float q[4], float rollspeed, float pitchspeed, float yawspeed, float covariance[9]",
+	62:    "float nav_roll, float nav_pitch, float alt_error, float aspd_error, float xtrack_error, int16_t nav_bearing, int16_t target_bearing, uint16_t wp_dist",
+	63:    "uint64_t time_usec, int32_t lat, int32_t lon, int32_t alt, int32_t relative_alt, float vx, float vy, float vz, float covariance[36], uint8_t estimator_type",
+	64:    "uint64_t time_usec, float x, float y, float z, float vx, float vy, float vz, float ax, float ay, float az, float covariance[45], uint8_t estimator_type",
+	65:    "uint32_t time_boot_ms, uint16_t chan1_raw, uint16_t chan2_raw, uint16_t chan3_raw, uint16_t chan4_raw, uint16_t chan5_raw, uint16_t chan6_raw, uint16_t chan7_raw, uint16_t chan8_raw, uint16_t chan9_raw, uint16_t chan10_raw, uint16_t chan11_raw, uint16_t chan12_raw, uint16_t chan13_raw, uint16_t chan14_raw, uint16_t chan15_raw, uint16_t chan16_raw, uint16_t chan17_raw, uint16_t chan18_raw, uint8_t chancount, uint8_t rssi",
+	66:    "uint16_t req_message_rate, uint8_t target_system, uint8_t target_component, uint8_t req_stream_id, uint8_t start_stop",
+	67:    "uint16_t message_rate, uint8_t stream_id, uint8_t on_off",
+	69:    "int16_t x, int16_t y, int16_t z, int16_t r, uint16_t buttons, uint8_t target, uint16_t buttons2, uint8_t enabled_extensions, int16_t s, int16_t t, int16_t aux1, int16_t aux2, int16_t aux3, int16_t aux4, int16_t aux5, int16_t aux6",
+	70:    "uint16_t chan1_raw, uint16_t chan2_raw, uint16_t chan3_raw, uint16_t chan4_raw, uint16_t chan5_raw, uint16_t chan6_raw, uint16_t chan7_raw, uint16_t chan8_raw, uint8_t target_system, uint8_t target_component, uint16_t chan9_raw, uint16_t chan10_raw, uint16_t chan11_raw, uint16_t chan12_raw, uint16_t chan13_raw, uint16_t chan14_raw, uint16_t chan15_raw, uint16_t chan16_raw, uint16_t chan17_raw, uint16_t chan18_raw",
+	73:    "float param1, float param2, float param3, float param4, int32_t x, int32_t y, float z, uint16_t seq, uint16_t command, uint8_t target_system, uint8_t target_component, uint8_t frame, uint8_t current, uint8_t autocontinue, uint8_t mission_type",
+	74:    "float airspeed, float groundspeed, float alt, float climb, int16_t heading, uint16_t throttle",
+	75:    "float param1, float param2, float param3, float param4, int32_t x, int32_t y, float z, uint16_t command, uint8_t target_system, uint8_t target_component, uint8_t frame, uint8_t current, uint8_t autocontinue",
+	76:    "float param1, float param2, float param3, float param4, float param5, float param6, float param7, uint16_t command, uint8_t target_system, uint8_t target_component, uint8_t confirmation",
+	77:    "uint16_t command, uint8_t result, uint8_t progress, int32_t result_param2, uint8_t target_system, uint8_t target_component",
+	80:    "uint16_t command, uint8_t target_system, uint8_t target_component",
+	81:    "uint32_t time_boot_ms, float roll, float pitch, float yaw, float thrust, uint8_t mode_switch, uint8_t manual_override_switch",
+	82:    "uint32_t time_boot_ms, float q[4], float body_roll_rate, float body_pitch_rate, float body_yaw_rate, float thrust, uint8_t target_system, uint8_t target_component, uint8_t type_mask, float thrust_body[3]",
+	83:    "uint32_t time_boot_ms, float q[4], float body_roll_rate, float body_pitch_rate, float body_yaw_rate, float thrust, uint8_t type_mask",
+	84:    "uint32_t time_boot_ms, float x, float y, float z, float vx, float vy, float vz, float afx, float afy, float afz, float yaw, float yaw_rate, uint16_t type_mask, uint8_t target_system, uint8_t target_component, uint8_t coordinate_frame",
+	85:    "uint32_t time_boot_ms, float x, float y, float z, float vx, float vy, float vz, float afx, float afy, float afz, float yaw, float yaw_rate, uint16_t type_mask, uint8_t coordinate_frame",
+	86:    "uint32_t time_boot_ms, int32_t lat_int, int32_t lon_int, float alt, float vx, float vy, float vz, float afx, float afy, float afz, float yaw, float yaw_rate, uint16_t type_mask, uint8_t target_system, uint8_t target_component, uint8_t coordinate_frame",
+	87:    "uint32_t time_boot_ms, int32_t lat_int, int32_t lon_int, float alt, float vx, float vy, float vz, float afx, float afy, float afz, float yaw, float yaw_rate, uint16_t type_mask, uint8_t coordinate_frame",
+	89:    "uint32_t time_boot_ms, float x, float y, float z, float roll, float pitch, float yaw",
+	90:    "uint64_t time_usec, float roll, float pitch, float yaw, float rollspeed, float pitchspeed, float yawspeed, int32_t lat, int32_t lon, int32_t alt, int16_t vx, int16_t vy, int16_t vz, int16_t xacc, int16_t yacc, int16_t zacc",
+	91:    "uint64_t time_usec, float roll_ailerons, float pitch_elevator, float yaw_rudder, float throttle, float aux1, float aux2, float aux3, float aux4, uint8_t mode, uint8_t nav_mode",
+	92:    "uint64_t time_usec, uint16_t chan1_raw, uint16_t chan2_raw, uint16_t chan3_raw, uint16_t chan4_raw, uint16_t chan5_raw, uint16_t chan6_raw, uint16_t chan7_raw, uint16_t chan8_raw, uint16_t chan9_raw, uint16_t chan10_raw, uint16_t chan11_raw, uint16_t chan12_raw, uint8_t rssi",
+	93:    "uint64_t time_usec, uint64_t flags, float controls[16], uint8_t mode",
+	100:   "uint64_t time_usec, float flow_comp_m_x, float flow_comp_m_y, float ground_distance, int16_t flow_x, int16_t flow_y, uint8_t sensor_id, uint8_t quality, float flow_rate_x, float flow_rate_y",
+	101:   "uint64_t usec, float x, float y, float z, float roll, float pitch, float yaw, float covariance[21], uint8_t reset_counter",
+	102:   "uint64_t usec, float x, float y, float z, float roll, float pitch, float yaw, float covariance[21], uint8_t reset_counter",
+	103:   "uint64_t usec, float x, float y, float z, float covariance[9], uint8_t reset_counter",
+	104:   "uint64_t usec, float x, float y, float z, float roll, float pitch, float yaw, float covariance[21]",
+	105:   "uint64_t time_usec, float xacc, float yacc, float zacc, float xgyro, float ygyro, float zgyro, float xmag, float ymag, float zmag, float abs_pressure, float diff_pressure, float pressure_alt, float temperature, uint16_t fields_updated, uint8_t id",
+	106:   "uint64_t time_usec, uint32_t integration_time_us, float integrated_x, float integrated_y, float integrated_xgyro, float integrated_ygyro, float integrated_zgyro, uint32_t time_delta_distance_us, float distance, int16_t temperature, uint8_t sensor_id, uint8_t quality",
+	107:   "uint64_t time_usec, float xacc, float yacc, float zacc, float xgyro, float ygyro, float zgyro, float xmag, float ymag, float zmag, float abs_pressure, float diff_pressure, float pressure_alt, float temperature, uint32_t fields_updated, uint8_t id",
+	108:   "float q1, float q2, float q3, float q4, float roll, float pitch, float yaw, float xacc, float yacc, float zacc, float xgyro, float ygyro, float zgyro, float lat, float lon, float alt, float std_dev_horz, float std_dev_vert, float vn, float ve, float vd, int32_t lat_int, int32_t lon_int",
+	109:   "uint16_t rxerrors, uint16_t fixed, uint8_t rssi, uint8_t remrssi, uint8_t txbuf, uint8_t noise, uint8_t remnoise",
+	110:   "uint8_t target_network, uint8_t target_system, uint8_t target_component, uint8_t payload[251]",
+	111:   "int64_t tc1, int64_t ts1, uint8_t target_system, uint8_t target_component",
+	112:   "uint64_t time_usec, uint32_t seq",
+	113:   "uint64_t time_usec, int32_t lat, int32_t lon, int32_t alt, uint16_t eph, uint16_t epv, uint16_t vel, int16_t vn, int16_t ve, int16_t vd, uint16_t cog, uint8_t fix_type, uint8_t satellites_visible, uint8_t id, uint16_t yaw",
+	114:   "uint64_t time_usec, uint32_t integration_time_us, float integrated_x, float integrated_y, float integrated_xgyro, float integrated_ygyro, float integrated_zgyro, uint32_t time_delta_distance_us, float distance, int16_t temperature, uint8_t sensor_id, uint8_t quality",
+	115:   "uint64_t time_usec, float attitude_quaternion[4], float rollspeed, float pitchspeed, float yawspeed, int32_t lat, int32_t lon, int32_t alt, int16_t vx, int16_t vy, int16_t vz, uint16_t ind_airspeed, uint16_t true_airspeed, int16_t xacc, int16_t yacc, int16_t zacc",
+	116:   "uint32_t time_boot_ms, int16_t xacc, int16_t yacc, int16_t zacc, int16_t xgyro, int16_t ygyro, int16_t zgyro, int16_t xmag, int16_t ymag, int16_t zmag, int16_t temperature",
+	117:   "uint16_t start, uint16_t end, uint8_t target_system, uint8_t target_component",
+	118:   "uint32_t time_utc, uint32_t size, uint16_t id, uint16_t num_logs, uint16_t last_log_num",
+	119:   "uint32_t ofs, uint32_t count, uint16_t id, uint8_t target_system, uint8_t target_component",
+	120:   "uint32_t ofs, uint16_t id, uint8_t count, uint8_t data[90]",
+	121:   "uint8_t target_system, uint8_t target_component",
+	122:   "uint8_t target_system, uint8_t target_component",
+	123:   "uint8_t target_system, uint8_t target_component, uint8_t len, uint8_t data[110]",
+	124:   "uint64_t time_usec, int32_t lat, int32_t lon, int32_t alt, uint32_t dgps_age, uint16_t eph, uint16_t epv, uint16_t vel, uint16_t cog, uint8_t fix_type, uint8_t satellites_visible, uint8_t dgps_numch, uint16_t yaw, int32_t alt_ellipsoid, uint32_t h_acc, uint32_t v_acc, uint32_t vel_acc, uint32_t hdg_acc",
+	125:   "uint16_t Vcc, uint16_t Vservo, uint16_t flags",
+	126:   "uint32_t baudrate, uint16_t timeout, uint8_t device, uint8_t flags, uint8_t count, uint8_t data[70], uint8_t target_system, uint8_t target_component",
+	127:   "uint32_t time_last_baseline_ms, uint32_t tow, int32_t baseline_a_mm, int32_t baseline_b_mm, int32_t baseline_c_mm, uint32_t accuracy, int32_t iar_num_hypotheses, uint16_t wn, uint8_t rtk_receiver_id, uint8_t rtk_health, uint8_t rtk_rate, uint8_t nsats, uint8_t baseline_coords_type",
+	128:   "uint32_t time_last_baseline_ms, uint32_t tow, int32_t baseline_a_mm, int32_t baseline_b_mm, int32_t baseline_c_mm, uint32_t accuracy, int32_t iar_num_hypotheses, uint16_t wn, uint8_t rtk_receiver_id, uint8_t rtk_health, uint8_t rtk_rate, uint8_t nsats, uint8_t baseline_coords_type",
+	129:   "uint32_t time_boot_ms, int16_t xacc, int16_t yacc, int16_t zacc, int16_t xgyro, int16_t ygyro, int16_t zgyro, int16_t xmag, int16_t ymag, int16_t zmag, int16_t temperature",
+	130:   "uint32_t size, uint16_t width, uint16_t height, uint16_t packets, uint8_t type, uint8_t payload, uint8_t jpg_quality",
+	131:   "uint16_t seqnr, uint8_t data[253]",
+	132:   "uint32_t time_boot_ms, uint16_t min_distance, uint16_t max_distance, uint16_t current_distance, uint8_t type, uint8_t id, uint8_t orientation, uint8_t covariance, float horizontal_fov, float vertical_fov, float quaternion[4], uint8_t signal_quality",
+	133:   "uint64_t mask, int32_t lat, int32_t lon, uint16_t grid_spacing",
+	134:   "int32_t lat, int32_t lon, uint16_t grid_spacing, int16_t data[16], uint8_t gridbit",
+	135:   "int32_t lat, int32_t lon",
+	136:   "int32_t lat, int32_t lon, float terrain_height, float current_height, uint16_t spacing, uint16_t pending, uint16_t loaded",
+	137:   "uint32_t time_boot_ms, float press_abs, float press_diff, int16_t temperature, int16_t temperature_press_diff",
+	138:   "uint64_t time_usec, float q[4], float x, float y, float z, float covariance[21]",
+	139:   "uint64_t time_usec, float controls[8], uint8_t group_mlx, uint8_t target_system, uint8_t target_component",
+	140:   "uint64_t time_usec, float controls[8], uint8_t group_mlx",
+	141:   "uint64_t time_usec, float altitude_monotonic, float altitude_amsl, float altitude_local, float altitude_relative, float altitude_terrain, float bottom_clearance",
+	142:   "uint8_t request_id, uint8_t uri_type, uint8_t uri[120], uint8_t transfer_type, uint8_t storage[120]",
+	143:   "uint32_t time_boot_ms, float press_abs, float press_diff, int16_t temperature, int16_t temperature_press_diff",
+	144:   "uint64_t timestamp, uint64_t custom_state, int32_t lat, int32_t lon, float alt, float vel[3], float acc[3], float attitude_q[4], float rates[3], float position_cov[3], uint8_t est_capabilities",
+	146:   "uint64_t time_usec, float x_acc, float y_acc, float z_acc, float x_vel, float y_vel, float z_vel, float x_pos, float y_pos, float z_pos, float airspeed, float vel_variance[3], float pos_variance[3], float q[4], float roll_rate, float pitch_rate, float yaw_rate",
+	147:   "int32_t current_consumed, int32_t energy_consumed, int16_t temperature, uint16_t voltages[10], int16_t current_battery, uint8_t id, uint8_t battery_function, uint8_t type, int8_t battery_remaining, int32_t time_remaining, uint8_t charge_state, uint16_t voltages_ext[4], uint8_t mode, uint32_t fault_bitmask",
+	148:   "uint64_t capabilities, uint64_t uid, uint32_t flight_sw_version, uint32_t middleware_sw_version, uint32_t os_sw_version, uint32_t board_version, uint16_t vendor_id, uint16_t product_id, uint8_t flight_custom_version[8], uint8_t middleware_custom_version[8], uint8_t os_custom_version[8], uint8_t uid2[18]",
+	149:   "uint64_t time_usec, float angle_x, float angle_y, float distance, float size_x, float size_y, uint8_t target_num, uint8_t frame, float x, float y, float z, float q[4], uint8_t type, uint8_t position_valid",
+	150:   "float mag_declination, int32_t raw_press, int32_t raw_temp, float gyro_cal_x, float gyro_cal_y, float gyro_cal_z, float accel_cal_x, float accel_cal_y, float accel_cal_z, int16_t mag_ofs_x, int16_t mag_ofs_y, int16_t mag_ofs_z",
+	151:   "int16_t mag_ofs_x, int16_t mag_ofs_y, int16_t mag_ofs_z, uint8_t target_system, uint8_t target_component",
+	152:   "uint16_t brkval, uint16_t freemem, uint32_t freemem32",
+	153:   "uint16_t adc1, uint16_t adc2, uint16_t adc3, uint16_t adc4, uint16_t adc5, uint16_t adc6",
+	154:   "float extra_value, uint16_t shutter_speed, uint8_t target_system, uint8_t target_component, uint8_t mode, uint8_t aperture, uint8_t iso, uint8_t exposure_type, uint8_t command_id, uint8_t engine_cut_off, uint8_t extra_param",
+	155:   "float extra_value, uint8_t target_system, uint8_t target_component, uint8_t session, uint8_t zoom_pos, int8_t zoom_step, uint8_t focus_lock, uint8_t shot, uint8_t command_id, uint8_t extra_param",
+	156:   "uint8_t target_system, uint8_t target_component, uint8_t mount_mode, uint8_t stab_roll, uint8_t stab_pitch, uint8_t stab_yaw",
+	157:   "int32_t input_a, int32_t input_b, int32_t input_c, uint8_t target_system, uint8_t target_component, uint8_t save_position",
+	158:   "int32_t pointing_a, int32_t pointing_b, int32_t pointing_c, uint8_t target_system, uint8_t target_component, uint8_t mount_mode",
+	160:   "float lat, float lng, uint8_t target_system, uint8_t target_component, uint8_t idx, uint8_t count",
+	161:   "uint8_t target_system, uint8_t target_component, uint8_t idx",
+	162:   "uint32_t breach_time, uint16_t breach_count, uint8_t breach_status, uint8_t breach_type, uint8_t breach_mitigation",
+	163:   "float omegaIx, float omegaIy, float omegaIz, float accel_weight, float renorm_val, float error_rp, float error_yaw",
+	164:   "float roll, float pitch, float yaw, float xacc, float yacc, float zacc, float xgyro, float ygyro, float zgyro, int32_t lat, int32_t lng",
+	165:   "uint16_t Vcc, uint8_t I2Cerr",
+	166:   "uint16_t rxerrors, uint16_t fixed, uint8_t rssi, uint8_t remrssi, uint8_t txbuf, uint8_t noise, uint8_t remnoise",
+	167:   "uint32_t last_trigger, uint32_t last_action, uint32_t last_recovery, uint32_t last_clear, uint16_t breach_count, uint8_t limits_state, uint8_t mods_enabled, uint8_t mods_required, uint8_t mods_triggered",
+	168:   "float direction, float speed, float speed_z",
+	169:   "uint8_t type, uint8_t len, uint8_t data[16]",
+	170:   "uint8_t type, uint8_t len, uint8_t data[32]",
+	171:   "uint8_t type, uint8_t len, uint8_t data[64]",
+	172:   "uint8_t type, uint8_t len, uint8_t data[96]",
+	173:   "float distance, float voltage",
+	174:   "float vx, float vy, float vz, float diff_pressure, float EAS2TAS, float ratio, float state_x, float state_y, float state_z, float Pax, float Pby, float Pcz",
+	175:   "int32_t lat, int32_t lng, int16_t alt, int16_t break_alt, uint16_t land_dir, uint8_t target_system, uint8_t target_component, uint8_t idx, uint8_t count, uint8_t flags",
+	176:   "uint8_t target_system, uint8_t target_component, uint8_t idx",
+	177:   "float current, float CompensationX, float CompensationY, float CompensationZ, uint16_t throttle, uint16_t interference",
+	178:   "float roll, float pitch, float yaw, float altitude, int32_t lat, int32_t lng",
+	179:   "uint64_t time_usec, float p1, float p2, float p3, float p4, uint16_t img_idx, uint8_t target_system, uint8_t cam_idx, uint8_t event_id",
+	180:   "uint64_t time_usec, int32_t lat, int32_t lng, float alt_msl, float alt_rel, float roll, float pitch, float yaw, float foc_len, uint16_t img_idx, uint8_t target_system, uint8_t cam_idx, uint8_t flags, uint16_t completed_captures",
+	181:   "uint16_t voltage, int16_t current_battery",
+	182:   "float roll, float pitch, float yaw, float altitude, int32_t lat, int32_t lng, float v1, float v2, float v3, float v4",
+	183:   "uint8_t target_system, uint8_t target_component",
+	184:   "uint32_t seqno, uint8_t target_system, uint8_t target_component, uint8_t data[200]",
+	185:   "uint32_t seqno, uint8_t target_system, uint8_t target_component, uint8_t status",
+	186:   "uint8_t target_system, uint8_t target_component, uint8_t instance, uint8_t pattern, uint8_t custom_len, uint8_t custom_bytes[24]",
+	191:   "float direction_x, float direction_y, float direction_z, uint8_t compass_id, uint8_t cal_mask, uint8_t cal_status, uint8_t attempt, uint8_t completion_pct, uint8_t completion_mask[10]",
+	192:   "float fitness, float ofs_x, float ofs_y, float ofs_z, float diag_x, float diag_y, float diag_z, float offdiag_x, float offdiag_y, float offdiag_z, uint8_t compass_id, uint8_t cal_mask, uint8_t cal_status, uint8_t autosaved, float orientation_confidence, uint8_t old_orientation, uint8_t new_orientation, float scale_factor",
+	193:   "float velocity_variance, float pos_horiz_variance, float pos_vert_variance, float compass_variance, float terrain_alt_variance, uint16_t flags, float airspeed_variance",
+	194:   "float desired, float achieved, float FF, float P, float I, float D, uint8_t axis, float SRate, float PDmod",
+	195:   "int32_t landing_lat, int32_t landing_lon, int32_t path_lat, int32_t path_lon, int32_t arc_entry_lat, int32_t arc_entry_lon, float altitude, float expected_travel_distance, float cross_track_error, uint8_t stage",
+	200:   "float delta_time, float delta_angle_x, float delta_angle_y, float delta_angle_z, float delta_velocity_x, float delta_velocity_y, float delta_velocity_z, float joint_roll, float joint_el, float joint_az, uint8_t target_system, uint8_t target_component",
+	201:   "float demanded_rate_x, float demanded_rate_y, float demanded_rate_z, uint8_t target_system, uint8_t target_component",
+	214:   "int16_t rl_torque_cmd, int16_t el_torque_cmd, int16_t az_torque_cmd, uint8_t target_system, uint8_t target_component",
+	215:   "uint8_t status, uint8_t capture_mode, uint8_t flags",
+	216:   "uint8_t target_system, uint8_t target_component, uint8_t cmd_id",
+	217:   "uint8_t cmd_id, uint8_t status, uint8_t value[4]",
+	218:   "uint8_t target_system, uint8_t target_component, uint8_t cmd_id, uint8_t value[4]",
+	219:   "uint8_t cmd_id, uint8_t status",
+	225:   "float ecu_index, float rpm, float fuel_consumed, float fuel_flow, float engine_load, float throttle_position, float spark_dwell_time, float barometric_pressure, float intake_manifold_pressure, float intake_manifold_temperature, float cylinder_head_temperature, float ignition_timing, float injection_time, float exhaust_gas_temperature, float throttle_out, float pt_compensation, uint8_t health, float ignition_voltage, float fuel_pressure",
+	226:   "float rpm1, float rpm2",
+	230:   "uint64_t time_usec, float vel_ratio, float pos_horiz_ratio, float pos_vert_ratio, float mag_ratio, float hagl_ratio, float tas_ratio, float pos_horiz_accuracy, float pos_vert_accuracy, uint16_t flags",
+	231:   "uint64_t time_usec, float wind_x, float wind_y, float wind_z, float var_horiz, float var_vert, float wind_alt, float horiz_accuracy, float vert_accuracy",
+	232:   "uint64_t time_usec, uint32_t time_week_ms, int32_t lat, int32_t lon, float alt, float hdop, float vdop, float vn, float ve, float vd, float speed_accuracy, float horiz_accuracy, float vert_accuracy, uint16_t ignore_flags, uint16_t time_week, uint8_t gps_id, uint8_t fix_type, uint8_t satellites_visible, uint16_t yaw",
+	233:   "uint8_t flags, uint8_t len, uint8_t data[180]",
+	234:   "uint32_t custom_mode, int32_t latitude, int32_t longitude, int16_t roll, int16_t pitch, uint16_t heading, int16_t heading_sp, int16_t altitude_amsl, int16_t altitude_sp, uint16_t wp_distance, uint8_t base_mode, uint8_t landed_state, int8_t throttle, uint8_t airspeed, uint8_t airspeed_sp, uint8_t groundspeed, int8_t climb_rate, uint8_t gps_nsat, uint8_t gps_fix_type, uint8_t battery_remaining, int8_t temperature, int8_t temperature_air, uint8_t failsafe, uint8_t wp_num",
+	235:   "uint32_t timestamp, int32_t latitude, int32_t longitude, uint16_t custom_mode, int16_t altitude, int16_t target_altitude, uint16_t target_distance, uint16_t wp_num, uint16_t failure_flags, uint8_t type, uint8_t autopilot, uint8_t heading, uint8_t target_heading, uint8_t throttle, uint8_t airspeed, uint8_t airspeed_sp, uint8_t groundspeed, uint8_t windspeed, uint8_t wind_heading, uint8_t eph, uint8_t epv, int8_t temperature_air, int8_t climb_rate, int8_t battery, int8_t custom0, int8_t custom1, int8_t custom2",
+	241:   "uint64_t time_usec, float vibration_x, float vibration_y, float vibration_z, uint32_t clipping_0, uint32_t clipping_1, uint32_t clipping_2",
+	242:   "int32_t latitude, int32_t longitude, int32_t altitude, float x, float y, float z, float q[4], float approach_x, float approach_y, float approach_z, uint64_t time_usec",
+	243:   "int32_t latitude, int32_t longitude, int32_t altitude, float x, float y, float z, float q[4], float approach_x, float approach_y, float approach_z, uint8_t target_system, uint64_t time_usec",
+	244:   "int32_t interval_us, uint16_t message_id",
+	245:   "uint8_t vtol_state, uint8_t landed_state",
+	246:   "uint32_t ICAO_address, int32_t lat, int32_t lon, int32_t altitude, uint16_t heading, uint16_t hor_velocity, int16_t ver_velocity, uint16_t flags, uint16_t squawk, uint8_t altitude_type, char callsign[9], uint8_t emitter_type, uint8_t tslc",
+	247:   "uint32_t id, float time_to_minimum_delta, float altitude_minimum_delta, float horizontal_minimum_delta, uint8_t src, uint8_t action, uint8_t threat_level",
+	248:   "uint16_t message_type, uint8_t target_network, uint8_t target_system, uint8_t target_component, uint8_t payload[249]",
+	249:   "uint16_t address, uint8_t ver, uint8_t type, int8_t value[32]",
+	250:   "uint64_t time_usec, float x, float y, float z, char name[10]",
+	251:   "uint32_t time_boot_ms, float value, char name[10]",
+	252:   "uint32_t time_boot_ms, int32_t value, char name[10]",
+	253:   "uint8_t severity, char text[50], uint16_t id, uint8_t chunk_seq",
+	254:   "uint32_t time_boot_ms, float value, uint8_t ind",
+	256:   "uint64_t initial_timestamp, uint8_t target_system, uint8_t target_component, uint8_t secret_key[32]",
+	257:   "uint32_t time_boot_ms, uint32_t last_change_ms, uint8_t state",
+	258:   "uint8_t target_system, uint8_t target_component, char tune[30], char tune2[200]",
+	259:   "uint32_t time_boot_ms, uint32_t firmware_version, float focal_length, float sensor_size_h, float sensor_size_v, uint32_t flags, uint16_t resolution_h, uint16_t resolution_v, uint16_t cam_definition_version, uint8_t vendor_name[32], uint8_t model_name[32], uint8_t lens_id, char cam_definition_uri[140], uint8_t gimbal_device_id, uint8_t camera_device_id",
+	260:   "uint32_t time_boot_ms, uint8_t mode_id, float zoomLevel, float focusLevel, uint8_t camera_device_id",
+	261:   "uint32_t time_boot_ms, float total_capacity, float used_capacity, float available_capacity, float read_speed, float write_speed, uint8_t storage_id, uint8_t storage_count, uint8_t status, uint8_t type, char name[32], uint8_t storage_usage",
+	262:   "uint32_t time_boot_ms, float image_interval, uint32_t recording_time_ms, float available_capacity, uint8_t image_status, uint8_t video_status, int32_t image_count, uint8_t camera_device_id",
+	263:   "uint64_t time_utc, uint32_t time_boot_ms, int32_t lat, int32_t lon, int32_t alt, int32_t relative_alt, float q[4], int32_t image_index, uint8_t camera_id, int8_t capture_result, char file_url[205]",
+	264:   "uint64_t arming_time_utc, uint64_t takeoff_time_utc, uint64_t flight_uuid, uint32_t time_boot_ms, uint32_t landing_time",
+	265:   "uint32_t time_boot_ms, float roll, float pitch, float yaw, float yaw_absolute",
+	266:   "uint16_t sequence, uint8_t target_system, uint8_t target_component, uint8_t length, uint8_t first_message_offset, uint8_t data[249]",
+	267:   "uint16_t sequence, uint8_t target_system, uint8_t target_component, uint8_t length, uint8_t first_message_offset, uint8_t data[249]",
+	268:   "uint16_t sequence, uint8_t target_system, uint8_t target_component",
+	269:   "float framerate, uint32_t bitrate, uint16_t flags, uint16_t resolution_h, uint16_t resolution_v, uint16_t rotation, uint16_t hfov, uint8_t stream_id, uint8_t count, uint8_t type, char name[32], char uri[160], uint8_t encoding, uint8_t camera_device_id",
+	270:   "float framerate, uint32_t bitrate, uint16_t flags, uint16_t resolution_h, uint16_t resolution_v, uint16_t rotation, uint16_t hfov, uint8_t stream_id, uint8_t camera_device_id",
+	271:   "uint32_t time_boot_ms, int32_t lat_camera, int32_t lon_camera, int32_t alt_camera, int32_t lat_image, int32_t lon_image, int32_t alt_image, float q[4], float hfov, float vfov, uint8_t camera_device_id",
+	275:   "float point_x, float point_y, float radius, float rec_top_x, float rec_top_y, float rec_bottom_x, float rec_bottom_y, uint8_t tracking_status, uint8_t tracking_mode, uint8_t target_data, uint8_t camera_device_id",
+	276:   "int32_t lat, int32_t lon, float alt, float h_acc, float v_acc, float vel_n, float vel_e, float vel_d, float vel_acc, float dist, float hdg, float hdg_acc, uint8_t tracking_status, uint8_t camera_device_id",
+	277:   "uint32_t time_boot_ms, float max, float max_point_x, float max_point_y, float min, float min_point_x, float min_point_y, uint8_t stream_id, uint8_t camera_device_id",
+	280:   "uint32_t time_boot_ms, uint32_t cap_flags, float roll_min, float roll_max, float pitch_min, float pitch_max, float yaw_min, float yaw_max, uint8_t gimbal_device_id",
+	281:   "uint32_t time_boot_ms, uint32_t flags, uint8_t gimbal_device_id, uint8_t primary_control_sysid, uint8_t primary_control_compid, uint8_t secondary_control_sysid, uint8_t secondary_control_compid",
+	282:   "uint32_t flags, float q[4], float angular_velocity_x, float angular_velocity_y, float angular_velocity_z, uint8_t target_system, uint8_t target_component, uint8_t gimbal_device_id",
+	283:   "uint64_t uid, uint32_t time_boot_ms, uint32_t firmware_version, uint32_t hardware_version, float roll_min, float roll_max, float pitch_min, float pitch_max, float yaw_min, float yaw_max, uint16_t cap_flags, uint16_t custom_cap_flags, char vendor_name[32], char model_name[32], char custom_name[32], uint8_t gimbal_device_id, uint32_t cap_flags2",
+	284:   "float q[4], float angular_velocity_x, float angular_velocity_y, float angular_velocity_z, uint16_t flags, uint8_t target_system, uint8_t target_component",
+	285:   "uint32_t time_boot_ms, float q[4], float angular_velocity_x, float angular_velocity_y, float angular_velocity_z, uint32_t failure_flags, uint16_t flags, uint8_t target_system, uint8_t target_component, float delta_yaw, float delta_yaw_velocity, uint8_t gimbal_device_id",
+	286:   "uint64_t time_boot_us, float q[4], uint32_t q_estimated_delay_us, float vx, float vy, float vz, uint32_t v_estimated_delay_us, float feed_forward_angular_velocity_z, uint16_t estimator_status, uint8_t target_system, uint8_t target_component, uint8_t landed_state, float angular_velocity_z",
+	287:   "uint32_t flags, float pitch, float yaw, float pitch_rate, float yaw_rate, uint8_t target_system, uint8_t target_component, uint8_t gimbal_device_id",
+	288:   "uint32_t flags, float pitch, float yaw, float pitch_rate, float yaw_rate, uint8_t target_system, uint8_t target_component, uint8_t gimbal_device_id",
+	290:   "uint64_t time_usec, uint32_t error_count[4], uint16_t counter, uint16_t failure_flags[4], int16_t temperature[4], uint8_t index, uint8_t count, uint8_t connection_type, uint8_t info",
+	291:   "uint64_t time_usec, int32_t rpm[4], float voltage[4], float current[4], uint8_t index",
+	295:   "float airspeed, float raw_press, int16_t temperature, uint8_t id, uint8_t flags",
+	296:   "uint64_t time_usec, uint32_t processing_time, int32_t lat, int32_t lon, float alt_ellipsoid, float alt, float eph, float epv, uint8_t target_system, uint8_t target_component, uint8_t id, uint8_t source, uint8_t flags",
+	299:   "char ssid[32], char password[64], int8_t mode, int8_t response",
+	300:   "uint16_t version, uint16_t min_version, uint16_t max_version, uint8_t spec_version_hash[8], uint8_t library_version_hash[8]",
+	301:   "uint32_t MMSI, int32_t lat, int32_t lon, uint16_t COG, uint16_t heading, uint16_t velocity, uint16_t dimension_bow, uint16_t dimension_stern, uint16_t tslc, uint16_t flags, int8_t turn_rate, uint8_t navigational_status, uint8_t type, uint8_t dimension_port, uint8_t dimension_starboard, char callsign[7], char name[20]",
+	310:   "uint64_t time_usec, uint32_t uptime_sec, uint16_t vendor_specific_status_code, uint8_t health, uint8_t mode, uint8_t sub_mode",
+	311:   "uint64_t time_usec, uint32_t uptime_sec, uint32_t sw_vcs_commit, char name[80], uint8_t hw_version_major, uint8_t hw_version_minor, uint8_t hw_unique_id[16], uint8_t sw_version_major, uint8_t sw_version_minor",
+	320:   "int16_t param_index, uint8_t target_system, uint8_t target_component, char param_id[16]",
+	321:   "uint8_t target_system, uint8_t target_component",
+	322:   "uint16_t param_count, uint16_t param_index, char param_id[16], char param_value[128], uint8_t param_type",
+	323:   "uint8_t target_system, uint8_t target_component, char param_id[16], char param_value[128], uint8_t param_type",
+	324:   "char param_id[16], char param_value[128], uint8_t param_type, uint8_t param_result",
+	330:   "uint64_t time_usec, uint16_t distances[72], uint16_t min_distance, uint16_t max_distance, uint8_t sensor_type, uint8_t increment, float increment_f, float angle_offset, uint8_t frame",
+	331:   "uint64_t time_usec, float x, float y, float z, float q[4], float vx, float vy, float vz, float rollspeed, float pitchspeed, float yawspeed, float pose_covariance[21], float velocity_covariance[21], uint8_t frame_id, uint8_t child_frame_id, uint8_t reset_counter, uint8_t estimator_type, int8_t quality",
+	332:   "uint64_t time_usec, float pos_x[5], float pos_y[5], float pos_z[5], float vel_x[5], float vel_y[5], float vel_z[5], float acc_x[5], float acc_y[5], float acc_z[5], float pos_yaw[5], float vel_yaw[5], uint16_t command[5], uint8_t valid_points",
+	333:   "uint64_t time_usec, float pos_x[5], float pos_y[5], float pos_z[5], float delta[5], float pos_yaw[5], uint8_t valid_points",
+	334:   "uint16_t mcc, uint16_t mnc, uint16_t lac, uint8_t status, uint8_t failure_reason, uint8_t type, uint8_t quality, uint8_t id, uint32_t link_tx_rate, uint32_t link_rx_rate, char cell_tower_id[9], uint8_t band_number, float band_frequency, uint32_t channel_number, float rx_level, float tx_level, float rx_quality, float sinr",
+	335:   "uint64_t timestamp, uint64_t last_heartbeat, uint16_t failed_sessions, uint16_t successful_sessions, uint8_t signal_quality, uint8_t ring_pending, uint8_t tx_session_pending, uint8_t rx_session_pending",
+	336:   "uint8_t enable_lte, uint8_t enable_pin, char pin[16], char new_pin[16], char apn[32], char puk[16], uint8_t roaming, uint8_t response",
+	339:   "float frequency, uint8_t index",
+	340:   "uint64_t time, int32_t lat, int32_t lon, int32_t alt, int32_t relative_alt, int32_t next_lat, int32_t next_lon, int32_t next_alt, int16_t vx, int16_t vy, int16_t vz, uint16_t h_acc, uint16_t v_acc, uint16_t vel_acc, uint16_t update_rate, uint8_t uas_id[18], uint8_t flight_state, uint8_t flags",
+	345:   "int16_t param_index, uint8_t target_system, uint8_t target_component, char param_id[16], uint8_t error",
+	350:   "uint64_t time_usec, uint16_t array_id, char name[10], float data[58]",
+	360:   "uint64_t time_usec, float radius, int32_t x, int32_t y, float z, uint8_t frame",
+	361:   "uint64_t time_usec, float major_radius, float minor_radius, float orientation, int32_t x, int32_t y, float z, uint8_t frame",
+	370:   "int32_t capacity_full_specification, int32_t capacity_full, uint16_t cycle_count, uint16_t weight, uint16_t discharge_minimum_voltage, uint16_t charging_minimum_voltage, uint16_t resting_minimum_voltage, uint8_t id, uint8_t battery_function, uint8_t type, char serial_number[16], char device_name[50], uint16_t charging_maximum_voltage, uint8_t cells_in_series, uint32_t discharge_maximum_current, uint32_t discharge_maximum_burst_current, char manufacture_date[11]",
+	371:   "float maximum_fuel, float consumed_fuel, float remaining_fuel, float flow_rate, float temperature, uint32_t fuel_type, uint8_t id, uint8_t percent_remaining",
+	372:   "float discharge_minimum_voltage, float charging_minimum_voltage, float resting_minimum_voltage, float charging_maximum_voltage, float charging_maximum_current, float nominal_voltage, float discharge_maximum_current, float discharge_maximum_burst_current, float design_capacity, float full_charge_capacity, uint16_t cycle_count, uint16_t weight, uint8_t id, uint8_t battery_function, uint8_t type, uint8_t state_of_health, uint8_t cells_in_series, char manufacture_date[9], char serial_number[32], char name[50]",
+	373:   "uint64_t status, float battery_current, float load_current, float power_generated, float bus_voltage, float bat_current_setpoint, uint32_t runtime, int32_t time_until_maintenance, uint16_t generator_speed, int16_t rectifier_temperature, int16_t generator_temperature",
+	375:   "uint64_t time_usec, uint32_t active, float actuator[32]",
+	376:   "uint32_t time_boot_ms, uint16_t on, uint16_t present",
+	380:   "int32_t safe_return, int32_t land, int32_t mission_next_item, int32_t mission_end, int32_t commanded_action",
+	385:   "uint16_t payload_type, uint8_t target_system, uint8_t target_component, uint8_t payload_length, uint8_t payload[128]",
+	386:   "uint32_t id, uint8_t target_system, uint8_t target_component, uint8_t bus, uint8_t len, uint8_t data[8]",
+	387:   "uint32_t id, uint8_t target_system, uint8_t target_component, uint8_t bus, uint8_t len, uint8_t data[64]",
+	388:   "uint16_t ids[16], uint8_t target_system, uint8_t target_component, uint8_t bus, uint8_t operation, uint8_t num_ids",
+	390:   "uint64_t time_usec, uint32_t uptime, uint32_t ram_usage, uint32_t ram_total, uint32_t storage_type[4], uint32_t storage_usage[4], uint32_t storage_total[4], uint32_t link_type[6], uint32_t link_tx_rate[6], uint32_t link_rx_rate[6], uint32_t link_tx_max[6], uint32_t link_rx_max[6], int16_t fan_speed[4], uint8_t type, uint8_t cpu_cores[8], uint8_t cpu_combined[10], uint8_t gpu_cores[4], uint8_t gpu_combined[10], int8_t temperature_board, int8_t temperature_core[8], uint16_t status_flags",
+	395:   "uint32_t time_boot_ms, uint32_t general_metadata_file_crc, uint32_t peripherals_metadata_file_crc, char general_metadata_uri[100], char peripherals_metadata_uri[100]",
+	396:   "uint64_t capabilities, uint32_t time_boot_ms, uint32_t time_manufacture_s, char vendor_name[32], char model_name[32], char software_version[24], char hardware_version[24], char serial_number[32]",
+	397:   "uint32_t time_boot_ms, uint32_t file_crc, char uri[100]",
+	400:   "uint32_t format, uint8_t target_system, uint8_t target_component, char tune[248]",
+	401:   "uint32_t format, uint8_t target_system, uint8_t target_component",
+	410:   "uint32_t id, uint32_t event_time_boot_ms, uint16_t sequence, uint8_t destination_component, uint8_t destination_system, uint8_t log_levels, uint8_t arguments[40]",
+	411:   "uint16_t sequence, uint8_t flags",
+	412:   "uint16_t first_sequence, uint16_t last_sequence, uint8_t target_system, uint8_t target_component",
+	413:   "uint16_t sequence, uint16_t sequence_oldest_available, uint8_t target_system, uint8_t target_component, uint8_t reason",
+	435:   "uint32_t custom_mode, uint32_t properties, uint8_t number_modes, uint8_t mode_index, uint8_t standard_mode, char mode_name[35]",
+	436:   "uint32_t custom_mode, uint32_t intended_custom_mode, uint8_t standard_mode",
+	437:   "uint8_t seq",
+	440:   "uint32_t uptime_ms, uint32_t error_status, float brightness, float strobe_period, float strobe_duty_cycle, float temp_c, float min_strobe_period, float max_strobe_period, uint8_t enable, uint8_t mode_bitmask, uint8_t mode",
+	9000:  "uint64_t time_usec, double distance[16], uint8_t count",
+	9005:  "uint64_t time_usec, float line_length, float speed, float tension, float voltage, float current, uint32_t status, int16_t temperature",
+	10001: "uint32_t ICAO, uint16_t stallSpeed, char callsign[9], uint8_t emitterType, uint8_t aircraftSize, uint8_t gpsOffsetLat, uint8_t gpsOffsetLon, uint8_t rfSelect",
+	10002: "uint32_t utcTime, int32_t gpsLat, int32_t gpsLon, int32_t gpsAlt, int32_t baroAltMSL, uint32_t accuracyHor, uint16_t accuracyVert, uint16_t accuracyVel, int16_t velVert, int16_t velNS, int16_t VelEW, uint16_t state, uint16_t squawk, uint8_t gpsFix, uint8_t numSats, uint8_t emergencyStatus",
+	10003: "uint8_t rfHealth",
+	10004: "char registration[9]",
+	10005: "char flight_id[9]",
+	10006: "uint32_t ReqMessageId",
+	10007: "int32_t baroAltMSL, uint16_t squawk, uint8_t state, uint8_t emergencyStatus, char flight_id[8], uint8_t x_bit",
+	10008: "uint16_t squawk, uint8_t state, uint8_t NIC_NACp, uint8_t boardTemp, uint8_t fault, char flight_id[8]",
+	10151: "float volt_batt, float curr_batt, float curr_gen, float curr_rot, float fuel_level, float throttle, uint32_t runtime, int32_t until_maintenance, float rectifier_temp, float generator_temp, float efi_batt, float efi_rpm, float efi_pw, float efi_fuel_flow, float efi_fuel_consumed, float efi_baro, float efi_mat, float efi_clt, float efi_tps, float efi_exhaust_gas_temperature, uint16_t generator_status, uint16_t efi_status, uint8_t efi_index",
+	11000: "uint32_t request_id, uint8_t target_system, uint8_t target_component, uint8_t bustype, uint8_t bus, uint8_t address, char busname[40], uint8_t regstart, uint8_t count, uint8_t bank",
+	11001: "uint32_t request_id, uint8_t result, uint8_t regstart, uint8_t count, uint8_t data[128], uint8_t bank",
+	11002: "uint32_t request_id, uint8_t target_system, uint8_t target_component, uint8_t bustype, uint8_t bus, uint8_t address, char busname[40], uint8_t regstart, uint8_t count, uint8_t data[128], uint8_t bank",
+	11003: "uint32_t request_id, uint8_t result",
+	11004: "uint32_t sequence, uint32_t operation, uint8_t target_system, uint8_t target_component, uint8_t data_length, uint8_t sig_length, uint8_t data[220]",
+	11005: "uint32_t sequence, uint32_t operation, uint8_t result, uint8_t data_length, uint8_t data[220]",
+	11010: "float desired, float achieved, float error, float theta, float omega, float sigma, float theta_dot, float omega_dot, float sigma_dot, float f, float f_dot, float u, uint8_t axis",
+	11011: "uint64_t time_usec, uint64_t time_delta_usec, float angle_delta[3], float position_delta[3], float confidence",
+	11020: "uint64_t time_usec, float AOA, float SSA",
+	11030: "uint16_t voltage[4], uint16_t current[4], uint16_t totalcurrent[4], uint16_t rpm[4], uint16_t count[4], uint8_t temperature[4]",
+	11031: "uint16_t voltage[4], uint16_t current[4], uint16_t totalcurrent[4], uint16_t rpm[4], uint16_t count[4], uint8_t temperature[4]",
+	11032: "uint16_t voltage[4], uint16_t current[4], uint16_t totalcurrent[4], uint16_t rpm[4], uint16_t count[4], uint8_t temperature[4]",
+	11033: "uint32_t request_id, float min_value, float max_value, float increment, uint8_t target_system, uint8_t target_component, uint8_t osd_screen, uint8_t osd_index, char param_id[16], uint8_t config_type",
+	11034: "uint32_t request_id, uint8_t result",
+	11035: "uint32_t request_id, uint8_t target_system, uint8_t target_component, uint8_t osd_screen, uint8_t osd_index",
+	11036: "uint32_t request_id, float min_value, float max_value, float increment, uint8_t result, char param_id[16], uint8_t config_type",
+	11037: "uint32_t time_boot_ms, float x, float y, float z, float min_distance, float max_distance, uint16_t obstacle_id, uint8_t sensor_type, uint8_t frame",
+	11038: "uint32_t time_boot_ms, int32_t lat, int32_t lng, float alt, float roll, float pitch, float yaw, float distance, float temperature, uint8_t id, uint8_t healthy",
+	11039: "int16_t MCU_temperature, uint16_t MCU_voltage, uint16_t MCU_voltage_min, uint16_t MCU_voltage_max, uint8_t id",
+	11040: "uint16_t voltage[4], uint16_t current[4], uint16_t totalcurrent[4], uint16_t rpm[4], uint16_t count[4], uint8_t temperature[4]",
+	11041: "uint16_t voltage[4], uint16_t current[4], uint16_t totalcurrent[4], uint16_t rpm[4], uint16_t count[4], uint8_t temperature[4]",
+	11042: "uint16_t voltage[4], uint16_t current[4], uint16_t totalcurrent[4], uint16_t rpm[4], uint16_t count[4], uint8_t temperature[4]",
+	11043: "uint16_t voltage[4], uint16_t current[4], uint16_t totalcurrent[4], uint16_t rpm[4], uint16_t count[4], uint8_t temperature[4]",
+	11044: "uint16_t voltage[4], uint16_t current[4], uint16_t totalcurrent[4], uint16_t rpm[4], uint16_t count[4], uint8_t temperature[4]",
+	11060: "uint32_t time_boot_ms, char name[10], char value[64]",
+	12900: "uint8_t target_system, uint8_t target_component, uint8_t id_or_mac[20], uint8_t id_type, uint8_t ua_type, uint8_t uas_id[20]",
+	12901: "int32_t latitude, int32_t longitude, float altitude_barometric, float altitude_geodetic, float height, float timestamp, uint16_t direction, uint16_t speed_horizontal, int16_t speed_vertical, uint8_t target_system, uint8_t target_component, uint8_t id_or_mac[20], uint8_t status, uint8_t height_reference, uint8_t horizontal_accuracy, uint8_t vertical_accuracy, uint8_t barometer_accuracy, uint8_t speed_accuracy, uint8_t timestamp_accuracy",
+	12902: "uint32_t timestamp, uint8_t target_system, uint8_t target_component, uint8_t id_or_mac[20], uint8_t authentication_type, uint8_t data_page, uint8_t last_page_index, uint8_t length, uint8_t authentication_data[23]",
+	12903: "uint8_t target_system, uint8_t target_component, uint8_t id_or_mac[20], uint8_t description_type, char description[23]",
+	12904: "int32_t operator_latitude, int32_t operator_longitude, float area_ceiling, float area_floor, float operator_altitude_geo, uint32_t timestamp, uint16_t area_count, uint16_t area_radius, uint8_t target_system, uint8_t target_component, uint8_t id_or_mac[20], uint8_t operator_location_type, uint8_t classification_type, uint8_t category_eu, uint8_t class_eu",
+	12905: "uint8_t target_system, uint8_t target_component, uint8_t id_or_mac[20], uint8_t operator_id_type, char operator_id[20]",
+	12915: "uint8_t target_system, uint8_t target_component, uint8_t id_or_mac[20], uint8_t single_message_size, uint8_t msg_pack_size, uint8_t messages[225]",
+	12918: "uint8_t status, char error[50]",
+	12919: "int32_t operator_latitude, int32_t operator_longitude, float operator_altitude_geo, uint32_t timestamp, uint8_t target_system, uint8_t target_component",
+	12920: "int16_t temperature, uint16_t humidity, uint8_t id",
+	42000: "uint8_t status",
+	42001: "float min1, float max1, float min2, float max2, float min3, float max3, float min4, float max4, float min5, float max5, int8_t numBands, uint8_t type1, uint8_t type2, uint8_t type3, uint8_t type4, uint8_t type5",
+	50001: "uint8_t rc_raw[32]",
+	50002: "float framerate, uint32_t bitrate, uint16_t resolution_h, uint16_t resolution_v, uint16_t rotation, uint8_t camera_id, uint8_t status, char uri[230]",
+	50003: "uint32_t rf_freq, uint32_t link_bw, uint32_t link_rate, int16_t snr, int16_t cpu_temp, int16_t board_temp, uint8_t rssi",
+	50004: "uint32_t size, uint32_t crc, uint8_t target_system, uint8_t target_component",
+	50005: "uint32_t offset, uint8_t target_system, uint8_t target_component",
+	52000: "char login[50], char password[50]",
+	52001: "uint8_t resp_type",
+}
+
+// typeSizes is the wire size of each MAVLink field type
+var typeSizes = map[string]int{
+	"double": 8, "uint64_t": 8, "int64_t": 8,
+	"float": 4, "uint32_t": 4, "int32_t": 4,
+	"uint16_t": 2, "int16_t": 2,
+	"uint8_t": 1, "int8_t": 1, "char": 1,
+}
+
+// Field is a payload field of a dialect message
+type Field struct {
+	Name   string
+	Type   string // MAVLink type, e.g. "uint16_t", "float" or "char"
+	Len    int    // Array length, 0 for a single value
+	Offset int    // Byte offset in the payload
+}
+
+// MessageFields returns the payload fields of a message in wire order
+func MessageFields(id uint32) ([]Field, bool) {
+	spec, ok := messageFields[id]
+	if !ok {
+		return nil, false
+	}
+
+	var fields []Field
+	offset := 0
+	for _, decl := range strings.Split(spec, ", ") {
+		typ, name, _ := strings.Cut(decl, " ")
+		f := Field{Name: name, Type: typ, Offset: offset}
+		if base, length, ok := strings.Cut(name, "["); ok {
+			f.Name = base
+			f.Len, _ = strconv.Atoi(strings.TrimSuffix(length, "]"))
+		}
+		fields = append(fields, f)
+		offset += f.Size()
+	}
+	return fields, true
+}
+
+// Size returns the number of payload bytes the field takes
+func (f Field) Size() int {
+	return typeSizes[f.Type] * max(f.Len, 1)
+}
+
+// Columns names the field's values as returned by Values: the field name,
+// or name[i] per element of a numeric array
+func (f Field) Columns() []string {
+	if f.Len == 0 || f.Type == "char" {
+		return []string{f.Name}
+	}
+	columns := make([]string, f.Len)
+	for i := range columns {
+		columns[i] = f.Name + "[" + strconv.Itoa(i) + "]"
+	}
+	return columns
+}
+
+// Values formats the field's value from a payload, one per column. Char
+// arrays are a single string. Bytes removed by MAVLink 2 trailing-zero
+// truncation, and extensions missing from MAVLink 1 frames, read as zero.
+func (f Field) Values(payload []byte) []string {
+	if f.Type == "char" {
+		var b strings.Builder
+		for i := 0; i < max(f.Len, 1); i++ {
+			c := payloadByte(payload, f.Offset+i)
+			if c == 0 {
+				break
+			}
+			b.WriteByte(c)
+		}
+		return []string{b.String()}
+	}
+
+	size := typeSizes[f.Type]
+	values := make([]string, max(f.Len, 1))
+	for i := range values {
+		values[i] = formatValue(f.Type, payloadUint64(payload, f.Offset+i*size, size))
+	}
+	return values
+}
+
+// payloadUint64 reads a little-endian value of size bytes from a possibly
+// truncated payload
+func payloadUint64(payload []byte, offset, size int) uint64 {
+	var b [8]byte
+	for i := 0; i < size; i++ {
+		b[i] = payloadByte(payload, offset+i)
+	}
+	return binary.LittleEndian.Uint64(b[:])
+}
+
+// formatValue formats the raw bits of a value of a MAVLink type
+func formatValue(typ string, bits uint64) string {
+	switch typ {
+	case "double":
+		return strconv.FormatFloat(math.Float64frombits(bits), 'g', -1, 64)
+	case "float":
+		return strconv.FormatFloat(float64(math.Float32frombits(uint32(bits))), 'g', -1, 32)
+	case "int64_t":
+		return strconv.FormatInt(int64(bits), 10)
+	case "int32_t":
+		return strconv.FormatInt(int64(int32(bits)), 10)
+	case "int16_t":
+		return strconv.FormatInt(int64(int16(bits)), 10)
+	case "int8_t":
+		return strconv.FormatInt(int64(int8(bits)), 10)
+	default:
+		return strconv.FormatUint(bits, 10)
+	}
+}
@@ -0,0 +1,203 @@
+package mavlink
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+// MAVLink start-of-frame markers
+const (
+	MagicV1 = 0xFE
+	MagicV2 = 0xFD
+)
+
+// Frame layout sizes
+const (
+	headerLenV1    = 6
+	headerLenV2    = 10
+	checksumLen    = 2
+	signatureLen   = 13
+	flagSigned     = 0x01
+	maxPayloadLen  = 255
+	maxFrameLength = headerLenV2 + maxPayloadLen + checksumLen + signatureLen
+)
+
+var (
+	// ErrChecksum is returned when a frame's CRC does not match its contents
+	ErrChecksum = errors.New("mavlink: checksum mismatch")
+	// ErrUnknownMessage is returned when a frame's CRC cannot be verified
+	// because its message ID is not part of the known dialect
+	ErrUnknownMessage = errors.New("mavlink: unknown message id")
+	// ErrSigned is returned when a signed frame would have to be modified,
+	// which would invalidate its signature
+	ErrSigned = errors.New("mavlink: frame is signed")
+)
+
+// Frame is a single MAVLink v1 or v2 packet
+type Frame struct {
+	Version       int
+	IncompatFlags uint8
+	CompatFlags   uint8
+	Seq           uint8
+	SysID         uint8
+	CompID        uint8
+	MsgID         uint32
+	Payload       []byte
+	Checksum      uint16
+
+	// Raw holds the complete frame exactly as received
+	Raw []byte
+}
+
+// Signed reports whether the frame carries a MAVLink 2 signature
+func (f *Frame) Signed() bool {
+	return f.Version == 2 && f.IncompatFlags&flagSigned != 0
+}
+
+// Name returns the dialect name of the frame's message
+func (f *Frame) Name() string {
+	return MessageName(f.MsgID)
+}
+
+// Validate verifies the frame checksum against the dialect CRC_EXTRA
+func (f *Frame) Validate() error {
+	info, ok := messages[f.MsgID]
+	if !ok {
+		return ErrUnknownMessage
+	}
+
+	headerLen := headerLenV1
+	if f.Version == 2 {
+		headerLen = headerLenV2
+	}
+
+	// Checksum covers everything after the magic byte up to the end of the payload
+	body := f.Raw[1 : headerLen+len(f.Payload)]
+	if checksum(body, info.crcExtra) != f.Checksum {
+		return ErrChecksum
+	}
+
+	return nil
+}
+
+// AppendWithSource appends the frame to dst with its source system and
+// component IDs replaced and the checksum recomputed
+func (f *Frame) AppendWithSource(dst []byte, sysID, compID uint8) ([]byte, error) {
+	if f.Signed() {
+		return dst, ErrSigned
+	}
+	info, ok := messages[f.MsgID]
+	if !ok {
+		return dst, ErrUnknownMessage
+	}
+
+	headerLen, sysOffset := headerLenV1, 3
+	if f.Version == 2 {
+		headerLen, sysOffset = headerLenV2, 5
+	}
+
+	start := len(dst)
+	dst = append(dst, f.Raw...)
+	raw := dst[start:]
+	raw[sysOffset] = sysID
+	raw[sysOffset+1] = compID
+
+	end := headerLen + len(f.Payload)
+	binary.LittleEndian.PutUint16(raw[end:], checksum(raw[1:end], info.crcExtra))
+	return dst, nil
+}
+
+// FrameLength returns the total length of the frame whose header starts buf,
+// or 0 if buf doesn't start with a complete header
+func FrameLength(buf []byte) int {
+	if len(buf) == 0 {
+		return 0
+	}
+	return frameLength(buf)
+}
+
+// frameLength returns the total length of the frame starting at buf[0], or 0
+// if the header is not yet complete
+func frameLength(buf []byte) int {
+	switch buf[0] {
+	case MagicV1:
+		if len(buf) < 2 {
+			return 0
+		}
+		return headerLenV1 + int(buf[1]) + checksumLen
+	case MagicV2:
+		if len(buf) < 3 {
+			return 0
+		}
+		n := headerLenV2 + int(buf[1]) + checksumLen
+		if buf[2]&flagSigned != 0 {
+			n += signatureLen
+		}
+		return n
+	}
+	return 0
+}
+
+// DecodeFrame decodes raw, which must hold exactly one complete frame
+func DecodeFrame(raw []byte) (Frame, error) {
+	if n := FrameLength(raw); n == 0 || n != len(raw) {
+		return Frame{}, errors.New("mavlink: not a complete frame")
+	}
+	return decodeFrame(raw), nil
+}
+
+// decodeFrame decodes a complete frame; raw must be exactly one frame long
+func decodeFrame(raw []byte) Frame {
+	f := Frame{Raw: raw}
+
+	payloadLen := int(raw[1])
+	if raw[0] == MagicV1 {
+		f.Version = 1
+		f.Seq = raw[2]
+		f.SysID = raw[3]
+		f.CompID = raw[4]
+		f.MsgID = uint32(raw[5])
+		f.Payload = raw[headerLenV1 : headerLenV1+payloadLen]
+		f.Checksum = binary.LittleEndian.Uint16(raw[headerLenV1+payloadLen:])
+		return f
+	}
+
+	f.Version = 2
+	f.IncompatFlags = raw[2]
+	f.CompatFlags = raw[3]
+	f.Seq = raw[4]
+	f.SysID = raw[5]
+	f.CompID = raw[6]
+	f.MsgID = uint32(raw[7]) | uint32(raw[8])<<8 | uint32(raw[9])<<16
+	f.Payload = raw[headerLenV2 : headerLenV2+payloadLen]
+	f.Checksum = binary.LittleEndian.Uint16(raw[headerLenV2+payloadLen:])
+	return f
+}
+
+// EncodeV2 builds a MAVLink 2 frame for a dialect message
+func EncodeV2(seq, sysID, compID uint8, msgID uint32, payload []byte) ([]byte, error) {
+	info, ok := messages[msgID]
+	if !ok {
+		return nil, ErrUnknownMessage
+	}
+	if len(payload) > maxPayloadLen {
+		return nil, fmt.Errorf("mavlink: payload too long (%d bytes)", len(payload))
+	}
+
+	raw := make([]byte, headerLenV2+len(payload)+checksumLen)
+	raw[0] = MagicV2
+	raw[1] = byte(len(payload))
+	raw[4] = seq
+	raw[5] = sysID
+	raw[6] = compID
+	raw[7] = byte(msgID)
+	raw[8] = byte(msgID >> 8)
+	raw[9] = byte(msgID >> 16)
+	copy(raw[headerLenV2:], payload)
+
+	sum := checksum(raw[1:headerLenV2+len(payload)], info.crcExtra)
+	binary.LittleEndian.PutUint16(raw[headerLenV2+len(payload):], sum)
+
+	return raw, nil
+}
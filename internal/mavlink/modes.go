@@ -0,0 +1,102 @@
+package mavlink
+
+import "fmt"
+
+// MAV_AUTOPILOT values with known flight mode encodings
+const (
+	autopilotArduPilot = 3
+	autopilotPX4       = 12
+)
+
+// MAV_TYPE values that pick an ArduPilot mode table
+const (
+	mavTypeFixedWing = 1
+	mavTypeRover     = 10
+	mavTypeBoat      = 11
+)
+
+// ArduPilot custom_mode numbers by vehicle firmware
+var (
+	arduCopterModes = map[uint32]string{
+		0: "STABILIZE", 1: "ACRO", 2: "ALT_HOLD", 3: "AUTO", 4: "GUIDED", 5: "LOITER", 6: "RTL", 7: "CIRCLE",
+		9: "LAND", 11: "DRIFT", 13: "SPORT", 14: "FLIP", 15: "AUTOTUNE", 16: "POSHOLD", 17: "BRAKE", 18: "THROW",
+		19: "AVOID_ADSB", 20: "GUIDED_NOGPS", 21: "SMART_RTL", 22: "FLOWHOLD", 23: "FOLLOW", 24: "ZIGZAG",
+		25: "SYSTEMID", 26: "AUTOROTATE", 27: "AUTO_RTL",
+	}
+	arduPlaneModes = map[uint32]string{
+		0: "MANUAL", 1: "CIRCLE", 2: "STABILIZE", 3: "TRAINING", 4: "ACRO", 5: "FBWA", 6: "FBWB", 7: "CRUISE",
+		8: "AUTOTUNE", 10: "AUTO", 11: "RTL", 12: "LOITER", 13: "TAKEOFF", 14: "AVOID_ADSB", 15: "GUIDED",
+		17: "QSTABILIZE", 18: "QHOVER", 19: "QLOITER", 20: "QLAND", 21: "QRTL", 22: "QAUTOTUNE", 23: "QACRO",
+		24: "THERMAL", 25: "LOITER_ALT_QLAND",
+	}
+	arduRoverModes = map[uint32]string{
+		0: "MANUAL", 1: "ACRO", 3: "STEERING", 4: "HOLD", 5: "LOITER", 6: "FOLLOW", 7: "SIMPLE", 8: "DOCK",
+		9: "CIRCLE", 10: "AUTO", 11: "RTL", 12: "SMART_RTL", 15: "GUIDED",
+	}
+)
+
+// PX4 main modes, and the sub modes of AUTO, from the high bytes of custom_mode
+var (
+	px4MainModes = map[uint8]string{
+		1: "MANUAL", 2: "ALTCTL", 3: "POSCTL", 4: "AUTO", 5: "ACRO", 6: "OFFBOARD", 7: "STABILIZED", 8: "RATTITUDE",
+	}
+	px4AutoModes = map[uint8]string{
+		1: "READY", 2: "TAKEOFF", 3: "LOITER", 4: "MISSION", 5: "RTL", 6: "LAND", 8: "FOLLOW", 9: "PRECLAND",
+	}
+)
+
+// FlightMode returns the name of the autopilot's flight mode, or "" if no
+// heartbeat was seen or the autopilot's modes aren't known
+func (t *Telemetry) FlightMode() string {
+	if !t.HaveHeartbeat {
+		return ""
+	}
+	return ModeName(t.Autopilot, t.VehicleType, t.CustomMode)
+}
+
+// ModeName returns the name of a custom_mode of an autopilot and vehicle
+// type, or "" if the autopilot's modes aren't known
+func ModeName(autopilot, vehicleType uint8, customMode uint32) string {
+	switch autopilot {
+	case autopilotArduPilot:
+		modes := arduCopterModes
+		switch vehicleType {
+		case mavTypeFixedWing:
+			modes = arduPlaneModes
+		case mavTypeRover, mavTypeBoat:
+			modes = arduRoverModes
+		}
+		return modes[customMode]
+	case autopilotPX4:
+		mainMode, subMode := uint8(customMode>>16), uint8(customMode>>24)
+		if mainMode == 4 && px4AutoModes[subMode] != "" {
+			return px4AutoModes[subMode]
+		}
+		return px4MainModes[mainMode]
+	}
+	return ""
+}
+
+// Firmware returns the autopilot firmware and, once AUTOPILOT_VERSION has
+// been seen, its version, e.g. "ArduPilot 4.5.1"
+func (t *Telemetry) Firmware() string {
+	if !t.HaveHeartbeat {
+		return ""
+	}
+
+	var name string
+	switch t.Autopilot {
+	case autopilotArduPilot:
+		name = "ArduPilot"
+	case autopilotPX4:
+		name = "PX4"
+	default:
+		return ""
+	}
+
+	if t.FirmwareSW == 0 {
+		return name
+	}
+	major, minor, patch := t.FirmwareSW>>24, (t.FirmwareSW>>16)&0xff, (t.FirmwareSW>>8)&0xff
+	return fmt.Sprintf("%s %d.%d.%d", name, major, minor, patch)
+}
@@ -0,0 +1,92 @@
+package mavlink
+
+// parserBufferSize is the parser's working buffer; it only grows when a
+// single Feed call delivers more than this
+const parserBufferSize = 16 * 1024
+
+// Parser reassembles MAVLink frames from a byte stream.
+// A Parser is not safe for concurrent use; use one per stream.
+type Parser struct {
+	buf     []byte // Pending bytes are buf[start:end]
+	start   int
+	end     int
+	frames  []Frame
+	skipped uint64
+}
+
+// NewParser creates a new frame parser
+func NewParser() *Parser {
+	return NewParserSize(parserBufferSize)
+}
+
+// NewParserSize creates a frame parser with a working buffer of size bytes.
+// Smaller buffers save memory but grow (allocate) when a single Feed call
+// delivers more.
+func NewParserSize(size int) *Parser {
+	return &Parser{
+		buf: make([]byte, size),
+	}
+}
+
+// Feed appends data to the stream and returns every frame completed by it.
+// Bytes that cannot start a frame are discarded and counted as skipped;
+// a trailing partial frame is kept until the next call.
+//
+// To avoid allocating on every call, the returned frames (including their
+// Raw and Payload slices) reference the parser's internal buffer and are
+// only valid until the next call to Feed.
+func (p *Parser) Feed(data []byte) []Frame {
+	// Move any partial frame to the front so the buffer is reused
+	pending := p.end - p.start
+	if p.start > 0 {
+		copy(p.buf, p.buf[p.start:p.end])
+		p.start, p.end = 0, pending
+	}
+	if need := pending + len(data); need > len(p.buf) {
+		buf := make([]byte, need)
+		copy(buf, p.buf[:pending])
+		p.buf = buf
+	}
+	p.end += copy(p.buf[p.end:], data)
+
+	frames := p.frames[:0]
+	for p.start < p.end {
+		buf := p.buf[p.start:p.end]
+
+		// Resynchronise on the next start-of-frame marker
+		if buf[0] != MagicV1 && buf[0] != MagicV2 {
+			i := 1
+			for i < len(buf) && buf[i] != MagicV1 && buf[i] != MagicV2 {
+				i++
+			}
+			p.skipped += uint64(i)
+			p.start += i
+			continue
+		}
+
+		n := frameLength(buf)
+		if n == 0 || len(buf) < n {
+			break // wait for more data
+		}
+
+		frames = append(frames, decodeFrame(buf[:n:n]))
+		p.start += n
+	}
+
+	if p.start == p.end {
+		p.start, p.end = 0, 0
+	}
+
+	p.frames = frames
+	return frames
+}
+
+// Skipped returns the number of bytes discarded while searching for frames
+func (p *Parser) Skipped() uint64 {
+	return p.skipped
+}
+
+// Buffered returns the number of bytes held for an incomplete frame
+func (p *Parser) Buffered() int {
+	return p.end - p.start
+}
@@ -0,0 +1,53 @@
+package mavlink
+
+import "testing"
+
+// benchmarkBurst returns a typical downlink message: a heartbeat, a position
+// and a system status
+func benchmarkBurst(b *testing.B) []byte {
+	var burst []byte
+	for i, m := range []struct {
+		id   uint32
+		size int
+	}{{MsgIDHeartbeat, 9}, {MsgIDGlobalPositionInt, 28}, {MsgIDSysStatus, 31}} {
+		frame, err := EncodeV2(uint8(i), 1, 1, m.id, make([]byte, m.size))
+		if err != nil {
+			b.Fatal(err)
+		}
+		burst = append(burst, frame...)
+	}
+	return burst
+}
+
+// BenchmarkParserFeed feeds whole messages, as the WebSocket delivers them
+func BenchmarkParserFeed(b *testing.B) {
+	burst := benchmarkBurst(b)
+	p := NewParser()
+
+	b.ReportAllocs()
+	b.SetBytes(int64(len(burst)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if frames := p.Feed(burst); len(frames) != 3 {
+			b.Fatalf("got %d frames, want 3", len(frames))
+		}
+	}
+}
+
+// BenchmarkParserFeedSplit feeds messages in two reads that split a frame,
+// as a TCP or serial stream may
+func BenchmarkParserFeedSplit(b *testing.B) {
+	burst := benchmarkBurst(b)
+	half := len(burst) / 2
+	p := NewParser()
+
+	b.ReportAllocs()
+	b.SetBytes(int64(len(burst)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		n := len(p.Feed(burst[:half]))
+		if n += len(p.Feed(burst[half:])); n != 3 {
+			b.Fatalf("got %d frames, want 3", n)
+		}
+	}
+}
@@ -0,0 +1,27 @@
+package mavlink
+
+// MsgIDStatusText is STATUSTEXT, a human-readable message ground stations
+// show in their message log
+const MsgIDStatusText = 253
+
+// STATUSTEXT severities (MAV_SEVERITY) used by the bridge
+const (
+	SeverityWarning = 4
+	SeverityInfo    = 6
+)
+
+// statusTextLen is the length of a STATUSTEXT payload without its extension
+// fields: severity and up to 50 characters of text
+const statusTextLen = 51
+
+// MaxStatusTextLen is how much text fits in one STATUSTEXT
+const MaxStatusTextLen = statusTextLen - 1
+
+// EncodeStatusText builds a STATUSTEXT frame; text longer than
+// MaxStatusTextLen is cut off
+func EncodeStatusText(seq, sysID, compID, severity uint8, text string) ([]byte, error) {
+	payload := make([]byte, statusTextLen)
+	payload[0] = severity
+	copy(payload[1:], text)
+	return EncodeV2(seq, sysID, compID, MsgIDStatusText, payload)
+}
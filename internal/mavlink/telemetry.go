@@ -0,0 +1,189 @@
+package mavlink
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"strings"
+)
+
+// Message IDs decoded for telemetry summaries
+const (
+	MsgIDHeartbeat = 0
+	MsgIDSysStatus = 1
+	MsgIDGPSRawInt = 24
+
+	MsgIDGlobalPositionInt = 33
+	MsgIDRadioStatus       = 109
+	MsgIDAutopilotVersion  = 148
+)
+
+// Wire offsets of the fields read from each message. MAVLink serializes
+// fields largest type first, so these differ from the XML field order.
+const (
+	heartbeatCustomModeOffset = 0
+	heartbeatTypeOffset       = 4
+	heartbeatAutopilotOffset  = 5
+	heartbeatBaseModeOffset   = 6
+	sysStatusBatteryOffset    = 30
+	gpsRawIntEphOffset        = 20
+	gpsRawIntFixTypeOffset    = 28
+	gpsRawIntSatellitesOffset = 29
+
+	globalPositionLatOffset     = 4
+	globalPositionLonOffset     = 8
+	globalPositionAltOffset     = 12
+	globalPositionRelAltOffset  = 16
+	globalPositionVxOffset      = 20
+	globalPositionVyOffset      = 22
+	globalPositionVzOffset      = 24
+	globalPositionHeadingOffset = 26
+
+	radioStatusRSSIOffset       = 4
+	radioStatusRemoteRSSIOffset = 5
+
+	autopilotVersionFlightSWOffset = 16
+
+	modeFlagSafetyArmed = 0x80
+)
+
+// gpsFixNames maps GPS_FIX_TYPE values to short labels
+var gpsFixNames = []string{"no GPS", "no fix", "2D fix", "3D fix", "DGPS", "RTK float", "RTK fixed", "static", "PPP"}
+
+// Telemetry is a summary of vehicle state assembled from telemetry frames
+type Telemetry struct {
+	HaveHeartbeat bool
+	Armed         bool
+	VehicleType   uint8  // MAV_TYPE
+	Autopilot     uint8  // MAV_AUTOPILOT
+	CustomMode    uint32 // Autopilot-specific flight mode, see FlightMode
+	FirmwareSW    uint32 // AUTOPILOT_VERSION flight_sw_version, 0 if not seen
+
+	HaveBattery bool
+	Battery     int // Remaining battery percentage, -1 if the autopilot doesn't report it
+
+	HaveGPS    bool
+	FixType    uint8
+	Satellites uint8
+	HDOP       float64 // -1 if unknown
+
+	HavePosition bool
+	Lat          float64 // Degrees
+	Lon          float64 // Degrees
+	AltMSL       float64 // Meters above mean sea level
+	AltRelative  float64 // Meters above home
+	GroundSpeed  float64 // Meters per second
+	ClimbRate    float64 // Meters per second, positive up
+	Heading      float64 // Degrees, -1 if unknown
+
+	HaveRadio  bool
+	RSSI       uint8 // Local radio signal strength (radio-specific units, 255 = unknown)
+	RemoteRSSI uint8 // Signal strength reported by the remote radio
+}
+
+// payloadByte returns a payload byte, treating bytes removed by MAVLink 2
+// trailing-zero truncation as zero
+func payloadByte(payload []byte, offset int) byte {
+	if offset < len(payload) {
+		return payload[offset]
+	}
+	return 0
+}
+
+// payloadUint16 reads a little-endian uint16 from a possibly truncated payload
+func payloadUint16(payload []byte, offset int) uint16 {
+	return uint16(payloadByte(payload, offset)) | uint16(payloadByte(payload, offset+1))<<8
+}
+
+// payloadInt32 reads a little-endian int32 from a possibly truncated payload
+func payloadInt32(payload []byte, offset int) int32 {
+	var b [4]byte
+	for i := range b {
+		b[i] = payloadByte(payload, offset+i)
+	}
+	return int32(binary.LittleEndian.Uint32(b[:]))
+}
+
+// Apply updates the summary from a frame, ignoring messages it doesn't use.
+// Frames from components other than the autopilot (or, for RADIO_STATUS,
+// the telemetry radio) should be filtered by the caller.
+func (t *Telemetry) Apply(f Frame) {
+	switch f.MsgID {
+	case MsgIDHeartbeat:
+		t.HaveHeartbeat = true
+		t.Armed = payloadByte(f.Payload, heartbeatBaseModeOffset)&modeFlagSafetyArmed != 0
+		t.VehicleType = payloadByte(f.Payload, heartbeatTypeOffset)
+		t.Autopilot = payloadByte(f.Payload, heartbeatAutopilotOffset)
+		t.CustomMode = uint32(payloadInt32(f.Payload, heartbeatCustomModeOffset))
+	case MsgIDSysStatus:
+		t.HaveBattery = true
+		t.Battery = int(int8(payloadByte(f.Payload, sysStatusBatteryOffset)))
+	case MsgIDGPSRawInt:
+		t.HaveGPS = true
+		t.FixType = payloadByte(f.Payload, gpsRawIntFixTypeOffset)
+		t.Satellites = payloadByte(f.Payload, gpsRawIntSatellitesOffset)
+		t.HDOP = -1
+		if eph := payloadUint16(f.Payload, gpsRawIntEphOffset); eph != math.MaxUint16 {
+			t.HDOP = float64(eph) / 100
+		}
+	case MsgIDGlobalPositionInt:
+		t.HavePosition = true
+		t.Lat = float64(payloadInt32(f.Payload, globalPositionLatOffset)) / 1e7
+		t.Lon = float64(payloadInt32(f.Payload, globalPositionLonOffset)) / 1e7
+		t.AltMSL = float64(payloadInt32(f.Payload, globalPositionAltOffset)) / 1000
+		t.AltRelative = float64(payloadInt32(f.Payload, globalPositionRelAltOffset)) / 1000
+		vx := float64(int16(payloadUint16(f.Payload, globalPositionVxOffset))) / 100
+		vy := float64(int16(payloadUint16(f.Payload, globalPositionVyOffset))) / 100
+		t.GroundSpeed = math.Hypot(vx, vy)
+		t.ClimbRate = float64(-int32(int16(payloadUint16(f.Payload, globalPositionVzOffset)))) / 100
+		t.Heading = -1
+		if hdg := payloadUint16(f.Payload, globalPositionHeadingOffset); hdg != math.MaxUint16 {
+			t.Heading = float64(hdg) / 100
+		}
+	case MsgIDRadioStatus:
+		t.HaveRadio = true
+		t.RSSI = payloadByte(f.Payload, radioStatusRSSIOffset)
+		t.RemoteRSSI = payloadByte(f.Payload, radioStatusRemoteRSSIOffset)
+	case MsgIDAutopilotVersion:
+		t.FirmwareSW = uint32(payloadInt32(f.Payload, autopilotVersionFlightSWOffset))
+	}
+}
+
+// Complete reports whether every summarized message has been seen
+func (t *Telemetry) Complete() bool {
+	return t.HaveHeartbeat && t.HaveBattery && t.HaveGPS
+}
+
+// String formats the summary as a single line, e.g. "🔋 76% • 3D fix (12 sats) • disarmed"
+func (t *Telemetry) String() string {
+	var parts []string
+
+	if t.HaveBattery {
+		if t.Battery >= 0 {
+			parts = append(parts, fmt.Sprintf("🔋 %d%%", t.Battery))
+		} else {
+			parts = append(parts, "🔋 ?")
+		}
+	}
+
+	if t.HaveGPS {
+		fix := "unknown fix"
+		if int(t.FixType) < len(gpsFixNames) {
+			fix = gpsFixNames[t.FixType]
+		}
+		parts = append(parts, fmt.Sprintf("%s (%d sats)", fix, t.Satellites))
+	}
+
+	if t.HaveHeartbeat {
+		if t.Armed {
+			parts = append(parts, "ARMED")
+		} else {
+			parts = append(parts, "disarmed")
+		}
+	}
+
+	if len(parts) == 0 {
+		return "no telemetry"
+	}
+	return strings.Join(parts, " • ")
+}
@@ -0,0 +1,39 @@
+package network
+
+import (
+	"errors"
+	"sync"
+	"syscall"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// unboundWarned makes the warning about unbound sockets appear once per interface
+var unboundWarned sync.Map
+
+// bindToDevice returns a socket option hook that binds a socket to an
+// interface, so its traffic takes that interface's route even when another
+// interface holds the default route. Binding needs CAP_NET_RAW on older
+// kernels; without it the source address alone selects the interface, which
+// works wherever the OS routes by source (e.g. with per-interface tables).
+// That fallback is logged, since elsewhere traffic takes the default route.
+func bindToDevice(name string) func(network, address string, c syscall.RawConn) error {
+	return func(network, address string, c syscall.RawConn) error {
+		var bindErr error
+		err := c.Control(func(fd uintptr) {
+			bindErr = syscall.SetsockoptString(int(fd), syscall.SOL_SOCKET, syscall.SO_BINDTODEVICE, name)
+		})
+		if err != nil {
+			return err
+		}
+		if errors.Is(bindErr, syscall.EPERM) {
+			if _, warned := unboundWarned.LoadOrStore(name, true); !warned {
+				log.WithFields(log.Fields{"component": "network", "interface": name}).Warn(
+					"Not permitted to bind connections to the interface (needs CAP_NET_RAW); " +
+						"they only use its address and may leave through the default route")
+			}
+			return nil
+		}
+		return bindErr
+	}
+}
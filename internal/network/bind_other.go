@@ -0,0 +1,11 @@
+//go:build !linux
+
+package network
+
+import "syscall"
+
+// bindToDevice is a no-op where sockets can't be bound to an interface; the
+// source address selects the interface instead
+func bindToDevice(name string) func(network, address string, c syscall.RawConn) error {
+	return nil
+}
@@ -0,0 +1,90 @@
+package network
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// fallbackDelay is how long a dial waits on the preferred address family
+// before racing the other one (RFC 8305 "happy eyeballs")
+const fallbackDelay = 300 * time.Millisecond
+
+// dialer is shared by the HTTP and WebSocket clients. It resolves both A and
+// AAAA records and races IPv6 against IPv4, so IPv6-only networks with NAT64
+// and IPv4-only networks both connect without waiting for a timeout.
+var dialer = &net.Dialer{
+	Timeout:       10 * time.Second,
+	KeepAlive:     30 * time.Second,
+	FallbackDelay: fallbackDelay,
+}
+
+// bound replaces the shared dialer when connections must leave through a
+// specific interface or source address; set once at startup
+var bound DialFunc
+
+// DialContext dials addr using the shared dual-stack dialer, applying any
+// static host overrides, custom DNS server and interface binding. The
+// binding is skipped for destinations on this machine or a private network,
+// such as a local InfluxDB or webhook receiver, which the bound interface
+// usually can't reach.
+func DialContext(ctx context.Context, network, addr string) (net.Conn, error) {
+	if bound != nil && !localDestination(ctx, addr) {
+		return bound(ctx, network, addr)
+	}
+	return dialer.DialContext(ctx, network, resolveOverride(addr))
+}
+
+// localDestination reports whether addr is on this machine or a private
+// network: loopback, RFC 1918, unique local or link-local addresses. A
+// host name counts if all of its addresses do.
+func localDestination(ctx context.Context, addr string) bool {
+	host, _, err := net.SplitHostPort(resolveOverride(addr))
+	if err != nil {
+		return false
+	}
+	if strings.EqualFold(host, "localhost") {
+		return true
+	}
+	if ip := net.ParseIP(host); ip != nil {
+		return privateIP(ip)
+	}
+
+	resolver := dialer.Resolver
+	if resolver == nil {
+		resolver = net.DefaultResolver
+	}
+	ips, err := resolver.LookupIP(ctx, "ip", host)
+	if err != nil || len(ips) == 0 {
+		return false
+	}
+	for _, ip := range ips {
+		if !privateIP(ip) {
+			return false
+		}
+	}
+	return true
+}
+
+// privateIP reports whether ip is only reachable locally
+func privateIP(ip net.IP) bool {
+	return ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast()
+}
+
+// Configure makes the default HTTP transport use the shared dialer, keep
+// more idle connections for reuse and resume TLS sessions, so repeated calls
+// to the API skip the full handshake
+func Configure() {
+	if transport, ok := http.DefaultTransport.(*http.Transport); ok {
+		transport.DialContext = DialContext
+		transport.MaxIdleConnsPerHost = defaultMaxIdlePerHost
+		if transport.TLSClientConfig == nil {
+			transport.TLSClientConfig = &tls.Config{}
+		}
+		transport.TLSClientConfig.ClientSessionCache = tls.NewLRUClientSessionCache(0)
+		shared = transport
+	}
+}
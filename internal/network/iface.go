@@ -0,0 +1,126 @@
+package network
+
+import (
+	"context"
+	"fmt"
+	"net"
+)
+
+// DialFunc dials a network address, like net.Dialer.DialContext
+type DialFunc func(ctx context.Context, network, addr string) (net.Conn, error)
+
+// InterfaceDialer returns a dialer whose connections leave through the named
+// interface, e.g. "wwan0" for an LTE modem. Connections use the interface's
+// address as their source and, on Linux, are bound to the device so they
+// follow its route rather than the default one. Addresses are looked up on
+// every dial, so a modem that reconnects with a new address keeps working.
+func InterfaceDialer(name string) (DialFunc, error) {
+	if _, err := net.InterfaceByName(name); err != nil {
+		return nil, fmt.Errorf("interface %s: %w", name, err)
+	}
+
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		v4, v6, err := interfaceAddrs(name)
+		if err != nil {
+			return nil, err
+		}
+		addr = resolveOverride(addr)
+
+		// Prefer IPv4, which mobile carriers route most reliably, and fall
+		// back to IPv6 when the interface has no IPv4 address or it fails
+		var dialErr error
+		for _, source := range []struct {
+			network string
+			ip      net.IP
+		}{{"tcp4", v4}, {"tcp6", v6}} {
+			if source.ip == nil {
+				continue
+			}
+			d := *dialer
+			d.LocalAddr = &net.TCPAddr{IP: source.ip}
+			d.Control = bindToDevice(name)
+			conn, err := d.DialContext(ctx, source.network, addr)
+			if err == nil {
+				return conn, nil
+			}
+			if dialErr == nil {
+				dialErr = err
+			}
+		}
+		return nil, fmt.Errorf("via %s: %w", name, dialErr)
+	}, nil
+}
+
+// BindInterface makes connections to public destinations leave through an
+// interface, given by name (e.g. "wwan0") or by one of its addresses (e.g.
+// "10.64.0.2"), rather than the default route. Local and private
+// destinations keep using the default route, see DialContext.
+func BindInterface(spec string) error {
+	if ip := net.ParseIP(spec); ip != nil {
+		if !localAddress(ip) {
+			return fmt.Errorf("%s is not an address of this machine", spec)
+		}
+		bound = func(ctx context.Context, network, addr string) (net.Conn, error) {
+			d := *dialer
+			d.LocalAddr = &net.TCPAddr{IP: ip}
+			return d.DialContext(ctx, network, resolveOverride(addr))
+		}
+		return nil
+	}
+
+	dial, err := InterfaceDialer(spec)
+	if err != nil {
+		return err
+	}
+	bound = dial
+	return nil
+}
+
+// localAddress reports whether an IP address belongs to one of this
+// machine's interfaces
+func localAddress(ip net.IP) bool {
+	addrs, err := net.InterfaceAddrs()
+	if err != nil {
+		return false
+	}
+	for _, a := range addrs {
+		if ipnet, ok := a.(*net.IPNet); ok && ipnet.IP.Equal(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// interfaceAddrs returns an interface's first IPv4 and global IPv6 address
+func interfaceAddrs(name string) (v4, v6 net.IP, err error) {
+	ifi, err := net.InterfaceByName(name)
+	if err != nil {
+		return nil, nil, fmt.Errorf("interface %s: %w", name, err)
+	}
+	if ifi.Flags&net.FlagUp == 0 {
+		return nil, nil, fmt.Errorf("interface %s is down", name)
+	}
+	addrs, err := ifi.Addrs()
+	if err != nil {
+		return nil, nil, fmt.Errorf("interface %s: %w", name, err)
+	}
+
+	for _, a := range addrs {
+		ipnet, ok := a.(*net.IPNet)
+		if !ok {
+			continue
+		}
+		switch ip := ipnet.IP; {
+		case ip.To4() != nil:
+			if v4 == nil {
+				v4 = ip
+			}
+		case ip.IsGlobalUnicast() && v6 == nil:
+			v6 = ip
+		}
+	}
+	if v4 == nil && v6 == nil {
+		return nil, nil, fmt.Errorf("interface %s has no usable address", name)
+	}
+	return v4, v6, nil
+}
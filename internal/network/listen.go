@@ -0,0 +1,23 @@
+package network
+
+import (
+	"fmt"
+	"net"
+	"strings"
+)
+
+// ValidateListenAddr checks a host:port listen address, with a hint for the
+// common mistake of an unbracketed IPv6 address such as "::1:5169"
+func ValidateListenAddr(addr string) error {
+	_, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		if strings.Count(addr, ":") > 1 && !strings.HasPrefix(addr, "[") {
+			return fmt.Errorf("invalid address %q: IPv6 addresses must be bracketed, e.g. [::1]:5169", addr)
+		}
+		return fmt.Errorf("invalid address %q: %w", addr, err)
+	}
+	if port == "" {
+		return fmt.Errorf("invalid address %q: missing port", addr)
+	}
+	return nil
+}
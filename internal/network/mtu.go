@@ -0,0 +1,93 @@
+package network
+
+import (
+	"fmt"
+	"net"
+	"strings"
+)
+
+// EthernetMTU is the MTU of a path without tunnels
+const EthernetMTU = 1500
+
+// tunnelPrefixes are names of VPN and tunnel interfaces, whose MTU is below
+// the path's because they wrap every packet in their own headers
+var tunnelPrefixes = []string{"tun", "tap", "wg", "ppp", "utun", "ipsec", "zt", "tailscale", "nordlynx", "gre", "vti"}
+
+// PathMTU describes the largest packet a connection can send unfragmented
+type PathMTU struct {
+	MTU       int    `json:"mtu"`
+	Interface string `json:"interface"` // Interface the connection leaves through
+	IPv6      bool   `json:"ipv6"`
+	Tunnel    bool   `json:"tunnel"` // Whether the interface is a VPN or tunnel
+}
+
+// ProbePathMTU estimates the path MTU of a connected TCP connection, possibly
+// wrapped in TLS: the MTU of the interface it leaves through, lowered to what
+// the kernel learned from the path (Linux only). A VPN whose tunnel drops
+// packets without reporting its MTU isn't detected by the kernel, so a
+// tunnel interface is flagged as well.
+func ProbePathMTU(conn net.Conn) (PathMTU, error) {
+	for {
+		inner, ok := conn.(interface{ NetConn() net.Conn })
+		if !ok {
+			break
+		}
+		conn = inner.NetConn()
+	}
+	tcp, ok := conn.(*net.TCPConn)
+	if !ok {
+		return PathMTU{}, fmt.Errorf("not a TCP connection")
+	}
+	local, ok := tcp.LocalAddr().(*net.TCPAddr)
+	if !ok {
+		return PathMTU{}, fmt.Errorf("no local address")
+	}
+
+	ifi, err := interfaceOf(local.IP)
+	if err != nil {
+		return PathMTU{}, err
+	}
+	p := PathMTU{
+		MTU:       ifi.MTU,
+		Interface: ifi.Name,
+		IPv6:      local.IP.To4() == nil,
+		Tunnel:    tunnelInterface(ifi.Name),
+	}
+	if raw, err := tcp.SyscallConn(); err == nil {
+		if mtu := kernelPathMTU(raw, p.IPv6); mtu > 0 && mtu < p.MTU {
+			p.MTU = mtu
+		}
+	}
+	return p, nil
+}
+
+// interfaceOf returns the interface holding an address
+func interfaceOf(ip net.IP) (*net.Interface, error) {
+	ifaces, err := net.Interfaces()
+	if err != nil {
+		return nil, err
+	}
+	for i := range ifaces {
+		addrs, err := ifaces[i].Addrs()
+		if err != nil {
+			continue
+		}
+		for _, a := range addrs {
+			if ipnet, ok := a.(*net.IPNet); ok && ipnet.IP.Equal(ip) {
+				return &ifaces[i], nil
+			}
+		}
+	}
+	return nil, fmt.Errorf("no interface has address %s", ip)
+}
+
+// tunnelInterface reports whether an interface name looks like a VPN or tunnel
+func tunnelInterface(name string) bool {
+	name = strings.ToLower(name)
+	for _, prefix := range tunnelPrefixes {
+		if strings.HasPrefix(name, prefix) {
+			return true
+		}
+	}
+	return false
+}
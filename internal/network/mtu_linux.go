@@ -0,0 +1,22 @@
+package network
+
+import "syscall"
+
+// kernelPathMTU returns the path MTU the kernel has cached for a connected
+// socket, including what it learned from ICMP "fragmentation needed"
+// replies, or 0 if unavailable
+func kernelPathMTU(c syscall.RawConn, ipv6 bool) int {
+	mtu := 0
+	_ = c.Control(func(fd uintptr) {
+		var err error
+		if ipv6 {
+			mtu, err = syscall.GetsockoptInt(int(fd), syscall.IPPROTO_IPV6, syscall.IPV6_MTU)
+		} else {
+			mtu, err = syscall.GetsockoptInt(int(fd), syscall.IPPROTO_IP, syscall.IP_MTU)
+		}
+		if err != nil {
+			mtu = 0
+		}
+	})
+	return mtu
+}
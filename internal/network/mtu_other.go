@@ -0,0 +1,10 @@
+//go:build !linux
+
+package network
+
+import "syscall"
+
+// kernelPathMTU is unavailable outside Linux; the interface MTU is used alone
+func kernelPathMTU(c syscall.RawConn, ipv6 bool) int {
+	return 0
+}
@@ -0,0 +1,69 @@
+package network
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+)
+
+var (
+	overridesMu sync.RWMutex
+	overrides   = make(map[string]string) // "host:port" -> "ip:port"
+)
+
+// AddResolve adds a static host override in curl's --resolve format,
+// "host:port:address", e.g. "api.aircast.one:443:10.0.0.5" or
+// "api.aircast.one:443:[fd00::5]". TLS still verifies against the host name.
+func AddResolve(spec string) error {
+	host, rest, ok := strings.Cut(spec, ":")
+	if !ok || host == "" {
+		return fmt.Errorf("invalid resolve %q: want host:port:address", spec)
+	}
+	port, address, ok := strings.Cut(rest, ":")
+	if !ok || port == "" || address == "" {
+		return fmt.Errorf("invalid resolve %q: want host:port:address", spec)
+	}
+
+	address = strings.TrimSuffix(strings.TrimPrefix(address, "["), "]")
+	if net.ParseIP(address) == nil {
+		return fmt.Errorf("invalid resolve %q: %q is not an IP address", spec, address)
+	}
+
+	overridesMu.Lock()
+	defer overridesMu.Unlock()
+	overrides[net.JoinHostPort(host, port)] = net.JoinHostPort(address, port)
+	return nil
+}
+
+// SetDNSServer sends all name lookups to a specific DNS server ("host" or
+// "host:port"), for field networks with local or split-horizon DNS
+func SetDNSServer(server string) error {
+	if _, _, err := net.SplitHostPort(server); err != nil {
+		server = net.JoinHostPort(strings.Trim(server, "[]"), "53")
+	}
+	host, _, _ := net.SplitHostPort(server)
+	if net.ParseIP(host) == nil {
+		return fmt.Errorf("invalid DNS server %q: must be an IP address", server)
+	}
+
+	dialer.Resolver = &net.Resolver{
+		PreferGo: true,
+		Dial: func(ctx context.Context, network, _ string) (net.Conn, error) {
+			var d net.Dialer
+			return d.DialContext(ctx, network, server)
+		},
+	}
+	return nil
+}
+
+// resolveOverride returns the overridden address for addr, if any
+func resolveOverride(addr string) string {
+	overridesMu.RLock()
+	defer overridesMu.RUnlock()
+	if override, ok := overrides[addr]; ok {
+		return override
+	}
+	return addr
+}
@@ -0,0 +1,109 @@
+package network
+
+import (
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// sensitiveHeaders are replaced before headers are logged
+var sensitiveHeaders = map[string]bool{
+	"Authorization":       true,
+	"Proxy-Authorization": true,
+	"Cookie":              true,
+	"Set-Cookie":          true,
+}
+
+// sensitiveParams are query parameters whose values are replaced before URLs are logged
+var sensitiveParams = []string{"token", "access_token", "refresh_token", "code", "code_verifier", "client_secret", "state"}
+
+// tracingTransport logs request and response metadata of every HTTP call
+type tracingTransport struct {
+	next   http.RoundTripper
+	logger *log.Entry
+}
+
+// EnableHTTPTrace logs method, URL, headers, status and timing of every HTTP
+// request made through the default transport, with credentials redacted
+func EnableHTTPTrace(logger *log.Entry) {
+	if _, ok := http.DefaultTransport.(*tracingTransport); ok {
+		return
+	}
+	http.DefaultTransport = &tracingTransport{
+		next:   http.DefaultTransport,
+		logger: logger.WithField("component", "http"),
+	}
+}
+
+func (t *tracingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	t.logger.WithFields(log.Fields{
+		"method":  req.Method,
+		"url":     redactURL(req.URL),
+		"headers": redactHeaders(req.Header),
+	}).Info("HTTP request")
+
+	resp, err := t.next.RoundTrip(req)
+
+	fields := log.Fields{
+		"method":   req.Method,
+		"url":      redactURL(req.URL),
+		"duration": time.Since(start).Round(time.Millisecond),
+	}
+	if err != nil {
+		t.logger.WithFields(fields).WithError(err).Info("HTTP request failed")
+		return resp, err
+	}
+
+	fields["status"] = resp.StatusCode
+	fields["proto"] = resp.Proto
+	fields["length"] = resp.ContentLength
+	fields["headers"] = redactHeaders(resp.Header)
+	t.logger.WithFields(fields).Info("HTTP response")
+	return resp, nil
+}
+
+// redactHeaders formats headers on one line, hiding credentials but keeping
+// the auth scheme (e.g. "Bearer [REDACTED]")
+func redactHeaders(h http.Header) string {
+	var parts []string
+	for name, values := range h {
+		value := strings.Join(values, ", ")
+		if name = http.CanonicalHeaderKey(name); sensitiveHeaders[name] {
+			scheme, _, found := strings.Cut(value, " ")
+			if found && strings.HasSuffix(name, "Authorization") {
+				value = scheme + " [REDACTED]"
+			} else {
+				value = "[REDACTED]"
+			}
+		}
+		parts = append(parts, name+": "+value)
+	}
+	sort.Strings(parts)
+	return strings.Join(parts, "; ")
+}
+
+// redactURL hides credentials in the URL's user info and query
+func redactURL(u *url.URL) string {
+	redacted := *u
+	if redacted.User != nil {
+		redacted.User = url.User("[REDACTED]")
+	}
+
+	query := redacted.Query()
+	changed := false
+	for _, name := range sensitiveParams {
+		if query.Has(name) {
+			query.Set(name, "[REDACTED]")
+			changed = true
+		}
+	}
+	if changed {
+		redacted.RawQuery = query.Encode()
+	}
+	return redacted.String()
+}
@@ -0,0 +1,55 @@
+package network
+
+import (
+	"crypto/tls"
+	"net/http"
+	"time"
+)
+
+// Defaults of the shared transport; http.DefaultTransport keeps only two
+// idle connections per host, too few for the parallel calls at startup
+const (
+	defaultIdleTimeout    = 90 * time.Second
+	defaultKeepAlive      = 30 * time.Second
+	defaultMaxIdlePerHost = 8
+)
+
+// shared is the default HTTP transport, set by Configure. The API client,
+// login and token refresh all use it, so they reuse each other's
+// connections instead of each paying for a TLS handshake.
+var shared *http.Transport
+
+// TransportOptions tunes connection reuse of the shared HTTP transport. Zero
+// values keep the defaults.
+type TransportOptions struct {
+	DisableHTTP2 bool          // Speak HTTP/1.1 even when the server offers HTTP/2
+	KeepAlive    time.Duration // TCP keep-alive probe interval; negative disables probes
+	IdleTimeout  time.Duration // How long idle connections are kept for reuse; negative disables reuse
+}
+
+// ConfigureTransport applies options to the shared transport. It must be
+// called before the first request, as HTTP/2 is set up on first use.
+func ConfigureTransport(opts TransportOptions) {
+	if shared == nil {
+		return
+	}
+
+	shared.ForceAttemptHTTP2 = !opts.DisableHTTP2
+	if opts.DisableHTTP2 {
+		// A non-nil empty map is how net/http is told not to negotiate h2
+		shared.TLSNextProto = map[string]func(string, *tls.Conn) http.RoundTripper{}
+	} else {
+		shared.TLSNextProto = nil
+	}
+
+	dialer.KeepAlive = defaultKeepAlive
+	if opts.KeepAlive != 0 {
+		dialer.KeepAlive = opts.KeepAlive
+	}
+
+	shared.DisableKeepAlives = opts.IdleTimeout < 0
+	shared.IdleConnTimeout = defaultIdleTimeout
+	if opts.IdleTimeout > 0 {
+		shared.IdleConnTimeout = opts.IdleTimeout
+	}
+}
@@ -0,0 +1,66 @@
+// Package qr encodes short text such as URLs as QR codes and renders them
+// for terminals, so links can be opened on a phone without retyping them.
+// Encoding is done by github.com/skip2/go-qrcode at error correction level L.
+package qr
+
+import (
+	"fmt"
+	"strings"
+
+	qrcode "github.com/skip2/go-qrcode"
+)
+
+// Code is an encoded QR code; Dark reports each module's color
+type Code struct {
+	Size    int
+	modules [][]bool
+}
+
+// Dark reports whether the module at column x, row y is dark
+func (c *Code) Dark(x, y int) bool {
+	return c.modules[y][x]
+}
+
+// Encode encodes text in the smallest version that holds it
+func Encode(text string) (*Code, error) {
+	code, err := qrcode.New(text, qrcode.Low)
+	if err != nil {
+		return nil, fmt.Errorf("qr: %w", err)
+	}
+	// String draws its own, narrower quiet zone
+	code.DisableBorder = true
+	modules := code.Bitmap()
+	return &Code{Size: len(modules), modules: modules}, nil
+}
+
+// String renders the code with half-block characters, two rows per line,
+// for terminals with a dark background: light modules and the quiet zone
+// are drawn in the foreground color, as qrencode's UTF8 output does
+func (c *Code) String() string {
+	const quiet = 2
+	light := func(x, y int) bool {
+		if x < 0 || y < 0 || x >= c.Size || y >= c.Size {
+			return true
+		}
+		return !c.modules[y][x]
+	}
+
+	var sb strings.Builder
+	for y := -quiet; y < c.Size+quiet; y += 2 {
+		for x := -quiet; x < c.Size+quiet; x++ {
+			top, bottom := light(x, y), light(x, y+1)
+			switch {
+			case top && bottom:
+				sb.WriteString("█")
+			case top:
+				sb.WriteString("▀")
+			case bottom:
+				sb.WriteString("▄")
+			default:
+				sb.WriteString(" ")
+			}
+		}
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
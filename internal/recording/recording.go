@@ -0,0 +1,298 @@
+// Package recording stores MAVLink traffic from one or more devices in a
+// single file with a shared timebase, so multi-aircraft missions can be
+// replayed in sync or split into per-device tlogs.
+//
+// A recording starts with a header followed by records:
+//
+//	kind (1 byte) | channel (4 bytes) | time (8 bytes, Unix µs) | length (2 bytes) | data
+//
+// All integers are big-endian. Each device gets a channel, declared by a
+// channel record whose data is JSON-encoded ChannelInfo. Several bridges
+// may append to the same file: records are written whole with O_APPEND and
+// timestamps are wall-clock anchored but advance monotonically.
+package recording
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// header identifies a recording file and its format version
+var header = []byte("AIRCASTREC\x00\x01")
+
+// Record kinds
+const (
+	KindChannel  byte = 1 // Channel declaration
+	KindDownlink byte = 2 // Frame from the device
+	KindUplink   byte = 3 // Frame from a ground station
+)
+
+const (
+	recordHeaderSize = 15
+	flushSize        = 32 * 1024       // Buffered bytes that trigger a write
+	flushInterval    = 1 * time.Second // Longest time records stay buffered
+)
+
+// ErrNotRecording is returned when a file isn't an aircast recording
+var ErrNotRecording = errors.New("not an aircast recording")
+
+// ChannelInfo describes the device recorded on a channel
+type ChannelInfo struct {
+	DeviceID string `json:"device_id"`
+	Name     string `json:"name,omitempty"`
+}
+
+// ChannelID returns the channel for a device. It is derived from the device
+// ID so separate bridges recording to one file agree on it.
+func ChannelID(deviceID string) uint32 {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(deviceID))
+	return h.Sum32()
+}
+
+// Writer appends one device's traffic to a recording
+type Writer struct {
+	file    *os.File
+	channel uint32
+	start   time.Time // Anchors the wall-clock timebase; time.Since uses the monotonic clock
+
+	mu     sync.Mutex
+	buf    []byte
+	err    error
+	done   chan struct{}
+	closed bool
+	wg     sync.WaitGroup
+}
+
+// Create opens a recording for appending, creating it if needed, and
+// declares the device's channel
+func Create(path string, info ChannelInfo) (*Writer, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY|os.O_APPEND, 0644)
+	if err == nil {
+		if _, err := file.Write(header); err != nil {
+			_ = file.Close()
+			return nil, fmt.Errorf("failed to write recording header: %w", err)
+		}
+	} else if os.IsExist(err) {
+		if err := checkHeader(path); err != nil {
+			return nil, err
+		}
+		if file, err = os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0644); err != nil {
+			return nil, fmt.Errorf("failed to open recording: %w", err)
+		}
+	} else {
+		return nil, fmt.Errorf("failed to create recording: %w", err)
+	}
+
+	w := &Writer{
+		file:    file,
+		channel: ChannelID(info.DeviceID),
+		start:   time.Now(),
+		done:    make(chan struct{}),
+	}
+
+	data, err := json.Marshal(info)
+	if err != nil {
+		_ = file.Close()
+		return nil, err
+	}
+	w.append(KindChannel, data)
+	if err := w.Flush(); err != nil {
+		_ = file.Close()
+		return nil, err
+	}
+
+	w.wg.Add(1)
+	go w.flushPeriodically()
+
+	return w, nil
+}
+
+// checkHeader verifies that an existing file is a recording in this format
+func checkHeader(path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open recording: %w", err)
+	}
+	defer func() { _ = file.Close() }()
+
+	got := make([]byte, len(header))
+	if _, err := io.ReadFull(file, got); err != nil || string(got) != string(header) {
+		return fmt.Errorf("%s: %w", path, ErrNotRecording)
+	}
+	return nil
+}
+
+// Channel returns the writer's channel ID
+func (w *Writer) Channel() uint32 {
+	return w.channel
+}
+
+// WriteFrame records a MAVLink frame; uplink is true for frames from a ground station
+func (w *Writer) WriteFrame(raw []byte, uplink bool) {
+	kind := KindDownlink
+	if uplink {
+		kind = KindUplink
+	}
+	w.append(kind, raw)
+}
+
+// append buffers a record, writing the buffer out once it is large enough
+func (w *Writer) append(kind byte, data []byte) {
+	ts := w.start.UnixMicro() + time.Since(w.start).Microseconds()
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed || w.err != nil {
+		return
+	}
+
+	var rec [recordHeaderSize]byte
+	rec[0] = kind
+	binary.BigEndian.PutUint32(rec[1:5], w.channel)
+	binary.BigEndian.PutUint64(rec[5:13], uint64(ts))
+	binary.BigEndian.PutUint16(rec[13:15], uint16(len(data)))
+	w.buf = append(w.buf, rec[:]...)
+	w.buf = append(w.buf, data...)
+
+	if len(w.buf) >= flushSize {
+		w.flushLocked()
+	}
+}
+
+// Flush writes buffered records to the file
+func (w *Writer) Flush() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.flushLocked()
+	return w.err
+}
+
+// flushLocked writes the buffer in a single write so records from other
+// bridges appending to the same file never interleave with a partial record
+func (w *Writer) flushLocked() {
+	if len(w.buf) == 0 || w.err != nil {
+		return
+	}
+	if _, err := w.file.Write(w.buf); err != nil {
+		w.err = fmt.Errorf("failed to write recording: %w", err)
+	}
+	w.buf = w.buf[:0]
+}
+
+// flushPeriodically bounds how long records stay buffered
+func (w *Writer) flushPeriodically() {
+	defer w.wg.Done()
+
+	ticker := time.NewTicker(flushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-w.done:
+			return
+		case <-ticker.C:
+			_ = w.Flush()
+		}
+	}
+}
+
+// Close flushes buffered records and closes the file
+func (w *Writer) Close() error {
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		return nil
+	}
+	w.closed = true
+	w.mu.Unlock()
+
+	close(w.done)
+	w.wg.Wait()
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.flushLocked()
+	if err := w.file.Close(); err != nil && w.err == nil {
+		w.err = err
+	}
+	return w.err
+}
+
+// Record is one entry read from a recording
+type Record struct {
+	Kind    byte
+	Channel uint32
+	Time    time.Time
+	Data    []byte
+}
+
+// Reader reads records from a recording
+type Reader struct {
+	r io.Reader
+}
+
+// NewReader checks the recording header and returns a reader for its records
+func NewReader(r io.Reader) (*Reader, error) {
+	got := make([]byte, len(header))
+	if _, err := io.ReadFull(r, got); err != nil || string(got) != string(header) {
+		return nil, ErrNotRecording
+	}
+	return &Reader{r: r}, nil
+}
+
+// Next returns the next record, or io.EOF at the end of the recording.
+// A record truncated by an interrupted write is reported as io.EOF.
+func (r *Reader) Next() (Record, error) {
+	var rec [recordHeaderSize]byte
+	if _, err := io.ReadFull(r.r, rec[:]); err != nil {
+		if err == io.ErrUnexpectedEOF {
+			err = io.EOF
+		}
+		return Record{}, err
+	}
+
+	data := make([]byte, binary.BigEndian.Uint16(rec[13:15]))
+	if _, err := io.ReadFull(r.r, data); err != nil {
+		if err == io.ErrUnexpectedEOF {
+			err = io.EOF
+		}
+		return Record{}, err
+	}
+
+	return Record{
+		Kind:    rec[0],
+		Channel: binary.BigEndian.Uint32(rec[1:5]),
+		Time:    time.UnixMicro(int64(binary.BigEndian.Uint64(rec[5:13]))),
+		Data:    data,
+	}, nil
+}
+
+// ChannelInfo decodes a channel declaration record
+func (rec Record) ChannelInfo() (ChannelInfo, error) {
+	var info ChannelInfo
+	if rec.Kind != KindChannel {
+		return info, fmt.Errorf("record is not a channel declaration")
+	}
+	err := json.Unmarshal(rec.Data, &info)
+	return info, err
+}
+
+// WriteTlog writes a frame in tlog format: a big-endian Unix µs timestamp
+// followed by the raw MAVLink packet
+func WriteTlog(w io.Writer, t time.Time, frame []byte) error {
+	var ts [8]byte
+	binary.BigEndian.PutUint64(ts[:], uint64(t.UnixMicro()))
+	if _, err := w.Write(ts[:]); err != nil {
+		return err
+	}
+	_, err := w.Write(frame)
+	return err
+}
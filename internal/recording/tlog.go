@@ -0,0 +1,60 @@
+package recording
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"io"
+	"time"
+
+	"github.com/pavliha/aircast/aircast-cli/internal/mavlink"
+)
+
+// tlogHeaderSize is the longest frame header needed to know a frame's length
+const tlogHeaderSize = 3
+
+// ErrNotTlog is returned when a file doesn't hold timestamped MAVLink frames
+var ErrNotTlog = errors.New("not a MAVLink tlog")
+
+// TlogReader reads frames from a tlog, as written by WriteTlog and by ground
+// stations such as Mission Planner
+type TlogReader struct {
+	r *bufio.Reader
+}
+
+// NewTlogReader returns a reader for the frames of a tlog
+func NewTlogReader(r io.Reader) *TlogReader {
+	return &TlogReader{r: bufio.NewReader(r)}
+}
+
+// Next returns the next frame and its timestamp, or io.EOF at the end of the
+// log. A frame truncated by an interrupted write is reported as io.EOF.
+func (t *TlogReader) Next() (time.Time, []byte, error) {
+	var ts [8]byte
+	if _, err := io.ReadFull(t.r, ts[:]); err != nil {
+		return time.Time{}, nil, truncated(err)
+	}
+
+	header, err := t.r.Peek(tlogHeaderSize)
+	if err != nil {
+		return time.Time{}, nil, truncated(err)
+	}
+	n := mavlink.FrameLength(header)
+	if n == 0 {
+		return time.Time{}, nil, ErrNotTlog
+	}
+
+	frame := make([]byte, n)
+	if _, err := io.ReadFull(t.r, frame); err != nil {
+		return time.Time{}, nil, truncated(err)
+	}
+	return time.UnixMicro(int64(binary.BigEndian.Uint64(ts[:]))), frame, nil
+}
+
+// truncated reports a partial read at the end of the log as io.EOF
+func truncated(err error) error {
+	if err == io.ErrUnexpectedEOF {
+		return io.EOF
+	}
+	return err
+}
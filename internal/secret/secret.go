@@ -0,0 +1,140 @@
+// Package secret reads secrets from where the user keeps them rather than
+// from plaintext configuration or flags that end up in shell history: an
+// environment variable, a file, an inherited file descriptor, or a prompt
+// on the terminal without echo.
+package secret
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+
+	xterm "github.com/charmbracelet/x/term"
+)
+
+// Reference forms accepted wherever a secret is configured
+const (
+	EnvPrefix  = "env:"   // env:NAME reads environment variable NAME
+	FilePrefix = "file:"  // file:PATH reads the file at PATH
+	FDPrefix   = "fd:"    // fd:N reads inherited file descriptor N until EOF
+	Prompt     = "prompt" // Asks on the terminal without echo
+)
+
+// ErrNoTerminal is returned for a prompt when stdin isn't a terminal, e.g.
+// under --daemon or a service manager
+var ErrNoTerminal = errors.New("no terminal to prompt on")
+
+var (
+	mu sync.Mutex
+	// once holds secrets read from descriptors and prompts, which can only
+	// be read once per process, by reference and label
+	once = map[string]string{}
+)
+
+// IsReference reports whether value refers to a secret instead of being one
+func IsReference(value string) bool {
+	return value == Prompt ||
+		strings.HasPrefix(value, EnvPrefix) ||
+		strings.HasPrefix(value, FilePrefix) ||
+		strings.HasPrefix(value, FDPrefix)
+}
+
+// Resolve returns the secret value refers to, or value itself if it isn't a
+// reference. label names the secret in prompts and errors, e.g. "webhook
+// secret for https://hooks.example.com".
+func Resolve(value, label string) (string, error) {
+	switch {
+	case strings.HasPrefix(value, EnvPrefix):
+		name := strings.TrimPrefix(value, EnvPrefix)
+		v := os.Getenv(name)
+		if v == "" {
+			return "", fmt.Errorf("%s: environment variable %s is not set", label, name)
+		}
+		return v, nil
+
+	case strings.HasPrefix(value, FilePrefix):
+		path := strings.TrimPrefix(value, FilePrefix)
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return "", fmt.Errorf("%s: %w", label, err)
+		}
+		return nonEmpty(trimNewline(string(data)), label, path)
+
+	case strings.HasPrefix(value, FDPrefix):
+		return readOnce(value, func() (string, error) {
+			n, err := strconv.Atoi(strings.TrimPrefix(value, FDPrefix))
+			if err != nil || n < 0 {
+				return "", fmt.Errorf("%s: invalid file descriptor in %q", label, value)
+			}
+			f := os.NewFile(uintptr(n), "fd "+strconv.Itoa(n))
+			if f == nil {
+				return "", fmt.Errorf("%s: file descriptor %d is not open", label, n)
+			}
+			defer f.Close()
+			data, err := io.ReadAll(f)
+			if err != nil {
+				return "", fmt.Errorf("%s: failed to read file descriptor %d: %w", label, n, err)
+			}
+			return nonEmpty(trimNewline(string(data)), label, f.Name())
+		})
+
+	case value == Prompt:
+		return readOnce(value+"\x00"+label, func() (string, error) {
+			return Read(label)
+		})
+	}
+	return value, nil
+}
+
+// Read asks for a secret on the terminal without echoing it
+func Read(label string) (string, error) {
+	fd := os.Stdin.Fd()
+	if !xterm.IsTerminal(fd) {
+		return "", fmt.Errorf("%s: %w", label, ErrNoTerminal)
+	}
+
+	fmt.Fprintf(os.Stderr, "%s: ", label)
+	data, err := xterm.ReadPassword(fd)
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", label, err)
+	}
+	if len(data) == 0 {
+		return "", fmt.Errorf("%s: nothing entered", label)
+	}
+	return string(data), nil
+}
+
+// readOnce reads a secret the first time key is asked for and returns the
+// same secret afterwards
+func readOnce(key string, read func() (string, error)) (string, error) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	if v, ok := once[key]; ok {
+		return v, nil
+	}
+	v, err := read()
+	if err != nil {
+		return "", err
+	}
+	once[key] = v
+	return v, nil
+}
+
+// trimNewline removes the line ending editors and echo leave at the end
+func trimNewline(s string) string {
+	return strings.TrimRight(s, "\r\n")
+}
+
+// nonEmpty rejects an empty secret, which is a mistake rather than a secret
+func nonEmpty(v, label, source string) (string, error) {
+	if v == "" {
+		return "", fmt.Errorf("%s: %s is empty", label, source)
+	}
+	return v, nil
+}
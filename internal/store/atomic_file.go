@@ -0,0 +1,79 @@
+package store
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const (
+	lockWait  = 30 * time.Second      // How long to wait for another aircast-cli holding a lock
+	lockRetry = 50 * time.Millisecond // Pause between attempts to take a lock
+)
+
+// errLocked is a lock held by another process
+var errLocked = errors.New("locked")
+
+// WriteFileAtomic replaces a file by writing a temporary file next to it and
+// renaming it over the original, so readers and a crash mid-write never see a
+// partial file
+func WriteFileAtomic(path string, data []byte, perm os.FileMode) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+"-*")
+	if err != nil {
+		return err
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+
+	// Chmod rather than the create mode, which the umask would narrow
+	_, err = tmp.Write(data)
+	if err == nil {
+		err = tmp.Sync()
+	}
+	if err == nil {
+		err = tmp.Chmod(perm)
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// LockFile takes an exclusive advisory lock next to path, so that aircast-cli
+// processes in other terminals don't interleave their read-modify-write of
+// the file. It waits up to lockWait for another holder and returns the
+// function that releases the lock.
+func LockFile(path string, perm os.FileMode) (func(), error) {
+	lockPath := path + ".lock"
+	f, err := os.OpenFile(lockPath, os.O_RDWR|os.O_CREATE, perm)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open lock file: %w", err)
+	}
+	// Let the rest of the group lock the shared store's files too
+	_ = f.Chmod(perm)
+
+	deadline := time.Now().Add(lockWait)
+	for {
+		err = tryLock(f)
+		if err == nil {
+			break
+		}
+		if !errors.Is(err, errLocked) || time.Now().After(deadline) {
+			_ = f.Close()
+			if errors.Is(err, errLocked) {
+				return nil, fmt.Errorf("%s is locked by another aircast-cli process", filepath.Base(path))
+			}
+			return nil, fmt.Errorf("failed to lock %s: %w", filepath.Base(path), err)
+		}
+		time.Sleep(lockRetry)
+	}
+
+	return func() {
+		_ = unlock(f)
+		_ = f.Close()
+	}, nil
+}
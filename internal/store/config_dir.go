@@ -0,0 +1,58 @@
+// Package store keeps the CLI's local state in the config directory
+// (~/.aircast): flight logbooks and folders, session statistics and
+// telemetry snapshots, along with the locking and atomic writes that the
+// other stores there use too.
+package store
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// configDirOverride replaces ~/.aircast when set with SetConfigDir
+var configDirOverride string
+
+// SetConfigDir keeps all state (login, config, caches, logs, control socket)
+// in dir instead of ~/.aircast, e.g. for per-user stores on a shared ground
+// station or an install on a USB drive
+func SetConfigDir(dir string) error {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return fmt.Errorf("invalid config directory %q: %w", dir, err)
+	}
+	configDirOverride = abs
+	return nil
+}
+
+// ConfigDirPath returns the config directory without creating it
+func ConfigDirPath() (string, error) {
+	if configDirOverride != "" {
+		return configDirOverride, nil
+	}
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	return filepath.Join(homeDir, ".aircast"), nil
+}
+
+// ensureConfigDir returns the config directory (~/.aircast), creating it if needed
+func ensureConfigDir() (string, error) {
+	configDir, err := ConfigDirPath()
+	if err != nil {
+		return "", err
+	}
+
+	// Create directory if it doesn't exist
+	if err := os.MkdirAll(configDir, 0700); err != nil {
+		return "", fmt.Errorf("failed to create config directory: %w", err)
+	}
+
+	return configDir, nil
+}
+
+// ConfigDir returns the config directory (~/.aircast), creating it if needed
+func ConfigDir() (string, error) {
+	return ensureConfigDir()
+}
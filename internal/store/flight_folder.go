@@ -0,0 +1,198 @@
+package store
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// flightFolderLayout names session folders so they sort chronologically
+const flightFolderLayout = "2006-01-02_15-04-05"
+
+// flightIndexName is the manifest in each flight folder
+const flightIndexName = "index.json"
+
+// FlightFolder collects the artifacts of one bridge session, such as
+// recordings, under flights/<device>/<timestamp>/ in the config directory,
+// described by an index.json manifest. The folder is only created once the
+// first artifact is added.
+type FlightFolder struct {
+	path string
+
+	mu      sync.Mutex
+	index   FlightIndex
+	created bool
+}
+
+// FlightIndex is the index.json manifest of a flight folder
+type FlightIndex struct {
+	DeviceID   string           `json:"device_id"`
+	DeviceName string           `json:"device_name,omitempty"`
+	StartedAt  time.Time        `json:"started_at"`
+	EndedAt    *time.Time       `json:"ended_at,omitempty"` // Unset while the session runs
+	Artifacts  []FlightArtifact `json:"artifacts"`
+	Flights    []Flight         `json:"flights,omitempty"`
+
+	// Path is the folder the manifest was read from
+	Path string `json:"-"`
+}
+
+// FlightArtifact is a file in a flight folder
+type FlightArtifact struct {
+	Name    string    `json:"name"`
+	Kind    string    `json:"kind"` // e.g. "recording"
+	AddedAt time.Time `json:"added_at"`
+}
+
+// NewFlightFolder prepares the folder for a session of a device started at startedAt
+func NewFlightFolder(deviceID, deviceName string, startedAt time.Time) (*FlightFolder, error) {
+	root, err := flightsRoot()
+	if err != nil {
+		return nil, err
+	}
+
+	return &FlightFolder{
+		path: filepath.Join(root, folderName(deviceID), startedAt.Local().Format(flightFolderLayout)),
+		index: FlightIndex{
+			DeviceID:   deviceID,
+			DeviceName: deviceName,
+			StartedAt:  startedAt.UTC(),
+			Artifacts:  []FlightArtifact{},
+		},
+	}, nil
+}
+
+// Path returns the folder's location
+func (f *FlightFolder) Path() string {
+	return f.path
+}
+
+// Created reports whether any artifact has been added, creating the folder
+func (f *FlightFolder) Created() bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.created
+}
+
+// Add registers an artifact and returns the path to write it to
+func (f *FlightFolder) Add(name, kind string) (string, error) {
+	if name != filepath.Base(name) || name == "." || name == ".." {
+		return "", fmt.Errorf("invalid artifact name %q", name)
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := os.MkdirAll(f.path, 0700); err != nil {
+		return "", fmt.Errorf("failed to create flight folder: %w", err)
+	}
+	f.created = true
+
+	f.index.Artifacts = append(f.index.Artifacts, FlightArtifact{Name: name, Kind: kind, AddedAt: time.Now().UTC()})
+	if err := f.writeIndex(); err != nil {
+		return "", err
+	}
+	return filepath.Join(f.path, name), nil
+}
+
+// AddFlight lists a flight of this session in the manifest
+func (f *FlightFolder) AddFlight(flight Flight) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.index.Flights = append(f.index.Flights, flight)
+	if !f.created {
+		return nil
+	}
+	return f.writeIndex()
+}
+
+// Close records the end of the session in the manifest
+func (f *FlightFolder) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	now := time.Now().UTC()
+	f.index.EndedAt = &now
+	if !f.created {
+		return nil
+	}
+	return f.writeIndex()
+}
+
+// writeIndex saves the manifest. Caller must hold mu.
+func (f *FlightFolder) writeIndex() error {
+	data, err := json.MarshalIndent(f.index, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal flight index: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(f.path, flightIndexName), data, 0600); err != nil {
+		return fmt.Errorf("failed to write flight index: %w", err)
+	}
+	return nil
+}
+
+// ListFlightFolders returns the manifests of all flight folders, oldest
+// first, optionally for one device
+func ListFlightFolders(deviceID string) ([]FlightIndex, error) {
+	root, err := flightsRoot()
+	if err != nil {
+		return nil, err
+	}
+
+	pattern := filepath.Join(root, "*", "*", flightIndexName)
+	if deviceID != "" {
+		pattern = filepath.Join(root, folderName(deviceID), "*", flightIndexName)
+	}
+	paths, err := filepath.Glob(pattern)
+	if err != nil {
+		return nil, err
+	}
+
+	var indexes []FlightIndex
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		var index FlightIndex
+		if err := json.Unmarshal(data, &index); err != nil {
+			continue
+		}
+		index.Path = filepath.Dir(path)
+		indexes = append(indexes, index)
+	}
+
+	sort.Slice(indexes, func(i, j int) bool {
+		return indexes[i].StartedAt.Before(indexes[j].StartedAt)
+	})
+	return indexes, nil
+}
+
+// flightsRoot returns the directory holding all flight folders
+func flightsRoot() (string, error) {
+	configDir, err := ensureConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(configDir, "flights"), nil
+}
+
+// folderName makes a device ID safe to use as a directory name
+func folderName(deviceID string) string {
+	name := strings.Map(func(r rune) rune {
+		if r == '/' || r == '\\' || r == ':' || r < ' ' {
+			return '_'
+		}
+		return r
+	}, deviceID)
+	if name == "" || name == "." || name == ".." {
+		return "_"
+	}
+	return name
+}
@@ -0,0 +1,97 @@
+package store
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// FlightLog is a per-device logbook of armed periods, kept in flights.jsonl
+type FlightLog struct {
+	configDir string
+}
+
+// Flight is one armed period of a vehicle
+type Flight struct {
+	DeviceID   string    `json:"device_id"`
+	DeviceName string    `json:"device_name,omitempty"`
+	ArmedAt    time.Time `json:"armed_at"`
+	DisarmedAt time.Time `json:"disarmed_at"`
+	// Incomplete is set when the bridge stopped while the vehicle was still
+	// armed; DisarmedAt is then the end of the session
+	Incomplete bool `json:"incomplete,omitempty"`
+	// Folder is the session's flight folder, if it kept any artifacts
+	Folder string `json:"folder,omitempty"`
+}
+
+// Duration returns how long the vehicle was armed
+func (f Flight) Duration() time.Duration {
+	return f.DisarmedAt.Sub(f.ArmedAt)
+}
+
+// NewFlightLog creates a new flight log store
+func NewFlightLog() (*FlightLog, error) {
+	configDir, err := ensureConfigDir()
+	if err != nil {
+		return nil, err
+	}
+
+	return &FlightLog{
+		configDir: configDir,
+	}, nil
+}
+
+// GetFlightLogPath returns the path to the flight log file
+func (fl *FlightLog) GetFlightLogPath() string {
+	return filepath.Join(fl.configDir, "flights.jsonl")
+}
+
+// Append adds a flight to the log
+func (fl *FlightLog) Append(flight Flight) error {
+	line, err := json.Marshal(flight)
+	if err != nil {
+		return fmt.Errorf("failed to marshal flight: %w", err)
+	}
+
+	f, err := os.OpenFile(fl.GetFlightLogPath(), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open flight log: %w", err)
+	}
+	defer f.Close()
+
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("failed to write flight log: %w", err)
+	}
+	return nil
+}
+
+// List returns logged flights, oldest first, optionally for one device
+func (fl *FlightLog) List(deviceID string) ([]Flight, error) {
+	f, err := os.Open(fl.GetFlightLogPath())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read flight log: %w", err)
+	}
+	defer f.Close()
+
+	var flights []Flight
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var flight Flight
+		if err := json.Unmarshal(scanner.Bytes(), &flight); err != nil {
+			continue
+		}
+		if deviceID == "" || flight.DeviceID == deviceID {
+			flights = append(flights, flight)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read flight log: %w", err)
+	}
+	return flights, nil
+}
@@ -0,0 +1,158 @@
+package store
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+)
+
+// StatsMonths is how many calendar months of session records are kept,
+// the current one included: enough to compare a month with the same month
+// a year earlier
+const StatsMonths = 13
+
+// MonthsStart returns the start of the calendar month n-1 months before
+// now's, so the last n months include the current one
+func MonthsStart(now time.Time, n int) time.Time {
+	return time.Date(now.Year(), now.Month()-time.Month(n-1), 1, 0, 0, 0, 0, now.Location())
+}
+
+// SessionRecord is the statistics of one bridge session kept for history
+type SessionRecord struct {
+	StartedAt  time.Time     `json:"started_at"`
+	EndedAt    time.Time     `json:"ended_at"`
+	DeviceID   string        `json:"device_id"`
+	DeviceName string        `json:"device_name,omitempty"`
+	Site       string        `json:"site,omitempty"`
+	BytesUp    uint64        `json:"bytes_up"`
+	BytesDown  uint64        `json:"bytes_down"`
+	Frames     uint64        `json:"frames"` // Downlink frames received
+	Lost       uint64        `json:"lost"`   // Downlink frames estimated lost
+	Reconnects uint64        `json:"reconnects"`
+	RTT        time.Duration `json:"rtt,omitempty"` // Smoothed WebSocket round trip at the end
+}
+
+// Duration returns how long the session ran
+func (r SessionRecord) Duration() time.Duration {
+	return r.EndedAt.Sub(r.StartedAt)
+}
+
+// LossRate returns the estimated fraction of downlink frames lost
+func (r SessionRecord) LossRate() float64 {
+	if r.Frames+r.Lost == 0 {
+		return 0
+	}
+	return float64(r.Lost) / float64(r.Frames+r.Lost)
+}
+
+// StatsDB keeps per-session statistics for StatsMonths, one JSON record per line,
+// unlike SessionHistory which keeps the last few sessions in full detail
+type StatsDB struct {
+	configDir string
+}
+
+// NewStatsDB creates a new session statistics store
+func NewStatsDB() (*StatsDB, error) {
+	configDir, err := ensureConfigDir()
+	if err != nil {
+		return nil, err
+	}
+
+	return &StatsDB{
+		configDir: configDir,
+	}, nil
+}
+
+// GetPath returns the path to the statistics file
+func (db *StatsDB) GetPath() string {
+	return filepath.Join(db.configDir, "stats.jsonl")
+}
+
+// Add appends a session record, dropping records beyond the retention period
+func (db *StatsDB) Add(rec SessionRecord) error {
+	line, err := json.Marshal(rec)
+	if err != nil {
+		return fmt.Errorf("failed to marshal session record: %w", err)
+	}
+
+	// Bridges ending together mustn't interleave their rewrites
+	unlock, err := LockFile(db.GetPath(), 0600)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	records, err := db.All()
+	if err != nil {
+		return err
+	}
+	cutoff := MonthsStart(time.Now(), StatsMonths)
+	if slices.ContainsFunc(records, func(r SessionRecord) bool { return r.StartedAt.Before(cutoff) }) {
+		return db.rewrite(append(records, rec), cutoff)
+	}
+
+	f, err := os.OpenFile(db.GetPath(), os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open session statistics: %w", err)
+	}
+	defer f.Close()
+
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("failed to write session statistics: %w", err)
+	}
+	return nil
+}
+
+// rewrite replaces the file with the records started after cutoff. The
+// caller holds the file's lock.
+func (db *StatsDB) rewrite(records []SessionRecord, cutoff time.Time) error {
+	var buf bytes.Buffer
+	for _, rec := range records {
+		if rec.StartedAt.Before(cutoff) {
+			continue
+		}
+		line, err := json.Marshal(rec)
+		if err != nil {
+			return fmt.Errorf("failed to marshal session record: %w", err)
+		}
+		buf.Write(line)
+		buf.WriteByte('\n')
+	}
+
+	if err := WriteFileAtomic(db.GetPath(), buf.Bytes(), 0600); err != nil {
+		return fmt.Errorf("failed to write session statistics: %w", err)
+	}
+	return nil
+}
+
+// All returns every session record, oldest first
+func (db *StatsDB) All() ([]SessionRecord, error) {
+	f, err := os.Open(db.GetPath())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read session statistics: %w", err)
+	}
+	defer f.Close()
+
+	var records []SessionRecord
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var rec SessionRecord
+		// Skip lines torn by a crash mid-write rather than losing the history
+		if err := json.Unmarshal(scanner.Bytes(), &rec); err != nil {
+			continue
+		}
+		records = append(records, rec)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read session statistics: %w", err)
+	}
+	return records, nil
+}
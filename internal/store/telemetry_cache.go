@@ -0,0 +1,103 @@
+package store
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// TelemetryCache remembers the last vehicle state seen on each device, so
+// the picker can tell an airframe that is merely powered off from one that
+// hasn't flown in months
+type TelemetryCache struct {
+	configDir string
+}
+
+// TelemetrySnapshot is the last known state of a device's vehicle
+type TelemetrySnapshot struct {
+	Battery    int       `json:"battery"` // Remaining percentage, -1 if unknown
+	Firmware   string    `json:"firmware,omitempty"`
+	FlightMode string    `json:"flight_mode,omitempty"`
+	SeenAt     time.Time `json:"seen_at"`
+}
+
+// String formats the snapshot as a single line, e.g. "🔋 76% • ArduPilot 4.5.1 • LOITER"
+func (s TelemetrySnapshot) String() string {
+	var parts []string
+	if s.Battery >= 0 {
+		parts = append(parts, fmt.Sprintf("🔋 %d%%", s.Battery))
+	}
+	if s.Firmware != "" {
+		parts = append(parts, s.Firmware)
+	}
+	if s.FlightMode != "" {
+		parts = append(parts, s.FlightMode)
+	}
+	if len(parts) == 0 {
+		return "no telemetry"
+	}
+	return strings.Join(parts, " • ")
+}
+
+// NewTelemetryCache creates a new telemetry cache
+func NewTelemetryCache() (*TelemetryCache, error) {
+	configDir, err := ensureConfigDir()
+	if err != nil {
+		return nil, err
+	}
+
+	return &TelemetryCache{
+		configDir: configDir,
+	}, nil
+}
+
+// GetCachePath returns the path to the telemetry cache file
+func (tc *TelemetryCache) GetCachePath() string {
+	return filepath.Join(tc.configDir, "telemetry.json")
+}
+
+// Load returns the snapshots by device ID
+func (tc *TelemetryCache) Load() (map[string]TelemetrySnapshot, error) {
+	data, err := os.ReadFile(tc.GetCachePath())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return map[string]TelemetrySnapshot{}, nil
+		}
+		return nil, fmt.Errorf("failed to read telemetry cache: %w", err)
+	}
+
+	snapshots := map[string]TelemetrySnapshot{}
+	if err := json.Unmarshal(data, &snapshots); err != nil {
+		return nil, fmt.Errorf("failed to parse telemetry cache: %w", err)
+	}
+	return snapshots, nil
+}
+
+// Save records a device's snapshot, replacing the previous one
+func (tc *TelemetryCache) Save(deviceID string, snapshot TelemetrySnapshot) error {
+	unlock, err := LockFile(tc.GetCachePath(), 0600)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	snapshots, err := tc.Load()
+	if err != nil {
+		// A corrupt cache only loses old snapshots; start over
+		snapshots = map[string]TelemetrySnapshot{}
+	}
+	snapshots[deviceID] = snapshot
+
+	data, err := json.MarshalIndent(snapshots, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal telemetry cache: %w", err)
+	}
+
+	if err := WriteFileAtomic(tc.GetCachePath(), data, 0600); err != nil {
+		return fmt.Errorf("failed to write telemetry cache: %w", err)
+	}
+	return nil
+}
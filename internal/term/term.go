@@ -0,0 +1,122 @@
+// Package term adapts console output to the terminal it is written to:
+// its width and whether it can display UTF-8 box drawing and emoji.
+package term
+
+import (
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+	xterm "github.com/charmbracelet/x/term"
+	"github.com/muesli/termenv"
+)
+
+const (
+	defaultWidth = 80 // Assumed when the width can't be determined
+	bannerWidth  = 65 // Width of banners on wide terminals
+	minBoxWidth  = 20 // Below this, banners are drawn without a box
+)
+
+// accessible is set by SetAccessible
+var accessible bool
+
+// SetAccessible switches console output to a screen-reader friendly form:
+// no colors, box drawing or emoji, and no full-screen interfaces
+func SetAccessible() {
+	accessible = true
+	lipgloss.SetColorProfile(termenv.Ascii)
+}
+
+// Accessible reports whether screen-reader friendly output was requested
+func Accessible() bool {
+	return accessible
+}
+
+// Width returns the current width of the terminal on stdout in columns,
+// falling back to $COLUMNS and then 80 columns
+func Width() int {
+	if w, _, err := xterm.GetSize(os.Stdout.Fd()); err == nil && w > 0 {
+		return w
+	}
+	if w, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && w > 0 {
+		return w
+	}
+	return defaultWidth
+}
+
+// UTF8 reports whether the terminal can display UTF-8. Serial consoles and
+// PuTTY sessions often run with no locale or a legacy one, where box
+// drawing and emoji turn into garbage.
+var UTF8 = sync.OnceValue(func() bool {
+	if runtime.GOOS == "windows" {
+		return true
+	}
+	if os.Getenv("TERM") == "dumb" {
+		return false
+	}
+	for _, name := range []string{"LC_ALL", "LC_CTYPE", "LANG"} {
+		if locale := os.Getenv(name); locale != "" {
+			locale = strings.ToLower(locale)
+			return strings.Contains(locale, "utf-8") || strings.Contains(locale, "utf8")
+		}
+	}
+	return false
+})
+
+// Symbol returns s, or ascii on terminals that can't display UTF-8 and in
+// accessible mode
+func Symbol(s, ascii string) string {
+	if UTF8() && !accessible {
+		return s
+	}
+	return ascii
+}
+
+// Truncate shortens s to at most width columns, ending it with "..."
+func Truncate(s string, width int) string {
+	if width <= 0 || lipgloss.Width(s) <= width {
+		return s
+	}
+	if width <= 3 {
+		return ansi.Truncate(s, width, "")
+	}
+	return ansi.Truncate(s, width, "...")
+}
+
+// Banner returns title in a box sized to the terminal, or underlined when
+// the terminal is too narrow for a box. In accessible mode it is just the
+// title.
+func Banner(title string) string {
+	if accessible {
+		return title
+	}
+
+	width := min(Width()-1, bannerWidth)
+	titleWidth := lipgloss.Width(title)
+
+	if width < minBoxWidth || titleWidth > width-4 {
+		rule := strings.Repeat(Symbol("═", "="), min(titleWidth, width))
+		return title + "\n" + rule
+	}
+
+	topLeft, topRight, bottomLeft, bottomRight := "╔", "╗", "╚", "╝"
+	horizontal, vertical := "═", "║"
+	if !UTF8() {
+		topLeft, topRight, bottomLeft, bottomRight = "+", "+", "+", "+"
+		horizontal, vertical = "-", "|"
+	}
+
+	inner := width - 2
+	left := (inner - titleWidth) / 2
+	right := inner - titleWidth - left
+
+	var b strings.Builder
+	b.WriteString(topLeft + strings.Repeat(horizontal, inner) + topRight + "\n")
+	b.WriteString(vertical + strings.Repeat(" ", left) + title + strings.Repeat(" ", right) + vertical + "\n")
+	b.WriteString(bottomLeft + strings.Repeat(horizontal, inner) + bottomRight)
+	return b.String()
+}
@@ -0,0 +1,164 @@
+package tsdb
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// influxWriter writes points to InfluxDB in line protocol, one measurement
+// per message tagged with the device and MAVLink source
+type influxWriter struct {
+	client   *http.Client
+	endpoint string
+	token    string
+	user     *url.Userinfo
+}
+
+// newInfluxWriter builds the write endpoint: /write for 1.x databases and
+// /api/v2/write for 2.x and 3.x buckets
+func newInfluxWriter(config Config) (*influxWriter, error) {
+	base, err := url.Parse(config.URL)
+	if err != nil || (base.Scheme != "http" && base.Scheme != "https") || base.Host == "" {
+		return nil, fmt.Errorf("influxdb sink: invalid url %q", redactURL(config.URL))
+	}
+
+	w := &influxWriter{client: &http.Client{}, token: config.Token, user: base.User}
+	base.User = nil
+
+	query := url.Values{"precision": {"ns"}}
+	if config.Database != "" {
+		base = base.JoinPath("write")
+		query.Set("db", config.Database)
+	} else {
+		base = base.JoinPath("api", "v2", "write")
+		query.Set("bucket", config.Bucket)
+		if config.Org != "" {
+			query.Set("org", config.Org)
+		}
+	}
+	base.RawQuery = query.Encode()
+	w.endpoint = base.String()
+	return w, nil
+}
+
+func (w *influxWriter) write(ctx context.Context, device Device, points []Point) error {
+	var body bytes.Buffer
+	for _, p := range points {
+		appendLine(&body, device, p)
+	}
+	if body.Len() == 0 {
+		return nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", w.endpoint, &body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "text/plain; charset=utf-8")
+	if w.token != "" {
+		req.Header.Set("Authorization", "Token "+w.token)
+	} else if w.user != nil {
+		password, _ := w.user.Password()
+		req.SetBasicAuth(w.user.Username(), password)
+	}
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode/100 != 2 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		err := fmt.Errorf("influxdb returned %s: %s", resp.Status, strings.TrimSpace(string(msg)))
+		if resp.StatusCode/100 == 4 && resp.StatusCode != http.StatusRequestTimeout && resp.StatusCode != http.StatusTooManyRequests {
+			return fmt.Errorf("%w: %w", errRejected, err)
+		}
+		return err
+	}
+	return nil
+}
+
+func (w *influxWriter) close() error {
+	w.client.CloseIdleConnections()
+	return nil
+}
+
+// appendLine writes a point in line protocol, e.g.
+// GLOBAL_POSITION_INT,device_id=abc,sys_id=1,comp_id=1 lat=504500000i,lon=305200000i 1700000000000000000
+func appendLine(buf *bytes.Buffer, device Device, p Point) {
+	start := buf.Len()
+
+	buf.WriteString(escapeInflux(p.Message, ", "))
+	buf.WriteString(",device_id=" + escapeInflux(device.ID, ",= "))
+	if device.Name != "" {
+		buf.WriteString(",device=" + escapeInflux(device.Name, ",= "))
+	}
+	fmt.Fprintf(buf, ",sys_id=%d,comp_id=%d", p.SysID, p.CompID)
+
+	sep := byte(' ')
+	for _, v := range p.Values {
+		value, ok := influxValue(v)
+		if !ok {
+			continue
+		}
+		buf.WriteByte(sep)
+		buf.WriteString(escapeInflux(v.Name, ",= "))
+		buf.WriteByte('=')
+		buf.WriteString(value)
+		sep = ','
+	}
+	if sep == ' ' {
+		// Nothing representable; a line needs at least one field
+		buf.Truncate(start)
+		return
+	}
+
+	buf.WriteByte(' ')
+	buf.WriteString(strconv.FormatInt(p.Time.UnixNano(), 10))
+	buf.WriteByte('\n')
+}
+
+// influxValue formats a field value: integers with the i suffix, floats as
+// is and char arrays as single-line strings. NaN, infinities and integers
+// beyond int64 have no line protocol form.
+func influxValue(v Value) (string, bool) {
+	switch v.Type {
+	case "char":
+		return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", " ", "\r", " ").Replace(v.Value) + `"`, true
+	case "float", "double":
+		f, err := strconv.ParseFloat(v.Value, 64)
+		if err != nil || math.IsNaN(f) || math.IsInf(f, 0) {
+			return "", false
+		}
+		return v.Value, true
+	default:
+		if _, err := strconv.ParseInt(v.Value, 10, 64); err != nil {
+			return "", false
+		}
+		return v.Value + "i", true
+	}
+}
+
+// escapeInflux backslash-escapes the given characters in a line protocol
+// measurement, tag or field key
+func escapeInflux(s, chars string) string {
+	if !strings.ContainsAny(s, chars) {
+		return s
+	}
+	var b strings.Builder
+	for _, r := range s {
+		if strings.ContainsRune(chars, r) {
+			b.WriteByte('\\')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
@@ -0,0 +1,369 @@
+// Package tsdb writes selected decoded telemetry fields to time-series
+// databases during a session, for teams building their own flight analytics.
+// Sinks are bridge outputs: they receive a copy of the device's frames and
+// write to InfluxDB (line protocol over HTTP) or PostgreSQL/TimescaleDB in
+// the background, so a slow or unreachable database never stalls the bridge.
+package tsdb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/pavliha/aircast/aircast-cli/internal/mavlink"
+	"github.com/pavliha/aircast/aircast-cli/internal/settings"
+	log "github.com/sirupsen/logrus"
+)
+
+// Sink types
+const (
+	TypeInfluxDB = "influxdb"
+	TypePostgres = "postgres"
+)
+
+const (
+	queueSize     = 1024             // Points waiting for the writer before new ones are dropped
+	batchSize     = 500              // Points that trigger a write
+	flushInterval = 1 * time.Second  // Longest time points wait for a write
+	writeTimeout  = 10 * time.Second // Bound on one write
+	maxPending    = 20000            // Points kept for retry while the database is unreachable
+	closeTimeout  = 5 * time.Second  // Time given to the final write on shutdown
+)
+
+// Config is a telemetry sink, configured in config.json's "telemetry_sinks"
+type Config = settings.TelemetrySink
+
+// errRejected marks a write the database refused for good, e.g. a type
+// mismatch or a missing permission. Retrying it can't succeed, so its
+// points are dropped.
+var errRejected = errors.New("write rejected")
+
+// DefaultTable is the Postgres table used when none is configured
+const DefaultTable = "aircast_telemetry"
+
+// Device identifies the device whose telemetry is written
+type Device struct {
+	ID   string
+	Name string
+}
+
+// Value is a decoded field value
+type Value struct {
+	Name  string
+	Type  string // MAVLink type, e.g. "int32_t", "float" or "char"
+	Value string
+}
+
+// Point is the selected fields of one message
+type Point struct {
+	Time    time.Time
+	Message string
+	SysID   uint8
+	CompID  uint8
+	Values  []Value
+}
+
+// writer stores points in a database
+type writer interface {
+	write(ctx context.Context, device Device, points []Point) error
+	close() error
+}
+
+// selection is the fields written for one message
+type selection struct {
+	name   string
+	fields []mavlink.Field
+	only   map[string]bool // Columns to keep, nil for all
+}
+
+// streamKey identifies a message stream for rate limiting
+type streamKey struct {
+	msgID  uint32
+	sysID  uint8
+	compID uint8
+}
+
+// Sink writes selected telemetry fields to a database. It implements the
+// bridge's OutputSink interface.
+type Sink struct {
+	device  Device
+	writer  writer
+	selects map[uint32]selection
+	minGap  time.Duration
+	logger  *log.Entry
+
+	mu   sync.Mutex
+	last map[streamKey]time.Time
+
+	points  chan Point
+	dropped atomic.Uint64
+	done    chan struct{}
+	closed  atomic.Bool
+	wg      sync.WaitGroup
+}
+
+// validate checks a sink's configuration
+func validate(c Config) error {
+	if c.URL == "" {
+		return fmt.Errorf("%s sink: missing url", c.Type)
+	}
+	if len(c.Fields) == 0 {
+		return fmt.Errorf("%s sink: no fields selected", c.Type)
+	}
+	if c.MaxRate < 0 {
+		return fmt.Errorf("%s sink: max_rate must not be negative", c.Type)
+	}
+	switch c.Type {
+	case TypeInfluxDB:
+		if (c.Database == "") == (c.Bucket == "") {
+			return fmt.Errorf("influxdb sink: set either database (1.x) or bucket (2.x, 3.x)")
+		}
+	case TypePostgres:
+	default:
+		return fmt.Errorf("unknown telemetry sink type %q (expected influxdb or postgres)", c.Type)
+	}
+	_, err := parseSelection(c.Fields)
+	return err
+}
+
+// Target describes where a sink writes, without credentials
+func Target(c Config) string {
+	target := redactURL(c.URL)
+	switch {
+	case c.Database != "":
+		target += " db=" + c.Database
+	case c.Bucket != "":
+		target += " bucket=" + c.Bucket
+	case c.Type == TypePostgres:
+		target += " table=" + table(c)
+	}
+	return target
+}
+
+// table returns a sink's Postgres table
+func table(c Config) string {
+	if c.Table == "" {
+		return DefaultTable
+	}
+	return c.Table
+}
+
+// redactURL hides the password in a URL
+func redactURL(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return "(invalid URL)"
+	}
+	return u.Redacted()
+}
+
+// parseSelection resolves "MESSAGE" and "MESSAGE.field" entries
+func parseSelection(entries []string) (map[uint32]selection, error) {
+	selects := make(map[uint32]selection)
+	for _, entry := range entries {
+		message, field, hasField := strings.Cut(strings.TrimSpace(entry), ".")
+		id, ok := mavlink.MessageID(message)
+		if !ok {
+			return nil, fmt.Errorf("unknown message %q in telemetry fields", message)
+		}
+		fields, _ := mavlink.MessageFields(id)
+
+		sel, seen := selects[id]
+		if !seen {
+			sel = selection{name: mavlink.MessageName(id), fields: fields, only: map[string]bool{}}
+		}
+		switch {
+		case !hasField:
+			sel.only = nil
+		case !hasColumn(fields, field):
+			return nil, fmt.Errorf("%s has no field %q", sel.name, field)
+		case sel.only != nil:
+			sel.only[field] = true
+		}
+		selects[id] = sel
+	}
+	return selects, nil
+}
+
+// hasColumn reports whether a message has a column, e.g. "lat" or "voltages[0]"
+func hasColumn(fields []mavlink.Field, column string) bool {
+	for _, f := range fields {
+		if f.Name == column {
+			return true
+		}
+		for _, c := range f.Columns() {
+			if c == column {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// New creates a sink for a device and starts its background writer
+func New(config Config, device Device, logger *log.Entry) (*Sink, error) {
+	if err := validate(config); err != nil {
+		return nil, err
+	}
+	selects, _ := parseSelection(config.Fields)
+
+	var w writer
+	var err error
+	switch config.Type {
+	case TypeInfluxDB:
+		w, err = newInfluxWriter(config)
+	case TypePostgres:
+		w, err = newPostgresWriter(config)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Sink{
+		device:  device,
+		writer:  w,
+		selects: selects,
+		logger:  logger.WithFields(log.Fields{"component": "tsdb", "sink": config.Type}),
+		last:    make(map[streamKey]time.Time),
+		points:  make(chan Point, queueSize),
+		done:    make(chan struct{}),
+	}
+	if config.MaxRate > 0 {
+		s.minGap = time.Duration(float64(time.Second) / config.MaxRate)
+	}
+
+	s.wg.Add(1)
+	go s.run()
+	return s, nil
+}
+
+// WriteFrame queues the selected fields of a downlink frame
+func (s *Sink) WriteFrame(raw []byte, uplink bool) {
+	if uplink || s.closed.Load() {
+		return
+	}
+	frame, err := mavlink.DecodeFrame(raw)
+	if err != nil {
+		return
+	}
+	sel, ok := s.selects[frame.MsgID]
+	if !ok {
+		return
+	}
+
+	now := time.Now()
+	if s.minGap > 0 {
+		key := streamKey{frame.MsgID, frame.SysID, frame.CompID}
+		s.mu.Lock()
+		if now.Sub(s.last[key]) < s.minGap {
+			s.mu.Unlock()
+			return
+		}
+		s.last[key] = now
+		s.mu.Unlock()
+	}
+
+	point := Point{Time: now, Message: sel.name, SysID: frame.SysID, CompID: frame.CompID}
+	for _, f := range sel.fields {
+		values := f.Values(frame.Payload)
+		for i, column := range f.Columns() {
+			if sel.only == nil || sel.only[column] || sel.only[f.Name] {
+				point.Values = append(point.Values, Value{Name: column, Type: f.Type, Value: values[i]})
+			}
+		}
+	}
+
+	select {
+	case s.points <- point:
+	default:
+		s.dropped.Add(1)
+	}
+}
+
+// run batches queued points and writes them until the sink is closed
+func (s *Sink) run() {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(flushInterval)
+	defer ticker.Stop()
+
+	var pending []Point
+	failing := false
+	flush := func(ctx context.Context) {
+		if len(pending) == 0 {
+			return
+		}
+		writeCtx, cancel := context.WithTimeout(ctx, writeTimeout)
+		err := s.writer.write(writeCtx, s.device, pending)
+		cancel()
+
+		if errors.Is(err, errRejected) {
+			s.logger.WithError(err).WithField("points", len(pending)).Warn("Telemetry rejected by the database, dropping it")
+			pending = pending[:0]
+			return
+		}
+		if err != nil {
+			if !failing {
+				s.logger.WithError(err).Warn("Failed to write telemetry, retrying")
+				failing = true
+			}
+			if len(pending) > maxPending {
+				pending = append(pending[:0], pending[len(pending)-maxPending:]...)
+			}
+			return
+		}
+		if failing {
+			s.logger.Info("Telemetry writes recovered")
+			failing = false
+		}
+		pending = pending[:0]
+	}
+
+	for {
+		select {
+		case point := <-s.points:
+			pending = append(pending, point)
+			if len(pending) >= batchSize && !failing {
+				flush(context.Background())
+			}
+		case <-ticker.C:
+			flush(context.Background())
+		case <-s.done:
+		drain:
+			for {
+				select {
+				case point := <-s.points:
+					pending = append(pending, point)
+				default:
+					break drain
+				}
+			}
+			ctx, cancel := context.WithTimeout(context.Background(), closeTimeout)
+			flush(ctx)
+			cancel()
+			if len(pending) > 0 {
+				s.logger.WithField("points", len(pending)).Warn("Telemetry not written before shutdown")
+			}
+			return
+		}
+	}
+}
+
+// Close writes the remaining points and closes the database connection
+func (s *Sink) Close() error {
+	if s.closed.Swap(true) {
+		return nil
+	}
+	close(s.done)
+	s.wg.Wait()
+
+	if n := s.dropped.Load(); n > 0 {
+		s.logger.WithField("points", n).Warn("Telemetry dropped because the database was too slow")
+	}
+	return s.writer.close()
+}
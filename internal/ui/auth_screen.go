@@ -0,0 +1,266 @@
+package ui
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/pavliha/aircast/aircast-cli/internal/auth"
+	"github.com/pavliha/aircast/aircast-cli/internal/term"
+)
+
+// spinnerFrames animate the waiting indicator
+var spinnerFrames = []string{"⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "⠧", "⠇", "⠏"}
+
+// asciiSpinnerFrames replace spinnerFrames on terminals without UTF-8
+var asciiSpinnerFrames = []string{"|", "/", "-", "\\"}
+
+type authState int
+
+const (
+	authRequesting authState = iota // Requesting a device code
+	authWaiting                     // Waiting for the user to authorize
+	authDone                        // Token received
+	authFailed                      // Request or polling failed; can retry
+)
+
+// authCodeMsg carries the result of a device code request
+type authCodeMsg struct {
+	attempt int
+	code    *auth.DeviceCodeResponse
+	err     error
+}
+
+// authTokenMsg carries the result of polling for the token
+type authTokenMsg struct {
+	attempt int
+	token   *auth.TokenResponse
+	err     error
+}
+
+type authTickMsg time.Time
+
+type authModel struct {
+	ctx           context.Context
+	authenticator *auth.DeviceCodeAuth
+
+	state   authState
+	attempt int // Incremented on retry so results of abandoned attempts are ignored
+	code    *auth.DeviceCodeResponse
+	expires time.Time
+	token   *auth.TokenResponse
+	err     error
+
+	pollCancel context.CancelFunc
+	frame      int
+	width      int
+	cancelled  bool
+}
+
+func (m authModel) Init() tea.Cmd {
+	return tea.Batch(m.requestCode(), authTick())
+}
+
+// authTick drives the spinner and expiry countdown
+func authTick() tea.Cmd {
+	return tea.Tick(100*time.Millisecond, func(t time.Time) tea.Msg {
+		return authTickMsg(t)
+	})
+}
+
+// requestCode requests a new device code for the current attempt
+func (m authModel) requestCode() tea.Cmd {
+	attempt := m.attempt
+	return func() tea.Msg {
+		code, err := m.authenticator.RequestCode(m.ctx)
+		return authCodeMsg{attempt: attempt, code: code, err: err}
+	}
+}
+
+// pollToken waits for the user to authorize the current code
+func (m authModel) pollToken(ctx context.Context) tea.Cmd {
+	attempt, code := m.attempt, m.code
+	return func() tea.Msg {
+		token, err := m.authenticator.PollToken(ctx, code)
+		return authTokenMsg{attempt: attempt, token: token, err: err}
+	}
+}
+
+// stopPolling abandons the in-flight token poll, if any
+func (m *authModel) stopPolling() {
+	if m.pollCancel != nil {
+		m.pollCancel()
+		m.pollCancel = nil
+	}
+}
+
+func (m authModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "ctrl+c", "q", "esc":
+			m.stopPolling()
+			m.cancelled = true
+			return m, tea.Quit
+		case "r":
+			if m.state == authWaiting || m.state == authFailed {
+				m.stopPolling()
+				m.attempt++
+				m.state = authRequesting
+				m.code = nil
+				m.err = nil
+				return m, m.requestCode()
+			}
+		}
+
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+
+	case authTickMsg:
+		m.frame = (m.frame + 1) % len(spinnerFrames)
+		return m, authTick()
+
+	case authCodeMsg:
+		if msg.attempt != m.attempt {
+			return m, nil
+		}
+		if msg.err != nil {
+			m.state = authFailed
+			m.err = msg.err
+			return m, nil
+		}
+		m.state = authWaiting
+		m.code = msg.code
+		m.expires = time.Now().Add(time.Duration(msg.code.ExpiresIn) * time.Second)
+
+		var ctx context.Context
+		ctx, m.pollCancel = context.WithCancel(m.ctx)
+		return m, m.pollToken(ctx)
+
+	case authTokenMsg:
+		if msg.attempt != m.attempt {
+			return m, nil
+		}
+		m.stopPolling()
+		if msg.err != nil {
+			m.state = authFailed
+			m.err = msg.err
+			return m, nil
+		}
+		m.state = authDone
+		m.token = msg.token
+		return m, tea.Quit
+	}
+
+	return m, nil
+}
+
+func (m authModel) View() string {
+	titleStyle := lipgloss.NewStyle().
+		Bold(true).
+		Foreground(lipgloss.Color("39")).
+		Padding(0, 1)
+
+	urlStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("10")).
+		Bold(true).
+		PaddingLeft(2)
+
+	hintStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("240"))
+	errorStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("9"))
+
+	// Wrap long lines to the terminal so resizes don't garble the screen
+	wrap := lipgloss.NewStyle()
+	if m.width > 0 {
+		wrap = wrap.Width(m.width - 1)
+	}
+
+	var s strings.Builder
+	s.WriteString("\n")
+
+	if m.state == authDone {
+		s.WriteString(term.Symbol("✓", "*") + " Authentication successful!")
+		if m.token != nil && m.token.Scope != "" {
+			s.WriteString(fmt.Sprintf("\n  Granted scopes: %s", m.token.Scope))
+		}
+		s.WriteString("\n\n")
+		return s.String()
+	}
+
+	s.WriteString(titleStyle.Render("Aircast Authentication"))
+	s.WriteString("\n\n")
+
+	spinner := spinnerFrames[m.frame]
+	if !term.UTF8() {
+		spinner = asciiSpinnerFrames[m.frame%len(asciiSpinnerFrames)]
+	}
+	switch m.state {
+	case authRequesting:
+		s.WriteString(fmt.Sprintf("  %s Requesting a login code...\n", spinner))
+
+	case authWaiting:
+		s.WriteString(wrap.Render("  To authenticate aircast-cli, visit this URL:"))
+		s.WriteString("\n\n")
+		s.WriteString(wrap.Render(urlStyle.Render(m.code.VerificationURIComplete)))
+		s.WriteString("\n\n")
+		if m.code.UserCode != "" {
+			s.WriteString(fmt.Sprintf("  Code: %s\n", m.code.UserCode))
+		}
+		if m.authenticator.Scope != "" {
+			s.WriteString(fmt.Sprintf("  Requested scope: %s\n", m.authenticator.Scope))
+		}
+		remaining := time.Until(m.expires).Round(time.Second)
+		if remaining < 0 {
+			remaining = 0
+		}
+		s.WriteString(fmt.Sprintf("\n  %s Waiting for authorization... code expires in %s\n", spinner, remaining))
+
+	case authFailed:
+		s.WriteString(wrap.Render(errorStyle.Render(fmt.Sprintf("  %s %v", term.Symbol("✗", "x"), m.err))))
+		s.WriteString("\n")
+	}
+
+	s.WriteString("\n")
+	s.WriteString(hintStyle.Render("  r: New code" + term.Symbol(" • ", " | ") + "q: Cancel"))
+	s.WriteString("\n\n")
+
+	return s.String()
+}
+
+// Authenticate runs the device code flow in an interactive screen with a
+// countdown and retry/cancel keys, falling back to plain output when not
+// attached to a terminal
+func Authenticate(ctx context.Context, authenticator *auth.DeviceCodeAuth) (*auth.TokenResponse, error) {
+	if !Interactive() {
+		return authenticator.Authenticate(ctx)
+	}
+
+	m := authModel{
+		ctx:           ctx,
+		authenticator: authenticator,
+		state:         authRequesting,
+	}
+
+	holdOutput()
+	p := tea.NewProgram(m, tea.WithContext(ctx))
+	finalModel, err := p.Run()
+	releaseOutput()
+	if err != nil {
+		if errors.Is(err, tea.ErrProgramKilled) || ctx.Err() != nil {
+			return nil, fmt.Errorf("authentication cancelled")
+		}
+		// Fallback to plain output if bubbletea fails
+		return authenticator.Authenticate(ctx)
+	}
+
+	result := finalModel.(authModel)
+	if result.cancelled || result.token == nil {
+		return nil, fmt.Errorf("authentication cancelled")
+	}
+
+	return result.token, nil
+}
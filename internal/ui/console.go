@@ -0,0 +1,98 @@
+package ui
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"sync"
+
+	"github.com/mattn/go-isatty"
+	"github.com/pavliha/aircast/aircast-cli/internal/term"
+)
+
+// screensDisabled is set by DisableScreens
+var screensDisabled bool
+
+// DisableScreens turns off full-screen interfaces, which pull in a terminal
+// renderer, in favor of plain prompts
+func DisableScreens() {
+	screensDisabled = true
+}
+
+// Interactive reports whether full-screen interfaces can be shown: stdin
+// and stdout are attached to a terminal, and neither accessible mode nor
+// DisableScreens turned them off
+func Interactive() bool {
+	return !term.Accessible() && !screensDisabled && isatty.IsTerminal(os.Stdin.Fd()) && isatty.IsTerminal(os.Stdout.Fd())
+}
+
+// heldWriter passes writes through, except while an interactive screen is
+// showing: then they are buffered and written out once it closes
+type heldWriter struct {
+	mu   sync.Mutex
+	out  io.Writer
+	held int
+	buf  bytes.Buffer
+}
+
+// LogOutput is where diagnostic output for the terminal should be written.
+// It holds log lines while a picker or other screen is on the terminal so
+// they can't corrupt it.
+var LogOutput io.Writer = logOutput
+
+var logOutput = &heldWriter{out: os.Stderr}
+
+// Output is where a running bridge prints notices for the operator. Like
+// LogOutput, it holds them while a screen is on the terminal.
+var Output io.Writer = output
+
+var output = &heldWriter{out: stdout{}}
+
+// stdout writes to os.Stdout as it is at the time, which quiet mode replaces
+type stdout struct{}
+
+func (stdout) Write(p []byte) (int, error) {
+	return os.Stdout.Write(p)
+}
+
+// holdOutput holds log lines and notices while a screen is showing
+func holdOutput() {
+	logOutput.hold()
+	output.hold()
+}
+
+// releaseOutput writes out what holdOutput held
+func releaseOutput() {
+	output.release()
+	logOutput.release()
+}
+
+// Write implements io.Writer
+func (w *heldWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.held > 0 {
+		return w.buf.Write(p)
+	}
+	return w.out.Write(p)
+}
+
+// hold starts buffering writes
+func (w *heldWriter) hold() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.held++
+}
+
+// release stops buffering and writes out anything held
+func (w *heldWriter) release() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.held--; w.held > 0 {
+		return
+	}
+	_, _ = w.out.Write(w.buf.Bytes())
+	w.buf.Reset()
+}
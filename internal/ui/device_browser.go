@@ -0,0 +1,233 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/pavliha/aircast/aircast-cli/internal/api"
+	"github.com/pavliha/aircast/aircast-cli/internal/term"
+)
+
+// browserLoadTimeout bounds one refresh of the device list
+const browserLoadTimeout = 15 * time.Second
+
+// LoadDevicesFunc fetches the current device list with online status
+type LoadDevicesFunc func(ctx context.Context) ([]api.Device, error)
+
+// devicesLoadedMsg carries the result of a device list refresh
+type devicesLoadedMsg struct {
+	devices []api.Device
+	err     error
+}
+
+// refreshTickMsg triggers the next periodic refresh. Ticks scheduled before
+// the latest refresh started carry an older generation and are ignored, so a
+// manual refresh doesn't start a second periodic one.
+type refreshTickMsg struct {
+	generation int
+}
+
+type deviceBrowserModel struct {
+	load    LoadDevicesFunc
+	aliases map[string]string
+	every   time.Duration
+	width   int // Terminal width, updated on resize
+
+	devices   []api.Device
+	cursor    int
+	loading   bool
+	refreshes int // Generation of the latest refresh
+	err       error
+	updatedAt time.Time
+
+	selected *api.Device
+	quit     bool
+}
+
+func (m deviceBrowserModel) Init() tea.Cmd {
+	return m.refresh()
+}
+
+// refresh loads the device list in the background
+func (m deviceBrowserModel) refresh() tea.Cmd {
+	load := m.load
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), browserLoadTimeout)
+		defer cancel()
+
+		devices, err := load(ctx)
+		return devicesLoadedMsg{devices: devices, err: err}
+	}
+}
+
+// scheduleRefresh waits for the refresh interval
+func (m deviceBrowserModel) scheduleRefresh() tea.Cmd {
+	generation := m.refreshes
+	return tea.Tick(m.every, func(time.Time) tea.Msg { return refreshTickMsg{generation: generation} })
+}
+
+func (m deviceBrowserModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "ctrl+c", "q", "esc":
+			m.quit = true
+			return m, tea.Quit
+		case "up", "k":
+			if m.cursor > 0 {
+				m.cursor--
+			}
+		case "down", "j":
+			if m.cursor < len(m.devices)-1 {
+				m.cursor++
+			}
+		case "r":
+			if !m.loading {
+				m.loading = true
+				m.refreshes++
+				return m, m.refresh()
+			}
+		case "enter":
+			if len(m.devices) > 0 {
+				device := m.devices[m.cursor]
+				m.selected = &device
+				return m, tea.Quit
+			}
+		}
+
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+
+	case refreshTickMsg:
+		if m.loading || msg.generation != m.refreshes {
+			return m, nil
+		}
+		m.loading = true
+		m.refreshes++
+		return m, m.refresh()
+
+	case devicesLoadedMsg:
+		m.loading = false
+		m.err = msg.err
+		if msg.err == nil {
+			// Keep the highlight on the same device as the list changes
+			var highlighted string
+			if m.cursor < len(m.devices) {
+				highlighted = m.devices[m.cursor].ID
+			}
+			m.devices = msg.devices
+			m.updatedAt = time.Now()
+			m.cursor = 0
+			for i, d := range m.devices {
+				if d.ID == highlighted {
+					m.cursor = i
+					break
+				}
+			}
+		}
+		return m, m.scheduleRefresh()
+	}
+	return m, nil
+}
+
+func (m deviceBrowserModel) View() string {
+	if m.selected != nil || m.quit {
+		return ""
+	}
+
+	titleStyle := lipgloss.NewStyle().
+		Bold(true).
+		Foreground(lipgloss.Color("39")).
+		Padding(0, 1)
+
+	selectedStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("10")).
+		Bold(true).
+		PaddingLeft(2)
+
+	normalStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("7")).
+		PaddingLeft(2)
+
+	hintStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("240"))
+	errorStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("9")).PaddingLeft(2)
+
+	var s strings.Builder
+	s.WriteString("\n")
+	s.WriteString(titleStyle.Render("Devices"))
+
+	status := "loading..."
+	if !m.updatedAt.IsZero() {
+		online := 0
+		for _, d := range m.devices {
+			if d.IsOnline {
+				online++
+			}
+		}
+		sep := term.Symbol(" • ", " | ")
+		status = fmt.Sprintf("%d of %d online%supdated %s", online, len(m.devices), sep, formatTimeSince(m.updatedAt))
+		if m.loading {
+			status += sep + "refreshing..."
+		}
+	}
+	if m.width > 0 {
+		status = term.Truncate(status, m.width-lipgloss.Width(titleStyle.Render("Devices"))-1)
+	}
+	s.WriteString(hintStyle.Render(status))
+	s.WriteString("\n\n")
+
+	if m.updatedAt.IsZero() && m.err == nil {
+		s.WriteString(normalStyle.Render("Fetching devices..."))
+		s.WriteString("\n")
+	} else if len(m.devices) == 0 && m.err == nil {
+		s.WriteString(normalStyle.Render("No devices found in your account"))
+		s.WriteString("\n")
+	}
+
+	for i, device := range m.devices {
+		cursor := " "
+		style := normalStyle
+		if m.cursor == i {
+			cursor = term.Symbol("❯", ">")
+			style = selectedStyle
+		}
+		s.WriteString(style.Render(fmt.Sprintf("%s %s", cursor, formatDevice(device, m.aliases[device.ID], m.width-4))))
+		s.WriteString("\n")
+	}
+
+	if m.err != nil {
+		s.WriteString("\n")
+		s.WriteString(errorStyle.Render(term.Truncate(fmt.Sprintf("%s Refresh failed: %v", term.Symbol("⚠", "!"), m.err), m.width-2)))
+		s.WriteString("\n")
+	}
+
+	s.WriteString("\n")
+	s.WriteString(hintLine(m.width, "Navigate", "Enter: Connect", "r: Refresh", "q: Quit"))
+	s.WriteString("\n\n")
+
+	return s.String()
+}
+
+// BrowseDevices shows a live-updating device list, refreshed every interval,
+// and returns the device the user chose to connect to, or nil if they quit
+func BrowseDevices(load LoadDevicesFunc, aliases map[string]string, every time.Duration) (*api.Device, error) {
+	m := deviceBrowserModel{
+		load:    load,
+		aliases: aliases,
+		every:   every,
+		loading: true,
+	}
+
+	holdOutput()
+	defer releaseOutput()
+
+	finalModel, err := tea.NewProgram(m).Run()
+	if err != nil {
+		return nil, err
+	}
+	return finalModel.(deviceBrowserModel).selected, nil
+}
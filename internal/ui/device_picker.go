@@ -1,24 +1,81 @@
 package ui
 
 import (
+	"bufio"
+	"context"
+	"errors"
 	"fmt"
+	"io"
+	"os"
+	"strconv"
 	"strings"
 	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/pavliha/aircast/aircast-cli/internal/api"
+	"github.com/pavliha/aircast/aircast-cli/internal/store"
+	"github.com/pavliha/aircast/aircast-cli/internal/term"
 )
 
+// previewTimeout bounds how long a telemetry preview may take
+const previewTimeout = 4 * time.Second
+
+const (
+	maxNameWidth = 40 // Device name column on wide terminals
+	minNameWidth = 10 // Narrowest the name column gets before lines wrap
+)
+
+// PreviewFunc returns a one-line live status for an online device
+type PreviewFunc func(ctx context.Context, device api.Device) (string, error)
+
+// previewMsg carries the result of a device preview
+type previewMsg struct {
+	deviceID string
+	text     string
+	err      error
+}
+
 type devicePickerModel struct {
-	devices  []api.Device
-	cursor   int
-	selected int
-	done     bool
+	devices   []api.Device
+	aliases   map[string]string                  // Aliases by device ID
+	lastKnown map[string]store.TelemetrySnapshot // Last telemetry seen, by device ID
+	cursor    int
+	selected  int
+	done      bool
+	width     int // Terminal width, updated on resize
+
+	preview  PreviewFunc
+	previews map[string]*previewMsg // nil value means the preview is in flight
 }
 
 func (m devicePickerModel) Init() tea.Cmd {
-	return nil
+	return m.requestPreview()
+}
+
+// requestPreview starts a preview of the highlighted device unless one exists
+func (m devicePickerModel) requestPreview() tea.Cmd {
+	if m.preview == nil {
+		return nil
+	}
+
+	device := m.devices[m.cursor]
+	if !device.IsOnline {
+		return nil
+	}
+	if _, ok := m.previews[device.ID]; ok {
+		return nil
+	}
+	m.previews[device.ID] = nil
+
+	preview := m.preview
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), previewTimeout)
+		defer cancel()
+
+		text, err := preview(ctx, device)
+		return previewMsg{deviceID: device.ID, text: text, err: err}
+	}
 }
 
 func (m devicePickerModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
@@ -31,10 +88,12 @@ func (m devicePickerModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			if m.cursor > 0 {
 				m.cursor--
 			}
+			return m, m.requestPreview()
 		case "down", "j":
 			if m.cursor < len(m.devices)-1 {
 				m.cursor++
 			}
+			return m, m.requestPreview()
 		case "enter", " ":
 			m.selected = m.cursor
 			m.done = true
@@ -48,6 +107,12 @@ func (m devicePickerModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				return m, tea.Quit
 			}
 		}
+
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+
+	case previewMsg:
+		m.previews[msg.deviceID] = &msg
 	}
 	return m, nil
 }
@@ -72,6 +137,14 @@ func (m devicePickerModel) View() string {
 		Foreground(lipgloss.Color("7")).
 		PaddingLeft(2)
 
+	previewStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("245")).
+		PaddingLeft(8)
+
+	lastKnownStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("240")).
+		PaddingLeft(8)
+
 	var s strings.Builder
 	s.WriteString("\n")
 	s.WriteString(titleStyle.Render("Select a Device"))
@@ -80,7 +153,7 @@ func (m devicePickerModel) View() string {
 	for i, device := range m.devices {
 		cursor := " "
 		if m.cursor == i {
-			cursor = "❯"
+			cursor = term.Symbol("❯", ">")
 		}
 
 		style := normalStyle
@@ -88,20 +161,53 @@ func (m devicePickerModel) View() string {
 			style = selectedStyle
 		}
 
-		deviceLine := fmt.Sprintf("%s [%d] %s", cursor, i+1, formatDevice(device))
+		prefix := fmt.Sprintf("%s [%d] ", cursor, i+1)
+		deviceLine := prefix + formatDevice(device, m.aliases[device.ID], m.width-2-lipgloss.Width(prefix))
 		s.WriteString(style.Render(deviceLine))
 		s.WriteString("\n")
+
+		if m.cursor == i && m.preview != nil && device.IsOnline {
+			line := term.Symbol("↳ ", "-> ") + m.previewText(device.ID)
+			s.WriteString(previewStyle.Render(term.Truncate(line, m.width-8)))
+			s.WriteString("\n")
+		}
+		if snapshot, ok := m.lastKnown[device.ID]; ok && !device.IsOnline {
+			line := term.Symbol("↳ ", "-> ") + formatLastKnown(snapshot)
+			s.WriteString(lastKnownStyle.Render(term.Truncate(line, m.width-8)))
+			s.WriteString("\n")
+		}
 	}
 
 	s.WriteString("\n")
-	s.WriteString(lipgloss.NewStyle().Foreground(lipgloss.Color("240")).Render("  ↑/↓: Navigate • Enter: Select • 1-9: Quick select • q: Quit"))
+	s.WriteString(hintLine(m.width, "Navigate", "Enter: Select", "1-9: Quick select", "q: Quit"))
 	s.WriteString("\n\n")
 
 	return s.String()
 }
 
-// PickDevice presents an interactive menu to select a device
-func PickDevice(devices []api.Device) (*api.Device, error) {
+// previewText returns the preview line for a device
+func (m devicePickerModel) previewText(deviceID string) string {
+	result, ok := m.previews[deviceID]
+	switch {
+	case !ok || result == nil:
+		return "fetching telemetry..."
+	case result.err != nil:
+		return "telemetry unavailable"
+	default:
+		return result.text
+	}
+}
+
+// formatLastKnown formats the telemetry last seen on an offline device
+func formatLastKnown(snapshot store.TelemetrySnapshot) string {
+	return fmt.Sprintf("last known: %s (%s)", snapshot, formatTimeSince(snapshot.SeenAt))
+}
+
+// PickDevice presents an interactive menu to select a device, showing any
+// aliases (by device ID) next to names. Offline devices show the telemetry
+// last seen on them from lastKnown (by device ID). If preview is non-nil,
+// the highlighted online device shows a live status line.
+func PickDevice(devices []api.Device, aliases map[string]string, lastKnown map[string]store.TelemetrySnapshot, preview PreviewFunc) (*api.Device, error) {
 	if len(devices) == 0 {
 		return nil, fmt.Errorf("no devices found in your account")
 	}
@@ -112,19 +218,29 @@ func PickDevice(devices []api.Device) (*api.Device, error) {
 		return &devices[0], nil
 	}
 
+	if term.Accessible() || screensDisabled {
+		return fallbackPicker(devices, aliases, lastKnown)
+	}
+
 	// Run interactive picker
 	m := devicePickerModel{
-		devices:  devices,
-		cursor:   0,
-		selected: -1,
-		done:     false,
+		devices:   devices,
+		aliases:   aliases,
+		lastKnown: lastKnown,
+		cursor:    0,
+		selected:  -1,
+		done:      false,
+		preview:   preview,
+		previews:  make(map[string]*previewMsg),
 	}
 
+	holdOutput()
 	p := tea.NewProgram(m)
 	finalModel, err := p.Run()
+	releaseOutput()
 	if err != nil {
 		// Fallback to old style if bubbletea fails
-		return fallbackPicker(devices)
+		return fallbackPicker(devices, aliases, lastKnown)
 	}
 
 	result := finalModel.(devicePickerModel)
@@ -133,71 +249,138 @@ func PickDevice(devices []api.Device) (*api.Device, error) {
 	}
 
 	selectedDevice := &devices[result.selected]
-	fmt.Printf("\n✓ Selected: %s\n\n", selectedDevice.Name)
+	fmt.Printf("\n%sSelected: %s\n\n", term.Symbol("✓ ", ""), selectedDevice.Name)
 
 	return selectedDevice, nil
 }
 
-// fallbackPicker is the old number-based picker as fallback
-func fallbackPicker(devices []api.Device) (*api.Device, error) {
-	fmt.Println("\n╔═══════════════════════════════════════════════════════════════╗")
-	fmt.Println("║                    Select a Device                            ║")
-	fmt.Println("╚═══════════════════════════════════════════════════════════════╝")
+// fallbackPicker is the old number-based picker as fallback, and the
+// picker of accessible mode
+func fallbackPicker(devices []api.Device, aliases map[string]string, lastKnown map[string]store.TelemetrySnapshot) (*api.Device, error) {
+	fmt.Println()
+	fmt.Println(term.Banner("Select a Device"))
 	fmt.Println()
 
 	for i, device := range devices {
-		fmt.Printf("[%d] %s\n", i+1, formatDevice(device))
+		snapshot, known := lastKnown[device.ID]
+		known = known && !device.IsOnline
+		if term.Accessible() {
+			description := describeDevice(device, aliases[device.ID])
+			if known {
+				description += ", " + formatLastKnown(snapshot)
+			}
+			fmt.Printf("%d. %s\n", i+1, description)
+			continue
+		}
+		prefix := fmt.Sprintf("[%d] ", i+1)
+		fmt.Printf("%s%s\n", prefix, formatDevice(device, aliases[device.ID], term.Width()-len(prefix)))
+		if known {
+			fmt.Printf("%s%s\n", strings.Repeat(" ", len(prefix)+2), term.Truncate(formatLastKnown(snapshot), term.Width()-len(prefix)-2))
+		}
 	}
 
 	fmt.Println()
 
+	input := bufio.NewReader(os.Stdin)
 	var selection int
 	for {
 		fmt.Printf("Select device (1-%d): ", len(devices))
-		_, err := fmt.Scanln(&selection)
+		line, err := input.ReadString('\n')
+		if err != nil && (!errors.Is(err, io.EOF) || line == "") {
+			return nil, fmt.Errorf("no device selected")
+		}
+		selection, err = strconv.Atoi(strings.TrimSpace(line))
 		if err != nil || selection < 1 || selection > len(devices) {
-			fmt.Println("Invalid selection. Please try again.")
+			fmt.Printf("Invalid selection. Enter a number from 1 to %d.\n", len(devices))
 			continue
 		}
 		break
 	}
 
 	selectedDevice := &devices[selection-1]
-	fmt.Printf("\n✓ Selected: %s\n\n", selectedDevice.Name)
+	fmt.Printf("\n%sSelected: %s\n\n", term.Symbol("✓ ", ""), selectedDevice.Name)
 
 	return selectedDevice, nil
 }
 
-// formatDevice formats a device for display
-func formatDevice(device api.Device) string {
-	var parts []string
+// describeDevice describes a device in a sentence for screen readers
+func describeDevice(device api.Device, alias string) string {
+	name := device.Name
+	if alias != "" {
+		name = fmt.Sprintf("%s, alias %s", name, alias)
+	}
 
-	// Name (truncate if too long)
+	status := "offline"
+	if device.IsOnline {
+		status = "online"
+	}
+
+	description := fmt.Sprintf("%s, %s", name, status)
+	if lastSeenTime, err := time.Parse(time.RFC3339, device.LastSeenAt); err == nil {
+		description += ", last seen " + formatTimeSince(lastSeenTime)
+	}
+	return description
+}
+
+// formatDevice formats a device and its aliases for display in at most
+// width columns (0 for no limit). On narrow terminals the last-seen time is
+// dropped before the name is shortened.
+func formatDevice(device api.Device, alias string, width int) string {
 	name := device.Name
-	if len(name) > 40 {
-		name = name[:37] + "..."
+	if alias != "" {
+		name = fmt.Sprintf("%s (%s)", name, alias)
 	}
-	parts = append(parts, fmt.Sprintf("%-40s", name))
 
-	// Status indicator
+	offline := term.Symbol("⚫ Offline", "- Offline")
+	status := offline
 	if device.IsOnline {
-		parts = append(parts, "🟢 Online")
-	} else {
-		parts = append(parts, "⚫ Offline")
+		status = term.Symbol("🟢 Online", "+ Online")
 	}
 
-	// Last seen
+	var lastSeen string
 	if device.LastSeenAt != "" {
-		lastSeenTime, err := time.Parse(time.RFC3339, device.LastSeenAt)
-		if err == nil {
-			lastSeen := formatTimeSince(lastSeenTime)
-			parts = append(parts, fmt.Sprintf("(Last seen: %s)", lastSeen))
+		if lastSeenTime, err := time.Parse(time.RFC3339, device.LastSeenAt); err == nil {
+			lastSeen = fmt.Sprintf("(Last seen: %s)", formatTimeSince(lastSeenTime))
 		}
 	}
 
+	nameWidth := maxNameWidth
+	if width > 0 {
+		// Reserve the wider status so names line up across rows
+		rest := lipgloss.Width(offline) + 1
+		if lastSeen != "" && width-rest-len(lastSeen)-1 >= minNameWidth {
+			rest += len(lastSeen) + 1
+		} else {
+			lastSeen = ""
+		}
+		nameWidth = max(min(nameWidth, width-rest), minNameWidth)
+	}
+
+	name = term.Truncate(name, nameWidth)
+	parts := []string{name + strings.Repeat(" ", nameWidth-lipgloss.Width(name)), status}
+	if lastSeen != "" {
+		parts = append(parts, lastSeen)
+	}
 	return strings.Join(parts, " ")
 }
 
+// hintLine renders a key help line, wrapped to the terminal width
+func hintLine(width int, hints ...string) string {
+	hints = append([]string{term.Symbol("↑/↓", "up/down") + ": " + hints[0]}, hints[1:]...)
+	return keyHints(width, hints...)
+}
+
+// keyHints renders key hints, wrapped to the terminal width
+func keyHints(width int, hints ...string) string {
+	text := strings.Join(hints, term.Symbol(" • ", " | "))
+
+	style := lipgloss.NewStyle().Foreground(lipgloss.Color("240")).PaddingLeft(2)
+	if width > 2 {
+		style = style.Width(width - 1)
+	}
+	return style.Render(text)
+}
+
 // formatTimeSince formats a duration in a human-readable way
 func formatTimeSince(t time.Time) string {
 	duration := time.Since(t)
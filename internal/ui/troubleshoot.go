@@ -0,0 +1,338 @@
+package ui
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/pavliha/aircast/aircast-cli/internal/api"
+	"github.com/pavliha/aircast/aircast-cli/internal/term"
+)
+
+const (
+	troubleshootRefresh = 5 * time.Second  // How often the agent status is refreshed
+	troubleshootTimeout = 15 * time.Second // Bound of one API call from the screen
+	troubleshootLogs    = 15               // Agent log lines shown
+)
+
+// TroubleshootResult is how the troubleshooting screen closed
+type TroubleshootResult int
+
+const (
+	// TroubleshootConnected means data flowed again
+	TroubleshootConnected TroubleshootResult = iota
+	// TroubleshootDismissed means the user closed the screen; the bridge keeps retrying
+	TroubleshootDismissed
+	// TroubleshootQuit means the user asked to stop the bridge
+	TroubleshootQuit
+)
+
+// Troubleshooter is what the troubleshooting screen needs from the bridge
+// and the API
+type Troubleshooter struct {
+	Device string
+	// NextRetry returns when the bridge reconnects next; it moves when a
+	// probe finds the device still not ready
+	NextRetry func() time.Time
+
+	Status  func(ctx context.Context) (*api.AgentStatus, error)
+	Restart func(ctx context.Context) error
+	Logs    func(ctx context.Context, lines int) ([]string, error)
+	// RetryNow reconnects without waiting for RetryAt
+	RetryNow func()
+}
+
+type agentStatusMsg struct {
+	status *api.AgentStatus
+	err    error
+}
+
+type agentLogsMsg struct {
+	lines []string
+	err   error
+}
+
+type agentRestartMsg struct {
+	err error
+}
+
+type troubleshootTickMsg time.Time
+
+type troubleshootModel struct {
+	t     Troubleshooter
+	width int
+
+	status     *api.AgentStatus
+	statusErr  error
+	statusAt   time.Time
+	refreshing bool
+
+	showLogs bool
+	logs     []string
+	logsErr  error
+
+	restarting bool
+	notice     string // Result of the last action
+
+	result TroubleshootResult
+	done   bool
+}
+
+func (m troubleshootModel) Init() tea.Cmd {
+	return tea.Batch(m.loadStatus(), troubleshootTick())
+}
+
+func troubleshootTick() tea.Cmd {
+	return tea.Tick(time.Second, func(t time.Time) tea.Msg { return troubleshootTickMsg(t) })
+}
+
+// loadStatus fetches the agent status in the background
+func (m troubleshootModel) loadStatus() tea.Cmd {
+	status := m.t.Status
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), troubleshootTimeout)
+		defer cancel()
+		s, err := status(ctx)
+		return agentStatusMsg{status: s, err: err}
+	}
+}
+
+// loadLogs fetches the agent's recent log lines in the background
+func (m troubleshootModel) loadLogs() tea.Cmd {
+	logs := m.t.Logs
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), troubleshootTimeout)
+		defer cancel()
+		lines, err := logs(ctx, troubleshootLogs)
+		return agentLogsMsg{lines: lines, err: err}
+	}
+}
+
+// restart asks the agent to restart in the background
+func (m troubleshootModel) restart() tea.Cmd {
+	restart := m.t.Restart
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), troubleshootTimeout)
+		defer cancel()
+		return agentRestartMsg{err: restart(ctx)}
+	}
+}
+
+// canRestart reports whether restarting the agent is worth offering
+func (m troubleshootModel) canRestart() bool {
+	if errors.Is(m.statusErr, api.ErrAgentUnsupported) {
+		return false
+	}
+	return m.status == nil || (m.status.Online && m.status.CanRestart)
+}
+
+func (m troubleshootModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "ctrl+c":
+			m.result, m.done = TroubleshootQuit, true
+			return m, tea.Quit
+		case "q", "esc":
+			m.result, m.done = TroubleshootDismissed, true
+			return m, tea.Quit
+		case "enter", " ":
+			m.t.RetryNow()
+			m.notice = "Retrying now..."
+		case "r":
+			if m.canRestart() && !m.restarting {
+				m.restarting = true
+				m.notice = "Restarting the agent..."
+				return m, m.restart()
+			}
+		case "l":
+			m.showLogs = !m.showLogs
+			if m.showLogs {
+				return m, m.loadLogs()
+			}
+		}
+
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+
+	case troubleshootTickMsg:
+		cmds := []tea.Cmd{troubleshootTick()}
+		if !m.refreshing && time.Since(m.statusAt) >= troubleshootRefresh {
+			m.refreshing = true
+			cmds = append(cmds, m.loadStatus())
+		}
+		return m, tea.Batch(cmds...)
+
+	case agentStatusMsg:
+		m.refreshing = false
+		m.statusAt = time.Now()
+		m.status, m.statusErr = msg.status, msg.err
+
+	case agentLogsMsg:
+		m.logs, m.logsErr = msg.lines, msg.err
+
+	case agentRestartMsg:
+		m.restarting = false
+		if msg.err != nil {
+			m.notice = fmt.Sprintf("Restart failed: %v", msg.err)
+			return m, nil
+		}
+		// Reconnect without waiting out the breaker
+		m.notice = "Agent restarted; reconnecting..."
+		m.t.RetryNow()
+		m.statusAt = time.Time{}
+		if m.showLogs {
+			return m, m.loadLogs()
+		}
+	}
+	return m, nil
+}
+
+func (m troubleshootModel) View() string {
+	if m.done {
+		return ""
+	}
+
+	titleStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("214")).Padding(0, 1)
+	labelStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("245")).PaddingLeft(2).Width(16)
+	goodStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("10"))
+	badStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("9"))
+	hintStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("240")).PaddingLeft(2)
+	logStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("250")).PaddingLeft(4)
+
+	width := m.width - 2
+	if width <= 0 {
+		width = 78
+	}
+	line := func(label, value string) string {
+		return labelStyle.Render(label) + value + "\n"
+	}
+	state := func(ok bool, good, bad string) string {
+		if ok {
+			return goodStyle.Render(term.Symbol("✓ ", "") + good)
+		}
+		return badStyle.Render(term.Symbol("✗ ", "") + bad)
+	}
+
+	var s strings.Builder
+	s.WriteString("\n")
+	s.WriteString(titleStyle.Render(term.Symbol("⚠ ", "") + "Device MAVLink proxy is not running"))
+	s.WriteString("\n\n")
+	s.WriteString(line("Device", m.t.Device))
+
+	switch {
+	case m.status != nil:
+		agent := state(m.status.Online, "online", "offline")
+		if m.status.Online && m.status.Version != "" {
+			agent += hintStyle.UnsetPaddingLeft().Render(" (aircast-agent " + m.status.Version + ")")
+		}
+		s.WriteString(line("Agent", agent))
+		if m.status.Online {
+			proxy := state(m.status.ProxyRunning, "running", "not running")
+			if m.status.ProxyError != "" {
+				proxy += badStyle.Render(": " + m.status.ProxyError)
+			}
+			s.WriteString(line("MAVLink proxy", term.Truncate(proxy, width-16)))
+		}
+	case errors.Is(m.statusErr, api.ErrAgentUnsupported):
+		s.WriteString(line("Agent", "status not available; check the device directly"))
+	case m.statusErr != nil:
+		s.WriteString(line("Agent", badStyle.Render(term.Truncate(fmt.Sprintf("status check failed: %v", m.statusErr), width-16))))
+	default:
+		s.WriteString(line("Agent", "checking..."))
+	}
+
+	retry := "retrying..."
+	if wait := time.Until(m.t.NextRetry()); wait > 0 {
+		retry = fmt.Sprintf("in %ds", int(wait.Round(time.Second).Seconds()))
+	}
+	s.WriteString(line("Next retry", retry))
+
+	s.WriteString("\n")
+	switch {
+	case m.status != nil && !m.status.Online:
+		s.WriteString(hintStyle.Render("The device isn't connected to Aircast. Check its power and network."))
+	case m.status != nil && !m.status.ProxyRunning:
+		s.WriteString(hintStyle.Render("The agent is up but can't reach the autopilot. Check the serial cable and baud rate."))
+	default:
+		s.WriteString(hintStyle.Render("Start the aircast-agent on your device."))
+	}
+	s.WriteString("\n")
+
+	if m.notice != "" {
+		s.WriteString("\n")
+		s.WriteString(hintStyle.Render(term.Truncate(m.notice, width)))
+		s.WriteString("\n")
+	}
+
+	if m.showLogs {
+		s.WriteString("\n")
+		s.WriteString(labelStyle.Render("Agent log"))
+		s.WriteString("\n")
+		switch {
+		case errors.Is(m.logsErr, api.ErrAgentUnsupported):
+			s.WriteString(logStyle.Render("Logs aren't available for this device."))
+			s.WriteString("\n")
+		case m.logsErr != nil:
+			s.WriteString(logStyle.Render(term.Truncate(fmt.Sprintf("Failed to load logs: %v", m.logsErr), width-4)))
+			s.WriteString("\n")
+		case m.logs == nil:
+			s.WriteString(logStyle.Render("Loading..."))
+			s.WriteString("\n")
+		case len(m.logs) == 0:
+			s.WriteString(logStyle.Render("The log is empty."))
+			s.WriteString("\n")
+		}
+		for _, l := range m.logs {
+			s.WriteString(logStyle.Render(term.Truncate(l, width-4)))
+			s.WriteString("\n")
+		}
+	}
+
+	hints := []string{"Enter: Retry now"}
+	if m.canRestart() {
+		hints = append(hints, "r: Restart agent")
+	}
+	if m.showLogs {
+		hints = append(hints, "l: Hide log")
+	} else {
+		hints = append(hints, "l: Agent log")
+	}
+	hints = append(hints, "q: Hide", "Ctrl+C: Stop bridge")
+	s.WriteString("\n")
+	s.WriteString(keyHints(m.width, hints...))
+	s.WriteString("\n\n")
+	return s.String()
+}
+
+// Troubleshoot shows a troubleshooting screen while the circuit breaker is
+// open: the agent's status, a restart action, its recent log and a countdown
+// to the next retry. It closes when connected is closed or ctx is done.
+func Troubleshoot(ctx context.Context, t Troubleshooter, connected <-chan struct{}) (TroubleshootResult, error) {
+	m := troubleshootModel{t: t}
+
+	holdOutput()
+	defer releaseOutput()
+
+	p := tea.NewProgram(m, tea.WithContext(ctx), tea.WithAltScreen())
+	go func() {
+		select {
+		case <-connected:
+			p.Quit()
+		case <-ctx.Done():
+		}
+	}()
+
+	finalModel, err := p.Run()
+	if err != nil {
+		if errors.Is(err, tea.ErrProgramKilled) {
+			return TroubleshootQuit, nil
+		}
+		return TroubleshootDismissed, err
+	}
+	return finalModel.(troubleshootModel).result, nil
+}
@@ -0,0 +1,31 @@
+package vehicleconfig
+
+import "strconv"
+
+// MAV_CMD_NAV_WAYPOINT, also used for the home position
+const cmdNavWaypoint = 16
+
+// commandNames are the MAV_CMD values most common in missions
+var commandNames = map[uint16]string{
+	16: "WAYPOINT", 17: "LOITER_UNLIM", 18: "LOITER_TURNS", 19: "LOITER_TIME", 20: "RETURN_TO_LAUNCH",
+	21: "LAND", 22: "TAKEOFF", 30: "CONTINUE_AND_CHANGE_ALT", 31: "LOITER_TO_ALT", 82: "SPLINE_WAYPOINT",
+	84: "VTOL_TAKEOFF", 85: "VTOL_LAND", 92: "GUIDED_ENABLE", 93: "DELAY", 94: "PAYLOAD_PLACE",
+	112: "CONDITION_DELAY", 113: "CONDITION_CHANGE_ALT", 114: "CONDITION_DISTANCE", 115: "CONDITION_YAW",
+	176: "DO_SET_MODE", 177: "DO_JUMP", 178: "DO_CHANGE_SPEED", 179: "DO_SET_HOME", 181: "DO_SET_RELAY",
+	182: "DO_REPEAT_RELAY", 183: "DO_SET_SERVO", 184: "DO_REPEAT_SERVO", 189: "DO_LAND_START",
+	195: "DO_SET_ROI_LOCATION", 197: "DO_SET_ROI_NONE", 201: "DO_SET_ROI", 203: "DO_DIGICAM_CONTROL",
+	205: "DO_MOUNT_CONTROL", 206: "DO_SET_CAM_TRIGG_DIST", 207: "DO_FENCE_ENABLE", 208: "DO_PARACHUTE",
+	211: "DO_GRIPPER", 212: "DO_AUTOTUNE_ENABLE", 223: "DO_ENGINE_CONTROL", 530: "SET_CAMERA_MODE",
+	531: "SET_CAMERA_ZOOM", 1000: "DO_GIMBAL_MANAGER_PITCHYAW", 2000: "IMAGE_START_CAPTURE",
+	2001: "IMAGE_STOP_CAPTURE", 2500: "VIDEO_START_CAPTURE", 2501: "VIDEO_STOP_CAPTURE",
+	3000: "DO_VTOL_TRANSITION",
+}
+
+// CommandName returns a MAV_CMD's name without the MAV_CMD_(NAV_) prefix,
+// or its number if it isn't known
+func CommandName(command uint16) string {
+	if name, ok := commandNames[command]; ok {
+		return name
+	}
+	return "CMD " + strconv.Itoa(int(command))
+}
@@ -0,0 +1,225 @@
+package vehicleconfig
+
+import (
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ChangeKind is how an entry differs, named after its JSON Patch operation
+type ChangeKind string
+
+const (
+	Added   ChangeKind = "add"
+	Removed ChangeKind = "remove"
+	Changed ChangeKind = "replace"
+)
+
+// Change is one parameter or mission item that differs between two files
+type Change struct {
+	Kind ChangeKind
+	Name string // Parameter name, or the mission item's index (the old index if removed)
+	Path string // JSON Pointer of the entry when the patch is applied in order
+
+	// float64 for parameters, MissionItem for mission items; Old is nil if
+	// added, New is nil if removed
+	Old, New any
+
+	// Fields of a changed mission item that differ
+	Fields []FieldChange
+}
+
+// FieldChange is a mission item field that differs
+type FieldChange struct {
+	Name     string // command, frame, param1-4, lat, lon, alt or autocontinue
+	Old, New float64
+}
+
+// PatchOp is an RFC 6902 JSON Patch operation
+type PatchOp struct {
+	Op    ChangeKind `json:"op"`
+	Path  string     `json:"path"`
+	Value any        `json:"value,omitempty"`
+}
+
+// Patch returns the changes as a JSON Patch. For parameters it applies to
+// an object of values by name, for missions to the array of items.
+func Patch(changes []Change) []PatchOp {
+	ops := make([]PatchOp, 0, len(changes))
+	for _, c := range changes {
+		ops = append(ops, PatchOp{Op: c.Kind, Path: c.Path, Value: c.New})
+	}
+	return ops
+}
+
+// DiffParams compares two parameter sets, in name order. Values are compared
+// at the autopilot's single precision, so 0.1 and 0.100000001 are equal.
+func DiffParams(old, new Params) []Change {
+	names := make([]string, 0, len(old)+len(new))
+	for name := range old {
+		names = append(names, name)
+	}
+	for name := range new {
+		if _, ok := old[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	var changes []Change
+	for _, name := range names {
+		oldValue, inOld := old[name]
+		newValue, inNew := new[name]
+		c := Change{Name: name, Path: "/" + escapePointer(name)}
+		switch {
+		case !inOld:
+			c.Kind, c.New = Added, newValue
+		case !inNew:
+			c.Kind, c.Old = Removed, oldValue
+		case !sameFloat32(oldValue, newValue):
+			c.Kind, c.Old, c.New = Changed, oldValue, newValue
+		default:
+			continue
+		}
+		changes = append(changes, c)
+	}
+	return changes
+}
+
+// DiffMission compares two missions. Items are aligned on the longest run of
+// unchanged ones, so a waypoint inserted mid-mission shows as one addition
+// rather than every later item changing.
+func DiffMission(old, new Mission) []Change {
+	// lcs[i][j] is the length of the longest common subsequence of old[i:] and new[j:]
+	lcs := make([][]int, len(old)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(new)+1)
+	}
+	for i := len(old) - 1; i >= 0; i-- {
+		for j := len(new) - 1; j >= 0; j-- {
+			if old[i].equal(new[j]) {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var changes []Change
+	i, j, pos := 0, 0, 0 // pos is the index in the partly patched mission
+	for i < len(old) || j < len(new) {
+		if i < len(old) && j < len(new) && old[i].equal(new[j]) {
+			i, j, pos = i+1, j+1, pos+1
+			continue
+		}
+
+		// Collect the gap up to the next unchanged item
+		var removed, added []int
+		for i < len(old) || j < len(new) {
+			if i < len(old) && j < len(new) && old[i].equal(new[j]) {
+				break
+			}
+			if i < len(old) && (j == len(new) || lcs[i+1][j] >= lcs[i][j+1]) {
+				removed = append(removed, i)
+				i++
+			} else {
+				added = append(added, j)
+				j++
+			}
+		}
+
+		// Items in the same place with the same command are edits; the rest
+		// were replaced, added or removed
+		paired := min(len(removed), len(added))
+		for k := 0; k < paired; k++ {
+			o, n := old[removed[k]], new[added[k]]
+			if o.Command == n.Command {
+				changes = append(changes, Change{
+					Kind: Changed, Name: strconv.Itoa(added[k]), Path: itemPath(pos),
+					Old: o, New: n, Fields: o.diff(n),
+				})
+			} else {
+				changes = append(changes,
+					Change{Kind: Removed, Name: strconv.Itoa(removed[k]), Path: itemPath(pos), Old: o},
+					Change{Kind: Added, Name: strconv.Itoa(added[k]), Path: itemPath(pos), New: n})
+			}
+			pos++
+		}
+		for _, r := range removed[paired:] {
+			changes = append(changes, Change{Kind: Removed, Name: strconv.Itoa(r), Path: itemPath(pos), Old: old[r]})
+		}
+		for _, a := range added[paired:] {
+			changes = append(changes, Change{Kind: Added, Name: strconv.Itoa(a), Path: itemPath(pos), New: new[a]})
+			pos++
+		}
+	}
+	return changes
+}
+
+// fields returns the item's fields by name, in display order
+func (m MissionItem) fields() []FieldChange {
+	autoContinue := 0.0
+	if m.AutoContinue {
+		autoContinue = 1
+	}
+	return []FieldChange{
+		{Name: "command", Old: float64(m.Command)},
+		{Name: "frame", Old: float64(m.Frame)},
+		{Name: "param1", Old: m.Params[0]},
+		{Name: "param2", Old: m.Params[1]},
+		{Name: "param3", Old: m.Params[2]},
+		{Name: "param4", Old: m.Params[3]},
+		{Name: "lat", Old: m.Params[4]},
+		{Name: "lon", Old: m.Params[5]},
+		{Name: "alt", Old: m.Params[6]},
+		{Name: "autocontinue", Old: autoContinue},
+	}
+}
+
+// diff returns the fields that differ between m and other
+func (m MissionItem) diff(other MissionItem) []FieldChange {
+	var changes []FieldChange
+	theirs := other.fields()
+	for i, f := range m.fields() {
+		if !sameField(f.Name, f.Old, theirs[i].Old) {
+			changes = append(changes, FieldChange{Name: f.Name, Old: f.Old, New: theirs[i].Old})
+		}
+	}
+	return changes
+}
+
+// equal reports whether two items are the same as far as the autopilot can tell
+func (m MissionItem) equal(other MissionItem) bool {
+	return len(m.diff(other)) == 0
+}
+
+// sameField compares a field at the precision MISSION_ITEM_INT carries it:
+// latitude and longitude in 1e-7 degrees, everything else single precision
+func sameField(name string, a, b float64) bool {
+	if name == "lat" || name == "lon" {
+		if math.IsNaN(a) || math.IsNaN(b) {
+			return math.IsNaN(a) && math.IsNaN(b)
+		}
+		return math.Round(a*1e7) == math.Round(b*1e7)
+	}
+	return sameFloat32(a, b)
+}
+
+// sameFloat32 compares at single precision, with unset (NaN) values equal
+func sameFloat32(a, b float64) bool {
+	if math.IsNaN(a) || math.IsNaN(b) {
+		return math.IsNaN(a) && math.IsNaN(b)
+	}
+	return float32(a) == float32(b)
+}
+
+// itemPath is the JSON Pointer of a mission item
+func itemPath(index int) string {
+	return "/" + strconv.Itoa(index)
+}
+
+// escapePointer escapes a JSON Pointer reference token (RFC 6901)
+func escapePointer(s string) string {
+	return strings.NewReplacer("~", "~0", "/", "~1").Replace(s)
+}
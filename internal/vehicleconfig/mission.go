@@ -0,0 +1,167 @@
+package vehicleconfig
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// wplHeader starts a QGroundControl waypoint file
+const wplHeader = "QGC WPL 110"
+
+// MissionItem is one mission command. Params are param1-4 followed by x, y
+// and z (usually latitude, longitude and altitude); NaN is an unset param.
+type MissionItem struct {
+	Command      uint16
+	Frame        uint8
+	Params       [7]float64
+	AutoContinue bool
+}
+
+// Mission is a mission's items in order. Item 0 is the home position, as in
+// waypoint files and the autopilot's mission.
+type Mission []MissionItem
+
+// LoadMission reads a QGroundControl waypoint file (QGC WPL 110, also
+// written by Mission Planner) or a QGroundControl .plan file
+func LoadMission(path string) (Mission, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var mission Mission
+	if trimmed := bytes.TrimSpace(data); bytes.HasPrefix(trimmed, []byte("{")) {
+		mission, err = parsePlan(trimmed)
+	} else {
+		mission, err = ParseWaypoints(bytes.NewReader(data))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return mission, nil
+}
+
+// ParseWaypoints reads a QGC WPL 110 waypoint file: a header line, then one
+// tab-separated line per item with its index, current flag, frame, command,
+// seven params and autocontinue flag
+func ParseWaypoints(r io.Reader) (Mission, error) {
+	scanner := bufio.NewScanner(r)
+	if !scanner.Scan() || strings.TrimSpace(scanner.Text()) != wplHeader {
+		if err := scanner.Err(); err != nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf("not a waypoint file (expected %q header)", wplHeader)
+	}
+
+	var mission Mission
+	for line := 2; scanner.Scan(); line++ {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		if len(fields) != 12 {
+			return nil, fmt.Errorf("line %d: expected 12 fields, found %d", line, len(fields))
+		}
+
+		var item MissionItem
+		frame, err := strconv.ParseUint(fields[2], 10, 8)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid frame %q", line, fields[2])
+		}
+		command, err := strconv.ParseUint(fields[3], 10, 16)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid command %q", line, fields[3])
+		}
+		item.Frame, item.Command = uint8(frame), uint16(command)
+		for i := range item.Params {
+			if item.Params[i], err = strconv.ParseFloat(fields[4+i], 64); err != nil {
+				return nil, fmt.Errorf("line %d: invalid param %q", line, fields[4+i])
+			}
+		}
+		item.AutoContinue = fields[11] != "0"
+		mission = append(mission, item)
+	}
+	return mission, scanner.Err()
+}
+
+// planFile is the part of a QGroundControl .plan file holding the mission
+type planFile struct {
+	FileType string `json:"fileType"`
+	Mission  struct {
+		Items               []planItem `json:"items"`
+		PlannedHomePosition []float64  `json:"plannedHomePosition"`
+	} `json:"mission"`
+}
+
+// planItem is a .plan mission item. Params can be null.
+type planItem struct {
+	Type         string     `json:"type"`
+	Command      uint16     `json:"command"`
+	Frame        uint8      `json:"frame"`
+	Params       []*float64 `json:"params"`
+	AutoContinue bool       `json:"autoContinue"`
+	ComplexType  string     `json:"complexItemType"`
+}
+
+// parsePlan reads the mission of a .plan file, with its planned home
+// position as item 0 the way QGroundControl uploads it
+func parsePlan(data []byte) (Mission, error) {
+	var plan planFile
+	if err := json.Unmarshal(data, &plan); err != nil {
+		return nil, fmt.Errorf("invalid plan file: %w", err)
+	}
+	if plan.FileType != "Plan" {
+		return nil, errors.New("not a QGroundControl plan file")
+	}
+
+	home := MissionItem{Command: cmdNavWaypoint, AutoContinue: true}
+	if p := plan.Mission.PlannedHomePosition; len(p) == 3 {
+		home.Params[4], home.Params[5], home.Params[6] = p[0], p[1], p[2]
+	}
+	mission := Mission{home}
+
+	for i, pi := range plan.Mission.Items {
+		if pi.Type != "SimpleItem" {
+			// Surveys and corridor scans are expanded into waypoints by
+			// QGroundControl; save the mission as a waypoint file to compare them
+			return nil, fmt.Errorf("item %d: %s items can't be compared, export the mission as a waypoint file", i+1, pi.ComplexType)
+		}
+		if len(pi.Params) != 7 {
+			return nil, fmt.Errorf("item %d: expected 7 params, found %d", i+1, len(pi.Params))
+		}
+
+		item := MissionItem{Command: pi.Command, Frame: pi.Frame, AutoContinue: pi.AutoContinue}
+		for j, p := range pi.Params {
+			item.Params[j] = math.NaN()
+			if p != nil {
+				item.Params[j] = *p
+			}
+		}
+		mission = append(mission, item)
+	}
+	return mission, nil
+}
+
+// MarshalJSON writes the item with unset params as null
+func (m MissionItem) MarshalJSON() ([]byte, error) {
+	params := make([]*float64, len(m.Params))
+	for i := range m.Params {
+		if !math.IsNaN(m.Params[i]) {
+			params[i] = &m.Params[i]
+		}
+	}
+	return json.Marshal(struct {
+		Command      uint16     `json:"command"`
+		Frame        uint8      `json:"frame"`
+		Params       []*float64 `json:"params"`
+		AutoContinue bool       `json:"autocontinue"`
+	}{m.Command, m.Frame, params, m.AutoContinue})
+}
@@ -0,0 +1,64 @@
+// Package vehicleconfig reads autopilot parameter and mission files as saved
+// by ground stations, and compares them for review before a flight.
+package vehicleconfig
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Params are parameter values by name
+type Params map[string]float64
+
+// LoadParams reads a parameter file: Mission Planner's NAME,VALUE lines or
+// QGroundControl's tab-separated "sysid compid NAME VALUE type" lines. Lines
+// starting with # are comments.
+func LoadParams(path string) (Params, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = file.Close() }()
+
+	params, err := ParseParams(file)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return params, nil
+}
+
+// ParseParams reads a parameter file, see LoadParams
+func ParseParams(r io.Reader) (Params, error) {
+	params := Params{}
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		fields := strings.FieldsFunc(text, func(r rune) bool {
+			return r == ',' || r == ' ' || r == '\t'
+		})
+		var name, value string
+		switch len(fields) {
+		case 2: // Mission Planner
+			name, value = fields[0], fields[1]
+		case 5: // QGroundControl
+			name, value = fields[2], fields[3]
+		default:
+			return nil, fmt.Errorf("line %d: expected NAME,VALUE or a QGroundControl parameter line", line)
+		}
+
+		v, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid value %q for %s", line, value, name)
+		}
+		params[name] = v
+	}
+	return params, scanner.Err()
+}
@@ -0,0 +1,330 @@
+// Package webhook notifies external services of session lifecycle events by
+// POSTing signed JSON, so chat integrations and flight logging backends learn
+// about sessions, link drops and alarms without polling. Deliveries are sent
+// in the background and retried; a slow or unreachable endpoint never stalls
+// the bridge.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/pavliha/aircast/aircast-cli/internal/settings"
+	log "github.com/sirupsen/logrus"
+)
+
+// Events
+const (
+	SessionStarted = "session.started"
+	SessionStopped = "session.stopped"
+	LinkLost       = "link.lost"
+	LinkRestored   = "link.restored"
+	AlarmRaised    = "alarm.raised"
+	AlarmCleared   = "alarm.cleared"
+)
+
+// Events lists every event a webhook can subscribe to
+var Events = []string{SessionStarted, SessionStopped, LinkLost, LinkRestored, AlarmRaised, AlarmCleared}
+
+// Request headers
+const (
+	HeaderEvent     = "X-Aircast-Event"
+	HeaderDelivery  = "X-Aircast-Delivery"
+	HeaderTimestamp = "X-Aircast-Timestamp"
+	HeaderSignature = "X-Aircast-Signature"
+)
+
+const (
+	queueSize       = 64               // Deliveries waiting per webhook before new ones are dropped
+	sendTimeout     = 10 * time.Second // Bound on one attempt
+	maxAttempts     = 4                // Attempts per delivery, with backoff in between
+	retryBackoff    = 2 * time.Second  // First retry delay, doubled after each attempt
+	shutdownTimeout = 10 * time.Second // Time given to queued deliveries on shutdown
+)
+
+// Config is a webhook, configured in config.json's "webhooks". Its Secret
+// signs each request (see Sign).
+type Config = settings.Webhook
+
+// validate checks a webhook's configuration
+func validate(c Config) error {
+	u, err := url.Parse(c.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("webhook: invalid url %q", Target(c))
+	}
+	for _, event := range c.Events {
+		if !known(event) {
+			return fmt.Errorf("webhook: unknown event %q (expected %s)", event, strings.Join(Events, ", "))
+		}
+	}
+	return nil
+}
+
+// Target describes where a webhook posts, without credentials or query
+func Target(c Config) string {
+	u, err := url.Parse(c.URL)
+	if err != nil {
+		return "(invalid URL)"
+	}
+	u.User = nil
+	u.RawQuery = ""
+	return u.String()
+}
+
+// wants reports whether a webhook subscribes to an event
+func wants(c Config, event string) bool {
+	if len(c.Events) == 0 {
+		return true
+	}
+	for _, e := range c.Events {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+// known reports whether an event name is valid
+func known(event string) bool {
+	for _, e := range Events {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+// Device identifies the device a session bridges
+type Device struct {
+	ID   string `json:"id"`
+	Name string `json:"name,omitempty"`
+}
+
+// Payload is the JSON body of every delivery. Data holds the event's details.
+type Payload struct {
+	Event     string      `json:"event"`
+	Time      time.Time   `json:"time"`
+	SessionID string      `json:"session_id"` // Random per run, links the events of one session
+	Device    Device      `json:"device"`
+	Data      interface{} `json:"data,omitempty"`
+}
+
+// Sign returns the signature header value for a request body sent at a
+// timestamp: "sha256=" and the hex HMAC-SHA256 of "<timestamp>.<body>".
+// Receivers should recompute it and reject stale timestamps.
+func Sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// delivery is one payload queued for a webhook
+type delivery struct {
+	id      string
+	payload Payload
+}
+
+// endpoint sends deliveries to one webhook in order
+type endpoint struct {
+	config  Config
+	queue   chan delivery
+	dropped atomic.Uint64
+}
+
+// Notifier sends events to the configured webhooks
+type Notifier struct {
+	device    Device
+	sessionID string
+	endpoints []*endpoint
+	client    *http.Client
+	logger    *log.Entry
+
+	// mu guards closed, so no event is queued after the queues close
+	mu     sync.RWMutex
+	closed bool
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// New validates the webhooks and starts their background senders
+func New(configs []Config, device Device, logger *log.Entry) (*Notifier, error) {
+	for _, c := range configs {
+		if err := validate(c); err != nil {
+			return nil, err
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	n := &Notifier{
+		device:    device,
+		sessionID: randomID(),
+		client:    &http.Client{Timeout: sendTimeout},
+		logger:    logger.WithField("component", "webhook"),
+		ctx:       ctx,
+		cancel:    cancel,
+	}
+	for _, c := range configs {
+		ep := &endpoint{config: c, queue: make(chan delivery, queueSize)}
+		n.endpoints = append(n.endpoints, ep)
+		n.wg.Add(1)
+		go n.run(ep)
+	}
+	return n, nil
+}
+
+// Notify queues an event for every webhook subscribed to it. It never blocks.
+func (n *Notifier) Notify(event string, data interface{}) {
+	if n == nil {
+		return
+	}
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+	if n.closed {
+		return
+	}
+
+	d := delivery{
+		id: randomID(),
+		payload: Payload{
+			Event:     event,
+			Time:      time.Now().UTC(),
+			SessionID: n.sessionID,
+			Device:    n.device,
+			Data:      data,
+		},
+	}
+	for _, ep := range n.endpoints {
+		if !wants(ep.config, event) {
+			continue
+		}
+		select {
+		case ep.queue <- d:
+		default:
+			ep.dropped.Add(1)
+		}
+	}
+}
+
+// run delivers an endpoint's queue until the notifier is closed
+func (n *Notifier) run(ep *endpoint) {
+	defer n.wg.Done()
+
+	logger := n.logger.WithField("url", Target(ep.config))
+	for d := range ep.queue {
+		if err := n.deliver(ep.config, d); err != nil {
+			logger.WithError(err).WithField("event", d.payload.Event).Warn("Webhook delivery failed")
+		}
+	}
+	if dropped := ep.dropped.Load(); dropped > 0 {
+		logger.WithField("events", dropped).Warn("Webhook events dropped because the endpoint was too slow")
+	}
+}
+
+// deliver posts a payload, retrying network errors, 429s and 5xx responses
+func (n *Notifier) deliver(config Config, d delivery) error {
+	body, err := json.Marshal(d.payload)
+	if err != nil {
+		return err
+	}
+
+	backoff := retryBackoff
+	for attempt := 1; ; attempt++ {
+		retry, err := n.post(config, d, body)
+		if err == nil || !retry || attempt == maxAttempts {
+			return err
+		}
+		select {
+		case <-n.ctx.Done():
+			return err
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+// post makes one delivery attempt and reports whether a failure is worth retrying
+func (n *Notifier) post(config Config, d delivery, body []byte) (bool, error) {
+	req, err := http.NewRequestWithContext(n.ctx, "POST", config.URL, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "aircast-cli")
+	req.Header.Set(HeaderEvent, d.payload.Event)
+	req.Header.Set(HeaderDelivery, d.id)
+	req.Header.Set(HeaderTimestamp, timestamp)
+	if config.Secret != "" {
+		req.Header.Set(HeaderSignature, Sign(config.Secret, timestamp, body))
+	}
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return true, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode/100 != 2 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		err := fmt.Errorf("endpoint returned %s: %s", resp.Status, strings.TrimSpace(string(msg)))
+		return resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500, err
+	}
+	return false, nil
+}
+
+// Close sends the queued events, waiting up to shutdownTimeout, and stops
+// the senders
+func (n *Notifier) Close() {
+	if n == nil {
+		return
+	}
+	n.mu.Lock()
+	if n.closed {
+		n.mu.Unlock()
+		return
+	}
+	n.closed = true
+	for _, ep := range n.endpoints {
+		close(ep.queue)
+	}
+	n.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		n.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(shutdownTimeout):
+		n.logger.Warn("Webhook deliveries not finished before shutdown")
+		n.cancel()
+		<-done
+	}
+	n.cancel()
+	n.client.CloseIdleConnections()
+}
+
+// randomID returns a random hex identifier
+func randomID() string {
+	id := make([]byte, 8)
+	_, _ = rand.Read(id)
+	return hex.EncodeToString(id)
+}